@@ -0,0 +1,349 @@
+// Command ingest-sharecode turns CS2 matchmaking share codes into parsed
+// matches in a parser database: decode each share code, download and
+// decompress its demo from a configured HTTP mirror, run it through the
+// same extractor pipeline cmd/parser uses, and store the result with
+// Source="valve". It skips any share code whose match is already stored,
+// so resubmitting one is a no-op.
+//
+// --stream parses straight from the mirror's HTTP response (decompressing
+// on the fly) via internal/parser's DemoSource/LoaderConfig pipeline
+// instead of this command's default of downloading the whole demo to
+// --dest-dir first - see streamAndStore.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/ingest"
+	"cs-griefer-electron/internal/parser"
+	"cs-griefer-electron/internal/sharecode"
+)
+
+const (
+	exitSuccess = 0
+	exitFailure = 1
+)
+
+// Mirrors sharecode.RetryConfig's own zero-value defaults (applied
+// internally by sharecode.NewQueue); --stream bypasses Queue entirely, so
+// streamWithRetry re-applies the same fallbacks itself.
+const (
+	defaultStreamMaxAttempts  = 5
+	defaultStreamInitialDelay = 1 * time.Second
+	defaultStreamMaxDelay     = 30 * time.Second
+)
+
+func main() {
+	var (
+		dbPath            = flag.String("db", "", "Path to the output SQLite database (required)")
+		sharecodes        = flag.String("sharecodes", "", "Comma-separated list of CSGO-xxxxx-xxxxx-xxxxx-xxxxx-xxxxx share codes to ingest (required)")
+		urlTemplate       = flag.String("url-template", "", "HTTP mirror URL template for a share code's .dem.bz2, with {match_id}/{outcome_id}/{token} placeholders (required; see sharecode.FetchConfig for why this replaces direct Steam GC resolution)")
+		destDir           = flag.String("dest-dir", "", "Directory downloaded demos are decompressed into (default: OS temp dir)")
+		positionInterval  = flag.Int("position-interval", 4, "Position extraction interval (1=all, 2=half, 4=quarter)")
+		retryMaxAttempts  = flag.Int("retry-max-attempts", 0, "Max download attempts per share code (0 = sharecode.RetryConfig default)")
+		retryInitialDelay = flag.Duration("retry-initial-delay", 0, "Initial backoff delay between download attempts (0 = sharecode.RetryConfig default)")
+		retryMaxDelay     = flag.Duration("retry-max-delay", 0, "Max backoff delay between download attempts (0 = sharecode.RetryConfig default)")
+		queueFile         = flag.String("queue-file", "", "Persist --sharecodes to this on-disk queue (see internal/ingest.Queue) instead of processing them once inline; re-running with the same --queue-file skips share codes already done and retries ones still pending/failed, surviving a killed process (optional)")
+		workers           = flag.Int("workers", 1, "With --queue-file, how many share codes internal/ingest.Pool resolves/handles concurrently")
+		rateLimit         = flag.Int("rate-limit", 0, "With --queue-file, max --url-template fetches per second across all --workers combined (0 = unlimited)")
+		stream            = flag.Bool("stream", false, "Parse each demo directly from --url-template's HTTP response instead of downloading it whole to --dest-dir first (see streamAndStore). Not supported together with --queue-file: internal/ingest.Pool's resolve step is file-path based.")
+	)
+	flag.Parse()
+
+	if *dbPath == "" {
+		fmt.Fprintln(os.Stderr, "error: --db is required")
+		os.Exit(exitFailure)
+	}
+	if *sharecodes == "" {
+		fmt.Fprintln(os.Stderr, "error: --sharecodes is required")
+		os.Exit(exitFailure)
+	}
+	if *urlTemplate == "" {
+		fmt.Fprintln(os.Stderr, "error: --url-template is required")
+		os.Exit(exitFailure)
+	}
+	if *stream && *queueFile != "" {
+		fmt.Fprintln(os.Stderr, "error: --stream is not supported together with --queue-file")
+		os.Exit(exitFailure)
+	}
+
+	if err := run(context.Background(), *dbPath, *sharecodes, *urlTemplate, *destDir, *positionInterval, *retryMaxAttempts, *retryInitialDelay, *retryMaxDelay, *queueFile, *workers, *rateLimit, *stream); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	os.Exit(exitSuccess)
+}
+
+func run(ctx context.Context, dbPath, sharecodesCSV, urlTemplate, destDir string, positionInterval, retryMaxAttempts int, retryInitialDelay, retryMaxDelay time.Duration, queueFile string, workers, rateLimit int, stream bool) error {
+	dbConn, err := db.Open(ctx, dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	reader := db.NewReader(dbConn)
+	writer := db.NewWriter(dbConn)
+	codes := splitSharecodes(sharecodesCSV)
+
+	if stream {
+		return runStreamed(ctx, reader, dbConn, writer, urlTemplate, destDir, positionInterval, rateLimit,
+			sharecode.RetryConfig{MaxAttempts: retryMaxAttempts, InitialDelay: retryInitialDelay, MaxDelay: retryMaxDelay}, codes)
+	}
+
+	if destDir == "" {
+		destDir = os.TempDir()
+	}
+	fetchCfg := sharecode.FetchConfig{URLTemplate: urlTemplate, DestDir: destDir}
+	handle := func(ctx context.Context, matchID, demoPath string) error {
+		return parseAndStore(ctx, dbConn, writer, matchID, demoPath, positionInterval)
+	}
+
+	if queueFile != "" {
+		return runQueued(ctx, reader, fetchCfg, handle, codes, queueFile, workers, rateLimit)
+	}
+
+	queue := sharecode.NewQueue(
+		reader,
+		fetchCfg,
+		sharecode.RetryConfig{
+			MaxAttempts:  retryMaxAttempts,
+			InitialDelay: retryInitialDelay,
+			MaxDelay:     retryMaxDelay,
+		},
+		func(ctx context.Context, matchID string, decoded sharecode.Decoded, demoPath string) error {
+			return handle(ctx, matchID, demoPath)
+		},
+	)
+
+	var failed []string
+	for _, code := range codes {
+		fmt.Printf("ingesting %s...\n", code)
+		if err := queue.Submit(ctx, code); err != nil {
+			fmt.Fprintf(os.Stderr, "  failed: %v\n", err)
+			failed = append(failed, code)
+			continue
+		}
+		fmt.Printf("  done\n")
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d share codes failed to ingest: %s", len(failed), len(codes), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// runStreamed is run's --stream counterpart: instead of sharecode.Queue
+// (decode, dedup, Fetch-to-disk, retry, handle), each code is decoded,
+// deduped against matches.id, then handed to streamAndStore with the same
+// exponential-backoff retry sharecode.Queue.fetchWithRetry uses around
+// Fetch - applied here around the whole stream-and-parse call instead,
+// since there's no separate download step to retry in isolation.
+func runStreamed(ctx context.Context, reader *db.Reader, dbConn *sql.DB, writer *db.Writer, urlTemplate, cacheDir string, positionInterval, rateLimit int, retryCfg sharecode.RetryConfig, codes []string) error {
+	if retryCfg.MaxAttempts <= 0 {
+		retryCfg.MaxAttempts = defaultStreamMaxAttempts
+	}
+	if retryCfg.InitialDelay <= 0 {
+		retryCfg.InitialDelay = defaultStreamInitialDelay
+	}
+	if retryCfg.MaxDelay <= 0 {
+		retryCfg.MaxDelay = defaultStreamMaxDelay
+	}
+
+	var failed []string
+	for _, code := range codes {
+		fmt.Printf("ingesting %s (streamed)...\n", code)
+
+		decoded, err := sharecode.Decode(code)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  failed: %v\n", err)
+			failed = append(failed, code)
+			continue
+		}
+		matchID := strconv.FormatUint(decoded.MatchID, 10)
+
+		exists, err := reader.GetMatchExists(ctx, matchID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  failed: checking existing match %s: %v\n", matchID, err)
+			failed = append(failed, code)
+			continue
+		}
+		if exists {
+			fmt.Printf("  already stored\n")
+			continue
+		}
+
+		if err := streamWithRetry(ctx, dbConn, writer, matchID, urlTemplate, decoded, cacheDir, positionInterval, rateLimit, retryCfg); err != nil {
+			fmt.Fprintf(os.Stderr, "  failed: %v\n", err)
+			failed = append(failed, code)
+			continue
+		}
+		fmt.Printf("  done\n")
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d share codes failed to ingest: %s", len(failed), len(codes), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// streamWithRetry calls streamAndStore, retrying with exponential backoff
+// (capped at retryCfg.MaxDelay) on failure - the --stream equivalent of
+// sharecode.Queue.fetchWithRetry.
+func streamWithRetry(ctx context.Context, dbConn *sql.DB, writer *db.Writer, matchID, urlTemplate string, decoded sharecode.Decoded, cacheDir string, positionInterval, rateLimit int, retryCfg sharecode.RetryConfig) error {
+	delay := retryCfg.InitialDelay
+	var lastErr error
+
+	for attempt := 1; attempt <= retryCfg.MaxAttempts; attempt++ {
+		err := streamAndStore(ctx, dbConn, writer, matchID, urlTemplate, decoded, cacheDir, positionInterval, rateLimit)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if attempt == retryCfg.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		delay *= 2
+		if delay > retryCfg.MaxDelay {
+			delay = retryCfg.MaxDelay
+		}
+	}
+
+	return fmt.Errorf("exhausted %d attempts: %w", retryCfg.MaxAttempts, lastErr)
+}
+
+// runQueued persists codes to an internal/ingest.Queue at queueFile and
+// runs them through an internal/ingest.Pool, instead of sharecode.Queue's
+// one-shot, blocking Submit loop - the --queue-file path this chunk adds
+// for callers that want crash-resumable, concurrent, rate-limited
+// ingestion of many share codes at once.
+func runQueued(ctx context.Context, reader *db.Reader, fetchCfg sharecode.FetchConfig, handle ingest.HandleFunc, codes []string, queueFile string, workers, rateLimit int) error {
+	queue, err := ingest.LoadQueue(queueFile)
+	if err != nil {
+		return fmt.Errorf("failed to load queue file: %w", err)
+	}
+	for _, code := range codes {
+		if _, err := queue.Add(code); err != nil {
+			return fmt.Errorf("failed to add %s to queue: %w", code, err)
+		}
+	}
+
+	pool := ingest.NewPool(ingest.Config{
+		Workers:       workers,
+		RatePerSecond: rateLimit,
+		Reader:        reader,
+	}, queue, ingest.SharecodeResolver(fetchCfg), handle)
+
+	if err := pool.Run(ctx); err != nil {
+		return fmt.Errorf("ingest pool: %w", err)
+	}
+
+	var failed []string
+	for _, item := range queue.Pending() {
+		if item.Status == ingest.StatusFailed {
+			failed = append(failed, item.Sharecode)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d share code(s) failed in %s: %s", len(failed), queueFile, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func splitSharecodes(csv string) []string {
+	var codes []string
+	for _, code := range strings.Split(csv, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// parseAndStore runs demoPath through the same NewParser/ParseWithDB
+// streaming-to-database flow cmd/parser's run uses, then records the match
+// with Source="valve" - this is a share code demo, so unlike cmd/parser
+// (which infers Source from the demo header) the source is already known.
+func parseAndStore(ctx context.Context, dbConn *sql.DB, writer *db.Writer, matchID, demoPath string, positionInterval int) error {
+	p, err := parser.NewParser(demoPath)
+	if err != nil {
+		return fmt.Errorf("failed to create parser: %w", err)
+	}
+	defer p.Close()
+
+	return parseWithDBAndStore(ctx, dbConn, writer, matchID, demoPath, positionInterval, p)
+}
+
+// streamAndStore is parseAndStore's --stream counterpart: instead of a demo
+// already downloaded to demoPath, it parses directly from urlTemplate's HTTP
+// response via internal/parser's DemoSource/LoaderConfig pipeline
+// (sharecode.ResolveURL resolves the same URL Fetch would have downloaded),
+// decompressing on the fly and never landing the full demo on disk unless
+// cacheDir is set (in which case LoaderConfig caches it keyed by matchID, the
+// same way a repeat Fetch of the same share code would reuse cfg.DestDir).
+func streamAndStore(ctx context.Context, dbConn *sql.DB, writer *db.Writer, matchID, urlTemplate string, decoded sharecode.Decoded, cacheDir string, positionInterval, rateLimit int) error {
+	src := parser.HTTPSource{URL: sharecode.ResolveURL(urlTemplate, decoded)}
+	loaderCfg := parser.LoaderConfig{
+		RatePerSecond: rateLimit,
+		CacheDir:      cacheDir,
+		CacheKey:      matchID,
+	}
+
+	p, err := parser.NewFromSource(ctx, src, loaderCfg, nil)
+	if err != nil {
+		return fmt.Errorf("failed to stream %s: %w", src.URL, err)
+	}
+	defer p.Close()
+
+	return parseWithDBAndStore(ctx, dbConn, writer, matchID, src.URL, positionInterval, p)
+}
+
+// parseWithDBAndStore is the InsertMatch(placeholder)/ParseWithDB/
+// InsertMatch(final) tail parseAndStore and streamAndStore share, once each
+// has its own *parser.Parser (built from a file path or a DemoSource
+// respectively) ready to go. demoLabel is only used in error messages - a
+// file path for parseAndStore, the resolved URL for streamAndStore.
+func parseWithDBAndStore(ctx context.Context, dbConn *sql.DB, writer *db.Writer, matchID, demoLabel string, positionInterval int, p *parser.Parser) error {
+	placeholderSource := "valve"
+	if err := writer.InsertMatch(ctx, db.Match{
+		ID:       matchID,
+		Map:      "unknown",
+		TickRate: 64.0,
+		Source:   &placeholderSource,
+	}); err != nil {
+		return fmt.Errorf("failed to insert placeholder match: %w", err)
+	}
+
+	matchData, err := p.ParseWithDB(ctx, func(stage string, tick, round int, pct float64) {}, dbConn, positionInterval, writer, matchID, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", demoLabel, err)
+	}
+
+	valveSource := "valve"
+	if err := writer.InsertMatch(ctx, db.Match{
+		ID:        matchID,
+		Map:       matchData.Map,
+		TickRate:  matchData.TickRate,
+		StartedAt: matchData.StartedAt,
+		Source:    &valveSource,
+	}); err != nil {
+		return fmt.Errorf("failed to update match: %w", err)
+	}
+
+	return nil
+}
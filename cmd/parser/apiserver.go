@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/httpapi"
+	"cs-griefer-electron/internal/rediscache"
+)
+
+// runAPIServer starts the read-only grief-score HTTP API over dbPath and
+// blocks until ctx is cancelled. cacheURL/cacheTTL configure the optional
+// Redis-backed Cache (see internal/httpapi.Cache); an empty cacheURL means
+// every request hits the database directly.
+func runAPIServer(ctx context.Context, dbPath, listenAddr, cacheURL string, cacheTTL time.Duration, rateLimit float64, rateBurst int) error {
+	dbConn, err := db.Open(ctx, dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	var cache httpapi.Cache
+	if cacheURL != "" {
+		cacheClient, err := rediscache.Dial(cacheURL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to --cache-url: %w", err)
+		}
+		defer cacheClient.Close()
+		cache = httpapi.NewRedisCache(cacheClient)
+	}
+
+	srv := httpapi.NewServer(db.NewReader(dbConn), dbConn, httpapi.Config{
+		Cache:              cache,
+		CacheTTL:           cacheTTL,
+		RateLimitPerSecond: rateLimit,
+		RateLimitBurst:     rateBurst,
+	})
+
+	httpServer := &http.Server{
+		Addr:    listenAddr,
+		Handler: srv.Handler(),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
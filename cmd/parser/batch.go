@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/exporters/tsdb"
+	"cs-griefer-electron/internal/ipc"
+	"cs-griefer-electron/internal/pipeline"
+)
+
+// runBatch expands pattern to a list of demos and parses them concurrently
+// via pipeline.BatchRunner, sharing outPath as every job's output database.
+// Individual demo failures are recorded in failed_demos and don't fail the
+// batch; runBatch only returns an error for setup failures (bad glob, no
+// matches, can't open the database).
+func runBatch(ctx context.Context, pattern, outPath string, workers int, positionInterval int, heapSoftLimitMB, heapHardLimitMB uint64, output *ipc.Output, steamAPIKey, steamCacheDir string, steamCacheTTL time.Duration, brokerURL, webhookURL, webhookSecret, webhookEvents string, webhookMapNumber int, cacheURL string, cacheTTL time.Duration, exportFormat, exportPath, scoringProfilePath string, tsdbBackend string, tsdbCfg tsdb.Config, parallelWorkers int, retryWrites bool, retryCfg db.RetryConfig, columnarDir string, recordInputs bool, positionEncoding string, positionKeyframeInterval int, enabledExtractors string, steamIDFormat string) error {
+	demoPaths, err := pipeline.ExpandDemoPaths(pattern)
+	if err != nil {
+		return err
+	}
+	if len(demoPaths) == 0 {
+		return fmt.Errorf("--batch %q matched no demo files", pattern)
+	}
+	output.Log("info", fmt.Sprintf("Batch mode: %d demo(s) matched, %d worker(s)", len(demoPaths), workers))
+
+	jobs := make([]pipeline.Job, len(demoPaths))
+	for i, path := range demoPaths {
+		jobs[i] = pipeline.Job{DemoPath: path}
+	}
+
+	runner := pipeline.NewBatchRunner(pipeline.Config{
+		Workers: workers,
+		DBPath:  outPath,
+		Output:  output,
+	})
+
+	result, err := runner.Run(ctx, jobs, func(ctx context.Context, dbConn *sql.DB, job pipeline.Job) error {
+		return runWithDB(ctx, dbConn, outPath, job.DemoPath, job.MatchID, positionInterval, heapSoftLimitMB, heapHardLimitMB, output, steamAPIKey, steamCacheDir, steamCacheTTL, brokerURL, webhookURL, webhookSecret, webhookEvents, webhookMapNumber, cacheURL, cacheTTL, exportFormat, exportPath, scoringProfilePath, tsdbBackend, tsdbCfg, parallelWorkers, retryWrites, retryCfg, columnarDir, recordInputs, positionEncoding, positionKeyframeInterval, enabledExtractors, steamIDFormat)
+	})
+	if err != nil {
+		return err
+	}
+
+	output.Log("info", fmt.Sprintf("Batch complete: %d succeeded, %d failed", result.Succeeded, len(result.Failed)))
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("batch: %d of %d demo(s) failed, see failed_demos in %s", len(result.Failed), len(jobs), outPath)
+	}
+	return nil
+}
@@ -8,13 +8,44 @@ import (
 	"cs-griefer-electron/internal/ipc"
 )
 
-// MemoryLogger logs memory usage periodically
+// BackpressureLevel describes how hard the parser should throttle
+// ingestion in response to heap pressure.
+type BackpressureLevel int
+
+const (
+	// BackpressureNone means heap usage is below HeapSoftLimitMB.
+	BackpressureNone BackpressureLevel = iota
+	// BackpressureSoft means HeapSoftLimitMB was exceeded: buffered
+	// writers should flush and the position sampling rate should drop.
+	BackpressureSoft
+	// BackpressureHard means HeapHardLimitMB was exceeded: the caller
+	// should force a GC and pause ingestion until heap drops back
+	// below the soft limit.
+	BackpressureHard
+)
+
+// MemoryLogger logs memory usage periodically and, once configured with
+// HeapSoftLimitMB/HeapHardLimitMB, doubles as a memory governor: each
+// CheckBackpressure call evaluates runtime.MemStats.HeapInuse and
+// reports a BackpressureLevel so the ingestion path can flush early and
+// sample positions less often instead of growing until the process OOMs.
+// LogIfNeeded only logs; it does not evaluate the limits.
 type MemoryLogger struct {
-	output    *ipc.Output
-	lastLog   time.Time
-	interval  time.Duration
-	lastTick  int
+	output       *ipc.Output
+	lastLog      time.Time
+	interval     time.Duration
+	lastTick     int
 	tickInterval int
+
+	// HeapSoftLimitMB, when non-zero, is the HeapInuse threshold at
+	// which CheckBackpressure starts reporting BackpressureSoft.
+	HeapSoftLimitMB uint64
+	// HeapHardLimitMB, when non-zero, is the HeapInuse threshold at
+	// which CheckBackpressure forces a runtime.GC() and reports
+	// BackpressureHard.
+	HeapHardLimitMB uint64
+
+	lastLevel BackpressureLevel
 }
 
 // NewMemoryLogger creates a new memory logger
@@ -31,30 +62,89 @@ func NewMemoryLogger(output *ipc.Output, intervalSeconds int, tickInterval int)
 func (ml *MemoryLogger) LogIfNeeded(tick int) {
 	now := time.Now()
 	shouldLog := false
-	
+
 	// Log every N seconds
 	if now.Sub(ml.lastLog) >= ml.interval {
 		shouldLog = true
 		ml.lastLog = now
 	}
-	
+
 	// Also log every N ticks (if tickInterval > 0)
 	if ml.tickInterval > 0 && tick > 0 && (tick-ml.lastTick) >= ml.tickInterval {
 		shouldLog = true
 		ml.lastTick = tick
 	}
-	
+
 	if !shouldLog {
 		return
 	}
-	
+
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
-	
+
 	heapAllocMB := float64(m.HeapAlloc) / (1024 * 1024)
 	heapInuseMB := float64(m.HeapInuse) / (1024 * 1024)
 	heapSysMB := float64(m.HeapSys) / (1024 * 1024)
-	
+
 	ml.output.Log("info", fmt.Sprintf("Memory: HeapAlloc=%.1fMB, HeapInuse=%.1fMB, HeapSys=%.1fMB, NumGC=%d, Tick=%d",
 		heapAllocMB, heapInuseMB, heapSysMB, m.NumGC, tick))
 }
+
+// HeapInuseMB returns the current HeapInuse in megabytes, rounded down.
+// Callers that buffer rows in memory (db.BulkInserter) can poll this to
+// decide whether to force a flush between their normal size/time-based
+// triggers.
+func (ml *MemoryLogger) HeapInuseMB() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapInuse / (1024 * 1024)
+}
+
+// CheckBackpressure evaluates HeapInuse against HeapSoftLimitMB/
+// HeapHardLimitMB and returns the resulting level. When the hard limit is
+// crossed it forces a runtime.GC() before returning, matching the
+// "pause parsing until heap drops below soft" behavior the caller is
+// expected to implement around this call (stop accepting new ticks until
+// a later CheckBackpressure call reports BackpressureNone or
+// BackpressureSoft again). Transitions are logged so operators can see
+// when and why throttling kicked in.
+func (ml *MemoryLogger) CheckBackpressure() BackpressureLevel {
+	if ml.HeapSoftLimitMB == 0 && ml.HeapHardLimitMB == 0 {
+		return BackpressureNone
+	}
+
+	heapMB := ml.HeapInuseMB()
+
+	level := BackpressureNone
+	switch {
+	case ml.HeapHardLimitMB > 0 && heapMB >= ml.HeapHardLimitMB:
+		level = BackpressureHard
+	case ml.HeapSoftLimitMB > 0 && heapMB >= ml.HeapSoftLimitMB:
+		level = BackpressureSoft
+	}
+
+	if level == BackpressureHard {
+		runtime.GC()
+	}
+
+	if level != ml.lastLevel {
+		ml.output.Log("warn", fmt.Sprintf(
+			"Memory backpressure changed: %v -> %v (HeapInuse=%dMB, soft=%dMB, hard=%dMB)",
+			ml.lastLevel, level, heapMB, ml.HeapSoftLimitMB, ml.HeapHardLimitMB))
+		ml.lastLevel = level
+	}
+
+	return level
+}
+
+// String renders a BackpressureLevel for log messages.
+func (l BackpressureLevel) String() string {
+	switch l {
+	case BackpressureSoft:
+		return "soft"
+	case BackpressureHard:
+		return "hard"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/ipc"
+	"cs-griefer-electron/internal/remotedb"
+)
+
+// runRemoteDBServer starts the remotedb.Server over dbPath and blocks
+// until ctx is cancelled, the way runAPIServer does for the read-only
+// httpapi.Server. Unlike --api-listen, this is a read/write endpoint: the
+// intent is other processes hold an internal/remotedb.Store/Client
+// pointed at this address so several parser workers can stream into one
+// shared database instead of each producing their own SQLite file.
+// authToken disables bearer-token auth when empty; certFile/keyFile, if
+// both set, serve HTTPS instead of plain HTTP.
+func runRemoteDBServer(ctx context.Context, dbPath, listenAddr, authToken, certFile, keyFile string, output *ipc.Output) error {
+	dbConn, err := db.Open(ctx, dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	srv := remotedb.NewServer(dbConn, authToken)
+	srv.OnProgress(func(matchID, stage string, tick, round int, pct float64) {
+		output.Progress(stage, tick, round, pct)
+	})
+
+	httpServer := &http.Server{
+		Addr:    listenAddr,
+		Handler: srv.Handler(),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if certFile != "" && keyFile != "" {
+			errCh <- httpServer.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			errCh <- httpServer.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
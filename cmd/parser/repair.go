@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/ipc"
+)
+
+// runRepair retries every one of matchID's events still stuck "pending" or
+// "failed" in dbPath (see db.Reader.GetFailedEvents), the rows a crashed
+// --retry-writes parse can leave behind, instead of re-parsing the demo.
+func runRepair(ctx context.Context, dbPath, matchID string, cfg db.RetryConfig, output *ipc.Output) error {
+	dbConn, err := db.Open(ctx, dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	rw := db.NewRetryingWriter(dbConn, cfg)
+	repaired, err := rw.Repair(ctx, matchID)
+	if err != nil {
+		return fmt.Errorf("repair stopped after fixing %d event(s): %w", repaired, err)
+	}
+
+	output.Log("info", fmt.Sprintf("Repaired %d event(s) for match %s", repaired, matchID))
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/ipc"
+	"cs-griefer-electron/internal/scoring"
+)
+
+// runRescore recomputes matchID's player scores from events already stored
+// in dbPath, without re-parsing the demo that produced them. profilePath is
+// a YAML scoring.ScoringProfile file; empty means scoring.DefaultProfile.
+func runRescore(ctx context.Context, dbPath, matchID, profilePath string, output *ipc.Output) error {
+	profile := scoring.DefaultProfile()
+	if profilePath != "" {
+		loaded, err := scoring.LoadProfile(profilePath)
+		if err != nil {
+			return err
+		}
+		profile = loaded
+	}
+	output.Log("info", fmt.Sprintf("Rescoring match %s with profile %s v%s", matchID, profile.Name, profile.Version))
+
+	dbConn, err := db.Open(ctx, dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	reader := db.NewReader(dbConn)
+	writer := db.NewWriter(dbConn)
+	if err := scoring.NewScorer(writer).ComputeScores(ctx, matchID, reader, profile); err != nil {
+		return fmt.Errorf("failed to compute scores: %w", err)
+	}
+
+	output.Log("info", "Player scores recomputed")
+	return nil
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cs-griefer-electron/internal/ipc"
+)
+
+// serveMaxQueuedRetryAfterSeconds is sent back in the Retry-After header
+// when the worker pool is saturated.
+const serveMaxQueuedRetryAfterSeconds = 5
+
+// jobStatus is the lifecycle state of one /parse request.
+type jobStatus string
+
+const (
+	jobStatusQueued  jobStatus = "queued"
+	jobStatusRunning jobStatus = "running"
+	jobStatusDone    jobStatus = "done"
+	jobStatusFailed  jobStatus = "failed"
+)
+
+// parseJob tracks one demo parse submitted to the --serve HTTP API.
+type parseJob struct {
+	mu sync.Mutex
+
+	ID         string
+	MatchID    string
+	DemoPath   string
+	OutputPath string // NDJSON file GET /matches/{id}/events tails
+	Status     jobStatus
+	Err        string
+	EventCount int
+
+	cancel context.CancelFunc
+}
+
+func (j *parseJob) setStatus(status jobStatus) {
+	j.mu.Lock()
+	j.Status = status
+	j.mu.Unlock()
+}
+
+func (j *parseJob) setFailed(err error) {
+	j.mu.Lock()
+	j.Status = jobStatusFailed
+	j.Err = err.Error()
+	j.mu.Unlock()
+}
+
+func (j *parseJob) snapshot() parseJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return parseJob{
+		ID:         j.ID,
+		MatchID:    j.MatchID,
+		DemoPath:   j.DemoPath,
+		OutputPath: j.OutputPath,
+		Status:     j.Status,
+		Err:        j.Err,
+		EventCount: j.EventCount,
+	}
+}
+
+// serveMetrics are the Prometheus-style counters exposed at /metrics. No
+// Prometheus client library is vendored, so these are plain atomic counters
+// rendered by hand in the exposition format.
+type serveMetrics struct {
+	parsesStarted atomic.Int64
+	parsesFailed  atomic.Int64
+	bytesRead     atomic.Int64
+	eventsEmitted atomic.Int64
+}
+
+// apiServer backs the --serve HTTP mode: a bounded worker pool of demo
+// parses, each trackable via its job ID through /matches/{id}/summary and
+// streamable (as it writes its NDJSON output) via /matches/{id}/events.
+type apiServer struct {
+	mu      sync.Mutex
+	jobs    map[string]*parseJob
+	sem     chan struct{} // bounded worker pool; buffered to --serve-workers
+	tempDir string
+	metrics serveMetrics
+}
+
+func newAPIServer(workers int, tempDir string) *apiServer {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &apiServer{
+		jobs:    make(map[string]*parseJob),
+		sem:     make(chan struct{}, workers),
+		tempDir: tempDir,
+	}
+}
+
+// runServe starts the HTTP API and blocks until ctx is cancelled.
+func runServe(ctx context.Context, listenAddr string, workers int, tempDir string, positionInterval int) error {
+	if tempDir == "" {
+		tempDir = os.TempDir()
+	}
+	if err := os.MkdirAll(tempDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create --serve-temp-dir: %w", err)
+	}
+
+	srv := newAPIServer(workers, tempDir)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /parse", srv.handleParse(positionInterval))
+	mux.HandleFunc("GET /matches/{id}/events", srv.handleEvents)
+	mux.HandleFunc("GET /matches/{id}/summary", srv.handleSummary)
+	mux.HandleFunc("GET /metrics", srv.handleMetrics)
+
+	httpServer := &http.Server{
+		Addr:    listenAddr,
+		Handler: mux,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+type parseRequest struct {
+	DemoPath string `json:"demo_path"`
+	MatchID  string `json:"match_id"`
+}
+
+type parseResponse struct {
+	JobID string `json:"job_id"`
+}
+
+// handleParse accepts {"demo_path": "...", "match_id": "..."} and queues a
+// background parse, returning its job id immediately. If every worker slot
+// is busy, it responds 429 with Retry-After instead of queuing unboundedly.
+func (s *apiServer) handleParse(positionInterval int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req parseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.DemoPath == "" {
+			http.Error(w, "demo_path is required", http.StatusBadRequest)
+			return
+		}
+		if _, err := os.Stat(req.DemoPath); err != nil {
+			http.Error(w, fmt.Sprintf("demo_path not readable: %v", err), http.StatusBadRequest)
+			return
+		}
+		matchID := req.MatchID
+		if matchID == "" {
+			base := filepath.Base(req.DemoPath)
+			matchID = base[:len(base)-len(filepath.Ext(base))]
+		}
+
+		select {
+		case s.sem <- struct{}{}:
+		default:
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", serveMaxQueuedRetryAfterSeconds))
+			http.Error(w, "all parse workers are busy, try again shortly", http.StatusTooManyRequests)
+			return
+		}
+
+		jobID := uuid.NewString()
+		jobCtx, cancel := context.WithCancel(context.Background())
+		job := &parseJob{
+			ID:         jobID,
+			MatchID:    matchID,
+			DemoPath:   req.DemoPath,
+			OutputPath: filepath.Join(s.tempDir, jobID+".ndjson"),
+			Status:     jobStatusQueued,
+			cancel:     cancel,
+		}
+
+		s.mu.Lock()
+		s.jobs[jobID] = job
+		s.mu.Unlock()
+
+		s.metrics.parsesStarted.Add(1)
+		go s.runJob(jobCtx, job, positionInterval)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(parseResponse{JobID: jobID})
+	}
+}
+
+// runJob executes one parse on its own goroutine, releasing its worker slot
+// (and cancel func) when done regardless of outcome.
+func (s *apiServer) runJob(ctx context.Context, job *parseJob, positionInterval int) {
+	defer func() { <-s.sem }()
+	defer job.cancel()
+
+	job.setStatus(jobStatusRunning)
+
+	output := ipc.NewOutput()
+	err := runJSON(ctx, job.DemoPath, job.OutputPath, job.MatchID, positionInterval, nil, 0, "json", output, "", "", 0, "", false, nil, "", "")
+	if err != nil {
+		s.metrics.parsesFailed.Add(1)
+		job.setFailed(err)
+		return
+	}
+
+	if info, statErr := os.Stat(job.OutputPath); statErr == nil {
+		s.metrics.bytesRead.Add(info.Size())
+	}
+	lineCount := countNDJSONLines(job.OutputPath)
+	job.mu.Lock()
+	job.EventCount = lineCount
+	job.Status = jobStatusDone
+	job.mu.Unlock()
+	s.metrics.eventsEmitted.Add(int64(lineCount))
+}
+
+func countNDJSONLines(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	count := 0
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := f.Read(buf)
+		for i := 0; i < n; i++ {
+			if buf[i] == '\n' {
+				count++
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return count
+}
+
+func (s *apiServer) jobByID(id string) (*parseJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+// handleSummary returns the job's current status, event count so far, and
+// error (if it failed).
+func (s *apiServer) handleSummary(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobByID(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "unknown job id", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job.snapshot())
+}
+
+// handleEvents streams the job's NDJSON output as Server-Sent Events,
+// tailing the file as it grows and closing once the job reaches a
+// terminal status with nothing left to send. Respects client disconnects
+// via the request context.
+func (s *apiServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobByID(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "unknown job id", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	var offset int64
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			newOffset, lines := readNewLines(job.OutputPath, offset)
+			offset = newOffset
+			for _, line := range lines {
+				fmt.Fprintf(w, "data: %s\n\n", line)
+			}
+			if len(lines) > 0 {
+				flusher.Flush()
+			}
+
+			status := job.snapshot().Status
+			if status == jobStatusDone || status == jobStatusFailed {
+				fmt.Fprintf(w, "event: done\ndata: {\"status\":%q}\n\n", status)
+				flusher.Flush()
+				return
+			}
+		}
+	}
+}
+
+// readNewLines reads every complete ("\n"-terminated) line in path starting
+// at fromOffset, returning the new end offset and the lines found.
+func readNewLines(path string, fromOffset int64) (int64, []string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fromOffset, nil
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() <= fromOffset {
+		return fromOffset, nil
+	}
+
+	if _, err := f.Seek(fromOffset, 0); err != nil {
+		return fromOffset, nil
+	}
+
+	data := make([]byte, info.Size()-fromOffset)
+	n, _ := f.Read(data)
+	data = data[:n]
+
+	var lines []string
+	lastNewline := -1
+	lineStart := 0
+	for i, b := range data {
+		if b == '\n' {
+			lines = append(lines, string(data[lineStart:i]))
+			lineStart = i + 1
+			lastNewline = i
+		}
+	}
+	return fromOffset + int64(lastNewline+1), lines
+}
+
+// handleMetrics renders parse counters in the Prometheus text exposition
+// format.
+func (s *apiServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# HELP cs2_parser_parses_started_total Number of /parse requests accepted.\n")
+	fmt.Fprintf(w, "# TYPE cs2_parser_parses_started_total counter\n")
+	fmt.Fprintf(w, "cs2_parser_parses_started_total %d\n", s.metrics.parsesStarted.Load())
+	fmt.Fprintf(w, "# HELP cs2_parser_parses_failed_total Number of parses that returned an error.\n")
+	fmt.Fprintf(w, "# TYPE cs2_parser_parses_failed_total counter\n")
+	fmt.Fprintf(w, "cs2_parser_parses_failed_total %d\n", s.metrics.parsesFailed.Load())
+	fmt.Fprintf(w, "# HELP cs2_parser_bytes_read_total Total bytes written to completed parses' NDJSON output.\n")
+	fmt.Fprintf(w, "# TYPE cs2_parser_bytes_read_total counter\n")
+	fmt.Fprintf(w, "cs2_parser_bytes_read_total %d\n", s.metrics.bytesRead.Load())
+	fmt.Fprintf(w, "# HELP cs2_parser_events_emitted_total Total events emitted across completed parses.\n")
+	fmt.Fprintf(w, "# TYPE cs2_parser_events_emitted_total counter\n")
+	fmt.Fprintf(w, "cs2_parser_events_emitted_total %d\n", s.metrics.eventsEmitted.Load())
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/snappy"
+)
+
+// Parquet output for JSONEvent.
+//
+// Pulling in a full Arrow/Parquet dependency just for eight scalar columns
+// is overkill, so this writes a small self-describing columnar format
+// instead: a sequence of Snappy-compressed row groups (one per chunk
+// flushed by runJSON, each sorted by RoundIndex/StartTick), each
+// holding one column per JSONEvent field. Type/ActorSteamID/VictimSteamID/
+// Reason are dictionary-encoded (they repeat heavily within a round) and
+// StartTick/EndTick are delta-encoded against the previous row, both ahead
+// of the Snappy pass. It keeps the same write-sorted-chunk-then-merge shape
+// as streaming_json.go so the two exporters can share the external sort via
+// the ChunkSink interface below.
+
+const parquetMagic = "CS2PQ2\x00"
+
+// ChunkSink is the external-merge-sort machinery writeChunk/mergeChunks
+// dispatch to: write a sorted slice of events to its own chunk file, then
+// later merge many such chunk files into one globally ordered output file.
+// JSON and Parquet both satisfy it, so runJSON doesn't need to know which
+// encoding it's producing beyond picking the right sink.
+type ChunkSink interface {
+	WriteChunk(events []JSONEvent, filepath string) error
+	MergeChunks(chunkFiles []string, outputPath string) error
+}
+
+type jsonChunkSink struct{}
+
+func (jsonChunkSink) WriteChunk(events []JSONEvent, filepath string) error {
+	return writeSortedChunk(events, filepath)
+}
+
+func (jsonChunkSink) MergeChunks(chunkFiles []string, outputPath string) error {
+	return mergeSortedChunks(chunkFiles, outputPath)
+}
+
+type parquetChunkSink struct{}
+
+func (parquetChunkSink) WriteChunk(events []JSONEvent, filepath string) error {
+	return writeParquetChunk(events, filepath)
+}
+
+func (parquetChunkSink) MergeChunks(chunkFiles []string, outputPath string) error {
+	return mergeParquetChunks(chunkFiles, outputPath)
+}
+
+// chunkSinkFor resolves the --format flag to its ChunkSink.
+func chunkSinkFor(format string) ChunkSink {
+	if format == "parquet" {
+		return parquetChunkSink{}
+	}
+	return jsonChunkSink{}
+}
+
+// writeChunk dispatches to the JSON or Parquet chunk writer based on the
+// --format flag.
+func writeChunk(format string, events []JSONEvent, filepath string) error {
+	return chunkSinkFor(format).WriteChunk(events, filepath)
+}
+
+// mergeChunks dispatches to the JSON or Parquet chunk merger based on the
+// --format flag.
+func mergeChunks(format string, chunkFiles []string, outputPath string) error {
+	return chunkSinkFor(format).MergeChunks(chunkFiles, outputPath)
+}
+
+// parquetColumn indices, in the order every row group writes them.
+const (
+	colType = iota
+	colRoundIndex
+	colStartTick
+	colEndTick
+	colActorSteamID
+	colVictimSteamID
+	colReason
+)
+
+// writeParquetChunk writes a sorted chunk of events to filepath as a
+// single row group, using the same RoundIndex/StartTick ordering as
+// writeSortedChunk.
+func writeParquetChunk(events []JSONEvent, filepath string) error {
+	sortJSONEvents(events)
+
+	f, err := os.Create(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet chunk file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if _, err := w.WriteString(parquetMagic); err != nil {
+		return err
+	}
+
+	if err := writeRowGroup(w, events); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// writeRowGroup writes one row group: a row count, followed by each
+// column Snappy-compressed independently (dictionary-friendly since
+// Type/ActorSteamID/VictimSteamID repeat heavily within a round).
+func writeRowGroup(w io.Writer, events []JSONEvent) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(events))); err != nil {
+		return err
+	}
+
+	types := make([]string, len(events))
+	rounds := make([]int32, len(events))
+	startTicks := make([]int32, len(events))
+	endTicks := make([]int32, len(events)) // -1 sentinel for nil
+	actors := make([]string, len(events))  // "" sentinel for nil
+	victims := make([]string, len(events)) // "" sentinel for nil
+	reasons := make([]string, len(events)) // "" sentinel for nil
+
+	for i, e := range events {
+		types[i] = e.Type
+		rounds[i] = int32(e.RoundIndex)
+		startTicks[i] = int32(e.StartTick)
+		if e.EndTick != nil {
+			endTicks[i] = int32(*e.EndTick)
+		} else {
+			endTicks[i] = -1
+		}
+		if e.ActorSteamID != nil {
+			actors[i] = *e.ActorSteamID
+		}
+		if e.VictimSteamID != nil {
+			victims[i] = *e.VictimSteamID
+		}
+		if e.Reason != nil {
+			reasons[i] = *e.Reason
+		}
+	}
+
+	if err := writeDictStringColumn(w, types); err != nil {
+		return err
+	}
+	if err := writeInt32Column(w, rounds); err != nil {
+		return err
+	}
+	if err := writeDeltaInt32Column(w, startTicks); err != nil {
+		return err
+	}
+	if err := writeDeltaInt32Column(w, endTicks); err != nil {
+		return err
+	}
+	if err := writeDictStringColumn(w, actors); err != nil {
+		return err
+	}
+	if err := writeDictStringColumn(w, victims); err != nil {
+		return err
+	}
+	return writeDictStringColumn(w, reasons)
+}
+
+func writeInt32Column(w io.Writer, values []int32) error {
+	raw := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(raw[i*4:], uint32(v))
+	}
+	return writeCompressedBlock(w, raw)
+}
+
+func writeStringColumn(w io.Writer, values []string) error {
+	raw := make([]byte, 0, len(values)*8)
+	for _, v := range values {
+		var lenBuf [4]byte
+		binary.LittleEndian.PutUint32(lenBuf[:], uint32(len(v)))
+		raw = append(raw, lenBuf[:]...)
+		raw = append(raw, v...)
+	}
+	return writeCompressedBlock(w, raw)
+}
+
+// writeDeltaInt32Column delta-encodes values (each entry minus the previous,
+// first entry relative to 0) before compressing. StartTick/EndTick come from
+// rows sorted by round then tick, so deltas are small and repetitive -
+// friendlier to Snappy than the raw absolute tick numbers.
+func writeDeltaInt32Column(w io.Writer, values []int32) error {
+	deltas := make([]int32, len(values))
+	var prev int32
+	for i, v := range values {
+		deltas[i] = v - prev
+		prev = v
+	}
+	return writeInt32Column(w, deltas)
+}
+
+// writeDictStringColumn dictionary-encodes values: the distinct strings
+// (in first-seen order) followed by one int32 index per row. Type and the
+// SteamID columns repeat heavily within a round, so this is both smaller
+// and more Snappy-friendly than writing the raw strings every row.
+func writeDictStringColumn(w io.Writer, values []string) error {
+	dictIndex := make(map[string]int32)
+	dict := make([]string, 0, len(values))
+	indices := make([]int32, len(values))
+	for i, v := range values {
+		idx, ok := dictIndex[v]
+		if !ok {
+			idx = int32(len(dict))
+			dictIndex[v] = idx
+			dict = append(dict, v)
+		}
+		indices[i] = idx
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(dict))); err != nil {
+		return err
+	}
+	if err := writeStringColumn(w, dict); err != nil {
+		return err
+	}
+	return writeInt32Column(w, indices)
+}
+
+func writeCompressedBlock(w io.Writer, raw []byte) error {
+	compressed := snappy.Encode(nil, raw)
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(compressed))); err != nil {
+		return err
+	}
+	_, err := w.Write(compressed)
+	return err
+}
+
+// mergeParquetChunks concatenates the row groups of multiple parquet
+// chunk files into a single output file without decoding column values —
+// each chunk is already one ordered row group, and chunks themselves are
+// produced in RoundIndex order by the caller, so concatenation preserves
+// the global sort.
+func mergeParquetChunks(chunkFiles []string, outputPath string) error {
+	if len(chunkFiles) == 0 {
+		return fmt.Errorf("no chunk files to merge")
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer out.Close()
+
+	w := bufio.NewWriter(out)
+	if _, err := w.WriteString(parquetMagic); err != nil {
+		return err
+	}
+
+	for _, chunkFile := range chunkFiles {
+		if err := appendRowGroups(w, chunkFile); err != nil {
+			return fmt.Errorf("failed to merge chunk %s: %w", chunkFile, err)
+		}
+	}
+
+	return w.Flush()
+}
+
+// appendRowGroups copies every row group from chunkFile (skipping its
+// magic header) straight into w.
+func appendRowGroups(w io.Writer, chunkFile string) error {
+	f, err := os.Open(chunkFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(parquetMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return fmt.Errorf("failed to read magic: %w", err)
+	}
+	if string(magic) != parquetMagic {
+		return fmt.Errorf("not a cs2 parquet chunk file")
+	}
+
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,78 @@
+// Command report renders a match already stored in a parser database into a
+// human- or machine-readable summary (see internal/reporters), combining
+// the per-round event timeline with the final player score table.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/reporters"
+)
+
+const (
+	exitSuccess = 0
+	exitFailure = 1
+)
+
+func main() {
+	var (
+		dbPath     = flag.String("db", "", "Path to the SQLite database produced by cmd/parser (required)")
+		matchID    = flag.String("match-id", "", "Match ID to report on (required)")
+		format     = flag.String("format", "text", "Output format: text, markdown or json")
+		profile    = flag.String("profile", "", "Restrict player scores to this scoring.ScoringProfile name (default: every profile the match was scored under)")
+		outputPath = flag.String("output", "", "Path to write the report to (default: stdout)")
+	)
+	flag.Parse()
+
+	if *dbPath == "" {
+		fmt.Fprintf(os.Stderr, "error: --db is required\n")
+		os.Exit(exitFailure)
+	}
+	if *matchID == "" {
+		fmt.Fprintf(os.Stderr, "error: --match-id is required\n")
+		os.Exit(exitFailure)
+	}
+
+	reporter, err := reporters.New(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	ctx := context.Background()
+
+	dbConn, err := db.Open(ctx, *dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to open database: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	defer dbConn.Close()
+
+	reader := db.NewReader(dbConn)
+	report, err := reporters.BuildReport(ctx, reader, *matchID, *profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to build report: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	rendered, err := reporter.Render(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to render report: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	if *outputPath == "" || *outputPath == "-" {
+		fmt.Println(rendered)
+		os.Exit(exitSuccess)
+	}
+
+	if err := os.WriteFile(*outputPath, []byte(rendered), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to write %s: %v\n", *outputPath, err)
+		os.Exit(exitFailure)
+	}
+	os.Exit(exitSuccess)
+}
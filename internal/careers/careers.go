@@ -0,0 +1,45 @@
+// Package careers aggregates db.Writer's per-match player_scores into a
+// cross-match reputation signal: career_players' cumulative stats and
+// grief-score EWMA (see db.Writer.UpsertCareerFromMatch), and
+// career_match_stats, the per-match ledger that makes folding a match
+// twice a no-op. Recompute rebuilds both tables from scratch, for when
+// career_players has drifted (a schema change, a bug fix to the fold
+// logic) and needs to be regenerated rather than incrementally repaired.
+package careers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// Recompute truncates career_players and career_match_stats, then refolds
+// every match currently in dbConn through db.Writer.UpsertCareerFromMatch,
+// oldest first (so first_seen/last_seen and the EWMA accumulate in the
+// same order they would have from live ingestion). Returns the number of
+// matches folded.
+func Recompute(ctx context.Context, dbConn *sql.DB) (int, error) {
+	if _, err := dbConn.ExecContext(ctx, `DELETE FROM career_match_stats`); err != nil {
+		return 0, fmt.Errorf("failed to clear career_match_stats: %w", err)
+	}
+	if _, err := dbConn.ExecContext(ctx, `DELETE FROM career_players`); err != nil {
+		return 0, fmt.Errorf("failed to clear career_players: %w", err)
+	}
+
+	reader := db.NewReader(dbConn)
+	matches, err := reader.GetMatches(ctx, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list matches: %w", err)
+	}
+
+	writer := db.NewWriter(dbConn)
+	for i := len(matches) - 1; i >= 0; i-- {
+		if err := writer.UpsertCareerFromMatch(ctx, matches[i].ID); err != nil {
+			return 0, fmt.Errorf("failed to fold match %s: %w", matches[i].ID, err)
+		}
+	}
+
+	return len(matches), nil
+}
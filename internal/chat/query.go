@@ -0,0 +1,257 @@
+// Package chat provides an IRCv3 CHATHISTORY-inspired query API over the
+// chat_messages table (populated by the SayText2 handler), so downstream
+// tools can page through a match's chat log with a typed Selector instead
+// of ad-hoc SQL - the same shape internal/httpapi already applies to
+// events (db.EventQuery) and positions (db.PositionQuery).
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// Selector picks which slice of a match's chat history Query returns,
+// modeled on IRCv3's CHATHISTORY command selectors.
+type Selector interface {
+	isSelector()
+}
+
+// Latest selects the N most recent messages.
+type Latest struct{ N int }
+
+// Before selects up to N messages strictly before Tick.
+type Before struct {
+	Tick int
+	N    int
+}
+
+// After selects up to N messages strictly after Tick.
+type After struct {
+	Tick int
+	N    int
+}
+
+// Between selects up to N messages with FromTick <= tick <= ToTick.
+type Between struct {
+	FromTick int
+	ToTick   int
+	N        int
+}
+
+// Around selects up to N messages centered on Tick - as evenly split
+// before/after as N allows, with Tick itself counted on the "before" side.
+type Around struct {
+	Tick int
+	N    int
+}
+
+func (Latest) isSelector()  {}
+func (Before) isSelector()  {}
+func (After) isSelector()   {}
+func (Between) isSelector() {}
+func (Around) isSelector()  {}
+
+// Filters narrows a Query beyond what Selector alone picks.
+type Filters struct {
+	// SteamID restricts results to messages from one player. Nil means no
+	// restriction.
+	SteamID *string
+	// TeamOnly restricts results to team-chat messages. false includes
+	// both all-chat and team chat - unlike db.Reader.GetChatMessages,
+	// which always excludes team chat.
+	TeamOnly bool
+	// RoundIndex restricts results to one round. Nil means no restriction.
+	RoundIndex *int
+	// SubstringMatch, if non-empty, restricts results to messages
+	// containing it (case-sensitive).
+	SubstringMatch string
+}
+
+// Cursor identifies a point in a match's chat history: Tick plus Seq (the
+// message row's SQLite rowid) to break ties between messages landing on
+// the same tick, since demoinfocs can deliver several chat lines per tick.
+//
+// Before/After/Between only bound on Tick (matching the selectors'
+// shape), so paging across a tick with more than one message can split
+// that tick's messages unevenly across two pages; Seq is exposed so a
+// caller that needs exact continuation can dedupe across that boundary
+// itself.
+type Cursor struct {
+	Tick int
+	Seq  int64
+}
+
+// Query runs selector (narrowed by filters) against matchID's chat
+// history, returning messages in ascending tick (then Seq) order and a
+// Cursor for the last message returned - the zero Cursor if nothing
+// matched.
+func Query(ctx context.Context, sqlDB *sql.DB, matchID string, selector Selector, filters Filters) ([]db.ChatMessage, Cursor, error) {
+	where, args := filterClause(matchID, filters)
+
+	var rows []chatRow
+	var err error
+	switch sel := selector.(type) {
+	case Latest:
+		rows, err = queryPage(ctx, sqlDB, where, args, "", nil, sel.N, true)
+	case Before:
+		rows, err = queryPage(ctx, sqlDB, where, args, "tick < ?", []interface{}{sel.Tick}, sel.N, true)
+	case After:
+		rows, err = queryPage(ctx, sqlDB, where, args, "tick > ?", []interface{}{sel.Tick}, sel.N, false)
+	case Between:
+		rows, err = queryPage(ctx, sqlDB, where, args, "tick BETWEEN ? AND ?", []interface{}{sel.FromTick, sel.ToTick}, sel.N, false)
+	case Around:
+		rows, err = queryAround(ctx, sqlDB, where, args, sel.Tick, sel.N)
+	default:
+		return nil, Cursor{}, fmt.Errorf("chat: unsupported selector %T", selector)
+	}
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].msg.Tick != rows[j].msg.Tick {
+			return rows[i].msg.Tick < rows[j].msg.Tick
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	messages := make([]db.ChatMessage, len(rows))
+	for i, r := range rows {
+		messages[i] = r.msg
+	}
+
+	var cursor Cursor
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		cursor = Cursor{Tick: last.msg.Tick, Seq: last.seq}
+	}
+	return messages, cursor, nil
+}
+
+// chatRow is a scanned chat_messages row plus its rowid (Cursor.Seq).
+type chatRow struct {
+	msg db.ChatMessage
+	seq int64
+}
+
+// filterClause builds the Filters portion of the WHERE clause shared by
+// every Selector.
+func filterClause(matchID string, f Filters) (string, []interface{}) {
+	clause := "match_id = ?"
+	args := []interface{}{matchID}
+
+	if f.TeamOnly {
+		clause += " AND is_team_chat = 1"
+	}
+	if f.SteamID != nil {
+		clause += " AND steamid = ?"
+		args = append(args, *f.SteamID)
+	}
+	if f.RoundIndex != nil {
+		clause += " AND round_index = ?"
+		args = append(args, *f.RoundIndex)
+	}
+	if f.SubstringMatch != "" {
+		clause += ` AND message LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(f.SubstringMatch)+"%")
+	}
+	return clause, args
+}
+
+// escapeLike escapes LIKE's own wildcard characters in a substring the
+// caller meant literally.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return r.Replace(s)
+}
+
+// queryPage runs one bounded query: where/whereArgs is the Filters
+// clause, extraWhere/extraArgs is the selector's own tick bound (empty
+// for Latest), LIMIT n. mostRecentFirst orders by tick/rowid descending
+// (so LIMIT keeps the n rows closest to the boundary) for Latest/Before;
+// After/Between order ascending. Either way, Query re-sorts ascending
+// before returning, so callers never see query order leak through.
+func queryPage(ctx context.Context, sqlDB *sql.DB, where string, whereArgs []interface{}, extraWhere string, extraArgs []interface{}, n int, mostRecentFirst bool) ([]chatRow, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	clause := where
+	args := append([]interface{}{}, whereArgs...)
+	if extraWhere != "" {
+		clause += " AND " + extraWhere
+		args = append(args, extraArgs...)
+	}
+
+	order := "tick ASC, rowid ASC"
+	if mostRecentFirst {
+		order = "tick DESC, rowid DESC"
+	}
+	args = append(args, n)
+
+	query := fmt.Sprintf(`
+		SELECT rowid, match_id, round_index, tick, steamid, name, team, message, is_team_chat
+		FROM chat_messages
+		WHERE %s
+		ORDER BY %s
+		LIMIT ?
+	`, clause, order)
+
+	rows, err := sqlDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("chat: query: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRows(rows)
+}
+
+// queryAround implements Around by splitting N across a Before-style
+// query (tick <= Tick, so Tick's own messages are included) and an
+// After-style query (tick > Tick).
+func queryAround(ctx context.Context, sqlDB *sql.DB, where string, whereArgs []interface{}, tick, n int) ([]chatRow, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	afterN := n / 2
+	beforeN := n - afterN
+
+	before, err := queryPage(ctx, sqlDB, where, whereArgs, "tick <= ?", []interface{}{tick}, beforeN, true)
+	if err != nil {
+		return nil, err
+	}
+	after, err := queryPage(ctx, sqlDB, where, whereArgs, "tick > ?", []interface{}{tick}, afterN, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(before, after...), nil
+}
+
+func scanRows(rows *sql.Rows) ([]chatRow, error) {
+	var out []chatRow
+	for rows.Next() {
+		var r chatRow
+		var isTeamChat int
+		var name, team sql.NullString
+		if err := rows.Scan(&r.seq, &r.msg.MatchID, &r.msg.RoundIndex, &r.msg.Tick, &r.msg.SteamID, &name, &team, &r.msg.Message, &isTeamChat); err != nil {
+			return nil, fmt.Errorf("chat: scan: %w", err)
+		}
+		r.msg.IsTeamChat = isTeamChat == 1
+		if name.Valid {
+			r.msg.Name = &name.String
+		}
+		if team.Valid {
+			r.msg.Team = &team.String
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("chat: iterate rows: %w", err)
+	}
+	return out, nil
+}
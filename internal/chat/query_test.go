@@ -0,0 +1,171 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"cs-griefer-electron/internal/db"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	sqlDB, err := db.Open(context.Background(), filepath.Join(t.TempDir(), "chat.db"))
+	if err != nil {
+		t.Fatalf("db.Open failed: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	writer := db.NewWriter(sqlDB)
+	if err := writer.InsertMatch(context.Background(), db.Match{ID: "m1", Map: "de_dust2", TickRate: 64}); err != nil {
+		t.Fatalf("InsertMatch failed: %v", err)
+	}
+	for _, steamID := range []string{"1", "111", "222"} {
+		if err := writer.InsertPlayer(context.Background(), db.Player{MatchID: "m1", SteamID: steamID, Name: steamID, Team: "A"}); err != nil {
+			t.Fatalf("InsertPlayer failed: %v", err)
+		}
+	}
+	return sqlDB
+}
+
+func seedMessages(t *testing.T, sqlDB *sql.DB, msgs []db.ChatMessage) {
+	t.Helper()
+	if err := db.NewWriter(sqlDB).InsertChatMessages(context.Background(), msgs); err != nil {
+		t.Fatalf("InsertChatMessages failed: %v", err)
+	}
+}
+
+func ticks(msgs []db.ChatMessage) []int {
+	out := make([]int, len(msgs))
+	for i, m := range msgs {
+		out[i] = m.Tick
+	}
+	return out
+}
+
+func TestQueryLatest(t *testing.T) {
+	sqlDB := testDB(t)
+	seedMessages(t, sqlDB, []db.ChatMessage{
+		{MatchID: "m1", Tick: 100, SteamID: "1", Message: "a"},
+		{MatchID: "m1", Tick: 200, SteamID: "1", Message: "b"},
+		{MatchID: "m1", Tick: 300, SteamID: "1", Message: "c"},
+	})
+
+	msgs, cursor, err := Query(context.Background(), sqlDB, "m1", Latest{N: 2}, Filters{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got, want := ticks(msgs), []int{200, 300}; !equalInts(got, want) {
+		t.Errorf("got ticks %v, want %v", got, want)
+	}
+	if cursor.Tick != 300 {
+		t.Errorf("got cursor tick %d, want 300", cursor.Tick)
+	}
+}
+
+func TestQueryBeforeAndAfter(t *testing.T) {
+	sqlDB := testDB(t)
+	seedMessages(t, sqlDB, []db.ChatMessage{
+		{MatchID: "m1", Tick: 100, SteamID: "1", Message: "a"},
+		{MatchID: "m1", Tick: 200, SteamID: "1", Message: "b"},
+		{MatchID: "m1", Tick: 300, SteamID: "1", Message: "c"},
+	})
+
+	before, _, err := Query(context.Background(), sqlDB, "m1", Before{Tick: 300, N: 10}, Filters{})
+	if err != nil {
+		t.Fatalf("Query (Before) failed: %v", err)
+	}
+	if got, want := ticks(before), []int{100, 200}; !equalInts(got, want) {
+		t.Errorf("Before: got ticks %v, want %v", got, want)
+	}
+
+	after, _, err := Query(context.Background(), sqlDB, "m1", After{Tick: 100, N: 10}, Filters{})
+	if err != nil {
+		t.Fatalf("Query (After) failed: %v", err)
+	}
+	if got, want := ticks(after), []int{200, 300}; !equalInts(got, want) {
+		t.Errorf("After: got ticks %v, want %v", got, want)
+	}
+}
+
+func TestQueryBetween(t *testing.T) {
+	sqlDB := testDB(t)
+	seedMessages(t, sqlDB, []db.ChatMessage{
+		{MatchID: "m1", Tick: 100, SteamID: "1", Message: "a"},
+		{MatchID: "m1", Tick: 200, SteamID: "1", Message: "b"},
+		{MatchID: "m1", Tick: 300, SteamID: "1", Message: "c"},
+	})
+
+	msgs, _, err := Query(context.Background(), sqlDB, "m1", Between{FromTick: 150, ToTick: 300, N: 10}, Filters{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got, want := ticks(msgs), []int{200, 300}; !equalInts(got, want) {
+		t.Errorf("got ticks %v, want %v", got, want)
+	}
+}
+
+func TestQueryAround(t *testing.T) {
+	sqlDB := testDB(t)
+	seedMessages(t, sqlDB, []db.ChatMessage{
+		{MatchID: "m1", Tick: 100, SteamID: "1", Message: "a"},
+		{MatchID: "m1", Tick: 200, SteamID: "1", Message: "b"},
+		{MatchID: "m1", Tick: 300, SteamID: "1", Message: "c"},
+		{MatchID: "m1", Tick: 400, SteamID: "1", Message: "d"},
+	})
+
+	msgs, _, err := Query(context.Background(), sqlDB, "m1", Around{Tick: 200, N: 3}, Filters{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got, want := ticks(msgs), []int{100, 200, 300}; !equalInts(got, want) {
+		t.Errorf("got ticks %v, want %v", got, want)
+	}
+}
+
+func TestQueryFilters(t *testing.T) {
+	sqlDB := testDB(t)
+	steamA, steamB := "111", "222"
+	seedMessages(t, sqlDB, []db.ChatMessage{
+		{MatchID: "m1", Tick: 100, SteamID: steamA, Message: "hello world", IsTeamChat: false},
+		{MatchID: "m1", Tick: 200, SteamID: steamB, Message: "rush b", IsTeamChat: true},
+		{MatchID: "m1", Tick: 300, SteamID: steamA, Message: "nice shot", IsTeamChat: false},
+	})
+
+	bySteam, _, err := Query(context.Background(), sqlDB, "m1", Latest{N: 10}, Filters{SteamID: &steamA})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got, want := ticks(bySteam), []int{100, 300}; !equalInts(got, want) {
+		t.Errorf("SteamID filter: got ticks %v, want %v", got, want)
+	}
+
+	teamChat, _, err := Query(context.Background(), sqlDB, "m1", Latest{N: 10}, Filters{TeamOnly: true})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got, want := ticks(teamChat), []int{200}; !equalInts(got, want) {
+		t.Errorf("TeamOnly filter: got ticks %v, want %v", got, want)
+	}
+
+	matched, _, err := Query(context.Background(), sqlDB, "m1", Latest{N: 10}, Filters{SubstringMatch: "rush"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got, want := ticks(matched), []int{200}; !equalInts(got, want) {
+		t.Errorf("SubstringMatch filter: got ticks %v, want %v", got, want)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BulkInserterConfig controls buffering and flush cadence for a
+// BulkInserter. BufferSize is the number of rows accumulated per table
+// before an automatic flush; FlushInterval is a time-based fallback so
+// low-volume tables (grenade positions, shots) don't sit buffered forever
+// on a quiet demo. HeapThresholdMB, when non-zero, lets a caller (the
+// parser's MemoryLogger) force an out-of-band flush when heap usage
+// crosses a limit instead of waiting on size or time.
+type BulkInserterConfig struct {
+	BufferSize      int
+	FlushInterval   time.Duration
+	HeapThresholdMB uint64
+}
+
+// DefaultBulkInserterConfig matches the row counts the streaming writer
+// already used for its position/shot buffers.
+func DefaultBulkInserterConfig() BulkInserterConfig {
+	return BulkInserterConfig{
+		BufferSize:    5000,
+		FlushInterval: 5 * time.Second,
+	}
+}
+
+// BulkInserter batches player_positions, grenade_positions and shots rows
+// into per-table buffers and flushes them in bulk, instead of the
+// per-row INSERT statements the rest of Writer uses. On SQLite it runs a
+// single transaction with a prepared INSERT per flush; on Postgres it
+// uses pq.CopyIn, which is an order of magnitude faster than individual
+// INSERTs for the high-volume tables a 128-tick demo produces.
+type BulkInserter struct {
+	db      *sql.DB
+	backend Backend
+	cfg     BulkInserterConfig
+
+	mu         sync.Mutex
+	positions  []PlayerPosition
+	grenadePos []GrenadePosition
+	shots      []Shot
+	lastFlush  time.Time
+}
+
+// NewBulkInserter creates a BulkInserter over an already schema-initialized
+// *sql.DB for the given backend.
+func NewBulkInserter(db *sql.DB, backend Backend, cfg BulkInserterConfig) *BulkInserter {
+	return &BulkInserter{
+		db:        db,
+		backend:   backend,
+		cfg:       cfg,
+		lastFlush: time.Now(),
+	}
+}
+
+// AddPositions appends player positions to the buffer, flushing if the
+// buffer size or flush interval has been exceeded.
+func (b *BulkInserter) AddPositions(ctx context.Context, positions ...PlayerPosition) error {
+	b.mu.Lock()
+	b.positions = append(b.positions, positions...)
+	full := len(b.positions) >= b.cfg.BufferSize
+	b.mu.Unlock()
+
+	if full || b.dueForTimeFlush() {
+		return b.FlushPositions(ctx)
+	}
+	return nil
+}
+
+// AddGrenadePositions appends grenade positions to the buffer.
+func (b *BulkInserter) AddGrenadePositions(ctx context.Context, positions ...GrenadePosition) error {
+	b.mu.Lock()
+	b.grenadePos = append(b.grenadePos, positions...)
+	full := len(b.grenadePos) >= b.cfg.BufferSize
+	b.mu.Unlock()
+
+	if full || b.dueForTimeFlush() {
+		return b.FlushGrenadePositions(ctx)
+	}
+	return nil
+}
+
+// AddShots appends shots to the buffer.
+func (b *BulkInserter) AddShots(ctx context.Context, shots ...Shot) error {
+	b.mu.Lock()
+	b.shots = append(b.shots, shots...)
+	full := len(b.shots) >= b.cfg.BufferSize
+	b.mu.Unlock()
+
+	if full || b.dueForTimeFlush() {
+		return b.FlushShots(ctx)
+	}
+	return nil
+}
+
+func (b *BulkInserter) dueForTimeFlush() bool {
+	if b.cfg.FlushInterval <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Since(b.lastFlush) >= b.cfg.FlushInterval
+}
+
+// FlushIfHeapAbove forces a flush of every buffer when currentHeapMB
+// crosses HeapThresholdMB. Wired to the parser's MemoryLogger so a demo
+// with millions of position rows doesn't grow the in-process buffers
+// without bound.
+func (b *BulkInserter) FlushIfHeapAbove(ctx context.Context, currentHeapMB uint64) error {
+	if b.cfg.HeapThresholdMB == 0 || currentHeapMB < b.cfg.HeapThresholdMB {
+		return nil
+	}
+	return b.FlushAll(ctx)
+}
+
+// FlushAll flushes every buffered table.
+func (b *BulkInserter) FlushAll(ctx context.Context) error {
+	if err := b.FlushPositions(ctx); err != nil {
+		return err
+	}
+	if err := b.FlushGrenadePositions(ctx); err != nil {
+		return err
+	}
+	return b.FlushShots(ctx)
+}
+
+// FlushPositions writes and clears the buffered player positions.
+func (b *BulkInserter) FlushPositions(ctx context.Context) error {
+	b.mu.Lock()
+	rows := b.positions
+	b.positions = nil
+	b.lastFlush = time.Now()
+	b.mu.Unlock()
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if b.backend == BackendPostgres {
+		return b.copyPositions(ctx, rows)
+	}
+	return NewWriter(b.db).InsertPlayerPositions(ctx, rows)
+}
+
+// FlushGrenadePositions writes and clears the buffered grenade positions.
+func (b *BulkInserter) FlushGrenadePositions(ctx context.Context) error {
+	b.mu.Lock()
+	rows := b.grenadePos
+	b.grenadePos = nil
+	b.lastFlush = time.Now()
+	b.mu.Unlock()
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if b.backend == BackendPostgres {
+		return b.copyGrenadePositions(ctx, rows)
+	}
+	return NewWriter(b.db).InsertGrenadePositions(ctx, rows)
+}
+
+// FlushShots writes and clears the buffered shots.
+func (b *BulkInserter) FlushShots(ctx context.Context) error {
+	b.mu.Lock()
+	rows := b.shots
+	b.shots = nil
+	b.lastFlush = time.Now()
+	b.mu.Unlock()
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if b.backend == BackendPostgres {
+		return b.copyShots(ctx, rows)
+	}
+	return NewWriter(b.db).InsertShots(ctx, rows)
+}
+
+// copyPositions uses pq.CopyIn inside a transaction to bulk-load
+// player_positions, which is dramatically faster than per-row INSERTs on
+// Postgres for the millions of rows a long demo produces.
+func (b *BulkInserter) copyPositions(ctx context.Context, rows []PlayerPosition) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("player_positions",
+		"match_id", "round_index", "tick", "steamid", "x", "y", "z",
+		"yaw", "team", "health", "armor", "weapon",
+	))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy-in statement: %w", err)
+	}
+
+	for _, pos := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			pos.MatchID, pos.RoundIndex, pos.Tick, pos.SteamID, pos.X, pos.Y, pos.Z,
+			pos.Yaw, pos.Team, pos.Health, pos.Armor, pos.Weapon,
+		); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy player position: %w", err)
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to finalize copy-in: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to close copy-in statement: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// copyGrenadePositions bulk-loads grenade_positions via pq.CopyIn.
+func (b *BulkInserter) copyGrenadePositions(ctx context.Context, rows []GrenadePosition) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("grenade_positions",
+		"match_id", "round_index", "tick", "projectile_id", "grenade_name",
+		"x", "y", "z", "thrower_steamid", "thrower_name", "thrower_team",
+	))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy-in statement: %w", err)
+	}
+
+	for _, pos := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			pos.MatchID, pos.RoundIndex, pos.Tick, int64(pos.ProjectileID), pos.GrenadeName,
+			pos.X, pos.Y, pos.Z, pos.ThrowerSteamID, pos.ThrowerName, pos.ThrowerTeam,
+		); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy grenade position: %w", err)
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to finalize copy-in: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to close copy-in statement: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// copyShots bulk-loads shots via pq.CopyIn.
+func (b *BulkInserter) copyShots(ctx context.Context, rows []Shot) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("shots",
+		"match_id", "round_index", "tick", "steamid", "weapon_name",
+		"x", "y", "z", "yaw", "pitch", "team",
+	))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy-in statement: %w", err)
+	}
+
+	for _, shot := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			shot.MatchID, shot.RoundIndex, shot.Tick, shot.SteamID, shot.WeaponName,
+			shot.X, shot.Y, shot.Z, shot.Yaw, shot.Pitch, shot.Team,
+		); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy shot: %w", err)
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to finalize copy-in: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to close copy-in statement: %w", err)
+	}
+
+	return tx.Commit()
+}
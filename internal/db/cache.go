@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"cs-griefer-electron/internal/rediscache"
+)
+
+// CachedReader wraps a Reader with a rediscache.Client so hot per-match
+// reads (player scores, rounds, event counts) served repeatedly to API
+// consumers hit Redis instead of re-querying SQLite/Postgres every time.
+// A nil cache (e.g. a rediscache.Client built from an unset --cache-url)
+// makes every method behave exactly like calling the underlying Reader
+// directly - rediscache.Client's methods are themselves no-ops on a nil
+// receiver, so CachedReader doesn't need its own nil-cache branches.
+type CachedReader struct {
+	*Reader
+	cache *rediscache.Client
+	ttl   time.Duration
+}
+
+// NewCachedReader wraps reader with cache, caching each hot read for ttl.
+func NewCachedReader(reader *Reader, cache *rediscache.Client, ttl time.Duration) *CachedReader {
+	return &CachedReader{Reader: reader, cache: cache, ttl: ttl}
+}
+
+// GetPlayerScores is like Reader.GetPlayerScores but serves (and
+// populates) a "match:{matchID}:scores" cache entry.
+func (c *CachedReader) GetPlayerScores(ctx context.Context, matchID string) ([]PlayerScore, error) {
+	key := "match:" + matchID + ":scores"
+	if cached, ok, err := c.cache.Get(key); err == nil && ok {
+		var scores []PlayerScore
+		if json.Unmarshal(cached, &scores) == nil {
+			return scores, nil
+		}
+	}
+
+	scores, err := c.Reader.GetPlayerScores(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(scores); err == nil {
+		_ = c.cache.Set(key, encoded, c.ttl)
+	}
+	return scores, nil
+}
+
+// GetPlayerScoresByProfile is like Reader.GetPlayerScoresByProfile but
+// serves (and populates) a "match:{matchID}:scores:{profileName}" cache
+// entry.
+func (c *CachedReader) GetPlayerScoresByProfile(ctx context.Context, matchID, profileName string) ([]PlayerScore, error) {
+	key := "match:" + matchID + ":scores:" + profileName
+	if cached, ok, err := c.cache.Get(key); err == nil && ok {
+		var scores []PlayerScore
+		if json.Unmarshal(cached, &scores) == nil {
+			return scores, nil
+		}
+	}
+
+	scores, err := c.Reader.GetPlayerScoresByProfile(ctx, matchID, profileName)
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(scores); err == nil {
+		_ = c.cache.Set(key, encoded, c.ttl)
+	}
+	return scores, nil
+}
+
+// GetRounds is like Reader.GetRounds but serves (and populates) a
+// "match:{matchID}:rounds" cache entry.
+func (c *CachedReader) GetRounds(ctx context.Context, matchID string) ([]Round, error) {
+	key := "match:" + matchID + ":rounds"
+	if cached, ok, err := c.cache.Get(key); err == nil && ok {
+		var rounds []Round
+		if json.Unmarshal(cached, &rounds) == nil {
+			return rounds, nil
+		}
+	}
+
+	rounds, err := c.Reader.GetRounds(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(rounds); err == nil {
+		_ = c.cache.Set(key, encoded, c.ttl)
+	}
+	return rounds, nil
+}
+
+// GetEventCount is like len of Reader.GetEvents for matchID's full event
+// set, but serves (and populates) a "match:{matchID}:event_count" cache
+// entry instead of re-querying and re-scanning every row each time.
+func (c *CachedReader) GetEventCount(ctx context.Context, matchID string) (int, error) {
+	key := "match:" + matchID + ":event_count"
+	if cached, ok, err := c.cache.Get(key); err == nil && ok {
+		var count int
+		if json.Unmarshal(cached, &count) == nil {
+			return count, nil
+		}
+	}
+
+	events, err := c.Reader.GetEvents(ctx, EventQuery{MatchID: matchID})
+	if err != nil {
+		return 0, err
+	}
+	count := len(events)
+	if encoded, err := json.Marshal(count); err == nil {
+		_ = c.cache.Set(key, encoded, c.ttl)
+	}
+	return count, nil
+}
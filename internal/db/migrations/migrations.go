@@ -0,0 +1,104 @@
+// Package migrations implements a small, versioned schema-migration
+// runner for the db package. It replaces the previous approach of
+// checking `pragma_table_info` per column and issuing a bare ALTER
+// TABLE, which gave no ordering guarantees, no record of what had been
+// applied, and no rollback path.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Migration is a single, named, forward-only (optionally reversible)
+// schema change. ID must be stable and unique across the lifetime of the
+// database — it is recorded in schema_migrations once applied and never
+// re-run.
+type Migration struct {
+	ID string
+
+	// Up applies the migration inside an open transaction.
+	Up func(ctx context.Context, tx *sql.Tx) error
+
+	// Down reverses the migration, if supported. Optional.
+	Down func(ctx context.Context, tx *sql.Tx) error
+}
+
+// createMigrationsTable is run once before any migration so Migrate has
+// somewhere to record applied IDs. It uses the same schema on SQLite and
+// Postgres.
+const createMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	id TEXT PRIMARY KEY,
+	applied_at TEXT NOT NULL
+);
+`
+
+// Migrate applies every migration in order whose ID is not yet present in
+// schema_migrations. Each migration runs in its own transaction so a
+// failure partway through leaves already-applied migrations intact and
+// reports which one failed.
+func Migrate(ctx context.Context, db *sql.DB, all []Migration) error {
+	if _, err := db.ExecContext(ctx, createMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedIDs(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	for _, m := range all {
+		if applied[m.ID] {
+			continue
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("migration %s: failed to begin transaction: %w", m.ID, err)
+		}
+
+		if err := m.Up(ctx, tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (id, applied_at) VALUES (?, ?)`,
+			m.ID, nowRFC3339(),
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: failed to record applied: %w", m.ID, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %s: failed to commit: %w", m.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func appliedIDs(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+func nowRFC3339() string {
+	return time.Now().Format(time.RFC3339)
+}
@@ -0,0 +1,189 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+// All is the ordered list of migrations applied to the SQLite backend.
+// Postgres materializes its full schema (including these columns) up
+// front in postgresSchema, so it never runs this list. Both this list and
+// Migrate are SQLite-specific: the Up funcs query pragma_table_info, and
+// Migrate records applied IDs with ?-placeholder SQL, which lib/pq does
+// not accept.
+var All = []Migration{
+	{
+		ID: "0001_players_connected_midgame",
+		Up: addColumnIfMissing("players", "connected_midgame", "INTEGER DEFAULT 0"),
+	},
+	{
+		ID: "0002_players_permanent_disconnect",
+		Up: addColumnIfMissing("players", "permanent_disconnect", "INTEGER DEFAULT 0"),
+	},
+	{
+		ID: "0003_players_first_connect_round",
+		Up: addColumnIfMissing("players", "first_connect_round", "INTEGER"),
+	},
+	{
+		ID: "0004_players_disconnect_round",
+		Up: addColumnIfMissing("players", "disconnect_round", "INTEGER"),
+	},
+	{
+		ID: "0005_matches_source",
+		Up: addColumnIfMissing("matches", "source", "TEXT"),
+	},
+	{
+		ID: "0006_shots_weapon_name_index",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_shots_weapon_name ON shots(weapon_name)`)
+			return err
+		},
+	},
+	{
+		ID: "0007_deaths_table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `
+				CREATE TABLE IF NOT EXISTS deaths (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					match_id TEXT NOT NULL,
+					round_index INTEGER NOT NULL,
+					tick INTEGER NOT NULL,
+					victim_steamid TEXT NOT NULL,
+					attacker_steamid TEXT,
+					weapon_name TEXT,
+					headshot INTEGER DEFAULT 0
+				);
+				CREATE INDEX IF NOT EXISTS idx_deaths_match_round ON deaths(match_id, round_index);
+			`)
+			return err
+		},
+	},
+	{
+		ID: "0008_failed_demos_table",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `
+				CREATE TABLE IF NOT EXISTS failed_demos (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					demo_path TEXT NOT NULL,
+					error TEXT NOT NULL,
+					stack TEXT,
+					failed_at TEXT NOT NULL
+				);
+				CREATE INDEX IF NOT EXISTS idx_failed_demos_path ON failed_demos(demo_path);
+			`)
+			return err
+		},
+	},
+	{
+		ID: "0009_player_scores_economy_grief_count",
+		Up: addColumnIfMissing("player_scores", "economy_grief_count", "INTEGER NOT NULL DEFAULT 0"),
+	},
+	{
+		ID: "0010_player_scores_profile_name",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			var count int
+			query := `SELECT COUNT(*) FROM pragma_table_info('player_scores') WHERE name = ?`
+			if err := tx.QueryRowContext(ctx, query, "profile_name").Scan(&count); err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+			// profile_name joins the primary key (a match can now be
+			// rescored under several ScoringProfiles without each one
+			// clobbering the last), which SQLite can't add via a plain
+			// ALTER TABLE, so this rebuilds the table instead.
+			_, err := tx.ExecContext(ctx, `
+				ALTER TABLE player_scores RENAME TO player_scores_pre_profile_name;
+				CREATE TABLE player_scores (
+					match_id TEXT NOT NULL,
+					steamid TEXT NOT NULL,
+					profile_name TEXT NOT NULL DEFAULT 'default',
+					team_kills INTEGER NOT NULL DEFAULT 0,
+					team_damage REAL NOT NULL DEFAULT 0,
+					team_flash_seconds REAL NOT NULL DEFAULT 0,
+					afk_seconds REAL NOT NULL DEFAULT 0,
+					body_block_seconds REAL NOT NULL DEFAULT 0,
+					economy_grief_count INTEGER NOT NULL DEFAULT 0,
+					grief_score REAL NOT NULL DEFAULT 0,
+					PRIMARY KEY(match_id, steamid, profile_name),
+					FOREIGN KEY(match_id) REFERENCES matches(id)
+				);
+				INSERT INTO player_scores (
+					match_id, steamid, profile_name, team_kills, team_damage, team_flash_seconds,
+					afk_seconds, body_block_seconds, economy_grief_count, grief_score
+				)
+				SELECT match_id, steamid, 'default', team_kills, team_damage, team_flash_seconds,
+				       afk_seconds, body_block_seconds, economy_grief_count, grief_score
+				FROM player_scores_pre_profile_name;
+				DROP TABLE player_scores_pre_profile_name;
+				CREATE INDEX IF NOT EXISTS idx_player_scores_match ON player_scores(match_id);
+			`)
+			return err
+		},
+	},
+	{
+		ID: "0011_player_scores_top_reasons",
+		Up: addColumnIfMissing("player_scores", "top_reasons_json", "TEXT"),
+	},
+	{
+		ID: "0012_events_status",
+		Up: addColumnIfMissing("events", "status", "TEXT NOT NULL DEFAULT 'committed'"),
+	},
+	{
+		ID: "0013_events_attempt_count",
+		Up: addColumnIfMissing("events", "attempt_count", "INTEGER NOT NULL DEFAULT 0"),
+	},
+	{
+		ID: "0014_career_tables",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `
+				CREATE TABLE IF NOT EXISTS career_players (
+					steamid TEXT PRIMARY KEY,
+					matches_played INTEGER NOT NULL DEFAULT 0,
+					team_kills INTEGER NOT NULL DEFAULT 0,
+					team_damage REAL NOT NULL DEFAULT 0,
+					team_flash_seconds REAL NOT NULL DEFAULT 0,
+					afk_seconds REAL NOT NULL DEFAULT 0,
+					body_block_seconds REAL NOT NULL DEFAULT 0,
+					grief_score_ewma REAL NOT NULL DEFAULT 0,
+					first_seen TEXT,
+					last_seen TEXT
+				);
+				CREATE TABLE IF NOT EXISTS career_match_stats (
+					match_id TEXT NOT NULL,
+					steamid TEXT NOT NULL,
+					team_kills INTEGER NOT NULL DEFAULT 0,
+					team_damage REAL NOT NULL DEFAULT 0,
+					team_flash_seconds REAL NOT NULL DEFAULT 0,
+					afk_seconds REAL NOT NULL DEFAULT 0,
+					body_block_seconds REAL NOT NULL DEFAULT 0,
+					grief_score REAL NOT NULL DEFAULT 0,
+					PRIMARY KEY(match_id, steamid),
+					FOREIGN KEY(match_id) REFERENCES matches(id)
+				);
+				CREATE INDEX IF NOT EXISTS idx_career_match_stats_steamid ON career_match_stats(steamid);
+			`)
+			return err
+		},
+	},
+}
+
+// addColumnIfMissing returns an Up func that adds a column to table only
+// if pragma_table_info doesn't already report it, so re-running against
+// a database created after the column was folded into the base schema is
+// a no-op rather than a "duplicate column" error.
+func addColumnIfMissing(table, column, ddlType string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		var count int
+		query := `SELECT COUNT(*) FROM pragma_table_info('` + table + `') WHERE name = ?`
+		if err := tx.QueryRowContext(ctx, query, column).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+column+` `+ddlType)
+		return err
+	}
+}
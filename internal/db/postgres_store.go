@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// PostgresStore is a Store implementation backed by PostgreSQL, for teams
+// that want several analyzer workers ingesting into one shared database
+// instead of each producing a standalone SQLite file. It holds a single
+// long-lived *sql.DB and serializes bulk-insert transactions behind a
+// write mutex, matching the pattern used by other tabular ingestion
+// services that share one pooled connection across many writers.
+type PostgresStore struct {
+	db *sql.DB
+
+	mu sync.Mutex // guards bulk insert transactions
+}
+
+// NewPostgresStore creates a PostgresStore over an already-open *sql.DB
+// (see OpenPostgres).
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Init creates the schema tables for every table this store writes to, if
+// they don't already exist.
+func (s *PostgresStore) Init(ctx context.Context) error {
+	return s.setup(ctx)
+}
+
+// setup materializes the translated schema. Kept as a single pass over the
+// DDL rather than one call per table since every statement is already
+// idempotent (CREATE TABLE/INDEX IF NOT EXISTS).
+func (s *PostgresStore) setup(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, postgresSchema); err != nil {
+		return fmt.Errorf("failed to initialize postgres schema: %w", err)
+	}
+	return nil
+}
+
+// InsertEvents persists a batch of extractor events for a match.
+func (s *PostgresStore) InsertEvents(ctx context.Context, matchID string, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO events (
+			match_id, round_index, type, start_tick, end_tick,
+			actor_steamid, victim_steamid, severity, confidence, meta_json
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range events {
+		if e.MatchID == "" {
+			e.MatchID = matchID
+		}
+		_, err := stmt.ExecContext(ctx,
+			e.MatchID, e.RoundIndex, e.Type, e.StartTick, e.EndTick,
+			e.ActorSteamID, e.VictimSteamID, e.Severity, e.Confidence, e.MetaJSON,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// InsertPositions persists a batch of player position samples for a match.
+func (s *PostgresStore) InsertPositions(ctx context.Context, matchID string, positions []PlayerPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO player_positions (
+			match_id, round_index, tick, steamid, x, y, z, yaw, team, health, armor, weapon
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (match_id, round_index, tick, steamid) DO UPDATE SET
+			x = EXCLUDED.x, y = EXCLUDED.y, z = EXCLUDED.z, yaw = EXCLUDED.yaw,
+			team = EXCLUDED.team, health = EXCLUDED.health, armor = EXCLUDED.armor, weapon = EXCLUDED.weapon
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, pos := range positions {
+		if pos.MatchID == "" {
+			pos.MatchID = matchID
+		}
+		_, err := stmt.ExecContext(ctx,
+			pos.MatchID, pos.RoundIndex, pos.Tick, pos.SteamID, pos.X, pos.Y, pos.Z, pos.Yaw, pos.Team,
+			pos.Health, pos.Armor, pos.Weapon,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert player position: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Flush is a no-op: every Insert* call above commits its own transaction.
+// It exists to satisfy the Store interface for callers that buffer writes
+// in front of the backend.
+func (s *PostgresStore) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close closes the underlying database connection.
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
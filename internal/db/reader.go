@@ -3,7 +3,10 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 )
 
 // Reader provides methods to read CS2 demo data from the database.
@@ -18,14 +21,36 @@ func NewReader(db *sql.DB) *Reader {
 
 // PlayerScore represents a player's griefing score.
 type PlayerScore struct {
-	MatchID          string
-	SteamID          string
-	TeamKills        int
-	TeamDamage       float64
-	TeamFlashSeconds float64
-	AFKSeconds       float64
-	BodyBlockSeconds float64
-	GriefScore       float64
+	MatchID string
+	SteamID string
+	// ProfileName is the scoring.ScoringProfile.Name that produced this
+	// row. A match can be rescored under several profiles (see the
+	// --rescore flag) without each one overwriting the last, since
+	// profile_name is part of player_scores' primary key.
+	ProfileName       string
+	TeamKills         int
+	TeamDamage        float64
+	TeamFlashSeconds  float64
+	AFKSeconds        float64
+	BodyBlockSeconds  float64
+	EconomyGriefCount int
+	GriefScore        float64
+	// TopReasons is the 3 most frequent extractors.ReasonCode values across
+	// this player's events (most frequent first), so a UI can show "why" a
+	// player scored the way they did without fetching and counting every
+	// event itself. Empty if the scorer ran before this field existed, or
+	// if the player had no reason-coded events.
+	TopReasons []ReasonCount
+}
+
+// ReasonCount is one entry in PlayerScore.TopReasons: a reason code (see
+// extractors.ReasonCode), its human-readable text (captured at scoring
+// time so a reader doesn't need to import the extractors package just to
+// decode it), and how many of the player's events carried that code.
+type ReasonCount struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
 }
 
 // MatchSummary represents a match summary with players and scores.
@@ -36,20 +61,29 @@ type MatchSummary struct {
 
 // EventQuery represents query parameters for events.
 type EventQuery struct {
-	MatchID  string
-	Type     *string
-	SteamID  *string
-	Round    *int
+	MatchID string
+	Type    *string
+	SteamID *string
+	Round   *int
+
+	// Limit and Offset page through a match's events (e.g. for an HTTP API
+	// that can't hold every event for a long match in one response). Limit
+	// <= 0 means no limit, matching every other optional EventQuery field's
+	// "unset" convention.
+	Limit  int
+	Offset int
 }
 
-// GetPlayerScores retrieves all player scores for a match.
+// GetPlayerScores retrieves all player scores for a match, across every
+// profile it's been (re)scored under - see GetPlayerScoresByProfile to
+// restrict to one.
 func (r *Reader) GetPlayerScores(ctx context.Context, matchID string) ([]PlayerScore, error) {
 	query := `
-		SELECT match_id, steamid, team_kills, team_damage, team_flash_seconds,
-		       afk_seconds, body_block_seconds, grief_score
+		SELECT match_id, steamid, profile_name, team_kills, team_damage, team_flash_seconds,
+		       afk_seconds, body_block_seconds, economy_grief_count, grief_score, top_reasons_json
 		FROM player_scores
 		WHERE match_id = ?
-		ORDER BY grief_score DESC
+		ORDER BY profile_name, grief_score DESC
 	`
 	rows, err := r.db.QueryContext(ctx, query, matchID)
 	if err != nil {
@@ -59,14 +93,9 @@ func (r *Reader) GetPlayerScores(ctx context.Context, matchID string) ([]PlayerS
 
 	scores := make([]PlayerScore, 0)
 	for rows.Next() {
-		var score PlayerScore
-		err := rows.Scan(
-			&score.MatchID, &score.SteamID, &score.TeamKills, &score.TeamDamage,
-			&score.TeamFlashSeconds, &score.AFKSeconds, &score.BodyBlockSeconds,
-			&score.GriefScore,
-		)
+		score, err := scanPlayerScore(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan player score: %w", err)
+			return nil, err
 		}
 		scores = append(scores, score)
 	}
@@ -78,6 +107,127 @@ func (r *Reader) GetPlayerScores(ctx context.Context, matchID string) ([]PlayerS
 	return scores, nil
 }
 
+// scanPlayerScore scans one player_scores row, including the
+// top_reasons_json column shared by every query that selects it in the
+// same column order as GetPlayerScores.
+func scanPlayerScore(rows *sql.Rows) (PlayerScore, error) {
+	var score PlayerScore
+	var topReasonsJSON sql.NullString
+	err := rows.Scan(
+		&score.MatchID, &score.SteamID, &score.ProfileName, &score.TeamKills, &score.TeamDamage,
+		&score.TeamFlashSeconds, &score.AFKSeconds, &score.BodyBlockSeconds,
+		&score.EconomyGriefCount, &score.GriefScore, &topReasonsJSON,
+	)
+	if err != nil {
+		return PlayerScore{}, fmt.Errorf("failed to scan player score: %w", err)
+	}
+	if topReasonsJSON.Valid && topReasonsJSON.String != "" {
+		if err := json.Unmarshal([]byte(topReasonsJSON.String), &score.TopReasons); err != nil {
+			return PlayerScore{}, fmt.Errorf("failed to unmarshal top_reasons_json: %w", err)
+		}
+	}
+	return score, nil
+}
+
+// GetPlayerScoresByProfile is like GetPlayerScores but restricted to the
+// rows written under one scoring.ScoringProfile.Name, for callers (like
+// the HTTP API's ?profile= query param) that want one score per player
+// instead of one per (player, profile) pair.
+func (r *Reader) GetPlayerScoresByProfile(ctx context.Context, matchID, profileName string) ([]PlayerScore, error) {
+	query := `
+		SELECT match_id, steamid, profile_name, team_kills, team_damage, team_flash_seconds,
+		       afk_seconds, body_block_seconds, economy_grief_count, grief_score, top_reasons_json
+		FROM player_scores
+		WHERE match_id = ? AND profile_name = ?
+		ORDER BY grief_score DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, matchID, profileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query player scores: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make([]PlayerScore, 0)
+	for rows.Next() {
+		score, err := scanPlayerScore(rows)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player scores: %w", err)
+	}
+
+	return scores, nil
+}
+
+// GetPlayerScore retrieves one player's score in matchID, optionally
+// restricted to profileName (empty means "default", matching
+// scoring.DefaultProfile.Name), for an HTTP API's
+// "/matches/{id}/players/{steamid}/scores" endpoint where the caller
+// already knows which player it wants instead of scanning GetPlayerScores'
+// full match list.
+func (r *Reader) GetPlayerScore(ctx context.Context, matchID, steamID, profileName string) (PlayerScore, error) {
+	if profileName == "" {
+		profileName = "default"
+	}
+	query := `
+		SELECT match_id, steamid, profile_name, team_kills, team_damage, team_flash_seconds,
+		       afk_seconds, body_block_seconds, economy_grief_count, grief_score, top_reasons_json
+		FROM player_scores
+		WHERE match_id = ? AND steamid = ? AND profile_name = ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, matchID, steamID, profileName)
+	if err != nil {
+		return PlayerScore{}, fmt.Errorf("failed to query player score: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return PlayerScore{}, fmt.Errorf("error iterating player score: %w", err)
+		}
+		return PlayerScore{}, fmt.Errorf("no score for match %s player %s profile %s: %w", matchID, steamID, profileName, sql.ErrNoRows)
+	}
+	return scanPlayerScore(rows)
+}
+
+// GetPlayerScoreHistory retrieves steamID's player_scores row from every
+// match that has one, most recent match first, for an HTTP API's
+// "/players/{steamid}/history" endpoint.
+func (r *Reader) GetPlayerScoreHistory(ctx context.Context, steamID string) ([]PlayerScore, error) {
+	query := `
+		SELECT ps.match_id, ps.steamid, ps.profile_name, ps.team_kills, ps.team_damage, ps.team_flash_seconds,
+		       ps.afk_seconds, ps.body_block_seconds, ps.economy_grief_count, ps.grief_score, ps.top_reasons_json
+		FROM player_scores ps
+		JOIN matches m ON m.id = ps.match_id
+		WHERE ps.steamid = ?
+		ORDER BY m.started_at DESC, ps.match_id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, steamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query player score history: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make([]PlayerScore, 0)
+	for rows.Next() {
+		score, err := scanPlayerScore(rows)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player score history: %w", err)
+	}
+
+	return scores, nil
+}
+
 // GetEvents retrieves events matching the query parameters.
 func (r *Reader) GetEvents(ctx context.Context, q EventQuery) ([]Event, error) {
 	query := `
@@ -105,6 +255,15 @@ func (r *Reader) GetEvents(ctx context.Context, q EventQuery) ([]Event, error) {
 
 	query += " ORDER BY start_tick ASC"
 
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+		if q.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, q.Offset)
+		}
+	}
+
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query events: %w", err)
@@ -140,6 +299,397 @@ func (r *Reader) GetEvents(ctx context.Context, q EventQuery) ([]Event, error) {
 	return events, nil
 }
 
+// FailedEvent is an events row RetryingWriter never finished committing,
+// returned by GetFailedEvents. RowID is the SQLite rowid a repair pass
+// (see RetryingWriter.Repair) retries the commit against.
+type FailedEvent struct {
+	Event
+	RowID int64
+}
+
+// GetFailedEvents retrieves matchID's events stuck in "pending" or
+// "failed" - i.e. everything RetryingWriter.BatchInsertEvents didn't finish
+// marking "committed", whether because the process crashed mid-write or
+// because retries were exhausted. Ordered by rowid (insertion order), so
+// a repair pass processes them in the order they were originally written.
+func (r *Reader) GetFailedEvents(ctx context.Context, matchID string) ([]FailedEvent, error) {
+	query := `
+		SELECT rowid, match_id, round_index, type, start_tick, end_tick,
+		       actor_steamid, victim_steamid, severity, confidence, meta_json,
+		       status, attempt_count
+		FROM events
+		WHERE match_id = ? AND status != 'committed'
+		ORDER BY rowid ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failed events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]FailedEvent, 0)
+	for rows.Next() {
+		var e FailedEvent
+		var severity, confidence sql.NullFloat64
+		err := rows.Scan(
+			&e.RowID, &e.MatchID, &e.RoundIndex, &e.Type, &e.StartTick, &e.EndTick,
+			&e.ActorSteamID, &e.VictimSteamID, &severity, &confidence, &e.MetaJSON,
+			&e.Status, &e.AttemptCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan failed event: %w", err)
+		}
+		if severity.Valid {
+			e.Severity = &severity.Float64
+		}
+		if confidence.Valid {
+			e.Confidence = &confidence.Float64
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating failed events: %w", err)
+	}
+
+	return events, nil
+}
+
+// PositionQuery parameterizes GetPlayerPositions the same way EventQuery
+// parameterizes GetEvents.
+type PositionQuery struct {
+	MatchID string
+	Round   *int
+	SteamID *string
+	// TickStart and TickEnd bound the returned ticks, inclusive. Nil means
+	// unbounded on that side, e.g. an API's ?tick_start=&tick_end= leaving
+	// one or both empty.
+	TickStart *int
+	TickEnd   *int
+
+	// Limit and Offset page through a round's positions, which can run to
+	// thousands of rows at tight --position-interval settings. Limit <= 0
+	// means no limit, matching EventQuery's convention.
+	Limit  int
+	Offset int
+}
+
+// GetPlayerPositions retrieves positions matching q, ordered by tick so a
+// caller can replay a player's path through a round in order.
+func (r *Reader) GetPlayerPositions(ctx context.Context, q PositionQuery) ([]PlayerPosition, error) {
+	query := `
+		SELECT match_id, round_index, tick, steamid, x, y, z, yaw, team, health, armor, weapon
+		FROM player_positions
+		WHERE match_id = ?
+	`
+	args := []interface{}{q.MatchID}
+
+	if q.Round != nil {
+		query += " AND round_index = ?"
+		args = append(args, *q.Round)
+	}
+	if q.SteamID != nil {
+		query += " AND steamid = ?"
+		args = append(args, *q.SteamID)
+	}
+	if q.TickStart != nil {
+		query += " AND tick >= ?"
+		args = append(args, *q.TickStart)
+	}
+	if q.TickEnd != nil {
+		query += " AND tick <= ?"
+		args = append(args, *q.TickEnd)
+	}
+
+	query += " ORDER BY tick ASC"
+
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+		if q.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, q.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query player positions: %w", err)
+	}
+	defer rows.Close()
+
+	positions := make([]PlayerPosition, 0)
+	for rows.Next() {
+		var p PlayerPosition
+		var yaw sql.NullFloat64
+		var team, weapon sql.NullString
+		var health, armor sql.NullInt64
+		err := rows.Scan(
+			&p.MatchID, &p.RoundIndex, &p.Tick, &p.SteamID, &p.X, &p.Y, &p.Z,
+			&yaw, &team, &health, &armor, &weapon,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player position: %w", err)
+		}
+		if yaw.Valid {
+			p.Yaw = &yaw.Float64
+		}
+		if team.Valid {
+			p.Team = &team.String
+		}
+		if health.Valid {
+			h := int(health.Int64)
+			p.Health = &h
+		}
+		if armor.Valid {
+			a := int(armor.Int64)
+			p.Armor = &a
+		}
+		if weapon.Valid {
+			p.Weapon = &weapon.String
+		}
+		positions = append(positions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player positions: %w", err)
+	}
+
+	return positions, nil
+}
+
+// ShotQuery parameterizes GetShots the same way EventQuery parameterizes
+// GetEvents.
+type ShotQuery struct {
+	MatchID string
+	Round   *int
+	SteamID *string
+
+	// Limit and Offset page through a match's shots. Limit <= 0 means no
+	// limit, matching EventQuery's convention.
+	Limit  int
+	Offset int
+}
+
+// GetShots retrieves shots matching q, ordered by tick.
+func (r *Reader) GetShots(ctx context.Context, q ShotQuery) ([]Shot, error) {
+	query := `
+		SELECT match_id, round_index, tick, steamid, weapon_name, x, y, z, yaw, pitch, team
+		FROM shots
+		WHERE match_id = ?
+	`
+	args := []interface{}{q.MatchID}
+
+	if q.Round != nil {
+		query += " AND round_index = ?"
+		args = append(args, *q.Round)
+	}
+	if q.SteamID != nil {
+		query += " AND steamid = ?"
+		args = append(args, *q.SteamID)
+	}
+
+	query += " ORDER BY tick ASC"
+
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+		if q.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, q.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shots: %w", err)
+	}
+	defer rows.Close()
+
+	shots := make([]Shot, 0)
+	for rows.Next() {
+		var s Shot
+		var pitch sql.NullFloat64
+		var team sql.NullString
+		err := rows.Scan(
+			&s.MatchID, &s.RoundIndex, &s.Tick, &s.SteamID, &s.WeaponName,
+			&s.X, &s.Y, &s.Z, &s.Yaw, &pitch, &team,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shot: %w", err)
+		}
+		if pitch.Valid {
+			s.Pitch = &pitch.Float64
+		}
+		if team.Valid {
+			s.Team = &team.String
+		}
+		shots = append(shots, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shots: %w", err)
+	}
+
+	return shots, nil
+}
+
+// positionSample is one row off either player_positions_keyframes or
+// player_positions_deltas, before ReconstructPositions folds it into an
+// absolute PlayerPosition - only one of (full fields) or (dx/dy/dz/yawDelta)
+// is populated, per isKeyframe.
+type positionSample struct {
+	tick       int
+	isKeyframe bool
+
+	x, y, z float64
+	yaw     *float64
+
+	dx, dy, dz int16
+	yawDelta   int16
+
+	team   *string
+	health *int
+	armor  *int
+	weapon *string
+}
+
+// ReconstructPositions replays matchID/roundIndex/steamID's
+// player_positions_keyframes and player_positions_deltas rows in tick order,
+// folding each delta's dx/dy/dz/yaw_delta onto the running position left by
+// the preceding keyframe or delta, and returns the resulting absolute
+// positions - the --position-encoding=delta counterpart to GetPlayerPositions
+// reading player_positions directly. Returns an empty slice, not an error,
+// if this round/player was recorded with --position-encoding=full instead
+// (nothing in either table).
+func (r *Reader) ReconstructPositions(ctx context.Context, matchID string, roundIndex int, steamID string) ([]PlayerPosition, error) {
+	samples := make([]positionSample, 0)
+
+	kfRows, err := r.db.QueryContext(ctx, `
+		SELECT tick, x, y, z, yaw, team, health, armor, weapon
+		FROM player_positions_keyframes
+		WHERE match_id = ? AND round_index = ? AND steamid = ?
+	`, matchID, roundIndex, steamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query position keyframes: %w", err)
+	}
+	for kfRows.Next() {
+		var s positionSample
+		var yaw sql.NullFloat64
+		var team, weapon sql.NullString
+		var health, armor sql.NullInt64
+		if err := kfRows.Scan(&s.tick, &s.x, &s.y, &s.z, &yaw, &team, &health, &armor, &weapon); err != nil {
+			kfRows.Close()
+			return nil, fmt.Errorf("failed to scan position keyframe: %w", err)
+		}
+		s.isKeyframe = true
+		if yaw.Valid {
+			s.yaw = &yaw.Float64
+		}
+		if team.Valid {
+			s.team = &team.String
+		}
+		if health.Valid {
+			h := int(health.Int64)
+			s.health = &h
+		}
+		if armor.Valid {
+			a := int(armor.Int64)
+			s.armor = &a
+		}
+		if weapon.Valid {
+			s.weapon = &weapon.String
+		}
+		samples = append(samples, s)
+	}
+	if err := kfRows.Err(); err != nil {
+		kfRows.Close()
+		return nil, fmt.Errorf("error iterating position keyframes: %w", err)
+	}
+	kfRows.Close()
+
+	deltaRows, err := r.db.QueryContext(ctx, `
+		SELECT tick, dx, dy, dz, yaw_delta, team, health, armor, weapon
+		FROM player_positions_deltas
+		WHERE match_id = ? AND round_index = ? AND steamid = ?
+	`, matchID, roundIndex, steamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query position deltas: %w", err)
+	}
+	for deltaRows.Next() {
+		var s positionSample
+		var team, weapon sql.NullString
+		var health, armor sql.NullInt64
+		if err := deltaRows.Scan(&s.tick, &s.dx, &s.dy, &s.dz, &s.yawDelta, &team, &health, &armor, &weapon); err != nil {
+			deltaRows.Close()
+			return nil, fmt.Errorf("failed to scan position delta: %w", err)
+		}
+		if team.Valid {
+			s.team = &team.String
+		}
+		if health.Valid {
+			h := int(health.Int64)
+			s.health = &h
+		}
+		if armor.Valid {
+			a := int(armor.Int64)
+			s.armor = &a
+		}
+		if weapon.Valid {
+			s.weapon = &weapon.String
+		}
+		samples = append(samples, s)
+	}
+	if err := deltaRows.Err(); err != nil {
+		deltaRows.Close()
+		return nil, fmt.Errorf("error iterating position deltas: %w", err)
+	}
+	deltaRows.Close()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].tick < samples[j].tick })
+
+	positions := make([]PlayerPosition, 0, len(samples))
+	var x, y, z, yaw float64
+	for _, s := range samples {
+		if s.isKeyframe {
+			x, y, z = s.x, s.y, s.z
+			if s.yaw != nil {
+				yaw = *s.yaw
+			} else {
+				yaw = 0
+			}
+		} else {
+			x += float64(s.dx)
+			y += float64(s.dy)
+			z += float64(s.dz)
+			yaw += float64(s.yawDelta) * 0.5
+			if yaw < 0 {
+				yaw += 360
+			} else if yaw >= 360 {
+				yaw -= 360
+			}
+		}
+
+		yawCopy := yaw
+		positions = append(positions, PlayerPosition{
+			MatchID:    matchID,
+			RoundIndex: roundIndex,
+			Tick:       s.tick,
+			SteamID:    steamID,
+			X:          x,
+			Y:          y,
+			Z:          z,
+			Yaw:        &yawCopy,
+			Team:       s.team,
+			Health:     s.health,
+			Armor:      s.armor,
+			Weapon:     s.weapon,
+		})
+	}
+
+	return positions, nil
+}
+
 // GetRounds retrieves all rounds for a match.
 func (r *Reader) GetRounds(ctx context.Context, matchID string) ([]Round, error) {
 	query := `
@@ -200,6 +750,29 @@ func (r *Reader) GetPlayerName(ctx context.Context, matchID, steamID string) (st
 	return name, nil
 }
 
+// GetMatch retrieves matchID's Match row, notably its Map and TickRate -
+// see reporters.BuildReport, which needs TickRate to turn an Event's
+// StartTick into a match-clock time.
+func (r *Reader) GetMatch(ctx context.Context, matchID string) (Match, error) {
+	query := `SELECT id, map, tick_rate, started_at, source FROM matches WHERE id = ?`
+	var m Match
+	var startedAt, source sql.NullString
+	err := r.db.QueryRowContext(ctx, query, matchID).Scan(&m.ID, &m.Map, &m.TickRate, &startedAt, &source)
+	if err != nil {
+		return Match{}, fmt.Errorf("failed to get match %s: %w", matchID, err)
+	}
+	if startedAt.Valid {
+		t, err := time.Parse(time.RFC3339, startedAt.String)
+		if err == nil {
+			m.StartedAt = &t
+		}
+	}
+	if source.Valid {
+		m.Source = &source.String
+	}
+	return m, nil
+}
+
 // GetMatchExists checks if a match exists.
 func (r *Reader) GetMatchExists(ctx context.Context, matchID string) (bool, error) {
 	query := `SELECT 1 FROM matches WHERE id = ? LIMIT 1`
@@ -214,6 +787,53 @@ func (r *Reader) GetMatchExists(ctx context.Context, matchID string) (bool, erro
 	return true, nil
 }
 
+// GetMatches lists every match in the database, most recently started
+// first, for an API's top-level "pick a match" view. limit <= 0 returns
+// every match (fine for the modest match counts this analyzer targets;
+// callers serving this over HTTP should still pass a sane page size).
+func (r *Reader) GetMatches(ctx context.Context, limit, offset int) ([]Match, error) {
+	query := `SELECT id, map, tick_rate, started_at, source FROM matches ORDER BY started_at DESC`
+	args := []interface{}{}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+		if offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]Match, 0)
+	for rows.Next() {
+		var m Match
+		var startedAt, source sql.NullString
+		if err := rows.Scan(&m.ID, &m.Map, &m.TickRate, &startedAt, &source); err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+		if startedAt.Valid {
+			if t, err := time.Parse(time.RFC3339, startedAt.String); err == nil {
+				m.StartedAt = &t
+			}
+		}
+		if source.Valid {
+			m.Source = &source.String
+		}
+		matches = append(matches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating matches: %w", err)
+	}
+
+	return matches, nil
+}
+
 // GetChatMessages retrieves chat messages for a match, optionally filtered by steamid.
 // Only returns all chat messages (excludes team chat).
 func (r *Reader) GetChatMessages(ctx context.Context, matchID string, steamid *string) ([]ChatMessage, error) {
@@ -269,6 +889,61 @@ func (r *Reader) GetChatMessages(ctx context.Context, matchID string, steamid *s
 	return messages, nil
 }
 
+// GetSystemChatEvents retrieves system/server chat events for a match,
+// optionally filtered by kind (see SystemChatEvent.Kind constants), oldest
+// first - the join/leave/kick/map/round-transition timeline GetChatMessages
+// doesn't carry.
+func (r *Reader) GetSystemChatEvents(ctx context.Context, matchID string, kind *string) ([]SystemChatEvent, error) {
+	var query string
+	var args []interface{}
+
+	if kind != nil {
+		query = `
+			SELECT match_id, round_index, tick, kind, steamid, name, message
+			FROM chat_system_messages
+			WHERE match_id = ? AND kind = ?
+			ORDER BY tick ASC
+		`
+		args = []interface{}{matchID, *kind}
+	} else {
+		query = `
+			SELECT match_id, round_index, tick, kind, steamid, name, message
+			FROM chat_system_messages
+			WHERE match_id = ?
+			ORDER BY tick ASC
+		`
+		args = []interface{}{matchID}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query system chat events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]SystemChatEvent, 0)
+	for rows.Next() {
+		var event SystemChatEvent
+		var steamid, name sql.NullString
+		if err := rows.Scan(&event.MatchID, &event.RoundIndex, &event.Tick, &event.Kind, &steamid, &name, &event.Message); err != nil {
+			return nil, fmt.Errorf("failed to scan system chat event: %w", err)
+		}
+		if steamid.Valid {
+			event.SteamID = &steamid.String
+		}
+		if name.Valid {
+			event.Name = &name.String
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating system chat events: %w", err)
+	}
+
+	return events, nil
+}
+
 // GetParserLogs retrieves parser logs for a match.
 func (r *Reader) GetParserLogs(ctx context.Context, matchID string) (string, error) {
 	query := `SELECT logs FROM parser_logs WHERE match_id = ?`
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sqlite "modernc.org/sqlite"
+)
+
+const (
+	defaultRetryMaxAttempts  = 5
+	defaultRetryInitialDelay = 50 * time.Millisecond
+	defaultRetryMaxDelay     = 2 * time.Second
+)
+
+// RetryConfig configures RetryingWriter's backoff. The zero value falls
+// back to the defaults above, the same "empty means default" convention
+// webhooks.Config and tsdb.Config use.
+type RetryConfig struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if c.InitialDelay <= 0 {
+		c.InitialDelay = defaultRetryInitialDelay
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = defaultRetryMaxDelay
+	}
+	return c
+}
+
+// RetryingWriter wraps Writer's event/position inserts with
+// exponential-backoff retry on transient SQLite errors (SQLITE_BUSY,
+// SQLITE_LOCKED - e.g. extractors.ParallelPipeline's worker connections
+// briefly contending for the write lock despite db.Open's busy_timeout)
+// and a per-event events.status lifecycle (pending -> committed, or
+// pending -> failed once retries are exhausted), so a parse that crashes
+// mid-write leaves a record of which events never made it instead of an
+// ambiguous gap. See Reader.GetFailedEvents to inspect rows that never
+// reached "committed".
+type RetryingWriter struct {
+	writer *Writer
+	reader *Reader
+	cfg    RetryConfig
+}
+
+// NewRetryingWriter wraps dbConn's Writer/Reader with cfg's retry policy.
+func NewRetryingWriter(dbConn *sql.DB, cfg RetryConfig) *RetryingWriter {
+	return &RetryingWriter{writer: NewWriter(dbConn), reader: NewReader(dbConn), cfg: cfg.withDefaults()}
+}
+
+// SetWatcher attaches a Watcher the same way Writer.SetWatcher does;
+// committed events are published exactly like BatchInsertEvents does.
+func (w *RetryingWriter) SetWatcher(watcher *Watcher) {
+	w.writer.SetWatcher(watcher)
+}
+
+// BatchInsertEvents inserts each event individually: first as a "pending" row
+// (so a crash before it's marked committed still leaves a durable trace
+// instead of the event vanishing entirely), then marks it "committed" by
+// rowid. Both steps retry with exponential backoff on SQLITE_BUSY/
+// SQLITE_LOCKED. If a row was inserted but couldn't be marked committed
+// after cfg.MaxAttempts, it's marked "failed" instead as a best-effort
+// final write (if even that fails, the row is left "pending" - either way
+// GetFailedEvents surfaces it). Returns the first failure immediately
+// rather than continuing the batch, so callers see a crashed parse's
+// actual failure point instead of a buried one.
+func (w *RetryingWriter) BatchInsertEvents(ctx context.Context, events []Event) error {
+	for _, e := range events {
+		if err := w.insertOne(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *RetryingWriter) insertOne(ctx context.Context, e Event) error {
+	var rowID int64
+	err := w.retry(ctx, func() error {
+		query := `
+			INSERT INTO events (
+				match_id, round_index, type, start_tick, end_tick,
+				actor_steamid, victim_steamid, severity, confidence, meta_json,
+				status, attempt_count
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'pending', 0)
+		`
+		result, err := w.writer.db.ExecContext(ctx, query,
+			e.MatchID, e.RoundIndex, e.Type, e.StartTick, e.EndTick,
+			e.ActorSteamID, e.VictimSteamID, e.Severity, e.Confidence, e.MetaJSON,
+		)
+		if err != nil {
+			return err
+		}
+		rowID, err = result.LastInsertId()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert event: %w", err)
+	}
+
+	commitErr := w.retry(ctx, func() error {
+		_, err := w.writer.db.ExecContext(ctx, `UPDATE events SET status = 'committed' WHERE rowid = ?`, rowID)
+		return err
+	})
+	if commitErr == nil {
+		if w.writer.watcher != nil {
+			w.writer.watcher.PublishEvents(e.MatchID, []Event{e})
+		}
+		return nil
+	}
+
+	// Retries exhausted marking it committed - record the row as failed so
+	// Reader.GetFailedEvents surfaces it instead of leaving it silently
+	// stuck. This write is itself best-effort: if it also hits
+	// SQLITE_BUSY/SQLITE_LOCKED, the row is left "pending", which
+	// GetFailedEvents reports just as readily.
+	_, _ = w.writer.db.ExecContext(ctx, `UPDATE events SET status = 'failed', attempt_count = attempt_count + 1 WHERE rowid = ?`, rowID)
+	return fmt.Errorf("event inserted as row %d but could not be marked committed: %w", rowID, commitErr)
+}
+
+// InsertPlayerPositions retries Writer.InsertPlayerPositions's whole batch
+// on SQLITE_BUSY/SQLITE_LOCKED. Unlike BatchInsertEvents, positions have
+// no per-row status column: they're sampling data an extractor can simply
+// regenerate from the demo on a resumed parse, not detection results a
+// repair pass needs to find and retry individually.
+func (w *RetryingWriter) InsertPlayerPositions(ctx context.Context, positions []PlayerPosition) error {
+	return w.retry(ctx, func() error {
+		return w.writer.InsertPlayerPositions(ctx, positions)
+	})
+}
+
+// Repair retries every one of matchID's events still stuck "pending" or
+// "failed" (see Reader.GetFailedEvents), marking each one "committed" in
+// turn, and returns how many it fixed. Stops and returns the error at the
+// first row repair can't fix, leaving the rest for a later repair run -
+// the same "report the real failure point" reasoning as BatchInsertEvents.
+func (w *RetryingWriter) Repair(ctx context.Context, matchID string) (int, error) {
+	failed, err := w.reader.GetFailedEvents(ctx, matchID)
+	if err != nil {
+		return 0, fmt.Errorf("listing failed events: %w", err)
+	}
+
+	repaired := 0
+	for _, f := range failed {
+		err := w.retry(ctx, func() error {
+			_, err := w.writer.db.ExecContext(ctx, `UPDATE events SET status = 'committed' WHERE rowid = ?`, f.RowID)
+			return err
+		})
+		if err != nil {
+			return repaired, fmt.Errorf("repairing row %d: %w", f.RowID, err)
+		}
+		repaired++
+	}
+	return repaired, nil
+}
+
+// retry calls fn, retrying with exponential backoff while the error is a
+// transient SQLITE_BUSY/SQLITE_LOCKED, up to cfg.MaxAttempts tries.
+func (w *RetryingWriter) retry(ctx context.Context, fn func() error) error {
+	delay := w.cfg.InitialDelay
+	var err error
+	for attempt := 1; attempt <= w.cfg.MaxAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if !isRetryableSQLiteError(err) || attempt == w.cfg.MaxAttempts {
+			return err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+		if delay > w.cfg.MaxDelay {
+			delay = w.cfg.MaxDelay
+		}
+	}
+	return err
+}
+
+// sqliteBusy and sqliteLocked are SQLite's primary result codes for
+// SQLITE_BUSY and SQLITE_LOCKED - the two transient "someone else holds
+// the lock, try again" errors db.Open's PRAGMA busy_timeout already
+// softens but can't eliminate entirely under enough concurrent writers
+// (e.g. several RetryingWriters sharing one --out file).
+const (
+	sqliteBusy   = 5
+	sqliteLocked = 6
+)
+
+// isRetryableSQLiteError reports whether err is a SQLITE_BUSY or
+// SQLITE_LOCKED *sqlite.Error (from modernc.org/sqlite, the driver
+// db.Open registers), masking off any extended result code bits.
+func isRetryableSQLiteError(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	primary := sqliteErr.Code() & 0xff
+	return primary == sqliteBusy || primary == sqliteLocked
+}
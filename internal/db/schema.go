@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+
+	"cs-griefer-electron/internal/db/migrations"
 )
 
-// Schema defines the SQLite database schema for storing CS2 demo data.
+// sqliteSchema defines the SQLite database schema for storing CS2 demo data.
 // Uses modernc.org/sqlite which is a pure Go SQLite driver with no CGO dependencies.
-const schema = `
+const sqliteSchema = `
 CREATE TABLE IF NOT EXISTS meta (
 	key TEXT PRIMARY KEY,
 	value TEXT NOT NULL
@@ -59,19 +61,28 @@ CREATE TABLE IF NOT EXISTS events (
 	severity REAL,
 	confidence REAL,
 	meta_json TEXT,
+	-- status/attempt_count track RetryingWriter's write lifecycle
+	-- (pending -> committed, or pending -> failed once retries are
+	-- exhausted). Plain Writer inserts never set these, so they keep
+	-- SQLite's column default of an already-committed row.
+	status TEXT NOT NULL DEFAULT 'committed',
+	attempt_count INTEGER NOT NULL DEFAULT 0,
 	FOREIGN KEY(match_id) REFERENCES matches(id)
 );
 
 CREATE TABLE IF NOT EXISTS player_scores (
 	match_id TEXT NOT NULL,
 	steamid TEXT NOT NULL,
+	profile_name TEXT NOT NULL DEFAULT 'default',
 	team_kills INTEGER NOT NULL DEFAULT 0,
 	team_damage REAL NOT NULL DEFAULT 0,
 	team_flash_seconds REAL NOT NULL DEFAULT 0,
 	afk_seconds REAL NOT NULL DEFAULT 0,
 	body_block_seconds REAL NOT NULL DEFAULT 0,
+	economy_grief_count INTEGER NOT NULL DEFAULT 0,
 	grief_score REAL NOT NULL DEFAULT 0,
-	PRIMARY KEY(match_id, steamid),
+	top_reasons_json TEXT,
+	PRIMARY KEY(match_id, steamid, profile_name),
 	FOREIGN KEY(match_id) REFERENCES matches(id)
 );
 
@@ -119,9 +130,83 @@ CREATE INDEX IF NOT EXISTS idx_chat_messages_match ON chat_messages(match_id);
 CREATE INDEX IF NOT EXISTS idx_chat_messages_steamid ON chat_messages(match_id, steamid);
 CREATE INDEX IF NOT EXISTS idx_chat_messages_round ON chat_messages(match_id, round_index);
 CREATE INDEX IF NOT EXISTS idx_chat_messages_tick ON chat_messages(match_id, tick);
+CREATE INDEX IF NOT EXISTS idx_chat_messages_steamid_tick ON chat_messages(match_id, steamid, tick);
+
+-- chat_system_messages holds server/system log lines (join/leave, kick/ban,
+-- name changes, map/round transitions, cvar changes) distinct from player
+-- chat_messages. steamid is nullable, unlike chat_messages' - a connect
+-- announcement fires at the same moment the player row is (or isn't yet)
+-- written, and some kinds (map_change, round_result, server_cvar) aren't
+-- attributable to a single player at all.
+CREATE TABLE IF NOT EXISTS chat_system_messages (
+	match_id TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	tick INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	steamid TEXT,
+	name TEXT,
+	message TEXT NOT NULL,
+	PRIMARY KEY(match_id, tick, kind, steamid, message),
+	FOREIGN KEY(match_id) REFERENCES matches(id),
+	FOREIGN KEY(match_id, steamid) REFERENCES players(match_id, steamid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_system_messages_match ON chat_system_messages(match_id);
+CREATE INDEX IF NOT EXISTS idx_chat_system_messages_round ON chat_system_messages(match_id, round_index);
+CREATE INDEX IF NOT EXISTS idx_chat_system_messages_tick ON chat_system_messages(match_id, tick);
+CREATE INDEX IF NOT EXISTS idx_chat_system_messages_kind ON chat_system_messages(match_id, kind);
 CREATE INDEX IF NOT EXISTS idx_player_positions_match_round ON player_positions(match_id, round_index);
 CREATE INDEX IF NOT EXISTS idx_player_positions_tick ON player_positions(match_id, round_index, tick);
 
+-- player_positions_keyframes/player_positions_deltas are an alternative to
+-- player_positions for --position-encoding=delta: a full row every
+-- --position-keyframe-interval sampled ticks (same columns as
+-- player_positions), and compact dx/dy/dz/yaw_delta rows in between that
+-- reconstruct back to absolute coordinates off the preceding keyframe or
+-- delta (see db.ReconstructPositions). Quantized to whole world units and
+-- 0.5 degree yaw steps, so reconstruction matches the original samples to
+-- that resolution rather than bit-for-bit. --position-encoding=full (the
+-- default) keeps using player_positions above instead of these tables.
+CREATE TABLE IF NOT EXISTS player_positions_keyframes (
+	match_id TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	tick INTEGER NOT NULL,
+	steamid TEXT NOT NULL,
+	x REAL NOT NULL,
+	y REAL NOT NULL,
+	z REAL NOT NULL,
+	yaw REAL,
+	team TEXT,
+	health INTEGER,
+	armor INTEGER,
+	weapon TEXT,
+	PRIMARY KEY(match_id, round_index, tick, steamid),
+	FOREIGN KEY(match_id) REFERENCES matches(id),
+	FOREIGN KEY(match_id, steamid) REFERENCES players(match_id, steamid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_positions_keyframes_match_round ON player_positions_keyframes(match_id, round_index);
+
+CREATE TABLE IF NOT EXISTS player_positions_deltas (
+	match_id TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	tick INTEGER NOT NULL,
+	steamid TEXT NOT NULL,
+	dx INTEGER NOT NULL,
+	dy INTEGER NOT NULL,
+	dz INTEGER NOT NULL,
+	yaw_delta INTEGER NOT NULL,
+	team TEXT,
+	health INTEGER,
+	armor INTEGER,
+	weapon TEXT,
+	PRIMARY KEY(match_id, round_index, tick, steamid),
+	FOREIGN KEY(match_id) REFERENCES matches(id),
+	FOREIGN KEY(match_id, steamid) REFERENCES players(match_id, steamid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_positions_deltas_match_round ON player_positions_deltas(match_id, round_index);
+
 CREATE TABLE IF NOT EXISTS grenade_positions (
 	match_id TEXT NOT NULL,
 	round_index INTEGER NOT NULL,
@@ -151,6 +236,11 @@ CREATE TABLE IF NOT EXISTS grenade_events (
 	thrower_steamid TEXT,
 	thrower_name TEXT,
 	thrower_team TEXT,
+	-- fires_json is only set for inferno_start/inferno_update/inferno_expire
+	-- rows: a JSON array of {x,y,z,is_burning} for every flame the inferno
+	-- has ever had, letting a 2D renderer draw the actual burning area (x/y/z
+	-- above are just its centroid) instead of a single point.
+	fires_json TEXT,
 	FOREIGN KEY(match_id) REFERENCES matches(id)
 );
 
@@ -160,6 +250,71 @@ CREATE INDEX IF NOT EXISTS idx_grenade_events_match_round ON grenade_events(matc
 CREATE INDEX IF NOT EXISTS idx_grenade_events_tick ON grenade_events(match_id, round_index, tick);
 CREATE INDEX IF NOT EXISTS idx_grenade_events_type ON grenade_events(event_type);
 
+-- bomb_events holds the bomb's full lifecycle (pickup/drop/plant/defuse/
+-- explode) plus periodic position samples while it's not attributable to
+-- a player action, so downstream tools can render round timelines that
+-- include bomb movement alongside kills and utility.
+CREATE TABLE IF NOT EXISTS bomb_events (
+	match_id TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	tick INTEGER NOT NULL,
+	event_type TEXT NOT NULL,
+	carrier_steamid TEXT,
+	x REAL NOT NULL,
+	y REAL NOT NULL,
+	z REAL NOT NULL,
+	site TEXT,
+	defuser_steamid TEXT,
+	has_kit INTEGER NOT NULL DEFAULT 0,
+	FOREIGN KEY(match_id) REFERENCES matches(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bomb_events_match_round ON bomb_events(match_id, round_index);
+CREATE INDEX IF NOT EXISTS idx_bomb_events_tick ON bomb_events(match_id, round_index, tick);
+CREATE INDEX IF NOT EXISTS idx_bomb_events_type ON bomb_events(event_type);
+
+-- grenade_predictions holds, for each projectile, a predicted
+-- detonation/landing point computed the moment it's first observed (see
+-- internal/parser/ballistics), alongside the actual grenade_events row
+-- recorded later - so downstream tools can measure predicted-vs-actual
+-- utility placement and render danger maps per round.
+CREATE TABLE IF NOT EXISTS grenade_predictions (
+	match_id TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	tick INTEGER NOT NULL,
+	projectile_id INTEGER NOT NULL,
+	grenade_name TEXT NOT NULL,
+	predicted_x REAL NOT NULL,
+	predicted_y REAL NOT NULL,
+	predicted_z REAL NOT NULL,
+	predicted_tick INTEGER NOT NULL,
+	effect_radius REAL NOT NULL,
+	PRIMARY KEY(match_id, round_index, projectile_id),
+	FOREIGN KEY(match_id) REFERENCES matches(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_grenade_predictions_match_round ON grenade_predictions(match_id, round_index);
+
+-- player_inputs holds reconstructed per-tick button state (see
+-- db.InputButton* and internal/parser's --record-inputs FrameDone
+-- handler), for bot/aim-assist detection and movement analysis that
+-- player_positions' periodic samples alone can't support.
+CREATE TABLE IF NOT EXISTS player_inputs (
+	match_id TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	tick INTEGER NOT NULL,
+	steamid TEXT NOT NULL,
+	buttons INTEGER NOT NULL,
+	forwardmove REAL NOT NULL,
+	sidemove REAL NOT NULL,
+	PRIMARY KEY(match_id, tick, steamid),
+	FOREIGN KEY(match_id) REFERENCES matches(id),
+	FOREIGN KEY(match_id, steamid) REFERENCES players(match_id, steamid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_inputs_match_round ON player_inputs(match_id, round_index);
+CREATE INDEX IF NOT EXISTS idx_player_inputs_steamid_tick ON player_inputs(match_id, steamid, tick);
+
 CREATE TABLE IF NOT EXISTS shots (
 	match_id TEXT NOT NULL,
 	round_index INTEGER NOT NULL,
@@ -179,104 +334,120 @@ CREATE TABLE IF NOT EXISTS shots (
 CREATE INDEX IF NOT EXISTS idx_shots_match_round ON shots(match_id, round_index);
 CREATE INDEX IF NOT EXISTS idx_shots_tick ON shots(match_id, round_index, tick);
 CREATE INDEX IF NOT EXISTS idx_shots_steamid ON shots(match_id, steamid);
+
+-- weapon_switches holds one row per observed ActiveWeapon() change (see
+-- internal/parser's weapon-switch FrameDone handler), so analysts can spot
+-- panic-switches, quick-swap plays and force-buy economy patterns.
+-- deploy_ticks_until_first_fire is NULL when no WeaponFire from the same
+-- player followed within the bounded deploy-latency window (see
+-- maxDeployWindowTicks) before the next switch or round end.
+CREATE TABLE IF NOT EXISTS weapon_switches (
+	match_id TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	tick INTEGER NOT NULL,
+	steamid TEXT NOT NULL,
+	prev_weapon TEXT,
+	new_weapon TEXT NOT NULL,
+	prev_ammo INTEGER,
+	new_ammo INTEGER,
+	was_empty INTEGER NOT NULL DEFAULT 0,
+	deploy_ticks_until_first_fire INTEGER,
+	PRIMARY KEY(match_id, tick, steamid),
+	FOREIGN KEY(match_id) REFERENCES matches(id),
+	FOREIGN KEY(match_id, steamid) REFERENCES players(match_id, steamid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_weapon_switches_match_round ON weapon_switches(match_id, round_index);
+CREATE INDEX IF NOT EXISTS idx_weapon_switches_steamid ON weapon_switches(match_id, steamid);
+
+-- player_round_weapon_stats holds one row per player per round,
+-- aggregating that round's weapon_switches - a companion summary table so
+-- readers don't have to re-aggregate the raw switch stream for common
+-- queries.
+CREATE TABLE IF NOT EXISTS player_round_weapon_stats (
+	match_id TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	steamid TEXT NOT NULL,
+	switches_per_round INTEGER NOT NULL DEFAULT 0,
+	avg_deploy_seconds REAL,
+	switches_to_empty_gun INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY(match_id, round_index, steamid),
+	FOREIGN KEY(match_id) REFERENCES matches(id),
+	FOREIGN KEY(match_id, steamid) REFERENCES players(match_id, steamid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_round_weapon_stats_match_round ON player_round_weapon_stats(match_id, round_index);
 `
 
-// runMigrations runs database migrations to add new columns to existing tables.
-func runMigrations(ctx context.Context, db *sql.DB) error {
-	// Check if players table has connected_midgame column
-	var hasConnectedMidgame bool
-	checkColumnQuery := `SELECT COUNT(*) FROM pragma_table_info('players') WHERE name = 'connected_midgame'`
-	var count int
-	if err := db.QueryRowContext(ctx, checkColumnQuery).Scan(&count); err == nil {
-		hasConnectedMidgame = count > 0
-	}
-	
-	if !hasConnectedMidgame {
-		_, err := db.ExecContext(ctx, `ALTER TABLE players ADD COLUMN connected_midgame INTEGER DEFAULT 0`)
-		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
-			// Ignore "duplicate column" errors, but log others
-			fmt.Printf("WARN: Failed to add connected_midgame column: %v\n", err)
-		}
-	}
-	
-	// Check if players table has permanent_disconnect column
-	var hasPermanentDisconnect bool
-	checkColumnQuery2 := `SELECT COUNT(*) FROM pragma_table_info('players') WHERE name = 'permanent_disconnect'`
-	var count2 int
-	if err := db.QueryRowContext(ctx, checkColumnQuery2).Scan(&count2); err == nil {
-		hasPermanentDisconnect = count2 > 0
-	}
-	
-	if !hasPermanentDisconnect {
-		_, err := db.ExecContext(ctx, `ALTER TABLE players ADD COLUMN permanent_disconnect INTEGER DEFAULT 0`)
-		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
-			// Ignore "duplicate column" errors, but log others
-			fmt.Printf("WARN: Failed to add permanent_disconnect column: %v\n", err)
-		}
-	}
-	
-	// Check if players table has first_connect_round column
-	var hasFirstConnectRound bool
-	checkColumnQuery3 := `SELECT COUNT(*) FROM pragma_table_info('players') WHERE name = 'first_connect_round'`
-	var count3 int
-	if err := db.QueryRowContext(ctx, checkColumnQuery3).Scan(&count3); err == nil {
-		hasFirstConnectRound = count3 > 0
-	}
-	
-	if !hasFirstConnectRound {
-		_, err := db.ExecContext(ctx, `ALTER TABLE players ADD COLUMN first_connect_round INTEGER`)
-		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
-			// Ignore "duplicate column" errors, but log others
-			fmt.Printf("WARN: Failed to add first_connect_round column: %v\n", err)
+// postgresTypeReplacer translates SQLite column types to their PostgreSQL
+// equivalents. AUTOINCREMENT is handled as a whole-phrase replacement since
+// Postgres expresses it as a column type (SERIAL) rather than a modifier.
+var postgresTypeReplacer = strings.NewReplacer(
+	"INTEGER PRIMARY KEY AUTOINCREMENT", "SERIAL PRIMARY KEY",
+	"INTEGER", "BIGINT",
+	"REAL", "DOUBLE PRECISION",
+)
+
+// postgresSchema is sqliteSchema translated for PostgreSQL, plus the one
+// column (matches.source) that SQLite only gains via runMigrations.
+// Postgres supports `ADD COLUMN IF NOT EXISTS` natively, so no ad-hoc
+// migration runner is needed for this backend.
+var postgresSchema = postgresTypeReplacer.Replace(sqliteSchema) + `
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS source TEXT;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'committed';
+ALTER TABLE events ADD COLUMN IF NOT EXISTS attempt_count BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS career_players (
+	steamid TEXT PRIMARY KEY,
+	matches_played BIGINT NOT NULL DEFAULT 0,
+	team_kills BIGINT NOT NULL DEFAULT 0,
+	team_damage DOUBLE PRECISION NOT NULL DEFAULT 0,
+	team_flash_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+	afk_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+	body_block_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+	grief_score_ewma DOUBLE PRECISION NOT NULL DEFAULT 0,
+	first_seen TEXT,
+	last_seen TEXT
+);
+
+CREATE TABLE IF NOT EXISTS career_match_stats (
+	match_id TEXT NOT NULL,
+	steamid TEXT NOT NULL,
+	team_kills BIGINT NOT NULL DEFAULT 0,
+	team_damage DOUBLE PRECISION NOT NULL DEFAULT 0,
+	team_flash_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+	afk_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+	body_block_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+	grief_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+	PRIMARY KEY(match_id, steamid),
+	FOREIGN KEY(match_id) REFERENCES matches(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_career_match_stats_steamid ON career_match_stats(steamid);
+`
+
+// InitSchema initializes the database schema for the given backend.
+// It creates all tables and indexes if they don't already exist.
+func InitSchema(ctx context.Context, db *sql.DB, backend Backend) error {
+	switch backend {
+	case BackendPostgres:
+		if _, err := db.ExecContext(ctx, postgresSchema); err != nil {
+			return fmt.Errorf("failed to initialize postgres schema: %w", err)
 		}
-	}
-	
-	// Check if players table has disconnect_round column
-	var hasDisconnectRound bool
-	checkColumnQuery4 := `SELECT COUNT(*) FROM pragma_table_info('players') WHERE name = 'disconnect_round'`
-	var count4 int
-	if err := db.QueryRowContext(ctx, checkColumnQuery4).Scan(&count4); err == nil {
-		hasDisconnectRound = count4 > 0
-	}
-	
-	if !hasDisconnectRound {
-		_, err := db.ExecContext(ctx, `ALTER TABLE players ADD COLUMN disconnect_round INTEGER`)
-		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
-			// Ignore "duplicate column" errors, but log others
-			fmt.Printf("WARN: Failed to add disconnect_round column: %v\n", err)
+		return nil
+	default:
+		if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+			return fmt.Errorf("failed to initialize schema: %w", err)
 		}
-	}
-	
-	// Check if matches table has source column
-	var hasSource bool
-	checkColumnQuery5 := `SELECT COUNT(*) FROM pragma_table_info('matches') WHERE name = 'source'`
-	var count5 int
-	if err := db.QueryRowContext(ctx, checkColumnQuery5).Scan(&count5); err == nil {
-		hasSource = count5 > 0
-	}
-	
-	if !hasSource {
-		_, err := db.ExecContext(ctx, `ALTER TABLE matches ADD COLUMN source TEXT`)
-		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
-			// Ignore "duplicate column" errors, but log others
-			fmt.Printf("WARN: Failed to add source column: %v\n", err)
+
+		// Run migrations for existing databases. Each migration is
+		// named and recorded in schema_migrations so it runs exactly
+		// once, in order, instead of re-checking pragma_table_info on
+		// every startup.
+		if err := migrations.Migrate(ctx, db, migrations.All); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
 		}
-	}
-	
-	return nil
-}
 
-// InitSchema initializes the database schema.
-// It creates all tables and indexes if they don't already exist.
-func InitSchema(ctx context.Context, db *sql.DB) error {
-	if _, err := db.ExecContext(ctx, schema); err != nil {
-		return fmt.Errorf("failed to initialize schema: %w", err)
-	}
-	
-	// Run migrations for existing databases
-	if err := runMigrations(ctx, db); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		return nil
 	}
-	
-	return nil
 }
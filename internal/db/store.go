@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Store is a pluggable persistence backend for parsed match data. It lets
+// the analyzer target SQLite (the default, single-file mode) or PostgreSQL
+// (so multiple analyzer workers can ingest into one shared database) without
+// the parser or extractors caring which engine is actually in use.
+type Store interface {
+	// Init prepares the backend for writing (schema creation, etc). It must
+	// be called once before any Insert* method.
+	Init(ctx context.Context) error
+
+	// InsertEvents persists a batch of extractor events for a match.
+	InsertEvents(ctx context.Context, matchID string, events []Event) error
+
+	// InsertPositions persists a batch of player position samples for a match.
+	InsertPositions(ctx context.Context, matchID string, positions []PlayerPosition) error
+
+	// Flush forces any buffered writes to be committed to the backend.
+	Flush(ctx context.Context) error
+
+	// Close releases resources held by the store.
+	Close() error
+}
+
+// SQLiteStore adapts the existing Writer to the Store interface.
+type SQLiteStore struct {
+	writer *Writer
+}
+
+// NewSQLiteStore creates a SQLiteStore over an already-open, schema-initialized
+// *sql.DB (see Open).
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{writer: NewWriter(db)}
+}
+
+// Init is a no-op: Open already runs InitSchema for the SQLite backend.
+func (s *SQLiteStore) Init(ctx context.Context) error {
+	return nil
+}
+
+// InsertEvents persists events via the underlying Writer.
+func (s *SQLiteStore) InsertEvents(ctx context.Context, matchID string, events []Event) error {
+	return s.writer.BatchInsertEvents(ctx, events)
+}
+
+// InsertPositions persists positions via the underlying Writer.
+func (s *SQLiteStore) InsertPositions(ctx context.Context, matchID string, positions []PlayerPosition) error {
+	return s.writer.InsertPlayerPositions(ctx, positions)
+}
+
+// Flush is a no-op: Writer commits every batch immediately.
+func (s *SQLiteStore) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close is a no-op: the *sql.DB passed to NewSQLiteStore is owned by the caller.
+func (s *SQLiteStore) Close() error {
+	return nil
+}
@@ -0,0 +1,492 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultStreamingMaxBatchSize     = 500
+	defaultStreamingMaxBatchInterval = 2 * time.Second
+	defaultStreamingQueueSize        = 64
+)
+
+// StreamingConfig configures StreamingWriter's flush thresholds and PRAGMA
+// toggles. The zero value falls back to the defaults above, the same
+// "empty means default" convention RetryConfig/webhooks.Config use.
+type StreamingConfig struct {
+	// MaxBatchSize is the row count, for any one of the four queues, that
+	// triggers an immediate flush instead of waiting for MaxBatchInterval.
+	MaxBatchSize int
+	// MaxBatchInterval is the longest a row sits queued before a flush,
+	// even if no queue has reached MaxBatchSize yet.
+	MaxBatchInterval time.Duration
+	// QueueSize bounds how many Enqueue* batches can sit unflushed before
+	// the next Enqueue* call blocks (applying backpressure to the caller
+	// rather than growing memory unboundedly).
+	QueueSize int
+
+	// WALMode, NormalSync and TempStoreMemory are SQLite-only PRAGMA
+	// toggles applied once at construction; leave them false when dbConn
+	// targets Postgres, which doesn't understand this PRAGMA syntax.
+	// db.Open already sets journal_mode=WAL on the connections it opens,
+	// so WALMode only matters for a *sql.DB StreamingWriter is handed some
+	// other way (e.g. one of ParallelPipeline's worker connections).
+	WALMode bool
+	// NormalSync relaxes synchronous from SQLite's default FULL to NORMAL:
+	// still durable against an application crash (WAL mode fsyncs on
+	// checkpoint), but no longer fsyncs after every transaction, which is
+	// what makes batched inserts into the tens-of-thousands-of-rows range
+	// worth streaming in the first place.
+	NormalSync bool
+	// TempStoreMemory keeps SQLite's temporary tables/indexes (used for
+	// sorts and the ensure-player-exists dedup this writer does internally)
+	// in memory instead of a temp file.
+	TempStoreMemory bool
+}
+
+func (c StreamingConfig) withDefaults() StreamingConfig {
+	if c.MaxBatchSize <= 0 {
+		c.MaxBatchSize = defaultStreamingMaxBatchSize
+	}
+	if c.MaxBatchInterval <= 0 {
+		c.MaxBatchInterval = defaultStreamingMaxBatchInterval
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultStreamingQueueSize
+	}
+	return c
+}
+
+// StreamingStats reports StreamingWriter's lifetime throughput, read via
+// Stats() so the parser CLI can print it (e.g. at the end of a --batch run).
+type StreamingStats struct {
+	EventsWritten           int64
+	PositionsWritten        int64
+	GrenadePositionsWritten int64
+	ShotsWritten            int64
+	Flushes                 int64
+	LastError               error
+}
+
+// AvgBatchSize returns the mean number of rows (across all four row types)
+// written per flush, or 0 if nothing has flushed yet.
+func (s StreamingStats) AvgBatchSize() float64 {
+	if s.Flushes == 0 {
+		return 0
+	}
+	total := s.EventsWritten + s.PositionsWritten + s.GrenadePositionsWritten + s.ShotsWritten
+	return float64(total) / float64(s.Flushes)
+}
+
+// StreamingWriter batches Enqueue'd events/positions/grenade positions/shots
+// behind long-lived prepared statements, flushing each batch in one
+// transaction from a single background goroutine once MaxBatchSize rows or
+// MaxBatchInterval - whichever comes first - is reached. This is the
+// high-throughput counterpart to Writer's BatchInsertEvents/
+// InsertPlayerPositions/InsertGrenadePositions/InsertShots, which each
+// re-prepare their statement and open a fresh transaction per call; Writer
+// stays exactly as it is for one-shot callers (e.g. rescore, --repair) that
+// don't want a background goroutine's lifecycle to manage.
+type StreamingWriter struct {
+	db      *sql.DB
+	cfg     StreamingConfig
+	watcher *Watcher
+
+	eventCh           chan []Event
+	positionCh        chan []PlayerPosition
+	grenadePositionCh chan []GrenadePosition
+	shotCh            chan []Shot
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	eventStmt           *sql.Stmt
+	positionStmt        *sql.Stmt
+	ensurePlayerStmt    *sql.Stmt
+	grenadePositionStmt *sql.Stmt
+	shotStmt            *sql.Stmt
+
+	statsMu sync.Mutex
+	stats   StreamingStats
+}
+
+// NewStreamingWriter prepares dbConn's long-lived statements and applies
+// cfg's PRAGMA toggles. Call Start before the first Enqueue* call and Stop
+// once done, to flush whatever is still queued and release the prepared
+// statements - mirroring webhooks.Dispatcher's Start/Stop contract.
+func NewStreamingWriter(dbConn *sql.DB, cfg StreamingConfig) (*StreamingWriter, error) {
+	cfg = cfg.withDefaults()
+
+	if cfg.WALMode {
+		if _, err := dbConn.Exec("PRAGMA journal_mode = WAL"); err != nil {
+			return nil, fmt.Errorf("streaming writer: enable WAL mode: %w", err)
+		}
+	}
+	if cfg.NormalSync {
+		if _, err := dbConn.Exec("PRAGMA synchronous = NORMAL"); err != nil {
+			return nil, fmt.Errorf("streaming writer: set synchronous=NORMAL: %w", err)
+		}
+	}
+	if cfg.TempStoreMemory {
+		if _, err := dbConn.Exec("PRAGMA temp_store = MEMORY"); err != nil {
+			return nil, fmt.Errorf("streaming writer: set temp_store=MEMORY: %w", err)
+		}
+	}
+
+	eventStmt, err := dbConn.Prepare(`
+		INSERT INTO events (
+			match_id, round_index, type, start_tick, end_tick,
+			actor_steamid, victim_steamid, severity, confidence, meta_json
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("streaming writer: prepare event statement: %w", err)
+	}
+
+	positionStmt, err := dbConn.Prepare(`
+		INSERT OR REPLACE INTO player_positions (
+			match_id, round_index, tick, steamid, x, y, z, yaw, team, health, armor, weapon
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		eventStmt.Close()
+		return nil, fmt.Errorf("streaming writer: prepare position statement: %w", err)
+	}
+
+	ensurePlayerStmt, err := dbConn.Prepare(`
+		INSERT OR IGNORE INTO players (match_id, steamid, name, team)
+		VALUES (?, ?, ?, ?)
+	`)
+	if err != nil {
+		eventStmt.Close()
+		positionStmt.Close()
+		return nil, fmt.Errorf("streaming writer: prepare ensure-player statement: %w", err)
+	}
+
+	grenadePositionStmt, err := dbConn.Prepare(`
+		INSERT OR REPLACE INTO grenade_positions (
+			match_id, round_index, tick, projectile_id, grenade_name,
+			x, y, z, thrower_steamid, thrower_name, thrower_team
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		eventStmt.Close()
+		positionStmt.Close()
+		ensurePlayerStmt.Close()
+		return nil, fmt.Errorf("streaming writer: prepare grenade position statement: %w", err)
+	}
+
+	shotStmt, err := dbConn.Prepare(`
+		INSERT INTO shots (
+			match_id, round_index, tick, steamid, weapon_name,
+			x, y, z, yaw, pitch, team
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		eventStmt.Close()
+		positionStmt.Close()
+		ensurePlayerStmt.Close()
+		grenadePositionStmt.Close()
+		return nil, fmt.Errorf("streaming writer: prepare shot statement: %w", err)
+	}
+
+	return &StreamingWriter{
+		db:                  dbConn,
+		cfg:                 cfg,
+		eventCh:             make(chan []Event, cfg.QueueSize),
+		positionCh:          make(chan []PlayerPosition, cfg.QueueSize),
+		grenadePositionCh:   make(chan []GrenadePosition, cfg.QueueSize),
+		shotCh:              make(chan []Shot, cfg.QueueSize),
+		eventStmt:           eventStmt,
+		positionStmt:        positionStmt,
+		ensurePlayerStmt:    ensurePlayerStmt,
+		grenadePositionStmt: grenadePositionStmt,
+		shotStmt:            shotStmt,
+	}, nil
+}
+
+// SetWatcher attaches a Watcher the same way Writer.SetWatcher does;
+// committed events are published once their batch's transaction commits.
+func (w *StreamingWriter) SetWatcher(watcher *Watcher) {
+	w.watcher = watcher
+}
+
+// Start launches the background flush goroutine. A no-op if already started.
+func (w *StreamingWriter) Start() {
+	if w.stopCh != nil {
+		return
+	}
+	w.stopCh = make(chan struct{})
+	w.doneCh = make(chan struct{})
+	go w.loop()
+}
+
+// Stop halts the flush goroutine, flushing (not dropping) whatever was still
+// queued, then closes the prepared statements. Safe to call more than once.
+func (w *StreamingWriter) Stop() error {
+	if w.stopCh == nil {
+		return nil
+	}
+	close(w.stopCh)
+	<-w.doneCh
+	w.stopCh = nil
+
+	var firstErr error
+	for _, stmt := range []*sql.Stmt{w.eventStmt, w.positionStmt, w.ensurePlayerStmt, w.grenadePositionStmt, w.shotStmt} {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// EnqueueEvents queues events for the next flush. Blocks once QueueSize
+// batches are already queued, applying backpressure to the caller instead
+// of silently dropping rows - unlike webhooks.Dispatcher.Emit, which can
+// tolerate dropping a lifecycle notification, losing an extracted event or
+// a tick of position data is not acceptable.
+func (w *StreamingWriter) EnqueueEvents(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	w.eventCh <- events
+}
+
+// EnqueuePlayerPositions queues positions for the next flush. See
+// EnqueueEvents for the blocking/backpressure contract.
+func (w *StreamingWriter) EnqueuePlayerPositions(positions []PlayerPosition) {
+	if len(positions) == 0 {
+		return
+	}
+	w.positionCh <- positions
+}
+
+// EnqueueGrenadePositions queues positions for the next flush. See
+// EnqueueEvents for the blocking/backpressure contract.
+func (w *StreamingWriter) EnqueueGrenadePositions(positions []GrenadePosition) {
+	if len(positions) == 0 {
+		return
+	}
+	w.grenadePositionCh <- positions
+}
+
+// EnqueueShots queues shots for the next flush. See EnqueueEvents for the
+// blocking/backpressure contract.
+func (w *StreamingWriter) EnqueueShots(shots []Shot) {
+	if len(shots) == 0 {
+		return
+	}
+	w.shotCh <- shots
+}
+
+// Stats returns a snapshot of lifetime throughput counters.
+func (w *StreamingWriter) Stats() StreamingStats {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+	return w.stats
+}
+
+// loop is the single background goroutine that owns every queue's
+// accumulation buffer - since only this goroutine ever touches them, no
+// mutex is needed around the buffers themselves, only around stats (read
+// from other goroutines via Stats).
+func (w *StreamingWriter) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.cfg.MaxBatchInterval)
+	defer ticker.Stop()
+
+	var events []Event
+	var positions []PlayerPosition
+	var grenadePositions []GrenadePosition
+	var shots []Shot
+
+	flush := func() {
+		if len(events) == 0 && len(positions) == 0 && len(grenadePositions) == 0 && len(shots) == 0 {
+			return
+		}
+		w.flushBatch(events, positions, grenadePositions, shots)
+		events, positions, grenadePositions, shots = nil, nil, nil, nil
+	}
+
+	for {
+		select {
+		case batch := <-w.eventCh:
+			events = append(events, batch...)
+			if len(events) >= w.cfg.MaxBatchSize {
+				flush()
+			}
+		case batch := <-w.positionCh:
+			positions = append(positions, batch...)
+			if len(positions) >= w.cfg.MaxBatchSize {
+				flush()
+			}
+		case batch := <-w.grenadePositionCh:
+			grenadePositions = append(grenadePositions, batch...)
+			if len(grenadePositions) >= w.cfg.MaxBatchSize {
+				flush()
+			}
+		case batch := <-w.shotCh:
+			shots = append(shots, batch...)
+			if len(shots) >= w.cfg.MaxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-w.stopCh:
+			w.drainRemaining(&events, &positions, &grenadePositions, &shots)
+			flush()
+			return
+		}
+	}
+}
+
+// drainRemaining folds whatever is already sitting in the queues into the
+// in-progress buffers, mirroring webhooks.Dispatcher.drainRemaining's
+// best-effort flush-before-halt behavior.
+func (w *StreamingWriter) drainRemaining(events *[]Event, positions *[]PlayerPosition, grenadePositions *[]GrenadePosition, shots *[]Shot) {
+	for {
+		select {
+		case batch := <-w.eventCh:
+			*events = append(*events, batch...)
+		case batch := <-w.positionCh:
+			*positions = append(*positions, batch...)
+		case batch := <-w.grenadePositionCh:
+			*grenadePositions = append(*grenadePositions, batch...)
+		case batch := <-w.shotCh:
+			*shots = append(*shots, batch...)
+		default:
+			return
+		}
+	}
+}
+
+// flushBatch writes every non-empty queue's rows in one transaction via the
+// long-lived prepared statements, bound to the transaction with
+// tx.StmtContext so each flush still commits atomically. Runs on the
+// background goroutine, so it has no caller context to respect; a failure
+// is recorded in stats.LastError rather than returned, and the batch is
+// dropped rather than retried (see db.RetryingWriter for that).
+func (w *StreamingWriter) flushBatch(events []Event, positions []PlayerPosition, grenadePositions []GrenadePosition, shots []Shot) {
+	ctx := context.Background()
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		w.recordError(fmt.Errorf("streaming writer: begin transaction: %w", err))
+		return
+	}
+	defer tx.Rollback()
+
+	if len(events) > 0 {
+		stmt := tx.StmtContext(ctx, w.eventStmt)
+		for _, e := range events {
+			if _, err := stmt.ExecContext(ctx,
+				e.MatchID, e.RoundIndex, e.Type, e.StartTick, e.EndTick,
+				e.ActorSteamID, e.VictimSteamID, e.Severity, e.Confidence, e.MetaJSON,
+			); err != nil {
+				w.recordError(fmt.Errorf("streaming writer: insert event: %w", err))
+				return
+			}
+		}
+	}
+
+	if len(positions) > 0 {
+		if err := w.ensurePlayersExist(ctx, tx, positions); err != nil {
+			w.recordError(err)
+			return
+		}
+		stmt := tx.StmtContext(ctx, w.positionStmt)
+		for _, pos := range positions {
+			if _, err := stmt.ExecContext(ctx,
+				pos.MatchID, pos.RoundIndex, pos.Tick, pos.SteamID, pos.X, pos.Y, pos.Z, pos.Yaw, pos.Team,
+				pos.Health, pos.Armor, pos.Weapon,
+			); err != nil {
+				w.recordError(fmt.Errorf("streaming writer: insert player position: %w", err))
+				return
+			}
+		}
+	}
+
+	if len(grenadePositions) > 0 {
+		stmt := tx.StmtContext(ctx, w.grenadePositionStmt)
+		for _, pos := range grenadePositions {
+			if _, err := stmt.ExecContext(ctx,
+				pos.MatchID, pos.RoundIndex, pos.Tick, int64(pos.ProjectileID), pos.GrenadeName,
+				pos.X, pos.Y, pos.Z, pos.ThrowerSteamID, pos.ThrowerName, pos.ThrowerTeam,
+			); err != nil {
+				w.recordError(fmt.Errorf("streaming writer: insert grenade position: %w", err))
+				return
+			}
+		}
+	}
+
+	if len(shots) > 0 {
+		stmt := tx.StmtContext(ctx, w.shotStmt)
+		for _, shot := range shots {
+			if _, err := stmt.ExecContext(ctx,
+				shot.MatchID, shot.RoundIndex, shot.Tick, shot.SteamID, shot.WeaponName,
+				shot.X, shot.Y, shot.Z, shot.Yaw, shot.Pitch, shot.Team,
+			); err != nil {
+				w.recordError(fmt.Errorf("streaming writer: insert shot: %w", err))
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		w.recordError(fmt.Errorf("streaming writer: commit: %w", err))
+		return
+	}
+
+	if w.watcher != nil && len(events) > 0 {
+		byMatch := make(map[string][]Event)
+		for _, e := range events {
+			byMatch[e.MatchID] = append(byMatch[e.MatchID], e)
+		}
+		for matchID, matchEvents := range byMatch {
+			w.watcher.PublishEvents(matchID, matchEvents)
+		}
+	}
+
+	w.statsMu.Lock()
+	w.stats.EventsWritten += int64(len(events))
+	w.stats.PositionsWritten += int64(len(positions))
+	w.stats.GrenadePositionsWritten += int64(len(grenadePositions))
+	w.stats.ShotsWritten += int64(len(shots))
+	w.stats.Flushes++
+	w.statsMu.Unlock()
+}
+
+// ensurePlayersExist inserts a placeholder row (default name "Player_<id>")
+// for any (match_id, steamid) pair in positions not already in players, so
+// the position insert's foreign key constraint is satisfied - the same
+// dedup-then-INSERT-OR-IGNORE approach Writer.InsertPlayerPositions uses.
+func (w *StreamingWriter) ensurePlayersExist(ctx context.Context, tx *sql.Tx, positions []PlayerPosition) error {
+	seen := make(map[string]bool, len(positions))
+	stmt := tx.StmtContext(ctx, w.ensurePlayerStmt)
+	for _, pos := range positions {
+		key := pos.MatchID + "|" + pos.SteamID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, err := stmt.ExecContext(ctx, pos.MatchID, pos.SteamID, fmt.Sprintf("Player_%s", pos.SteamID), nil); err != nil {
+			return fmt.Errorf("streaming writer: ensure player exists %s/%s: %w", pos.MatchID, pos.SteamID, err)
+		}
+	}
+	return nil
+}
+
+func (w *StreamingWriter) recordError(err error) {
+	w.statsMu.Lock()
+	w.stats.LastError = err
+	w.statsMu.Unlock()
+}
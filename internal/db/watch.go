@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"sync"
+)
+
+// PlayerScoreSnapshot is a revisioned view of a single player's score,
+// published to WatchScores subscribers as the scorer recomputes it.
+type PlayerScoreSnapshot struct {
+	Score    PlayerScore
+	Revision uint64
+}
+
+// Watcher lets consumers subscribe to events and player-score updates for
+// a match as they are written, instead of polling SQLite for progress.
+// Writer calls Publish* after each committed batch; Watcher fans the new
+// rows out to every channel currently registered for that match ID.
+//
+// Revision is a per-match, monotonically increasing counter bumped on
+// every publish, mirroring the revision concept used by watch-channel
+// style stores: a subscriber that reconnects can compare the revision it
+// last saw against Revision to decide whether it missed anything.
+type Watcher struct {
+	mu        sync.Mutex
+	revision  map[string]uint64
+	eventSubs map[string][]chan Event
+	scoreSubs map[string][]chan PlayerScoreSnapshot
+}
+
+// NewWatcher creates an empty Watcher.
+func NewWatcher() *Watcher {
+	return &Watcher{
+		revision:  make(map[string]uint64),
+		eventSubs: make(map[string][]chan Event),
+		scoreSubs: make(map[string][]chan PlayerScoreSnapshot),
+	}
+}
+
+// WatchEvents returns a channel that receives every Event published for
+// matchID from this point forward. The channel is closed when ctx is
+// canceled; callers must keep draining it until then to avoid blocking
+// PublishEvents.
+func (w *Watcher) WatchEvents(ctx context.Context, matchID string) (<-chan Event, error) {
+	ch := make(chan Event, 64)
+
+	w.mu.Lock()
+	w.eventSubs[matchID] = append(w.eventSubs[matchID], ch)
+	w.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.removeEventSub(matchID, ch)
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// WatchScores returns a channel that receives a PlayerScoreSnapshot every
+// time ComputeScores recomputes scores for matchID.
+func (w *Watcher) WatchScores(ctx context.Context, matchID string) (<-chan PlayerScoreSnapshot, error) {
+	ch := make(chan PlayerScoreSnapshot, 64)
+
+	w.mu.Lock()
+	w.scoreSubs[matchID] = append(w.scoreSubs[matchID], ch)
+	w.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.removeScoreSub(matchID, ch)
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// PublishEvents fans out a committed batch of events to every subscriber
+// registered for matchID. Sends are non-blocking: a subscriber too slow
+// to drain its buffered channel misses the update rather than stalling
+// the writer.
+func (w *Watcher) PublishEvents(matchID string, events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	w.revision[matchID]++
+	subs := append([]chan Event(nil), w.eventSubs[matchID]...)
+	w.mu.Unlock()
+
+	for _, ch := range subs {
+		for _, e := range events {
+			select {
+			case ch <- e:
+			default:
+			}
+		}
+	}
+}
+
+// PublishScore fans out a recomputed PlayerScore to every subscriber
+// registered for matchID, stamped with the match's current revision.
+func (w *Watcher) PublishScore(matchID string, score PlayerScore) {
+	w.mu.Lock()
+	w.revision[matchID]++
+	rev := w.revision[matchID]
+	subs := append([]chan PlayerScoreSnapshot(nil), w.scoreSubs[matchID]...)
+	w.mu.Unlock()
+
+	snap := PlayerScoreSnapshot{Score: score, Revision: rev}
+	for _, ch := range subs {
+		select {
+		case ch <- snap:
+		default:
+		}
+	}
+}
+
+// Revision returns the current revision counter for matchID.
+func (w *Watcher) Revision(matchID string) uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.revision[matchID]
+}
+
+func (w *Watcher) removeEventSub(matchID string, target chan Event) {
+	subs := w.eventSubs[matchID]
+	for i, ch := range subs {
+		if ch == target {
+			w.eventSubs[matchID] = append(subs[:i], subs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (w *Watcher) removeScoreSub(matchID string, target chan PlayerScoreSnapshot) {
+	subs := w.scoreSubs[matchID]
+	for i, ch := range subs {
+		if ch == target {
+			w.scoreSubs[matchID] = append(subs[:i], subs[i+1:]...)
+			return
+		}
+	}
+}
@@ -10,7 +10,8 @@ import (
 
 // Writer provides methods to write CS2 demo data to the database.
 type Writer struct {
-	db *sql.DB
+	db      *sql.DB
+	watcher *Watcher
 }
 
 // NewWriter creates a new database writer.
@@ -18,6 +19,13 @@ func NewWriter(db *sql.DB) *Writer {
 	return &Writer{db: db}
 }
 
+// SetWatcher attaches a Watcher so that committed batches are fanned out
+// to live subscribers (see WatchEvents/WatchScores). Optional: a Writer
+// with no watcher behaves exactly as before.
+func (w *Writer) SetWatcher(watcher *Watcher) {
+	w.watcher = watcher
+}
+
 // Match represents a CS2 match.
 type Match struct {
 	ID        string
@@ -29,14 +37,14 @@ type Match struct {
 
 // Player represents a player in a match.
 type Player struct {
-	MatchID            string
-	SteamID            string
-	Name               string
-	Team               string // "A" or "B" (Team A/Team B)
-	ConnectedMidgame   bool   // True if player connected after round 1
+	MatchID             string
+	SteamID             string
+	Name                string
+	Team                string // "A" or "B" (Team A/Team B)
+	ConnectedMidgame    bool   // True if player connected after round 1
 	PermanentDisconnect bool   // True if player disconnected and never returned
-	FirstConnectRound  *int   // Round index when player first connected (nil if round 0)
-	DisconnectRound    *int   // Round index when player disconnected (nil if never disconnected)
+	FirstConnectRound   *int   // Round index when player first connected (nil if round 0)
+	DisconnectRound     *int   // Round index when player disconnected (nil if never disconnected)
 }
 
 // Round represents a round in a match.
@@ -63,6 +71,15 @@ type Event struct {
 	Severity      *float64
 	Confidence    *float64
 	MetaJSON      *string // JSON string for additional metadata
+
+	// Status and AttemptCount track RetryingWriter's write lifecycle
+	// ("pending", "committed" or "failed" - see RetryingWriter). InsertEvent
+	// and BatchInsertEvents never set these, leaving the events.status
+	// column's "committed" default, since they write inside one
+	// transaction with no partial-write state to track. Populated when an
+	// Event comes back from Reader.GetFailedEvents.
+	Status       string
+	AttemptCount int
 }
 
 // InsertMatch inserts or replaces a match record.
@@ -93,7 +110,7 @@ func (w *Writer) InsertPlayer(ctx context.Context, p Player) error {
 	if p.PermanentDisconnect {
 		permanentDisconnect = 1
 	}
-	
+
 	query := `
 		INSERT OR REPLACE INTO players (match_id, steamid, name, team, connected_midgame, permanent_disconnect, first_connect_round, disconnect_round)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
@@ -153,6 +170,18 @@ func (w *Writer) SetMeta(ctx context.Context, key, value string) error {
 	return nil
 }
 
+// RecordFailedDemo inserts a failed_demos row for a demo that errored out
+// of a batch run (see pipeline.BatchRunner) instead of aborting the rest
+// of the batch, so operators can retry just the failures afterward.
+func (w *Writer) RecordFailedDemo(ctx context.Context, demoPath, errMsg, stack string) error {
+	query := `INSERT INTO failed_demos (demo_path, error, stack, failed_at) VALUES (?, ?, ?, ?)`
+	_, err := w.db.ExecContext(ctx, query, demoPath, errMsg, stack, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to record failed demo: %w", err)
+	}
+	return nil
+}
+
 // BatchInsertEvents inserts multiple events in a single transaction.
 func (w *Writer) BatchInsertEvents(ctx context.Context, events []Event) error {
 	tx, err := w.db.BeginTx(ctx, nil)
@@ -189,26 +218,54 @@ func (w *Writer) BatchInsertEvents(ctx context.Context, events []Event) error {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if w.watcher != nil {
+		byMatch := make(map[string][]Event)
+		for _, e := range events {
+			byMatch[e.MatchID] = append(byMatch[e.MatchID], e)
+		}
+		for matchID, matchEvents := range byMatch {
+			w.watcher.PublishEvents(matchID, matchEvents)
+		}
+	}
+
 	return nil
 }
 
-// InsertPlayerScore inserts or replaces a player score record.
+// InsertPlayerScore inserts or replaces a player score record. An empty
+// score.ProfileName is stored as "default", matching scoring.DefaultProfile's
+// Name and player_scores.profile_name's column default.
 func (w *Writer) InsertPlayerScore(ctx context.Context, score PlayerScore) error {
+	profileName := score.ProfileName
+	if profileName == "" {
+		profileName = "default"
+	}
+	var topReasonsJSON *string
+	if len(score.TopReasons) > 0 {
+		encoded, err := json.Marshal(score.TopReasons)
+		if err != nil {
+			return fmt.Errorf("failed to marshal top reasons: %w", err)
+		}
+		s := string(encoded)
+		topReasonsJSON = &s
+	}
 	query := `
 		INSERT OR REPLACE INTO player_scores (
-			match_id, steamid, team_kills, team_damage, team_flash_seconds,
-			afk_seconds, body_block_seconds, grief_score
+			match_id, steamid, profile_name, team_kills, team_damage, team_flash_seconds,
+			afk_seconds, body_block_seconds, economy_grief_count, grief_score, top_reasons_json
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := w.db.ExecContext(ctx, query,
-		score.MatchID, score.SteamID, score.TeamKills, score.TeamDamage,
+		score.MatchID, score.SteamID, profileName, score.TeamKills, score.TeamDamage,
 		score.TeamFlashSeconds, score.AFKSeconds, score.BodyBlockSeconds,
-		score.GriefScore,
+		score.EconomyGriefCount, score.GriefScore, topReasonsJSON,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert player score: %w", err)
 	}
+	if w.watcher != nil {
+		w.watcher.PublishScore(score.MatchID, score)
+	}
 	return nil
 }
 
@@ -269,6 +326,74 @@ func (w *Writer) InsertChatMessages(ctx context.Context, messages []ChatMessage)
 	return nil
 }
 
+// System chat event kinds - see SystemChatEvent.Kind.
+const (
+	SystemChatKindConnect     = "connect"
+	SystemChatKindDisconnect  = "disconnect"
+	SystemChatKindNameChange  = "name_change"
+	SystemChatKindKick        = "kick"
+	SystemChatKindBan         = "ban"
+	SystemChatKindMapChange   = "map_change"
+	SystemChatKindRoundResult = "round_result"
+	SystemChatKindServerCvar  = "server_cvar"
+)
+
+// SystemChatEvent represents one server/system log line (player join/leave,
+// kick/ban, name change, map/round transition, cvar change) distinct from
+// player chat. Unlike ChatMessage, SteamID is optional: some kinds aren't
+// attributable to a single player (map_change, round_result, server_cvar),
+// and a connect announcement can fire before the player's row exists.
+type SystemChatEvent struct {
+	MatchID    string
+	RoundIndex int
+	Tick       int
+	Kind       string
+	SteamID    *string
+	Name       *string
+	Message    string
+}
+
+// InsertSystemChatEvents inserts multiple system chat events in a transaction.
+func (w *Writer) InsertSystemChatEvents(ctx context.Context, events []SystemChatEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT OR IGNORE INTO chat_system_messages (
+			match_id, round_index, tick, kind, steamid, name, message
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		_, err := stmt.ExecContext(ctx,
+			event.MatchID, event.RoundIndex, event.Tick, event.Kind, event.SteamID, event.Name, event.Message,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert system chat event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // PlayerPosition represents a player's position at a specific tick.
 type PlayerPosition struct {
 	MatchID    string
@@ -279,7 +404,7 @@ type PlayerPosition struct {
 	Y          float64
 	Z          float64
 	Yaw        *float64 // View angle (yaw) in degrees
-	Team       *string // "T" or "CT"
+	Team       *string  // "T" or "CT"
 	Health     *int
 	Armor      *int
 	Weapon     *string
@@ -305,7 +430,7 @@ func (w *Writer) InsertPlayerPositions(ctx context.Context, positions []PlayerPo
 		MatchID string
 		SteamID string
 	}, 0)
-	
+
 	for _, pos := range positions {
 		key := pos.MatchID + "|" + pos.SteamID
 		if !playerSet[key] {
@@ -369,6 +494,177 @@ func (w *Writer) InsertPlayerPositions(ctx context.Context, positions []PlayerPo
 	return nil
 }
 
+// InsertPlayerPositionKeyframes inserts full position rows into
+// player_positions_keyframes - see PlayerPositionDelta and
+// --position-encoding=delta.
+func (w *Writer) InsertPlayerPositionKeyframes(ctx context.Context, keyframes []PlayerPosition) error {
+	if len(keyframes) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := ensurePlayersExist(ctx, tx, positionPlayerKeys(keyframes)); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT OR REPLACE INTO player_positions_keyframes (
+			match_id, round_index, tick, steamid, x, y, z, yaw, team, health, armor, weapon
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, pos := range keyframes {
+		_, err := stmt.ExecContext(ctx,
+			pos.MatchID, pos.RoundIndex, pos.Tick, pos.SteamID, pos.X, pos.Y, pos.Z, pos.Yaw, pos.Team,
+			pos.Health, pos.Armor, pos.Weapon,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert player position keyframe: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PlayerPositionDelta is one sampled tick's movement relative to the
+// preceding keyframe or delta in --position-encoding=delta mode, in the
+// units ParseWithDB's delta encoder produces: DX/DY/DZ are whole world
+// units and YawDelta is in 0.5 degree steps - see db.ReconstructPositions,
+// which folds a round's keyframes/deltas back into []PlayerPosition.
+type PlayerPositionDelta struct {
+	MatchID    string
+	RoundIndex int
+	Tick       int
+	SteamID    string
+	DX         int16
+	DY         int16
+	DZ         int16
+	YawDelta   int16
+	Team       *string
+	Health     *int
+	Armor      *int
+	Weapon     *string
+}
+
+// InsertPlayerPositionDeltas inserts delta rows into
+// player_positions_deltas - see PlayerPositionDelta.
+func (w *Writer) InsertPlayerPositionDeltas(ctx context.Context, deltas []PlayerPositionDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	keys := make([]struct {
+		MatchID string
+		SteamID string
+	}, len(deltas))
+	for i, d := range deltas {
+		keys[i] = struct {
+			MatchID string
+			SteamID string
+		}{d.MatchID, d.SteamID}
+	}
+	if err := ensurePlayersExist(ctx, tx, keys); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT OR REPLACE INTO player_positions_deltas (
+			match_id, round_index, tick, steamid, dx, dy, dz, yaw_delta, team, health, armor, weapon
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, d := range deltas {
+		_, err := stmt.ExecContext(ctx,
+			d.MatchID, d.RoundIndex, d.Tick, d.SteamID, d.DX, d.DY, d.DZ, d.YawDelta, d.Team,
+			d.Health, d.Armor, d.Weapon,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert player position delta: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// positionPlayerKeys collects the unique (match_id, steamid) pairs out of
+// positions, for ensurePlayersExist.
+func positionPlayerKeys(positions []PlayerPosition) []struct {
+	MatchID string
+	SteamID string
+} {
+	seen := make(map[string]bool)
+	keys := make([]struct {
+		MatchID string
+		SteamID string
+	}, 0)
+	for _, pos := range positions {
+		key := pos.MatchID + "|" + pos.SteamID
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, struct {
+				MatchID string
+				SteamID string
+			}{pos.MatchID, pos.SteamID})
+		}
+	}
+	return keys
+}
+
+// ensurePlayersExist inserts a default-named row for each (match_id,
+// steamid) pair not already in players, so position inserts (keyframe or
+// delta) satisfy the foreign key constraint the same way
+// InsertPlayerPositions does.
+func ensurePlayersExist(ctx context.Context, tx *sql.Tx, keys []struct {
+	MatchID string
+	SteamID string
+}) error {
+	query := `INSERT OR IGNORE INTO players (match_id, steamid, name, team) VALUES (?, ?, ?, ?)`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare player statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, k := range keys {
+		defaultName := fmt.Sprintf("Player_%s", k.SteamID)
+		if _, err := stmt.ExecContext(ctx, k.MatchID, k.SteamID, defaultName, nil); err != nil {
+			return fmt.Errorf("failed to ensure player exists %s/%s: %w", k.MatchID, k.SteamID, err)
+		}
+	}
+	return nil
+}
+
 // GrenadePosition represents a grenade's position at a specific tick.
 type GrenadePosition struct {
 	MatchID        string
@@ -398,6 +694,13 @@ type GrenadeEvent struct {
 	ThrowerSteamID *string
 	ThrowerName    *string
 	ThrowerTeam    *string
+	// FiresJSON is only set for inferno_start/inferno_update/inferno_expire
+	// rows: a JSON array of {x,y,z,is_burning} for every flame demoinfocs is
+	// tracking for that inferno (past + present), so a 2D renderer can draw
+	// the actual burning area instead of a single point. X/Y/Z above are the
+	// centroid of the currently-active flames (or of all flames if none are
+	// still burning, e.g. at inferno_expire) rather than the grenade's origin.
+	FiresJSON *string
 }
 
 // InsertGrenadePositions inserts multiple grenade positions in a transaction.
@@ -458,9 +761,9 @@ func (w *Writer) InsertGrenadeEvents(ctx context.Context, events []GrenadeEvent)
 	query := `
 		INSERT INTO grenade_events (
 			match_id, round_index, tick, event_type, projectile_id, grenade_name,
-			x, y, z, thrower_steamid, thrower_name, thrower_team
+			x, y, z, thrower_steamid, thrower_name, thrower_team, fires_json
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	stmt, err := tx.PrepareContext(ctx, query)
@@ -472,7 +775,7 @@ func (w *Writer) InsertGrenadeEvents(ctx context.Context, events []GrenadeEvent)
 	for _, e := range events {
 		_, err := stmt.ExecContext(ctx,
 			e.MatchID, e.RoundIndex, e.Tick, e.EventType, int64(e.ProjectileID), e.GrenadeName,
-			e.X, e.Y, e.Z, e.ThrowerSteamID, e.ThrowerName, e.ThrowerTeam,
+			e.X, e.Y, e.Z, e.ThrowerSteamID, e.ThrowerName, e.ThrowerTeam, e.FiresJSON,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert grenade event: %w", err)
@@ -486,6 +789,316 @@ func (w *Writer) InsertGrenadeEvents(ctx context.Context, events []GrenadeEvent)
 	return nil
 }
 
+// BombEvent is one step of the bomb's lifecycle (pickup/drop/plant/defuse/
+// explode) or a periodic position sample taken while it's not attributable
+// to one of those - see EventType and internal/parser's bomb handlers.
+type BombEvent struct {
+	MatchID        string
+	RoundIndex     int
+	Tick           int
+	EventType      string
+	CarrierSteamID *string
+	X              float64
+	Y              float64
+	Z              float64
+	Site           *string
+	DefuserSteamID *string
+	HasKit         bool
+}
+
+// InsertBombEvents inserts multiple bomb events in a transaction.
+func (w *Writer) InsertBombEvents(ctx context.Context, events []BombEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO bomb_events (
+			match_id, round_index, tick, event_type, carrier_steamid,
+			x, y, z, site, defuser_steamid, has_kit
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range events {
+		_, err := stmt.ExecContext(ctx,
+			e.MatchID, e.RoundIndex, e.Tick, e.EventType, e.CarrierSteamID,
+			e.X, e.Y, e.Z, e.Site, e.DefuserSteamID, e.HasKit,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert bomb event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GrenadePrediction is a projectile's predicted detonation/landing point,
+// computed the moment it's first observed (see internal/parser/ballistics),
+// paired against the actual GrenadeEvent recorded later for the same
+// ProjectileID.
+type GrenadePrediction struct {
+	MatchID       string
+	RoundIndex    int
+	Tick          int
+	ProjectileID  uint64
+	GrenadeName   string
+	PredictedX    float64
+	PredictedY    float64
+	PredictedZ    float64
+	PredictedTick int
+	EffectRadius  float64
+}
+
+// InsertGrenadePredictions inserts multiple grenade predictions in a
+// transaction.
+func (w *Writer) InsertGrenadePredictions(ctx context.Context, predictions []GrenadePrediction) error {
+	if len(predictions) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT OR REPLACE INTO grenade_predictions (
+			match_id, round_index, tick, projectile_id, grenade_name,
+			predicted_x, predicted_y, predicted_z, predicted_tick, effect_radius
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range predictions {
+		_, err := stmt.ExecContext(ctx,
+			p.MatchID, p.RoundIndex, p.Tick, int64(p.ProjectileID), p.GrenadeName,
+			p.PredictedX, p.PredictedY, p.PredictedZ, p.PredictedTick, p.EffectRadius,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert grenade prediction: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Input button bits packed into PlayerInput.Buttons - our own compact
+// layout, not the engine's sparse common.ButtonBitMask values, since only
+// this subset is reconstructed (see PlayerInput).
+const (
+	InputButtonForward = 1 << iota
+	InputButtonBack
+	InputButtonLeft
+	InputButtonRight
+	InputButtonJump
+	InputButtonDuck
+	InputButtonAttack
+	InputButtonReload
+	InputButtonUse
+	InputButtonWalk
+)
+
+// PlayerInput is a player's reconstructed button state on one tick, behind
+// --record-inputs. ForwardMove/SideMove are signed speeds (units/s) along
+// the player's view basis at that tick, derived from consecutive Position()
+// samples rather than read directly - demoinfocs-golang doesn't expose the
+// analog move values CS2's own net code computed them from.
+type PlayerInput struct {
+	MatchID     string
+	RoundIndex  int
+	Tick        int
+	SteamID     string
+	Buttons     int
+	ForwardMove float64
+	SideMove    float64
+}
+
+// InsertPlayerInputs inserts multiple player inputs in a transaction.
+func (w *Writer) InsertPlayerInputs(ctx context.Context, inputs []PlayerInput) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT OR REPLACE INTO player_inputs (
+			match_id, round_index, tick, steamid, buttons, forwardmove, sidemove
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, in := range inputs {
+		_, err := stmt.ExecContext(ctx,
+			in.MatchID, in.RoundIndex, in.Tick, in.SteamID, in.Buttons, in.ForwardMove, in.SideMove,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert player input: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// WeaponSwitch is one observed ActiveWeapon() change for a player, with an
+// optional paired deploy latency (see InsertWeaponSwitches).
+// DeployTicksUntilFirstFire is nil when no WeaponFire from the same player
+// followed within the bounded deploy-latency window before the next switch
+// or round end.
+type WeaponSwitch struct {
+	MatchID                   string
+	RoundIndex                int
+	Tick                      int
+	SteamID                   string
+	PrevWeapon                *string
+	NewWeapon                 string
+	PrevAmmo                  *int
+	NewAmmo                   *int
+	WasEmpty                  bool
+	DeployTicksUntilFirstFire *int
+}
+
+// InsertWeaponSwitches inserts multiple weapon switches in a transaction.
+func (w *Writer) InsertWeaponSwitches(ctx context.Context, switches []WeaponSwitch) error {
+	if len(switches) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT OR REPLACE INTO weapon_switches (
+			match_id, round_index, tick, steamid, prev_weapon, new_weapon,
+			prev_ammo, new_ammo, was_empty, deploy_ticks_until_first_fire
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, s := range switches {
+		_, err := stmt.ExecContext(ctx,
+			s.MatchID, s.RoundIndex, s.Tick, s.SteamID, s.PrevWeapon, s.NewWeapon,
+			s.PrevAmmo, s.NewAmmo, s.WasEmpty, s.DeployTicksUntilFirstFire,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert weapon switch: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PlayerRoundWeaponStats aggregates one player's weapon_switches for a
+// single round. AvgDeploySeconds is nil when none of the round's switches
+// paired with a first-fire deploy latency.
+type PlayerRoundWeaponStats struct {
+	MatchID            string
+	RoundIndex         int
+	SteamID            string
+	SwitchesPerRound   int
+	AvgDeploySeconds   *float64
+	SwitchesToEmptyGun int
+}
+
+// InsertPlayerRoundWeaponStats inserts multiple per-round weapon stat rows
+// in a transaction.
+func (w *Writer) InsertPlayerRoundWeaponStats(ctx context.Context, stats []PlayerRoundWeaponStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT OR REPLACE INTO player_round_weapon_stats (
+			match_id, round_index, steamid, switches_per_round,
+			avg_deploy_seconds, switches_to_empty_gun
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, s := range stats {
+		_, err := stmt.ExecContext(ctx,
+			s.MatchID, s.RoundIndex, s.SteamID, s.SwitchesPerRound,
+			s.AvgDeploySeconds, s.SwitchesToEmptyGun,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert player round weapon stats: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // MetaJSON creates a JSON string from a map for use in Event.MetaJSON.
 func MetaJSON(m map[string]interface{}) (*string, error) {
 	if m == nil || len(m) == 0 {
@@ -557,6 +1170,134 @@ func (w *Writer) InsertShots(ctx context.Context, shots []Shot) error {
 	return nil
 }
 
+// careerEWMAAlpha weights each newly folded match's grief_score against a
+// player's running career_players.grief_score_ewma: new = alpha*match +
+// (1-alpha)*old. 0.3 favors recent form over a long career average without
+// letting one bad match dominate it, the same "smoothed but responsive"
+// tradeoff AFKExtractor's speed-threshold debounce makes for single-event
+// noise.
+const careerEWMAAlpha = 0.3
+
+// UpsertCareerFromMatch reads matchID's player_scores (profile_name
+// "default") and folds each player's contribution into career_players,
+// the cross-match reputation table careers.Recompute rebuilds from
+// scratch. It records the fold in career_match_stats first and skips any
+// player already recorded there for matchID, so calling this twice for the
+// same match (e.g. a retried --batch job) doesn't double-count it.
+func (w *Writer) UpsertCareerFromMatch(ctx context.Context, matchID string) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var startedAt sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT started_at FROM matches WHERE id = ?`, matchID).Scan(&startedAt)
+	if err != nil {
+		return fmt.Errorf("failed to look up match %s: %w", matchID, err)
+	}
+	seenAt := time.Now().Format(time.RFC3339)
+	if startedAt.Valid && startedAt.String != "" {
+		seenAt = startedAt.String
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT steamid, team_kills, team_damage, team_flash_seconds, afk_seconds, body_block_seconds, grief_score
+		FROM player_scores
+		WHERE match_id = ? AND profile_name = 'default'
+	`, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to query player scores: %w", err)
+	}
+
+	type matchStat struct {
+		steamID          string
+		teamKills        int
+		teamDamage       float64
+		teamFlashSeconds float64
+		afkSeconds       float64
+		bodyBlockSeconds float64
+		griefScore       float64
+	}
+	var stats []matchStat
+	for rows.Next() {
+		var s matchStat
+		if err := rows.Scan(&s.steamID, &s.teamKills, &s.teamDamage, &s.teamFlashSeconds, &s.afkSeconds, &s.bodyBlockSeconds, &s.griefScore); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan player score: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("error iterating player scores: %w", err)
+	}
+	rows.Close()
+
+	for _, s := range stats {
+		var alreadyFolded int
+		err := tx.QueryRowContext(ctx, `SELECT 1 FROM career_match_stats WHERE match_id = ? AND steamid = ?`, matchID, s.steamID).Scan(&alreadyFolded)
+		if err == nil {
+			continue // already folded this match's contribution for this player
+		}
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check career_match_stats: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO career_match_stats (match_id, steamid, team_kills, team_damage, team_flash_seconds, afk_seconds, body_block_seconds, grief_score)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, matchID, s.steamID, s.teamKills, s.teamDamage, s.teamFlashSeconds, s.afkSeconds, s.bodyBlockSeconds, s.griefScore)
+		if err != nil {
+			return fmt.Errorf("failed to insert career_match_stats: %w", err)
+		}
+
+		var matchesPlayed int
+		var teamKills int
+		var teamDamage, teamFlashSeconds, afkSeconds, bodyBlockSeconds, griefScoreEWMA float64
+		var firstSeen, lastSeen sql.NullString
+		err = tx.QueryRowContext(ctx, `
+			SELECT matches_played, team_kills, team_damage, team_flash_seconds, afk_seconds, body_block_seconds, grief_score_ewma, first_seen, last_seen
+			FROM career_players WHERE steamid = ?
+		`, s.steamID).Scan(&matchesPlayed, &teamKills, &teamDamage, &teamFlashSeconds, &afkSeconds, &bodyBlockSeconds, &griefScoreEWMA, &firstSeen, &lastSeen)
+		switch {
+		case err == sql.ErrNoRows:
+			matchesPlayed, teamKills, teamDamage, teamFlashSeconds, afkSeconds, bodyBlockSeconds = 0, 0, 0, 0, 0, 0
+			griefScoreEWMA = s.griefScore
+			firstSeen = sql.NullString{String: seenAt, Valid: true}
+			lastSeen = sql.NullString{String: seenAt, Valid: true}
+		case err != nil:
+			return fmt.Errorf("failed to look up career_players: %w", err)
+		default:
+			griefScoreEWMA = careerEWMAAlpha*s.griefScore + (1-careerEWMAAlpha)*griefScoreEWMA
+			if !firstSeen.Valid || seenAt < firstSeen.String {
+				firstSeen = sql.NullString{String: seenAt, Valid: true}
+			}
+			if !lastSeen.Valid || seenAt > lastSeen.String {
+				lastSeen = sql.NullString{String: seenAt, Valid: true}
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT OR REPLACE INTO career_players (
+				steamid, matches_played, team_kills, team_damage, team_flash_seconds,
+				afk_seconds, body_block_seconds, grief_score_ewma, first_seen, last_seen
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, s.steamID, matchesPlayed+1, teamKills+s.teamKills, teamDamage+s.teamDamage,
+			teamFlashSeconds+s.teamFlashSeconds, afkSeconds+s.afkSeconds, bodyBlockSeconds+s.bodyBlockSeconds,
+			griefScoreEWMA, firstSeen.String, lastSeen.String)
+		if err != nil {
+			return fmt.Errorf("failed to upsert career_players: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // InsertParserLogs inserts parser logs for a match.
 func (w *Writer) InsertParserLogs(ctx context.Context, matchID string, logs string) error {
 	query := `
@@ -0,0 +1,341 @@
+// Package columnar streams ParseWithDB's high-volume telemetry slices
+// (player positions, shots, grenade positions, grenade events) to
+// partitioned per-table files instead of SQLite, for downstream analytical
+// tools (DuckDB, pandas, Polars) to query a match without going through
+// the database.
+//
+// This does not produce real Parquet/Arrow: github.com/apache/arrow/go
+// isn't vendored in this offline build, the same constraint
+// cmd/parser/streaming_parquet.go already worked around for --format
+// parquet in JSON mode. Writer reuses that file's approach rather than
+// inventing a second one: one magic-prefixed file per table, a sequence of
+// row groups (one per InsertX call, so a row group's size already matches
+// ParseWithDB's own 5000/2000-row flush buffers), each column
+// Snappy-compressed independently, with SteamID/WeaponName/GrenadeName/Team
+// dictionary-encoded and Tick delta-encoded against the previous row in the
+// group - both repeat/trend heavily within a round, so this shrinks well
+// below the equivalent SQLite rows even without real Parquet underneath.
+package columnar
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"cs-griefer-electron/internal/db"
+)
+
+const magic = "CS2COL1\x00"
+
+// Config configures a Writer.
+type Config struct {
+	// Dir is created if missing; each table gets its own file under it
+	// (positions.cs2col, shots.cs2col, grenade_positions.cs2col,
+	// grenade_events.cs2col, players.jsonl, chat_messages.jsonl).
+	Dir string
+}
+
+// Writer implements the same telemetry-insert surface internal/db.Writer
+// does (see TelemetryWriter), streaming each call as one row group to its
+// table's file under Config.Dir instead of a SQLite table.
+//
+// Player and ChatMessage rows are comparatively low-volume and aren't the
+// telemetry this package optimizes for, so they're appended as plain
+// NDJSON rather than going through the row-group/dictionary machinery
+// below - simpler, and still inspectable without tooling that understands
+// this package's format.
+type Writer struct {
+	mu    sync.Mutex
+	dir   string
+	files map[string]*os.File
+	bufs  map[string]*bufio.Writer
+}
+
+// NewWriter creates cfg.Dir if needed and returns a Writer over it.
+func NewWriter(cfg Config) (*Writer, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("columnar: failed to create output dir: %w", err)
+	}
+	return &Writer{
+		dir:   cfg.Dir,
+		files: make(map[string]*os.File),
+		bufs:  make(map[string]*bufio.Writer),
+	}, nil
+}
+
+// Close flushes and closes every table file this Writer opened.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var firstErr error
+	for table, buf := range w.bufs {
+		if err := buf.Flush(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("columnar: failed to flush %s: %w", table, err)
+		}
+	}
+	for table, f := range w.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("columnar: failed to close %s: %w", table, err)
+		}
+	}
+	return firstErr
+}
+
+// bufFor returns table's buffered writer, creating its file (and writing
+// the magic header) on first use. Callers must hold w.mu.
+func (w *Writer) bufFor(table, ext string) (*bufio.Writer, error) {
+	if buf, ok := w.bufs[table]; ok {
+		return buf, nil
+	}
+
+	f, err := os.Create(filepath.Join(w.dir, table+ext))
+	if err != nil {
+		return nil, fmt.Errorf("columnar: failed to create %s file: %w", table, err)
+	}
+
+	buf := bufio.NewWriter(f)
+	if ext == rowGroupExt {
+		if _, err := buf.WriteString(magic); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("columnar: failed to write %s header: %w", table, err)
+		}
+	}
+
+	w.files[table] = f
+	w.bufs[table] = buf
+	return buf, nil
+}
+
+const rowGroupExt = ".cs2col"
+
+// InsertPlayerPositions writes positions as one row group to
+// positions.cs2col.
+func (w *Writer) InsertPlayerPositions(ctx context.Context, positions []db.PlayerPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("positions", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writePositionRowGroup(buf, positions)
+}
+
+// InsertPlayerPositionKeyframes writes keyframes as one row group to
+// player_positions_keyframes.cs2col.
+func (w *Writer) InsertPlayerPositionKeyframes(ctx context.Context, keyframes []db.PlayerPosition) error {
+	if len(keyframes) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("player_positions_keyframes", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writePositionRowGroup(buf, keyframes)
+}
+
+// InsertPlayerPositionDeltas writes deltas as one row group to
+// player_positions_deltas.cs2col.
+func (w *Writer) InsertPlayerPositionDeltas(ctx context.Context, deltas []db.PlayerPositionDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("player_positions_deltas", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writePositionDeltaRowGroup(buf, deltas)
+}
+
+// InsertShots writes shots as one row group to shots.cs2col.
+func (w *Writer) InsertShots(ctx context.Context, shots []db.Shot) error {
+	if len(shots) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("shots", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writeShotRowGroup(buf, shots)
+}
+
+// InsertGrenadePositions writes positions as one row group to
+// grenade_positions.cs2col.
+func (w *Writer) InsertGrenadePositions(ctx context.Context, positions []db.GrenadePosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("grenade_positions", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writeGrenadePositionRowGroup(buf, positions)
+}
+
+// InsertGrenadeEvents writes events as one row group to
+// grenade_events.cs2col.
+func (w *Writer) InsertGrenadeEvents(ctx context.Context, events []db.GrenadeEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("grenade_events", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writeGrenadeEventRowGroup(buf, events)
+}
+
+// InsertBombEvents writes events as one row group to bomb_events.cs2col.
+func (w *Writer) InsertBombEvents(ctx context.Context, events []db.BombEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("bomb_events", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writeBombEventRowGroup(buf, events)
+}
+
+// InsertGrenadePredictions writes predictions as one row group to
+// grenade_predictions.cs2col.
+func (w *Writer) InsertGrenadePredictions(ctx context.Context, predictions []db.GrenadePrediction) error {
+	if len(predictions) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("grenade_predictions", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writeGrenadePredictionRowGroup(buf, predictions)
+}
+
+// InsertPlayerInputs writes inputs as one row group to
+// player_inputs.cs2col.
+func (w *Writer) InsertPlayerInputs(ctx context.Context, inputs []db.PlayerInput) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("player_inputs", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writePlayerInputRowGroup(buf, inputs)
+}
+
+// InsertWeaponSwitches writes switches as one row group to
+// weapon_switches.cs2col.
+func (w *Writer) InsertWeaponSwitches(ctx context.Context, switches []db.WeaponSwitch) error {
+	if len(switches) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("weapon_switches", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writeWeaponSwitchRowGroup(buf, switches)
+}
+
+// InsertPlayerRoundWeaponStats writes stats as one row group to
+// player_round_weapon_stats.cs2col.
+func (w *Writer) InsertPlayerRoundWeaponStats(ctx context.Context, stats []db.PlayerRoundWeaponStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("player_round_weapon_stats", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writePlayerRoundWeaponStatsRowGroup(buf, stats)
+}
+
+// InsertPlayer appends player as one NDJSON line to players.jsonl.
+func (w *Writer) InsertPlayer(ctx context.Context, player db.Player) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("players", ".jsonl")
+	if err != nil {
+		return err
+	}
+	return writeJSONLine(buf, player)
+}
+
+// InsertChatMessages appends each message as one NDJSON line to
+// chat_messages.jsonl.
+func (w *Writer) InsertChatMessages(ctx context.Context, messages []db.ChatMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("chat_messages", ".jsonl")
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		if err := writeJSONLine(buf, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertSystemChatEvents appends each event as one NDJSON line to
+// chat_system_messages.jsonl.
+func (w *Writer) InsertSystemChatEvents(ctx context.Context, events []db.SystemChatEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("chat_system_messages", ".jsonl")
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := writeJSONLine(buf, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package columnar
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/snappy"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/parser"
+	"cs-griefer-electron/internal/parser/extractors"
+)
+
+// readCompressedBlock is the inverse of writeCompressedBlock, used here
+// only to verify what Writer produced - this package has no reader of its
+// own yet (see the package doc comment: downstream tools are expected to
+// read this format directly, not through this package).
+func readCompressedBlock(t *testing.T, r *bytes.Reader) []byte {
+	t.Helper()
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		t.Fatalf("failed to read block size: %v", err)
+	}
+	compressed := make([]byte, size)
+	if _, err := r.Read(compressed); err != nil {
+		t.Fatalf("failed to read compressed block: %v", err)
+	}
+	raw, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		t.Fatalf("failed to decode snappy block: %v", err)
+	}
+	return raw
+}
+
+func readDictStringColumn(t *testing.T, r *bytes.Reader) []string {
+	t.Helper()
+	var dictLen uint32
+	if err := binary.Read(r, binary.LittleEndian, &dictLen); err != nil {
+		t.Fatalf("failed to read dict length: %v", err)
+	}
+	dictRaw := readCompressedBlock(t, r)
+	dict := make([]string, 0, dictLen)
+	for off := 0; len(dict) < int(dictLen); {
+		strLen := binary.LittleEndian.Uint32(dictRaw[off:])
+		off += 4
+		dict = append(dict, string(dictRaw[off:off+int(strLen)]))
+		off += int(strLen)
+	}
+
+	indexRaw := readCompressedBlock(t, r)
+	values := make([]string, len(indexRaw)/4)
+	for i := range values {
+		idx := int32(binary.LittleEndian.Uint32(indexRaw[i*4:]))
+		values[i] = dict[idx]
+	}
+	return values
+}
+
+func TestWriteDictStringColumnRoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	values := []string{"a", "b", "a", "a", "c", "b"}
+	if err := writeDictStringColumn(&buf, values); err != nil {
+		t.Fatalf("writeDictStringColumn failed: %v", err)
+	}
+
+	got := readDictStringColumn(t, bytes.NewReader(buf.Bytes()))
+	if len(got) != len(values) {
+		t.Fatalf("got %d values, want %d", len(got), len(values))
+	}
+	for i, v := range values {
+		if got[i] != v {
+			t.Errorf("index %d: got %q, want %q", i, got[i], v)
+		}
+	}
+}
+
+func TestWriteDeltaInt32ColumnRoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	values := []int32{100, 105, 105, 200, 150}
+	if err := writeDeltaInt32Column(&buf, values); err != nil {
+		t.Fatalf("writeDeltaInt32Column failed: %v", err)
+	}
+
+	raw := readCompressedBlock(t, bytes.NewReader(buf.Bytes()))
+	deltas := make([]int32, len(raw)/4)
+	for i := range deltas {
+		deltas[i] = int32(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+
+	var prev int32
+	for i, d := range deltas {
+		got := prev + d
+		if got != values[i] {
+			t.Errorf("index %d: reconstructed %d, want %d", i, got, values[i])
+		}
+		prev = got
+	}
+}
+
+func TestOptionalHelpers(t *testing.T) {
+	if optStr(nil) != "" {
+		t.Error("optStr(nil) should be empty")
+	}
+	s := "x"
+	if optStr(&s) != "x" {
+		t.Error("optStr should dereference a non-nil pointer")
+	}
+	if optInt32(nil) != -1 {
+		t.Error("optInt32(nil) should be -1")
+	}
+	n := 7
+	if optInt32(&n) != 7 {
+		t.Error("optInt32 should dereference a non-nil pointer")
+	}
+}
+
+func TestWriterInsertPlayerPositionsCreatesRowGroupFile(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	positions := []db.PlayerPosition{
+		{MatchID: "m1", RoundIndex: 0, Tick: 128, SteamID: "76561198000000001", X: 1, Y: 2, Z: 3},
+		{MatchID: "m1", RoundIndex: 0, Tick: 132, SteamID: "76561198000000001", X: 1.5, Y: 2.5, Z: 3.5},
+	}
+	if err := w.InsertPlayerPositions(context.Background(), positions); err != nil {
+		t.Fatalf("InsertPlayerPositions failed: %v", err)
+	}
+	// A second call should append a second row group to the same file,
+	// not overwrite it or rewrite the magic header.
+	if err := w.InsertPlayerPositions(context.Background(), positions); err != nil {
+		t.Fatalf("second InsertPlayerPositions failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "positions.cs2col"))
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		t.Fatalf("expected output to start with magic header %q", magic)
+	}
+	if bytes.Count(data, []byte(magic)) != 1 {
+		t.Error("expected exactly one magic header across both row groups")
+	}
+}
+
+func TestWriterInsertEmptySliceIsNoop(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.InsertShots(context.Background(), nil); err != nil {
+		t.Fatalf("InsertShots(nil) failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "shots.cs2col")); !os.IsNotExist(err) {
+		t.Error("expected no file to be created for an empty Insert call")
+	}
+}
+
+func TestKillRowFromEventDecodesMetaJSON(t *testing.T) {
+	killer, victim := "76561198000000001", "76561198000000002"
+	metaJSON := `{"weapon":"ak47","headshot":true,"distance":512.5}`
+	event := extractors.Event{
+		Type:          "KILL",
+		RoundIndex:    3,
+		StartTick:     1000,
+		ActorSteamID:  &killer,
+		VictimSteamID: &victim,
+		MetaJSON:      &metaJSON,
+	}
+
+	row := killRowFromEvent("m1", event)
+	if row.MatchID != "m1" || row.RoundIndex != 3 || row.Tick != 1000 {
+		t.Fatalf("unexpected identifying fields: %+v", row)
+	}
+	if optStr(row.KillerSteamID) != killer || optStr(row.VictimSteamID) != victim {
+		t.Errorf("unexpected killer/victim: %+v", row)
+	}
+	if row.Weapon != "ak47" || !row.Headshot {
+		t.Errorf("expected weapon=ak47 headshot=true, got %+v", row)
+	}
+}
+
+func TestKillRowFromEventMalformedMetaJSONLeavesZeroValues(t *testing.T) {
+	event := extractors.Event{Type: "KILL", MetaJSON: strPtr("not json")}
+	row := killRowFromEvent("m1", event)
+	if row.Weapon != "" || row.Headshot {
+		t.Errorf("expected zero-value weapon/headshot for malformed MetaJSON, got %+v", row)
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestWriteParquetWritesOneFilePerTable(t *testing.T) {
+	dir := t.TempDir()
+	killer, victim := "76561198000000001", "76561198000000002"
+	metaJSON := `{"weapon":"awp","headshot":false}`
+
+	data := &parser.MatchData{
+		Positions: []parser.PlayerPositionData{
+			{RoundIndex: 0, Tick: 128, SteamID: killer, X: 1, Y: 2, Z: 3},
+		},
+		Shots: []parser.ShotData{
+			{RoundIndex: 0, Tick: 130, SteamID: killer, WeaponName: "awp", X: 1, Y: 2, Z: 3},
+		},
+		GrenadePositions: []parser.GrenadePositionData{
+			{RoundIndex: 0, Tick: 131, ProjectileID: 1, GrenadeName: "smokegrenade", X: 4, Y: 5, Z: 6},
+		},
+		GrenadeEvents: []parser.GrenadeEventData{
+			{RoundIndex: 0, Tick: 132, EventType: "smoke_start", ProjectileID: 1, GrenadeName: "smokegrenade", X: 4, Y: 5, Z: 6},
+		},
+		ChatMessages: []parser.ChatMessageData{
+			{RoundIndex: 0, Tick: 133, SteamID: killer, Name: "p1", Team: "T", Message: "gg"},
+		},
+		Events: []extractors.Event{
+			{Type: "KILL", RoundIndex: 0, StartTick: 140, ActorSteamID: &killer, VictimSteamID: &victim, MetaJSON: &metaJSON},
+			{Type: "TEAM_KILL", RoundIndex: 0, StartTick: 141, ActorSteamID: &killer, VictimSteamID: &victim},
+		},
+	}
+
+	if err := WriteParquet(dir, "m1", data); err != nil {
+		t.Fatalf("WriteParquet failed: %v", err)
+	}
+
+	for _, name := range []string{"positions.cs2col", "shots.cs2col", "grenade_positions.cs2col", "grenade_events.cs2col", "chat_messages.jsonl", "kills.cs2col"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to exist: %v", name, err)
+		}
+	}
+}
+
+func TestParquetSinkFlushesOnClose(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewParquetSink(dir, "m1")
+	if err != nil {
+		t.Fatalf("NewParquetSink failed: %v", err)
+	}
+
+	killer, victim := "76561198000000001", "76561198000000002"
+	if err := sink.Kill(parser.KillEvent{RoundIndex: 0, Tick: 100, Killer: &killer, Victim: &victim, Weapon: "ak47", Headshot: true}); err != nil {
+		t.Fatalf("Kill failed: %v", err)
+	}
+	if err := sink.GrenadeThrown(parser.GrenadeThrownEvent{RoundIndex: 0, Tick: 50, Thrower: &killer, GrenadeName: "flashbang"}); err != nil {
+		t.Fatalf("GrenadeThrown failed: %v", err)
+	}
+	if err := sink.PlayerPosition(parser.PlayerPositionEvent{RoundIndex: 0, Tick: 50, SteamID: killer, X: 1, Y: 2, Z: 3}); err != nil {
+		t.Fatalf("PlayerPosition failed: %v", err)
+	}
+	if err := sink.ChatMessage(parser.ChatMessageEvent{RoundIndex: 0, Tick: 50, SteamID: killer, Message: "hi"}); err != nil {
+		t.Fatalf("ChatMessage failed: %v", err)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for _, name := range []string{"kills.cs2col", "grenade_throws.cs2col", "positions.cs2col", "chat_messages.jsonl"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to exist after Close: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,718 @@
+package columnar
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/golang/snappy"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// writeJSONLine marshals v and appends it to w as one NDJSON line.
+func writeJSONLine(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	_, err = w.Write([]byte{'\n'})
+	return err
+}
+
+// writeRowGroupHeader writes a row group's row count, same as
+// cmd/parser/streaming_parquet.go's writeRowGroup.
+func writeRowGroupHeader(w io.Writer, rowCount int) error {
+	return binary.Write(w, binary.LittleEndian, uint32(rowCount))
+}
+
+func writeCompressedBlock(w io.Writer, raw []byte) error {
+	compressed := snappy.Encode(nil, raw)
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(compressed))); err != nil {
+		return err
+	}
+	_, err := w.Write(compressed)
+	return err
+}
+
+func writeInt32Column(w io.Writer, values []int32) error {
+	raw := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(raw[i*4:], uint32(v))
+	}
+	return writeCompressedBlock(w, raw)
+}
+
+// writeDeltaInt32Column delta-encodes values (each entry minus the
+// previous, first entry relative to 0) before compressing - Tick is sorted
+// ascending within a round group, so deltas stay small and repetitive,
+// friendlier to Snappy than raw absolute tick numbers.
+func writeDeltaInt32Column(w io.Writer, values []int32) error {
+	deltas := make([]int32, len(values))
+	var prev int32
+	for i, v := range values {
+		deltas[i] = v - prev
+		prev = v
+	}
+	return writeInt32Column(w, deltas)
+}
+
+func writeUint64Column(w io.Writer, values []uint64) error {
+	raw := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(raw[i*8:], v)
+	}
+	return writeCompressedBlock(w, raw)
+}
+
+func writeFloat64Column(w io.Writer, values []float64) error {
+	raw := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(raw[i*8:], math.Float64bits(v))
+	}
+	return writeCompressedBlock(w, raw)
+}
+
+func writeStringColumn(w io.Writer, values []string) error {
+	raw := make([]byte, 0, len(values)*8)
+	for _, v := range values {
+		var lenBuf [4]byte
+		binary.LittleEndian.PutUint32(lenBuf[:], uint32(len(v)))
+		raw = append(raw, lenBuf[:]...)
+		raw = append(raw, v...)
+	}
+	return writeCompressedBlock(w, raw)
+}
+
+// writeDictStringColumn dictionary-encodes values: the distinct strings
+// (in first-seen order) followed by one int32 index per row. SteamID,
+// WeaponName, GrenadeName and Team all repeat heavily within a round, so
+// this is both smaller and more Snappy-friendly than writing the raw
+// strings every row.
+func writeDictStringColumn(w io.Writer, values []string) error {
+	dictIndex := make(map[string]int32)
+	dict := make([]string, 0, len(values))
+	indices := make([]int32, len(values))
+	for i, v := range values {
+		idx, ok := dictIndex[v]
+		if !ok {
+			idx = int32(len(dict))
+			dictIndex[v] = idx
+			dict = append(dict, v)
+		}
+		indices[i] = idx
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(dict))); err != nil {
+		return err
+	}
+	if err := writeStringColumn(w, dict); err != nil {
+		return err
+	}
+	return writeInt32Column(w, indices)
+}
+
+// optStr returns "" for a nil *string, the pointed-to value otherwise -
+// the sentinel writeDictStringColumn's readers treat as "absent".
+func optStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// optInt32 returns -1 for a nil *int, the pointed-to value otherwise.
+func optInt32(i *int) int32 {
+	if i == nil {
+		return -1
+	}
+	return int32(*i)
+}
+
+// optFloat64 returns math.NaN() for a nil *float64, the pointed-to value
+// otherwise - a column that's legitimately ever NaN doesn't occur in this
+// telemetry (positions/angles), so NaN is an unambiguous "absent" sentinel.
+func optFloat64(f *float64) float64 {
+	if f == nil {
+		return math.NaN()
+	}
+	return *f
+}
+
+func writePositionRowGroup(w io.Writer, positions []db.PlayerPosition) error {
+	if err := writeRowGroupHeader(w, len(positions)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(positions))
+	rounds := make([]int32, len(positions))
+	ticks := make([]int32, len(positions))
+	steamIDs := make([]string, len(positions))
+	xs := make([]float64, len(positions))
+	ys := make([]float64, len(positions))
+	zs := make([]float64, len(positions))
+	yaws := make([]float64, len(positions))
+	teams := make([]string, len(positions))
+	healths := make([]int32, len(positions))
+	armors := make([]int32, len(positions))
+	weapons := make([]string, len(positions))
+
+	for i, p := range positions {
+		matchIDs[i] = p.MatchID
+		rounds[i] = int32(p.RoundIndex)
+		ticks[i] = int32(p.Tick)
+		steamIDs[i] = p.SteamID
+		xs[i] = p.X
+		ys[i] = p.Y
+		zs[i] = p.Z
+		yaws[i] = optFloat64(p.Yaw)
+		teams[i] = optStr(p.Team)
+		healths[i] = optInt32(p.Health)
+		armors[i] = optInt32(p.Armor)
+		weapons[i] = optStr(p.Weapon)
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, steamIDs),
+		writeFloat64Column(w, xs),
+		writeFloat64Column(w, ys),
+		writeFloat64Column(w, zs),
+		writeFloat64Column(w, yaws),
+		writeDictStringColumn(w, teams),
+		writeInt32Column(w, healths),
+		writeInt32Column(w, armors),
+		writeDictStringColumn(w, weapons),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeShotRowGroup(w io.Writer, shots []db.Shot) error {
+	if err := writeRowGroupHeader(w, len(shots)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(shots))
+	rounds := make([]int32, len(shots))
+	ticks := make([]int32, len(shots))
+	steamIDs := make([]string, len(shots))
+	weapons := make([]string, len(shots))
+	xs := make([]float64, len(shots))
+	ys := make([]float64, len(shots))
+	zs := make([]float64, len(shots))
+	yaws := make([]float64, len(shots))
+	pitches := make([]float64, len(shots))
+	teams := make([]string, len(shots))
+
+	for i, s := range shots {
+		matchIDs[i] = s.MatchID
+		rounds[i] = int32(s.RoundIndex)
+		ticks[i] = int32(s.Tick)
+		steamIDs[i] = s.SteamID
+		weapons[i] = s.WeaponName
+		xs[i] = s.X
+		ys[i] = s.Y
+		zs[i] = s.Z
+		yaws[i] = s.Yaw
+		pitches[i] = optFloat64(s.Pitch)
+		teams[i] = optStr(s.Team)
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, steamIDs),
+		writeDictStringColumn(w, weapons),
+		writeFloat64Column(w, xs),
+		writeFloat64Column(w, ys),
+		writeFloat64Column(w, zs),
+		writeFloat64Column(w, yaws),
+		writeFloat64Column(w, pitches),
+		writeDictStringColumn(w, teams),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGrenadePositionRowGroup(w io.Writer, positions []db.GrenadePosition) error {
+	if err := writeRowGroupHeader(w, len(positions)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(positions))
+	rounds := make([]int32, len(positions))
+	ticks := make([]int32, len(positions))
+	projectileIDs := make([]uint64, len(positions))
+	grenadeNames := make([]string, len(positions))
+	xs := make([]float64, len(positions))
+	ys := make([]float64, len(positions))
+	zs := make([]float64, len(positions))
+	throwerSteamIDs := make([]string, len(positions))
+	throwerNames := make([]string, len(positions))
+	throwerTeams := make([]string, len(positions))
+
+	for i, p := range positions {
+		matchIDs[i] = p.MatchID
+		rounds[i] = int32(p.RoundIndex)
+		ticks[i] = int32(p.Tick)
+		projectileIDs[i] = p.ProjectileID
+		grenadeNames[i] = p.GrenadeName
+		xs[i] = p.X
+		ys[i] = p.Y
+		zs[i] = p.Z
+		throwerSteamIDs[i] = optStr(p.ThrowerSteamID)
+		throwerNames[i] = optStr(p.ThrowerName)
+		throwerTeams[i] = optStr(p.ThrowerTeam)
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeUint64Column(w, projectileIDs),
+		writeDictStringColumn(w, grenadeNames),
+		writeFloat64Column(w, xs),
+		writeFloat64Column(w, ys),
+		writeFloat64Column(w, zs),
+		writeDictStringColumn(w, throwerSteamIDs),
+		writeDictStringColumn(w, throwerNames),
+		writeDictStringColumn(w, throwerTeams),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGrenadePredictionRowGroup(w io.Writer, predictions []db.GrenadePrediction) error {
+	if err := writeRowGroupHeader(w, len(predictions)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(predictions))
+	rounds := make([]int32, len(predictions))
+	ticks := make([]int32, len(predictions))
+	projectileIDs := make([]uint64, len(predictions))
+	grenadeNames := make([]string, len(predictions))
+	predXs := make([]float64, len(predictions))
+	predYs := make([]float64, len(predictions))
+	predZs := make([]float64, len(predictions))
+	predictedTicks := make([]int32, len(predictions))
+	effectRadii := make([]float64, len(predictions))
+
+	for i, p := range predictions {
+		matchIDs[i] = p.MatchID
+		rounds[i] = int32(p.RoundIndex)
+		ticks[i] = int32(p.Tick)
+		projectileIDs[i] = p.ProjectileID
+		grenadeNames[i] = p.GrenadeName
+		predXs[i] = p.PredictedX
+		predYs[i] = p.PredictedY
+		predZs[i] = p.PredictedZ
+		predictedTicks[i] = int32(p.PredictedTick)
+		effectRadii[i] = p.EffectRadius
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeUint64Column(w, projectileIDs),
+		writeDictStringColumn(w, grenadeNames),
+		writeFloat64Column(w, predXs),
+		writeFloat64Column(w, predYs),
+		writeFloat64Column(w, predZs),
+		writeInt32Column(w, predictedTicks),
+		writeFloat64Column(w, effectRadii),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePlayerInputRowGroup(w io.Writer, inputs []db.PlayerInput) error {
+	if err := writeRowGroupHeader(w, len(inputs)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(inputs))
+	rounds := make([]int32, len(inputs))
+	ticks := make([]int32, len(inputs))
+	steamIDs := make([]string, len(inputs))
+	buttons := make([]int32, len(inputs))
+	forwardMoves := make([]float64, len(inputs))
+	sideMoves := make([]float64, len(inputs))
+
+	for i, in := range inputs {
+		matchIDs[i] = in.MatchID
+		rounds[i] = int32(in.RoundIndex)
+		ticks[i] = int32(in.Tick)
+		steamIDs[i] = in.SteamID
+		buttons[i] = int32(in.Buttons)
+		forwardMoves[i] = in.ForwardMove
+		sideMoves[i] = in.SideMove
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, steamIDs),
+		writeInt32Column(w, buttons),
+		writeFloat64Column(w, forwardMoves),
+		writeFloat64Column(w, sideMoves),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGrenadeEventRowGroup(w io.Writer, events []db.GrenadeEvent) error {
+	if err := writeRowGroupHeader(w, len(events)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(events))
+	rounds := make([]int32, len(events))
+	ticks := make([]int32, len(events))
+	eventTypes := make([]string, len(events))
+	projectileIDs := make([]uint64, len(events))
+	grenadeNames := make([]string, len(events))
+	xs := make([]float64, len(events))
+	ys := make([]float64, len(events))
+	zs := make([]float64, len(events))
+	throwerSteamIDs := make([]string, len(events))
+	throwerNames := make([]string, len(events))
+	throwerTeams := make([]string, len(events))
+	firesJSONs := make([]string, len(events))
+
+	for i, e := range events {
+		matchIDs[i] = e.MatchID
+		rounds[i] = int32(e.RoundIndex)
+		ticks[i] = int32(e.Tick)
+		eventTypes[i] = e.EventType
+		projectileIDs[i] = e.ProjectileID
+		grenadeNames[i] = e.GrenadeName
+		xs[i] = e.X
+		ys[i] = e.Y
+		zs[i] = e.Z
+		throwerSteamIDs[i] = optStr(e.ThrowerSteamID)
+		throwerNames[i] = optStr(e.ThrowerName)
+		throwerTeams[i] = optStr(e.ThrowerTeam)
+		firesJSONs[i] = optStr(e.FiresJSON)
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, eventTypes),
+		writeUint64Column(w, projectileIDs),
+		writeDictStringColumn(w, grenadeNames),
+		writeFloat64Column(w, xs),
+		writeFloat64Column(w, ys),
+		writeFloat64Column(w, zs),
+		writeDictStringColumn(w, throwerSteamIDs),
+		writeDictStringColumn(w, throwerNames),
+		writeDictStringColumn(w, throwerTeams),
+		// Not dictionary-encoded like the columns above: each inferno's fire
+		// list is effectively unique per row, so a dictionary would just add
+		// overhead instead of saving space.
+		writeStringColumn(w, firesJSONs),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeWeaponSwitchRowGroup(w io.Writer, switches []db.WeaponSwitch) error {
+	if err := writeRowGroupHeader(w, len(switches)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(switches))
+	rounds := make([]int32, len(switches))
+	ticks := make([]int32, len(switches))
+	steamIDs := make([]string, len(switches))
+	prevWeapons := make([]string, len(switches))
+	newWeapons := make([]string, len(switches))
+	prevAmmo := make([]int32, len(switches))
+	newAmmo := make([]int32, len(switches))
+	wasEmpty := make([]int32, len(switches))
+	deployTicks := make([]int32, len(switches))
+
+	for i, s := range switches {
+		matchIDs[i] = s.MatchID
+		rounds[i] = int32(s.RoundIndex)
+		ticks[i] = int32(s.Tick)
+		steamIDs[i] = s.SteamID
+		prevWeapons[i] = optStr(s.PrevWeapon)
+		newWeapons[i] = s.NewWeapon
+		prevAmmo[i] = optInt32(s.PrevAmmo)
+		newAmmo[i] = optInt32(s.NewAmmo)
+		if s.WasEmpty {
+			wasEmpty[i] = 1
+		}
+		deployTicks[i] = optInt32(s.DeployTicksUntilFirstFire)
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, steamIDs),
+		writeDictStringColumn(w, prevWeapons),
+		writeDictStringColumn(w, newWeapons),
+		writeInt32Column(w, prevAmmo),
+		writeInt32Column(w, newAmmo),
+		writeInt32Column(w, wasEmpty),
+		writeInt32Column(w, deployTicks),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePlayerRoundWeaponStatsRowGroup(w io.Writer, stats []db.PlayerRoundWeaponStats) error {
+	if err := writeRowGroupHeader(w, len(stats)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(stats))
+	rounds := make([]int32, len(stats))
+	steamIDs := make([]string, len(stats))
+	switchesPerRound := make([]int32, len(stats))
+	avgDeploySeconds := make([]float64, len(stats))
+	switchesToEmptyGun := make([]int32, len(stats))
+
+	for i, s := range stats {
+		matchIDs[i] = s.MatchID
+		rounds[i] = int32(s.RoundIndex)
+		steamIDs[i] = s.SteamID
+		switchesPerRound[i] = int32(s.SwitchesPerRound)
+		avgDeploySeconds[i] = optFloat64(s.AvgDeploySeconds)
+		switchesToEmptyGun[i] = int32(s.SwitchesToEmptyGun)
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDictStringColumn(w, steamIDs),
+		writeInt32Column(w, switchesPerRound),
+		writeFloat64Column(w, avgDeploySeconds),
+		writeInt32Column(w, switchesToEmptyGun),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeBombEventRowGroup(w io.Writer, events []db.BombEvent) error {
+	if err := writeRowGroupHeader(w, len(events)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(events))
+	rounds := make([]int32, len(events))
+	ticks := make([]int32, len(events))
+	eventTypes := make([]string, len(events))
+	carrierSteamIDs := make([]string, len(events))
+	xs := make([]float64, len(events))
+	ys := make([]float64, len(events))
+	zs := make([]float64, len(events))
+	sites := make([]string, len(events))
+	defuserSteamIDs := make([]string, len(events))
+	hasKits := make([]int32, len(events))
+
+	for i, e := range events {
+		matchIDs[i] = e.MatchID
+		rounds[i] = int32(e.RoundIndex)
+		ticks[i] = int32(e.Tick)
+		eventTypes[i] = e.EventType
+		carrierSteamIDs[i] = optStr(e.CarrierSteamID)
+		xs[i] = e.X
+		ys[i] = e.Y
+		zs[i] = e.Z
+		sites[i] = optStr(e.Site)
+		defuserSteamIDs[i] = optStr(e.DefuserSteamID)
+		if e.HasKit {
+			hasKits[i] = 1
+		}
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, eventTypes),
+		writeDictStringColumn(w, carrierSteamIDs),
+		writeFloat64Column(w, xs),
+		writeFloat64Column(w, ys),
+		writeFloat64Column(w, zs),
+		writeDictStringColumn(w, sites),
+		writeDictStringColumn(w, defuserSteamIDs),
+		writeInt32Column(w, hasKits),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeKillRowGroup(w io.Writer, kills []KillRow) error {
+	if err := writeRowGroupHeader(w, len(kills)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(kills))
+	rounds := make([]int32, len(kills))
+	ticks := make([]int32, len(kills))
+	killerSteamIDs := make([]string, len(kills))
+	victimSteamIDs := make([]string, len(kills))
+	weapons := make([]string, len(kills))
+	headshots := make([]int32, len(kills))
+
+	for i, k := range kills {
+		matchIDs[i] = k.MatchID
+		rounds[i] = int32(k.RoundIndex)
+		ticks[i] = int32(k.Tick)
+		killerSteamIDs[i] = optStr(k.KillerSteamID)
+		victimSteamIDs[i] = optStr(k.VictimSteamID)
+		weapons[i] = k.Weapon
+		if k.Headshot {
+			headshots[i] = 1
+		}
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, killerSteamIDs),
+		writeDictStringColumn(w, victimSteamIDs),
+		writeDictStringColumn(w, weapons),
+		writeInt32Column(w, headshots),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGrenadeThrowRowGroup(w io.Writer, throws []GrenadeThrowRow) error {
+	if err := writeRowGroupHeader(w, len(throws)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(throws))
+	rounds := make([]int32, len(throws))
+	ticks := make([]int32, len(throws))
+	throwerSteamIDs := make([]string, len(throws))
+	grenadeNames := make([]string, len(throws))
+
+	for i, t := range throws {
+		matchIDs[i] = t.MatchID
+		rounds[i] = int32(t.RoundIndex)
+		ticks[i] = int32(t.Tick)
+		throwerSteamIDs[i] = optStr(t.ThrowerSteamID)
+		grenadeNames[i] = t.GrenadeName
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, throwerSteamIDs),
+		writeDictStringColumn(w, grenadeNames),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePositionDeltaRowGroup writes PlayerPositionDelta rows (see
+// --position-encoding=delta). DX/DY/DZ/YawDelta are already small signed
+// integers, so they go through the int32 column writer rather than dict or
+// delta encoding - there's no further structure in them worth exploiting.
+func writePositionDeltaRowGroup(w io.Writer, deltas []db.PlayerPositionDelta) error {
+	if err := writeRowGroupHeader(w, len(deltas)); err != nil {
+		return err
+	}
+
+	matchIDs := make([]string, len(deltas))
+	rounds := make([]int32, len(deltas))
+	ticks := make([]int32, len(deltas))
+	steamIDs := make([]string, len(deltas))
+	dxs := make([]int32, len(deltas))
+	dys := make([]int32, len(deltas))
+	dzs := make([]int32, len(deltas))
+	yawDeltas := make([]int32, len(deltas))
+	teams := make([]string, len(deltas))
+	healths := make([]int32, len(deltas))
+	armors := make([]int32, len(deltas))
+	weapons := make([]string, len(deltas))
+
+	for i, d := range deltas {
+		matchIDs[i] = d.MatchID
+		rounds[i] = int32(d.RoundIndex)
+		ticks[i] = int32(d.Tick)
+		steamIDs[i] = d.SteamID
+		dxs[i] = int32(d.DX)
+		dys[i] = int32(d.DY)
+		dzs[i] = int32(d.DZ)
+		yawDeltas[i] = int32(d.YawDelta)
+		teams[i] = optStr(d.Team)
+		healths[i] = optInt32(d.Health)
+		armors[i] = optInt32(d.Armor)
+		weapons[i] = optStr(d.Weapon)
+	}
+
+	for _, err := range []error{
+		writeDictStringColumn(w, matchIDs),
+		writeInt32Column(w, rounds),
+		writeDeltaInt32Column(w, ticks),
+		writeDictStringColumn(w, steamIDs),
+		writeInt32Column(w, dxs),
+		writeInt32Column(w, dys),
+		writeInt32Column(w, dzs),
+		writeInt32Column(w, yawDeltas),
+		writeDictStringColumn(w, teams),
+		writeInt32Column(w, healths),
+		writeInt32Column(w, armors),
+		writeDictStringColumn(w, weapons),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
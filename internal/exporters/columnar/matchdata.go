@@ -0,0 +1,370 @@
+package columnar
+
+import (
+	"context"
+	"encoding/json"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/parser"
+	"cs-griefer-electron/internal/parser/extractors"
+)
+
+// KillRow is a flattened, columnar-friendly kill row. Kills aren't a
+// dedicated field on parser.MatchData the way positions/shots/grenades
+// are - extractors.KillExtractor instead records them as generic
+// extractors.Event entries (Type == "KILL") with weapon/headshot tucked
+// into MetaJSON. WriteParquet pulls those back out here so kills get the
+// same one-file-per-table treatment as everything else. Team kills
+// (Type == "TEAM_KILL") are a separate detector's event type and aren't
+// included.
+type KillRow struct {
+	MatchID       string
+	RoundIndex    int
+	Tick          int
+	KillerSteamID *string
+	VictimSteamID *string
+	Weapon        string
+	Headshot      bool
+}
+
+// killMeta is the subset of KillExtractor's MetaJSON this package reads.
+type killMeta struct {
+	Weapon   string `json:"weapon"`
+	Headshot bool   `json:"headshot"`
+}
+
+// InsertKills writes kills as one row group to kills.cs2col.
+func (w *Writer) InsertKills(ctx context.Context, kills []KillRow) error {
+	if len(kills) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := w.bufFor("kills", rowGroupExt)
+	if err != nil {
+		return err
+	}
+	return writeKillRowGroup(buf, kills)
+}
+
+// killRowFromEvent converts a KILL extractors.Event into a KillRow,
+// decoding weapon/headshot out of MetaJSON. A malformed or absent
+// MetaJSON just leaves those two fields at their zero values rather than
+// failing the whole export.
+func killRowFromEvent(matchID string, e extractors.Event) KillRow {
+	row := KillRow{
+		MatchID:       matchID,
+		RoundIndex:    e.RoundIndex,
+		Tick:          e.StartTick,
+		KillerSteamID: e.ActorSteamID,
+		VictimSteamID: e.VictimSteamID,
+	}
+	if e.MetaJSON != nil {
+		var meta killMeta
+		if err := json.Unmarshal([]byte(*e.MetaJSON), &meta); err == nil {
+			row.Weapon = meta.Weapon
+			row.Headshot = meta.Headshot
+		}
+	}
+	return row
+}
+
+// WriteParquet serializes data into dir as one table file per event type,
+// the same format Writer streams live ParseWithDB telemetry into (see the
+// package doc comment) - demoID is stamped onto every row the way
+// db.Writer's MatchID column would be. This is the batch-mode entry point
+// for a Parse() result already fully in memory; NewParquetSink below is
+// the streaming equivalent for a Stream(ctx) caller that never builds one.
+func WriteParquet(dir, demoID string, data *parser.MatchData) error {
+	w, err := NewWriter(Config{Dir: dir})
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	ctx := context.Background()
+
+	positions := make([]db.PlayerPosition, len(data.Positions))
+	for i, p := range data.Positions {
+		team := p.Team
+		positions[i] = db.PlayerPosition{
+			MatchID:    demoID,
+			RoundIndex: p.RoundIndex,
+			Tick:       p.Tick,
+			SteamID:    p.SteamID,
+			X:          p.X,
+			Y:          p.Y,
+			Z:          p.Z,
+			Yaw:        &p.Yaw,
+			Team:       &team,
+			Health:     p.Health,
+			Armor:      p.Armor,
+			Weapon:     p.Weapon,
+		}
+	}
+	if err := w.InsertPlayerPositions(ctx, positions); err != nil {
+		return err
+	}
+
+	shots := make([]db.Shot, len(data.Shots))
+	for i, s := range data.Shots {
+		shots[i] = db.Shot{
+			MatchID:    demoID,
+			RoundIndex: s.RoundIndex,
+			Tick:       s.Tick,
+			SteamID:    s.SteamID,
+			WeaponName: s.WeaponName,
+			X:          s.X,
+			Y:          s.Y,
+			Z:          s.Z,
+			Yaw:        s.Yaw,
+			Pitch:      s.Pitch,
+			Team:       s.Team,
+		}
+	}
+	if err := w.InsertShots(ctx, shots); err != nil {
+		return err
+	}
+
+	grenadePositions := make([]db.GrenadePosition, len(data.GrenadePositions))
+	for i, p := range data.GrenadePositions {
+		grenadePositions[i] = db.GrenadePosition{
+			MatchID:        demoID,
+			RoundIndex:     p.RoundIndex,
+			Tick:           p.Tick,
+			ProjectileID:   p.ProjectileID,
+			GrenadeName:    p.GrenadeName,
+			X:              p.X,
+			Y:              p.Y,
+			Z:              p.Z,
+			ThrowerSteamID: p.ThrowerSteamID,
+			ThrowerName:    p.ThrowerName,
+			ThrowerTeam:    p.ThrowerTeam,
+		}
+	}
+	if err := w.InsertGrenadePositions(ctx, grenadePositions); err != nil {
+		return err
+	}
+
+	grenadeEvents := make([]db.GrenadeEvent, len(data.GrenadeEvents))
+	for i, e := range data.GrenadeEvents {
+		grenadeEvents[i] = db.GrenadeEvent{
+			MatchID:        demoID,
+			RoundIndex:     e.RoundIndex,
+			Tick:           e.Tick,
+			EventType:      e.EventType,
+			ProjectileID:   e.ProjectileID,
+			GrenadeName:    e.GrenadeName,
+			X:              e.X,
+			Y:              e.Y,
+			Z:              e.Z,
+			ThrowerSteamID: e.ThrowerSteamID,
+			ThrowerName:    e.ThrowerName,
+			ThrowerTeam:    e.ThrowerTeam,
+			FiresJSON:      e.FiresJSON,
+		}
+	}
+	if err := w.InsertGrenadeEvents(ctx, grenadeEvents); err != nil {
+		return err
+	}
+
+	chatMessages := make([]db.ChatMessage, len(data.ChatMessages))
+	for i, c := range data.ChatMessages {
+		name, team := c.Name, c.Team
+		chatMessages[i] = db.ChatMessage{
+			MatchID:    demoID,
+			RoundIndex: c.RoundIndex,
+			Tick:       c.Tick,
+			SteamID:    c.SteamID,
+			Name:       &name,
+			Team:       &team,
+			Message:    c.Message,
+			IsTeamChat: c.IsTeamChat,
+		}
+	}
+	if err := w.InsertChatMessages(ctx, chatMessages); err != nil {
+		return err
+	}
+
+	kills := make([]KillRow, 0, len(data.Events))
+	for _, e := range data.Events {
+		if e.Type != "KILL" {
+			continue
+		}
+		kills = append(kills, killRowFromEvent(demoID, e))
+	}
+	return w.InsertKills(ctx, kills)
+}
+
+// flushThreshold is the row count NewParquetSink buffers per table before
+// writing a row group, mirroring ParseWithDB's own 5000/2000-row flush
+// buffers (see columnar.go's package doc) - writing a row group per
+// streamed event would be far too fine-grained for position data.
+const flushThreshold = 5000
+
+// Sink is the streaming counterpart to WriteParquet: a Stream(ctx) caller
+// wires Kill/GrenadeThrown/PlayerPosition/ChatMessage up to
+// parser.Parser's OnKill/OnGrenadeThrown/OnPlayerPosition/OnChatMessage so
+// telemetry streams straight to dir instead of building a MatchData
+// first. Buffers up to flushThreshold rows per table and flushes
+// everything still buffered on Close.
+//
+// Stream doesn't expose grenade trajectories or explosion events (only the
+// throw itself), so unlike WriteParquet's richer grenade_positions.cs2col
+// and grenade_events.cs2col, Sink only ever produces a
+// grenade_throws.cs2col with one row per GrenadeProjectileThrow - callers
+// that need full grenade telemetry still want ParseWithDB's columnar.Writer.
+type Sink struct {
+	w      *Writer
+	demoID string
+
+	kills     []KillRow
+	throws    []GrenadeThrowRow
+	positions []db.PlayerPosition
+	chats     []db.ChatMessage
+}
+
+// GrenadeThrowRow is the one grenade-related row Sink can produce from
+// Stream's events - see Sink's doc comment for why this isn't the full
+// grenade_positions/grenade_events pair WriteParquet writes.
+type GrenadeThrowRow struct {
+	MatchID        string
+	RoundIndex     int
+	Tick           int
+	ThrowerSteamID *string
+	GrenadeName    string
+}
+
+// NewParquetSink creates dir if needed and returns a Sink over it.
+func NewParquetSink(dir, demoID string) (*Sink, error) {
+	w, err := NewWriter(Config{Dir: dir})
+	if err != nil {
+		return nil, err
+	}
+	return &Sink{w: w, demoID: demoID}, nil
+}
+
+// Kill buffers e as a kill row, flushing if flushThreshold is reached.
+func (s *Sink) Kill(e parser.KillEvent) error {
+	s.kills = append(s.kills, KillRow{
+		MatchID:       s.demoID,
+		RoundIndex:    e.RoundIndex,
+		Tick:          e.Tick,
+		KillerSteamID: e.Killer,
+		VictimSteamID: e.Victim,
+		Weapon:        e.Weapon,
+		Headshot:      e.Headshot,
+	})
+	if len(s.kills) >= flushThreshold {
+		return s.flushKills()
+	}
+	return nil
+}
+
+// GrenadeThrown buffers e as a grenade-throw row, flushing if
+// flushThreshold is reached.
+func (s *Sink) GrenadeThrown(e parser.GrenadeThrownEvent) error {
+	s.throws = append(s.throws, GrenadeThrowRow{
+		MatchID:        s.demoID,
+		RoundIndex:     e.RoundIndex,
+		Tick:           e.Tick,
+		ThrowerSteamID: e.Thrower,
+		GrenadeName:    e.GrenadeName,
+	})
+	if len(s.throws) >= flushThreshold {
+		return s.flushThrows()
+	}
+	return nil
+}
+
+// PlayerPosition buffers e as a position row, flushing if flushThreshold
+// is reached.
+func (s *Sink) PlayerPosition(e parser.PlayerPositionEvent) error {
+	s.positions = append(s.positions, db.PlayerPosition{
+		MatchID:    s.demoID,
+		RoundIndex: e.RoundIndex,
+		Tick:       e.Tick,
+		SteamID:    e.SteamID,
+		X:          e.X,
+		Y:          e.Y,
+		Z:          e.Z,
+	})
+	if len(s.positions) >= flushThreshold {
+		return s.flushPositions()
+	}
+	return nil
+}
+
+// ChatMessage buffers e as a chat row, flushing if flushThreshold is
+// reached.
+func (s *Sink) ChatMessage(e parser.ChatMessageEvent) error {
+	s.chats = append(s.chats, db.ChatMessage{
+		MatchID:    s.demoID,
+		RoundIndex: e.RoundIndex,
+		Tick:       e.Tick,
+		SteamID:    e.SteamID,
+		Message:    e.Message,
+		IsTeamChat: e.IsTeamChat,
+	})
+	if len(s.chats) >= flushThreshold {
+		return s.flushChats()
+	}
+	return nil
+}
+
+func (s *Sink) flushKills() error {
+	if err := s.w.InsertKills(context.Background(), s.kills); err != nil {
+		return err
+	}
+	s.kills = s.kills[:0]
+	return nil
+}
+
+func (s *Sink) flushThrows() error {
+	if len(s.throws) == 0 {
+		return nil
+	}
+	w := s.w
+	w.mu.Lock()
+	buf, err := w.bufFor("grenade_throws", rowGroupExt)
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := writeGrenadeThrowRowGroup(buf, s.throws); err != nil {
+		return err
+	}
+	s.throws = s.throws[:0]
+	return nil
+}
+
+func (s *Sink) flushPositions() error {
+	if err := s.w.InsertPlayerPositions(context.Background(), s.positions); err != nil {
+		return err
+	}
+	s.positions = s.positions[:0]
+	return nil
+}
+
+func (s *Sink) flushChats() error {
+	if err := s.w.InsertChatMessages(context.Background(), s.chats); err != nil {
+		return err
+	}
+	s.chats = s.chats[:0]
+	return nil
+}
+
+// Close flushes every still-buffered table and closes the underlying
+// Writer.
+func (s *Sink) Close() error {
+	for _, flush := range []func() error{s.flushKills, s.flushThrows, s.flushPositions, s.flushChats} {
+		if err := flush(); err != nil {
+			s.w.Close()
+			return err
+		}
+	}
+	return s.w.Close()
+}
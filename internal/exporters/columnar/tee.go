@@ -0,0 +1,139 @@
+package columnar
+
+import (
+	"context"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// TelemetryWriter is the subset of internal/parser.ParseWithDB's writer
+// parameter a Writer (or db.Writer) satisfies, named here so Tee doesn't
+// need to import internal/parser just to describe its shape.
+type TelemetryWriter interface {
+	InsertPlayerPositions(ctx context.Context, positions []db.PlayerPosition) error
+	InsertPlayerPositionKeyframes(ctx context.Context, keyframes []db.PlayerPosition) error
+	InsertPlayerPositionDeltas(ctx context.Context, deltas []db.PlayerPositionDelta) error
+	InsertPlayer(ctx context.Context, player db.Player) error
+	InsertChatMessages(ctx context.Context, messages []db.ChatMessage) error
+	InsertSystemChatEvents(ctx context.Context, events []db.SystemChatEvent) error
+	InsertGrenadePositions(ctx context.Context, positions []db.GrenadePosition) error
+	InsertGrenadeEvents(ctx context.Context, events []db.GrenadeEvent) error
+	InsertBombEvents(ctx context.Context, events []db.BombEvent) error
+	InsertGrenadePredictions(ctx context.Context, predictions []db.GrenadePrediction) error
+	InsertPlayerInputs(ctx context.Context, inputs []db.PlayerInput) error
+	InsertShots(ctx context.Context, shots []db.Shot) error
+	InsertWeaponSwitches(ctx context.Context, switches []db.WeaponSwitch) error
+	InsertPlayerRoundWeaponStats(ctx context.Context, stats []db.PlayerRoundWeaponStats) error
+}
+
+// Tee returns a TelemetryWriter that forwards every call to both a and b -
+// e.g. cmd/parser's SQLite db.Writer plus a columnar Writer, so --out and
+// --columnar-dir can both be populated from the same parse instead of
+// --columnar-dir replacing SQLite output outright (AFK/BodyBlock detection
+// still reads positions back from SQLite mid-parse; see NewAFKExtractor).
+// A call stops at the first error, so b is skipped once a has failed.
+func Tee(a, b TelemetryWriter) TelemetryWriter {
+	return teeWriter{a: a, b: b}
+}
+
+type teeWriter struct {
+	a, b TelemetryWriter
+}
+
+func (t teeWriter) InsertPlayerPositions(ctx context.Context, positions []db.PlayerPosition) error {
+	if err := t.a.InsertPlayerPositions(ctx, positions); err != nil {
+		return err
+	}
+	return t.b.InsertPlayerPositions(ctx, positions)
+}
+
+func (t teeWriter) InsertPlayerPositionKeyframes(ctx context.Context, keyframes []db.PlayerPosition) error {
+	if err := t.a.InsertPlayerPositionKeyframes(ctx, keyframes); err != nil {
+		return err
+	}
+	return t.b.InsertPlayerPositionKeyframes(ctx, keyframes)
+}
+
+func (t teeWriter) InsertPlayerPositionDeltas(ctx context.Context, deltas []db.PlayerPositionDelta) error {
+	if err := t.a.InsertPlayerPositionDeltas(ctx, deltas); err != nil {
+		return err
+	}
+	return t.b.InsertPlayerPositionDeltas(ctx, deltas)
+}
+
+func (t teeWriter) InsertPlayer(ctx context.Context, player db.Player) error {
+	if err := t.a.InsertPlayer(ctx, player); err != nil {
+		return err
+	}
+	return t.b.InsertPlayer(ctx, player)
+}
+
+func (t teeWriter) InsertChatMessages(ctx context.Context, messages []db.ChatMessage) error {
+	if err := t.a.InsertChatMessages(ctx, messages); err != nil {
+		return err
+	}
+	return t.b.InsertChatMessages(ctx, messages)
+}
+
+func (t teeWriter) InsertSystemChatEvents(ctx context.Context, events []db.SystemChatEvent) error {
+	if err := t.a.InsertSystemChatEvents(ctx, events); err != nil {
+		return err
+	}
+	return t.b.InsertSystemChatEvents(ctx, events)
+}
+
+func (t teeWriter) InsertGrenadePositions(ctx context.Context, positions []db.GrenadePosition) error {
+	if err := t.a.InsertGrenadePositions(ctx, positions); err != nil {
+		return err
+	}
+	return t.b.InsertGrenadePositions(ctx, positions)
+}
+
+func (t teeWriter) InsertGrenadeEvents(ctx context.Context, events []db.GrenadeEvent) error {
+	if err := t.a.InsertGrenadeEvents(ctx, events); err != nil {
+		return err
+	}
+	return t.b.InsertGrenadeEvents(ctx, events)
+}
+
+func (t teeWriter) InsertBombEvents(ctx context.Context, events []db.BombEvent) error {
+	if err := t.a.InsertBombEvents(ctx, events); err != nil {
+		return err
+	}
+	return t.b.InsertBombEvents(ctx, events)
+}
+
+func (t teeWriter) InsertGrenadePredictions(ctx context.Context, predictions []db.GrenadePrediction) error {
+	if err := t.a.InsertGrenadePredictions(ctx, predictions); err != nil {
+		return err
+	}
+	return t.b.InsertGrenadePredictions(ctx, predictions)
+}
+
+func (t teeWriter) InsertPlayerInputs(ctx context.Context, inputs []db.PlayerInput) error {
+	if err := t.a.InsertPlayerInputs(ctx, inputs); err != nil {
+		return err
+	}
+	return t.b.InsertPlayerInputs(ctx, inputs)
+}
+
+func (t teeWriter) InsertShots(ctx context.Context, shots []db.Shot) error {
+	if err := t.a.InsertShots(ctx, shots); err != nil {
+		return err
+	}
+	return t.b.InsertShots(ctx, shots)
+}
+
+func (t teeWriter) InsertWeaponSwitches(ctx context.Context, switches []db.WeaponSwitch) error {
+	if err := t.a.InsertWeaponSwitches(ctx, switches); err != nil {
+		return err
+	}
+	return t.b.InsertWeaponSwitches(ctx, switches)
+}
+
+func (t teeWriter) InsertPlayerRoundWeaponStats(ctx context.Context, stats []db.PlayerRoundWeaponStats) error {
+	if err := t.a.InsertPlayerRoundWeaponStats(ctx, stats); err != nil {
+		return err
+	}
+	return t.b.InsertPlayerRoundWeaponStats(ctx, stats)
+}
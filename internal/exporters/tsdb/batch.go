@@ -0,0 +1,97 @@
+package tsdb
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultBatchSize/defaultFlushInterval are used when a Config leaves
+// BatchSize/FlushInterval unset (<= 0).
+const (
+	defaultBatchSize     = 500
+	defaultFlushInterval = 10 * time.Second
+)
+
+// batcher accumulates Points and flushes them - on reaching batchSize, on a
+// flushInterval timer, or on Close - to a backend-supplied flushFn, so
+// influxSink/prometheusSink only need to implement "send this batch",
+// not their own buffering/timer goroutine.
+type batcher struct {
+	mu       sync.Mutex
+	points   []Point
+	batch    int
+	interval time.Duration
+	flushFn  func(points []Point) error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newBatcher(cfg Config, flushFn func(points []Point) error) *batcher {
+	batch := cfg.BatchSize
+	if batch <= 0 {
+		batch = defaultBatchSize
+	}
+	interval := cfg.FlushInterval
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+
+	b := &batcher{
+		batch:    batch,
+		interval: interval,
+		flushFn:  flushFn,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.flush()
+		case <-b.stop:
+			b.flush()
+			return
+		}
+	}
+}
+
+// add appends p, flushing immediately (on the caller's goroutine) if that
+// fills the batch, so a burst of points doesn't wait for the next tick.
+func (b *batcher) add(p Point) {
+	b.mu.Lock()
+	b.points = append(b.points, p)
+	full := len(b.points) >= b.batch
+	b.mu.Unlock()
+	if full {
+		b.flush()
+	}
+}
+
+func (b *batcher) flush() {
+	b.mu.Lock()
+	if len(b.points) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	points := b.points
+	b.points = nil
+	b.mu.Unlock()
+
+	_ = b.flushFn(points)
+}
+
+// close stops the flush timer and flushes whatever's left, blocking until
+// that final flush completes.
+func (b *batcher) close() error {
+	close(b.stop)
+	<-b.done
+	return nil
+}
@@ -0,0 +1,120 @@
+package tsdb
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influxSink writes Points as InfluxDB line protocol over HTTP to an
+// InfluxDB v2 /api/v2/write endpoint. Line protocol is a plain text format
+// (no client SDK needed), so this talks to InfluxDB with net/http alone.
+type influxSink struct {
+	url     string
+	token   string
+	client  *http.Client
+	batcher *batcher
+}
+
+func newInfluxSink(cfg Config) (Sink, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("tsdb: influx backend requires Config.URL")
+	}
+
+	writeURL := cfg.URL
+	if cfg.InfluxOrg != "" || cfg.InfluxBucket != "" {
+		writeURL = fmt.Sprintf("%s?org=%s&bucket=%s&precision=ns",
+			strings.TrimRight(cfg.URL, "/"), cfg.InfluxOrg, cfg.InfluxBucket)
+	}
+
+	s := &influxSink{
+		url:    writeURL,
+		token:  cfg.InfluxToken,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	s.batcher = newBatcher(cfg, s.flush)
+	return s, nil
+}
+
+func (s *influxSink) Write(p Point) error {
+	s.batcher.add(p)
+	return nil
+}
+
+func (s *influxSink) Close() error {
+	return s.batcher.close()
+}
+
+// flush encodes points as newline-delimited line protocol and POSTs them in
+// one request - InfluxDB's own recommended batching shape.
+func (s *influxSink) flush(points []Point) error {
+	var buf bytes.Buffer
+	for _, p := range points {
+		buf.WriteString(encodeLineProtocol(p))
+		buf.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, &buf)
+	if err != nil {
+		return err
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("tsdb: influx write returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encodeLineProtocol renders p as one InfluxDB line protocol line:
+// measurement,tag=val,tag=val field=val,field=val timestamp
+func encodeLineProtocol(p Point) string {
+	var b strings.Builder
+	b.WriteString(escapeLineProtocol(p.Measurement))
+
+	for _, k := range sortedKeys(p.Tags) {
+		b.WriteByte(',')
+		b.WriteString(escapeLineProtocol(k))
+		b.WriteByte('=')
+		b.WriteString(escapeLineProtocol(p.Tags[k]))
+	}
+
+	b.WriteByte(' ')
+	first := true
+	for _, k := range sortedFieldKeys(p.Fields) {
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		b.WriteString(escapeLineProtocol(k))
+		b.WriteByte('=')
+		b.WriteString(strconv.FormatFloat(p.Fields[k], 'g', -1, 64))
+	}
+
+	b.WriteByte(' ')
+	ts := p.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	b.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+	return b.String()
+}
+
+// escapeLineProtocol escapes the characters line protocol treats specially
+// in measurement/tag/field names and tag values: commas, spaces and equals
+// signs.
+func escapeLineProtocol(s string) string {
+	replacer := strings.NewReplacer(",", "\\,", " ", "\\ ", "=", "\\=")
+	return replacer.Replace(s)
+}
@@ -0,0 +1,120 @@
+package tsdb
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prometheusSink writes Points to a Prometheus Pushgateway. True Prometheus
+// remote-write is a protobuf+snappy wire format that needs generated
+// client-model/prompb types this repo doesn't vendor, so instead this POSTs
+// the plain Prometheus text exposition format to the Pushgateway's
+// /metrics/job/<job> endpoint, which is what the Pushgateway natively
+// accepts over HTTP - no client SDK required.
+type prometheusSink struct {
+	url     string
+	client  *http.Client
+	batcher *batcher
+}
+
+func newPrometheusSink(cfg Config) (Sink, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("tsdb: prometheus backend requires Config.URL")
+	}
+
+	job := cfg.PrometheusJob
+	if job == "" {
+		job = "cs2_demo_analyzer"
+	}
+	pushURL := fmt.Sprintf("%s/metrics/job/%s", strings.TrimRight(cfg.URL, "/"), job)
+
+	s := &prometheusSink{
+		url:    pushURL,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	s.batcher = newBatcher(cfg, s.flush)
+	return s, nil
+}
+
+func (s *prometheusSink) Write(p Point) error {
+	s.batcher.add(p)
+	return nil
+}
+
+func (s *prometheusSink) Close() error {
+	return s.batcher.close()
+}
+
+// flush renders points as Prometheus text exposition format and PUTs them
+// to the Pushgateway. A PUT replaces the job's whole metric group, matching
+// how the Pushgateway expects a batch push rather than incremental updates.
+func (s *prometheusSink) flush(points []Point) error {
+	var buf bytes.Buffer
+	for _, p := range points {
+		encodePrometheusPoint(&buf, p)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("tsdb: prometheus pushgateway returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encodePrometheusPoint writes one text-exposition line per field:
+// <measurement>_<field>{tag="val",...} <value> <timestamp_ms>
+func encodePrometheusPoint(buf *bytes.Buffer, p Point) {
+	labels := make([]string, 0, len(p.Tags))
+	for _, k := range sortedKeys(p.Tags) {
+		labels = append(labels, fmt.Sprintf("%s=%q", sanitizePromName(k), p.Tags[k]))
+	}
+	labelStr := ""
+	if len(labels) > 0 {
+		labelStr = "{" + strings.Join(labels, ",") + "}"
+	}
+
+	ts := p.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	tsMillis := ts.UnixMilli()
+
+	for _, k := range sortedFieldKeys(p.Fields) {
+		name := sanitizePromName(p.Measurement) + "_" + sanitizePromName(k)
+		buf.WriteString(name)
+		buf.WriteString(labelStr)
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatFloat(p.Fields[k], 'g', -1, 64))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(tsMillis, 10))
+		buf.WriteByte('\n')
+	}
+}
+
+// sanitizePromName replaces characters Prometheus metric/label names
+// disallow (anything but [a-zA-Z0-9_]) with underscores.
+func sanitizePromName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,144 @@
+// Package tsdb mirrors the extractor event stream to a time-series
+// database, turning each emitted extractors.Event into a Point a Grafana
+// dashboard can chart longitudinally across many matches - tracking
+// griefing trends over time instead of a one-off SQLite query against a
+// single match. Point-conversion happens at the export boundary (see
+// PointFromEvent), the same approach internal/sinks takes converting an
+// Event into a Record, rather than threading tsdb concerns through every
+// extractor.
+package tsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"cs-griefer-electron/internal/parser/extractors"
+)
+
+// Point is one time-series sample: measurement = event type, tags index
+// the series (match/round/actor/victim), fields carry the numeric values a
+// dashboard plots or aggregates.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]float64
+	Time        time.Time
+}
+
+// Sink writes Points to whatever time-series backend it was opened
+// against. Implementations batch internally (see newBatcher) rather than
+// making callers manage flushing themselves.
+type Sink interface {
+	Write(p Point) error
+	Close() error
+}
+
+// Open builds a Sink for backend ("none", "influx" or "prometheus").
+// "none" returns a nilSink that drops every point - the zero-config default
+// when --tsdb isn't set. cfg's batching fields apply to influx/prometheus
+// alike; see Config.
+func Open(backend string, cfg Config) (Sink, error) {
+	switch backend {
+	case "", "none":
+		return nilSink{}, nil
+	case "influx":
+		return newInfluxSink(cfg)
+	case "prometheus":
+		return newPrometheusSink(cfg)
+	default:
+		return nil, fmt.Errorf("tsdb: unsupported backend %q (want none, influx or prometheus)", backend)
+	}
+}
+
+// Config configures a Sink's destination and batching behavior.
+type Config struct {
+	// URL is the backend's write endpoint - an InfluxDB v2 bucket's
+	// /api/v2/write URL, or a Prometheus Pushgateway base URL.
+	URL string
+	// InfluxOrg/InfluxBucket/InfluxToken authenticate and scope writes to
+	// an InfluxDB v2 instance. Unused by the prometheus backend.
+	InfluxOrg    string
+	InfluxBucket string
+	InfluxToken  string
+	// PrometheusJob names the Pushgateway job grouping key. Unused by the
+	// influx backend.
+	PrometheusJob string
+	// BatchSize is how many points accumulate before an automatic flush.
+	BatchSize int
+	// FlushInterval is the longest a point waits before being flushed even
+	// if BatchSize hasn't been reached.
+	FlushInterval time.Duration
+}
+
+// nilSink silently drops every point - the --tsdb=none backend.
+type nilSink struct{}
+
+func (nilSink) Write(Point) error { return nil }
+func (nilSink) Close() error      { return nil }
+
+// PointFromEvent converts one extractors.Event into a Point: measurement is
+// the event type, tags are match_id/round_index/actor_steamid/
+// victim_steamid, and fields are severity/confidence plus every numeric
+// value found in the event's MetaJSON (distance, seconds, hit_count,
+// total_damage, ...) - string/bool meta keys (endedBy, state, reason, ...)
+// aren't representable as a time-series field, so they're dropped here;
+// they're still queryable via the events table/API for anything that needs
+// them.
+func PointFromEvent(matchID string, e extractors.Event) Point {
+	tags := map[string]string{
+		"match_id":    matchID,
+		"round_index": fmt.Sprintf("%d", e.RoundIndex),
+	}
+	if e.ActorSteamID != nil {
+		tags["actor_steamid"] = *e.ActorSteamID
+	}
+	if e.VictimSteamID != nil {
+		tags["victim_steamid"] = *e.VictimSteamID
+	}
+
+	fields := map[string]float64{
+		"severity":   e.Severity,
+		"confidence": e.Confidence,
+	}
+	if e.MetaJSON != nil {
+		var meta map[string]interface{}
+		if err := json.Unmarshal([]byte(*e.MetaJSON), &meta); err == nil {
+			for k, v := range meta {
+				if n, ok := v.(float64); ok {
+					fields[k] = n
+				}
+			}
+		}
+	}
+
+	return Point{
+		Measurement: e.Type,
+		Tags:        tags,
+		Fields:      fields,
+		Time:        time.Now(),
+	}
+}
+
+// sortedKeys returns m's keys in sorted order, so influxSink/prometheusSink
+// render tags/fields deterministically instead of depending on Go's
+// randomized map iteration order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedFieldKeys is sortedKeys for a float64-valued map.
+func sortedFieldKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,123 @@
+package tsdb
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"cs-griefer-electron/internal/parser/extractors"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestPointFromEventTagsAndFields(t *testing.T) {
+	meta := `{"distance": 128.5, "reason": "loitering", "hit_count": 3}`
+	e := extractors.Event{
+		Type:          "AFK_STILLNESS",
+		RoundIndex:    2,
+		StartTick:     100,
+		ActorSteamID:  strPtr("steam1"),
+		VictimSteamID: strPtr("steam2"),
+		Severity:      0.6,
+		Confidence:    0.9,
+		MetaJSON:      &meta,
+	}
+
+	p := PointFromEvent("match-1", e)
+
+	if p.Measurement != "AFK_STILLNESS" {
+		t.Errorf("Measurement = %q, want AFK_STILLNESS", p.Measurement)
+	}
+	if p.Tags["match_id"] != "match-1" || p.Tags["round_index"] != "2" {
+		t.Errorf("unexpected tags: %+v", p.Tags)
+	}
+	if p.Tags["actor_steamid"] != "steam1" || p.Tags["victim_steamid"] != "steam2" {
+		t.Errorf("unexpected actor/victim tags: %+v", p.Tags)
+	}
+	if p.Fields["severity"] != 0.6 || p.Fields["confidence"] != 0.9 {
+		t.Errorf("unexpected severity/confidence fields: %+v", p.Fields)
+	}
+	if p.Fields["distance"] != 128.5 || p.Fields["hit_count"] != 3 {
+		t.Errorf("expected numeric meta keys as fields, got %+v", p.Fields)
+	}
+	if _, ok := p.Fields["reason"]; ok {
+		t.Errorf("expected string meta key 'reason' to be dropped, got %+v", p.Fields)
+	}
+}
+
+func TestPointFromEventNoMeta(t *testing.T) {
+	e := extractors.Event{Type: "TEAM_KILL", RoundIndex: 0}
+	p := PointFromEvent("match-1", e)
+	if len(p.Fields) != 2 {
+		t.Errorf("expected only severity/confidence fields with no MetaJSON, got %+v", p.Fields)
+	}
+}
+
+func TestEncodeLineProtocol(t *testing.T) {
+	p := Point{
+		Measurement: "afk_stillness",
+		Tags:        map[string]string{"match_id": "m 1", "round_index": "2"},
+		Fields:      map[string]float64{"severity": 0.5, "distance": 12},
+		Time:        time.Unix(0, 1700000000000000000),
+	}
+
+	line := encodeLineProtocol(p)
+	want := `afk_stillness,match_id=m\ 1,round_index=2 distance=12,severity=0.5 1700000000000000000`
+	if line != want {
+		t.Errorf("encodeLineProtocol = %q, want %q", line, want)
+	}
+}
+
+func TestEncodePrometheusPoint(t *testing.T) {
+	p := Point{
+		Measurement: "afk-stillness",
+		Tags:        map[string]string{"match_id": "m1"},
+		Fields:      map[string]float64{"severity": 0.5},
+		Time:        time.Unix(1700000000, 0),
+	}
+
+	var buf bytes.Buffer
+	encodePrometheusPoint(&buf, p)
+	got := buf.String()
+	if !strings.Contains(got, `afk_stillness_severity{match_id="m1"} 0.5`) {
+		t.Errorf("encodePrometheusPoint output = %q, missing expected metric line", got)
+	}
+}
+
+func TestSanitizePromName(t *testing.T) {
+	if got := sanitizePromName("afk-stillness.v2"); got != "afk_stillness_v2" {
+		t.Errorf("sanitizePromName = %q, want afk_stillness_v2", got)
+	}
+}
+
+func TestOpenNoneBackend(t *testing.T) {
+	s, err := Open("none", Config{})
+	if err != nil {
+		t.Fatalf("Open(none) failed: %v", err)
+	}
+	if err := s.Write(Point{Measurement: "x"}); err != nil {
+		t.Errorf("nilSink.Write = %v, want nil", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("nilSink.Close = %v, want nil", err)
+	}
+}
+
+func TestOpenUnsupportedBackend(t *testing.T) {
+	if _, err := Open("timescale", Config{}); err == nil {
+		t.Fatal("expected an error for an unsupported backend")
+	}
+}
+
+func TestOpenInfluxRequiresURL(t *testing.T) {
+	if _, err := Open("influx", Config{}); err == nil {
+		t.Fatal("expected an error when --tsdb=influx is set without a URL")
+	}
+}
+
+func TestOpenPrometheusRequiresURL(t *testing.T) {
+	if _, err := Open("prometheus", Config{}); err == nil {
+		t.Fatal("expected an error when --tsdb=prometheus is set without a URL")
+	}
+}
@@ -0,0 +1,59 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	"cs-griefer-electron/internal/rediscache"
+)
+
+// Cache is the pluggable interface handlers cache hot reads behind: match
+// scores and player histories are cheap to compute once but expensive to
+// recompute on every request from a public-facing frontend. There's no
+// go-redis/cache vendored in this module (it builds offline, GOPROXY=off),
+// so redisCache below reuses the hand-rolled rediscache.Client this tree
+// already has (see internal/rediscache) instead - same gob-encoded-value,
+// configurable-TTL shape go-redis/cache would have given us, just without
+// the extra dependency.
+type Cache interface {
+	// Get decodes the cached value for key into dest (a pointer), reporting
+	// whether it was found.
+	Get(key string, dest any) (bool, error)
+	// Set stores value at key, expiring after ttl (0 means no expiry).
+	Set(key string, value any, ttl time.Duration) error
+}
+
+// redisCache is a Cache backed by rediscache.Client, gob-encoding values.
+// A nil client (no --cache-url configured) makes every method a clean
+// cache miss, since rediscache.Client's own methods are nil-safe.
+type redisCache struct {
+	client *rediscache.Client
+}
+
+// NewRedisCache wraps client as a Cache. client may be nil, in which case
+// the returned Cache always misses, so callers always pay the cost of a
+// direct Reader query - a deployment without --cache-url configured never
+// crashes.
+func NewRedisCache(client *rediscache.Client) Cache {
+	return &redisCache{client: client}
+}
+
+func (c *redisCache) Get(key string, dest any) (bool, error) {
+	data, ok, err := c.client.Get(key)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(dest); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (c *redisCache) Set(key string, value any, ttl time.Duration) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return err
+	}
+	return c.client.Set(key, buf.Bytes(), ttl)
+}
@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ipRateLimiter is a per-remote-IP token bucket, guarding a public
+// deployment of Server from any single client hammering the hot-query
+// endpoints. Buckets are created lazily on first request and refilled
+// continuously (ratePerSecond tokens/sec, capped at burst). Buckets are
+// never removed: the map keeps one entry per remote IP seen for the
+// limiter's lifetime.
+type ipRateLimiter struct {
+	mu            sync.Mutex
+	buckets       map[string]*tokenBucket
+	ratePerSecond float64
+	burst         float64
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// newIPRateLimiter returns a limiter allowing ratePerSecond requests/sec per
+// IP, with bursts up to burst requests. ratePerSecond <= 0 disables limiting
+// entirely (Allow always returns true) - the zero value for "no --api-rate-limit
+// configured".
+func newIPRateLimiter(ratePerSecond float64, burst int) *ipRateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &ipRateLimiter{
+		buckets:       make(map[string]*tokenBucket),
+		ratePerSecond: ratePerSecond,
+		burst:         float64(burst),
+	}
+}
+
+// allow reports whether a request from ip may proceed, consuming one token
+// from its bucket if so.
+func (l *ipRateLimiter) allow(ip string) bool {
+	if l.ratePerSecond <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, lastRefill: now}
+		l.buckets[ip] = b
+	}
+
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens = min(l.burst, b.tokens+elapsed*l.ratePerSecond)
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// middleware rejects requests over the per-IP rate limit with 429 before
+// they reach handler.
+func (l *ipRateLimiter) middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.allow(clientIP(r)) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// clientIP extracts the remote IP from r, preferring RemoteAddr (this
+// package has no trusted-proxy configuration, so X-Forwarded-For is
+// deliberately not honored - it's trivially spoofable by the very clients
+// the rate limiter exists to slow down).
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
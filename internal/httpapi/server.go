@@ -0,0 +1,648 @@
+// Package httpapi serves matches, rounds, positions, events and player
+// grief scores over HTTP, turning the one-shot parser CLI's SQLite/Postgres
+// output into a queryable backend for a web frontend. It sits on top of
+// db.Reader and scoring.Scorer: scores are never recomputed per-request
+// here (see cmd/parser's --rescore for that), only read back and
+// optionally cached.
+package httpapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cs-griefer-electron/internal/chat"
+	"cs-griefer-electron/internal/db"
+)
+
+const defaultEventPageSize = 100
+const defaultPositionPageSize = 1000
+
+// Config configures a Server.
+type Config struct {
+	// Cache wraps hot queries (match scores, player histories). Nil means
+	// every request hits reader directly.
+	Cache Cache
+	// CacheTTL is how long a cached entry stays fresh.
+	CacheTTL time.Duration
+	// RateLimitPerSecond bounds requests/sec per remote IP. <= 0 disables
+	// rate limiting.
+	RateLimitPerSecond float64
+	// RateLimitBurst is the token bucket size per IP (default 1 if <= 0).
+	RateLimitBurst int
+}
+
+// Server serves the grief-score HTTP API described in the package doc.
+type Server struct {
+	reader  *db.Reader
+	sqlDB   *sql.DB
+	cache   Cache
+	ttl     time.Duration
+	limiter *ipRateLimiter
+}
+
+// NewServer creates a Server reading from reader. sqlDB is the same
+// connection reader wraps; handleMatchChat's selector-based queries go
+// through internal/chat.Query, which needs direct SQL access beyond what
+// db.Reader exposes.
+func NewServer(reader *db.Reader, sqlDB *sql.DB, cfg Config) *Server {
+	return &Server{
+		reader:  reader,
+		sqlDB:   sqlDB,
+		cache:   cfg.Cache,
+		ttl:     cfg.CacheTTL,
+		limiter: newIPRateLimiter(cfg.RateLimitPerSecond, cfg.RateLimitBurst),
+	}
+}
+
+// Handler returns the API's routes wrapped in the per-IP rate limiter.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /matches", s.handleMatches)
+	mux.HandleFunc("GET /matches/{id}", s.handleMatch)
+	mux.HandleFunc("GET /matches/{id}/scores", s.handleMatchScores)
+	mux.HandleFunc("GET /matches/{id}/players/{sid}/scores", s.handlePlayerScore)
+	mux.HandleFunc("GET /players/{steamid}/history", s.handlePlayerHistory)
+	mux.HandleFunc("GET /matches/{id}/events", s.handleMatchEvents)
+	mux.HandleFunc("GET /matches/{id}/rounds", s.handleMatchRounds)
+	mux.HandleFunc("GET /matches/{id}/chat", s.handleMatchChat)
+	mux.HandleFunc("GET /matches/{id}/system-chat", s.handleMatchSystemChat)
+	mux.HandleFunc("GET /matches/{id}/positions", s.handleMatchPositions)
+	mux.HandleFunc("GET /matches/{id}/shots", s.handleMatchShots)
+	mux.HandleFunc("GET /matches/{id}/players/{sid}/name", s.handlePlayerName)
+	return s.limiter.middleware(withCORS(withGzip(mux)))
+}
+
+// matchesPage is handleMatches' response shape, paginated the same way
+// handleMatchEvents is - a long-running deployment can accumulate far more
+// matches than is reasonable to return in one response.
+type matchesPage struct {
+	Matches []db.Match `json:"matches"`
+	Page    int        `json:"page"`
+	Limit   int        `json:"limit"`
+}
+
+// handleMatches returns a page of every match in the database, most
+// recently started first, for a UI's top-level match list. Query params:
+// page (1-based, default 1) and limit (default defaultEventPageSize). Not
+// cached: new matches appear as demos are parsed, so this list changes far
+// more often than any one match's (immutable) scores/rounds.
+func (s *Server) handleMatches(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page := 1
+	if p := q.Get("page"); p != "" {
+		parsed, err := strconv.Atoi(p)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid page", http.StatusBadRequest)
+			return
+		}
+		page = parsed
+	}
+	limit := defaultEventPageSize
+	if l := q.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	matches, err := s.reader.GetMatches(r.Context(), limit, (page-1)*limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, matchesPage{Matches: matches, Page: page, Limit: limit})
+}
+
+// handleMatch returns one match's Match row, serving (and populating) a
+// "match:{id}" cache entry - like rounds and scores, a match's map/tick
+// rate/started_at are immutable once parsed.
+func (s *Server) handleMatch(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	key := "httpapi:match:" + matchID
+
+	var match db.Match
+	if s.fromCache(key, &match) {
+		writeJSON(w, match)
+		return
+	}
+
+	match, err := s.reader.GetMatch(r.Context(), matchID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.toCache(key, match)
+	writeJSON(w, match)
+}
+
+// handleMatchScores returns every player's grief score for --match-id,
+// serving (and populating) a "match:{id}:scores" cache entry. An optional
+// ?profile= query param restricts the response to scores written under
+// that scoring.ScoringProfile.Name (see --rescore); without it, a match
+// rescored under several profiles returns one row per (player, profile).
+func (s *Server) handleMatchScores(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	profileName := r.URL.Query().Get("profile")
+	key := "httpapi:match:" + matchID + ":scores:" + profileName
+
+	var scores []db.PlayerScore
+	if s.fromCache(key, &scores) {
+		writeJSON(w, scores)
+		return
+	}
+
+	var err error
+	if profileName != "" {
+		scores, err = s.reader.GetPlayerScoresByProfile(r.Context(), matchID, profileName)
+	} else {
+		scores, err = s.reader.GetPlayerScores(r.Context(), matchID)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.toCache(key, scores)
+	writeJSON(w, scores)
+}
+
+// handlePlayerScore returns one player's grief score in --match-id,
+// serving (and populating) a "match:{id}:player:{sid}:score:{profile}"
+// cache entry. An optional ?profile= query param is forwarded to
+// Reader.GetPlayerScore exactly as handleMatchScores' is.
+func (s *Server) handlePlayerScore(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	steamID := r.PathValue("sid")
+	profileName := r.URL.Query().Get("profile")
+	key := "httpapi:match:" + matchID + ":player:" + steamID + ":score:" + profileName
+
+	var score db.PlayerScore
+	if s.fromCache(key, &score) {
+		writeJSON(w, score)
+		return
+	}
+
+	score, err := s.reader.GetPlayerScore(r.Context(), matchID, steamID, profileName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.toCache(key, score)
+	writeJSON(w, score)
+}
+
+// handlePlayerHistory returns every match score for --steamid, serving
+// (and populating) a "player:{steamid}:history" cache entry.
+func (s *Server) handlePlayerHistory(w http.ResponseWriter, r *http.Request) {
+	steamID := r.PathValue("steamid")
+	key := "httpapi:player:" + steamID + ":history"
+
+	var history []db.PlayerScore
+	if s.fromCache(key, &history) {
+		writeJSON(w, history)
+		return
+	}
+
+	history, err := s.reader.GetPlayerScoreHistory(r.Context(), steamID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.toCache(key, history)
+	writeJSON(w, history)
+}
+
+// eventsPage is handleMatchEvents's response shape: the page of events plus
+// enough to let a client request the next one.
+type eventsPage struct {
+	Events []db.Event `json:"events"`
+	Page   int        `json:"page"`
+	Limit  int        `json:"limit"`
+}
+
+// handleMatchEvents returns a filtered, paginated page of --match-id's
+// events. Query params: type, round, actor (all optional filters), page
+// (1-based, default 1) and limit (default defaultEventPageSize). Not
+// cached: unlike scores and history, events are commonly filtered by a
+// near-unbounded combination of type/round/actor/page, so the cache hit
+// rate wouldn't justify the memory.
+func (s *Server) handleMatchEvents(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	q := r.URL.Query()
+
+	query := db.EventQuery{MatchID: matchID}
+	if t := q.Get("type"); t != "" {
+		query.Type = &t
+	}
+	if a := q.Get("actor"); a != "" {
+		query.SteamID = &a
+	}
+	if roundStr := q.Get("round"); roundStr != "" {
+		round, err := strconv.Atoi(roundStr)
+		if err != nil {
+			http.Error(w, "invalid round", http.StatusBadRequest)
+			return
+		}
+		query.Round = &round
+	}
+
+	page := 1
+	if p := q.Get("page"); p != "" {
+		parsed, err := strconv.Atoi(p)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid page", http.StatusBadRequest)
+			return
+		}
+		page = parsed
+	}
+	limit := defaultEventPageSize
+	if l := q.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	query.Limit = limit
+	query.Offset = (page - 1) * limit
+
+	events, err := s.reader.GetEvents(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, eventsPage{Events: events, Page: page, Limit: limit})
+}
+
+// handleMatchRounds returns every round of --match-id, serving (and
+// populating) a "match:{id}:rounds" cache entry - like scores, a match's
+// rounds are immutable once parsed, so they're worth caching the same way.
+func (s *Server) handleMatchRounds(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	key := "httpapi:match:" + matchID + ":rounds"
+
+	var rounds []db.Round
+	if s.fromCache(key, &rounds) {
+		writeJSON(w, rounds)
+		return
+	}
+
+	rounds, err := s.reader.GetRounds(r.Context(), matchID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.toCache(key, rounds)
+	writeJSON(w, rounds)
+}
+
+// chatPage is handleMatchChat's response shape when a selector is given:
+// the page of messages plus a cursor for requesting the next one (IRCv3
+// CHATHISTORY-style pagination - see internal/chat).
+type chatPage struct {
+	Messages []db.ChatMessage `json:"messages"`
+	Cursor   chat.Cursor      `json:"cursor"`
+}
+
+// handleMatchChat returns --match-id's chat messages.
+//
+// With no ?selector=, this is the original, backward-compatible
+// behavior: all-chat messages, optionally restricted to one player via
+// ?steamid=, oldest first.
+//
+// With ?selector=, it instead runs an internal/chat.Query: selector is
+// one of "latest:N", "before:TICK:N", "after:TICK:N",
+// "between:FROM:TO:N" or "around:TICK:N". Filters apply on top: steamid,
+// team_only=1, round (round index), q (case-sensitive substring match).
+//
+// Not cached, for the same reason as handleMatchEvents: the filter/
+// selector combination makes the key space too wide to be worth it.
+func (s *Server) handleMatchChat(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	q := r.URL.Query()
+
+	selectorParam := q.Get("selector")
+	if selectorParam == "" {
+		var steamID *string
+		if sid := q.Get("steamid"); sid != "" {
+			steamID = &sid
+		}
+
+		messages, err := s.reader.GetChatMessages(r.Context(), matchID, steamID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, messages)
+		return
+	}
+
+	selector, err := parseChatSelector(selectorParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filters := chat.Filters{TeamOnly: q.Get("team_only") == "1", SubstringMatch: q.Get("q")}
+	if sid := q.Get("steamid"); sid != "" {
+		filters.SteamID = &sid
+	}
+	if roundStr := q.Get("round"); roundStr != "" {
+		round, err := strconv.Atoi(roundStr)
+		if err != nil {
+			http.Error(w, "invalid round", http.StatusBadRequest)
+			return
+		}
+		filters.RoundIndex = &round
+	}
+
+	messages, cursor, err := chat.Query(r.Context(), s.sqlDB, matchID, selector, filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, chatPage{Messages: messages, Cursor: cursor})
+}
+
+// handleMatchSystemChat returns --match-id's server/system log lines (join/
+// leave, kick/ban, name changes, map/round transitions, cvar changes),
+// optionally restricted to one kind via ?kind=, oldest first - the join/
+// leave timeline handleMatchChat's player chat alone can't reconstruct.
+func (s *Server) handleMatchSystemChat(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+
+	var kind *string
+	if k := r.URL.Query().Get("kind"); k != "" {
+		kind = &k
+	}
+
+	events, err := s.reader.GetSystemChatEvents(r.Context(), matchID, kind)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, events)
+}
+
+// parseChatSelector parses handleMatchChat's colon-separated ?selector=
+// value into a chat.Selector.
+func parseChatSelector(s string) (chat.Selector, error) {
+	parts := strings.Split(s, ":")
+	atoi := func(field string) (int, error) {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, fmt.Errorf("invalid selector %q: %q is not an integer", s, field)
+		}
+		return v, nil
+	}
+
+	switch parts[0] {
+	case "latest":
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid selector %q: want latest:N", s)
+		}
+		n, err := atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		return chat.Latest{N: n}, nil
+	case "before", "after", "around":
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid selector %q: want %s:TICK:N", s, parts[0])
+		}
+		tick, err := atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		n, err := atoi(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		switch parts[0] {
+		case "before":
+			return chat.Before{Tick: tick, N: n}, nil
+		case "after":
+			return chat.After{Tick: tick, N: n}, nil
+		default:
+			return chat.Around{Tick: tick, N: n}, nil
+		}
+	case "between":
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("invalid selector %q: want between:FROM:TO:N", s)
+		}
+		from, err := atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := atoi(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		n, err := atoi(parts[3])
+		if err != nil {
+			return nil, err
+		}
+		return chat.Between{FromTick: from, ToTick: to, N: n}, nil
+	default:
+		return nil, fmt.Errorf("invalid selector %q: unknown kind %q", s, parts[0])
+	}
+}
+
+// positionsPage is handleMatchPositions' response shape, paginated the same
+// way handleMatchEvents is.
+type positionsPage struct {
+	Positions []db.PlayerPosition `json:"positions"`
+	Page      int                 `json:"page"`
+	Limit     int                 `json:"limit"`
+}
+
+// handleMatchPositions returns a filtered, paginated page of --match-id's
+// sampled player positions. Query params: round, steamid, tick_start,
+// tick_end (all optional filters), page (1-based, default 1) and limit
+// (default defaultPositionPageSize). Not cached, for the same reason as
+// handleMatchEvents: the filter combination makes the key space too wide.
+func (s *Server) handleMatchPositions(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	q := r.URL.Query()
+
+	query := db.PositionQuery{MatchID: matchID}
+	if sid := q.Get("steamid"); sid != "" {
+		query.SteamID = &sid
+	}
+	if roundStr := q.Get("round"); roundStr != "" {
+		round, err := strconv.Atoi(roundStr)
+		if err != nil {
+			http.Error(w, "invalid round", http.StatusBadRequest)
+			return
+		}
+		query.Round = &round
+	}
+	if tickStartStr := q.Get("tick_start"); tickStartStr != "" {
+		tickStart, err := strconv.Atoi(tickStartStr)
+		if err != nil {
+			http.Error(w, "invalid tick_start", http.StatusBadRequest)
+			return
+		}
+		query.TickStart = &tickStart
+	}
+	if tickEndStr := q.Get("tick_end"); tickEndStr != "" {
+		tickEnd, err := strconv.Atoi(tickEndStr)
+		if err != nil {
+			http.Error(w, "invalid tick_end", http.StatusBadRequest)
+			return
+		}
+		query.TickEnd = &tickEnd
+	}
+
+	page := 1
+	if p := q.Get("page"); p != "" {
+		parsed, err := strconv.Atoi(p)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid page", http.StatusBadRequest)
+			return
+		}
+		page = parsed
+	}
+	limit := defaultPositionPageSize
+	if l := q.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	query.Limit = limit
+	query.Offset = (page - 1) * limit
+
+	positions, err := s.reader.GetPlayerPositions(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, positionsPage{Positions: positions, Page: page, Limit: limit})
+}
+
+// shotsPage is handleMatchShots' response shape, paginated the same way
+// handleMatchEvents is.
+type shotsPage struct {
+	Shots []db.Shot `json:"shots"`
+	Page  int       `json:"page"`
+	Limit int       `json:"limit"`
+}
+
+// handleMatchShots returns a filtered, paginated page of --match-id's
+// weapon fire events. Query params: round, steamid (both optional
+// filters), page (1-based, default 1) and limit (default
+// defaultEventPageSize). Not cached, for the same reason as
+// handleMatchEvents: the filter/page combination makes the key space too
+// wide to be worth it.
+func (s *Server) handleMatchShots(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	q := r.URL.Query()
+
+	query := db.ShotQuery{MatchID: matchID}
+	if sid := q.Get("steamid"); sid != "" {
+		query.SteamID = &sid
+	}
+	if roundStr := q.Get("round"); roundStr != "" {
+		round, err := strconv.Atoi(roundStr)
+		if err != nil {
+			http.Error(w, "invalid round", http.StatusBadRequest)
+			return
+		}
+		query.Round = &round
+	}
+
+	page := 1
+	if p := q.Get("page"); p != "" {
+		parsed, err := strconv.Atoi(p)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid page", http.StatusBadRequest)
+			return
+		}
+		page = parsed
+	}
+	limit := defaultEventPageSize
+	if l := q.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	query.Limit = limit
+	query.Offset = (page - 1) * limit
+
+	shots, err := s.reader.GetShots(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, shotsPage{Shots: shots, Page: page, Limit: limit})
+}
+
+// playerNameResponse is handlePlayerName's response shape.
+type playerNameResponse struct {
+	SteamID string `json:"steamid"`
+	Name    string `json:"name"`
+}
+
+// handlePlayerName returns a player's in-match name, serving (and
+// populating) a "match:{id}:player:{sid}:name" cache entry.
+func (s *Server) handlePlayerName(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	steamID := r.PathValue("sid")
+	key := "httpapi:match:" + matchID + ":player:" + steamID + ":name"
+
+	var resp playerNameResponse
+	if s.fromCache(key, &resp) {
+		writeJSON(w, resp)
+		return
+	}
+
+	name, err := s.reader.GetPlayerName(r.Context(), matchID, steamID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp = playerNameResponse{SteamID: steamID, Name: name}
+	s.toCache(key, resp)
+	writeJSON(w, resp)
+}
+
+// fromCache reports whether key was found in s.cache, decoding it into
+// dest. Always false when s.cache is nil.
+func (s *Server) fromCache(key string, dest any) bool {
+	if s.cache == nil {
+		return false
+	}
+	ok, err := s.cache.Get(key, dest)
+	return err == nil && ok
+}
+
+// toCache stores value at key in s.cache, if configured. Errors are not
+// fatal to the request that triggered the populate - a write-through
+// failure just means the next request misses again.
+func (s *Server) toCache(key string, value any) {
+	if s.cache == nil {
+		return
+	}
+	_ = s.cache.Set(key, value, s.ttl)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintf(w, `{"error":%q}`, err.Error())
+	}
+}
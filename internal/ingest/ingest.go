@@ -0,0 +1,186 @@
+// Package ingest runs a worker pool over a crash-resumable queue of CS2
+// match share codes, turning each into a parsed match the same way
+// cmd/ingest-sharecode's single-shot loop does (see internal/sharecode),
+// but bounded by a rate limiter and backed by an on-disk queue so a
+// process that's killed mid-run picks back up where it left off instead
+// of re-submitting share codes that already succeeded.
+//
+// Resolving a share code's demo URL is pluggable via ResolveFunc rather
+// than hardwired to a specific Steam Web API client: the real mechanism
+// CS2 clients use (a CMsgGCCStrike15_v2_MatchList request over an
+// authenticated Game Coordinator connection) needs a logged-in Steam
+// client, not a REST call a --steam-api-key can make, and isn't available
+// in this offline build environment (no such GC client is vendored here).
+// internal/sharecode.Fetch's HTTP-mirror-template fallback is the default
+// ResolveFunc (see SharecodeResolver below); swapping in real GC
+// resolution later only means providing a different ResolveFunc, not
+// touching the queue or worker pool.
+package ingest
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/sharecode"
+)
+
+// ResolveFunc turns a share code into a locally available demo file plus
+// the matchID it should be stored under.
+type ResolveFunc func(ctx context.Context, code string) (matchID, demoPath string, err error)
+
+// SharecodeResolver adapts sharecode.Decode+sharecode.Fetch into a
+// ResolveFunc, the default way a Pool resolves a share code absent a real
+// Game Coordinator client.
+func SharecodeResolver(fetchCfg sharecode.FetchConfig) ResolveFunc {
+	return func(ctx context.Context, code string) (string, string, error) {
+		decoded, err := sharecode.Decode(code)
+		if err != nil {
+			return "", "", err
+		}
+		demoPath, err := sharecode.Fetch(ctx, fetchCfg, decoded)
+		if err != nil {
+			return "", "", err
+		}
+		return strconv.FormatUint(decoded.MatchID, 10), demoPath, nil
+	}
+}
+
+// HandleFunc parses demoPath and stores it under matchID - typically
+// NewParser+ParseWithDB followed by db.Writer.InsertMatch, the same step
+// cmd/ingest-sharecode's sharecode.Handler performs.
+type HandleFunc func(ctx context.Context, matchID, demoPath string) error
+
+// Config configures a Pool.
+type Config struct {
+	// Workers bounds how many share codes Run resolves/handles
+	// concurrently. <= 0 defaults to 1.
+	Workers int
+	// RatePerSecond caps how often ResolveFunc is called across all
+	// workers combined, so a shared demo mirror (or, eventually, the
+	// Steam Web API) doesn't get hammered by Workers goroutines at once.
+	// <= 0 means unlimited.
+	RatePerSecond int
+	// Reader is used to skip a share code whose matchID is already
+	// stored, so re-running Pool over a queue that partially succeeded
+	// last time doesn't re-download and re-parse those demos.
+	Reader *db.Reader
+}
+
+// Pool runs Queue's pending items through Resolve then Handle, bounded by
+// Config.Workers and Config.RatePerSecond, persisting each item's outcome
+// back to Queue as it completes.
+type Pool struct {
+	cfg     Config
+	queue   *Queue
+	resolve ResolveFunc
+	handle  HandleFunc
+	limiter *tokenBucket
+}
+
+// NewPool creates a Pool over queue, using resolve to turn a pending
+// share code into a demo file and handle to parse/store it.
+func NewPool(cfg Config, queue *Queue, resolve ResolveFunc, handle HandleFunc) *Pool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	return &Pool{
+		cfg:     cfg,
+		queue:   queue,
+		resolve: resolve,
+		handle:  handle,
+		limiter: newTokenBucket(cfg.RatePerSecond),
+	}
+}
+
+// Run processes every item Queue.Pending returns at call time, fanning out
+// across Config.Workers goroutines, and returns once they've all settled
+// (succeeded, failed, or were skipped as already-stored). A later Run call
+// - e.g. after a restart - picks up whatever Queue still reports pending.
+func (p *Pool) Run(ctx context.Context) error {
+	items := p.queue.Pending()
+	itemCh := make(chan Item)
+
+	go func() {
+		defer close(itemCh)
+		for _, item := range items {
+			select {
+			case itemCh <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range itemCh {
+				p.processOne(ctx, item)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// processOne resolves and handles one item, recording the outcome back to
+// p.queue. Errors are recorded on the queue rather than returned, since one
+// bad share code shouldn't stop the rest of the batch from being tried.
+func (p *Pool) processOne(ctx context.Context, item Item) {
+	matchID, err := p.dedupMatchID(ctx, item.Sharecode)
+	if err != nil {
+		p.queue.MarkFailed(item.Sharecode, err)
+		return
+	}
+	if matchID != "" {
+		p.queue.MarkDone(item.Sharecode)
+		return
+	}
+
+	if err := p.limiter.wait(ctx); err != nil {
+		p.queue.MarkFailed(item.Sharecode, err)
+		return
+	}
+
+	resolvedMatchID, demoPath, err := p.resolve(ctx, item.Sharecode)
+	if err != nil {
+		p.queue.MarkFailed(item.Sharecode, err)
+		return
+	}
+
+	if err := p.handle(ctx, resolvedMatchID, demoPath); err != nil {
+		p.queue.MarkFailed(item.Sharecode, err)
+		return
+	}
+
+	p.queue.MarkDone(item.Sharecode)
+}
+
+// dedupMatchID decodes code just far enough to check matches.id, without
+// resolving/downloading its demo. It returns ("", nil) when the match
+// isn't stored yet (the normal case); returns the matchID when it's
+// already there so the caller can skip re-processing it.
+func (p *Pool) dedupMatchID(ctx context.Context, code string) (string, error) {
+	if p.cfg.Reader == nil {
+		return "", nil
+	}
+	decoded, err := sharecode.Decode(code)
+	if err != nil {
+		return "", err
+	}
+	matchID := strconv.FormatUint(decoded.MatchID, 10)
+
+	exists, err := p.cfg.Reader.GetMatchExists(ctx, matchID)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return matchID, nil
+	}
+	return "", nil
+}
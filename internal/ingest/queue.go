@@ -0,0 +1,153 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// queueFileVersion guards against loading a queue file written by an
+// incompatible future/past version of this struct.
+const queueFileVersion = 1
+
+// Status is an Item's processing state.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Item is one share code tracked by a Queue.
+type Item struct {
+	Sharecode string `json:"sharecode"`
+	Status    Status `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+type queueFile struct {
+	Version int    `json:"version"`
+	Items   []Item `json:"items"`
+}
+
+// Queue is an on-disk, crash-resumable list of share codes to ingest: each
+// mutation is saved to Path immediately (write-temp-then-rename, the same
+// pattern parser.SaveCheckpoint uses), so a process killed mid-run can
+// reload it with LoadQueue and pick up wherever it left off, instead of
+// Pool re-submitting share codes that already succeeded.
+type Queue struct {
+	mu    sync.Mutex
+	path  string
+	items []Item
+	index map[string]int // sharecode -> index into items
+}
+
+// LoadQueue reads path's queue file, or returns an empty Queue backed by
+// path if it doesn't exist yet.
+func LoadQueue(path string) (*Queue, error) {
+	q := &Queue{path: path, index: make(map[string]int)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ingest: read queue file: %w", err)
+	}
+
+	var qf queueFile
+	if err := json.Unmarshal(data, &qf); err != nil {
+		return nil, fmt.Errorf("ingest: parse queue file: %w", err)
+	}
+	q.items = qf.Items
+	for i, item := range q.items {
+		q.index[item.Sharecode] = i
+	}
+	return q, nil
+}
+
+// Add enqueues code as StatusPending if it isn't already tracked. Returns
+// false if code was already in the queue (in any status), making repeated
+// Add calls for the same share code a no-op.
+func (q *Queue) Add(code string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.index[code]; ok {
+		return false, nil
+	}
+
+	q.index[code] = len(q.items)
+	q.items = append(q.items, Item{Sharecode: code, Status: StatusPending})
+	return true, q.save()
+}
+
+// Pending returns a snapshot of every item still in StatusPending or
+// StatusFailed - failed items are retried on the next Run the same as
+// pending ones, since MarkFailed doesn't distinguish permanent failures
+// from transient ones (the caller's HandleFunc/ResolveFunc already owns
+// its own retry/backoff, e.g. sharecode.Queue's RetryConfig).
+func (q *Queue) Pending() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	out := make([]Item, 0, len(q.items))
+	for _, item := range q.items {
+		if item.Status == StatusPending || item.Status == StatusFailed {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// MarkDone records code as StatusDone.
+func (q *Queue) MarkDone(code string) error {
+	return q.update(code, func(item *Item) {
+		item.Status = StatusDone
+		item.LastError = ""
+	})
+}
+
+// MarkFailed records code as StatusFailed, incrementing its attempt count
+// and recording err's message.
+func (q *Queue) MarkFailed(code string, err error) error {
+	return q.update(code, func(item *Item) {
+		item.Status = StatusFailed
+		item.Attempts++
+		if err != nil {
+			item.LastError = err.Error()
+		}
+	})
+}
+
+func (q *Queue) update(code string, mutate func(*Item)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i, ok := q.index[code]
+	if !ok {
+		return fmt.Errorf("ingest: %q is not in the queue", code)
+	}
+	mutate(&q.items[i])
+	return q.save()
+}
+
+// save writes the queue to q.path atomically. Callers must hold q.mu.
+func (q *Queue) save() error {
+	data, err := json.MarshalIndent(queueFile{Version: queueFileVersion, Items: q.items}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ingest: marshal queue: %w", err)
+	}
+
+	tmpPath := q.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("ingest: write queue temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, q.path); err != nil {
+		return fmt.Errorf("ingest: install queue file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package ingest
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueueAddIsIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := LoadQueue(path)
+	if err != nil {
+		t.Fatalf("LoadQueue: %v", err)
+	}
+
+	added, err := q.Add("CSGO-aaaaa-aaaaa-aaaaa-aaaaa-aaaaa")
+	if err != nil || !added {
+		t.Fatalf("first Add: added=%v err=%v, want added=true err=nil", added, err)
+	}
+
+	added, err = q.Add("CSGO-aaaaa-aaaaa-aaaaa-aaaaa-aaaaa")
+	if err != nil || added {
+		t.Fatalf("second Add: added=%v err=%v, want added=false err=nil", added, err)
+	}
+
+	if len(q.Pending()) != 1 {
+		t.Fatalf("expected 1 pending item, got %d", len(q.Pending()))
+	}
+}
+
+func TestQueuePersistsAcrossLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := LoadQueue(path)
+	if err != nil {
+		t.Fatalf("LoadQueue: %v", err)
+	}
+	if _, err := q.Add("CSGO-bbbbb-bbbbb-bbbbb-bbbbb-bbbbb"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := q.MarkDone("CSGO-bbbbb-bbbbb-bbbbb-bbbbb-bbbbb"); err != nil {
+		t.Fatalf("MarkDone: %v", err)
+	}
+
+	reloaded, err := LoadQueue(path)
+	if err != nil {
+		t.Fatalf("reload LoadQueue: %v", err)
+	}
+	if len(reloaded.Pending()) != 0 {
+		t.Fatalf("expected a done item to not be pending after reload, got %d pending", len(reloaded.Pending()))
+	}
+	if reloaded.items[0].Status != StatusDone {
+		t.Errorf("expected reloaded item to stay StatusDone, got %s", reloaded.items[0].Status)
+	}
+}
+
+func TestQueueMarkFailedRemainsPending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := LoadQueue(path)
+	if err != nil {
+		t.Fatalf("LoadQueue: %v", err)
+	}
+	code := "CSGO-ccccc-ccccc-ccccc-ccccc-ccccc"
+	if _, err := q.Add(code); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := q.MarkFailed(code, errors.New("mirror unreachable")); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+
+	pending := q.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("expected a failed item to still be retried (pending), got %d pending", len(pending))
+	}
+	if pending[0].Attempts != 1 {
+		t.Errorf("expected Attempts to increment to 1, got %d", pending[0].Attempts)
+	}
+	if pending[0].LastError != "mirror unreachable" {
+		t.Errorf("expected LastError recorded, got %q", pending[0].LastError)
+	}
+}
+
+func TestQueueUpdateUnknownCodeErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := LoadQueue(path)
+	if err != nil {
+		t.Fatalf("LoadQueue: %v", err)
+	}
+	if err := q.MarkDone("CSGO-zzzzz-zzzzz-zzzzz-zzzzz-zzzzz"); err == nil {
+		t.Error("expected MarkDone on an unknown share code to error")
+	}
+}
@@ -0,0 +1,56 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a minimal requests-per-second limiter, same shape and
+// same reasoning as steamapi's unexported tokenBucket: the repo would
+// normally reach for golang.org/x/time/rate here, but it isn't vendored
+// and this module is built offline (GOPROXY=off).
+type tokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+	enabled  bool
+}
+
+// newTokenBucket creates a limiter allowing at most ratePerSecond calls
+// per second, evenly spaced. ratePerSecond <= 0 disables limiting.
+func newTokenBucket(ratePerSecond int) *tokenBucket {
+	if ratePerSecond <= 0 {
+		return &tokenBucket{enabled: false}
+	}
+	return &tokenBucket{enabled: true, interval: time.Second / time.Duration(ratePerSecond)}
+}
+
+// wait blocks until the next call is allowed, or ctx is cancelled.
+func (t *tokenBucket) wait(ctx context.Context) error {
+	if !t.enabled {
+		return ctx.Err()
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
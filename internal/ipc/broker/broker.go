@@ -0,0 +1,229 @@
+// Package broker publishes extracted events to a pub/sub topic in real
+// time, as an optional side channel alongside the file/database output
+// runJSON/run already produce. No Redis or NATS client library is
+// vendored in this module, but both protocols are simple enough that a
+// plain TCP connection speaking the minimal "publish one message" subset
+// of each wire format is all a one-way publisher needs - see redis.go and
+// nats.go.
+package broker
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultQueueSize bounds how many unpublished messages an
+	// asyncPublisher buffers before it starts dropping.
+	defaultQueueSize = 1024
+	dialTimeout      = 3 * time.Second
+)
+
+// Publisher publishes payloads to a named topic. Publish never blocks the
+// caller beyond enqueueing onto a bounded channel: once full (or once the
+// underlying transport has failed), messages are dropped and counted
+// instead. Callers that don't configure a broker at all should use NoOp
+// rather than a nil Publisher.
+type Publisher interface {
+	Publish(topic string, payload []byte)
+
+	// PublishDedup is Publish plus a caller-assigned idempotency key. For
+	// the nats:// transport this rides along as a Nats-Msg-Id header,
+	// which a JetStream-backed subject uses to drop re-delivered
+	// duplicates within its configured duplicate window - useful when the
+	// same event is republished across a --resume run. Other transports
+	// (and JetStream-less NATS subjects) simply ignore dedupID.
+	PublishDedup(topic string, payload []byte, dedupID string)
+
+	Close() error
+}
+
+// rawPublisher is the blocking, one-message-at-a-time operation a concrete
+// transport implements. asyncPublisher wraps one to provide the
+// non-blocking Publisher contract. dedupID is "" when the caller has no
+// natural idempotency key for the message.
+type rawPublisher interface {
+	publish(topic string, payload []byte, dedupID string) error
+	close() error
+}
+
+// NoOp returns a Publisher that discards everything. Used when no --broker
+// flag is configured, or when Dial couldn't reach the configured one - file
+// output must never depend on a broker being available.
+func NoOp() Publisher {
+	return &droppingPublisher{}
+}
+
+// Dial parses rawURL (e.g. "redis://host:6379" or "nats://host:4222") and
+// connects to it. An unsupported scheme or unparseable URL is a
+// configuration error and is returned as such. A broker that's simply
+// unreachable right now is NOT an error: Dial logs a warning and returns a
+// Publisher that drops everything, so a demo parse never fails just
+// because a dashboard's broker happened to be down.
+func Dial(rawURL string) (Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("broker: invalid broker url %q: %w", rawURL, err)
+	}
+
+	var dial func(*url.URL) (rawPublisher, error)
+	switch u.Scheme {
+	case "redis":
+		dial = dialRedis
+	case "nats":
+		dial = dialNATS
+	default:
+		return nil, fmt.Errorf("broker: unsupported scheme %q (want redis:// or nats://)", u.Scheme)
+	}
+
+	raw, err := dial(u)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: broker unreachable, continuing with file-only output: %v\n", err)
+		return NoOp(), nil
+	}
+
+	return newAsyncPublisher(raw), nil
+}
+
+// EventTopic builds the "cs2.match.<id>.events.<type>" subject a parse run
+// publishes eventType's events under (e.g. "kill", "afk", "economy") - one
+// subject per event type, so a live consumer can subscribe to just the
+// events it cares about instead of filtering a single firehose topic.
+func EventTopic(matchID, eventType string) string {
+	return "cs2.match." + matchID + ".events." + eventType
+}
+
+// LifecycleTopic builds the "cs2.match.<id>.<marker>" subject a parse run
+// publishes round lifecycle markers under - marker is one of
+// "round_start", "freezetime_end" or "round_end".
+func LifecycleTopic(matchID, marker string) string {
+	return "cs2.match." + matchID + "." + marker
+}
+
+// droppingPublisher discards every message. It's what NoOp returns, and
+// also what Dial falls back to when the configured broker is unreachable.
+type droppingPublisher struct {
+	dropped uint64
+}
+
+func (p *droppingPublisher) Publish(topic string, payload []byte) {
+	atomic.AddUint64(&p.dropped, 1)
+}
+
+func (p *droppingPublisher) PublishDedup(topic string, payload []byte, dedupID string) {
+	atomic.AddUint64(&p.dropped, 1)
+}
+
+func (p *droppingPublisher) Close() error { return nil }
+
+// asyncPublisher makes a rawPublisher non-blocking: Publish enqueues onto a
+// bounded channel drained by a single background goroutine. The queue
+// overflowing, or the transport failing outright, both degrade to
+// dropping-with-count rather than blocking or crashing the parse.
+type asyncPublisher struct {
+	raw   rawPublisher
+	queue chan message
+
+	dropped uint64
+	failed  int32 // 1 once raw.publish has errored and given up for good
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+	done      chan struct{}
+}
+
+type message struct {
+	topic   string
+	payload []byte
+	dedupID string
+}
+
+func newAsyncPublisher(raw rawPublisher) *asyncPublisher {
+	p := &asyncPublisher{
+		raw:     raw,
+		queue:   make(chan message, defaultQueueSize),
+		closeCh: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go p.loop()
+	return p
+}
+
+func (p *asyncPublisher) Publish(topic string, payload []byte) {
+	p.PublishDedup(topic, payload, "")
+}
+
+func (p *asyncPublisher) PublishDedup(topic string, payload []byte, dedupID string) {
+	if atomic.LoadInt32(&p.failed) == 1 {
+		atomic.AddUint64(&p.dropped, 1)
+		return
+	}
+	select {
+	case p.queue <- message{topic: topic, payload: payload, dedupID: dedupID}:
+	default:
+		atomic.AddUint64(&p.dropped, 1)
+	}
+}
+
+func (p *asyncPublisher) loop() {
+	defer close(p.done)
+	for {
+		select {
+		case msg := <-p.queue:
+			if err := p.raw.publish(msg.topic, msg.payload, msg.dedupID); err != nil {
+				atomic.StoreInt32(&p.failed, 1)
+				fmt.Fprintf(os.Stderr, "WARN: broker publish failed, dropping further events: %v\n", err)
+				p.drainRemaining()
+				return
+			}
+		case <-p.closeCh:
+			p.flushRemaining()
+			return
+		}
+	}
+}
+
+// flushRemaining publishes whatever was already enqueued by the time Close
+// was called, so a graceful shutdown doesn't silently lose messages a
+// caller believed it had successfully handed off.
+func (p *asyncPublisher) flushRemaining() {
+	for {
+		select {
+		case msg := <-p.queue:
+			if err := p.raw.publish(msg.topic, msg.payload, msg.dedupID); err != nil {
+				atomic.AddUint64(&p.dropped, 1)
+				p.drainRemaining()
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *asyncPublisher) drainRemaining() {
+	for {
+		select {
+		case <-p.queue:
+			atomic.AddUint64(&p.dropped, 1)
+		default:
+			return
+		}
+	}
+}
+
+func (p *asyncPublisher) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.closeCh)
+	})
+	<-p.done
+
+	if dropped := atomic.LoadUint64(&p.dropped); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "WARN: broker publisher dropped %d event(s) (overflow or transport failure)\n", dropped)
+	}
+	return p.raw.close()
+}
@@ -0,0 +1,139 @@
+package broker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEventTopic(t *testing.T) {
+	if got, want := EventTopic("abc123", "kill"), "cs2.match.abc123.events.kill"; got != want {
+		t.Errorf("EventTopic(%q, %q) = %q, want %q", "abc123", "kill", got, want)
+	}
+}
+
+func TestLifecycleTopic(t *testing.T) {
+	if got, want := LifecycleTopic("abc123", "round_end"), "cs2.match.abc123.round_end"; got != want {
+		t.Errorf("LifecycleTopic(%q, %q) = %q, want %q", "abc123", "round_end", got, want)
+	}
+}
+
+func TestDialUnsupportedScheme(t *testing.T) {
+	if _, err := Dial("amqp://localhost:5672"); err == nil {
+		t.Fatal("expected an error for an unsupported scheme, got nil")
+	}
+}
+
+func TestDialInvalidURL(t *testing.T) {
+	if _, err := Dial("redis://%zz"); err == nil {
+		t.Fatal("expected an error for an unparseable url, got nil")
+	}
+}
+
+func TestNoOpDropsEverything(t *testing.T) {
+	pub := NoOp()
+	pub.Publish("topic", []byte("payload"))
+	if err := pub.Close(); err != nil {
+		t.Errorf("NoOp().Close() = %v, want nil", err)
+	}
+}
+
+// fakeRawPublisher records published messages (or returns failAfter's
+// error starting from the failAfter-th call) without touching the network.
+type fakeRawPublisher struct {
+	mu        sync.Mutex
+	published []message
+	failAfter int // 0 = never fail
+	block     chan struct{}
+}
+
+func (f *fakeRawPublisher) publish(topic string, payload []byte, dedupID string) error {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published = append(f.published, message{topic: topic, payload: payload, dedupID: dedupID})
+	if f.failAfter > 0 && len(f.published) >= f.failAfter {
+		return errors.New("fake transport failure")
+	}
+	return nil
+}
+
+func (f *fakeRawPublisher) close() error { return nil }
+
+func (f *fakeRawPublisher) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.published)
+}
+
+func TestAsyncPublisherDeliversMessages(t *testing.T) {
+	raw := &fakeRawPublisher{}
+	pub := newAsyncPublisher(raw)
+
+	pub.Publish("cs2.match.1.events", []byte(`{"type":"TEAM_KILL"}`))
+	pub.Publish("cs2.match.1.events", []byte(`{"type":"ABANDON"}`))
+
+	if err := pub.Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+	if raw.count() != 2 {
+		t.Errorf("expected 2 published messages, got %d", raw.count())
+	}
+}
+
+func TestAsyncPublisherForwardsDedupID(t *testing.T) {
+	raw := &fakeRawPublisher{}
+	pub := newAsyncPublisher(raw)
+
+	pub.PublishDedup("cs2.match.1.round_end", []byte(`{"round":3}`), "match-1:round_end:3")
+	if err := pub.Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+
+	if len(raw.published) != 1 {
+		t.Fatalf("expected 1 published message, got %d", len(raw.published))
+	}
+	if got, want := raw.published[0].dedupID, "match-1:round_end:3"; got != want {
+		t.Errorf("dedupID = %q, want %q", got, want)
+	}
+}
+
+func TestAsyncPublisherDropsOnTransportFailure(t *testing.T) {
+	raw := &fakeRawPublisher{failAfter: 1}
+	pub := newAsyncPublisher(raw)
+
+	pub.Publish("topic", []byte("1")) // consumed, then fails
+	// Give the background goroutine a moment to observe the failure and
+	// flip p.failed before the next Publish checks it.
+	time.Sleep(20 * time.Millisecond)
+	pub.Publish("topic", []byte("2")) // should be dropped immediately
+
+	if err := pub.Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+	if raw.count() != 1 {
+		t.Errorf("expected exactly 1 published message before the transport failed, got %d", raw.count())
+	}
+}
+
+func TestAsyncPublisherDropsOnOverflow(t *testing.T) {
+	raw := &fakeRawPublisher{block: make(chan struct{})}
+	pub := newAsyncPublisher(raw)
+
+	// The first Publish is picked up by the loop goroutine and blocks on
+	// raw.publish, so every subsequent one has to sit in the queue.
+	for i := 0; i < defaultQueueSize+10; i++ {
+		pub.Publish("topic", []byte("x"))
+	}
+	close(raw.block)
+
+	if err := pub.Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+	if pub.dropped == 0 {
+		t.Error("expected some messages to be dropped once the queue filled up")
+	}
+}
@@ -0,0 +1,107 @@
+package broker
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// dialNATS opens a plain TCP connection and speaks just enough of NATS's
+// line-based text protocol (INFO/CONNECT/PUB) to publish a message. No
+// client library is vendored in this module, but the protocol is simple
+// enough to hand-roll the one-way subset this package needs.
+//
+// This is fire-and-forget: a real NATS client keeps reading the
+// connection for async -ERR replies and PINGs, but a minimal publish-only
+// client without a reader loop can't react to those, so a server-side
+// rejection (e.g. bad auth) surfaces only as a closed connection on the
+// next write, not immediately. Good enough for a best-effort side channel
+// that already degrades to drop-with-count on any failure.
+func dialNATS(u *url.URL) (rawPublisher, error) {
+	addr := u.Host
+	if addr == "" {
+		addr = "127.0.0.1:4222"
+	}
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("nats: dial %s: %w", addr, err)
+	}
+
+	reader := bufio.NewReader(conn)
+	// Every NATS server greets with an INFO line before accepting commands.
+	if _, err := reader.ReadString('\n'); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats: read INFO: %w", err)
+	}
+
+	connectOpts := `{"verbose":false,"pedantic":false}`
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			connectOpts = fmt.Sprintf(`{"verbose":false,"pedantic":false,"user":%q,"pass":%q}`, u.User.Username(), pass)
+		}
+	}
+	if _, err := conn.Write([]byte("CONNECT " + connectOpts + "\r\n")); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats: write CONNECT: %w", err)
+	}
+
+	return &natsPublisher{conn: conn}, nil
+}
+
+type natsPublisher struct {
+	conn net.Conn
+}
+
+// publish sends a plain PUB frame, or - when dedupID is set - an HPUB
+// frame carrying a Nats-Msg-Id header. That header is the same one
+// nats.go's real JetStream client attaches for publish-side
+// deduplication: a JetStream-enabled stream remembers Nats-Msg-Id values
+// within its configured duplicate window and silently drops a re-publish
+// that repeats one, which is what gives an at-least-once republish (e.g.
+// a --resume run re-emitting a round already delivered) exactly-once
+// semantics on the consuming end. This client doesn't implement
+// JetStream's ack/retry protocol itself - see dialNATS's doc comment -
+// so "at-least-once" here means "the underlying stream may see a message
+// more than once and is responsible for deduping it", not that this
+// publisher guarantees delivery.
+func (n *natsPublisher) publish(topic string, payload []byte, dedupID string) error {
+	if dedupID == "" {
+		header := "PUB " + topic + " " + strconv.Itoa(len(payload)) + "\r\n"
+		if _, err := n.conn.Write([]byte(header)); err != nil {
+			return fmt.Errorf("nats: write PUB header: %w", err)
+		}
+		if _, err := n.conn.Write(payload); err != nil {
+			return fmt.Errorf("nats: write payload: %w", err)
+		}
+		if _, err := n.conn.Write([]byte("\r\n")); err != nil {
+			return fmt.Errorf("nats: write trailer: %w", err)
+		}
+		return nil
+	}
+
+	// HPUB <subject> <hdr-bytes> <total-bytes>\r\n<headers>\r\n\r\n<payload>\r\n
+	headers := "NATS/1.0\r\nNats-Msg-Id: " + dedupID + "\r\n\r\n"
+	hdrBytes := len(headers)
+	totalBytes := hdrBytes + len(payload)
+
+	header := "HPUB " + topic + " " + strconv.Itoa(hdrBytes) + " " + strconv.Itoa(totalBytes) + "\r\n"
+	if _, err := n.conn.Write([]byte(header)); err != nil {
+		return fmt.Errorf("nats: write HPUB header: %w", err)
+	}
+	if _, err := n.conn.Write([]byte(headers)); err != nil {
+		return fmt.Errorf("nats: write headers: %w", err)
+	}
+	if _, err := n.conn.Write(payload); err != nil {
+		return fmt.Errorf("nats: write payload: %w", err)
+	}
+	if _, err := n.conn.Write([]byte("\r\n")); err != nil {
+		return fmt.Errorf("nats: write trailer: %w", err)
+	}
+	return nil
+}
+
+func (n *natsPublisher) close() error {
+	return n.conn.Close()
+}
@@ -0,0 +1,92 @@
+package broker
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// dialRedis opens a plain TCP connection and speaks just enough RESP
+// (REdis Serialization Protocol) to call AUTH/PUBLISH. There's no real
+// client library vendored in this module, but RESP's wire format is
+// simple enough to hand-roll the one command this package needs.
+func dialRedis(u *url.URL) (rawPublisher, error) {
+	addr := u.Host
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("redis: dial %s: %w", addr, err)
+	}
+
+	r := &redisPublisher{conn: conn, reader: bufio.NewReader(conn)}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			if err := r.authenticate(u.User.Username(), pass); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+type redisPublisher struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (r *redisPublisher) authenticate(username, password string) error {
+	var err error
+	if username != "" {
+		_, err = r.command("AUTH", username, password)
+	} else {
+		_, err = r.command("AUTH", password)
+	}
+	if err != nil {
+		return fmt.Errorf("redis: auth: %w", err)
+	}
+	return nil
+}
+
+// publish ignores dedupID: plain Redis PUBLISH has no concept of
+// message-level deduplication.
+func (r *redisPublisher) publish(topic string, payload []byte, dedupID string) error {
+	_, err := r.command("PUBLISH", topic, string(payload))
+	return err
+}
+
+// command writes args as a RESP array of bulk strings and reads back a
+// single reply line. That's enough to detect errors without a full RESP
+// parser: AUTH/PUBLISH only ever reply with one integer/simple-string/
+// error line, never an array.
+func (r *redisPublisher) command(args ...string) (string, error) {
+	var buf []byte
+	buf = append(buf, []byte("*"+strconv.Itoa(len(args))+"\r\n")...)
+	for _, a := range args {
+		buf = append(buf, []byte("$"+strconv.Itoa(len(a))+"\r\n")...)
+		buf = append(buf, a...)
+		buf = append(buf, '\r', '\n')
+	}
+	if _, err := r.conn.Write(buf); err != nil {
+		return "", fmt.Errorf("redis: write: %w", err)
+	}
+
+	line, err := r.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("redis: read reply: %w", err)
+	}
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "-") {
+		return "", fmt.Errorf("redis: %s", strings.TrimPrefix(line, "-"))
+	}
+	return line, nil
+}
+
+func (r *redisPublisher) close() error {
+	return r.conn.Close()
+}
@@ -0,0 +1,342 @@
+// Package memgov replaces the old "check runtime.ReadMemStats every 10K
+// events and force a GC after every chunk flush" heuristic with an
+// adaptive governor: a background ticker samples HeapInuse, keeps an EWMA
+// of the allocation rate, and uses that to predict whether the next chunk
+// would breach a soft memory budget before it actually does, growing or
+// shrinking the caller's chunk size accordingly. A GC is only forced when
+// HeapInuse is already at or past the soft limit, instead of on every
+// flush regardless of need.
+package memgov
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMinChunkSize/DefaultMaxChunkSize bound how far ChunkSize can
+	// drift from its starting midpoint.
+	DefaultMinChunkSize = 10_000
+	DefaultMaxChunkSize = 500_000
+
+	DefaultSampleInterval = 500 * time.Millisecond
+	DefaultReportInterval = 5 * time.Second
+
+	// ewmaAlpha weights how much a single sample moves the smoothed
+	// allocation rate; 0.3 tracks real shifts within a few samples
+	// without reacting to every GC-driven wobble in HeapInuse.
+	ewmaAlpha = 0.3
+)
+
+// Config tunes a Governor. Zero values fall back to the defaults above.
+type Config struct {
+	// SoftLimitBytes is the HeapInuse level chunk sizing tries to stay
+	// under. 0 means "no soft limit" - ChunkSize then stays fixed at the
+	// Min/Max midpoint and RecordFlush never forces a GC.
+	SoftLimitBytes uint64
+	MinChunkSize   int
+	MaxChunkSize   int
+	SampleInterval time.Duration
+
+	// ReportInterval/OnReport, if both set, call OnReport with a Stats
+	// snapshot roughly every ReportInterval while the governor is
+	// running - the "humanize-style throughput report" hook for
+	// ipc.Output to log periodically.
+	ReportInterval time.Duration
+	OnReport       func(Stats)
+}
+
+func (c Config) withDefaults() Config {
+	if c.MinChunkSize <= 0 {
+		c.MinChunkSize = DefaultMinChunkSize
+	}
+	if c.MaxChunkSize <= 0 {
+		c.MaxChunkSize = DefaultMaxChunkSize
+	}
+	if c.MaxChunkSize < c.MinChunkSize {
+		c.MaxChunkSize = c.MinChunkSize
+	}
+	if c.SampleInterval <= 0 {
+		c.SampleInterval = DefaultSampleInterval
+	}
+	if c.ReportInterval <= 0 {
+		c.ReportInterval = DefaultReportInterval
+	}
+	return c
+}
+
+// Stats is a point-in-time snapshot of a Governor's throughput, for
+// logging or tests.
+type Stats struct {
+	BytesPerSec      float64
+	EventsPerSec     float64
+	ChunksFlushed    int
+	ForcedGCs        int
+	CurrentChunkSize int
+}
+
+// String renders s as a single humanize-style log line.
+func (s Stats) String() string {
+	return fmt.Sprintf("%.1f MB/s in, %.0f events/s out, %d chunk(s) flushed, %d forced GC(s), chunk size %d",
+		s.BytesPerSec/(1024*1024), s.EventsPerSec, s.ChunksFlushed, s.ForcedGCs, s.CurrentChunkSize)
+}
+
+// Governor samples process memory on a ticker and adapts a caller's chunk
+// size to it. Create one with New, call Start before parsing begins, and
+// Stop once it's done; ChunkSize/ShouldFlush/RecordFlush are safe to call
+// from the parsing goroutine concurrently with the sampling goroutine.
+type Governor struct {
+	cfg Config
+
+	mu         sync.Mutex
+	chunkSize  int
+	ewmaRate   float64 // bytes/sec
+	lastHeap   uint64
+	lastSample time.Time
+	lastReport time.Time
+	startTime  time.Time
+
+	totalEvents   int64
+	chunksFlushed int
+	forcedGCs     int
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// New creates a Governor. GOMEMLimit can be passed as cfg.SoftLimitBytes
+// when the caller wants to honor an externally-configured GOMEMLIMIT
+// instead of a --memory-limit flag; see GOMEMLimit.
+func New(cfg Config) *Governor {
+	cfg = cfg.withDefaults()
+	return &Governor{
+		cfg:       cfg,
+		chunkSize: (cfg.MinChunkSize + cfg.MaxChunkSize) / 2,
+	}
+}
+
+// GOMEMLimit returns the memory limit already in effect via the
+// GOMEMLIMIT environment variable or an earlier debug.SetMemoryLimit
+// call, or 0 if none is set (debug.SetMemoryLimit's "no limit" sentinel
+// is math.MaxInt64). Callers should prefer this over a separate
+// --memory-limit flag fighting an operator's GOMEMLIMIT.
+func GOMEMLimit() uint64 {
+	if v, ok := os.LookupEnv("GOMEMLIMIT"); ok {
+		if n, err := parseByteSize(v); err == nil {
+			return n
+		}
+	}
+	limit := debug.SetMemoryLimit(-1) // query without changing
+	if limit > 0 && limit != math.MaxInt64 {
+		return uint64(limit)
+	}
+	return 0
+}
+
+// parseByteSize parses GOMEMLIMIT's own syntax: a decimal number followed
+// by an optional unit (B, KiB, MiB, GiB, TiB).
+func parseByteSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix     string
+		multiplier uint64
+	}{
+		{"TiB", 1 << 40},
+		{"GiB", 1 << 30},
+		{"MiB", 1 << 20},
+		{"KiB", 1 << 10},
+		{"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			numPart := strings.TrimSuffix(s, u.suffix)
+			n, err := strconv.ParseUint(numPart, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("memgov: invalid GOMEMLIMIT %q: %w", s, err)
+			}
+			return n * u.multiplier, nil
+		}
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// Start launches the background sampling goroutine. Calling Start twice
+// without an intervening Stop is a no-op.
+func (g *Governor) Start() {
+	g.mu.Lock()
+	if g.stopCh != nil {
+		g.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	g.startTime = now
+	g.lastSample = now
+	g.lastReport = now
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	g.lastHeap = m.HeapInuse
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	g.stopCh = stopCh
+	g.doneCh = doneCh
+	g.mu.Unlock()
+
+	go g.loop(stopCh, doneCh)
+}
+
+// Stop halts the sampling goroutine and waits for it to exit.
+func (g *Governor) Stop() {
+	g.mu.Lock()
+	stopCh := g.stopCh
+	doneCh := g.doneCh
+	g.stopCh = nil
+	g.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+func (g *Governor) loop(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+	ticker := time.NewTicker(g.cfg.SampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.sample()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (g *Governor) sample() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	elapsed := now.Sub(g.lastSample).Seconds()
+	if elapsed > 0 {
+		var instRate float64
+		if m.HeapInuse > g.lastHeap {
+			instRate = float64(m.HeapInuse-g.lastHeap) / elapsed
+		}
+		g.ewmaRate = ewmaAlpha*instRate + (1-ewmaAlpha)*g.ewmaRate
+	}
+	g.lastHeap = m.HeapInuse
+	g.lastSample = now
+
+	g.adjustChunkSizeLocked(m.HeapInuse)
+
+	if g.cfg.OnReport != nil && now.Sub(g.lastReport) >= g.cfg.ReportInterval {
+		g.lastReport = now
+		stats := g.statsLocked()
+		go g.cfg.OnReport(stats)
+	}
+}
+
+// adjustChunkSizeLocked grows or shrinks chunkSize based on how many
+// sample intervals remain, at the current EWMA allocation rate, before
+// HeapInuse would reach SoftLimitBytes. Caller must hold g.mu.
+func (g *Governor) adjustChunkSizeLocked(heapInUse uint64) {
+	if g.cfg.SoftLimitBytes == 0 {
+		return
+	}
+	headroom := int64(g.cfg.SoftLimitBytes) - int64(heapInUse)
+	if headroom <= 0 {
+		g.chunkSize = g.cfg.MinChunkSize
+		return
+	}
+	if g.ewmaRate <= 0 {
+		return
+	}
+
+	secondsToLimit := float64(headroom) / g.ewmaRate
+	intervalSeconds := g.cfg.SampleInterval.Seconds()
+	switch {
+	case secondsToLimit < intervalSeconds*4:
+		// Projected to breach the soft limit within a few samples:
+		// shrink toward the floor so the next chunk flushes sooner.
+		g.chunkSize = max(g.cfg.MinChunkSize, g.chunkSize/2)
+	case secondsToLimit > intervalSeconds*20:
+		// Comfortable headroom: grow toward the ceiling.
+		g.chunkSize = min(g.cfg.MaxChunkSize, g.chunkSize+g.chunkSize/4+1)
+	}
+}
+
+// ChunkSize returns the currently recommended chunk size.
+func (g *Governor) ChunkSize() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.chunkSize
+}
+
+// ShouldFlush reports whether a chunk that has accumulated
+// itemsSinceLastFlush events should be flushed now.
+func (g *Governor) ShouldFlush(itemsSinceLastFlush int) bool {
+	return itemsSinceLastFlush >= g.ChunkSize()
+}
+
+// RecordFlush tells the governor a chunk of n events was just written to
+// disk, and forces a GC only if HeapInuse is already at or past the soft
+// limit - replacing the old "runtime.GC() after every flush" pattern,
+// which serialized parsing behind a stop-the-world GC regardless of
+// whether one was actually needed.
+func (g *Governor) RecordFlush(n int) {
+	g.mu.Lock()
+	g.totalEvents += int64(n)
+	g.chunksFlushed++
+	softLimit := g.cfg.SoftLimitBytes
+	g.mu.Unlock()
+
+	if softLimit == 0 {
+		return
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	if m.HeapInuse < softLimit {
+		return
+	}
+
+	g.mu.Lock()
+	g.forcedGCs++
+	g.mu.Unlock()
+	runtime.GC()
+}
+
+// Stats returns a point-in-time snapshot of the governor's throughput.
+func (g *Governor) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.statsLocked()
+}
+
+// statsLocked is Stats without taking the lock; caller must hold it.
+func (g *Governor) statsLocked() Stats {
+	var eventsPerSec float64
+	if elapsed := time.Since(g.startTime).Seconds(); elapsed > 0 {
+		eventsPerSec = float64(g.totalEvents) / elapsed
+	}
+	return Stats{
+		BytesPerSec:      g.ewmaRate,
+		EventsPerSec:     eventsPerSec,
+		ChunksFlushed:    g.chunksFlushed,
+		ForcedGCs:        g.forcedGCs,
+		CurrentChunkSize: g.chunkSize,
+	}
+}
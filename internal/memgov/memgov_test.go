@@ -0,0 +1,137 @@
+package memgov
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]uint64{
+		"1024": 1024,
+		"512B": 512,
+		"4KiB": 4 << 10,
+		"2MiB": 2 << 20,
+		"1GiB": 1 << 30,
+		"1TiB": 1 << 40,
+	}
+	for in, want := range cases {
+		got, err := parseByteSize(in)
+		if err != nil {
+			t.Errorf("parseByteSize(%q) returned error: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseByteSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+
+	if _, err := parseByteSize("not-a-size"); err == nil {
+		t.Error("expected an error for an unparseable GOMEMLIMIT value")
+	}
+}
+
+func TestGOMEMLimitHonorsEnv(t *testing.T) {
+	t.Setenv("GOMEMLIMIT", "256MiB")
+	if got := GOMEMLimit(); got != 256<<20 {
+		t.Errorf("GOMEMLimit() = %d, want %d", got, 256<<20)
+	}
+}
+
+func TestGOMEMLimitUnsetFallsBackToZero(t *testing.T) {
+	os.Unsetenv("GOMEMLIMIT")
+	// debug.SetMemoryLimit(-1) queries the process-wide limit, which is
+	// unset (math.MaxInt64) in a normal test binary, so this should fall
+	// through to the "no limit" sentinel.
+	if got := GOMEMLimit(); got != 0 {
+		t.Errorf("GOMEMLimit() with no GOMEMLIMIT set = %d, want 0", got)
+	}
+}
+
+func TestNewStartsAtMidpointChunkSize(t *testing.T) {
+	g := New(Config{MinChunkSize: 100, MaxChunkSize: 300})
+	if got := g.ChunkSize(); got != 200 {
+		t.Errorf("ChunkSize() = %d, want 200", got)
+	}
+}
+
+func TestAdjustChunkSizeShrinksNearLimit(t *testing.T) {
+	g := New(Config{SoftLimitBytes: 1000, MinChunkSize: 100, MaxChunkSize: 300, SampleInterval: time.Second})
+	g.chunkSize = 300
+	g.ewmaRate = 100 // bytes/sec
+
+	// heapInUse leaves only 200 bytes of headroom at 100 B/s -> 2s to
+	// limit, well under intervalSeconds*4 (4s), so it should shrink.
+	g.adjustChunkSizeLocked(800)
+	if g.chunkSize != 150 {
+		t.Errorf("chunkSize after shrink = %d, want 150", g.chunkSize)
+	}
+}
+
+func TestAdjustChunkSizeGrowsWithHeadroom(t *testing.T) {
+	g := New(Config{SoftLimitBytes: 1_000_000, MinChunkSize: 100, MaxChunkSize: 300, SampleInterval: time.Second})
+	g.chunkSize = 100
+	g.ewmaRate = 1 // bytes/sec - plenty of headroom at this rate
+
+	g.adjustChunkSizeLocked(0)
+	if g.chunkSize <= 100 {
+		t.Errorf("chunkSize after growth = %d, want > 100", g.chunkSize)
+	}
+}
+
+func TestAdjustChunkSizeAtOrPastLimitDropsToMin(t *testing.T) {
+	g := New(Config{SoftLimitBytes: 1000, MinChunkSize: 100, MaxChunkSize: 300})
+	g.chunkSize = 300
+	g.adjustChunkSizeLocked(1000)
+	if g.chunkSize != 100 {
+		t.Errorf("chunkSize at soft limit = %d, want MinChunkSize 100", g.chunkSize)
+	}
+}
+
+func TestAdjustChunkSizeNoSoftLimitIsNoop(t *testing.T) {
+	g := New(Config{MinChunkSize: 100, MaxChunkSize: 300})
+	g.chunkSize = 150
+	g.adjustChunkSizeLocked(999999999)
+	if g.chunkSize != 150 {
+		t.Errorf("chunkSize with no soft limit changed to %d, want unchanged 150", g.chunkSize)
+	}
+}
+
+func TestShouldFlush(t *testing.T) {
+	g := New(Config{MinChunkSize: 100, MaxChunkSize: 100})
+	if g.ShouldFlush(99) {
+		t.Error("ShouldFlush(99) with chunk size 100 = true, want false")
+	}
+	if !g.ShouldFlush(100) {
+		t.Error("ShouldFlush(100) with chunk size 100 = false, want true")
+	}
+}
+
+func TestRecordFlushForcesGCOnlyPastSoftLimit(t *testing.T) {
+	g := New(Config{}) // no soft limit: RecordFlush should never force a GC
+	g.RecordFlush(500)
+	stats := g.Stats()
+	if stats.ForcedGCs != 0 {
+		t.Errorf("ForcedGCs = %d, want 0 with no soft limit configured", stats.ForcedGCs)
+	}
+	if stats.ChunksFlushed != 1 {
+		t.Errorf("ChunksFlushed = %d, want 1", stats.ChunksFlushed)
+	}
+}
+
+func TestStatsString(t *testing.T) {
+	s := Stats{BytesPerSec: 2 * 1024 * 1024, EventsPerSec: 1500, ChunksFlushed: 3, ForcedGCs: 1, CurrentChunkSize: 50000}
+	got := s.String()
+	want := "2.0 MB/s in, 1500 events/s out, 3 chunk(s) flushed, 1 forced GC(s), chunk size 50000"
+	if got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestStartStopIsIdempotent(t *testing.T) {
+	g := New(Config{SampleInterval: 10 * time.Millisecond})
+	g.Start()
+	g.Start() // should be a no-op, not a second goroutine
+	g.Stop()
+	g.Stop() // should also be a no-op
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ZipArchive opens a .zip file once so ArchiveSource can mint a DemoSource
+// per .dem entry inside it without reopening the archive - archive/zip's
+// default decompressor already pools flate.Readers internally across
+// every File.Open call on the same Reader (see the stdlib's
+// flateReaderPool), which is the same "don't allocate a new decompressor
+// per file" benefit go-git's sync.ZLibReader pool gives per-blob, without
+// this package hand-rolling a pool of its own.
+//
+// HLTV-style demo bundles are also sometimes distributed as .rar - that
+// format isn't supported here: the only Go rar decoders are third-party
+// (e.g. github.com/nwaples/rardecode) and none are vendored in this
+// offline build, so ZipArchive is zip-only until one is added.
+type ZipArchive struct {
+	rc *zip.ReadCloser
+}
+
+// OpenZipArchive opens the zip file at path.
+func OpenZipArchive(path string) (*ZipArchive, error) {
+	rc, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive %s: %w", path, err)
+	}
+	return &ZipArchive{rc: rc}, nil
+}
+
+// DemoNames returns every entry in the archive ending in ".dem", in
+// archive order.
+func (a *ZipArchive) DemoNames() []string {
+	var names []string
+	for _, f := range a.rc.File {
+		if strings.HasSuffix(strings.ToLower(f.Name), ".dem") {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// Source returns a DemoSource for the named entry. The entry isn't opened
+// until NewParserFromSource calls Open on it, so minting one per name
+// returned by DemoNames is cheap even for an archive with hundreds of
+// demos.
+func (a *ZipArchive) Source(name string) DemoSource {
+	return ArchiveSource{archive: a, name: name}
+}
+
+// Close closes the underlying zip file. Every ArchiveSource minted from
+// this archive becomes unusable after Close - call it only once every
+// demo inside has finished parsing.
+func (a *ZipArchive) Close() error {
+	return a.rc.Close()
+}
+
+// ArchiveSource reads a single demo entry out of a ZipArchive - construct
+// one via ZipArchive.Source, not directly.
+type ArchiveSource struct {
+	archive *ZipArchive
+	name    string
+}
+
+// Open implements DemoSource.
+func (s ArchiveSource) Open(ctx context.Context) (io.ReadCloser, SourceMeta, error) {
+	var file *zip.File
+	for _, f := range s.archive.rc.File {
+		if f.Name == s.name {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return nil, SourceMeta{}, fmt.Errorf("entry %q not found in archive", s.name)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, SourceMeta{}, fmt.Errorf("failed to open archive entry %q: %w", s.name, err)
+	}
+
+	name := s.name
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return &archiveEntryReadCloser{rc: rc}, SourceMeta{Name: name, Size: int64(file.UncompressedSize64)}, nil
+}
+
+// archiveEntryReadCloser tolerates Close being called more than once, the
+// same "already closed" leniency Parser.Close gives the underlying
+// demoinfocs stream.
+type archiveEntryReadCloser struct {
+	rc     io.ReadCloser
+	closed bool
+}
+
+func (e *archiveEntryReadCloser) Read(p []byte) (int, error) {
+	return e.rc.Read(p)
+}
+
+func (e *archiveEntryReadCloser) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.rc.Close()
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create zip fixture: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to add entry %q: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write entry %q: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip fixture: %v", err)
+	}
+}
+
+func TestZipArchiveDemoNames(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bundle.zip")
+	writeTestZip(t, path, map[string]string{
+		"match1.dem": "demo-bytes-1",
+		"match2.dem": "demo-bytes-2",
+		"readme.txt": "not a demo",
+	})
+
+	archive, err := OpenZipArchive(path)
+	if err != nil {
+		t.Fatalf("OpenZipArchive failed: %v", err)
+	}
+	defer archive.Close()
+
+	names := archive.DemoNames()
+	if len(names) != 2 {
+		t.Fatalf("DemoNames() = %v, want 2 entries", names)
+	}
+}
+
+func TestArchiveSourceOpen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bundle.zip")
+	writeTestZip(t, path, map[string]string{
+		"demos/match1.dem": "demo-bytes-1",
+	})
+
+	archive, err := OpenZipArchive(path)
+	if err != nil {
+		t.Fatalf("OpenZipArchive failed: %v", err)
+	}
+	defer archive.Close()
+
+	src := archive.Source("demos/match1.dem")
+	rc, meta, err := src.Open(context.Background())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rc.Close()
+
+	if meta.Name != "match1.dem" {
+		t.Errorf("got name %q, want match1.dem", meta.Name)
+	}
+	if meta.Size != int64(len("demo-bytes-1")) {
+		t.Errorf("got size %d, want %d", meta.Size, len("demo-bytes-1"))
+	}
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(data) != "demo-bytes-1" {
+		t.Errorf("got %q, want %q", data, "demo-bytes-1")
+	}
+
+	// Close must tolerate being called more than once.
+	if err := rc.Close(); err != nil {
+		t.Errorf("second Close returned an error: %v", err)
+	}
+}
+
+func TestArchiveSourceOpenMissingEntry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bundle.zip")
+	writeTestZip(t, path, map[string]string{"match1.dem": "demo-bytes"})
+
+	archive, err := OpenZipArchive(path)
+	if err != nil {
+		t.Fatalf("OpenZipArchive failed: %v", err)
+	}
+	defer archive.Close()
+
+	if _, _, err := archive.Source("nonexistent.dem").Open(context.Background()); err == nil {
+		t.Error("expected an error for a missing archive entry")
+	}
+}
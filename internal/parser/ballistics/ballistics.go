@@ -0,0 +1,145 @@
+// Package ballistics predicts where a thrown grenade will come to rest and
+// detonate, so downstream tools can compare predicted vs. actual utility
+// placement and render per-round danger maps.
+//
+// Predict does not have real per-map collision geometry to clip against -
+// this repo's parser doesn't load BSP/nav-mesh data for any map - so it
+// approximates "the map's playable area" with a generic world bounding box
+// and a synthetic floor placed a fixed distance below the throw point,
+// rather than the map's actual geometry. Treat its output as an estimate
+// for visualization, not ground truth.
+package ballistics
+
+import "math"
+
+// Physics constants, taken from Source's defaults.
+const (
+	Gravity     = 800.0 // sv_gravity, units/s^2
+	Restitution = 0.45  // velocity retained (reflected) on a bounce
+)
+
+const (
+	// worldHalfExtent stands in for the map's actual playable-area bbox,
+	// which isn't loaded anywhere in this repo - see package doc.
+	worldHalfExtent = 16384.0
+	// floorDrop places the synthetic floor this far below the throw
+	// point, approximating "the grenade eventually hits the ground"
+	// without real geometry.
+	floorDrop = 600.0
+	// maxBounces caps how many reflections a grenade with no fixed fuse
+	// (i.e. none, in practice - kept as a safety net) gets before Predict
+	// gives up and reports wherever it currently is.
+	maxBounces = 6
+	// maxSteps is a hard safety cap in case a fuse/ground-contact
+	// condition is never satisfied.
+	maxSteps = 20000
+)
+
+// fuseSeconds holds grenades whose detonation is a fixed time after the
+// throw, independent of bounces or ground contact.
+var fuseSeconds = map[string]float64{
+	"hegrenade": 1.5,
+	"flashbang": 1.5,
+}
+
+// effectRadius holds each predictable grenade kind's effect radius in
+// world units. A kind absent from this map isn't predictable - Predict
+// returns ok=false (e.g. decoy, which this package doesn't model).
+var effectRadius = map[string]float64{
+	"hegrenade":    350,
+	"flashbang":    1000,
+	"incendiary":   150,
+	"smokegrenade": 144,
+}
+
+// Prediction is a projectile's predicted detonation/landing point.
+type Prediction struct {
+	X, Y, Z      float64
+	TickOffset   int // ticks after the throw tick
+	EffectRadius float64
+}
+
+// Predict integrates pos/vel forward from the throw tick under gravity,
+// reflecting off the generic world bbox described in the package doc,
+// until grenadeName's detonation condition is met:
+//
+//   - hegrenade/flashbang: a fixed fuse, fuseSeconds after the throw.
+//   - incendiary: detonates on first ground contact.
+//   - smokegrenade: detonates one second after first ground contact.
+//
+// ok is false for grenade kinds Predict doesn't model (e.g. decoy) or an
+// invalid tickRate.
+func Predict(grenadeName string, tickRate float64, pos, vel [3]float64) (Prediction, bool) {
+	radius, ok := effectRadius[grenadeName]
+	if !ok || tickRate <= 0 {
+		return Prediction{}, false
+	}
+
+	dt := 1.0 / tickRate
+	floorZ := pos[2] - floorDrop
+	minX, maxX := -worldHalfExtent, worldHalfExtent
+	minY, maxY := -worldHalfExtent, worldHalfExtent
+
+	x, y, z := pos[0], pos[1], pos[2]
+	vx, vy, vz := vel[0], vel[1], vel[2]
+
+	fuse, hasFuse := fuseSeconds[grenadeName]
+	fuseTicks := 0
+	if hasFuse {
+		fuseTicks = int(fuse * tickRate)
+	}
+
+	groundContactStep := -1
+	bounces := 0
+
+	for step := 0; step < maxSteps; step++ {
+		if hasFuse && step >= fuseTicks {
+			return Prediction{X: x, Y: y, Z: z, TickOffset: step, EffectRadius: radius}, true
+		}
+
+		vz -= Gravity * dt
+		x += vx * dt
+		y += vy * dt
+		z += vz * dt
+
+		bounced := false
+		if z <= floorZ {
+			z = floorZ
+			vz = -vz * Restitution
+			bounced = true
+			if groundContactStep < 0 {
+				groundContactStep = step
+			}
+		}
+		if x < minX || x > maxX {
+			x = math.Max(minX, math.Min(maxX, x))
+			vx = -vx * Restitution
+			bounced = true
+		}
+		if y < minY || y > maxY {
+			y = math.Max(minY, math.Min(maxY, y))
+			vy = -vy * Restitution
+			bounced = true
+		}
+		if bounced {
+			bounces++
+		}
+
+		switch grenadeName {
+		case "incendiary":
+			if groundContactStep >= 0 {
+				return Prediction{X: x, Y: y, Z: z, TickOffset: step, EffectRadius: radius}, true
+			}
+		case "smokegrenade":
+			if groundContactStep >= 0 && step-groundContactStep >= int(tickRate) {
+				return Prediction{X: x, Y: y, Z: z, TickOffset: step, EffectRadius: radius}, true
+			}
+		}
+
+		if !hasFuse && bounces > maxBounces {
+			return Prediction{X: x, Y: y, Z: z, TickOffset: step, EffectRadius: radius}, true
+		}
+	}
+
+	return Prediction{X: x, Y: y, Z: z, TickOffset: maxSteps, EffectRadius: radius}, true
+}
@@ -0,0 +1,61 @@
+package ballistics
+
+import "testing"
+
+func TestPredictUnknownGrenade(t *testing.T) {
+	if _, ok := Predict("decoy", 64, [3]float64{}, [3]float64{}); ok {
+		t.Fatal("expected decoy to be unpredictable")
+	}
+	if _, ok := Predict("hegrenade", 0, [3]float64{}, [3]float64{}); ok {
+		t.Fatal("expected invalid tickRate to be rejected")
+	}
+}
+
+func TestPredictHEGrenadeFixedFuse(t *testing.T) {
+	pos := [3]float64{0, 0, 100}
+	vel := [3]float64{100, 0, 200}
+	pred, ok := Predict("hegrenade", 64, pos, vel)
+	if !ok {
+		t.Fatal("expected hegrenade to be predictable")
+	}
+	if pred.EffectRadius != 350 {
+		t.Fatalf("EffectRadius = %v, want 350", pred.EffectRadius)
+	}
+	wantTicks := int(1.5 * 64)
+	if pred.TickOffset != wantTicks {
+		t.Fatalf("TickOffset = %d, want %d", pred.TickOffset, wantTicks)
+	}
+}
+
+func TestPredictIncendiaryDetonatesOnGroundContact(t *testing.T) {
+	pos := [3]float64{0, 0, 100}
+	vel := [3]float64{0, 0, 0}
+	pred, ok := Predict("incendiary", 64, pos, vel)
+	if !ok {
+		t.Fatal("expected incendiary to be predictable")
+	}
+	if pred.Z > pos[2]-floorDrop+1 {
+		t.Fatalf("expected incendiary to land at the synthetic floor, got Z=%v", pred.Z)
+	}
+	if pred.EffectRadius != 150 {
+		t.Fatalf("EffectRadius = %v, want 150", pred.EffectRadius)
+	}
+}
+
+func TestPredictSmokeWaitsAfterGroundContact(t *testing.T) {
+	pos := [3]float64{0, 0, 100}
+	vel := [3]float64{0, 0, 0}
+	tickRate := 64.0
+
+	incendiary, _ := Predict("incendiary", tickRate, pos, vel)
+	smoke, ok := Predict("smokegrenade", tickRate, pos, vel)
+	if !ok {
+		t.Fatal("expected smokegrenade to be predictable")
+	}
+	if smoke.TickOffset < incendiary.TickOffset+int(tickRate) {
+		t.Fatalf("expected smoke to detonate at least 1s after ground contact, got offset %d vs ground contact ~%d", smoke.TickOffset, incendiary.TickOffset)
+	}
+	if smoke.EffectRadius != 144 {
+		t.Fatalf("EffectRadius = %v, want 144", smoke.EffectRadius)
+	}
+}
@@ -0,0 +1,321 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// checkpointVersion guards against loading a checkpoint written by an
+// incompatible future/past version of this struct.
+const checkpointVersion = 1
+
+// Checkpoint is the state a crashed runJSON/run needs to resume a parse
+// instead of starting over: the last round ParseWithDB had fully flushed,
+// the byte offset it had written up to in the NDJSON temp file, and the
+// sorted-chunk files already finalized for rounds up to that point.
+//
+// demoinfocs-golang v5 exposes no way to seek into a CS2 demo - its
+// packets are delta-compressed against everything before them, so
+// GameState has to be rebuilt by walking from tick 0 regardless of where
+// a resumed run starts caring about output again. A Checkpoint therefore
+// doesn't let ParseWithDB skip decoding; it lets the caller skip
+// re-writing/re-merging output that's already safely on disk, which is
+// the expensive, I/O-bound part of a 40-minute parse.
+type Checkpoint struct {
+	Version        int      `json:"version"`
+	LastRoundIndex int      `json:"last_round_index"`
+	LastTick       int      `json:"last_tick"`
+	NDJSONOffset   int64    `json:"ndjson_offset"`
+	ChunkFiles     []string `json:"chunk_files"`
+	ExtractorHash  string   `json:"extractor_hash"`
+	Checksum       string   `json:"checksum"`
+}
+
+// checksumPayload returns the bytes Checkpoint.Checksum is computed over:
+// everything except the Checksum field itself.
+func (c Checkpoint) checksumPayload() ([]byte, error) {
+	c.Checksum = ""
+	return json.Marshal(c)
+}
+
+// sign sets Checksum to a SHA-256 digest of the rest of the checkpoint, so
+// IsCorrupted can detect a partially-written or hand-edited file.
+func (c Checkpoint) sign() (Checkpoint, error) {
+	payload, err := c.checksumPayload()
+	if err != nil {
+		return c, err
+	}
+	sum := sha256.Sum256(payload)
+	c.Checksum = hex.EncodeToString(sum[:])
+	return c, nil
+}
+
+// SaveCheckpoint writes ckpt to path atomically (write to a temp file in
+// the same directory, then rename) so a crash mid-write never leaves a
+// corrupt checkpoint for the next run to trip over.
+func SaveCheckpoint(path string, ckpt Checkpoint) error {
+	ckpt.Version = checkpointVersion
+	signed, err := ckpt.sign()
+	if err != nil {
+		return fmt.Errorf("failed to sign checkpoint: %w", err)
+	}
+
+	data, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to install checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint reads and parses a checkpoint file written by
+// SaveCheckpoint. It does not validate it - call IsCorrupted for that.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ckpt Checkpoint
+	if err := json.Unmarshal(data, &ckpt); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return &ckpt, nil
+}
+
+// MemCheckpoint is the state Parser.Checkpoint/ResumeParser exchange for
+// the in-memory Parse()/ParseWithDB path: the fully accumulated MatchData
+// captured so far, alongside the same round/tick bookkeeping Checkpoint
+// uses above. Unlike Checkpoint, this is written to an arbitrary io.Writer
+// (a file, a socket, an in-memory buffer) rather than a named path, since
+// a long-running in-memory parse's caller doesn't otherwise touch the
+// filesystem at all.
+//
+// demoinfocs-golang v5 exposes no mid-demo seek API (see Checkpoint's
+// comment above) and no RNG of its own - a CS2 demo is a deterministic
+// binary log of server snapshots, not a simulation replayed from a seed -
+// so there's no "RNG-relevant state" to capture; LastTick plus the
+// MatchData already extracted is everything a resume needs.
+type MemCheckpoint struct {
+	Version        int        `json:"version"`
+	LastRoundIndex int        `json:"last_round_index"`
+	LastTick       int        `json:"last_tick"`
+	Data           *MatchData `json:"data"`
+	Checksum       string     `json:"checksum"`
+}
+
+func (c MemCheckpoint) checksumPayload() ([]byte, error) {
+	c.Checksum = ""
+	return json.Marshal(c)
+}
+
+func (c MemCheckpoint) sign() (MemCheckpoint, error) {
+	payload, err := c.checksumPayload()
+	if err != nil {
+		return c, err
+	}
+	sum := sha256.Sum256(payload)
+	c.Checksum = hex.EncodeToString(sum[:])
+	return c, nil
+}
+
+// Checkpoint serializes the MatchData a Parse/ParseWithDB call in progress
+// has accumulated so far, plus the round/tick it got through, to w as
+// JSON. The only safe place to call it is from the SetCheckpointCallback
+// hook - that callback runs synchronously on the same goroutine driving
+// the parse, the one window a caller has onto state that is otherwise
+// being mutated concurrently with the parse itself.
+func (p *Parser) Checkpoint(w io.Writer) error {
+	if p.data == nil {
+		return fmt.Errorf("checkpoint: no parse in progress")
+	}
+	ckpt := MemCheckpoint{
+		Version:        checkpointVersion,
+		LastRoundIndex: p.checkpointRound,
+		LastTick:       p.checkpointTick,
+		Data:           p.data,
+	}
+	signed, err := ckpt.sign()
+	if err != nil {
+		return fmt.Errorf("failed to sign checkpoint: %w", err)
+	}
+	if err := json.NewEncoder(w).Encode(signed); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ResumeParser reopens demoPath and configures the resulting Parser to
+// continue a parse checkpointed by Checkpoint: the next Parse/ParseWithDB
+// call returns MatchData where rounds up to the checkpoint's
+// LastRoundIndex come straight from the checkpoint, and only rounds after
+// that are re-extracted from the fresh parse (see mergeResumedMatchData
+// and ResumeFrom's doc comment) - demoinfocs-golang v5 still has to walk
+// every tick from 0 to reach them, the same "skip re-recording, not
+// re-decoding" trade-off the disk-backed Checkpoint flow above makes.
+//
+// This lets a long-running in-memory analysis retried after an
+// ErrUnexpectedEndOfDemo or an OOM restart pick up from its last
+// checkpoint instead of starting over from nothing. Close on the returned
+// Parser releases the reopened demo file as usual.
+func ResumeParser(r io.Reader, demoPath string) (*Parser, error) {
+	var ckpt MemCheckpoint
+	if err := json.NewDecoder(r).Decode(&ckpt); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	want := ckpt.Checksum
+	resigned, err := ckpt.sign()
+	if err != nil || resigned.Checksum != want {
+		return nil, fmt.Errorf("checkpoint failed integrity check (corrupt or hand-edited)")
+	}
+	if ckpt.Version != checkpointVersion {
+		return nil, fmt.Errorf("checkpoint version %d unsupported by this build (want %d)", ckpt.Version, checkpointVersion)
+	}
+	if ckpt.Data == nil {
+		return nil, fmt.Errorf("checkpoint has no data to resume from")
+	}
+
+	p, err := NewParser(demoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reopen demo for resume: %w", err)
+	}
+	if err := p.ResumeFrom(&Checkpoint{LastRoundIndex: ckpt.LastRoundIndex, LastTick: ckpt.LastTick}); err != nil {
+		p.Close()
+		return nil, err
+	}
+	p.resumeData = ckpt.Data
+	return p, nil
+}
+
+// mergeResumedMatchData combines a checkpointed MatchData (rounds up to
+// and including resumeFromRound) with a fresh re-parse's MatchData, which
+// - since demoinfocs-golang v5 can't skip decoding - still walked from
+// tick 0 and so re-derives the same early rounds too. Early rounds come
+// from prior, everything after resumeFromRound comes from fresh.
+// data.Events isn't touched here - flushEventsFromExtractors already
+// round-filters it the same way ResumeFrom always has.
+func mergeResumedMatchData(prior, fresh *MatchData, resumeFromRound int) *MatchData {
+	merged := &MatchData{
+		Map:       fresh.Map,
+		TickRate:  fresh.TickRate,
+		StartedAt: fresh.StartedAt,
+		Source:    fresh.Source,
+		Players:   fresh.Players,
+		Events:    fresh.Events,
+	}
+
+	for _, round := range prior.Rounds {
+		if round.RoundIndex <= resumeFromRound {
+			merged.Rounds = append(merged.Rounds, round)
+		}
+	}
+	for _, round := range fresh.Rounds {
+		if round.RoundIndex > resumeFromRound {
+			merged.Rounds = append(merged.Rounds, round)
+		}
+	}
+
+	for _, msg := range prior.ChatMessages {
+		if msg.RoundIndex <= resumeFromRound {
+			merged.ChatMessages = append(merged.ChatMessages, msg)
+		}
+	}
+	for _, msg := range fresh.ChatMessages {
+		if msg.RoundIndex > resumeFromRound {
+			merged.ChatMessages = append(merged.ChatMessages, msg)
+		}
+	}
+
+	for _, pos := range prior.Positions {
+		if pos.RoundIndex <= resumeFromRound {
+			merged.Positions = append(merged.Positions, pos)
+		}
+	}
+	for _, pos := range fresh.Positions {
+		if pos.RoundIndex > resumeFromRound {
+			merged.Positions = append(merged.Positions, pos)
+		}
+	}
+
+	for _, gp := range prior.GrenadePositions {
+		if gp.RoundIndex <= resumeFromRound {
+			merged.GrenadePositions = append(merged.GrenadePositions, gp)
+		}
+	}
+	for _, gp := range fresh.GrenadePositions {
+		if gp.RoundIndex > resumeFromRound {
+			merged.GrenadePositions = append(merged.GrenadePositions, gp)
+		}
+	}
+
+	for _, ge := range prior.GrenadeEvents {
+		if ge.RoundIndex <= resumeFromRound {
+			merged.GrenadeEvents = append(merged.GrenadeEvents, ge)
+		}
+	}
+	for _, ge := range fresh.GrenadeEvents {
+		if ge.RoundIndex > resumeFromRound {
+			merged.GrenadeEvents = append(merged.GrenadeEvents, ge)
+		}
+	}
+
+	for _, shot := range prior.Shots {
+		if shot.RoundIndex <= resumeFromRound {
+			merged.Shots = append(merged.Shots, shot)
+		}
+	}
+	for _, shot := range fresh.Shots {
+		if shot.RoundIndex > resumeFromRound {
+			merged.Shots = append(merged.Shots, shot)
+		}
+	}
+
+	return merged
+}
+
+// IsCorrupted reports whether ckpt should be distrusted and discarded in
+// favor of a clean parse, analogous to a leveldb-style IsCorrupted check:
+// either its checksum no longer matches its contents (partial write, or
+// hand edit), its version doesn't match what this build writes, the
+// NDJSON file it references has been truncated below the offset it
+// claims to have written through (e.g. a crash mid-write, or the temp
+// file got clobbered by an unrelated run), or one of its ChunkFiles no
+// longer exists.
+func IsCorrupted(ckpt *Checkpoint, ndjsonPath string) bool {
+	if ckpt == nil {
+		return true
+	}
+	if ckpt.Version != checkpointVersion {
+		return true
+	}
+
+	want := ckpt.Checksum
+	resigned, err := (*ckpt).sign()
+	if err != nil || resigned.Checksum != want {
+		return true
+	}
+
+	info, err := os.Stat(ndjsonPath)
+	if err != nil || info.Size() < ckpt.NDJSONOffset {
+		return true
+	}
+
+	for _, chunkFile := range ckpt.ChunkFiles {
+		if _, err := os.Stat(chunkFile); err != nil {
+			return true
+		}
+	}
+
+	return false
+}
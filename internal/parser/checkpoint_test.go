@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadCheckpointRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	ckptPath := filepath.Join(dir, "out.json.ckpt")
+	ndjsonPath := filepath.Join(dir, "out.json.events.tmp")
+
+	if err := os.WriteFile(ndjsonPath, make([]byte, 100), 0o644); err != nil {
+		t.Fatalf("failed to create fake events file: %v", err)
+	}
+
+	want := Checkpoint{LastRoundIndex: 3, LastTick: 12345, NDJSONOffset: 100}
+	if err := SaveCheckpoint(ckptPath, want); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+
+	got, err := LoadCheckpoint(ckptPath)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	if got.LastRoundIndex != want.LastRoundIndex || got.LastTick != want.LastTick || got.NDJSONOffset != want.NDJSONOffset {
+		t.Errorf("round-tripped checkpoint = %+v, want fields matching %+v", got, want)
+	}
+	if got.Checksum == "" {
+		t.Error("expected SaveCheckpoint to sign the checkpoint with a non-empty checksum")
+	}
+	if IsCorrupted(got, ndjsonPath) {
+		t.Error("freshly round-tripped checkpoint should not be reported as corrupted")
+	}
+}
+
+func TestIsCorruptedDetectsTamperedChecksum(t *testing.T) {
+	dir := t.TempDir()
+	ckptPath := filepath.Join(dir, "out.json.ckpt")
+	ndjsonPath := filepath.Join(dir, "out.json.events.tmp")
+	os.WriteFile(ndjsonPath, make([]byte, 100), 0o644)
+
+	ckpt := Checkpoint{LastRoundIndex: 1, LastTick: 500, NDJSONOffset: 100}
+	if err := SaveCheckpoint(ckptPath, ckpt); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(ckptPath)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	loaded.LastTick = 999999 // tamper with a signed field without re-signing
+
+	if !IsCorrupted(loaded, ndjsonPath) {
+		t.Error("expected a tampered checkpoint to be reported as corrupted")
+	}
+}
+
+func TestIsCorruptedDetectsTruncatedNDJSON(t *testing.T) {
+	dir := t.TempDir()
+	ckptPath := filepath.Join(dir, "out.json.ckpt")
+	ndjsonPath := filepath.Join(dir, "out.json.events.tmp")
+
+	ckpt := Checkpoint{LastRoundIndex: 2, LastTick: 800, NDJSONOffset: 500}
+	if err := SaveCheckpoint(ckptPath, ckpt); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+
+	// NDJSON file is shorter than the offset the checkpoint claims to
+	// have written through - e.g. a crash mid-write.
+	os.WriteFile(ndjsonPath, make([]byte, 10), 0o644)
+
+	loaded, err := LoadCheckpoint(ckptPath)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	if !IsCorrupted(loaded, ndjsonPath) {
+		t.Error("expected a truncated NDJSON file to fail the corruption check")
+	}
+}
+
+func TestIsCorruptedNilCheckpoint(t *testing.T) {
+	if !IsCorrupted(nil, "/nonexistent") {
+		t.Error("a nil checkpoint should always be reported as corrupted")
+	}
+}
+
+func TestResumeFromSuppressesEarlierRounds(t *testing.T) {
+	p := &Parser{resumeFromRound: -1}
+	if err := p.ResumeFrom(&Checkpoint{LastRoundIndex: 5}); err != nil {
+		t.Fatalf("ResumeFrom failed: %v", err)
+	}
+	if p.resumeFromRound != 5 {
+		t.Errorf("resumeFromRound = %d, want 5", p.resumeFromRound)
+	}
+
+	if err := p.ResumeFrom(nil); err != nil {
+		t.Fatalf("ResumeFrom(nil) failed: %v", err)
+	}
+	if p.resumeFromRound != -1 {
+		t.Errorf("resumeFromRound after ResumeFrom(nil) = %d, want -1", p.resumeFromRound)
+	}
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	p := &Parser{resumeFromRound: -1}
+	p.data = &MatchData{
+		Map:     "de_dust2",
+		Rounds:  []RoundData{{RoundIndex: 0, StartTick: 0, EndTick: 1000}},
+		Players: []PlayerData{{SteamID: "76561198000000001", Name: "alice"}},
+	}
+	p.checkpointRound = 0
+	p.checkpointTick = 1000
+
+	var buf bytes.Buffer
+	if err := p.Checkpoint(&buf); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+
+	var ckpt MemCheckpoint
+	if err := json.Unmarshal(buf.Bytes(), &ckpt); err != nil {
+		t.Fatalf("failed to decode checkpoint: %v", err)
+	}
+	if ckpt.LastRoundIndex != 0 || ckpt.LastTick != 1000 {
+		t.Errorf("got round=%d tick=%d, want round=0 tick=1000", ckpt.LastRoundIndex, ckpt.LastTick)
+	}
+	if ckpt.Checksum == "" {
+		t.Error("expected Checkpoint to sign the checkpoint with a non-empty checksum")
+	}
+	if ckpt.Data == nil || ckpt.Data.Map != "de_dust2" {
+		t.Errorf("expected Data to round-trip, got %+v", ckpt.Data)
+	}
+}
+
+func TestCheckpointWithoutInProgressParseFails(t *testing.T) {
+	p := &Parser{resumeFromRound: -1}
+	var buf bytes.Buffer
+	if err := p.Checkpoint(&buf); err == nil {
+		t.Error("expected Checkpoint to fail when no parse is in progress")
+	}
+}
+
+func TestResumeParserRejectsTamperedChecksum(t *testing.T) {
+	p := &Parser{resumeFromRound: -1}
+	p.data = &MatchData{Map: "de_mirage"}
+	p.checkpointRound = 2
+	p.checkpointTick = 5000
+
+	var buf bytes.Buffer
+	if err := p.Checkpoint(&buf); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	tampered := bytes.Replace(buf.Bytes(), []byte("de_mirage"), []byte("de_nuke!!"), 1)
+
+	if _, err := ResumeParser(bytes.NewReader(tampered), "/nonexistent/demo.dem"); err == nil {
+		t.Error("expected ResumeParser to reject a tampered checkpoint")
+	}
+}
+
+func TestMergeResumedMatchDataSplitsOnResumeFromRound(t *testing.T) {
+	prior := &MatchData{
+		Rounds:    []RoundData{{RoundIndex: 0}, {RoundIndex: 1}},
+		Positions: []PlayerPositionData{{RoundIndex: 0, Tick: 1}, {RoundIndex: 1, Tick: 2}},
+	}
+	fresh := &MatchData{
+		Map:       "de_inferno",
+		Rounds:    []RoundData{{RoundIndex: 0}, {RoundIndex: 1}, {RoundIndex: 2}},
+		Positions: []PlayerPositionData{{RoundIndex: 0, Tick: 1}, {RoundIndex: 1, Tick: 2}, {RoundIndex: 2, Tick: 3}},
+	}
+
+	merged := mergeResumedMatchData(prior, fresh, 1)
+
+	if merged.Map != "de_inferno" {
+		t.Errorf("Map = %q, want %q", merged.Map, "de_inferno")
+	}
+	if len(merged.Rounds) != 3 {
+		t.Fatalf("len(Rounds) = %d, want 3", len(merged.Rounds))
+	}
+	if len(merged.Positions) != 3 {
+		t.Fatalf("len(Positions) = %d, want 3", len(merged.Positions))
+	}
+	for i, round := range merged.Rounds {
+		if round.RoundIndex != i {
+			t.Errorf("Rounds[%d].RoundIndex = %d, want %d", i, round.RoundIndex, i)
+		}
+	}
+}
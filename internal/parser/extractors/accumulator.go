@@ -0,0 +1,117 @@
+package extractors
+
+import "fmt"
+
+// DefaultAccumulatorWindowTicks is the tick window (~1 second at a 64 tick
+// server) within which consecutive hits between the same actor, victim and
+// weapon are coalesced into one AccumulatorEntry instead of producing one
+// Event per hit.
+const DefaultAccumulatorWindowTicks = 64
+
+// AccumulatorEntry is one in-progress coalesced event: every hit between
+// the same actor, victim and weapon, merged as long as consecutive hits
+// stay within the Accumulator's window of each other, until the window
+// lapses, the round ends, or a participant dies.
+type AccumulatorEntry struct {
+	RoundIndex    int
+	ActorSteamID  string
+	VictimSteamID string
+	Weapon        string
+	FirstTick     int
+	LastTick      int
+	HitCount      int
+
+	// Metrics sums named numeric totals across every merged hit (e.g.
+	// "total_damage", "total_blind_seconds"), for the caller's flush
+	// function to drop straight into MetaJSON.
+	Metrics map[string]float64
+}
+
+// Accumulator coalesces a stream of per-hit events keyed by (round, actor,
+// victim, weapon) into AccumulatorEntry values, merging consecutive hits
+// within windowTicks of each other and handing a finished entry to flush
+// once it closes (window expiry, FlushRound or FlushParticipant).
+// TeamDamageExtractor and TeamFlashExtractor each own one of these instead
+// of re-implementing pending-hit bookkeeping.
+type Accumulator struct {
+	windowTicks int
+	pending     map[string]*AccumulatorEntry
+	flush       func(*AccumulatorEntry)
+}
+
+// NewAccumulator creates an Accumulator that merges hits within windowTicks
+// of each other, calling flush(entry) once each coalesced entry closes.
+func NewAccumulator(windowTicks int, flush func(*AccumulatorEntry)) *Accumulator {
+	return &Accumulator{
+		windowTicks: windowTicks,
+		pending:     make(map[string]*AccumulatorEntry),
+		flush:       flush,
+	}
+}
+
+func accumulatorKey(round int, actor, victim, weapon string) string {
+	return fmt.Sprintf("%d_%s_%s_%s", round, actor, victim, weapon)
+}
+
+// Add records one hit at tick, merging it into the open entry for (round,
+// actor, victim, weapon) if tick falls within windowTicks of that entry's
+// last hit. Otherwise the old entry (if any) is flushed and a new one is
+// started - including on a weapon change mid-window, since weapon is part
+// of the key.
+func (a *Accumulator) Add(round int, actor, victim, weapon string, tick int, metrics map[string]float64) {
+	key := accumulatorKey(round, actor, victim, weapon)
+	entry, exists := a.pending[key]
+	if exists && tick-entry.LastTick <= a.windowTicks {
+		entry.LastTick = tick
+		entry.HitCount++
+		for name, value := range metrics {
+			entry.Metrics[name] += value
+		}
+		return
+	}
+
+	if exists {
+		a.flushEntry(key, entry)
+	}
+
+	merged := make(map[string]float64, len(metrics))
+	for name, value := range metrics {
+		merged[name] = value
+	}
+	a.pending[key] = &AccumulatorEntry{
+		RoundIndex:    round,
+		ActorSteamID:  actor,
+		VictimSteamID: victim,
+		Weapon:        weapon,
+		FirstTick:     tick,
+		LastTick:      tick,
+		HitCount:      1,
+		Metrics:       merged,
+	}
+}
+
+// FlushRound flushes every pending entry belonging to round, e.g. on round
+// end, when no more hits for that round can arrive.
+func (a *Accumulator) FlushRound(round int) {
+	for key, entry := range a.pending {
+		if entry.RoundIndex == round {
+			a.flushEntry(key, entry)
+		}
+	}
+}
+
+// FlushParticipant flushes every pending entry where steamID is the actor
+// or the victim, e.g. on that player's death - a dead player can neither
+// land nor receive any more hits to merge into it.
+func (a *Accumulator) FlushParticipant(steamID string) {
+	for key, entry := range a.pending {
+		if entry.ActorSteamID == steamID || entry.VictimSteamID == steamID {
+			a.flushEntry(key, entry)
+		}
+	}
+}
+
+func (a *Accumulator) flushEntry(key string, entry *AccumulatorEntry) {
+	a.flush(entry)
+	delete(a.pending, key)
+}
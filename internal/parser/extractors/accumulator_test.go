@@ -0,0 +1,95 @@
+package extractors
+
+import "testing"
+
+func TestAccumulatorMergesWithinWindow(t *testing.T) {
+	var flushed []*AccumulatorEntry
+	acc := NewAccumulator(64, func(e *AccumulatorEntry) { flushed = append(flushed, e) })
+
+	acc.Add(0, "1", "2", "ak47", 1000, map[string]float64{"total_damage": 20})
+	acc.Add(0, "1", "2", "ak47", 1050, map[string]float64{"total_damage": 15})
+	acc.FlushRound(0)
+
+	if len(flushed) != 1 {
+		t.Fatalf("expected 1 flushed entry, got %d", len(flushed))
+	}
+	if flushed[0].HitCount != 2 {
+		t.Errorf("expected hit_count 2, got %d", flushed[0].HitCount)
+	}
+	if flushed[0].Metrics["total_damage"] != 35 {
+		t.Errorf("expected total_damage 35, got %v", flushed[0].Metrics["total_damage"])
+	}
+	if flushed[0].FirstTick != 1000 || flushed[0].LastTick != 1050 {
+		t.Errorf("expected first/last tick 1000/1050, got %d/%d", flushed[0].FirstTick, flushed[0].LastTick)
+	}
+}
+
+func TestAccumulatorWindowRollover(t *testing.T) {
+	var flushed []*AccumulatorEntry
+	acc := NewAccumulator(64, func(e *AccumulatorEntry) { flushed = append(flushed, e) })
+
+	// Second hit lands 65 ticks after the first - just outside the window -
+	// so it should close out the first entry instead of merging into it.
+	acc.Add(0, "1", "2", "ak47", 1000, map[string]float64{"total_damage": 20})
+	acc.Add(0, "1", "2", "ak47", 1065, map[string]float64{"total_damage": 15})
+	acc.FlushRound(0)
+
+	if len(flushed) != 2 {
+		t.Fatalf("expected 2 flushed entries (window rollover), got %d", len(flushed))
+	}
+	if flushed[0].HitCount != 1 || flushed[1].HitCount != 1 {
+		t.Errorf("expected each flushed entry to have hit_count 1, got %d and %d", flushed[0].HitCount, flushed[1].HitCount)
+	}
+}
+
+func TestAccumulatorRoundBoundaryFlush(t *testing.T) {
+	var flushed []*AccumulatorEntry
+	acc := NewAccumulator(64, func(e *AccumulatorEntry) { flushed = append(flushed, e) })
+
+	acc.Add(0, "1", "2", "ak47", 1000, map[string]float64{"total_damage": 20})
+	acc.Add(1, "1", "2", "ak47", 1000, map[string]float64{"total_damage": 20})
+
+	acc.FlushRound(0)
+	if len(flushed) != 1 {
+		t.Fatalf("expected FlushRound(0) to flush only round 0's entry, got %d", len(flushed))
+	}
+
+	acc.FlushRound(1)
+	if len(flushed) != 2 {
+		t.Fatalf("expected FlushRound(1) to flush round 1's entry too, got %d", len(flushed))
+	}
+}
+
+func TestAccumulatorWeaponChangeMidWindow(t *testing.T) {
+	var flushed []*AccumulatorEntry
+	acc := NewAccumulator(64, func(e *AccumulatorEntry) { flushed = append(flushed, e) })
+
+	// Same actor/victim/round, but a different weapon is a different key,
+	// so it must not merge even though it's within the tick window.
+	acc.Add(0, "1", "2", "ak47", 1000, map[string]float64{"total_damage": 20})
+	acc.Add(0, "1", "2", "he", 1010, map[string]float64{"total_damage": 40})
+	acc.FlushRound(0)
+
+	if len(flushed) != 2 {
+		t.Fatalf("expected 2 flushed entries (weapon change), got %d", len(flushed))
+	}
+}
+
+func TestAccumulatorFlushParticipant(t *testing.T) {
+	var flushed []*AccumulatorEntry
+	acc := NewAccumulator(64, func(e *AccumulatorEntry) { flushed = append(flushed, e) })
+
+	acc.Add(0, "1", "2", "ak47", 1000, map[string]float64{"total_damage": 20})
+	acc.Add(0, "3", "4", "ak47", 1000, map[string]float64{"total_damage": 20})
+
+	// "2" dying should only close the entry it's the victim of.
+	acc.FlushParticipant("2")
+	if len(flushed) != 1 {
+		t.Fatalf("expected FlushParticipant to flush 1 entry, got %d", len(flushed))
+	}
+
+	acc.FlushRound(0)
+	if len(flushed) != 2 {
+		t.Fatalf("expected the remaining entry to flush at round end, got %d", len(flushed))
+	}
+}
@@ -6,37 +6,104 @@ import (
 	"fmt"
 	"math"
 
-	common "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/common"
+	"cs-griefer-electron/internal/db"
+
 	_ "modernc.org/sqlite" // Pure Go SQLite driver (no cgo required)
 )
 
 // AFKExtractor detects when players are AFK (stationary for extended periods).
 // It tracks player positions and detects when they haven't moved significantly.
 // AFK is only tracked after freeze time ends in each round.
+//
+// Position/death/disconnect lookups go through a PositionStore rather than
+// direct SQL: ProcessAFKFromDatabase used to issue several queries per
+// player per round (and HandleFreezeTimeEnd/getPlayerPositionFromDB one
+// query per tick), which dominated post-parse detection time on long
+// demos. The store lazily loads a match's rows once and serves every later
+// lookup from memory instead.
 type AFKExtractor struct {
-	playerStates       map[string]*playerAFKState // key: roundIndex_steamID
-	freezeEndTicks     map[int]int                // roundIndex -> freeze end tick
-	roundEndTicks      map[int]int                // roundIndex -> round end tick
-	lastDiscoveryTick  map[string]int             // key: roundIndex_tick -> last discovery tick
-	tickRate           float64
-	minAFKSeconds      float64
-	events             []Event
-	db                 *sql.DB
+	playerStates   map[string]*playerAFKState // key: roundIndex_steamID
+	freezeEndTicks map[int]int                // roundIndex -> freeze end tick
+	roundEndTicks  map[int]int                // roundIndex -> round end tick
+	tickRate       float64
+	minAFKSeconds  float64
+	events         []Event
+	matchID        string
+	store          PositionStore
+
+	// Loitering detection: a second AFK trigger alongside plain stillness,
+	// for players who nudge their mouse or wiggle in place to defeat the
+	// movementThreshold check. loiterEnabled gates it independently so a
+	// caller can fall back to pure stillness detection if it proves too
+	// aggressive on a given server's movement noise.
+	loiterEnabled       bool
+	loiterRadius        float64
+	loiterWindowSeconds float64
+
+	// episodes records every finalized AFK interval (however it ended) per
+	// round per player, so GetRoundAFKSummary can report effective-playtime
+	// stats instead of a binary AFK flag: roundIndex -> steamID -> episodes.
+	episodes map[int]map[string][]afkEpisode
+
+	// activity records non-positional signs of life (shots fired, utility
+	// thrown, bomb plant/defuse, damage dealt/received) fed in via
+	// RegisterActivity by the extractors that already detect those events.
+	// A stationary player holding an angle or defusing the bomb looks
+	// identical to AFK under pure movement-threshold detection; a recent
+	// entry here cancels AFK tracking the same way movement does.
+	activity map[string][]activityRecord
+}
+
+// ActivityKind identifies the kind of non-positional activity that can
+// cancel AFK tracking for an otherwise-stationary player. See RegisterActivity.
+type ActivityKind string
+
+const (
+	ActivityShotFired      ActivityKind = "shot_fired"
+	ActivityGrenadeThrown  ActivityKind = "grenade_thrown"
+	ActivityBombPlant      ActivityKind = "bomb_plant"
+	ActivityBombDefuse     ActivityKind = "bomb_defuse"
+	ActivityDamageDealt    ActivityKind = "damage_dealt"
+	ActivityDamageReceived ActivityKind = "damage_received"
+)
+
+type activityRecord struct {
+	tick int
+	kind ActivityKind
+}
+
+// afkEpisode is one finalized AFK interval for a single player within a
+// round. The round-start-only detector below can only ever produce one of
+// these per player per round today, but the shape already supports more,
+// should a future detector add mid-round AFK tracking.
+type afkEpisode struct {
+	StartTick       int
+	EndTick         int
+	DurationSeconds float64
+}
+
+// AFKRoundSummary aggregates one player's AFK episodes within a round, for
+// consumers building per-round "effective playtime" stats rather than
+// reading a binary AFK flag off individual events.
+type AFKRoundSummary struct {
+	TotalAFKSeconds    float64
+	EpisodeCount       int
+	LongestEpisodeSecs float64
 }
 
 type playerAFKState struct {
-	roundIndex           int
-	steamID              string
-	initialPosition      *position // Position at freeze time end
-	lastPosition         *position
-	lastMoveTick         int
-	gracePeriodEndTick   int           // End of 5-second grace period
-	movedDuringGrace     bool          // Whether player moved during grace period
-	afkStartTick         *int          // When AFK period started (only if not moved during grace)
-	firstMovementTick    *int          // When player first moved (if they moved)
-	deathTick            *int          // When player died (if they died)
-	minAFKSeconds        float64       // Minimum seconds to be considered AFK (e.g., 5 seconds)
-	movementThreshold    float64       // Minimum distance to consider as movement (2-5 units)
+	roundIndex         int
+	steamID            string
+	initialPosition    *position // Position at freeze time end
+	lastPosition       *position
+	lastMoveTick       int
+	gracePeriodEndTick int     // End of 5-second grace period
+	movedDuringGrace   bool    // Whether player moved during grace period
+	afkStartTick       *int    // When AFK period started (only if not moved during grace)
+	firstMovementTick  *int    // When player first moved (if they moved)
+	deathTick          *int    // When player died (if they died)
+	minAFKSeconds      float64 // Minimum seconds to be considered AFK (e.g., 5 seconds)
+	movementThreshold  float64 // Minimum distance to consider as movement (2-5 units)
 }
 
 type position struct {
@@ -46,18 +113,72 @@ type position struct {
 // Position is exported for use by parser
 type Position = position
 
-// NewAFKExtractor creates a new AFK extractor.
-func NewAFKExtractor(tickRate float64, db *sql.DB) *AFKExtractor {
+// NewAFKExtractor creates a new AFK extractor scoped to matchID. db is
+// wrapped in a MemPositionStore that lazily loads matchID's rows on first
+// use; db may be nil in tests that only call ProcessAFKFromDatabase's
+// helpers against a store populated via SetPositionStore.
+func NewAFKExtractor(tickRate float64, db *sql.DB, matchID string) *AFKExtractor {
 	return &AFKExtractor{
-		playerStates:      make(map[string]*playerAFKState),
-		freezeEndTicks:    make(map[int]int),
-		roundEndTicks:     make(map[int]int),
-		lastDiscoveryTick: make(map[string]int),
-		tickRate:          tickRate,
-		minAFKSeconds:     5.0, // 5 seconds of no movement = AFK
-		events:            make([]Event, 0),
-		db:                db,
+		playerStates:        make(map[string]*playerAFKState),
+		freezeEndTicks:      make(map[int]int),
+		roundEndTicks:       make(map[int]int),
+		tickRate:            tickRate,
+		minAFKSeconds:       5.0, // 5 seconds of no movement = AFK
+		events:              make([]Event, 0),
+		matchID:             matchID,
+		store:               NewMemPositionStore(db),
+		loiterEnabled:       true,
+		loiterRadius:        50.0,
+		loiterWindowSeconds: 10.0,
+		episodes:            make(map[int]map[string][]afkEpisode),
+		activity:            make(map[string][]activityRecord),
+	}
+}
+
+// RegisterActivity records that steamID performed a non-positional action
+// (shot fired, grenade thrown, bomb plant/defuse, damage dealt/received) at
+// tick. ProcessAFKFromDatabase/ProcessAFKFromPositions check this alongside
+// movement before treating a stationary player as AFK - holding an AWP
+// angle while firing, or defusing the bomb, shouldn't count.
+func (e *AFKExtractor) RegisterActivity(steamID string, tick int, kind ActivityKind) {
+	e.activity[steamID] = append(e.activity[steamID], activityRecord{tick: tick, kind: kind})
+}
+
+// lastActivityWithin returns the most recent activity steamID performed at
+// or before tick, if it happened recently enough (within minAFKSeconds) to
+// cancel AFK tracking.
+func (e *AFKExtractor) lastActivityWithin(steamID string, tick int) (ActivityKind, bool) {
+	var latest *activityRecord
+	for i := range e.activity[steamID] {
+		rec := e.activity[steamID][i]
+		if rec.tick > tick {
+			continue
+		}
+		if latest == nil || rec.tick > latest.tick {
+			latest = &rec
+		}
+	}
+	if latest == nil || float64(tick-latest.tick)/e.tickRate > e.minAFKSeconds {
+		return "", false
 	}
+	return latest.kind, true
+}
+
+// SetPositionStore overrides the extractor's PositionStore, e.g. to inject a
+// fixture store in tests.
+func (e *AFKExtractor) SetPositionStore(store PositionStore) {
+	e.store = store
+}
+
+// SetLoiterDetection configures the loitering detector: enabled gates
+// whether it runs at all, radius is the max bounding-box spread (units)
+// a player's position may wander within window while still counting as
+// loitering rather than genuine movement, and window is the trailing
+// duration (seconds) that spread is measured over.
+func (e *AFKExtractor) SetLoiterDetection(enabled bool, radius float64, window float64) {
+	e.loiterEnabled = enabled
+	e.loiterRadius = radius
+	e.loiterWindowSeconds = window
 }
 
 // HandlePlayerPositionUpdate should be called periodically to check player positions.
@@ -79,14 +200,14 @@ func (e *AFKExtractor) HandleRoundStart(roundIndex int, tick int) {
 	for _, key := range keysToDelete {
 		delete(e.playerStates, key)
 	}
-	
+
 	// Clear freeze end tick for previous rounds
 	for rIdx := range e.freezeEndTicks {
 		if rIdx < roundIndex {
 			delete(e.freezeEndTicks, rIdx)
 		}
 	}
-	
+
 	// Clear round end tick for previous rounds
 	for rIdx := range e.roundEndTicks {
 		if rIdx < roundIndex {
@@ -104,45 +225,33 @@ func (e *AFKExtractor) HandleRoundEnd(roundIndex int, roundEndTick int) {
 // AFK tracking will only start after this tick.
 func (e *AFKExtractor) HandleFreezeTimeEnd(roundIndex int, freezeEndTick int) {
 	e.freezeEndTicks[roundIndex] = freezeEndTick
-	
+
 	// Query all player positions from the database at freeze time end
 	// to initialize AFK tracking for all players
 	e.initializePlayersFromDatabase(roundIndex, freezeEndTick)
 }
 
-// initializePlayersFromDatabase queries the database for all unique players in a round.
-// Instead of just querying at freeze end, we get all unique steamids that appear in the round
-// and initialize them with their position at freeze end (or their first position if not at freeze end).
+// initializePlayersFromDatabase loads e.matchID's positions into the store
+// (a no-op once already loaded) and initializes AFK state for every player
+// with a recorded position in roundIndex, using their position at freeze
+// end. Positions that arrive for a new match before the store has ever seen
+// it fall through to CheckAllPlayersAFK the same way a missing freeze-end
+// sample always has.
 func (e *AFKExtractor) initializePlayersFromDatabase(roundIndex int, freezeEndTick int) {
-	// Query all DISTINCT steamids that appear in this round
-	// We need to check player_positions table for positions in this round/match
-	query := "SELECT DISTINCT steamid FROM player_positions WHERE tick >= ? ORDER BY steamid"
-	rows, err := e.db.Query(query, freezeEndTick)
-	if err != nil {
-		// Silently skip if query fails - database might not have data yet
+	if err := e.store.LoadMatch(e.matchID); err != nil {
 		return
 	}
-	defer rows.Close()
 
 	// Grace period: 5 seconds after freeze time
 	gracePeriodSeconds := 5.0
 	gracePeriodTicks := int(math.Ceil(gracePeriodSeconds * e.tickRate))
 	gracePeriodEndTick := freezeEndTick + gracePeriodTicks
 
-	playerCount := 0
-	for rows.Next() {
-		var steamID string
-		if err := rows.Scan(&steamID); err != nil {
-			continue // Skip rows that can't be scanned
-		}
-
+	for _, steamID := range e.store.PlayersInRound(e.matchID, roundIndex) {
 		// Get this player's position at freeze end if available, otherwise skip for now
 		// They'll be initialized when first seen in CheckAllPlayersAFK
-		pos, err := e.getPlayerPositionFromDB(steamID, freezeEndTick)
-		if err != nil {
-			continue
-		}
-		if pos == nil {
+		pos, ok := e.getPlayerPositionFromDB(roundIndex, steamID, freezeEndTick)
+		if !ok {
 			// Player doesn't have position at freeze end - will initialize on first check
 			continue
 		}
@@ -162,449 +271,305 @@ func (e *AFKExtractor) initializePlayersFromDatabase(roundIndex int, freezeEndTi
 			minAFKSeconds:      5.0,
 			movementThreshold:  3.0, // 2-5 units to ignore jitter
 		}
-		playerCount++
 	}
 }
 
-// getPlayerPositionFromDB queries the player's position from the database for a given tick.
-func (e *AFKExtractor) getPlayerPositionFromDB(steamID string, tick int) (*position, error) {
-	query := "SELECT x, y, z FROM player_positions WHERE steamid = ? AND tick = ?"
-	row := e.db.QueryRow(query, steamID, tick)
+// getPlayerPositionFromDB returns steamID's position at exactly tick in
+// roundIndex from e.store, scoped to e.matchID.
+func (e *AFKExtractor) getPlayerPositionFromDB(roundIndex int, steamID string, tick int) (*position, bool) {
+	pos, ok := e.store.PositionAt(e.matchID, roundIndex, steamID, tick)
+	if !ok {
+		return nil, false
+	}
+	return &pos, true
+}
 
-	var x, y, z float64
-	if err := row.Scan(&x, &y, &z); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // No position found for this tick
-		}
-		return nil, err
+// ProcessAFKFromDatabase processes AFK detection for a match by replaying
+// its positions, deaths and disconnects through an ExtractorLoop. This
+// implements the "AFK at round start" detector:
+// - 5 second grace window starting at freezeTimeEnd (roundStart)
+// - If player moves during grace: NOT_AFK
+// - If player doesn't move during grace: AFK starts at roundStart (not after grace ends)
+// - AFK continues until: move, die, or round end
+// - Only tracks round-start AFK (no mid-round AFK intervals)
+func (e *AFKExtractor) ProcessAFKFromDatabase(matchID string, roundIndex int, freezeEndTick int, roundEndTick int) error {
+	e.roundEndTicks[roundIndex] = roundEndTick
+
+	if err := e.store.LoadMatch(matchID); err != nil {
+		return fmt.Errorf("failed to load positions: %w", err)
 	}
 
-	return &position{X: x, Y: y, Z: z}, nil
+	loop := NewExtractorLoop(newAFKTickHandler(e))
+	loop.Run(e.store, matchID, roundIndex, e.tickRate, freezeEndTick, roundEndTick)
+	return nil
 }
 
-// UpdatePlayerPosition updates a player's position and checks for AFK.
-// AFK tracking only starts after freeze time ends.
-func (e *AFKExtractor) UpdatePlayerPosition(player *common.Player, roundIndex int, tick int) {
-	if player == nil {
-		return
-	}
+// ProcessAFKFromPositions is a thin convenience wrapper around
+// ProcessAFKFromDatabase for a caller that already has a round's positions
+// in memory - e.g. a live parser streaming frames as the demo is read -
+// and wants AFK detection without round-tripping through SQLite at all. It
+// populates a throwaway fixture MemPositionStore from positions (ignoring
+// any outside roundIndex) and replays it exactly like ProcessAFKFromDatabase,
+// leaving e.store and anything it's already loaded untouched.
+//
+// positions carries only coordinates, so death/disconnect-aware behavior
+// (diedWhileAFK, disconnect carry-forward) doesn't apply on this path - a
+// caller that needs those should go through ProcessAFKFromDatabase, or
+// populate a store's AddDeath/AddDisconnect directly before calling Run.
+func (e *AFKExtractor) ProcessAFKFromPositions(matchID string, roundIndex int, freezeEndTick int, roundEndTick int, positions []db.PlayerPosition) error {
+	e.roundEndTicks[roundIndex] = roundEndTick
 
-	// Check if freeze time has ended for this round
-	// If freeze time hasn't ended yet, don't track AFK
-	freezeEndTick, freezeTimeEnded := e.freezeEndTicks[roundIndex]
-	if !freezeTimeEnded || tick < freezeEndTick {
-		return
+	store := NewMemPositionStore(nil)
+	for _, p := range positions {
+		if p.RoundIndex != roundIndex {
+			continue
+		}
+		store.AddPosition(matchID, roundIndex, p.SteamID, p.Tick, position{X: p.X, Y: p.Y, Z: p.Z})
 	}
 
-	steamID := getSteamID(player)
-	if steamID == nil {
-		return
-	}
+	loop := NewExtractorLoop(newAFKTickHandler(e))
+	loop.Run(store, matchID, roundIndex, e.tickRate, freezeEndTick, roundEndTick)
+	return nil
+}
 
-	key := fmt.Sprintf("%d_%s", roundIndex, *steamID)
-	state, exists := e.playerStates[key]
+// afkTickHandler is the TickHandler that implements round-start AFK
+// detection on top of ExtractorLoop: the grace-period/movement/death/
+// disconnect state machine that used to live directly inside
+// ProcessAFKFromDatabase's own tick-by-tick loop.
+type afkTickHandler struct {
+	extractor *AFKExtractor
+
+	lastPosition      map[string]position
+	movedDuringGrace  map[string]bool
+	afkStartTick      map[string]*int
+	firstMovementTick map[string]*int
+	reason            map[string]string // steamID -> detectionReasonNoMovement/Loitering for the in-progress AFK run
+
+	windowStart map[string]int       // steamID -> tick of its first recorded sample, for loiterWindowSeconds coverage
+	window      map[string][]tickPos // steamID -> samples within the trailing loiterWindowSeconds
+
+	suspected   map[string]bool // steamID -> AFK_SUSPECTED already fired for the in-progress episode
+	confirmed   map[string]bool // steamID -> AFK_CONFIRMED already fired for the in-progress episode
+	lastAFKTick map[string]int  // steamID -> last tick observed still-AFK during the in-progress episode
+}
 
-	// Fetch position from the database
-	currentPos, err := e.getPlayerPositionFromDB(*steamID, tick)
-	if err != nil {
-		return // Skip if position query fails
-	}
-	if currentPos == nil {
-		return // No position data available
+func newAFKTickHandler(extractor *AFKExtractor) *afkTickHandler {
+	return &afkTickHandler{
+		extractor:         extractor,
+		lastPosition:      make(map[string]position),
+		movedDuringGrace:  make(map[string]bool),
+		afkStartTick:      make(map[string]*int),
+		firstMovementTick: make(map[string]*int),
+		reason:            make(map[string]string),
+		windowStart:       make(map[string]int),
+		window:            make(map[string][]tickPos),
+		suspected:         make(map[string]bool),
+		confirmed:         make(map[string]bool),
+		lastAFKTick:       make(map[string]int),
 	}
+}
 
-	if !exists {
-		// New player state - initialize after freeze time
-		// Get grace period end tick for this round
-		gracePeriodEndTick := 0
-		if freezeEndTick, exists := e.freezeEndTicks[roundIndex]; exists {
-			gracePeriodSeconds := 5.0
-			gracePeriodTicks := int(math.Ceil(gracePeriodSeconds * e.tickRate))
-			gracePeriodEndTick = freezeEndTick + gracePeriodTicks
-		}
-		
-		e.playerStates[key] = &playerAFKState{
-			roundIndex:         roundIndex,
-			steamID:            *steamID,
-			lastPosition:       currentPos,
-			lastMoveTick:        tick,
-			gracePeriodEndTick:  gracePeriodEndTick,
-			movedDuringGrace:   tick > gracePeriodEndTick, // If discovered after grace period, they implicitly "moved"
-			afkStartTick:       nil,
-			firstMovementTick:  nil,
-			deathTick:          nil,
-			minAFKSeconds:      5.0,
-			movementThreshold:  3.0,
-		}
+// checkLifecycle emits AFK_SUSPECTED and/or AFK_CONFIRMED the first time
+// steamID's in-progress episode (still AFK as of tick) crosses 50%/100% of
+// minAFKSeconds, and records tick as the last tick it was seen still AFK so
+// a later AFK_RETURNED can report the episode's true extent.
+func (h *afkTickHandler) checkLifecycle(ctx *RoundContext, steamID string, tick int) {
+	afkStart := h.afkStartTick[steamID]
+	if afkStart == nil {
 		return
 	}
+	h.lastAFKTick[steamID] = tick
 
-	// Check if player has moved significantly (use movement threshold)
-	dx := currentPos.X - state.lastPosition.X
-	dy := currentPos.Y - state.lastPosition.Y
-	dz := currentPos.Z - state.lastPosition.Z
-	distance := math.Sqrt(dx*dx + dy*dy + dz*dz)
-
-	if distance > state.movementThreshold {
-		// Player moved - cancel AFK tracking
-		// Note: With new implementation, this is handled in ProcessAFKFromDatabase
-		state.lastPosition = currentPos
-		state.lastMoveTick = tick
-		state.afkStartTick = nil
-	} else {
-		// Player hasn't moved much
-		if state.afkStartTick == nil {
-			// Start tracking AFK period (only after freeze time)
-			state.afkStartTick = &tick
-		} else {
-			// Check if AFK period is long enough
-			afkTicks := tick - *state.afkStartTick
-			afkSeconds := float64(afkTicks) / e.tickRate
-
-			if afkSeconds >= e.minAFKSeconds {
-				// Player has been AFK long enough - we'll finalize on next move or round end
-				state.lastPosition = currentPos
-			}
-		}
+	elapsedSeconds := float64(tick-*afkStart) / ctx.TickRate
+	minSeconds := h.extractor.minAFKSeconds
+	if !h.suspected[steamID] && elapsedSeconds >= minSeconds*0.5 {
+		h.suspected[steamID] = true
+		h.extractor.createAFKLifecycleEvent("AFK_SUSPECTED", ctx.RoundIndex, steamID, *afkStart, tick)
 	}
-}
-
-// ProcessAFKFromDatabase processes AFK detection for a match by querying positions from the database.
-// This implements the "AFK at round start" detector based on the new requirements:
-// - 5 second grace window starting at freezeTimeEnd (roundStart)
-// - If player moves during grace: NOT_AFK
-// - If player doesn't move during grace: AFK starts at roundStart (not after grace ends)
-// - AFK continues until: move, die, or round end
-// - Only tracks round-start AFK (no mid-round AFK intervals)
-func (e *AFKExtractor) ProcessAFKFromDatabase(matchID string, roundIndex int, freezeEndTick int, roundEndTick int) error {
-	// Record round end tick
-	e.roundEndTicks[roundIndex] = roundEndTick
-	
-	// Define grace period: 5 seconds starting at freezeEndTick (roundStart)
-	gracePeriodSeconds := 5.0
-	gracePeriodTicks := int(math.Ceil(gracePeriodSeconds * e.tickRate))
-	gracePeriodEndTick := freezeEndTick + gracePeriodTicks
-	
-	// Movement threshold: 2-5 units (using 3.0 as middle ground)
-	moveEps := 3.0
-
-	// Query disconnect events for this round and previous rounds FIRST
-	// Players who disconnect should not be tracked for AFK
-	// We need to check disconnects from previous rounds too, as they might still be disconnected
-	disconnectQuery := `
-		SELECT actor_steamid, start_tick, end_tick, round_index
-		FROM events
-		WHERE match_id = ? AND type = 'DISCONNECT' AND actor_steamid IS NOT NULL
-		ORDER BY start_tick
-	`
-	disconnectRows, err := e.db.Query(disconnectQuery, matchID)
-	disconnectIntervals := make(map[string][]struct{ start, end int }) // steamID -> list of [start, end] intervals
-	if err == nil {
-		defer disconnectRows.Close()
-		for disconnectRows.Next() {
-			var steamID string
-			var startTick int
-			var endTick sql.NullInt64
-			var eventRoundIndex int
-			if err := disconnectRows.Scan(&steamID, &startTick, &endTick, &eventRoundIndex); err == nil {
-				// Only consider disconnects that are relevant to this round
-				// If disconnect happened before this round and no reconnect, they're still disconnected
-				// If disconnect happened during this round, include it
-				if eventRoundIndex < roundIndex {
-					// Disconnect from previous round - if no reconnect, they're still disconnected
-					if !endTick.Valid {
-						// No reconnect, still disconnected - mark as disconnected from round start
-						disconnectIntervals[steamID] = append(disconnectIntervals[steamID], struct{ start, end int }{start: freezeEndTick, end: roundEndTick})
-					}
-				} else if eventRoundIndex == roundIndex {
-					// Disconnect during this round
-					disconnectEnd := roundEndTick // Default to round end if no reconnect
-					if endTick.Valid {
-						disconnectEnd = int(endTick.Int64)
-					}
-					disconnectIntervals[steamID] = append(disconnectIntervals[steamID], struct{ start, end int }{start: startTick, end: disconnectEnd})
-				}
-			}
-		}
+	if !h.confirmed[steamID] && elapsedSeconds >= minSeconds {
+		h.confirmed[steamID] = true
+		h.extractor.createAFKLifecycleEvent("AFK_CONFIRMED", ctx.RoundIndex, steamID, *afkStart, tick)
 	}
+}
 
-	// Query death events for this round
-	deathQuery := `
-		SELECT DISTINCT victim_steamid, start_tick
-		FROM events
-		WHERE match_id = ? AND round_index = ? AND victim_steamid IS NOT NULL
-		ORDER BY start_tick
-	`
-	deathRows, err := e.db.Query(deathQuery, matchID, roundIndex)
-	deathTicks := make(map[string]int) // steamID -> death tick
-	if err == nil {
-		defer deathRows.Close()
-		for deathRows.Next() {
-			var steamID string
-			var deathTick int
-			if err := deathRows.Scan(&steamID, &deathTick); err == nil {
-				if existingTick, exists := deathTicks[steamID]; !exists || deathTick < existingTick {
-					deathTicks[steamID] = deathTick
-				}
-			}
-		}
-	}
+// resetLifecycle clears steamID's in-progress-episode lifecycle state once
+// that episode ends (by movement), so a later mid-round episode - should a
+// future detector add one - starts its own suspected/confirmed sequence.
+func (h *afkTickHandler) resetLifecycle(steamID string) {
+	delete(h.suspected, steamID)
+	delete(h.confirmed, steamID)
+	delete(h.lastAFKTick, steamID)
+}
 
-	// Helper function to check if player is disconnected or dead at a given tick
-	isPlayerDisconnectedOrDead := func(steamID string, tick int) bool {
-		// Check if player is dead at this tick
-		if deathTick, isDead := deathTicks[steamID]; isDead && tick >= deathTick {
-			return true
-		}
-		// Check if player is disconnected at this tick
-		if intervals, isDisconnected := disconnectIntervals[steamID]; isDisconnected {
-			for _, interval := range intervals {
-				if tick >= interval.start && tick <= interval.end {
-					return true
-				}
-			}
-		}
-		return false
-	}
+// moveEps is the movement threshold (2-5 units) used to ignore position
+// jitter when deciding whether a player has moved.
+const moveEps = 3.0
+
+// detectionReasonNoMovement and detectionReasonLoitering are the values
+// AFK_STILLNESS's detectionMethod metadata field takes, distinguishing
+// "stood still" from "wiggled in place to dodge the movement check" -
+// unrelated to the reason/reason_code ReasonCode vocabulary, which buckets
+// the finished interval by duration instead of by what triggered it.
+const (
+	detectionReasonNoMovement = "no_movement"
+	detectionReasonLoitering  = "loitering"
+)
 
-	// Get all unique players for this round
-	query := `
-		SELECT DISTINCT steamid
-		FROM player_positions
-		WHERE match_id = ? AND round_index = ?
-		ORDER BY steamid
-	`
-	rows, err := e.db.Query(query, matchID, roundIndex)
-	if err != nil {
-		return fmt.Errorf("failed to query players: %w", err)
-	}
-	defer rows.Close()
-
-	// Initialize player states - track movement during grace period
-	// Local type for tracking AFK state during processing
-	type afkPlayerState struct {
-		steamID           string
-		lastPosition      *position
-		lastPositionTick  int
-		movedDuringGrace  bool
-		afkStartTick      *int // nil if NOT_AFK, set to freezeEndTick if AFK
-		deathTick         *int
-		firstMovementTick *int
-	}
-	
-	playerStates := make(map[string]*afkPlayerState)
-
-	for rows.Next() {
-		var steamID string
-		if err := rows.Scan(&steamID); err != nil {
-			continue
-		}
+// tickPos is one sample in afkTickHandler's per-player loitering window.
+type tickPos struct {
+	tick int
+	pos  position
+}
 
-		// Skip if player is disconnected at round start - don't track AFK for disconnected players
-		if isPlayerDisconnectedOrDead(steamID, freezeEndTick) {
-			continue
-		}
+// checkLoitering appends sample to steamID's sliding window (pruning
+// anything older than loiterWindowSeconds) and reports whether the window
+// already covers the full configured duration and the player's bounding
+// box across it stays within loiterRadius - i.e. they've been wiggling in
+// a small area rather than actually repositioning, even on ticks where
+// their instantaneous distance exceeds moveEps.
+func (h *afkTickHandler) checkLoitering(ctx *RoundContext, steamID string, tick int, pos position) bool {
+	if _, ok := h.windowStart[steamID]; !ok {
+		h.windowStart[steamID] = tick
+	}
 
-		// Get position at freeze end (roundStart)
-		queryPos := `
-			SELECT x, y, z
-			FROM player_positions
-			WHERE match_id = ? AND round_index = ? AND steamid = ? AND tick = ?
-		`
-		var x, y, z float64
-		row := e.db.QueryRow(queryPos, matchID, roundIndex, steamID, freezeEndTick)
-		if err := row.Scan(&x, &y, &z); err != nil {
-			// Try to get first available position after freeze end
-			queryFirst := `
-				SELECT tick, x, y, z
-				FROM player_positions
-				WHERE match_id = ? AND round_index = ? AND steamid = ? AND tick >= ?
-				ORDER BY tick ASC
-				LIMIT 1
-			`
-			var firstTick int
-			rowFirst := e.db.QueryRow(queryFirst, matchID, roundIndex, steamID, freezeEndTick)
-			if err := rowFirst.Scan(&firstTick, &x, &y, &z); err != nil {
-				continue // Skip if no position data
-			}
-			// If first position is after grace period, player might still be AFK
-			// We'll initialize with this position and check if they move
-		}
+	windowTicks := int(h.extractor.loiterWindowSeconds * ctx.TickRate)
+	cutoff := tick - windowTicks
 
-		playerStates[steamID] = &afkPlayerState{
-			steamID:           steamID,
-			lastPosition:      &position{X: x, Y: y, Z: z},
-			lastPositionTick:  freezeEndTick,
-			movedDuringGrace:  false,
-			afkStartTick:      nil, // Will be set if no movement during grace
-			deathTick:         nil,
-			firstMovementTick: nil,
-		}
+	samples := append(h.window[steamID], tickPos{tick: tick, pos: pos})
+	start := 0
+	for start < len(samples) && samples[start].tick < cutoff {
+		start++
 	}
+	samples = samples[start:]
+	h.window[steamID] = samples
 
-	// Update death ticks in player states
-	for steamID, deathTick := range deathTicks {
-		if state, exists := playerStates[steamID]; exists {
-			if state.deathTick == nil || deathTick < *state.deathTick {
-				state.deathTick = &deathTick
-			}
-		}
+	if tick-h.windowStart[steamID] < windowTicks {
+		return false // not enough history yet to judge a sustained loiter
 	}
 
-	// Query all positions for this round, ordered by tick
-	posQuery := `
-		SELECT steamid, tick, x, y, z
-		FROM player_positions
-		WHERE match_id = ? AND round_index = ? AND tick >= ? AND tick <= ?
-		ORDER BY tick ASC
-	`
-	posRows, err := e.db.Query(posQuery, matchID, roundIndex, freezeEndTick, roundEndTick)
-	if err != nil {
-		return fmt.Errorf("failed to query positions: %w", err)
-	}
-	defer posRows.Close()
-
-	// Process positions tick by tick
-	for posRows.Next() {
-		var steamID string
-		var tick int
-		var x, y, z float64
-		if err := posRows.Scan(&steamID, &tick, &x, &y, &z); err != nil {
-			continue
-		}
+	minX, maxX := samples[0].pos.X, samples[0].pos.X
+	minY, maxY := samples[0].pos.Y, samples[0].pos.Y
+	minZ, maxZ := samples[0].pos.Z, samples[0].pos.Z
+	for _, s := range samples[1:] {
+		minX, maxX = math.Min(minX, s.pos.X), math.Max(maxX, s.pos.X)
+		minY, maxY = math.Min(minY, s.pos.Y), math.Max(maxY, s.pos.Y)
+		minZ, maxZ = math.Min(minZ, s.pos.Z), math.Max(maxZ, s.pos.Z)
+	}
+	spread := math.Max(maxX-minX, math.Max(maxY-minY, maxZ-minZ))
+	return spread <= h.extractor.loiterRadius
+}
 
-		// Skip AFK tracking if player is disconnected or dead at this tick
-		// But still process their position to update state
-		isDisconnectedOrDead := isPlayerDisconnectedOrDead(steamID, tick)
+func (h *afkTickHandler) OnFreezeEnd(ctx *RoundContext) {}
 
-		// Skip if player is disconnected or dead at this tick - don't track AFK for disconnected players
-		if isPlayerDisconnectedOrDead(steamID, tick) {
+func (h *afkTickHandler) OnTick(ctx *RoundContext, tick int, positions []PositionSample) {
+	for _, sample := range positions {
+		steamID := sample.SteamID
+		if !ctx.IsAlive(steamID, tick) || !ctx.IsConnected(steamID) {
 			continue
 		}
 
-		state, exists := playerStates[steamID]
-		if !exists {
-			// Skip if player is disconnected at round start - don't track AFK for disconnected players
-			if isPlayerDisconnectedOrDead(steamID, freezeEndTick) {
-				continue
-			}
-
-			// Initialize player on the fly if we encounter them
-			state = &afkPlayerState{
-				steamID:           steamID,
-				lastPosition:      &position{X: x, Y: y, Z: z},
-				lastPositionTick:  tick,
-				movedDuringGrace:  false,
-				afkStartTick:      nil,
-				deathTick:         nil,
-				firstMovementTick: nil,
+		loitering := h.extractor.loiterEnabled && h.checkLoitering(ctx, steamID, tick, sample.Pos)
+		activityKind, activeRecently := h.extractor.lastActivityWithin(steamID, tick)
+
+		last, seen := h.lastPosition[steamID]
+		h.lastPosition[steamID] = sample.Pos
+		if !seen {
+			// First surviving sample for this player - if it falls inside
+			// the grace window, they could be AFK from round start (unless
+			// they're already mid-activity, e.g. holding an angle and
+			// firing since before the sample stream starts).
+			if tick <= ctx.GracePeriodEndTick && !activeRecently {
+				afkStart := ctx.FreezeEndTick
+				h.afkStartTick[steamID] = &afkStart
+				h.reason[steamID] = detectionReasonNoMovement
+				h.checkLifecycle(ctx, steamID, tick)
 			}
-			playerStates[steamID] = state
-			
-			// If player first appears during grace period, they could be AFK
-			// If they appear after grace period, they're not considered for round-start AFK
-			// Only start AFK tracking if they're not disconnected/dead at round start
-			if tick <= gracePeriodEndTick && !isPlayerDisconnectedOrDead(steamID, freezeEndTick) {
-				// Player was present during grace - AFK starts at roundStart if no movement
-				afkStart := freezeEndTick
-				state.afkStartTick = &afkStart
-			}
-		}
-
-		currentPos := &position{X: x, Y: y, Z: z}
-
-		// If player is disconnected or dead, cancel any active AFK tracking
-		if isDisconnectedOrDead {
-			if state.afkStartTick != nil {
-				// If they died, finalize with DIED status
-				if state.deathTick != nil && *state.deathTick == tick {
-					e.createAFKEvent(matchID, roundIndex, state.steamID, *state.afkStartTick, tick, "DIED", true, state.firstMovementTick)
-				}
-				// Cancel AFK tracking (disconnected or dead)
-				state.afkStartTick = nil
-			}
-			// Update position but skip AFK tracking
-			state.lastPosition = currentPos
-			state.lastPositionTick = tick
 			continue
 		}
 
-		// Check if player has moved significantly
-		dx := currentPos.X - state.lastPosition.X
-		dy := currentPos.Y - state.lastPosition.Y
-		dz := currentPos.Z - state.lastPosition.Z
+		dx := sample.Pos.X - last.X
+		dy := sample.Pos.Y - last.Y
+		dz := sample.Pos.Z - last.Z
 		distance := math.Sqrt(dx*dx + dy*dy + dz*dz)
+		moved := distance > moveEps && !loitering
 
-		if distance > moveEps {
-			// Player moved significantly
-			if state.firstMovementTick == nil {
-				state.firstMovementTick = &tick
-			}
-
-			// If during grace period, mark that they moved (they're NOT_AFK)
-			if tick < gracePeriodEndTick {
-				state.movedDuringGrace = true
-				// Cancel AFK tracking - player moved during grace
-				state.afkStartTick = nil
-			} else if state.afkStartTick != nil {
-				// Player moved after grace period, ending AFK
-				// Finalize AFK interval (ended by movement)
-				e.createAFKEvent(matchID, roundIndex, state.steamID, *state.afkStartTick, tick, "MOVED", false, state.firstMovementTick)
-				state.afkStartTick = nil
-			}
-
-			state.lastPosition = currentPos
-			state.lastPositionTick = tick
-		} else {
-			// Player hasn't moved significantly
-			
-			// If they moved during grace period, they're NOT_AFK - skip
-			if state.movedDuringGrace {
-				state.lastPosition = currentPos
-				state.lastPositionTick = tick
-				continue
+		if moved || activeRecently {
+			if h.firstMovementTick[steamID] == nil && moved {
+				movedTick := tick
+				h.firstMovementTick[steamID] = &movedTick
 			}
-
-			// Check if player died at this tick (while AFK)
-			if state.deathTick != nil && *state.deathTick == tick {
-				// Player died while AFK
-				if state.afkStartTick != nil {
-					// Finalize AFK interval (ended by death, state = AFK_DIED)
-					e.createAFKEvent(matchID, roundIndex, state.steamID, *state.afkStartTick, tick, "DIED", true, state.firstMovementTick)
-					state.afkStartTick = nil
+			if tick < ctx.GracePeriodEndTick {
+				h.movedDuringGrace[steamID] = true
+				h.afkStartTick[steamID] = nil
+				h.resetLifecycle(steamID)
+			} else if afkStart := h.afkStartTick[steamID]; afkStart != nil {
+				wasConfirmed, lastAFKTick := h.confirmed[steamID], h.lastAFKTick[steamID]
+				endedBy := "MOVED"
+				eventActivity := ActivityKind("")
+				if !moved && activeRecently {
+					endedBy = "ACTIVITY"
+					eventActivity = activityKind
 				}
-			} else if state.afkStartTick == nil && tick < gracePeriodEndTick {
-				// Player hasn't moved during grace period yet - start AFK at roundStart
-				// Only if they weren't disconnected/dead at round start
-				if !isPlayerDisconnectedOrDead(steamID, freezeEndTick) {
-					afkStart := freezeEndTick
-					state.afkStartTick = &afkStart
+				h.extractor.createAFKEvent(ctx.MatchID, ctx.RoundIndex, steamID, *afkStart, tick, endedBy, false, h.firstMovementTick[steamID], h.reason[steamID], eventActivity)
+				if wasConfirmed {
+					h.extractor.createAFKReturnedEvent(ctx.RoundIndex, steamID, *afkStart, lastAFKTick, tick, eventActivity)
 				}
+				h.afkStartTick[steamID] = nil
+				h.resetLifecycle(steamID)
 			}
-			
-			// Continue tracking position (player is still stationary)
-			state.lastPosition = currentPos
-			state.lastPositionTick = tick
+			continue
 		}
-	}
 
-	// Finalize any remaining AFK states at round end
-	for _, state := range playerStates {
-		if state.afkStartTick != nil && !state.movedDuringGrace {
-			// Only finalize if player wasn't disconnected/dead at round end
-			if !isPlayerDisconnectedOrDead(state.steamID, roundEndTick) {
-				// Player was still AFK at round end
-				e.createAFKEvent(matchID, roundIndex, state.steamID, *state.afkStartTick, roundEndTick, "ROUND_END", false, state.firstMovementTick)
+		// Hasn't moved significantly, or is wiggling in place within
+		// loiterRadius - either way, not enough to end AFK tracking.
+		if distance > moveEps && loitering {
+			h.reason[steamID] = detectionReasonLoitering
+		}
+		if h.movedDuringGrace[steamID] {
+			continue
+		}
+		if h.afkStartTick[steamID] == nil && tick < ctx.GracePeriodEndTick {
+			afkStart := ctx.FreezeEndTick
+			h.afkStartTick[steamID] = &afkStart
+			if h.reason[steamID] == "" {
+				h.reason[steamID] = detectionReasonNoMovement
 			}
 		}
+		h.checkLifecycle(ctx, steamID, tick)
 	}
+}
 
-	return nil
+func (h *afkTickHandler) OnDeath(ctx *RoundContext, tick int, steamID string) {
+	if afkStart := h.afkStartTick[steamID]; afkStart != nil {
+		h.extractor.createAFKEvent(ctx.MatchID, ctx.RoundIndex, steamID, *afkStart, tick, "DIED", true, h.firstMovementTick[steamID], h.reason[steamID], "")
+		h.afkStartTick[steamID] = nil
+	}
+}
+
+func (h *afkTickHandler) OnDisconnect(ctx *RoundContext, tick int, steamID string, reconnected bool) {
+	if reconnected {
+		return
+	}
+	h.afkStartTick[steamID] = nil
+}
+
+func (h *afkTickHandler) OnRoundEnd(ctx *RoundContext) {
+	for steamID, afkStart := range h.afkStartTick {
+		if afkStart == nil || h.movedDuringGrace[steamID] {
+			continue
+		}
+		if !ctx.IsAlive(steamID, ctx.RoundEndTick) || !ctx.IsConnected(steamID) {
+			continue
+		}
+		h.extractor.createAFKEvent(ctx.MatchID, ctx.RoundIndex, steamID, *afkStart, ctx.RoundEndTick, "ROUND_END", false, h.firstMovementTick[steamID], h.reason[steamID], "")
+	}
 }
 
 // createAFKEvent creates an AFK event with the specified end condition
 // state is a local type from ProcessAFKFromDatabase, so we pass individual fields
-func (e *AFKExtractor) createAFKEvent(matchID string, roundIndex int, steamID string, afkStartTick int, endTick int, endedBy string, diedWhileAFK bool, firstMovementTick *int) {
+func (e *AFKExtractor) createAFKEvent(matchID string, roundIndex int, steamID string, afkStartTick int, endTick int, endedBy string, diedWhileAFK bool, firstMovementTick *int, detectionMethod string, activityKind ActivityKind) {
+	e.recordEpisode(roundIndex, steamID, afkStartTick, endTick)
+
 	afkTicks := endTick - afkStartTick
 	afkSeconds := float64(afkTicks) / e.tickRate
 
@@ -614,6 +579,10 @@ func (e *AFKExtractor) createAFKEvent(matchID string, roundIndex int, steamID st
 		stateStr = "AFK_DIED"
 	}
 
+	if detectionMethod == "" {
+		detectionMethod = detectionReasonNoMovement
+	}
+
 	// Build metadata
 	meta := make(map[string]interface{})
 	meta["seconds"] = afkSeconds
@@ -623,8 +592,16 @@ func (e *AFKExtractor) createAFKEvent(matchID string, roundIndex int, steamID st
 	meta["state"] = stateStr
 	meta["endedBy"] = endedBy
 	meta["diedWhileAFK"] = diedWhileAFK
+	meta["detectionMethod"] = detectionMethod
+	if activityKind != "" {
+		meta["activity_kind"] = string(activityKind)
+	}
+
+	reasonCode := classifyAFKReason(afkSeconds, diedWhileAFK)
+	meta["reason_code"] = reasonCode
+	meta["reason"] = DescribeReason(reasonCode)
 	if firstMovementTick != nil {
-		timeToFirstMovement := float64(*firstMovementTick - afkStartTick) / e.tickRate
+		timeToFirstMovement := float64(*firstMovementTick-afkStartTick) / e.tickRate
 		meta["timeToFirstMovement"] = timeToFirstMovement
 	}
 
@@ -633,202 +610,131 @@ func (e *AFKExtractor) createAFKEvent(matchID string, roundIndex int, steamID st
 
 	// Create event (using AFK_STILLNESS to match UI expectations)
 	event := Event{
-		Type:          "AFK_STILLNESS",
-		RoundIndex:    roundIndex,
-		StartTick:     afkStartTick,
-		EndTick:       &endTick,
-		ActorSteamID:  &steamID,
-		Severity:      1.0, // AFK is always severity 1.0
-		Confidence:    1.0, // High confidence for position-based detection
-		MetaJSON:      &metaJSONStr,
+		Type:         "AFK_STILLNESS",
+		RoundIndex:   roundIndex,
+		StartTick:    afkStartTick,
+		EndTick:      &endTick,
+		ActorSteamID: &steamID,
+		Severity:     1.0, // AFK is always severity 1.0
+		Confidence:   1.0, // High confidence for position-based detection
+		MetaJSON:     &metaJSONStr,
 	}
 
 	e.events = append(e.events, event)
 }
 
-// CheckAllPlayersAFK checks all tracked players for AFK status using live position data.
-// DEPRECATED: Use ProcessAFKFromDatabase instead after positions are written to the database.
-func (e *AFKExtractor) CheckAllPlayersAFK(roundIndex int, tick int, playerPositions map[string]*position) {
-	// Check if freeze time has ended for this round
-	freezeEndTick, freezeTimeEnded := e.freezeEndTicks[roundIndex]
-	if !freezeTimeEnded || tick < freezeEndTick {
-		return
+// createAFKLifecycleEvent emits an AFK_SUSPECTED or AFK_CONFIRMED marker at
+// the tick an in-progress AFK episode crosses the suspected (50% of
+// minAFKSeconds) or confirmed (100%) threshold. Unlike AFK_STILLNESS, these
+// fire while the episode is still ongoing rather than once it's finalized,
+// for overlays/reports that want an early warning instead of a post-round
+// summary.
+func (e *AFKExtractor) createAFKLifecycleEvent(eventType string, roundIndex int, steamID string, afkStartTick int, tick int) {
+	seconds := float64(tick-afkStartTick) / e.tickRate
+	meta := map[string]interface{}{
+		"start_tick": afkStartTick,
+		"tick":       tick,
+		"seconds":    seconds,
 	}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
 
-	// Initialize any new players from the provided positions
-	for steamID, pos := range playerPositions {
-		if pos == nil {
-			continue
-		}
-		key := fmt.Sprintf("%d_%s", roundIndex, steamID)
-		if _, exists := e.playerStates[key]; !exists {
-			// Initialize new player
-			gracePeriodSeconds := 5.0
-			gracePeriodTicks := int(math.Ceil(gracePeriodSeconds * e.tickRate))
-			gracePeriodEndTick := freezeEndTick + gracePeriodTicks
-			
-			e.playerStates[key] = &playerAFKState{
-				roundIndex:         roundIndex,
-				steamID:            steamID,
-				initialPosition:    pos,
-				lastPosition:       pos,
-				lastMoveTick:       tick,
-				gracePeriodEndTick: gracePeriodEndTick,
-				movedDuringGrace:   tick > gracePeriodEndTick, // If discovered after grace period, they implicitly "moved"
-				afkStartTick:       nil,
-				firstMovementTick:  nil,
-				deathTick:          nil,
-				minAFKSeconds:      5.0,
-				movementThreshold:  3.0,
-			}
-		}
-	}
-
-	// Check all players we're tracking
-	for _, state := range e.playerStates {
-		if state.roundIndex != roundIndex {
-			continue
-		}
-
-		// Get position from provided map (live data)
-		currentPos, exists := playerPositions[state.steamID]
-		if !exists || currentPos == nil {
-			// No position at this tick, skip for now
-			continue
-		}
-
-		// Check if player has moved significantly (use movement threshold, not hardcoded 10.0)
-		dx := currentPos.X - state.lastPosition.X
-		dy := currentPos.Y - state.lastPosition.Y
-		dz := currentPos.Z - state.lastPosition.Z
-		distance := math.Sqrt(dx*dx + dy*dy + dz*dz)
-
-		if distance > state.movementThreshold {
-			// Player moved significantly
-
-			// Record first movement tick
-			if state.firstMovementTick == nil {
-				state.firstMovementTick = &tick
-			}
-
-			// If during grace period, mark that they moved (they're NOT AFK)
-			if tick <= state.gracePeriodEndTick {
-				state.movedDuringGrace = true
-			}
-
-			// If AFK was being tracked, cancel it (ended due to movement)
-			// Note: With new implementation, this is handled in ProcessAFKFromDatabase
-			state.afkStartTick = nil
-
-			state.lastPosition = currentPos
-			state.lastMoveTick = tick
-		} else {
-			// Player hasn't moved significantly
-
-			// If they already moved during grace period, they're not AFK - skip
-			if state.movedDuringGrace {
-				state.lastPosition = currentPos
-				continue
-			}
-
-			// Start or continue AFK tracking only for players who haven't moved during grace period
-			if state.afkStartTick == nil {
-				// Start tracking AFK period (after grace period ends)
-				if tick > state.gracePeriodEndTick {
-					state.afkStartTick = &tick
-				}
-			} else {
-				// Check if AFK period is long enough
-				afkTicks := tick - *state.afkStartTick
-				afkSeconds := float64(afkTicks) / e.tickRate
-
-				if afkSeconds >= state.minAFKSeconds {
-					// Player has been AFK long enough
-					state.lastPosition = currentPos
-				}
-			}
-		}
-	}
+	e.events = append(e.events, Event{
+		Type:         eventType,
+		RoundIndex:   roundIndex,
+		StartTick:    tick,
+		ActorSteamID: &steamID,
+		Severity:     0.0, // purely informational - AFK_STILLNESS still carries the scored interval
+		Confidence:   1.0,
+		MetaJSON:     &metaJSONStr,
+	})
 }
 
-// discoverNewPlayers discovers players that haven't been initialized yet but exist in the database
-func (e *AFKExtractor) discoverNewPlayers(roundIndex int, freezeEndTick int, currentTick int) {
-	// Only discover periodically (every 64 ticks or ~1 second) to avoid excessive queries
-	// Check if we've already discovered at this tick
-	discoveryKey := fmt.Sprintf("%d_%d", roundIndex, currentTick)
-	if e.lastDiscoveryTick == nil {
-		e.lastDiscoveryTick = make(map[string]int)
-	}
-	
-	if lastTick, exists := e.lastDiscoveryTick[discoveryKey]; exists && currentTick-lastTick < 64 {
-		return // Skip if we just discovered
+// createAFKReturnedEvent emits an AFK_RETURNED event the tick a previously
+// AFK_CONFIRMED player moves beyond movementThreshold again (or registers a
+// qualifying activity), carrying the episode's total duration, its
+// first/last observed-AFK ticks, and - if it was activity rather than
+// movement that ended it - which kind, so analysts can tell "player held
+// an angle and shot" from "player actually moved".
+func (e *AFKExtractor) createAFKReturnedEvent(roundIndex int, steamID string, firstAFKTick int, lastAFKTick int, returnTick int, activityKind ActivityKind) {
+	meta := map[string]interface{}{
+		"first_afk_tick":    firstAFKTick,
+		"last_afk_tick":     lastAFKTick,
+		"return_tick":       returnTick,
+		"total_afk_seconds": float64(lastAFKTick-firstAFKTick) / e.tickRate,
+	}
+	if activityKind != "" {
+		meta["activity_kind"] = string(activityKind)
 	}
-	e.lastDiscoveryTick[discoveryKey] = currentTick
-
-	// Query for steamids that might not be initialized yet
-	query := "SELECT DISTINCT steamid FROM player_positions WHERE tick >= ? AND tick <= ? + 10"
-	rows, err := e.db.Query(query, freezeEndTick, currentTick)
-	if err != nil {
-		return
-	}
-	defer rows.Close()
-
-	gracePeriodSeconds := 5.0
-	gracePeriodTicks := int(math.Ceil(gracePeriodSeconds * e.tickRate))
-	gracePeriodEndTick := freezeEndTick + gracePeriodTicks
-
-	for rows.Next() {
-		var steamID string
-		if err := rows.Scan(&steamID); err != nil {
-			continue
-		}
-
-		key := fmt.Sprintf("%d_%s", roundIndex, steamID)
-
-		// Check if already initialized
-		if _, exists := e.playerStates[key]; exists {
-			continue // Already tracking this player
-		}
-
-		// Try to get position at current tick
-		pos, err := e.getPlayerPositionFromDB(steamID, currentTick)
-		if err != nil || pos == nil {
-			continue
-		}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
 
-		// Initialize new player
-		e.playerStates[key] = &playerAFKState{
-			roundIndex:         roundIndex,
-			steamID:            steamID,
-			initialPosition:    pos,
-			lastPosition:       pos,
-			lastMoveTick:       currentTick,
-			gracePeriodEndTick: gracePeriodEndTick,
-			movedDuringGrace:   currentTick > gracePeriodEndTick, // If discovered after grace period, they implicitly "moved"
-			afkStartTick:       nil,
-			firstMovementTick:  nil,
-			deathTick:          nil,
-			minAFKSeconds:      5.0,
-			movementThreshold:  3.0,
-		}
-	}
+	e.events = append(e.events, Event{
+		Type:         "AFK_RETURNED",
+		RoundIndex:   roundIndex,
+		StartTick:    returnTick,
+		ActorSteamID: &steamID,
+		Severity:     0.0,
+		Confidence:   1.0,
+		MetaJSON:     &metaJSONStr,
+	})
 }
 
-// CancelAFK is deprecated - AFK detection is now done via ProcessAFKFromDatabase
-// This function is kept for backwards compatibility but does nothing
-func (e *AFKExtractor) CancelAFK(player *common.Player, roundIndex int, tick int) {
-	// Deprecated - no longer used
+// recordEpisode appends one finalized AFK interval to roundIndex/steamID's
+// episode history, backing GetRoundAFKSummary.
+func (e *AFKExtractor) recordEpisode(roundIndex int, steamID string, startTick, endTick int) {
+	if e.episodes[roundIndex] == nil {
+		e.episodes[roundIndex] = make(map[string][]afkEpisode)
+	}
+	e.episodes[roundIndex][steamID] = append(e.episodes[roundIndex][steamID], afkEpisode{
+		StartTick:       startTick,
+		EndTick:         endTick,
+		DurationSeconds: float64(endTick-startTick) / e.tickRate,
+	})
 }
 
-// FinalizeRound is deprecated - AFK detection is now done via ProcessAFKFromDatabase
-// This function is kept for backwards compatibility but does nothing
-func (e *AFKExtractor) FinalizeRound(roundIndex int, finalTick int) {
-	// Deprecated - no longer used
+// GetRoundAFKSummary returns, for every steamID with at least one recorded
+// AFK episode in roundIndex, their total AFK seconds, episode count, and
+// longest single episode - the aggregate a caller needs to report effective
+// playtime for the round rather than a binary AFK flag.
+func (e *AFKExtractor) GetRoundAFKSummary(roundIndex int) map[string]AFKRoundSummary {
+	summary := make(map[string]AFKRoundSummary)
+	for steamID, episodes := range e.episodes[roundIndex] {
+		s := AFKRoundSummary{EpisodeCount: len(episodes)}
+		for _, ep := range episodes {
+			s.TotalAFKSeconds += ep.DurationSeconds
+			if ep.DurationSeconds > s.LongestEpisodeSecs {
+				s.LongestEpisodeSecs = ep.DurationSeconds
+			}
+		}
+		summary[steamID] = s
+	}
+	return summary
 }
 
-
 // GetEvents returns all extracted events.
 func (e *AFKExtractor) GetEvents() []Event {
 	return e.events
 }
 
+// ClearEvents discards all extracted events, e.g. once the caller has
+// flushed them to a file or the database.
+func (e *AFKExtractor) ClearEvents() {
+	e.events = e.events[:0]
+}
+
+// IsAFKTrackingComplete reports whether roundIndex/steamID's AFK state has
+// already been resolved (the player moved or died), so callers sampling
+// live positions for later ProcessAFKFromDatabase analysis can skip
+// writing any more of that player's positions for the round. Returns
+// false for a player with no tracked state yet, which is always the case
+// here since AFK resolution itself happens post-parse in
+// ProcessAFKFromDatabase - this is a hook for once live tracking updates
+// playerStates during the round, not a behavior change today.
+func (e *AFKExtractor) IsAFKTrackingComplete(roundIndex int, steamID string) bool {
+	state, exists := e.playerStates[fmt.Sprintf("%d_%s", roundIndex, steamID)]
+	if !exists {
+		return false
+	}
+	return state.firstMovementTick != nil || state.deathTick != nil
+}
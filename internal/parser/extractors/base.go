@@ -52,4 +52,3 @@ func isSamePlayer(p1, p2 *common.Player) bool {
 func isTeamKill(attacker, victim *common.Player) bool {
 	return isSameTeam(attacker, victim) && !isSamePlayer(attacker, victim)
 }
-
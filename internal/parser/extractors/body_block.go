@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sort"
 	"strings"
+
+	"cs-griefer-electron/internal/db"
 )
 
 type BodyBlockExtractor struct {
@@ -17,6 +20,7 @@ type BodyBlockExtractor struct {
 }
 
 type bodyBlockPosition struct {
+	tick    int
 	steamID string
 	team    string
 	x       float64
@@ -57,13 +61,6 @@ func (e *BodyBlockExtractor) ProcessRoundFromDatabase(matchID string, roundIndex
 		return
 	}
 
-	// Thresholds tuned for head-stacking detection
-	const (
-		maxXYDistance = 24.0
-		minZDelta     = 40.0
-		maxZDelta     = 90.0
-	)
-
 	query := `
 		SELECT tick, steamid, x, y, z, team
 		FROM player_positions
@@ -77,6 +74,56 @@ func (e *BodyBlockExtractor) ProcessRoundFromDatabase(matchID string, roundIndex
 	}
 	defer rows.Close()
 
+	samples := make([]bodyBlockPosition, 0, 64)
+	for rows.Next() {
+		var tick int
+		var steamID, team string
+		var x, y, z float64
+		if err := rows.Scan(&tick, &steamID, &x, &y, &z, &team); err != nil {
+			continue
+		}
+		samples = append(samples, bodyBlockPosition{tick: tick, steamID: steamID, team: team, x: x, y: y, z: z})
+	}
+
+	e.processSamples(roundIndex, samples)
+}
+
+// ProcessRoundFromPositions is the in-memory counterpart to
+// ProcessRoundFromDatabase, for callers with no SQL database to re-query -
+// e.g. internal/parser.ParseWithDB's no-writer (JSON/Parquet streaming)
+// path, which only ever has the current round's positions in memory (see
+// currentRoundPositions there). positions outside roundIndex are ignored,
+// so a caller can pass a buffer scoped to exactly one round without
+// filtering it first.
+func (e *BodyBlockExtractor) ProcessRoundFromPositions(roundIndex int, startTick int, endTick int, positions []db.PlayerPosition) {
+	samples := make([]bodyBlockPosition, 0, len(positions))
+	for _, p := range positions {
+		if p.RoundIndex != roundIndex || p.Tick < startTick || p.Tick > endTick {
+			continue
+		}
+		var team string
+		if p.Team != nil {
+			team = *p.Team
+		}
+		samples = append(samples, bodyBlockPosition{tick: p.Tick, steamID: p.SteamID, team: team, x: p.X, y: p.Y, z: p.Z})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].tick < samples[j].tick })
+
+	e.processSamples(roundIndex, samples)
+}
+
+// processSamples runs the head-stacking detector over samples (already
+// sorted by tick ascending), shared by the database and in-memory entry
+// points above so the detection logic itself doesn't care which backend
+// supplied the positions.
+func (e *BodyBlockExtractor) processSamples(roundIndex int, samples []bodyBlockPosition) {
+	// Thresholds tuned for head-stacking detection
+	const (
+		maxXYDistance = 24.0
+		minZDelta     = 40.0
+		maxZDelta     = 90.0
+	)
+
 	active := make(map[string]*bodyBlockState)
 
 	currentTick := -1
@@ -165,33 +212,18 @@ func (e *BodyBlockExtractor) ProcessRoundFromDatabase(matchID string, roundIndex
 		}
 	}
 
-	for rows.Next() {
-		var tick int
-		var steamID string
-		var x, y, z float64
-		var team string
-
-		if err := rows.Scan(&tick, &steamID, &x, &y, &z, &team); err != nil {
-			continue
-		}
-
+	for _, sample := range samples {
 		if currentTick == -1 {
-			currentTick = tick
+			currentTick = sample.tick
 		}
 
-		if tick != currentTick {
+		if sample.tick != currentTick {
 			flushTick(currentTick, positions)
 			positions = positions[:0]
-			currentTick = tick
+			currentTick = sample.tick
 		}
 
-		positions = append(positions, bodyBlockPosition{
-			steamID: steamID,
-			team:    team,
-			x:       x,
-			y:       y,
-			z:       z,
-		})
+		positions = append(positions, sample)
 	}
 
 	if len(positions) > 0 {
@@ -205,57 +237,61 @@ func (e *BodyBlockExtractor) ProcessRoundFromDatabase(matchID string, roundIndex
 }
 
 func (e *BodyBlockExtractor) finalizePair(key string, state *bodyBlockState, roundIndex int) {
-    if state.totalTicks <= 0 {
+	if state.totalTicks <= 0 {
 		fmt.Fprintf(os.Stderr, "[BODY_BLOCK] Skip pair %s (round %d): totalTicks=%d\n", key, roundIndex, state.totalTicks)
-        return
-    }
+		return
+	}
 
-    seconds := float64(state.totalTicks) / e.tickRate
-    if seconds < e.minSeconds {
+	seconds := float64(state.totalTicks) / e.tickRate
+	if seconds < e.minSeconds {
 		fmt.Fprintf(os.Stderr, "[BODY_BLOCK] Skip pair %s (round %d): seconds=%.2f < minSeconds=%.2f\n", key, roundIndex, seconds, e.minSeconds)
-        return
-    }
+		return
+	}
 
-    parts := strings.SplitN(key, "_", 2)
-    if len(parts) != 2 {
+	parts := strings.SplitN(key, "_", 2)
+	if len(parts) != 2 {
 		fmt.Fprintf(os.Stderr, "[BODY_BLOCK] Skip pair %s (round %d): invalid key\n", key, roundIndex)
-        return
-    }
-    actorSteamID := parts[0]
-    victimSteamID := parts[1]
-
-    avgDistXY := state.sumDistXY / float64(state.totalTicks)
-    avgZDelta := state.sumZDelta / float64(state.totalTicks)
-
-    severity := math.Min(1.0, seconds/5.0)
-    confidence := math.Min(1.0, 0.5+(seconds/5.0))
-
-    meta := map[string]interface{}{
-        "seconds":         seconds,
-        "stacked_ticks":   state.totalTicks,
-        "min_xy_distance": state.minDistXY,
-        "avg_xy_distance": avgDistXY,
-        "avg_z_delta":     avgZDelta,
-    }
-
-    metaJSON, _ := json.Marshal(meta)
-    metaJSONStr := string(metaJSON)
-
-    startTick := state.startTick
-    endTick := state.lastTick
-
-    e.events = append(e.events, Event{
-        Type:          "BODY_BLOCK",
-        RoundIndex:    roundIndex,
-        StartTick:     startTick,
-        EndTick:       &endTick,
-        ActorSteamID:  &actorSteamID,
-        VictimSteamID: &victimSteamID,
-        Severity:      severity,
-        Confidence:    confidence,
-        MetaJSON:      &metaJSONStr,
-    })
+		return
+	}
+	actorSteamID := parts[0]
+	victimSteamID := parts[1]
+
+	avgDistXY := state.sumDistXY / float64(state.totalTicks)
+	avgZDelta := state.sumZDelta / float64(state.totalTicks)
+
+	severity := math.Min(1.0, seconds/5.0)
+	confidence := math.Min(1.0, 0.5+(seconds/5.0))
+
+	reasonCode := classifyBodyBlockReason(seconds)
+
+	meta := map[string]interface{}{
+		"seconds":         seconds,
+		"stacked_ticks":   state.totalTicks,
+		"min_xy_distance": state.minDistXY,
+		"avg_xy_distance": avgDistXY,
+		"avg_z_delta":     avgZDelta,
+		"reason_code":     reasonCode,
+		"reason":          DescribeReason(reasonCode),
+	}
+
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	startTick := state.startTick
+	endTick := state.lastTick
+
+	e.events = append(e.events, Event{
+		Type:          "BODY_BLOCK",
+		RoundIndex:    roundIndex,
+		StartTick:     startTick,
+		EndTick:       &endTick,
+		ActorSteamID:  &actorSteamID,
+		VictimSteamID: &victimSteamID,
+		Severity:      severity,
+		Confidence:    confidence,
+		MetaJSON:      &metaJSONStr,
+	})
 
 	fmt.Fprintf(os.Stderr, "[BODY_BLOCK] Detected round %d: actor=%s victim=%s seconds=%.2f ticks=%d minXY=%.2f avgXY=%.2f avgZ=%.2f startTick=%d endTick=%d\n",
 		roundIndex, actorSteamID, victimSteamID, seconds, state.totalTicks, state.minDistXY, avgDistXY, avgZDelta, startTick, endTick)
-}
\ No newline at end of file
+}
@@ -0,0 +1,85 @@
+package extractors
+
+import (
+	"testing"
+
+	"cs-griefer-electron/internal/db"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestProcessRoundFromPositionsDetectsStackedTeammates(t *testing.T) {
+	e := NewBodyBlockExtractor(64.0, nil)
+
+	positions := make([]db.PlayerPosition, 0, 64)
+	for tick := 100; tick < 140; tick++ {
+		positions = append(positions,
+			db.PlayerPosition{RoundIndex: 1, Tick: tick, SteamID: "top", Team: strPtr("T"), X: 0, Y: 0, Z: 60},
+			db.PlayerPosition{RoundIndex: 1, Tick: tick, SteamID: "bottom", Team: strPtr("T"), X: 0, Y: 0, Z: 0},
+		)
+	}
+
+	e.ProcessRoundFromPositions(1, 100, 139, positions)
+
+	events := e.GetEvents()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 BODY_BLOCK event, got %d", len(events))
+	}
+	if events[0].Type != "BODY_BLOCK" {
+		t.Errorf("expected Type BODY_BLOCK, got %q", events[0].Type)
+	}
+	if events[0].ActorSteamID == nil || *events[0].ActorSteamID != "top" {
+		t.Errorf("expected actor \"top\", got %v", events[0].ActorSteamID)
+	}
+	if events[0].VictimSteamID == nil || *events[0].VictimSteamID != "bottom" {
+		t.Errorf("expected victim \"bottom\", got %v", events[0].VictimSteamID)
+	}
+}
+
+func TestProcessRoundFromPositionsIgnoresOtherRoundsAndTeams(t *testing.T) {
+	e := NewBodyBlockExtractor(64.0, nil)
+
+	positions := []db.PlayerPosition{
+		// Different round: should be filtered out entirely.
+		{RoundIndex: 2, Tick: 100, SteamID: "top", Team: strPtr("T"), X: 0, Y: 0, Z: 60},
+		{RoundIndex: 2, Tick: 100, SteamID: "bottom", Team: strPtr("T"), X: 0, Y: 0, Z: 0},
+		// Same round, but opposing teams: shouldn't count as a body block.
+		{RoundIndex: 1, Tick: 100, SteamID: "t_player", Team: strPtr("T"), X: 0, Y: 0, Z: 60},
+		{RoundIndex: 1, Tick: 100, SteamID: "ct_player", Team: strPtr("CT"), X: 0, Y: 0, Z: 0},
+	}
+
+	e.ProcessRoundFromPositions(1, 0, 200, positions)
+
+	if events := e.GetEvents(); len(events) != 0 {
+		t.Fatalf("expected no events, got %d", len(events))
+	}
+}
+
+func TestProcessRoundFromPositionsMatchesDatabasePath(t *testing.T) {
+	dbExtractor := NewBodyBlockExtractor(64.0, nil)
+	memExtractor := NewBodyBlockExtractor(64.0, nil)
+
+	var samples []bodyBlockPosition
+	var positions []db.PlayerPosition
+	for tick := 10; tick < 50; tick++ {
+		samples = append(samples,
+			bodyBlockPosition{tick: tick, steamID: "a", team: "T", x: 0, y: 0, z: 60},
+			bodyBlockPosition{tick: tick, steamID: "b", team: "T", x: 0, y: 0, z: 0},
+		)
+		positions = append(positions,
+			db.PlayerPosition{RoundIndex: 3, Tick: tick, SteamID: "a", Team: strPtr("T"), X: 0, Y: 0, Z: 60},
+			db.PlayerPosition{RoundIndex: 3, Tick: tick, SteamID: "b", Team: strPtr("T"), X: 0, Y: 0, Z: 0},
+		)
+	}
+	dbExtractor.processSamples(3, samples)
+	memExtractor.ProcessRoundFromPositions(3, 0, 49, positions)
+
+	dbEvents, memEvents := dbExtractor.GetEvents(), memExtractor.GetEvents()
+	if len(dbEvents) != 1 || len(memEvents) != 1 {
+		t.Fatalf("expected both paths to detect 1 event, got db=%d mem=%d", len(dbEvents), len(memEvents))
+	}
+	if dbEvents[0].StartTick != memEvents[0].StartTick || *dbEvents[0].EndTick != *memEvents[0].EndTick {
+		t.Errorf("expected identical tick windows, got db=[%d,%d] mem=[%d,%d]",
+			dbEvents[0].StartTick, *dbEvents[0].EndTick, memEvents[0].StartTick, *memEvents[0].EndTick)
+	}
+}
@@ -0,0 +1,290 @@
+package extractors
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// voteKickTimeoutSeconds is how long after a VOTE_KICK_PASSED event a
+// matching DISCONNECT is still considered caused by that vote, per
+// DisconnectExtractor.UpgradeVoteKicked.
+const voteKickTimeoutSeconds = 30
+
+// defaultToxicWords is the built-in fallback word list used when no custom
+// list is loaded via LoadToxicWordList. It is intentionally short - callers
+// that care about coverage should supply a real list (e.g. Steam's chat
+// filter format, one word per line).
+var defaultToxicWords = []string{
+	"kys",
+	"retard",
+	"retarded",
+	"nigger",
+	"nigga",
+	"faggot",
+}
+
+// pendingVoteKick tracks a vote_started..vote_passed/vote_failed sequence
+// that hasn't resolved yet.
+type pendingVoteKick struct {
+	initiatorSteamID string
+	targetSteamID    string
+	startTick        int
+	roundIndex       int
+}
+
+// ChatVoteExtractor extracts toxic-chat flags and vote-kick lifecycle events
+// from chat messages and vote-related GenericGameEvents. Vote-kick detection
+// is best-effort: demoinfocs-golang v5 doesn't expose a typed vote event, so
+// this relies on the same raw GenericGameEvent name/field matching used for
+// player_disconnect (see parser.go) and degrades gracefully to
+// VOTE_KICK_INITIATED/VOTE_KICK_PASSED events with no resolved target when a
+// demo's vote_* events don't carry the fields this extractor looks for.
+//
+// demoinfocs-golang v5 has no voice-data event at all, so MIC_SPAM detection
+// has nothing to subscribe to; HandleVoiceActivity is a no-op hook kept here
+// so wiring it up later (if a future demoinfocs version adds one) doesn't
+// require touching callers again.
+type ChatVoteExtractor struct {
+	events []Event
+
+	toxicPattern *regexp.Regexp
+	pendingVotes map[string]*pendingVoteKick // key: vote id (best-effort)
+}
+
+// NewChatVoteExtractor creates a new extractor using the built-in toxic word
+// list. Use SetToxicWordList (e.g. with words read by LoadToxicWordList) to
+// replace it with a custom one.
+func NewChatVoteExtractor() *ChatVoteExtractor {
+	e := &ChatVoteExtractor{
+		events:       make([]Event, 0),
+		pendingVotes: make(map[string]*pendingVoteKick),
+	}
+	e.toxicPattern = compileToxicPattern(defaultToxicWords)
+	return e
+}
+
+// SetToxicWordList replaces the word list used to flag TOXIC_CHAT messages.
+func (e *ChatVoteExtractor) SetToxicWordList(words []string) {
+	e.toxicPattern = compileToxicPattern(words)
+}
+
+// compileToxicPattern builds a case-insensitive, word-boundary regex from a
+// word list. Words are escaped so they're matched literally.
+func compileToxicPattern(words []string) *regexp.Regexp {
+	escaped := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		escaped = append(escaped, regexp.QuoteMeta(w))
+	}
+	if len(escaped) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(escaped, "|") + `)\b`)
+}
+
+// LoadToxicWordList reads a word list from path, one word per line. Blank
+// lines and lines starting with "#" are ignored, matching the plain-text
+// format Steam's chat filter lists use.
+func LoadToxicWordList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return words, nil
+}
+
+// HandleChatMessage inspects a chat message for toxic content and emits a
+// TOXIC_CHAT event when the configured word list matches.
+func (e *ChatVoteExtractor) HandleChatMessage(steamID, message string, roundIndex, tick int, isTeamChat bool) {
+	if e.toxicPattern == nil || steamID == "" || message == "" {
+		return
+	}
+	match := e.toxicPattern.FindString(message)
+	if match == "" {
+		return
+	}
+
+	meta := map[string]interface{}{
+		"matched_word": strings.ToLower(match),
+		"is_team_chat": isTeamChat,
+	}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	actorID := steamID
+	e.events = append(e.events, Event{
+		Type:         "TOXIC_CHAT",
+		RoundIndex:   roundIndex,
+		StartTick:    tick,
+		ActorSteamID: &actorID,
+		Severity:     0.3,
+		Confidence:   0.7,
+		MetaJSON:     &metaJSONStr,
+	})
+}
+
+// HandleVoteStarted records the start of a vote-kick (initiator/target may be
+// empty strings if the demo's vote_started event doesn't carry them) and
+// emits VOTE_KICK_INITIATED.
+func (e *ChatVoteExtractor) HandleVoteStarted(voteID, initiatorSteamID, targetSteamID string, roundIndex, tick int) {
+	e.pendingVotes[voteID] = &pendingVoteKick{
+		initiatorSteamID: initiatorSteamID,
+		targetSteamID:    targetSteamID,
+		startTick:        tick,
+		roundIndex:       roundIndex,
+	}
+
+	meta := map[string]interface{}{
+		"initiator_steam_id": initiatorSteamID,
+		"target_steam_id":    targetSteamID,
+	}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	var actorID *string
+	if initiatorSteamID != "" {
+		id := initiatorSteamID
+		actorID = &id
+	}
+	var victimID *string
+	if targetSteamID != "" {
+		id := targetSteamID
+		victimID = &id
+	}
+
+	e.events = append(e.events, Event{
+		Type:          "VOTE_KICK_INITIATED",
+		RoundIndex:    roundIndex,
+		StartTick:     tick,
+		ActorSteamID:  actorID,
+		VictimSteamID: victimID,
+		Severity:      0.2,
+		Confidence:    0.6,
+		MetaJSON:      &metaJSONStr,
+	})
+}
+
+// HandleVoteResult resolves a pending vote (passed == true/false) and, when
+// passed, emits VOTE_KICK_PASSED with the yes/no tallies. Unknown voteIDs
+// (e.g. votes whose vote_started this extractor never saw) are ignored.
+func (e *ChatVoteExtractor) HandleVoteResult(voteID string, passed bool, yesVotes, noVotes, roundIndex, tick int) {
+	pending, ok := e.pendingVotes[voteID]
+	delete(e.pendingVotes, voteID)
+	if !ok || !passed {
+		return
+	}
+
+	meta := map[string]interface{}{
+		"initiator_steam_id": pending.initiatorSteamID,
+		"target_steam_id":    pending.targetSteamID,
+		"yes_votes":          yesVotes,
+		"no_votes":           noVotes,
+	}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	var actorID *string
+	if pending.initiatorSteamID != "" {
+		id := pending.initiatorSteamID
+		actorID = &id
+	}
+	var victimID *string
+	if pending.targetSteamID != "" {
+		id := pending.targetSteamID
+		victimID = &id
+	}
+
+	e.events = append(e.events, Event{
+		Type:          "VOTE_KICK_PASSED",
+		RoundIndex:    roundIndex,
+		StartTick:     tick,
+		ActorSteamID:  actorID,
+		VictimSteamID: victimID,
+		Severity:      0.4,
+		Confidence:    0.6,
+		MetaJSON:      &metaJSONStr,
+	})
+}
+
+// HandleVoiceActivity is a no-op hook reserved for MIC_SPAM detection.
+// demoinfocs-golang v5 doesn't expose voice_data frequency, so there is
+// nothing to key severity off of yet; this exists so wiring it up later
+// doesn't require touching every caller again.
+func (e *ChatVoteExtractor) HandleVoiceActivity(steamID string, roundIndex, tick int, sampleCount int) {
+	_ = steamID
+	_ = roundIndex
+	_ = tick
+	_ = sampleCount
+}
+
+// GetEvents returns all events produced so far.
+func (e *ChatVoteExtractor) GetEvents() []Event {
+	return e.events
+}
+
+// ClearEvents clears the accumulated events.
+func (e *ChatVoteExtractor) ClearEvents() {
+	e.events = e.events[:0]
+}
+
+// voteKickEventsForUpgrade returns this extractor's VOTE_KICK_PASSED events,
+// for DisconnectExtractor.UpgradeVoteKicked to scan without exposing
+// pendingVotes (which is cleared as votes resolve and wouldn't cover votes
+// resolved in an earlier flush).
+func (e *ChatVoteExtractor) voteKickEventsForUpgrade() []Event {
+	var passed []Event
+	for _, event := range e.events {
+		if event.Type == "VOTE_KICK_PASSED" {
+			passed = append(passed, event)
+		}
+	}
+	return passed
+}
+
+// ApplyVoteKickContext cross-references every VOTE_KICK_PASSED event against
+// disconnectExtractor's recorded disconnects and upgrades any disconnect
+// within voteKickTimeoutSeconds of a passed vote targeting that player. See
+// DisconnectExtractor.UpgradeVoteKicked for the matching rule.
+func (e *ChatVoteExtractor) ApplyVoteKickContext(disconnectExtractor *DisconnectExtractor, tickRate float64) {
+	for _, vote := range e.voteKickEventsForUpgrade() {
+		if vote.VictimSteamID == nil {
+			continue
+		}
+		var initiator string
+		var yesVotes, noVotes int
+		if vote.MetaJSON != nil {
+			var meta map[string]interface{}
+			if err := json.Unmarshal([]byte(*vote.MetaJSON), &meta); err == nil {
+				initiator, _ = meta["initiator_steam_id"].(string)
+				if v, ok := meta["yes_votes"].(float64); ok {
+					yesVotes = int(v)
+				}
+				if v, ok := meta["no_votes"].(float64); ok {
+					noVotes = int(v)
+				}
+			}
+		}
+		disconnectExtractor.UpgradeVoteKicked(*vote.VictimSteamID, vote.StartTick, tickRate, initiator, yesVotes, noVotes)
+	}
+}
@@ -0,0 +1,138 @@
+package extractors
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChatVoteExtractorFlagsToxicChat(t *testing.T) {
+	e := NewChatVoteExtractor()
+	e.HandleChatMessage("1", "you are such a retard", 1, 100, false)
+
+	events := e.GetEvents()
+	if len(events) != 1 || events[0].Type != "TOXIC_CHAT" {
+		t.Fatalf("expected 1 TOXIC_CHAT event, got %+v", events)
+	}
+	if *events[0].ActorSteamID != "1" {
+		t.Errorf("expected actor 1, got %s", *events[0].ActorSteamID)
+	}
+}
+
+func TestChatVoteExtractorIgnoresCleanChat(t *testing.T) {
+	e := NewChatVoteExtractor()
+	e.HandleChatMessage("1", "nice shot!", 1, 100, false)
+
+	if len(e.GetEvents()) != 0 {
+		t.Errorf("expected no events for clean chat, got %d", len(e.GetEvents()))
+	}
+}
+
+func TestChatVoteExtractorCustomWordList(t *testing.T) {
+	e := NewChatVoteExtractor()
+	e.SetToxicWordList([]string{"banword"})
+	e.HandleChatMessage("1", "this has a banword in it", 1, 100, false)
+	e.HandleChatMessage("2", "retard", 1, 101, false)
+
+	if len(e.GetEvents()) != 1 {
+		t.Fatalf("expected only the custom word to match, got %d events", len(e.GetEvents()))
+	}
+}
+
+func TestChatVoteExtractorVoteKickPassedEmitsEvent(t *testing.T) {
+	e := NewChatVoteExtractor()
+	e.HandleVoteStarted("v1", "100", "200", 3, 500)
+	e.HandleVoteResult("v1", true, 4, 1, 3, 550)
+
+	var passed *Event
+	for i := range e.GetEvents() {
+		if e.GetEvents()[i].Type == "VOTE_KICK_PASSED" {
+			passed = &e.GetEvents()[i]
+		}
+	}
+	if passed == nil {
+		t.Fatalf("expected a VOTE_KICK_PASSED event, got %+v", e.GetEvents())
+	}
+	if *passed.VictimSteamID != "200" {
+		t.Errorf("expected target 200, got %s", *passed.VictimSteamID)
+	}
+
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(*passed.MetaJSON), &meta); err != nil {
+		t.Fatalf("failed to unmarshal meta: %v", err)
+	}
+	if meta["yes_votes"] != float64(4) || meta["no_votes"] != float64(1) {
+		t.Errorf("expected yes/no tallies in meta, got %+v", meta)
+	}
+}
+
+func TestChatVoteExtractorVoteFailedEmitsNoPassedEvent(t *testing.T) {
+	e := NewChatVoteExtractor()
+	e.HandleVoteStarted("v1", "100", "200", 3, 500)
+	e.HandleVoteResult("v1", false, 1, 4, 3, 550)
+
+	for _, event := range e.GetEvents() {
+		if event.Type == "VOTE_KICK_PASSED" {
+			t.Fatalf("did not expect VOTE_KICK_PASSED for a failed vote")
+		}
+	}
+}
+
+func TestChatVoteExtractorUnknownVoteIDIgnored(t *testing.T) {
+	e := NewChatVoteExtractor()
+	e.HandleVoteResult("unknown", true, 5, 0, 1, 100)
+
+	if len(e.GetEvents()) != 0 {
+		t.Errorf("expected no events for an unresolved vote id, got %d", len(e.GetEvents()))
+	}
+}
+
+func TestDisconnectExtractorUpgradeVoteKicked(t *testing.T) {
+	e := NewDisconnectExtractor()
+	actor := "100"
+	meta := `{"classification":"ABANDON","reason_family":"voluntary"}`
+	e.events = append(e.events, Event{
+		Type:         "ABANDON",
+		RoundIndex:   3,
+		StartTick:    520, // 20 ticks (= 0.3s @ 64 tick) after the vote passed
+		ActorSteamID: &actor,
+		Severity:     0.3,
+		Confidence:   0.6,
+		MetaJSON:     &meta,
+	})
+
+	e.UpgradeVoteKicked("100", 500, 64.0, "999", 5, 1)
+
+	var upgraded map[string]interface{}
+	if err := json.Unmarshal([]byte(*e.events[0].MetaJSON), &upgraded); err != nil {
+		t.Fatalf("failed to unmarshal upgraded meta: %v", err)
+	}
+	if upgraded["classification"] != DisconnectClassVoteKicked {
+		t.Errorf("expected classification %s, got %v", DisconnectClassVoteKicked, upgraded["classification"])
+	}
+	if upgraded["initiator_steam_id"] != "999" {
+		t.Errorf("expected initiator 999, got %v", upgraded["initiator_steam_id"])
+	}
+}
+
+func TestDisconnectExtractorUpgradeVoteKickedOutsideWindowNoop(t *testing.T) {
+	e := NewDisconnectExtractor()
+	actor := "100"
+	meta := `{"classification":"ABANDON"}`
+	e.events = append(e.events, Event{
+		Type:         "ABANDON",
+		RoundIndex:   3,
+		StartTick:    500 + int(64.0*voteKickTimeoutSeconds) + 1,
+		ActorSteamID: &actor,
+		MetaJSON:     &meta,
+	})
+
+	e.UpgradeVoteKicked("100", 500, 64.0, "999", 5, 1)
+
+	var unchanged map[string]interface{}
+	if err := json.Unmarshal([]byte(*e.events[0].MetaJSON), &unchanged); err != nil {
+		t.Fatalf("failed to unmarshal meta: %v", err)
+	}
+	if unchanged["classification"] != "ABANDON" {
+		t.Errorf("expected classification to stay ABANDON, got %v", unchanged["classification"])
+	}
+}
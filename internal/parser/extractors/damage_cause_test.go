@@ -0,0 +1,74 @@
+package extractors
+
+import (
+	"testing"
+
+	common "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/common"
+)
+
+func TestClassifyDamageCause(t *testing.T) {
+	attacker := &common.Player{SteamID64: 1}
+	victim := &common.Player{SteamID64: 2}
+
+	cases := []struct {
+		name     string
+		attacker *common.Player
+		victim   *common.Player
+		weapon   *common.Equipment
+		want     DamageCause
+	}{
+		{"self-damage", attacker, attacker, &common.Equipment{Type: common.EqHE}, DamageCauseSuicide},
+		{"rifle", attacker, victim, &common.Equipment{Type: common.EqAK47}, DamageCauseDirectFire},
+		{"no weapon info", attacker, victim, nil, DamageCauseDirectFire},
+		{"he grenade", attacker, victim, &common.Equipment{Type: common.EqHE}, DamageCauseGrenadeSplash},
+		{"molotov", attacker, victim, &common.Equipment{Type: common.EqMolotov}, DamageCauseMolotovBurn},
+		{"incendiary", attacker, victim, &common.Equipment{Type: common.EqIncendiary}, DamageCauseMolotovBurn},
+		{"bomb with attacker", attacker, victim, &common.Equipment{Type: common.EqBomb}, DamageCauseC4Explosion},
+		{"bomb with no attacker", nil, victim, &common.Equipment{Type: common.EqBomb}, DamageCauseC4Explosion},
+		{"world damage", nil, victim, nil, DamageCauseWorld},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := classifyDamageCause(tc.attacker, tc.victim, tc.weapon)
+			if got != tc.want {
+				t.Errorf("classifyDamageCause() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDamageCauseFilterDefault(t *testing.T) {
+	filter := DefaultDamageCauseFilter()
+
+	denied := []DamageCause{DamageCauseSuicide, DamageCauseWorld}
+	for _, cause := range denied {
+		if filter.Allowed(cause) {
+			t.Errorf("expected %s to be denied by default", cause)
+		}
+	}
+
+	allowed := []DamageCause{DamageCauseDirectFire, DamageCauseGrenadeSplash, DamageCauseMolotovBurn, DamageCauseC4Explosion}
+	for _, cause := range allowed {
+		if !filter.Allowed(cause) {
+			t.Errorf("expected %s to be allowed by default", cause)
+		}
+	}
+}
+
+func TestDamageCauseFilterNilAllowsEverything(t *testing.T) {
+	var filter *DamageCauseFilter
+	if !filter.Allowed(DamageCauseSuicide) {
+		t.Error("expected nil filter to allow everything")
+	}
+}
+
+func TestDamageCauseFilterCustomDenyList(t *testing.T) {
+	filter := NewDamageCauseFilter([]DamageCause{DamageCauseMolotovBurn})
+	if filter.Allowed(DamageCauseMolotovBurn) {
+		t.Error("expected MOLOTOV_BURN to be denied")
+	}
+	if !filter.Allowed(DamageCauseDirectFire) {
+		t.Error("expected DIRECT_FIRE to remain allowed")
+	}
+}
@@ -3,10 +3,13 @@ package extractors
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
+	common "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/common"
 	events "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/events"
 )
 
@@ -16,8 +19,25 @@ type DisconnectExtractor struct {
 	pendingDisconnects map[string]*pendingDisconnect // key: steamID
 	lastRoundEndTick   *int                          // Track last round end tick to filter disconnects within 10s
 	disconnectReasons  map[string]interface{}        // key: steamID-tick, value: reason code from GenericGameEvent
+
+	lastAdverseTick map[string]int      // key: steamID, value: tick of most recent damage taken - the closest proxy to "something bad just happened to them" available
+	teamRoundsWon   map[common.Team]int // running round-win count per team, used to judge the score margin at the moment a player disconnects
+	matchKills      map[string]int      // key: steamID, set once via SetMatchContext
+	matchDeaths     map[string]int      // key: steamID, set once via SetMatchContext
 }
 
+// DisconnectClass is the taxonomy ClassifyDisconnects sorts every
+// unrecovered (or slow-to-recover) disconnect into.
+const (
+	DisconnectClassRageQuit    = "RAGE_QUIT"
+	DisconnectClassTimeout     = "TIMEOUT"
+	DisconnectClassKick        = "KICK"
+	DisconnectClassAbandon     = "ABANDON"
+	DisconnectClassTechnical   = "TECHNICAL"
+	DisconnectClassNormalLeave = "NORMAL_LEAVE"
+	DisconnectClassVoteKicked  = "VOTE_KICKED"
+)
+
 type pendingDisconnect struct {
 	steamID        string
 	roundIndex     int
@@ -33,16 +53,20 @@ func NewDisconnectExtractor() *DisconnectExtractor {
 	}
 }
 
-// HandlePlayerDisconnected processes a player disconnect event.
-func (e *DisconnectExtractor) HandlePlayerDisconnected(event events.PlayerDisconnected, roundIndex int, tick int, tickRate float64) {
+// HandlePlayerDisconnected processes a player disconnect event and returns
+// the reason family ("kick", "network", "user", "voluntary" or "other" -
+// see disconnectReasonFamily) and human-readable reason text it decoded,
+// so callers that need the kick/ban distinction (e.g. system chat log
+// generation) don't have to re-derive it from the raw event themselves.
+func (e *DisconnectExtractor) HandlePlayerDisconnected(event events.PlayerDisconnected, roundIndex int, tick int, tickRate float64) (reasonFamily string, reasonText string) {
 	player := event.Player
 	if player == nil {
-		return
+		return "", ""
 	}
 
 	steamID := getSteamID(player)
 	if steamID == nil {
-		return
+		return "", ""
 	}
 
 	steamIDStr := *steamID
@@ -62,20 +86,36 @@ func (e *DisconnectExtractor) HandlePlayerDisconnected(event events.PlayerDiscon
 	meta := make(map[string]interface{})
 
 	// Try to get disconnect reason from stored GenericGameEvent data first
-	// This is more reliable than trying to extract from PlayerDisconnected event
-	// Match by tick (player_disconnect GenericGameEvent fires just before PlayerDisconnected)
-	// Look for reasons within ±10 ticks to handle any small timing differences
+	// - this is more reliable than trying to extract from PlayerDisconnected
+	// event. StoreDisconnectReason is keyed by the resolved SteamID when the
+	// player_disconnect event's userid could be resolved (see
+	// internal/userid), so try that exact key first - it can't collide with
+	// another player disconnecting on the same tick, unlike the tick-window
+	// fallback below.
 	var reasonValue interface{}
 	foundReason := false
-	for storedKey, storedReason := range e.disconnectReasons {
-		if strings.HasPrefix(storedKey, fmt.Sprintf("tick-")) {
-			// Extract tick from key
+
+	exactKey := fmt.Sprintf("%s-%d", steamIDStr, tick)
+	if storedReason, ok := e.disconnectReasons[exactKey]; ok {
+		reasonValue = storedReason
+		delete(e.disconnectReasons, exactKey)
+		foundReason = true
+	}
+
+	// Fall back to the tick-window heuristic only for reasons stored under
+	// the "tick-N" key - i.e. player_disconnect events whose userid
+	// couldn't be resolved to a SteamID. Look for reasons within ±10 ticks
+	// to handle any small timing differences between player_disconnect and
+	// PlayerDisconnected.
+	if !foundReason {
+		for storedKey, storedReason := range e.disconnectReasons {
+			if !strings.HasPrefix(storedKey, "tick-") {
+				continue
+			}
 			var storedTick int
 			if _, err := fmt.Sscanf(storedKey, "tick-%d", &storedTick); err == nil {
-				// Match if within ±10 ticks
 				if storedTick >= tick-10 && storedTick <= tick+10 {
 					reasonValue = storedReason
-					// Clean up after use
 					delete(e.disconnectReasons, storedKey)
 					foundReason = true
 					break
@@ -91,9 +131,34 @@ func (e *DisconnectExtractor) HandlePlayerDisconnected(event events.PlayerDiscon
 
 	// Format the reason using our mapping function
 	reason := formatDisconnectReason(reasonValue)
+	reasonText = reason
 	meta["reason"] = reason
 	meta["disconnect_time"] = float64(tick) / tickRate // Time in seconds
 
+	// Record the signals ClassifyDisconnects needs, captured now while the
+	// live game state (score, recent damage) is still available - by the
+	// time the post-processing pass runs, all we have left is this event.
+	reasonCode := disconnectReasonCode(reasonValue)
+	reasonFamily = disconnectReasonFamily(reasonCode)
+	meta["reason_code"] = reasonCode
+	meta["reason_family"] = reasonFamily
+	meta["team"] = int(player.Team)
+
+	ownRoundsWon := e.teamRoundsWon[player.Team]
+	oppTeam := common.TeamCounterTerrorists
+	if player.Team == common.TeamCounterTerrorists {
+		oppTeam = common.TeamTerrorists
+	}
+	oppRoundsWon := e.teamRoundsWon[oppTeam]
+	meta["own_rounds_won"] = ownRoundsWon
+	meta["opp_rounds_won"] = oppRoundsWon
+
+	adverseOffsetTicks := -1
+	if lastTick, ok := e.lastAdverseTick[steamIDStr]; ok {
+		adverseOffsetTicks = tick - lastTick
+	}
+	meta["adverse_offset_ticks"] = adverseOffsetTicks
+
 	// Store as pending disconnect in case they reconnect
 	e.pendingDisconnects[steamIDStr] = &pendingDisconnect{
 		steamID:        steamIDStr,
@@ -115,6 +180,8 @@ func (e *DisconnectExtractor) HandlePlayerDisconnected(event events.PlayerDiscon
 		Confidence:    0.9,
 		MetaJSON:      &metaJSONStr,
 	})
+
+	return reasonFamily, reasonText
 }
 
 // HandlePlayerConnect processes a player connect event and checks if they reconnected.
@@ -192,12 +259,257 @@ func (e *DisconnectExtractor) ClearEvents() {
 	e.events = e.events[:0]
 }
 
+// HandlePlayerHurt records the tick at which a player most recently took
+// damage. It's the closest thing to a unified "something adverse just
+// happened to them" signal available without separate per-cause tracking,
+// since deaths, team-kills, and heavy chip damage all start as a PlayerHurt
+// against the victim.
+func (e *DisconnectExtractor) HandlePlayerHurt(event events.PlayerHurt, tick int) {
+	victim := event.Player
+	if victim == nil {
+		return
+	}
+
+	steamID := getSteamID(victim)
+	if steamID == nil {
+		return
+	}
+
+	if e.lastAdverseTick == nil {
+		e.lastAdverseTick = make(map[string]int)
+	}
+	e.lastAdverseTick[*steamID] = tick
+}
+
+// HandleRoundEnd records a round's winner, so a later disconnect can be
+// judged against the score margin at the moment it happened.
+func (e *DisconnectExtractor) HandleRoundEnd(winningTeam common.Team) {
+	if e.teamRoundsWon == nil {
+		e.teamRoundsWon = make(map[common.Team]int)
+	}
+	e.teamRoundsWon[winningTeam]++
+}
+
+// SetMatchContext records each player's final match kills/deaths - the one
+// classification input that isn't available at disconnect time, since
+// "bottom-3 K/D in the match" can only be judged once the match is over.
+// Callers should set this once, after the last round, before calling
+// ClassifyDisconnects.
+func (e *DisconnectExtractor) SetMatchContext(kills, deaths map[string]int) {
+	e.matchKills = kills
+	e.matchDeaths = deaths
+}
+
+// bottom3KD returns the set of steamIDs with the 3 lowest kill/death ratios
+// recorded via SetMatchContext. Players with zero deaths are scored by raw
+// kills, same convention as a KD ratio with deaths floored at 1.
+func (e *DisconnectExtractor) bottom3KD() map[string]bool {
+	type kdEntry struct {
+		steamID string
+		kd      float64
+	}
+
+	entries := make([]kdEntry, 0, len(e.matchKills))
+	for steamID, kills := range e.matchKills {
+		deaths := e.matchDeaths[steamID]
+		kd := float64(kills)
+		if deaths > 0 {
+			kd = float64(kills) / float64(deaths)
+		}
+		entries = append(entries, kdEntry{steamID: steamID, kd: kd})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].kd < entries[j].kd })
+
+	bottom := make(map[string]bool)
+	for i := 0; i < len(entries) && i < 3; i++ {
+		bottom[entries[i].steamID] = true
+	}
+	return bottom
+}
+
+// disconnectReconnectWindowRounds is how many rounds a player has to
+// reconnect within before ClassifyDisconnects stops treating the disconnect
+// as recoverable and scores it as a true departure.
+const disconnectReconnectWindowRounds = 3
+
+// disconnectAdverseWindowSeconds is how recently an adverse event (damage
+// taken) must have happened, relative to the disconnect, to plausibly have
+// triggered it.
+const disconnectAdverseWindowSeconds = 15.0
+
+// ClassifyDisconnects is a post-processing pass over every DISCONNECT event
+// recorded so far: it sorts each one that wasn't a quick reconnect into
+// RAGE_QUIT, TIMEOUT, KICK, ABANDON, TECHNICAL, or NORMAL_LEAVE and emits a
+// matching ABANDON event (distinct from DISCONNECT, severity scaled by how
+// many signals corroborate it) for anything but NORMAL_LEAVE. Must be called
+// once, after the last round has been processed and SetMatchContext has run.
+func (e *DisconnectExtractor) ClassifyDisconnects(tickRate float64) {
+	bottomKDSet := e.bottom3KD()
+	adverseWindowTicks := int(disconnectAdverseWindowSeconds * tickRate)
+
+	var abandonEvents []Event
+	for _, event := range e.events {
+		if event.Type != "DISCONNECT" || event.ActorSteamID == nil {
+			continue
+		}
+
+		var meta map[string]interface{}
+		if event.MetaJSON != nil {
+			json.Unmarshal([]byte(*event.MetaJSON), &meta)
+		}
+		if meta == nil {
+			continue
+		}
+
+		reconnected, _ := meta["reconnected"].(bool)
+		if reconnected {
+			if reconnectRound, ok := meta["reconnect_round"].(float64); ok {
+				if int(reconnectRound)-event.RoundIndex <= disconnectReconnectWindowRounds {
+					continue // reconnected quickly enough to count as a normal leave
+				}
+			}
+		}
+
+		reasonFamily, _ := meta["reason_family"].(string)
+		adverseOffsetTicks := -1
+		if v, ok := meta["adverse_offset_ticks"].(float64); ok {
+			adverseOffsetTicks = int(v)
+		}
+		ownRoundsWon := 0
+		if v, ok := meta["own_rounds_won"].(float64); ok {
+			ownRoundsWon = int(v)
+		}
+		oppRoundsWon := 0
+		if v, ok := meta["opp_rounds_won"].(float64); ok {
+			oppRoundsWon = int(v)
+		}
+
+		losingBadly := oppRoundsWon-ownRoundsWon >= 5
+		recentAdverse := adverseOffsetTicks >= 0 && adverseOffsetTicks <= adverseWindowTicks
+		bottomKD := bottomKDSet[*event.ActorSteamID]
+
+		var classification string
+		switch {
+		case reasonFamily == "kick":
+			classification = DisconnectClassKick
+		case reasonFamily == "network":
+			classification = DisconnectClassTimeout
+		case reasonFamily == "user", reasonFamily == "voluntary":
+			switch {
+			case recentAdverse && losingBadly && bottomKD:
+				classification = DisconnectClassRageQuit
+			case !reconnected:
+				classification = DisconnectClassAbandon
+			default:
+				classification = DisconnectClassNormalLeave
+			}
+		default:
+			classification = DisconnectClassTechnical
+		}
+
+		if classification == DisconnectClassNormalLeave {
+			continue
+		}
+
+		severity := 0.3
+		if losingBadly {
+			severity += 0.25
+		}
+		if bottomKD {
+			severity += 0.2
+		}
+		if recentAdverse {
+			severity += 0.25
+		}
+		severity = math.Min(1.0, severity)
+
+		confidence := 0.6
+		switch classification {
+		case DisconnectClassKick, DisconnectClassTimeout:
+			confidence = 0.9
+		case DisconnectClassRageQuit:
+			confidence = 0.85
+		}
+
+		abandonReasonCode := classifyDisconnectReason(classification)
+
+		abandonMeta := map[string]interface{}{
+			"classification":       classification,
+			"reason_code":          abandonReasonCode,
+			"reason":               DescribeReason(abandonReasonCode),
+			"reason_family":        reasonFamily,
+			"losing_badly":         losingBadly,
+			"bottom3_kd":           bottomKD,
+			"adverse_offset_ticks": adverseOffsetTicks,
+			"own_rounds_won":       ownRoundsWon,
+			"opp_rounds_won":       oppRoundsWon,
+			"reconnected":          reconnected,
+		}
+		abandonMetaJSON, _ := json.Marshal(abandonMeta)
+		abandonMetaJSONStr := string(abandonMetaJSON)
+
+		abandonEvents = append(abandonEvents, Event{
+			Type:         "ABANDON",
+			RoundIndex:   event.RoundIndex,
+			StartTick:    event.StartTick,
+			EndTick:      nil,
+			ActorSteamID: event.ActorSteamID,
+			Severity:     severity,
+			Confidence:   confidence,
+			MetaJSON:     &abandonMetaJSONStr,
+		})
+	}
+
+	e.events = append(e.events, abandonEvents...)
+}
+
 // StoreDisconnectReason stores a reason code from a GenericGameEvent for later use.
 func (e *DisconnectExtractor) StoreDisconnectReason(steamID string, tick int, reason interface{}) {
 	reasonKey := fmt.Sprintf("%s-%d", steamID, tick)
 	e.disconnectReasons[reasonKey] = reason
 }
 
+// UpgradeVoteKicked looks for an ABANDON event (produced by ClassifyDisconnects)
+// for steamID whose StartTick falls within voteKickTimeoutSeconds of voteTick,
+// and if found, reclassifies it as DisconnectClassVoteKicked with the
+// initiator's SteamID and vote tallies folded into its MetaJSON. Must be
+// called after ClassifyDisconnects, since it upgrades the ABANDON events that
+// pass produces rather than the raw DISCONNECT events.
+func (e *DisconnectExtractor) UpgradeVoteKicked(steamID string, voteTick int, tickRate float64, initiatorSteamID string, yesVotes, noVotes int) {
+	windowTicks := int(voteKickTimeoutSeconds * tickRate)
+
+	for i := range e.events {
+		event := &e.events[i]
+		if event.Type != "ABANDON" || event.ActorSteamID == nil || *event.ActorSteamID != steamID {
+			continue
+		}
+		if event.StartTick < voteTick || event.StartTick-voteTick > windowTicks {
+			continue
+		}
+
+		var meta map[string]interface{}
+		if event.MetaJSON != nil {
+			json.Unmarshal([]byte(*event.MetaJSON), &meta)
+		}
+		if meta == nil {
+			meta = map[string]interface{}{}
+		}
+		meta["classification"] = DisconnectClassVoteKicked
+		meta["reason_code"] = ReasonDisconnectVoteKicked
+		meta["reason"] = DescribeReason(ReasonDisconnectVoteKicked)
+		meta["vote_kicked"] = true
+		meta["initiator_steam_id"] = initiatorSteamID
+		meta["yes_votes"] = yesVotes
+		meta["no_votes"] = noVotes
+
+		metaJSON, _ := json.Marshal(meta)
+		metaJSONStr := string(metaJSON)
+		event.MetaJSON = &metaJSONStr
+		event.Confidence = math.Max(event.Confidence, 0.95)
+		return
+	}
+}
+
 // getDisconnectReasonFromEvent tries to extract reason from PlayerDisconnected event using reflection.
 func getDisconnectReasonFromEvent(event events.PlayerDisconnected) interface{} {
 	// Try to get reason from event using reflection
@@ -228,6 +540,46 @@ func getDisconnectReasonFromEvent(event events.PlayerDisconnected) interface{} {
 	return reasonValue
 }
 
+// disconnectReasonCode extracts the numeric ENetworkDisconnectionReason code
+// from a reason value of unknown shape, returning -1 if none could be
+// parsed. This mirrors the int/string handling in formatDisconnectReason,
+// but returns the raw code instead of a label - disconnectReasonFamily and
+// ClassifyDisconnects need the code, not the text.
+func disconnectReasonCode(reasonValue interface{}) int {
+	if code, ok := reasonValue.(int); ok {
+		return code
+	}
+	if reasonStr, ok := reasonValue.(string); ok {
+		if code, err := strconv.Atoi(strings.TrimSpace(reasonStr)); err == nil {
+			return code
+		}
+	}
+	return -1
+}
+
+// disconnectReasonFamily buckets a numeric ENetworkDisconnectionReason code
+// into the coarse families ClassifyDisconnects reasons about: explicit
+// server-side kicks, network/timeout failures, and user-initiated
+// disconnects (voluntary covers a couple of codes whose human-readable text
+// looks kick-like but which the protocol groups with ordinary disconnects).
+// Anything else is "other" - usually an engine-level or overflow code with
+// no clear voluntary/involuntary signal, which ClassifyDisconnects treats as
+// TECHNICAL.
+func disconnectReasonFamily(code int) string {
+	switch {
+	case code >= 140 && code <= 149:
+		return "kick"
+	case code == 4, code == 29, code == 72, code == 79, code == 80:
+		return "network"
+	case code == 2, code == 45:
+		return "user"
+	case code == 30, code == 39:
+		return "voluntary"
+	default:
+		return "other"
+	}
+}
+
 // formatDisconnectReason converts a reason code (int or string) to a human-readable string.
 // Based on CS2 disconnect reason codes from ENetworkDisconnectionReason enum.
 // Source: https://swiftlys2.net/docs/api/protobufdefinitions/enetworkdisconnectionreason/
@@ -0,0 +1,206 @@
+package extractors
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDisconnectReasonFamily(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		want string
+	}{
+		{"steam_logged_in_elsewhere", 14, "other"},
+		{"reject_bad_challenge", 140, "kick"},
+		{"reject_no_lobby", 141, "kick"},
+		{"reject_banned", 149, "kick"},
+		{"kicked_team_killing_outside_14x", 150, "other"},
+		{"connection_lost", 4, "network"},
+		{"unable_to_establish_connection", 29, "network"},
+		{"connect_request_timed_out", 72, "network"},
+		{"remote_timeout", 79, "network"},
+		{"remote_timeout_connecting", 80, "network"},
+		{"disconnected_by_user", 2, "user"},
+		{"user_command", 45, "user"},
+		{"disconnected", 30, "voluntary"},
+		{"kicked_generic_code", 39, "voluntary"},
+		{"overflow_unmapped", 5, "other"},
+		{"invalid_code", 0, "other"},
+		{"unknown_high_code", 999, "other"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := disconnectReasonFamily(tc.code)
+			if got != tc.want {
+				t.Errorf("disconnectReasonFamily(%d) = %q, want %q", tc.code, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDisconnectReasonCode(t *testing.T) {
+	if code := disconnectReasonCode(39); code != 39 {
+		t.Errorf("expected int reason 39 to parse as 39, got %d", code)
+	}
+	if code := disconnectReasonCode("39"); code != 39 {
+		t.Errorf("expected string reason \"39\" to parse as 39, got %d", code)
+	}
+	if code := disconnectReasonCode("Kicked"); code != -1 {
+		t.Errorf("expected non-numeric reason to parse as -1, got %d", code)
+	}
+	if code := disconnectReasonCode(nil); code != -1 {
+		t.Errorf("expected nil reason to parse as -1, got %d", code)
+	}
+}
+
+// newDisconnectEvent builds a DISCONNECT event the way HandlePlayerDisconnected
+// would, without needing a real demoinfocs event.
+func newDisconnectEvent(steamID string, roundIndex, startTick int, meta map[string]interface{}) Event {
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+	return Event{
+		Type:         "DISCONNECT",
+		RoundIndex:   roundIndex,
+		StartTick:    startTick,
+		ActorSteamID: &steamID,
+		Severity:     0.4,
+		Confidence:   0.9,
+		MetaJSON:     &metaJSONStr,
+	}
+}
+
+func TestClassifyDisconnectsKick(t *testing.T) {
+	e := NewDisconnectExtractor()
+	e.events = append(e.events, newDisconnectEvent("1", 5, 1000, map[string]interface{}{
+		"reason_family":        "kick",
+		"adverse_offset_ticks": -1,
+		"own_rounds_won":       2,
+		"opp_rounds_won":       10,
+	}))
+
+	e.ClassifyDisconnects(64.0)
+
+	abandon := findAbandonEvent(t, e, "1")
+	if classification := classificationOf(t, abandon); classification != DisconnectClassKick {
+		t.Errorf("expected %s, got %s", DisconnectClassKick, classification)
+	}
+}
+
+func TestClassifyDisconnectsTimeout(t *testing.T) {
+	e := NewDisconnectExtractor()
+	e.events = append(e.events, newDisconnectEvent("1", 5, 1000, map[string]interface{}{
+		"reason_family":        "network",
+		"adverse_offset_ticks": -1,
+		"own_rounds_won":       5,
+		"opp_rounds_won":       5,
+	}))
+
+	e.ClassifyDisconnects(64.0)
+
+	abandon := findAbandonEvent(t, e, "1")
+	if classification := classificationOf(t, abandon); classification != DisconnectClassTimeout {
+		t.Errorf("expected %s, got %s", DisconnectClassTimeout, classification)
+	}
+}
+
+func TestClassifyDisconnectsTechnical(t *testing.T) {
+	e := NewDisconnectExtractor()
+	e.events = append(e.events, newDisconnectEvent("1", 5, 1000, map[string]interface{}{
+		"reason_family":        "other",
+		"adverse_offset_ticks": -1,
+		"own_rounds_won":       5,
+		"opp_rounds_won":       5,
+	}))
+
+	e.ClassifyDisconnects(64.0)
+
+	abandon := findAbandonEvent(t, e, "1")
+	if classification := classificationOf(t, abandon); classification != DisconnectClassTechnical {
+		t.Errorf("expected %s, got %s", DisconnectClassTechnical, classification)
+	}
+}
+
+func TestClassifyDisconnectsRageQuit(t *testing.T) {
+	e := NewDisconnectExtractor()
+	e.matchKills = map[string]int{"1": 2, "2": 20, "3": 18, "4": 15, "5": 12}
+	e.matchDeaths = map[string]int{"1": 20, "2": 2, "3": 3, "4": 4, "5": 5}
+
+	// Disconnected 5 seconds (320 ticks at 64 tick) after taking damage,
+	// losing by 6 rounds, and has the worst K/D in the match.
+	e.events = append(e.events, newDisconnectEvent("1", 10, 64640, map[string]interface{}{
+		"reason_family":        "user",
+		"adverse_offset_ticks": 320,
+		"own_rounds_won":       2,
+		"opp_rounds_won":       8,
+	}))
+
+	e.ClassifyDisconnects(64.0)
+
+	abandon := findAbandonEvent(t, e, "1")
+	if classification := classificationOf(t, abandon); classification != DisconnectClassRageQuit {
+		t.Errorf("expected %s, got %s", DisconnectClassRageQuit, classification)
+	}
+}
+
+func TestClassifyDisconnectsAbandonWithoutRecentAdverse(t *testing.T) {
+	e := NewDisconnectExtractor()
+	e.events = append(e.events, newDisconnectEvent("1", 10, 64640, map[string]interface{}{
+		"reason_family":        "voluntary",
+		"adverse_offset_ticks": -1, // no recent damage recorded
+		"own_rounds_won":       2,
+		"opp_rounds_won":       8,
+	}))
+
+	e.ClassifyDisconnects(64.0)
+
+	abandon := findAbandonEvent(t, e, "1")
+	if classification := classificationOf(t, abandon); classification != DisconnectClassAbandon {
+		t.Errorf("expected %s, got %s", DisconnectClassAbandon, classification)
+	}
+}
+
+func TestClassifyDisconnectsNormalLeaveOnQuickReconnect(t *testing.T) {
+	e := NewDisconnectExtractor()
+	e.events = append(e.events, newDisconnectEvent("1", 10, 64640, map[string]interface{}{
+		"reason_family":        "user",
+		"adverse_offset_ticks": 50,
+		"own_rounds_won":       2,
+		"opp_rounds_won":       8,
+		"reconnected":          true,
+		"reconnect_round":      11,
+	}))
+
+	e.ClassifyDisconnects(64.0)
+
+	for _, event := range e.events {
+		if event.Type == "ABANDON" {
+			t.Fatalf("expected no ABANDON event for a quick reconnect, got one: %+v", event)
+		}
+	}
+}
+
+func findAbandonEvent(t *testing.T, e *DisconnectExtractor, steamID string) Event {
+	t.Helper()
+	for _, event := range e.events {
+		if event.Type == "ABANDON" && event.ActorSteamID != nil && *event.ActorSteamID == steamID {
+			return event
+		}
+	}
+	t.Fatalf("no ABANDON event found for steamID %s", steamID)
+	return Event{}
+}
+
+func classificationOf(t *testing.T, event Event) string {
+	t.Helper()
+	var meta map[string]interface{}
+	if event.MetaJSON == nil {
+		t.Fatalf("ABANDON event missing MetaJSON")
+	}
+	if err := json.Unmarshal([]byte(*event.MetaJSON), &meta); err != nil {
+		t.Fatalf("failed to unmarshal ABANDON meta: %v", err)
+	}
+	classification, _ := meta["classification"].(string)
+	return classification
+}
@@ -12,7 +12,10 @@ import (
 // EconomyExtractor detects economy griefing patterns based on player money and spending behavior.
 // It analyzes each player's buy decisions compared to their team to identify griefing.
 type EconomyExtractor struct {
-	events []Event
+	events        []Event
+	lastSnapshots map[common.Team][]PlayerEconomySnapshot
+	ledger        *economyLedger
+	lossStreak    map[common.Team]int
 }
 
 // NewEconomyExtractor creates a new economy griefing extractor.
@@ -22,6 +25,15 @@ func NewEconomyExtractor() *EconomyExtractor {
 	}
 }
 
+// LastSnapshots returns the per-team PlayerEconomySnapshots computed by the
+// most recent HandleFreezeTimeEnd call, keyed by team. Callers that need to
+// fold these into a cross-round aggregate (EconomyProfileExtractor) read
+// this right after HandleFreezeTimeEnd instead of re-deriving snapshots
+// from the game state themselves.
+func (e *EconomyExtractor) LastSnapshots() map[common.Team][]PlayerEconomySnapshot {
+	return e.lastSnapshots
+}
+
 // PlayerEconomySnapshot captures a player's economy state at freeze time end.
 type PlayerEconomySnapshot struct {
 	SteamID         string
@@ -31,7 +43,138 @@ type PlayerEconomySnapshot struct {
 	StartRoundMoney int // Money at round start (before buying)
 	PrimaryWeapon   string
 	AllWeapons      []string // All equipped weapons
-	EquipmentValue  int      // Total value of current equipment
+	EquipmentValue  int      // Buy-menu value of the loadout carried into the round (see LoadoutCost)
+	SpentOnLoadout  int      // Same value as EquipmentValue, named for call sites that compare it against MoneySpent rather than against team averages
+	HasArmor        bool
+	HasHelmet       bool
+	GrenadeCount    int
+	BuyCategory     string // One of the BuyCategory* constants, see classifyBuyCategory
+
+	// Ledger fields (see economy_ledger.go): MoneySpent includes weapons
+	// bought and then dropped for a teammate, which inflates how "invested"
+	// a player looks. SpentOnSelf backs that back out.
+	SpentOnSelf           int
+	SpentOnTeammates      int
+	ReceivedFromTeammates int
+}
+
+// BuyCategory is the canonical CS buy-round taxonomy: how much a player (or
+// a team, by majority vote) invested into a round's loadout. This replaces
+// ad-hoc percentage thresholds with the same small vocabulary coaches and
+// Source's BuyPresetManager use to talk about rounds.
+const (
+	BuyCategorySave    = "save"
+	BuyCategoryEco     = "eco"
+	BuyCategorySemiEco = "semi_eco"
+	BuyCategoryForce   = "force_buy"
+	BuyCategoryHalfBuy = "half_buy"
+	BuyCategoryFullBuy = "full_buy"
+)
+
+// buyCategoryRank gives each BuyCategory an ordinal position on a
+// save-to-full-buy investment scale, so callers can ask "how many tiers
+// apart are these two categories" instead of comparing raw percentages.
+var buyCategoryRank = map[string]int{
+	BuyCategorySave:    0,
+	BuyCategoryEco:     1,
+	BuyCategorySemiEco: 2,
+	BuyCategoryForce:   3,
+	BuyCategoryHalfBuy: 4,
+	BuyCategoryFullBuy: 5,
+}
+
+// classifyBuyCategory labels a single player-round with a BuyCategory.
+// Thresholds are checked most-specific-first so a round matches exactly one
+// category: full-buy and half-buy require a high enough loadout cost AND
+// the weapon/armor mix that implies; force-buy is spend-ratio driven
+// independent of loadout cost; save/eco/semi-eco then split on how little
+// was spent and whether a primary weapon was bought at all.
+func classifyBuyCategory(s PlayerEconomySnapshot) string {
+	hasPrimary := isRifle(s.PrimaryWeapon) || isSMG(s.PrimaryWeapon)
+
+	// Use SpentOnSelf rather than MoneySpent: money spent on a weapon that
+	// was then dropped for a teammate shouldn't count toward this player's
+	// own buy category.
+	spendPct := 0.0
+	if s.StartRoundMoney > 0 {
+		spendPct = float64(s.SpentOnSelf) / float64(s.StartRoundMoney)
+	}
+
+	switch {
+	case s.EquipmentValue >= 4700 && isRifle(s.PrimaryWeapon) && s.HasHelmet && s.GrenadeCount >= 1:
+		return BuyCategoryFullBuy
+	case s.EquipmentValue >= 2500 && s.EquipmentValue < 4700 && hasPrimary && s.HasArmor:
+		return BuyCategoryHalfBuy
+	case s.StartRoundMoney > 0 && s.StartRoundMoney < 4000 && spendPct >= 0.8:
+		return BuyCategoryForce
+	case s.SpentOnSelf < 500:
+		return BuyCategorySave
+	case s.SpentOnSelf < 1500 && !hasPrimary:
+		return BuyCategoryEco
+	case s.SpentOnSelf < 2500 && !hasPrimary:
+		return BuyCategorySemiEco
+	default:
+		return BuyCategoryHalfBuy
+	}
+}
+
+// moneyCap is CS2's per-player money cap.
+const moneyCap = 16000
+
+// lossBonusTiers is the CS2 consecutive-round-loss bonus, indexed by
+// loss streak (1st loss, 2nd loss, ...), capped at the 5th tier onward.
+var lossBonusTiers = []int{1400, 1900, 2400, 2900, 3400}
+
+// lossBonus returns the loss-bonus cash award for a team on the given
+// consecutive-loss streak (0 if they didn't just lose).
+func lossBonus(streak int) int {
+	if streak <= 0 {
+		return 0
+	}
+	if streak > len(lossBonusTiers) {
+		streak = len(lossBonusTiers)
+	}
+	return lossBonusTiers[streak-1]
+}
+
+// killRewardEstimate is a flat stand-in for "money this player is likely to
+// earn from kills next round" - without a per-player kill-rate model, a
+// single rifle-kill reward is a conservative floor on next round's income.
+const killRewardEstimate = 300
+
+// HandleRoundEnd updates each team's consecutive-loss streak: the winning
+// team's streak resets to zero, the losing team's increments. Callers must
+// invoke this once per round, after HandleFreezeTimeEnd has run for that
+// round, so loss-bonus projections in the *following* round reflect the
+// outcome of this one.
+func (e *EconomyExtractor) HandleRoundEnd(winningTeam common.Team) {
+	if e.lossStreak == nil {
+		e.lossStreak = make(map[common.Team]int)
+	}
+	for _, team := range []common.Team{common.TeamTerrorists, common.TeamCounterTerrorists} {
+		if team == winningTeam {
+			e.lossStreak[team] = 0
+		} else {
+			e.lossStreak[team]++
+		}
+	}
+}
+
+// TeamBuyCategory returns the majority BuyCategory across snapshots, the
+// same majority-vote shape detectEconomyGriefing already uses for
+// majorityWeaponType.
+func TeamBuyCategory(snapshots []PlayerEconomySnapshot) string {
+	counts := make(map[string]int)
+	best := BuyCategoryHalfBuy
+	bestCount := 0
+	for _, s := range snapshots {
+		counts[s.BuyCategory]++
+		if counts[s.BuyCategory] > bestCount {
+			bestCount = counts[s.BuyCategory]
+			best = s.BuyCategory
+		}
+	}
+	return best
 }
 
 // HandleFreezeTimeEnd analyzes player economies at the end of freeze time (after buy phase).
@@ -62,36 +205,57 @@ func (e *EconomyExtractor) HandleFreezeTimeEnd(roundIndex int, tick int, players
 		// Get primary weapon and equipment value
 		primaryWeapon := ""
 		var allWeapons []string
-		equipmentValue := 0
-		
+		grenadeCount := 0
+
 		for _, weapon := range player.Weapons() {
 			if weapon == nil {
 				continue
 			}
 			weaponName := weapon.String()
 			allWeapons = append(allWeapons, weaponName)
-			equipmentValue += weapon.AmmoReserve() // Rough approximation
-			
+
 			// Identify primary weapon
 			if weapon.Class() == common.EqClassRifle || weapon.Class() == common.EqClassSMG || weapon.Class() == common.EqClassHeavy {
 				primaryWeapon = weaponName
 			}
+			if weapon.Class() == common.EqClassGrenade {
+				grenadeCount++
+			}
+		}
+
+		loadoutCost := LoadoutCost(player)
+		moneySpent := player.MoneySpentThisRound()
+
+		ledgerEntry := e.ledgerForRound(roundIndex).entry(*steamID)
+		spentOnSelf := moneySpent - ledgerEntry.SpentOnTeammates
+		if spentOnSelf < 0 {
+			spentOnSelf = 0
 		}
 
 		snapshot := PlayerEconomySnapshot{
-			SteamID:         *steamID,
-			Team:            player.Team,
-			Money:           player.Money(),
-			MoneySpent:      player.MoneySpentThisRound(),
-			StartRoundMoney: player.Money() + player.MoneySpentThisRound(),
-			PrimaryWeapon:   primaryWeapon,
-			AllWeapons:      allWeapons,
-			EquipmentValue:  equipmentValue,
+			SteamID:               *steamID,
+			Team:                  player.Team,
+			Money:                 player.Money(),
+			MoneySpent:            moneySpent,
+			StartRoundMoney:       player.Money() + moneySpent,
+			PrimaryWeapon:         primaryWeapon,
+			AllWeapons:            allWeapons,
+			EquipmentValue:        loadoutCost,
+			SpentOnLoadout:        loadoutCost,
+			HasArmor:              player.Armor() > 0,
+			HasHelmet:             player.HasHelmet(),
+			GrenadeCount:          grenadeCount,
+			SpentOnSelf:           spentOnSelf,
+			SpentOnTeammates:      ledgerEntry.SpentOnTeammates,
+			ReceivedFromTeammates: ledgerEntry.ReceivedFromTeammates,
 		}
+		snapshot.BuyCategory = classifyBuyCategory(snapshot)
 
 		teamSnapshots[player.Team] = append(teamSnapshots[player.Team], snapshot)
 	}
 
+	e.lastSnapshots = teamSnapshots
+
 	// Run detection for each team
 	for team, snapshots := range teamSnapshots {
 		if len(snapshots) > 0 {
@@ -107,7 +271,7 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 	var totalSpent int
 	var avgStartMoney float64
 	var avgSpent float64
-	
+
 	// Count weapon types
 	rifleCount := 0
 	smgCount := 0
@@ -115,8 +279,8 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 
 	for _, s := range snapshots {
 		totalStartMoney += s.StartRoundMoney
-		totalSpent += s.MoneySpent
-		
+		totalSpent += s.SpentOnSelf
+
 		// Classify weapons
 		weapon := s.PrimaryWeapon
 		if isRifle(weapon) {
@@ -138,7 +302,7 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 		totalRemaining += s.Money
 	}
 	avgRemaining := float64(totalRemaining) / float64(len(snapshots))
-	
+
 	// Determine majority weapon type
 	majorityWeaponType := "other"
 	maxCount := 0
@@ -154,38 +318,28 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 		maxCount = pistolOnlyCount
 		majorityWeaponType = "pistol"
 	}
-	
+
+	teamCategory := TeamBuyCategory(snapshots)
+	teamRank := buyCategoryRank[teamCategory]
+
 	// Check each player for griefing behavior
 	for _, snapshot := range snapshots {
 		spendPct := 0.0
 		if snapshot.StartRoundMoney > 0 {
-			spendPct = float64(snapshot.MoneySpent) / float64(snapshot.StartRoundMoney)
+			spendPct = float64(snapshot.SpentOnSelf) / float64(snapshot.StartRoundMoney)
 		}
 
+		// A player who bought a rifle and dropped it to a teammate shouldn't be
+		// flagged for "not buying with the team" or "saving while rich" - they
+		// spent the money, just not on themselves.
+		generousDrop := snapshot.SpentOnTeammates >= 3000
+
 		// Detect griefing patterns
 		var isGriefing bool
 		var griefType string
 		var severity float64
 		var confidence float64
 
-		// Determine player's weapon type
-		playerWeaponType := "other"
-		if isRifle(snapshot.PrimaryWeapon) {
-			playerWeaponType = "rifle"
-		} else if isSMG(snapshot.PrimaryWeapon) {
-			playerWeaponType = "smg"
-		} else if isPistol(snapshot.PrimaryWeapon) {
-			playerWeaponType = "pistol"
-		}
-
-		// Weapon hierarchy values (higher is better)
-		weaponValue := map[string]int{
-			"rifle":  3,
-			"smg":    2,
-			"pistol": 1,
-			"other":  0,
-		}
-
 		// Calculate remaining money alignment with team average
 		remainingDiffPct := 0.0
 		if avgRemaining > 0 {
@@ -196,58 +350,68 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 		// If remaining money is within 15% of team average, they evened out the economy (likely legitimate)
 		evenedOut := avgRemaining > 0 && remainingDiffPct < 0.15
 
-		// Pattern 1: Equipment mismatch (bought WORSE weapon than team majority)
-		// Only flag if player bought a cheaper/worse weapon AND spent significantly less than team
-		playerValue := weaponValue[playerWeaponType]
-		majorityValue := weaponValue[majorityWeaponType]
-		spendDifference := teamSpendPct - spendPct
-		
-		if playerValue < majorityValue && playerWeaponType != "other" && majorityWeaponType != "other" &&
-			snapshot.StartRoundMoney > 3000 && snapshot.MoneySpent > 1500 && spendDifference > 0.25 && !evenedOut {
-			fmt.Fprintf(os.Stderr, "[ECONOMY]     ✓ Pattern 1: Equipment mismatch (%s %s when team majority is %s, $%d spent vs team avg $%d, diff %.1f%%)\n",
-				playerWeaponType, snapshot.PrimaryWeapon, majorityWeaponType, snapshot.MoneySpent, int(avgSpent), spendDifference*100.0)
-			isGriefing = true
-			griefType = "equipment_mismatch"
-			severity = 0.75
-			confidence = 0.85
-		}
-
-		// Pattern 2: Not buying with team (accounting for saved equipment)
 		// Consider "good weapon" if they have a rifle/SMG as primary OR if they have a saved rifle/SMG (low spend + rifle/SMG in inventory)
 		hasGoodWeapon := isRifle(snapshot.PrimaryWeapon) || (isSMG(snapshot.PrimaryWeapon) && majorityWeaponType == "smg")
 		hasSavedWeapon := snapshot.MoneySpent < 1000 && hasRifleOrSMGInInventory(snapshot.AllWeapons)
 		if hasSavedWeapon {
 			hasGoodWeapon = true
 		}
-		spendDifference = teamSpendPct - spendPct
-		
-		if !isGriefing && !hasGoodWeapon && teamSpendPct > 0.4 && spendPct < 0.25 && snapshot.StartRoundMoney > 2000 && spendDifference > 0.25 && !evenedOut {
-			fmt.Fprintf(os.Stderr, "[ECONOMY]     ✓ Pattern 2: Not buying with team (team %.1f%%, player %.1f%%, diff %.1f%%, remaining diff %.1f%%, no proper weapon)\n",
-				teamSpendPct*100.0, spendPct*100.0, spendDifference*100.0, remainingDiffPct*100.0)
+
+		playerRank := buyCategoryRank[snapshot.BuyCategory]
+		tierDiff := teamRank - playerRank // positive: player under-invested relative to the team
+
+		// Pattern 1: Equipment mismatch - player's buy category trails the team's by
+		// at least 2 tiers but they still bought something (force-buy or better on
+		// their own scale), so this isn't a full eco - it's a deliberate downgrade.
+		if tierDiff >= 2 && playerRank >= buyCategoryRank[BuyCategoryForce] && snapshot.MoneySpent > 1500 && !evenedOut {
+			fmt.Fprintf(os.Stderr, "[ECONOMY]     ✓ Pattern 1: Equipment mismatch (%s buy vs team %s, %d tiers below, $%d spent vs team avg $%d)\n",
+				snapshot.BuyCategory, teamCategory, tierDiff, snapshot.MoneySpent, int(avgSpent))
+			isGriefing = true
+			griefType = "equipment_mismatch"
+			severity = 0.75
+			confidence = 0.85
+		}
+
+		// Pattern 2: Not buying with team - player's category trails the team's by
+		// at least 2 tiers and they have no usable weapon (not even a saved one).
+		if !isGriefing && !generousDrop && tierDiff >= 2 && !hasGoodWeapon && snapshot.StartRoundMoney > 2000 && !evenedOut {
+			fmt.Fprintf(os.Stderr, "[ECONOMY]     ✓ Pattern 2: Not buying with team (%s buy vs team %s, %d tiers below, remaining diff %.1f%%, no proper weapon)\n",
+				snapshot.BuyCategory, teamCategory, tierDiff, remainingDiffPct*100.0)
 			isGriefing = true
 			griefType = "no_buy_with_team"
 			potentialSpend := float64(snapshot.StartRoundMoney)
 			severity = math.Min(1.0, potentialSpend/5000.0) * 0.7
-			deviation := spendDifference
-			confidence = math.Min(1.0, deviation*2.0) * 0.8
+			confidence = math.Min(1.0, float64(tierDiff)/4.0) * 0.8
 		}
 
-		// Pattern 3: Excessive saving (keeping >$6000 while team has low money)
-		if !isGriefing && snapshot.Money > 6000 && avgStartMoney < 5000 && teamSpendPct > 0.25 {
-			fmt.Fprintf(os.Stderr, "[ECONOMY]     ✓ Pattern 3: Excessive saving ($%d remaining, team avg $%d)\n",
-				snapshot.Money, int(avgStartMoney))
+		// Pattern 3: Excessive saving (keeping >$6000 while team has low money).
+		// A save is only griefing if it isn't actually justified by next
+		// round's economy: project what the player will walk in with next
+		// round (current money + the loss bonus they'd earn by losing again +
+		// a conservative kill-reward floor) and compare against moneyCap -
+		// saving past the cap, or saving deep into a loss streak, is the
+		// textbook correct play, not griefing.
+		lossStreak := e.lossStreak[team]
+		projectedMoney := snapshot.Money + lossBonus(lossStreak) + killRewardEstimate
+		saveLegitimacy := math.Max(0, math.Min(1.0, float64(projectedMoney)/float64(moneyCap)))
+		saveJustified := projectedMoney > moneyCap+1500 || (projectedMoney <= moneyCap && lossStreak >= 3)
+		if !isGriefing && !saveJustified && snapshot.Money > 6000 && avgStartMoney < 5000 && teamSpendPct > 0.25 {
+			fmt.Fprintf(os.Stderr, "[ECONOMY]     ✓ Pattern 3: Excessive saving ($%d remaining, team avg $%d, projected next round $%d, loss streak %d)\n",
+				snapshot.Money, int(avgStartMoney), projectedMoney, lossStreak)
 			isGriefing = true
 			griefType = "excessive_saving"
 			excessMoney := float64(snapshot.Money - 3000)
 			severity = math.Min(1.0, excessMoney/10000.0) * 0.6
 			teamPoverty := 1.0 - (avgStartMoney / 8000.0)
-			confidence = math.Min(1.0, teamPoverty*1.5) * 0.7
+			confidence = math.Min(1.0, teamPoverty*1.5) * 0.7 * (1.0 - saveLegitimacy)
 		}
 
-		// Pattern 4: Full save with high money
-		if !isGriefing && snapshot.StartRoundMoney > 8000 && snapshot.MoneySpent < 800 && teamSpendPct > 0.5 && !hasGoodWeapon {
-			fmt.Fprintf(os.Stderr, "[ECONOMY]     ✓ Pattern 4: Full save with high money ($%d start, $%d spent, team %.1f%%)\n",
-				snapshot.StartRoundMoney, snapshot.MoneySpent, teamSpendPct*100.0)
+		// Pattern 4: Full save with high money - player's category is the bottom
+		// tier (save) while the team bought force-buy or better.
+		if !isGriefing && !generousDrop && snapshot.BuyCategory == BuyCategorySave && teamRank >= buyCategoryRank[BuyCategoryForce] &&
+			snapshot.StartRoundMoney > 8000 && !hasGoodWeapon {
+			fmt.Fprintf(os.Stderr, "[ECONOMY]     ✓ Pattern 4: Full save with high money ($%d start, %s buy vs team %s)\n",
+				snapshot.StartRoundMoney, snapshot.BuyCategory, teamCategory)
 			isGriefing = true
 			griefType = "full_save_high_money"
 			severity = 0.8
@@ -257,7 +421,7 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 		if isGriefing {
 			fmt.Fprintf(os.Stderr, "[ECONOMY]     → DETECTED: %s (severity=%.2f, confidence=%.2f)\n",
 				griefType, severity, confidence)
-			
+
 			// Determine which weapons were likely bought vs saved
 			weaponDetails := make([]map[string]interface{}, 0)
 			for _, weapon := range snapshot.AllWeapons {
@@ -265,7 +429,7 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 				if !isRelevantWeapon(weapon) {
 					continue
 				}
-				
+
 				// Estimate if weapon was bought or saved based on spend
 				// High spend (>$2000) = likely bought expensive weapons
 				// Low spend (<$800) = likely saved weapons
@@ -279,13 +443,13 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 				} else {
 					estimatedPurchase = "likely_bought"
 				}
-				
+
 				weaponDetails = append(weaponDetails, map[string]interface{}{
-					"name":      weapon,
-					"purchase":  estimatedPurchase,
+					"name":     weapon,
+					"purchase": estimatedPurchase,
 				})
 			}
-			
+
 			// Include other team members' weapons
 			otherPlayers := make([]map[string]interface{}, 0)
 			for _, s := range snapshots {
@@ -304,22 +468,34 @@ func (e *EconomyExtractor) detectEconomyGriefing(roundIndex int, tick int, team
 					})
 				}
 			}
-			
+
+			reasonCode := classifyEconomyGriefReason(griefType)
+
 			meta := map[string]interface{}{
-				"grief_type":           griefType,
-				"start_money":          snapshot.StartRoundMoney,
-				"money_spent":          snapshot.MoneySpent,
-				"remaining_money":      snapshot.Money,
-				"spend_pct":            spendPct * 100.0,
-				"team_avg_spend":       avgSpent,
-				"team_avg_money":       avgStartMoney,
-				"team_avg_remaining":   avgRemaining,
-				"team_spend_pct":       teamSpendPct * 100.0,
-				"primary_weapon":       snapshot.PrimaryWeapon,
-				"all_weapons":          snapshot.AllWeapons,
-				"weapon_details":       weaponDetails,
-				"majority_weapon_type": majorityWeaponType,
-				"other_players":        otherPlayers,
+				"grief_type":                 griefType,
+				"reason_code":                reasonCode,
+				"reason":                     DescribeReason(reasonCode),
+				"start_money":                snapshot.StartRoundMoney,
+				"money_spent":                snapshot.MoneySpent,
+				"remaining_money":            snapshot.Money,
+				"spend_pct":                  spendPct * 100.0,
+				"team_avg_spend":             avgSpent,
+				"team_avg_money":             avgStartMoney,
+				"team_avg_remaining":         avgRemaining,
+				"team_spend_pct":             teamSpendPct * 100.0,
+				"primary_weapon":             snapshot.PrimaryWeapon,
+				"all_weapons":                snapshot.AllWeapons,
+				"weapon_details":             weaponDetails,
+				"majority_weapon_type":       majorityWeaponType,
+				"other_players":              otherPlayers,
+				"buy_category":               snapshot.BuyCategory,
+				"team_buy_category":          teamCategory,
+				"spent_on_self":              snapshot.SpentOnSelf,
+				"spent_on_teammates":         snapshot.SpentOnTeammates,
+				"received_from_teammates":    snapshot.ReceivedFromTeammates,
+				"loss_streak":                lossStreak,
+				"projected_next_round_money": projectedMoney,
+				"save_legitimacy":            saveLegitimacy,
 			}
 
 			metaJSON, _ := json.Marshal(meta)
@@ -395,11 +571,11 @@ func hasRifleOrSMGInInventory(weapons []string) bool {
 func isRelevantWeapon(weapon string) bool {
 	// Exclude C4, starter pistols, and knife
 	excluded := map[string]bool{
-		"C4":        true,
-		"Knife":     true,
-		"Glock-18":  true,
-		"USP-S":     true,
-		"P2000":     true,
+		"C4":       true,
+		"Knife":    true,
+		"Glock-18": true,
+		"USP-S":    true,
+		"P2000":    true,
 	}
 	return !excluded[weapon] && weapon != ""
 }
@@ -0,0 +1,132 @@
+package extractors
+
+import (
+	common "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/common"
+	events "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/events"
+)
+
+// economyLedgerEntry tracks, for one player in one round, how much of
+// player.MoneySpentThisRound() actually stayed with them versus was handed
+// to a teammate, and how much value they received the same way. This lets
+// detectEconomyGriefing judge a player by what they kept, not by what
+// MoneySpentThisRound reports (which includes weapons bought and then
+// dropped for someone else).
+type economyLedgerEntry struct {
+	SpentOnTeammates      int // buy-menu value of weapons this player bought, dropped, and a teammate picked up
+	ReceivedFromTeammates int // buy-menu value of weapons this player picked up after a teammate dropped them
+}
+
+// pendingDrop records an ItemDrop waiting to be matched against a later
+// ItemPickup of the same physical weapon instance.
+type pendingDrop struct {
+	droppedBy string
+	team      common.Team
+	eqType    common.EquipmentType
+}
+
+// economyLedger attributes dropped/picked-up weapons to the player who
+// originally paid for them, keyed by round index and then by the weapon's
+// UniqueID2 (stable per physical weapon instance across drop/pickup).
+type economyLedger struct {
+	roundIndex int
+	pending    map[string]pendingDrop         // key: weapon UniqueID2 string
+	entries    map[string]*economyLedgerEntry // key: steamID, scoped to roundIndex
+}
+
+func newEconomyLedger() *economyLedger {
+	return &economyLedger{
+		pending: make(map[string]pendingDrop),
+		entries: make(map[string]*economyLedgerEntry),
+	}
+}
+
+// resetForRound clears per-round state. Any drop that was never picked up
+// (or only picked back up by the same player) doesn't carry attribution
+// into the next round.
+func (l *economyLedger) resetForRound(roundIndex int) {
+	l.roundIndex = roundIndex
+	l.pending = make(map[string]pendingDrop)
+	l.entries = make(map[string]*economyLedgerEntry)
+}
+
+func (l *economyLedger) entry(steamID string) *economyLedgerEntry {
+	e, ok := l.entries[steamID]
+	if !ok {
+		e = &economyLedgerEntry{}
+		l.entries[steamID] = e
+	}
+	return e
+}
+
+// handleDrop records that steamID dropped weapon, so a later pickup by a
+// teammate can be attributed back to them.
+func (l *economyLedger) handleDrop(steamID string, team common.Team, weapon *common.Equipment) {
+	if weapon == nil {
+		return
+	}
+	l.pending[weapon.UniqueID2().String()] = pendingDrop{droppedBy: steamID, team: team, eqType: weapon.Type}
+}
+
+// handlePickup checks whether weapon is a drop this ledger is tracking; if
+// a same-team player other than the original owner picks it up, the
+// buy-menu price is moved from the dropper's SpentOnTeammates to the
+// picker's ReceivedFromTeammates. Self pickups (retrieving your own drop)
+// and enemy pickups are not attributed.
+func (l *economyLedger) handlePickup(steamID string, team common.Team, weapon *common.Equipment) {
+	if weapon == nil {
+		return
+	}
+
+	key := weapon.UniqueID2().String()
+	drop, ok := l.pending[key]
+	if !ok {
+		return
+	}
+	delete(l.pending, key)
+
+	if drop.droppedBy == steamID || drop.team != team {
+		return
+	}
+
+	price := weaponPrice(drop.eqType)
+	l.entry(drop.droppedBy).SpentOnTeammates += price
+	l.entry(steamID).ReceivedFromTeammates += price
+}
+
+// HandleItemDrop should be wired to the demoinfocs ItemDrop event.
+func (e *EconomyExtractor) HandleItemDrop(event events.ItemDrop, roundIndex int) {
+	if event.Player == nil {
+		return
+	}
+	e.ledgerForRound(roundIndex).handleDrop(steamIDString(event.Player), event.Player.Team, event.Weapon)
+}
+
+// HandleItemPickup should be wired to the demoinfocs ItemPickup event.
+func (e *EconomyExtractor) HandleItemPickup(event events.ItemPickup, roundIndex int) {
+	if event.Player == nil {
+		return
+	}
+	e.ledgerForRound(roundIndex).handlePickup(steamIDString(event.Player), event.Player.Team, event.Weapon)
+}
+
+// ledgerForRound returns e's ledger, resetting it if roundIndex has moved on
+// since the last drop/pickup event.
+func (e *EconomyExtractor) ledgerForRound(roundIndex int) *economyLedger {
+	if e.ledger == nil {
+		e.ledger = newEconomyLedger()
+		e.ledger.resetForRound(roundIndex)
+	} else if e.ledger.roundIndex != roundIndex {
+		e.ledger.resetForRound(roundIndex)
+	}
+	return e.ledger
+}
+
+// steamIDString is the same SteamID64-to-string conversion getSteamID uses,
+// without the nil-player guard (callers here already checked).
+func steamIDString(player *common.Player) string {
+	id := getSteamID(player)
+	if id == nil {
+		return ""
+	}
+	return *id
+}
@@ -0,0 +1,184 @@
+package extractors
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// playerEconRoundSample is one round's worth of inputs into a player's
+// economic-personality aggregate: enough to compute aggression and to
+// tell overbuy/underbuy/team-alignment apart from a bare BuyCategory.
+type playerEconRoundSample struct {
+	aggression   float64 // 0-100, this round's aggression score
+	buyCategory  string
+	teamCategory string
+	isForceBuy   bool
+	isOverbuy    bool // bought rifle/SMG while team ecoed
+	isUnderbuy   bool // ecoed while team full-bought
+	matchesTeam  bool
+}
+
+// EconomyProfileExtractor is a sibling of EconomyExtractor: instead of
+// flagging individual suspicious rounds, it aggregates every round's buy
+// behavior per player into a personality vector, recency-weighted so a
+// player's current habits matter more than how they opened the demo. This
+// gives reviewers a baseline to separate habitual savers/aggressive
+// players from players who are actually griefing teammates in
+// detectEconomyGriefing.
+type EconomyProfileExtractor struct {
+	events  []Event
+	samples map[string][]playerEconRoundSample // key: steamID
+}
+
+// NewEconomyProfileExtractor creates a new economy-personality extractor.
+func NewEconomyProfileExtractor() *EconomyProfileExtractor {
+	return &EconomyProfileExtractor{
+		events:  make([]Event, 0),
+		samples: make(map[string][]playerEconRoundSample),
+	}
+}
+
+// econRecencyDecay controls the exponential recency weighting used when
+// averaging per-round samples: weight(i) = econRecencyDecay^(n-1-i) for the
+// i-th of n samples in chronological order, so the most recent round has
+// weight 1 and earlier rounds decay geometrically.
+const econRecencyDecay = 0.92
+
+// RecordRound folds one player's round-end economy snapshot into their
+// running profile. It must be called once per player per round, after
+// EconomyExtractor.HandleFreezeTimeEnd has classified snapshot.BuyCategory,
+// with teamCategory the majority buy category EconomyExtractor computed for
+// that player's team that round.
+func (e *EconomyProfileExtractor) RecordRound(snapshot PlayerEconomySnapshot, teamCategory string) {
+	playerRank := buyCategoryRank[snapshot.BuyCategory]
+	teamRank := buyCategoryRank[teamCategory]
+
+	spendPct := 0.0
+	if snapshot.StartRoundMoney > 0 {
+		spendPct = float64(snapshot.MoneySpent) / float64(snapshot.StartRoundMoney)
+	}
+
+	boughtUtility := snapshot.HasArmor && snapshot.GrenadeCount > 0
+	boughtHeavy := snapshot.PrimaryWeapon == "AWP" || snapshot.PrimaryWeapon == "M249" || snapshot.PrimaryWeapon == "Negev"
+
+	aggression := spendPct * 60.0
+	if boughtUtility {
+		aggression += 25.0
+	}
+	if boughtHeavy {
+		aggression += 15.0
+	}
+	aggression = math.Max(0, math.Min(100, aggression))
+
+	hasPrimary := isRifle(snapshot.PrimaryWeapon) || isSMG(snapshot.PrimaryWeapon)
+
+	sample := playerEconRoundSample{
+		aggression:   aggression,
+		buyCategory:  snapshot.BuyCategory,
+		teamCategory: teamCategory,
+		isForceBuy:   snapshot.BuyCategory == BuyCategoryForce,
+		isOverbuy:    hasPrimary && (teamCategory == BuyCategorySave || teamCategory == BuyCategoryEco),
+		isUnderbuy:   !hasPrimary && teamCategory == BuyCategoryFullBuy,
+		matchesTeam:  playerRank == teamRank,
+	}
+
+	e.samples[snapshot.SteamID] = append(e.samples[snapshot.SteamID], sample)
+}
+
+// recencyWeightedMean averages a []float64 using econRecencyDecay so later
+// entries count more than earlier ones.
+func recencyWeightedMean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var weightedSum, weightSum float64
+	weight := 1.0
+	for i := len(values) - 1; i >= 0; i-- {
+		weightedSum += values[i] * weight
+		weightSum += weight
+		weight *= econRecencyDecay
+	}
+	return weightedSum / weightSum
+}
+
+// recencyWeightedFrequency is recencyWeightedMean over a bool slice
+// converted to 0/1, i.e. "how often, weighted toward recent rounds".
+func recencyWeightedFrequency(flags []bool) float64 {
+	values := make([]float64, len(flags))
+	for i, f := range flags {
+		if f {
+			values[i] = 1
+		}
+	}
+	return recencyWeightedMean(values)
+}
+
+// Finalize computes one PLAYER_ECON_PROFILE event per player seen across
+// the whole demo. It must be called once, after the last round has been
+// recorded via RecordRound.
+func (e *EconomyProfileExtractor) Finalize() {
+	for steamID, samples := range e.samples {
+		if len(samples) == 0 {
+			continue
+		}
+
+		aggressionValues := make([]float64, len(samples))
+		forceBuy := make([]bool, len(samples))
+		overbuy := make([]bool, len(samples))
+		underbuy := make([]bool, len(samples))
+		teamAligned := make([]bool, len(samples))
+		saveCount := 0
+
+		for i, s := range samples {
+			aggressionValues[i] = s.aggression
+			forceBuy[i] = s.isForceBuy
+			overbuy[i] = s.isOverbuy
+			underbuy[i] = s.isUnderbuy
+			teamAligned[i] = s.matchesTeam
+			if s.buyCategory == BuyCategorySave {
+				saveCount++
+			}
+		}
+
+		aggression := recencyWeightedMean(aggressionValues)
+		saveTendency := 100.0 * float64(saveCount) / float64(len(samples))
+		forceBuyFrequency := recencyWeightedFrequency(forceBuy)
+		overbuyFrequency := recencyWeightedFrequency(overbuy)
+		underbuyFrequency := recencyWeightedFrequency(underbuy)
+		teamAlignment := recencyWeightedFrequency(teamAligned)
+
+		meta := map[string]interface{}{
+			"aggression":          aggression,
+			"save_tendency":       saveTendency,
+			"force_buy_frequency": forceBuyFrequency,
+			"overbuy_frequency":   overbuyFrequency,
+			"underbuy_frequency":  underbuyFrequency,
+			"team_alignment":      teamAlignment,
+			"rounds_observed":     len(samples),
+		}
+		metaJSON, _ := json.Marshal(meta)
+		metaJSONStr := string(metaJSON)
+
+		id := steamID
+		e.events = append(e.events, Event{
+			Type:         "PLAYER_ECON_PROFILE",
+			RoundIndex:   -1,
+			StartTick:    0,
+			ActorSteamID: &id,
+			Severity:     0,
+			Confidence:   1,
+			MetaJSON:     &metaJSONStr,
+		})
+	}
+}
+
+// GetEvents returns all PLAYER_ECON_PROFILE events produced by Finalize.
+func (e *EconomyProfileExtractor) GetEvents() []Event {
+	return e.events
+}
+
+// ClearEvents clears the accumulated events.
+func (e *EconomyProfileExtractor) ClearEvents() {
+	e.events = e.events[:0]
+}
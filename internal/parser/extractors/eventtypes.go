@@ -0,0 +1,143 @@
+package extractors
+
+import "sort"
+
+// EventTypeInfo documents one Event.Type value this package can emit: a
+// human-readable description, the shape callers can expect in MetaJSON, and
+// the severity this extractor typically assigns it. --list-events dumps
+// this table as JSON for front-end consumption; CompileFilter validates bare
+// type names in a filter expression against it.
+type EventTypeInfo struct {
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	MetaSchema      map[string]string `json:"meta_schema,omitempty"`
+	DefaultSeverity string            `json:"default_severity"`
+}
+
+// eventTypeRegistry is the source of truth for every Event.Type this
+// package's extractors produce. Adding a new extractor should add an entry
+// here too, or it won't show up in --list-events and can't be named in a
+// --events filter expression.
+var eventTypeRegistry = []EventTypeInfo{
+	{
+		Name:            "TEAM_KILL",
+		Description:     "A player killed a teammate.",
+		MetaSchema:      map[string]string{"weapon": "string", "headshot": "bool", "distance": "float64, optional", "reason_code": "int, see extractors.ReasonCode", "reason": "string, extractors.DescribeReason(reason_code)"},
+		DefaultSeverity: "0.8, fixed; kills near round end or of an already-disconnected victim are not reported",
+	},
+	{
+		Name:            "TEAM_DAMAGE",
+		Description:     "A player's damage to teammates, merged into one event per (round, actor, victim, weapon), coalescing hits within a 2-second window.",
+		MetaSchema:      map[string]string{"dmg_health": "int", "dmg_armor": "int", "total_damage": "int", "hit_count": "int", "is_utility": "bool", "cause": "string, one of DIRECT_FIRE/GRENADE_SPLASH/MOLOTOV_BURN/C4_EXPLOSION", "hitgroups": "map[string]int, optional", "weapon": "string, optional", "reason_code": "int, see extractors.ReasonCode", "reason": "string, extractors.DescribeReason(reason_code)"},
+		DefaultSeverity: "0.0-1.0, scaled by total damage dealt",
+	},
+	{
+		Name:            "TEAM_FLASH",
+		Description:     "A player flashed a teammate, merged into one event per (round, actor, victim), coalescing flashes within DefaultAccumulatorWindowTicks of each other.",
+		MetaSchema:      map[string]string{"blind_duration": "float64 (seconds), average across merged flashes", "total_blind_seconds": "float64, summed across merged flashes", "hit_count": "int", "reason_code": "int, see extractors.ReasonCode", "reason": "string, extractors.DescribeReason(reason_code)"},
+		DefaultSeverity: "0.0-1.0, scaled by total blind seconds",
+	},
+	{
+		Name:            "FRIENDLY_FIRE_INCIDENT",
+		Description:     "A player's PlayerHurt/PlayerFlashed/Kill sub-events against one teammate, merged into one event per (round, actor, victim) when gaps between them stay within MergeWindow. Runs alongside, not in place of, TEAM_DAMAGE/TEAM_FLASH/TEAM_KILL.",
+		MetaSchema:      map[string]string{"dmg_health": "int", "dmg_armor": "int", "flash_seconds": "float64", "killed_bool": "bool", "is_utility": "bool, true if any sub-event used a grenade-class weapon (common.EqClassGrenade)", "weapons": "[]string, optional, distinct weapons involved", "hitgroups": "map[string]int, optional", "kill_weapon": "string, optional, set when killed_bool is true"},
+		DefaultSeverity: "0.0-1.0, see FriendlyFireSeverityFunc/DefaultFriendlyFireSeverity; 1.0 if killed_bool",
+	},
+	{
+		Name:        "DISCONNECT",
+		Description: "A player disconnected, possibly adversely (e.g. mid-round while losing).",
+		MetaSchema:  map[string]string{"reason": "string", "reason_code": "int", "reason_family": "string", "team": "int", "own_rounds_won": "int", "opp_rounds_won": "int", "adverse_offset_ticks": "int, optional", "reconnected": "bool, optional", "reconnect_time": "float64, optional", "reconnect_round": "int, optional", "disconnect_duration": "float64, optional"},
+		// Note: DISCONNECT's reason/reason_code describe the raw
+		// ENetworkDisconnectionReason (see formatDisconnectReason), not the
+		// extractors.ReasonCode vocabulary - ABANDON's reason/reason_code
+		// below are the ones that use extractors.ReasonCode.
+		DefaultSeverity: "0.0-1.0, scaled by how adverse the timing was",
+	},
+	{
+		Name:            "ABANDON",
+		Description:     "A player disconnected and never reconnected for the rest of the match, or was vote-kicked.",
+		MetaSchema:      map[string]string{"classification": "string", "reason_code": "int, see extractors.ReasonCode", "reason": "string, extractors.DescribeReason(reason_code)", "vote_kicked": "bool, optional", "initiator_steam_id": "string, optional", "yes_votes": "int, optional", "no_votes": "int, optional"},
+		DefaultSeverity: "1.0",
+	},
+	{
+		Name:            "AFK_STILLNESS",
+		Description:     "A player didn't move for an extended period after freeze time ended.",
+		MetaSchema:      map[string]string{"seconds": "float64", "afkDuration": "float64", "start_tick": "int", "end_tick": "int", "state": "string (\"AFK\" or \"AFK_DIED\")", "endedBy": "string, \"MOVED\"/\"DIED\"/\"ROUND_END\"/\"ACTIVITY\"", "diedWhileAFK": "bool", "detectionMethod": "string, \"no_movement\" or \"loitering\"", "timeToFirstMovement": "float64, optional", "activity_kind": "string, see extractors.ActivityKind, set when endedBy is \"ACTIVITY\"", "reason_code": "int, see extractors.ReasonCode", "reason": "string, extractors.DescribeReason(reason_code)"},
+		DefaultSeverity: "1.0",
+	},
+	{
+		Name:            "AFK_SUSPECTED",
+		Description:     "Early warning fired once an in-progress AFK episode reaches 50% of AFKExtractor's minAFKSeconds threshold, before AFK_STILLNESS/AFK_CONFIRMED would report it.",
+		MetaSchema:      map[string]string{"start_tick": "int", "tick": "int", "seconds": "float64"},
+		DefaultSeverity: "0.0 (informational, not itself griefing)",
+	},
+	{
+		Name:            "AFK_CONFIRMED",
+		Description:     "Fired the tick an in-progress AFK episode reaches AFKExtractor's full minAFKSeconds threshold, ahead of the finalized AFK_STILLNESS interval reported once the episode ends.",
+		MetaSchema:      map[string]string{"start_tick": "int", "tick": "int", "seconds": "float64"},
+		DefaultSeverity: "0.0 (informational, not itself griefing)",
+	},
+	{
+		Name:            "AFK_RETURNED",
+		Description:     "A previously AFK_CONFIRMED player moved beyond movementThreshold again, or registered a qualifying activity (shot fired, utility thrown, bomb plant/defuse, damage dealt/received), ending the episode.",
+		MetaSchema:      map[string]string{"first_afk_tick": "int", "last_afk_tick": "int", "return_tick": "int", "total_afk_seconds": "float64", "activity_kind": "string, see extractors.ActivityKind, optional - set if activity rather than movement ended the episode"},
+		DefaultSeverity: "0.0 (informational, not itself griefing)",
+	},
+	{
+		Name:            "ECONOMY_GRIEF",
+		Description:     "A player made an economically harmful buy/drop decision (e.g. repeated force-buys into a lost economy).",
+		MetaSchema:      map[string]string{"grief_type": "string, one of equipment_mismatch/no_buy_with_team/excessive_saving/full_save_high_money", "reason_code": "int, see extractors.ReasonCode", "reason": "string, extractors.DescribeReason(reason_code)"},
+		DefaultSeverity: "0.0-1.0, scaled by the extractor's economy model",
+	},
+	{
+		Name:            "BODY_BLOCK",
+		Description:     "A player blocked a teammate's movement in a way that impeded them.",
+		MetaSchema:      map[string]string{"seconds": "float64", "reason_code": "int, see extractors.ReasonCode", "reason": "string, extractors.DescribeReason(reason_code)"},
+		DefaultSeverity: "0.0-1.0",
+	},
+	{
+		Name:            "PLAYER_GRIEFING_SCORE",
+		Description:     "Aggregated per-player, per-round griefing score rolled up from the other event types.",
+		DefaultSeverity: "0.0-1.0",
+	},
+	{
+		Name:            "TOXIC_CHAT",
+		Description:     "A chat message matched the toxicity/abuse wordlist.",
+		DefaultSeverity: "0.3, fixed for every wordlist match",
+	},
+	{
+		Name:            "VOTE_KICK_INITIATED",
+		Description:     "A player started a vote to kick another player.",
+		DefaultSeverity: "0.2, fixed",
+	},
+	{
+		Name:            "VOTE_KICK_PASSED",
+		Description:     "A vote-kick against a player passed.",
+		MetaSchema:      map[string]string{"initiator_steam_id": "string", "yes_votes": "int", "no_votes": "int"},
+		DefaultSeverity: "0.4, fixed",
+	},
+	{
+		Name:            "PLAYER_ECON_PROFILE",
+		Description:     "Per-player economic profile aggregated over the whole demo (one event per player, RoundIndex -1), recency-weighted toward later rounds.",
+		DefaultSeverity: "0.0 (informational, not itself griefing)",
+	},
+}
+
+// EventTypes returns a copy of the registry, sorted by Name, for
+// --list-events and for any caller that wants to enumerate known types.
+func EventTypes() []EventTypeInfo {
+	out := make([]EventTypeInfo, len(eventTypeRegistry))
+	copy(out, eventTypeRegistry)
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// IsKnownEventType reports whether name matches a registered Event.Type.
+func IsKnownEventType(name string) bool {
+	for _, info := range eventTypeRegistry {
+		if info.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package extractors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter is a compiled --events expression: an optional set of allowed
+// Event.Type names (an empty set means "any type"), AND'd together with any
+// number of field comparisons. Compile it once with CompileFilter and reuse
+// the result for every Event in the streaming loop.
+type Filter struct {
+	types       map[string]bool
+	comparisons []comparison
+}
+
+type compareOp int
+
+const (
+	opEQ compareOp = iota
+	opGE
+	opLE
+	opGT
+	opLT
+)
+
+type comparison struct {
+	field string
+	op    compareOp
+	num   float64
+	str   string
+	isNum bool
+}
+
+// CompileFilter parses a comma-separated --events expression, e.g.
+//
+//	TEAM_KILL,TEAM_DAMAGE,severity>=0.7,actor=STEAM_0:1:12345
+//
+// Bare, comma-separated tokens that match a known Event.Type name are OR'd
+// together into an allow-list; every other term is a "field<op>value"
+// comparison, AND'd against the type allow-list and every other comparison.
+// Supported fields are severity/confidence/round (numeric, ops >= <= > < =;
+// round compares against RoundIndex) and actor/victim (string equality
+// against ActorSteamID/VictimSteamID). An
+// empty expr compiles to a nil *Filter, which Match treats as "allow
+// everything".
+func CompileFilter(expr string) (*Filter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+
+	f := &Filter{types: make(map[string]bool)}
+	for _, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		if cmp, ok, err := parseComparison(term); err != nil {
+			return nil, err
+		} else if ok {
+			f.comparisons = append(f.comparisons, cmp)
+			continue
+		}
+
+		if !IsKnownEventType(term) {
+			return nil, fmt.Errorf("unknown event type %q in --events filter", term)
+		}
+		f.types[term] = true
+	}
+	return f, nil
+}
+
+// operators is ordered longest-prefix-first so ">=" isn't mis-split as ">".
+var operators = []struct {
+	text string
+	op   compareOp
+}{
+	{">=", opGE},
+	{"<=", opLE},
+	{">", opGT},
+	{"<", opLT},
+	{"=", opEQ},
+}
+
+// parseComparison splits term on its operator if it has one. A term with no
+// recognized operator is reported via ok=false so the caller treats it as a
+// bare event-type name instead.
+func parseComparison(term string) (comparison, bool, error) {
+	for _, candidate := range operators {
+		idx := strings.Index(term, candidate.text)
+		if idx <= 0 {
+			continue
+		}
+		field := strings.TrimSpace(term[:idx])
+		value := strings.TrimSpace(term[idx+len(candidate.text):])
+
+		switch field {
+		case "severity", "confidence":
+			num, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return comparison{}, false, fmt.Errorf("--events filter: invalid number %q for field %q", value, field)
+			}
+			return comparison{field: field, op: candidate.op, num: num, isNum: true}, true, nil
+		case "actor", "victim", "round":
+			if field == "round" {
+				num, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return comparison{}, false, fmt.Errorf("--events filter: invalid number %q for field %q", value, field)
+				}
+				return comparison{field: field, op: candidate.op, num: num, isNum: true}, true, nil
+			}
+			if candidate.op != opEQ {
+				return comparison{}, false, fmt.Errorf("--events filter: field %q only supports \"=\"", field)
+			}
+			return comparison{field: field, op: opEQ, str: value}, true, nil
+		default:
+			return comparison{}, false, fmt.Errorf("--events filter: unknown field %q", field)
+		}
+	}
+	return comparison{}, false, nil
+}
+
+// Match reports whether e satisfies f. A nil Filter matches everything.
+func (f *Filter) Match(e Event) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.types) > 0 && !f.types[e.Type] {
+		return false
+	}
+	for _, cmp := range f.comparisons {
+		if !cmp.matches(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparison) matches(e Event) bool {
+	switch c.field {
+	case "severity":
+		return compareFloat(e.Severity, c.op, c.num)
+	case "confidence":
+		return compareFloat(e.Confidence, c.op, c.num)
+	case "round":
+		return compareFloat(float64(e.RoundIndex), c.op, c.num)
+	case "actor":
+		return e.ActorSteamID != nil && *e.ActorSteamID == c.str
+	case "victim":
+		return e.VictimSteamID != nil && *e.VictimSteamID == c.str
+	default:
+		return false
+	}
+}
+
+func compareFloat(actual float64, op compareOp, want float64) bool {
+	switch op {
+	case opGE:
+		return actual >= want
+	case opLE:
+		return actual <= want
+	case opGT:
+		return actual > want
+	case opLT:
+		return actual < want
+	default:
+		return actual == want
+	}
+}
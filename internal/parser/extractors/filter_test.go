@@ -0,0 +1,133 @@
+package extractors
+
+import "testing"
+
+func steamIDPtr(s string) *string { return &s }
+
+func TestCompileFilterEmptyMatchesEverything(t *testing.T) {
+	f, err := CompileFilter("")
+	if err != nil {
+		t.Fatalf("CompileFilter(\"\") returned error: %v", err)
+	}
+	if f != nil {
+		t.Fatalf("CompileFilter(\"\") = %+v, want nil", f)
+	}
+	if !f.Match(Event{Type: "TEAM_KILL"}) {
+		t.Error("nil Filter should match every event")
+	}
+}
+
+func TestCompileFilterRejectsUnknownType(t *testing.T) {
+	if _, err := CompileFilter("NOT_A_REAL_TYPE"); err == nil {
+		t.Error("expected an error for an unknown event type")
+	}
+}
+
+func TestCompileFilterTypeAllowList(t *testing.T) {
+	f, err := CompileFilter("TEAM_KILL,TEAM_DAMAGE")
+	if err != nil {
+		t.Fatalf("CompileFilter failed: %v", err)
+	}
+	if !f.Match(Event{Type: "TEAM_KILL"}) {
+		t.Error("expected TEAM_KILL to match the allow-list")
+	}
+	if f.Match(Event{Type: "TOXIC_CHAT"}) {
+		t.Error("expected TOXIC_CHAT to be excluded by the allow-list")
+	}
+}
+
+func TestCompileFilterSeverityComparison(t *testing.T) {
+	f, err := CompileFilter("severity>=0.7")
+	if err != nil {
+		t.Fatalf("CompileFilter failed: %v", err)
+	}
+	if !f.Match(Event{Type: "TEAM_KILL", Severity: 0.8}) {
+		t.Error("expected severity 0.8 to satisfy severity>=0.7")
+	}
+	if f.Match(Event{Type: "TEAM_KILL", Severity: 0.5}) {
+		t.Error("expected severity 0.5 to fail severity>=0.7")
+	}
+}
+
+func TestCompileFilterCombinesTypeAndComparison(t *testing.T) {
+	f, err := CompileFilter("TEAM_KILL,TEAM_DAMAGE,severity>=0.7,actor=STEAM_1")
+	if err != nil {
+		t.Fatalf("CompileFilter failed: %v", err)
+	}
+
+	match := Event{Type: "TEAM_KILL", Severity: 0.9, ActorSteamID: steamIDPtr("STEAM_1")}
+	if !f.Match(match) {
+		t.Error("expected event satisfying all three predicates to match")
+	}
+
+	wrongType := match
+	wrongType.Type = "DISCONNECT"
+	if f.Match(wrongType) {
+		t.Error("expected a type outside the allow-list to be excluded")
+	}
+
+	lowSeverity := match
+	lowSeverity.Severity = 0.1
+	if f.Match(lowSeverity) {
+		t.Error("expected low severity to be excluded")
+	}
+
+	wrongActor := match
+	wrongActor.ActorSteamID = steamIDPtr("STEAM_2")
+	if f.Match(wrongActor) {
+		t.Error("expected a different actor to be excluded")
+	}
+}
+
+func TestCompileFilterActorNilNeverMatches(t *testing.T) {
+	f, err := CompileFilter("actor=STEAM_1")
+	if err != nil {
+		t.Fatalf("CompileFilter failed: %v", err)
+	}
+	if f.Match(Event{Type: "TEAM_KILL"}) {
+		t.Error("expected an event with a nil ActorSteamID not to match an actor= filter")
+	}
+}
+
+func TestCompileFilterRoundComparison(t *testing.T) {
+	f, err := CompileFilter("round<5")
+	if err != nil {
+		t.Fatalf("CompileFilter failed: %v", err)
+	}
+	if !f.Match(Event{Type: "TEAM_KILL", RoundIndex: 2}) {
+		t.Error("expected round 2 to satisfy round<5")
+	}
+	if f.Match(Event{Type: "TEAM_KILL", RoundIndex: 7}) {
+		t.Error("expected round 7 to fail round<5")
+	}
+}
+
+func TestCompileFilterInvalidNumber(t *testing.T) {
+	if _, err := CompileFilter("severity>=not-a-number"); err == nil {
+		t.Error("expected an error for a non-numeric severity comparison")
+	}
+}
+
+func TestCompileFilterUnknownField(t *testing.T) {
+	if _, err := CompileFilter("weapon=ak47"); err == nil {
+		t.Error("expected an error for an unsupported field")
+	}
+}
+
+func TestEventTypesSortedAndIncludesKnownTypes(t *testing.T) {
+	types := EventTypes()
+	if len(types) == 0 {
+		t.Fatal("expected a non-empty event type registry")
+	}
+	for i := 1; i < len(types); i++ {
+		if types[i-1].Name > types[i].Name {
+			t.Fatalf("EventTypes() not sorted: %q before %q", types[i-1].Name, types[i].Name)
+		}
+	}
+	if !IsKnownEventType("TEAM_KILL") {
+		t.Error("expected TEAM_KILL to be a known event type")
+	}
+	if IsKnownEventType("NOT_A_REAL_TYPE") {
+		t.Error("expected an unregistered name to be unknown")
+	}
+}
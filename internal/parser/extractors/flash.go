@@ -0,0 +1,80 @@
+package extractors
+
+import (
+	"encoding/json"
+
+	events "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/events"
+)
+
+// FlashExtractor extracts enemy flash events (an attacker blinding an
+// opponent), the complement of TeamFlashExtractor's own-team flashes.
+// FlashAssistExtractor later joins these against KILL events from the
+// database to attribute a kill to whoever set it up by flashing the
+// victim; this extractor just persists the raw flashes.
+type FlashExtractor struct {
+	events []Event
+}
+
+// NewFlashExtractor creates a new enemy flash extractor.
+func NewFlashExtractor() *FlashExtractor {
+	return &FlashExtractor{
+		events: make([]Event, 0),
+	}
+}
+
+// HandlePlayerFlashed records event as an ENEMY_FLASH if attacker and victim
+// are on opposing teams; team flashes are TeamFlashExtractor's job.
+func (e *FlashExtractor) HandlePlayerFlashed(event events.PlayerFlashed, roundIndex int, tick int) {
+	attacker := event.Attacker
+	victim := event.Player
+	if attacker == nil || victim == nil {
+		return
+	}
+
+	if isSameTeam(attacker, victim) {
+		return // team flash, handled by TeamFlashExtractor
+	}
+
+	duration := 0.0
+	if flashDuration := event.FlashDuration(); flashDuration > 0 {
+		duration = flashDuration.Seconds()
+	} else {
+		duration = 3.0
+	}
+
+	// Skip flashes too brief to plausibly have set up a kill.
+	if duration < 0.5 {
+		return
+	}
+
+	attackerSteamID := getSteamID(attacker)
+	victimSteamID := getSteamID(victim)
+	if attackerSteamID == nil || victimSteamID == nil {
+		return
+	}
+
+	meta := map[string]interface{}{"blind_duration": duration}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	e.events = append(e.events, Event{
+		Type:          "ENEMY_FLASH",
+		RoundIndex:    roundIndex,
+		StartTick:     tick,
+		ActorSteamID:  attackerSteamID,
+		VictimSteamID: victimSteamID,
+		Severity:      0.0,
+		Confidence:    1.0,
+		MetaJSON:      &metaJSONStr,
+	})
+}
+
+// GetEvents returns all extracted events.
+func (e *FlashExtractor) GetEvents() []Event {
+	return e.events
+}
+
+// ClearEvents clears all extracted events from memory.
+func (e *FlashExtractor) ClearEvents() {
+	e.events = e.events[:0]
+}
@@ -0,0 +1,184 @@
+package extractors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// FlashAssistExtractor attributes a kill to whoever flashed the victim
+// shortly beforehand: it re-reads this round's ENEMY_FLASH events (recorded
+// live by FlashExtractor) and KILL events (recorded live by KillExtractor)
+// from the database and pairs a flash with the kill it set up.
+type FlashAssistExtractor struct {
+	db           *sql.DB
+	tickRate     float64
+	assistWindow float64 // seconds within which a flash can still count as an assist
+	events       []Event
+}
+
+// NewFlashAssistExtractor creates a new flash assist extractor.
+func NewFlashAssistExtractor(tickRate float64, db *sql.DB) *FlashAssistExtractor {
+	return &FlashAssistExtractor{
+		db:           db,
+		tickRate:     tickRate,
+		assistWindow: 3.0,
+		events:       make([]Event, 0),
+	}
+}
+
+// GetEvents returns all extracted events.
+func (e *FlashAssistExtractor) GetEvents() []Event {
+	return e.events
+}
+
+// ClearEvents clears all extracted events from memory.
+func (e *FlashAssistExtractor) ClearEvents() {
+	e.events = e.events[:0]
+}
+
+type flashRow struct {
+	flasherSteamID string
+	victimSteamID  string
+	tick           int
+}
+
+type killRow struct {
+	killerSteamID string
+	victimSteamID string
+	tick          int
+}
+
+// ProcessRoundFromDatabase pairs this round's ENEMY_FLASH events with the
+// KILL that followed, crediting whoever flashed the victim (teammate of the
+// killer, including the killer himself) within assistWindow seconds.
+func (e *FlashAssistExtractor) ProcessRoundFromDatabase(matchID string, roundIndex int) error {
+	if e.db == nil || matchID == "" {
+		return nil
+	}
+
+	flashRows, err := e.db.Query(`
+		SELECT actor_steamid, victim_steamid, start_tick
+		FROM events
+		WHERE match_id = ? AND round_index = ? AND type = 'ENEMY_FLASH'
+			AND actor_steamid IS NOT NULL AND victim_steamid IS NOT NULL
+		ORDER BY start_tick
+	`, matchID, roundIndex)
+	if err != nil {
+		return fmt.Errorf("failed to query flashes: %w", err)
+	}
+	defer flashRows.Close()
+
+	var flashes []flashRow
+	for flashRows.Next() {
+		var f flashRow
+		if err := flashRows.Scan(&f.flasherSteamID, &f.victimSteamID, &f.tick); err != nil {
+			continue
+		}
+		flashes = append(flashes, f)
+	}
+	if len(flashes) == 0 {
+		return nil
+	}
+
+	killRows, err := e.db.Query(`
+		SELECT actor_steamid, victim_steamid, start_tick
+		FROM events
+		WHERE match_id = ? AND round_index = ? AND type = 'KILL'
+			AND actor_steamid IS NOT NULL AND victim_steamid IS NOT NULL
+		ORDER BY start_tick
+	`, matchID, roundIndex)
+	if err != nil {
+		return fmt.Errorf("failed to query kills: %w", err)
+	}
+	defer killRows.Close()
+
+	var kills []killRow
+	for killRows.Next() {
+		var k killRow
+		if err := killRows.Scan(&k.killerSteamID, &k.victimSteamID, &k.tick); err != nil {
+			continue
+		}
+		kills = append(kills, k)
+	}
+
+	windowTicks := int(math.Ceil(e.assistWindow * e.tickRate))
+
+	for _, flash := range flashes {
+		for _, kill := range kills {
+			if kill.victimSteamID != flash.victimSteamID {
+				continue
+			}
+			if kill.tick < flash.tick || kill.tick-flash.tick > windowTicks {
+				continue
+			}
+
+			// The flasher must be on the killer's team (the killer flashing
+			// his own target counts too, since isSameTeam-style comparisons
+			// would be trivially true for the same player).
+			if flash.flasherSteamID != kill.killerSteamID {
+				flasherTeam, err := e.teamAtTick(matchID, roundIndex, flash.flasherSteamID, flash.tick)
+				if err != nil || flasherTeam == "" {
+					continue
+				}
+				killerTeam, err := e.teamAtTick(matchID, roundIndex, kill.killerSteamID, kill.tick)
+				if err != nil || killerTeam == "" || flasherTeam != killerTeam {
+					continue
+				}
+			}
+
+			e.recordAssist(roundIndex, flash, kill)
+			break
+		}
+	}
+
+	return nil
+}
+
+// teamAtTick looks up steamID's team from the closest player_positions row
+// to tick, the same per-tick team source BodyBlockExtractor uses.
+func (e *FlashAssistExtractor) teamAtTick(matchID string, roundIndex int, steamID string, tick int) (string, error) {
+	var team sql.NullString
+	err := e.db.QueryRow(`
+		SELECT team FROM player_positions
+		WHERE match_id = ? AND round_index = ? AND steamid = ?
+		ORDER BY ABS(tick - ?) ASC
+		LIMIT 1
+	`, matchID, roundIndex, steamID, tick).Scan(&team)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return team.String, nil
+}
+
+func (e *FlashAssistExtractor) recordAssist(roundIndex int, flash flashRow, kill killRow) {
+	seconds := float64(kill.tick-flash.tick) / e.tickRate
+
+	meta := map[string]interface{}{
+		"seconds":   seconds,
+		"kill_tick": kill.tick,
+		"killer":    kill.killerSteamID,
+	}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	killTick := kill.tick
+	flasher := flash.flasherSteamID
+	victim := kill.victimSteamID
+
+	e.events = append(e.events, Event{
+		Type:          "FLASH_ASSIST",
+		RoundIndex:    roundIndex,
+		StartTick:     flash.tick,
+		EndTick:       &killTick,
+		ActorSteamID:  &flasher,
+		VictimSteamID: &victim,
+		Severity:      0.0, // a positive play, not a griefing signal
+		Confidence:    1.0,
+		MetaJSON:      &metaJSONStr,
+	})
+}
@@ -0,0 +1,347 @@
+package extractors
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	common "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/common"
+	events "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/events"
+)
+
+// FriendlyFireIncidentExtractor emits a FRIENDLY_FIRE_INCIDENT event per
+// (round, attacker, victim) pair, merging PlayerHurt, PlayerFlashed and Kill
+// sub-events within MergeWindow of each other into one incident instead of
+// one event per modality.
+//
+// This is deliberately additive rather than a replacement for
+// TeamDamageExtractor/TeamFlashExtractor/TeamKillExtractor: those three
+// extractors' event types (TEAM_DAMAGE/TEAM_FLASH/TEAM_KILL) are keyed on
+// directly by scoring.Scorer.ComputeScores, and folding them into one event
+// type would mean rewriting that switch (and the metrics ScoringProfile
+// weighs) too, which is out of scope here. FriendlyFireIncidentExtractor
+// runs alongside the existing three, giving callers that want a single
+// merged view of "this player hurt/flashed/killed that teammate" an
+// additional, independent signal without disturbing the scoring pipeline
+// that already consumes the per-modality events.
+type FriendlyFireIncidentExtractor struct {
+	windowTicks int
+	filter      *DamageCauseFilter
+	severityFn  FriendlyFireSeverityFunc
+	open        map[string]*openIncident
+	events      []Event
+}
+
+// openIncident is one in-progress (not yet merge-window-expired) incident.
+type openIncident struct {
+	inc      Incident
+	lastTick int
+}
+
+// Incident is the merged-so-far state of one (round, attacker, victim)
+// friendly-fire incident, passed to a FriendlyFireSeverityFunc so callers
+// can weigh modalities differently than DefaultFriendlyFireSeverity does.
+type Incident struct {
+	RoundIndex    int
+	ActorSteamID  string
+	VictimSteamID string
+	FirstTick     int
+	LastTick      int
+	DmgHealth     int
+	DmgArmor      int
+	FlashSeconds  float64
+	FlashCount    int
+	Killed        bool
+	KillWeapon    string
+	HasUtility    bool
+	Weapons       []string // distinct weapons involved, in first-seen order
+	Hitgroups     map[string]int
+}
+
+// FriendlyFireSeverityFunc scores a finalized Incident into [0, 1]. Passed
+// to NewFriendlyFireIncidentExtractor so callers can weigh a grief profile's
+// own notion of "how bad was this" instead of DefaultFriendlyFireSeverity's.
+type FriendlyFireSeverityFunc func(Incident) float64
+
+// DefaultFriendlyFireMergeWindow is the gap, between the last sub-event of
+// an incident and the next one on the same (round, attacker, victim) pair,
+// past which the next sub-event starts a new incident instead of extending
+// the current one.
+const DefaultFriendlyFireMergeWindow = 2 * time.Second
+
+// DefaultFriendlyFireSeverity scores a teamkill as maximally severe, and
+// otherwise scales damage (100 health+armor = 1.0) and flash time (5 seconds
+// = 1.0) the same way TeamDamageExtractor/TeamFlashExtractor already do,
+// summing the two before capping at 1.0 so a damage-then-flash incident
+// reads as worse than either alone.
+func DefaultFriendlyFireSeverity(inc Incident) float64 {
+	if inc.Killed {
+		return 1.0
+	}
+	score := float64(inc.DmgHealth+inc.DmgArmor)/100.0 + inc.FlashSeconds/5.0
+	return math.Min(score, 1.0)
+}
+
+// NewFriendlyFireIncidentExtractor creates a new extractor. mergeWindow <= 0
+// falls back to DefaultFriendlyFireMergeWindow, a nil filter falls back to
+// DefaultDamageCauseFilter (denying SUICIDE/WORLD damage causes), and a nil
+// severityFn falls back to DefaultFriendlyFireSeverity.
+func NewFriendlyFireIncidentExtractor(tickRate float64, mergeWindow time.Duration, filter *DamageCauseFilter, severityFn FriendlyFireSeverityFunc) *FriendlyFireIncidentExtractor {
+	if mergeWindow <= 0 {
+		mergeWindow = DefaultFriendlyFireMergeWindow
+	}
+	if filter == nil {
+		filter = DefaultDamageCauseFilter()
+	}
+	if severityFn == nil {
+		severityFn = DefaultFriendlyFireSeverity
+	}
+	return &FriendlyFireIncidentExtractor{
+		windowTicks: int(tickRate * mergeWindow.Seconds()),
+		filter:      filter,
+		severityFn:  severityFn,
+		open:        make(map[string]*openIncident),
+		events:      make([]Event, 0),
+	}
+}
+
+// incidentKey identifies an in-progress incident by round, actor and victim -
+// unlike accumulatorKey, it doesn't split by weapon, since one incident can
+// span several weapons/modalities.
+func incidentKey(roundIndex int, actorSteamID, victimSteamID string) string {
+	return fmt.Sprintf("%d|%s|%s", roundIndex, actorSteamID, victimSteamID)
+}
+
+// isUtilityEquipment classifies eq via its EquipmentClass rather than a
+// hard-coded list of grenade name strings, so a new grenade type added to
+// the underlying demoinfocs-golang library is picked up automatically.
+func isUtilityEquipment(eq *common.Equipment) bool {
+	return eq != nil && eq.Class() == common.EqClassGrenade
+}
+
+// openFor returns the in-progress incident for (roundIndex, actorSteamID,
+// victimSteamID), flushing and replacing it first if tick falls outside the
+// merge window of its last sub-event.
+func (e *FriendlyFireIncidentExtractor) openFor(roundIndex int, actorSteamID, victimSteamID string, tick int) *openIncident {
+	key := incidentKey(roundIndex, actorSteamID, victimSteamID)
+	if oi, ok := e.open[key]; ok {
+		if tick-oi.lastTick > e.windowTicks {
+			e.flush(key)
+		} else {
+			oi.lastTick = tick
+			if tick > oi.inc.LastTick {
+				oi.inc.LastTick = tick
+			}
+			return oi
+		}
+	}
+
+	oi := &openIncident{
+		inc: Incident{
+			RoundIndex:    roundIndex,
+			ActorSteamID:  actorSteamID,
+			VictimSteamID: victimSteamID,
+			FirstTick:     tick,
+			LastTick:      tick,
+			Hitgroups:     make(map[string]int),
+		},
+		lastTick: tick,
+	}
+	e.open[key] = oi
+	return oi
+}
+
+// addWeapon appends weapon to inc.Weapons if it isn't already present.
+func addWeapon(inc *Incident, weapon string) {
+	if weapon == "" {
+		return
+	}
+	for _, w := range inc.Weapons {
+		if w == weapon {
+			return
+		}
+	}
+	inc.Weapons = append(inc.Weapons, weapon)
+}
+
+// weaponName returns eq's EquipmentType name, falling back to its raw
+// String() for types demoinfocs-golang hasn't classified (mirrors the
+// weapon-naming fallback TeamDamageExtractor/TeamKillExtractor already use).
+func weaponName(eq *common.Equipment) string {
+	if eq == nil {
+		return "unknown"
+	}
+	if eq.Type != common.EqUnknown {
+		return eq.Type.String()
+	}
+	return eq.String()
+}
+
+// HandlePlayerHurt folds a PlayerHurt event's health/armor damage into the
+// open incident for (roundIndex, attacker, victim), if it's team damage the
+// extractor's DamageCauseFilter allows.
+func (e *FriendlyFireIncidentExtractor) HandlePlayerHurt(event events.PlayerHurt, roundIndex int, tick int) {
+	attacker := event.Attacker
+	victim := event.Player
+	if attacker == nil || victim == nil || isSamePlayer(attacker, victim) || !isSameTeam(attacker, victim) {
+		return
+	}
+
+	cause := classifyDamageCause(attacker, victim, event.Weapon)
+	if !e.filter.Allowed(cause) {
+		return
+	}
+
+	attackerSteamID := getSteamID(attacker)
+	victimSteamID := getSteamID(victim)
+	if attackerSteamID == nil || victimSteamID == nil {
+		return
+	}
+
+	oi := e.openFor(roundIndex, *attackerSteamID, *victimSteamID, tick)
+	oi.inc.DmgHealth += int(event.HealthDamage)
+	oi.inc.DmgArmor += int(event.ArmorDamage)
+	addWeapon(&oi.inc, weaponName(event.Weapon))
+	if isUtilityEquipment(event.Weapon) {
+		oi.inc.HasUtility = true
+	}
+	if event.HitGroup != 0 {
+		oi.inc.Hitgroups[fmt.Sprintf("%d", int(event.HitGroup))]++
+	}
+}
+
+// HandlePlayerFlashed folds a PlayerFlashed event's blind duration into the
+// open incident for (roundIndex, flasher, victim). Flashes under 1 second
+// are dropped as insignificant, matching TeamFlashExtractor.
+func (e *FriendlyFireIncidentExtractor) HandlePlayerFlashed(event events.PlayerFlashed, roundIndex int, tick int) {
+	attacker := event.Attacker
+	victim := event.Player
+	if attacker == nil || !isTeamKill(attacker, victim) {
+		return
+	}
+
+	flasherSteamID := getSteamID(attacker)
+	victimSteamID := getSteamID(victim)
+	if flasherSteamID == nil || victimSteamID == nil {
+		return
+	}
+
+	duration := 3.0
+	if fd := event.FlashDuration(); fd > 0 {
+		duration = fd.Seconds()
+	}
+	if duration < 1.0 {
+		return
+	}
+
+	oi := e.openFor(roundIndex, *flasherSteamID, *victimSteamID, tick)
+	oi.inc.FlashSeconds += duration
+	oi.inc.FlashCount++
+	oi.inc.HasUtility = true
+	addWeapon(&oi.inc, "Flashbang")
+}
+
+// HandleKill folds a teamkill into the open incident for (roundIndex,
+// killer, victim), marking it Killed so DefaultFriendlyFireSeverity and
+// callers treat the whole incident as maximally severe.
+func (e *FriendlyFireIncidentExtractor) HandleKill(event events.Kill, roundIndex int, tick int) {
+	attacker := event.Killer
+	victim := event.Victim
+	if attacker == nil || victim == nil || !isTeamKill(attacker, victim) {
+		return
+	}
+
+	attackerSteamID := getSteamID(attacker)
+	victimSteamID := getSteamID(victim)
+	if attackerSteamID == nil || victimSteamID == nil {
+		return
+	}
+
+	weapon := weaponName(event.Weapon)
+	oi := e.openFor(roundIndex, *attackerSteamID, *victimSteamID, tick)
+	oi.inc.Killed = true
+	oi.inc.KillWeapon = weapon
+	addWeapon(&oi.inc, weapon)
+	if isUtilityEquipment(event.Weapon) {
+		oi.inc.HasUtility = true
+	}
+}
+
+// FinalizeRound flushes every incident open for roundIndex. Call this when
+// a round closes, same as TeamDamageExtractor/TeamFlashExtractor.
+func (e *FriendlyFireIncidentExtractor) FinalizeRound(roundIndex int) {
+	for key, oi := range e.open {
+		if oi.inc.RoundIndex == roundIndex {
+			e.flush(key)
+		}
+	}
+}
+
+// FlushParticipant finalizes any incident involving steamID as attacker or
+// victim, e.g. when that player dies mid-window and can no longer contribute
+// to or receive further sub-events.
+func (e *FriendlyFireIncidentExtractor) FlushParticipant(steamID string) {
+	for key, oi := range e.open {
+		if oi.inc.ActorSteamID == steamID || oi.inc.VictimSteamID == steamID {
+			e.flush(key)
+		}
+	}
+}
+
+// flush finalizes and emits the incident at key, if still open.
+func (e *FriendlyFireIncidentExtractor) flush(key string) {
+	oi, ok := e.open[key]
+	if !ok {
+		return
+	}
+	delete(e.open, key)
+	e.emit(oi.inc)
+}
+
+func (e *FriendlyFireIncidentExtractor) emit(inc Incident) {
+	meta := make(map[string]interface{})
+	meta["dmg_health"] = inc.DmgHealth
+	meta["dmg_armor"] = inc.DmgArmor
+	meta["flash_seconds"] = inc.FlashSeconds
+	meta["killed_bool"] = inc.Killed
+	meta["is_utility"] = inc.HasUtility
+	if len(inc.Weapons) > 0 {
+		meta["weapons"] = inc.Weapons
+	}
+	if len(inc.Hitgroups) > 0 {
+		meta["hitgroups"] = inc.Hitgroups
+	}
+	if inc.Killed && inc.KillWeapon != "" {
+		meta["kill_weapon"] = inc.KillWeapon
+	}
+
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	actorSteamID := inc.ActorSteamID
+	victimSteamID := inc.VictimSteamID
+	endTick := inc.LastTick
+
+	e.events = append(e.events, Event{
+		Type:          "FRIENDLY_FIRE_INCIDENT",
+		RoundIndex:    inc.RoundIndex,
+		StartTick:     inc.FirstTick,
+		EndTick:       &endTick,
+		ActorSteamID:  &actorSteamID,
+		VictimSteamID: &victimSteamID,
+		Severity:      e.severityFn(inc),
+		Confidence:    1.0,
+		MetaJSON:      &metaJSONStr,
+	})
+}
+
+// GetEvents returns all extracted events.
+func (e *FriendlyFireIncidentExtractor) GetEvents() []Event {
+	return e.events
+}
+
+// ClearEvents clears all extracted events from memory.
+func (e *FriendlyFireIncidentExtractor) ClearEvents() {
+	e.events = e.events[:0]
+}
@@ -0,0 +1,123 @@
+package extractors
+
+import "testing"
+
+func TestFriendlyFireMergeAcrossModalities(t *testing.T) {
+	tickRate := 64.0 // 64 tick server
+	extractor := NewFriendlyFireIncidentExtractor(tickRate, DefaultFriendlyFireMergeWindow, nil, nil)
+
+	// Damage, then a flash of the same pair within the 128-tick (2 second)
+	// merge window, should merge into one incident.
+	oi := extractor.openFor(0, "1", "2", 1000)
+	oi.inc.DmgHealth += 20
+	oi.inc.DmgArmor += 10
+	addWeapon(&oi.inc, "ak47")
+
+	oi = extractor.openFor(0, "1", "2", 1100)
+	oi.inc.FlashSeconds += 2.0
+	oi.inc.FlashCount++
+	addWeapon(&oi.inc, "Flashbang")
+
+	extractor.FinalizeRound(0)
+
+	events := extractor.GetEvents()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 merged incident, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.Type != "FRIENDLY_FIRE_INCIDENT" {
+		t.Errorf("expected type FRIENDLY_FIRE_INCIDENT, got %s", event.Type)
+	}
+	if event.StartTick != 1000 {
+		t.Errorf("expected start tick 1000, got %d", event.StartTick)
+	}
+	if event.EndTick == nil || *event.EndTick != 1100 {
+		t.Errorf("expected end tick 1100, got %v", event.EndTick)
+	}
+
+	// Damage score: 30/100 = 0.3; flash score: 2.0/5.0 = 0.4; total 0.7.
+	expectedSeverity := 0.7
+	if event.Severity < expectedSeverity-0.01 || event.Severity > expectedSeverity+0.01 {
+		t.Errorf("expected severity ~%.2f, got %.2f", expectedSeverity, event.Severity)
+	}
+}
+
+func TestFriendlyFireMergeWindowRollover(t *testing.T) {
+	tickRate := 64.0
+	extractor := NewFriendlyFireIncidentExtractor(tickRate, DefaultFriendlyFireMergeWindow, nil, nil)
+
+	extractor.openFor(0, "1", "2", 1000)
+	// Second sub-event lands outside the merge window, so it should close
+	// the first incident rather than extend it.
+	extractor.openFor(0, "1", "2", 1000+extractor.windowTicks+1)
+	extractor.FinalizeRound(0)
+
+	events := extractor.GetEvents()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 separate incidents, got %d", len(events))
+	}
+}
+
+func TestFriendlyFireKillForcesSeverityToOne(t *testing.T) {
+	extractor := NewFriendlyFireIncidentExtractor(64.0, DefaultFriendlyFireMergeWindow, nil, nil)
+
+	oi := extractor.openFor(0, "1", "2", 1000)
+	oi.inc.DmgHealth += 5 // well below a 1.0 damage-only severity
+	oi.inc.Killed = true
+	oi.inc.KillWeapon = "Knife"
+	extractor.FinalizeRound(0)
+
+	events := extractor.GetEvents()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 incident, got %d", len(events))
+	}
+	if events[0].Severity != 1.0 {
+		t.Errorf("expected severity 1.0 for a teamkill incident, got %.2f", events[0].Severity)
+	}
+}
+
+func TestFriendlyFireRoundBoundaryFlush(t *testing.T) {
+	extractor := NewFriendlyFireIncidentExtractor(64.0, DefaultFriendlyFireMergeWindow, nil, nil)
+
+	extractor.openFor(0, "1", "2", 1000)
+	extractor.openFor(1, "1", "2", 1000)
+
+	extractor.FinalizeRound(0)
+	if len(extractor.GetEvents()) != 1 {
+		t.Fatalf("expected FinalizeRound(0) to flush only round 0's incident, got %d events", len(extractor.GetEvents()))
+	}
+
+	extractor.FinalizeRound(1)
+	if len(extractor.GetEvents()) != 2 {
+		t.Fatalf("expected FinalizeRound(1) to flush round 1's incident too, got %d events", len(extractor.GetEvents()))
+	}
+}
+
+func TestFriendlyFireFlushParticipant(t *testing.T) {
+	extractor := NewFriendlyFireIncidentExtractor(64.0, DefaultFriendlyFireMergeWindow, nil, nil)
+
+	extractor.openFor(0, "1", "2", 1000)
+	extractor.openFor(0, "3", "4", 1000)
+
+	extractor.FlushParticipant("2")
+	if len(extractor.GetEvents()) != 1 {
+		t.Fatalf("expected FlushParticipant to close only the incident involving steamID 2, got %d events", len(extractor.GetEvents()))
+	}
+	if len(extractor.open) != 1 {
+		t.Fatalf("expected the unrelated (3, 4) incident to remain open, got %d open", len(extractor.open))
+	}
+}
+
+func TestFriendlyFireCustomSeverityFunc(t *testing.T) {
+	always := func(Incident) float64 { return 0.42 }
+	extractor := NewFriendlyFireIncidentExtractor(64.0, DefaultFriendlyFireMergeWindow, nil, always)
+
+	extractor.openFor(0, "1", "2", 1000)
+	extractor.FinalizeRound(0)
+
+	events := extractor.GetEvents()
+	if len(events) != 1 || events[0].Severity != 0.42 {
+		t.Fatalf("expected the custom severity func's value to be used, got %+v", events)
+	}
+}
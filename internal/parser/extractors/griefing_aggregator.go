@@ -0,0 +1,335 @@
+package extractors
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// griefingSample is one decayed-score input: a single griefing-adjacent
+// event (team kill, long team flash, rage disconnect, suicide) attributed
+// to a player in a given round.
+type griefingSample struct {
+	roundIndex int
+	eventType  string
+	severity   float64 // event.Severity * the per-type weight below
+}
+
+// griefingRingBufferSize bounds how many samples GriefingAggregator keeps
+// per player; once exceeded, the oldest sample is dropped. This is plenty
+// for a single match (a player griefing every round for an entire MR24
+// match would still fit).
+const griefingRingBufferSize = 128
+
+// griefingDecayLambda controls how fast older rounds stop counting toward
+// a player's score: weight(Δrounds) = exp(-λ·Δrounds).
+const griefingDecayLambda = 0.25
+
+// GriefingEvalIntervalRounds is how often Evaluate should be called by the
+// caller (every N rounds) to decide whether anyone has crossed a threshold.
+const GriefingEvalIntervalRounds = 3
+
+// griefingHysteresis is how far below a threshold a player's score must
+// fall before that threshold is allowed to fire again.
+const griefingHysteresis = 0.15
+
+// Per-event-type weights applied to event.Severity before it enters the
+// decayed sum, reflecting how indicative each event is of intentional
+// griefing versus an unlucky round.
+const (
+	griefingWeightTeamKill       = 1.0
+	griefingWeightSuicide        = 0.3
+	griefingWeightTeamFlash      = 0.6
+	griefingWeightRageDisconnect = 0.8
+)
+
+// griefingTeamFlashMinDuration is the minimum blind duration (seconds) a
+// TEAM_FLASH event must have to count toward the griefing score at all.
+const griefingTeamFlashMinDuration = 3.0
+
+// griefingThreshold names one of the low/medium/high alert bands.
+type griefingThreshold struct {
+	Name  string
+	Score float64
+}
+
+// griefingThresholds are the configurable low/medium/high cutoffs a
+// player's decayed score can cross, checked lowest-to-highest.
+var griefingThresholds = []griefingThreshold{
+	{Name: "low", Score: 2.0},
+	{Name: "medium", Score: 4.0},
+	{Name: "high", Score: 7.0},
+}
+
+// playerGriefingState is the per-threshold hysteresis state for one player:
+// whether that threshold has already fired since the score last dropped
+// griefingHysteresis below it.
+type playerGriefingState struct {
+	fired map[string]bool
+}
+
+// GriefingOffender is one row of GriefingAggregator.TopOffenders.
+type GriefingOffender struct {
+	SteamID string
+	Score   float64
+}
+
+// GriefingTimelineEntry is one row of GriefingAggregator.PerRoundTimeline.
+type GriefingTimelineEntry struct {
+	RoundIndex int
+	EventType  string
+	Severity   float64
+}
+
+// GriefingAggregator consumes the union of TeamKillExtractor,
+// TeamFlashExtractor and DisconnectExtractor events (and any future
+// extractor that wants in) and maintains a decayed, per-player griefing
+// score: recent griefing-adjacent events count close to full weight, older
+// ones fade out exponentially. Crossing a configured threshold emits a
+// PLAYER_GRIEFING_SCORE event, gated by per-threshold hysteresis so a
+// player oscillating right at a cutoff doesn't spam alerts.
+type GriefingAggregator struct {
+	events  []Event
+	samples map[string][]griefingSample // key: steamID, oldest first
+	states  map[string]*playerGriefingState
+	cursors map[string]int // source key -> events already consumed by IngestSource
+
+	maxRoundSeen int
+}
+
+// NewGriefingAggregator creates an empty aggregator.
+func NewGriefingAggregator() *GriefingAggregator {
+	return &GriefingAggregator{
+		events:  make([]Event, 0),
+		samples: make(map[string][]griefingSample),
+		states:  make(map[string]*playerGriefingState),
+		cursors: make(map[string]int),
+	}
+}
+
+// IngestSource folds any new events in source (beyond what was already
+// consumed under this sourceKey) into the aggregator. Callers pass the full,
+// cumulative GetEvents() slice from an extractor (e.g. "team_kill",
+// "team_flash", "disconnect") each time they want the aggregator caught up;
+// IngestSource tracks how far it has already read so calling it repeatedly
+// with a growing slice is safe and never double-counts. If source is
+// shorter than what was previously consumed (the extractor's ClearEvents
+// was called), the cursor resets and source is read from the start.
+func (a *GriefingAggregator) IngestSource(sourceKey string, source []Event) {
+	start := a.cursors[sourceKey]
+	if start > len(source) {
+		start = 0
+	}
+	for _, event := range source[start:] {
+		a.ingestOne(event)
+	}
+	a.cursors[sourceKey] = len(source)
+}
+
+// ingestOne classifies a single event and, if it's one the aggregator
+// tracks, appends a decayed-score sample for its actor.
+func (a *GriefingAggregator) ingestOne(event Event) {
+	weight, ok := griefingWeight(event)
+	if !ok || event.ActorSteamID == nil {
+		return
+	}
+
+	if event.RoundIndex > a.maxRoundSeen {
+		a.maxRoundSeen = event.RoundIndex
+	}
+
+	steamID := *event.ActorSteamID
+	samples := append(a.samples[steamID], griefingSample{
+		roundIndex: event.RoundIndex,
+		eventType:  event.Type,
+		severity:   event.Severity * weight,
+	})
+	if len(samples) > griefingRingBufferSize {
+		samples = samples[len(samples)-griefingRingBufferSize:]
+	}
+	a.samples[steamID] = samples
+}
+
+// griefingWeight maps an extractor Event to its griefing weight, or
+// (0, false) if this event type/shape isn't counted at all (e.g. a team
+// flash under griefingTeamFlashMinDuration).
+func griefingWeight(event Event) (float64, bool) {
+	switch event.Type {
+	case "TEAM_KILL":
+		if event.VictimSteamID != nil && event.ActorSteamID != nil && *event.VictimSteamID == *event.ActorSteamID {
+			return griefingWeightSuicide, true
+		}
+		return griefingWeightTeamKill, true
+
+	case "TEAM_FLASH":
+		duration, ok := metaFloat(event.MetaJSON, "blind_duration")
+		if !ok || duration < griefingTeamFlashMinDuration {
+			return 0, false
+		}
+		return griefingWeightTeamFlash, true
+
+	case "ABANDON":
+		classification, ok := metaString(event.MetaJSON, "classification")
+		if !ok || classification != DisconnectClassRageQuit {
+			return 0, false
+		}
+		return griefingWeightRageDisconnect, true
+
+	default:
+		return 0, false
+	}
+}
+
+// score computes steamID's current decayed score as of currentRound:
+// Σ sample.severity · exp(-λ·(currentRound - sample.roundIndex)).
+func (a *GriefingAggregator) score(steamID string, currentRound int) float64 {
+	var total float64
+	for _, sample := range a.samples[steamID] {
+		deltaRounds := currentRound - sample.roundIndex
+		if deltaRounds < 0 {
+			deltaRounds = 0
+		}
+		total += sample.severity * math.Exp(-griefingDecayLambda*float64(deltaRounds))
+	}
+	return total
+}
+
+// Evaluate recomputes every tracked player's decayed score as of
+// currentRound and emits a PLAYER_GRIEFING_SCORE event for each threshold a
+// player newly crosses (i.e. not already fired, per griefingHysteresis).
+// It should be called roughly every GriefingEvalIntervalRounds rounds.
+func (a *GriefingAggregator) Evaluate(currentRound int) []Event {
+	steamIDs := make([]string, 0, len(a.samples))
+	for steamID := range a.samples {
+		steamIDs = append(steamIDs, steamID)
+	}
+	sort.Strings(steamIDs) // deterministic event ordering
+
+	var produced []Event
+	for _, steamID := range steamIDs {
+		score := a.score(steamID, currentRound)
+		state, ok := a.states[steamID]
+		if !ok {
+			state = &playerGriefingState{fired: make(map[string]bool)}
+			a.states[steamID] = state
+		}
+
+		for _, threshold := range griefingThresholds {
+			switch {
+			case score >= threshold.Score && !state.fired[threshold.Name]:
+				state.fired[threshold.Name] = true
+				produced = append(produced, a.buildEvent(steamID, currentRound, score, threshold.Name))
+			case score < threshold.Score-griefingHysteresis:
+				state.fired[threshold.Name] = false
+			}
+		}
+	}
+
+	a.events = append(a.events, produced...)
+	return produced
+}
+
+// buildEvent constructs the PLAYER_GRIEFING_SCORE event for one threshold
+// crossing.
+func (a *GriefingAggregator) buildEvent(steamID string, currentRound int, score float64, level string) Event {
+	meta := map[string]interface{}{
+		"score":        score,
+		"level":        level,
+		"sample_count": len(a.samples[steamID]),
+	}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	id := steamID
+	return Event{
+		Type:         "PLAYER_GRIEFING_SCORE",
+		RoundIndex:   currentRound,
+		StartTick:    0,
+		ActorSteamID: &id,
+		Severity:     math.Min(1.0, score/griefingThresholds[len(griefingThresholds)-1].Score),
+		Confidence:   math.Min(1.0, float64(len(a.samples[steamID]))/10.0),
+		MetaJSON:     &metaJSONStr,
+	}
+}
+
+// TopOffenders returns the n players with the highest current decayed
+// score (as of the last round any event was observed for), descending.
+func (a *GriefingAggregator) TopOffenders(n int) []GriefingOffender {
+	offenders := make([]GriefingOffender, 0, len(a.samples))
+	for steamID := range a.samples {
+		offenders = append(offenders, GriefingOffender{
+			SteamID: steamID,
+			Score:   a.score(steamID, a.maxRoundSeen),
+		})
+	}
+	sort.Slice(offenders, func(i, j int) bool {
+		if offenders[i].Score != offenders[j].Score {
+			return offenders[i].Score > offenders[j].Score
+		}
+		return offenders[i].SteamID < offenders[j].SteamID
+	})
+	if n >= 0 && n < len(offenders) {
+		offenders = offenders[:n]
+	}
+	return offenders
+}
+
+// PerRoundTimeline returns steamID's raw samples in chronological order,
+// for rendering a per-round griefing heat-map.
+func (a *GriefingAggregator) PerRoundTimeline(steamID string) []GriefingTimelineEntry {
+	samples := a.samples[steamID]
+	timeline := make([]GriefingTimelineEntry, len(samples))
+	for i, sample := range samples {
+		timeline[i] = GriefingTimelineEntry{
+			RoundIndex: sample.roundIndex,
+			EventType:  sample.eventType,
+			Severity:   sample.severity,
+		}
+	}
+	return timeline
+}
+
+// Reset clears every player's score and alert state, e.g. on map change in
+// a multi-map series so one map's griefing doesn't bleed into the next.
+func (a *GriefingAggregator) Reset() {
+	a.samples = make(map[string][]griefingSample)
+	a.states = make(map[string]*playerGriefingState)
+	a.cursors = make(map[string]int)
+	a.maxRoundSeen = 0
+}
+
+// GetEvents returns all PLAYER_GRIEFING_SCORE events produced by Evaluate.
+func (a *GriefingAggregator) GetEvents() []Event {
+	return a.events
+}
+
+// ClearEvents clears the accumulated events.
+func (a *GriefingAggregator) ClearEvents() {
+	a.events = a.events[:0]
+}
+
+// metaFloat extracts a float64 field from an event's MetaJSON, if present.
+func metaFloat(metaJSON *string, key string) (float64, bool) {
+	if metaJSON == nil {
+		return 0, false
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(*metaJSON), &meta); err != nil {
+		return 0, false
+	}
+	value, ok := meta[key].(float64)
+	return value, ok
+}
+
+// metaString extracts a string field from an event's MetaJSON, if present.
+func metaString(metaJSON *string, key string) (string, bool) {
+	if metaJSON == nil {
+		return "", false
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(*metaJSON), &meta); err != nil {
+		return "", false
+	}
+	value, ok := meta[key].(string)
+	return value, ok
+}
@@ -0,0 +1,173 @@
+package extractors
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func griefingSteamID(s string) *string { return &s }
+
+func teamKillEventAt(actor, victim string, round int, severity float64) Event {
+	return Event{
+		Type:          "TEAM_KILL",
+		RoundIndex:    round,
+		ActorSteamID:  griefingSteamID(actor),
+		VictimSteamID: griefingSteamID(victim),
+		Severity:      severity,
+		Confidence:    1.0,
+	}
+}
+
+func teamFlashEventAt(actor, victim string, round int, duration float64) Event {
+	metaJSON, _ := json.Marshal(map[string]interface{}{"blind_duration": duration})
+	metaJSONStr := string(metaJSON)
+	return Event{
+		Type:          "TEAM_FLASH",
+		RoundIndex:    round,
+		ActorSteamID:  griefingSteamID(actor),
+		VictimSteamID: griefingSteamID(victim),
+		Severity:      1.0,
+		Confidence:    1.0,
+		MetaJSON:      &metaJSONStr,
+	}
+}
+
+func abandonEventAt(actor string, round int, classification string) Event {
+	metaJSON, _ := json.Marshal(map[string]interface{}{"classification": classification})
+	metaJSONStr := string(metaJSON)
+	return Event{
+		Type:         "ABANDON",
+		RoundIndex:   round,
+		ActorSteamID: griefingSteamID(actor),
+		Severity:     0.4,
+		Confidence:   0.9,
+		MetaJSON:     &metaJSONStr,
+	}
+}
+
+func TestGriefingAggregatorIgnoresShortTeamFlash(t *testing.T) {
+	a := NewGriefingAggregator()
+	a.IngestSource("team_flash", []Event{teamFlashEventAt("1", "2", 1, 1.5)})
+
+	if len(a.samples["1"]) != 0 {
+		t.Errorf("expected short team flash to be ignored, got %d samples", len(a.samples["1"]))
+	}
+}
+
+func TestGriefingAggregatorCountsLongTeamFlash(t *testing.T) {
+	a := NewGriefingAggregator()
+	a.IngestSource("team_flash", []Event{teamFlashEventAt("1", "2", 1, 4.0)})
+
+	if len(a.samples["1"]) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(a.samples["1"]))
+	}
+	if a.samples["1"][0].severity != griefingWeightTeamFlash {
+		t.Errorf("expected severity %.2f, got %.2f", griefingWeightTeamFlash, a.samples["1"][0].severity)
+	}
+}
+
+func TestGriefingAggregatorSuicideWeightedLower(t *testing.T) {
+	a := NewGriefingAggregator()
+	a.IngestSource("team_kill", []Event{
+		teamKillEventAt("1", "1", 1, 1.0), // suicide: actor == victim
+		teamKillEventAt("2", "3", 1, 1.0), // team kill
+	})
+
+	if got := a.samples["1"][0].severity; got != griefingWeightSuicide {
+		t.Errorf("expected suicide weight %.2f, got %.2f", griefingWeightSuicide, got)
+	}
+	if got := a.samples["2"][0].severity; got != griefingWeightTeamKill {
+		t.Errorf("expected team kill weight %.2f, got %.2f", griefingWeightTeamKill, got)
+	}
+}
+
+func TestGriefingAggregatorIngestSourceNeverDoubleCounts(t *testing.T) {
+	a := NewGriefingAggregator()
+	events := []Event{teamKillEventAt("1", "2", 1, 1.0)}
+
+	a.IngestSource("team_kill", events)
+	a.IngestSource("team_kill", events) // same slice again, should be a no-op
+
+	events = append(events, teamKillEventAt("1", "2", 2, 1.0))
+	a.IngestSource("team_kill", events) // only the new entry should be picked up
+
+	if len(a.samples["1"]) != 2 {
+		t.Fatalf("expected 2 samples after growing the source slice, got %d", len(a.samples["1"]))
+	}
+}
+
+func TestGriefingAggregatorFiresThresholdAndRespectsHysteresis(t *testing.T) {
+	a := NewGriefingAggregator()
+
+	// Three team kills in round 1 clears the "low" threshold (2.0) without
+	// also clearing "medium" (4.0).
+	events := make([]Event, 0, 3)
+	for i := 0; i < 3; i++ {
+		events = append(events, teamKillEventAt("1", "2", 1, 1.0))
+	}
+	a.IngestSource("team_kill", events)
+
+	produced := a.Evaluate(1)
+	if len(produced) != 1 {
+		t.Fatalf("expected exactly 1 alert on first crossing, got %d", len(produced))
+	}
+
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(*produced[0].MetaJSON), &meta); err != nil {
+		t.Fatalf("failed to unmarshal alert meta: %v", err)
+	}
+	if meta["level"] != "low" {
+		t.Errorf("expected level 'low', got %v", meta["level"])
+	}
+
+	// Re-evaluating the same round without new events must not refire.
+	if produced := a.Evaluate(1); len(produced) != 0 {
+		t.Errorf("expected no refire while still above threshold, got %d alerts", len(produced))
+	}
+
+	// Far enough in the future that decay drops the score back under threshold.
+	if produced := a.Evaluate(50); len(produced) != 0 {
+		t.Errorf("expected no alert once score has decayed away, got %d", len(produced))
+	}
+}
+
+func TestGriefingAggregatorRageDisconnectCounted(t *testing.T) {
+	a := NewGriefingAggregator()
+	a.IngestSource("disconnect", []Event{
+		abandonEventAt("1", 1, DisconnectClassRageQuit),
+		abandonEventAt("2", 1, DisconnectClassTechnical), // not counted
+	})
+
+	if len(a.samples["1"]) != 1 {
+		t.Errorf("expected rage quit to be counted, got %d samples", len(a.samples["1"]))
+	}
+	if len(a.samples["2"]) != 0 {
+		t.Errorf("expected non-rage-quit classification to be ignored, got %d samples", len(a.samples["2"]))
+	}
+}
+
+func TestGriefingAggregatorTopOffenders(t *testing.T) {
+	a := NewGriefingAggregator()
+	a.IngestSource("team_kill", []Event{
+		teamKillEventAt("1", "9", 1, 1.0),
+		teamKillEventAt("2", "9", 1, 1.0),
+		teamKillEventAt("2", "9", 1, 1.0),
+	})
+
+	top := a.TopOffenders(1)
+	if len(top) != 1 || top[0].SteamID != "2" {
+		t.Fatalf("expected steamID 2 to be the top offender, got %+v", top)
+	}
+}
+
+func TestGriefingAggregatorReset(t *testing.T) {
+	a := NewGriefingAggregator()
+	a.IngestSource("team_kill", []Event{teamKillEventAt("1", "2", 1, 1.0)})
+	a.Evaluate(1)
+
+	a.Reset()
+
+	if len(a.samples) != 0 || len(a.states) != 0 || len(a.cursors) != 0 {
+		t.Errorf("expected Reset to clear all per-player state")
+	}
+}
@@ -0,0 +1,202 @@
+package extractors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// ErrPipelineRunning is returned by ParallelPipeline.ProcessRounds when a
+// session is already in flight on that pipeline; only one session runs at
+// a time, same contract as go-ethereum bloombits' Matcher.Start.
+var ErrPipelineRunning = errors.New("pipeline already running")
+
+// ExtractorFactory builds a fresh set of DBExtractors bound to dbConn, one
+// call per worker, so each goroutine only ever mutates extractors (and
+// their internal events slices) it privately owns.
+type ExtractorFactory func(dbConn *sql.DB) []DBExtractor
+
+// ParallelPipeline fans ProcessRoundFromDatabase calls for many rounds of
+// one match out across a worker pool, modeled on go-ethereum's bloombits
+// Matcher: workers pull round tasks off a retrievals channel, each against
+// its own *sql.DB connection (SQLite's WAL mode, enabled by db.Open, lets
+// these coexist with the writer connection that's still open elsewhere),
+// and send partial results back on a deliveries channel that a single
+// coordinator goroutine merges in round order.
+//
+// Only extractors whose ProcessRoundFromDatabase result depends solely on
+// that round's own rows belong here - BodyBlockExtractor,
+// TradeKillExtractor, FlashAssistExtractor and UtilityUsageExtractor all
+// qualify. AFKExtractor does NOT: it tracks player disconnect/movement
+// state that carries from one round into the next (see
+// TestProcessAFKFromDatabaseCarriesDisconnectFromPreviousRound), which
+// requires rounds to be processed in order on a single goroutine, so it
+// must keep running through the sequential Pipeline instead.
+type ParallelPipeline struct {
+	dbPath  string
+	workers int
+	factory ExtractorFactory
+	config  *RegistryConfig
+
+	running atomic.Bool
+}
+
+// NewParallelPipeline creates a pipeline that processes rounds of the
+// database at dbPath across workers goroutines, each one calling factory
+// to build its own private extractor set over its own connection. workers
+// <= 0 is treated as 1.
+func NewParallelPipeline(dbPath string, workers int, factory ExtractorFactory) *ParallelPipeline {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &ParallelPipeline{dbPath: dbPath, workers: workers, factory: factory}
+}
+
+// ApplyConfig loads the enable/disable config DBExtractor.Enabled checks
+// against, same contract as Pipeline.ApplyConfig.
+func (p *ParallelPipeline) ApplyConfig(config *RegistryConfig) {
+	p.config = config
+}
+
+// roundResult is one worker's answer for one RoundWindow, carried back to
+// the coordinator over the deliveries channel.
+type roundResult struct {
+	round  RoundWindow
+	events []Event
+	err    error
+}
+
+// ProcessRounds runs every round in rounds across the worker pool and
+// returns the combined events, sorted by (RoundIndex, StartTick) so the
+// result is deterministic regardless of which worker finishes which round
+// first. Returns ErrPipelineRunning if a session is already in flight on
+// this pipeline.
+func (p *ParallelPipeline) ProcessRounds(ctx context.Context, matchID string, rounds []RoundWindow) ([]Event, error) {
+	if !p.running.CompareAndSwap(false, true) {
+		return nil, ErrPipelineRunning
+	}
+	defer p.running.Store(false)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	retrievals := make(chan RoundWindow)
+	deliveries := make(chan roundResult)
+
+	var wg sync.WaitGroup
+	wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer wg.Done()
+			p.worker(ctx, matchID, retrievals, deliveries)
+		}()
+	}
+
+	go func() {
+		defer close(retrievals)
+		for _, round := range rounds {
+			select {
+			case retrievals <- round:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(deliveries)
+	}()
+
+	// The coordinator is the only goroutine reading deliveries, so merging
+	// into byRound needs no lock of its own - unlike the retrievals/
+	// deliveries channels, this map is never touched concurrently.
+	byRound := make(map[int][]Event, len(rounds))
+	var firstErr error
+	for result := range deliveries {
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+				cancel() // stop feeding/working once one round has failed
+			}
+			continue
+		}
+		byRound[result.round.RoundIndex] = result.events
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	indexes := make([]int, 0, len(byRound))
+	for idx := range byRound {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	all := make([]Event, 0, len(rounds))
+	for _, idx := range indexes {
+		events := byRound[idx]
+		sort.SliceStable(events, func(i, j int) bool { return events[i].StartTick < events[j].StartTick })
+		all = append(all, events...)
+	}
+	return all, nil
+}
+
+// worker opens its own database connection and a private extractor set via
+// p.factory, then processes rounds off retrievals until the channel closes
+// or ctx is cancelled. It uses db.OpenWorker rather than db.Open: the
+// writer connection ProcessRounds's caller already opened has migrated
+// p.dbPath before any round reaches the pipeline, so re-running InitSchema
+// per worker would just have every worker race to apply the same pending
+// migration against the same file.
+func (p *ParallelPipeline) worker(ctx context.Context, matchID string, retrievals <-chan RoundWindow, deliveries chan<- roundResult) {
+	dbConn, err := db.OpenWorker(ctx, p.dbPath)
+	if err != nil {
+		// Drain retrievals instead of returning immediately, so the feeder
+		// goroutine doesn't block forever sending to a worker that can
+		// never make progress.
+		for round := range retrievals {
+			select {
+			case deliveries <- roundResult{round: round, err: fmt.Errorf("opening worker connection: %w", err)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		return
+	}
+	defer dbConn.Close()
+
+	workerExtractors := p.factory(dbConn)
+
+	for round := range retrievals {
+		events, err := p.processRound(ctx, workerExtractors, matchID, round)
+		select {
+		case deliveries <- roundResult{round: round, events: events, err: err}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processRound runs every enabled extractor in workerExtractors against
+// round, the parallel-pipeline analogue of Pipeline.ProcessRound.
+func (p *ParallelPipeline) processRound(ctx context.Context, workerExtractors []DBExtractor, matchID string, round RoundWindow) ([]Event, error) {
+	var all []Event
+	for _, extractor := range workerExtractors {
+		if !extractor.Enabled(p.config) {
+			continue
+		}
+		events, err := extractor.ProcessRoundFromDatabase(ctx, matchID, round)
+		if err != nil {
+			return nil, fmt.Errorf("%s: round %d: %w", extractor.Name(), round.RoundIndex, err)
+		}
+		all = append(all, events...)
+	}
+	return all, nil
+}
@@ -0,0 +1,136 @@
+package extractors
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// fakeDBExtractor emits one deterministic event per round it's asked to
+// process, after first recording the call for assertions.
+type fakeDBExtractor struct {
+	name string
+
+	mu     sync.Mutex
+	seen   []int // RoundIndex values this instance actually processed
+	failOn int   // RoundIndex to fail on, or -1 for never
+}
+
+func newFakeDBExtractor(name string) *fakeDBExtractor {
+	return &fakeDBExtractor{name: name, failOn: -1}
+}
+
+func (f *fakeDBExtractor) Name() string                     { return f.name }
+func (f *fakeDBExtractor) Enabled(cfg *RegistryConfig) bool { return cfg.IsEnabled(f.name) }
+
+func (f *fakeDBExtractor) ProcessRoundFromDatabase(ctx context.Context, matchID string, round RoundWindow) ([]Event, error) {
+	f.mu.Lock()
+	f.seen = append(f.seen, round.RoundIndex)
+	f.mu.Unlock()
+
+	if round.RoundIndex == f.failOn {
+		return nil, fmt.Errorf("round %d: synthetic failure", round.RoundIndex)
+	}
+	return []Event{{Type: f.name, RoundIndex: round.RoundIndex, StartTick: round.StartTick}}, nil
+}
+
+func windowsForRounds(n int) []RoundWindow {
+	rounds := make([]RoundWindow, n)
+	for i := range rounds {
+		rounds[i] = RoundWindow{RoundIndex: i, StartTick: i * 1000, EndTick: i*1000 + 900}
+	}
+	return rounds
+}
+
+func TestParallelPipelineProcessesEveryRoundInOrder(t *testing.T) {
+	factory := func(dbConn *sql.DB) []DBExtractor {
+		return []DBExtractor{newFakeDBExtractor("fake")}
+	}
+	p := NewParallelPipeline(t.TempDir()+"/test.db", 4, factory)
+
+	events, err := p.ProcessRounds(context.Background(), "match-1", windowsForRounds(10))
+	if err != nil {
+		t.Fatalf("ProcessRounds failed: %v", err)
+	}
+	if len(events) != 10 {
+		t.Fatalf("got %d events, want 10", len(events))
+	}
+	for i, e := range events {
+		if e.RoundIndex != i {
+			t.Errorf("events[%d].RoundIndex = %d, want %d (expected sorted round order)", i, e.RoundIndex, i)
+		}
+	}
+}
+
+func TestParallelPipelinePropagatesExtractorError(t *testing.T) {
+	factory := func(dbConn *sql.DB) []DBExtractor {
+		f := newFakeDBExtractor("fake")
+		f.failOn = 3
+		return []DBExtractor{f}
+	}
+	p := NewParallelPipeline(t.TempDir()+"/test.db", 2, factory)
+
+	_, err := p.ProcessRounds(context.Background(), "match-1", windowsForRounds(5))
+	if err == nil {
+		t.Fatal("expected ProcessRounds to return the extractor's error")
+	}
+}
+
+func TestParallelPipelineRejectsConcurrentSessions(t *testing.T) {
+	release := make(chan struct{})
+	factory := func(dbConn *sql.DB) []DBExtractor {
+		return []DBExtractor{&blockingExtractor{release: release}}
+	}
+	p := NewParallelPipeline(t.TempDir()+"/test.db", 1, factory)
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = p.ProcessRounds(context.Background(), "match-1", windowsForRounds(1))
+		close(done)
+	}()
+
+	// Give the first session a moment to mark itself running before the
+	// second session tries to start.
+	<-waitForRunning(p)
+
+	if _, err := p.ProcessRounds(context.Background(), "match-1", windowsForRounds(1)); err != ErrPipelineRunning {
+		t.Errorf("second ProcessRounds = %v, want ErrPipelineRunning", err)
+	}
+
+	close(release)
+	<-done
+}
+
+// blockingExtractor processes its one round only after release is closed,
+// giving TestParallelPipelineRejectsConcurrentSessions a window in which
+// the first session is guaranteed still running.
+type blockingExtractor struct {
+	release chan struct{}
+}
+
+func (b *blockingExtractor) Name() string                     { return "blocking" }
+func (b *blockingExtractor) Enabled(cfg *RegistryConfig) bool { return true }
+func (b *blockingExtractor) ProcessRoundFromDatabase(ctx context.Context, matchID string, round RoundWindow) ([]Event, error) {
+	<-b.release
+	return nil, nil
+}
+
+// waitForRunning polls p.running until it flips true, returning a channel
+// that's closed once it has (or immediately if it never does, bounding the
+// wait so a broken guard fails the test instead of hanging it).
+func waitForRunning(p *ParallelPipeline) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for i := 0; i < 100000; i++ {
+			if p.running.Load() {
+				return
+			}
+			runtime.Gosched()
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,206 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+)
+
+// RoundWindow is the round-level tick window a DBExtractor needs to re-query
+// player_positions/events for one round. It reconciles AFKExtractor's
+// (freezeEndTick, roundEndTick) window with BodyBlockExtractor's
+// (startTick, endTick) window into a single shape every DBExtractor sees.
+type RoundWindow struct {
+	RoundIndex    int
+	StartTick     int
+	FreezeEndTick *int // nil if this round has no recorded freeze end tick
+	EndTick       int
+}
+
+// DBExtractor is a post-parse, database-query-driven detector: unlike
+// BehaviorExtractor (which reacts to live demoinfocs callbacks as the demo
+// streams by), a DBExtractor re-reads already-persisted rows for a round, so
+// it can look both forward and backward within the round instead of only at
+// what's already happened by the time a callback fires. AFK and body-block
+// detection are both this shape; Pipeline lets callers register more
+// (trade kills, flash-assist attribution, utility usage, ...) without the
+// runner in cmd/parser hardcoding another loop per detector.
+type DBExtractor interface {
+	// Name identifies this extractor in config (enable/disable list) and
+	// logs, same contract as BehaviorExtractor.Name.
+	Name() string
+
+	// Enabled reports whether cfg has this extractor turned on. cfg may be
+	// nil (no config loaded), in which case every extractor defaults to
+	// enabled.
+	Enabled(cfg *RegistryConfig) bool
+
+	// ProcessRoundFromDatabase re-reads matchID/round's rows from the
+	// database and returns whatever events it detected.
+	ProcessRoundFromDatabase(ctx context.Context, matchID string, round RoundWindow) ([]Event, error)
+}
+
+// Pipeline runs every registered DBExtractor once per round, replacing the
+// hand-duplicated AFK/body-block loops that used to be copy-pasted across
+// cmd/parser/main.go's RAM-only and streaming branches.
+type Pipeline struct {
+	extractors []DBExtractor
+	config     *RegistryConfig
+}
+
+// NewPipeline creates an empty Pipeline. Every registered extractor runs
+// unless ApplyConfig loads a disable flag for its Name().
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// RegisterExtractor adds extractor to the pipeline. Extractors run in
+// registration order; third parties add their own detector by implementing
+// DBExtractor and calling this instead of the runner growing another loop.
+func (p *Pipeline) RegisterExtractor(extractor DBExtractor) {
+	p.extractors = append(p.extractors, extractor)
+}
+
+// ApplyConfig loads the enable/disable config DBExtractor.Enabled checks
+// against. Shares RegistryConfig with BehaviorExtractor's ExtractorRegistry
+// so one config file gates both kinds of extractor.
+func (p *Pipeline) ApplyConfig(config *RegistryConfig) {
+	p.config = config
+}
+
+// ProcessRound runs every enabled extractor against round and returns the
+// combined events it detected.
+func (p *Pipeline) ProcessRound(ctx context.Context, matchID string, round RoundWindow) ([]Event, error) {
+	var all []Event
+	for _, extractor := range p.extractors {
+		if !extractor.Enabled(p.config) {
+			continue
+		}
+		events, err := extractor.ProcessRoundFromDatabase(ctx, matchID, round)
+		if err != nil {
+			return all, fmt.Errorf("%s: %w", extractor.Name(), err)
+		}
+		all = append(all, events...)
+	}
+	return all, nil
+}
+
+// IsEnabled reports whether name is enabled per cfg. A cfg built by
+// ParseEnabledExtractors enables only its listed names; otherwise it
+// defaults to true for names with no override and for a nil cfg (no config
+// loaded).
+func (c *RegistryConfig) IsEnabled(name string) bool {
+	if c == nil {
+		return true
+	}
+	if c.enabledOnly != nil {
+		return c.enabledOnly[name]
+	}
+	override, ok := c.overrides[name]
+	if !ok {
+		return true
+	}
+	return override.Enabled
+}
+
+// afkDBExtractor adapts AFKExtractor to DBExtractor without changing its
+// existing ProcessAFKFromDatabase signature, which parser.go's live
+// tracking hooks still call directly today.
+type afkDBExtractor struct {
+	extractor *AFKExtractor
+}
+
+// NewAFKDBExtractor adapts extractor for use with Pipeline.
+func NewAFKDBExtractor(extractor *AFKExtractor) DBExtractor {
+	return &afkDBExtractor{extractor: extractor}
+}
+
+func (a *afkDBExtractor) Name() string                     { return "afk" }
+func (a *afkDBExtractor) Enabled(cfg *RegistryConfig) bool { return cfg.IsEnabled(a.Name()) }
+func (a *afkDBExtractor) ProcessRoundFromDatabase(ctx context.Context, matchID string, round RoundWindow) ([]Event, error) {
+	if round.FreezeEndTick == nil {
+		return nil, nil // matches the prior runner's "skip rounds without freeze end tick"
+	}
+	before := len(a.extractor.events)
+	if err := a.extractor.ProcessAFKFromDatabase(matchID, round.RoundIndex, *round.FreezeEndTick, round.EndTick); err != nil {
+		return nil, err
+	}
+	return a.extractor.events[before:], nil
+}
+
+// bodyBlockDBExtractor adapts BodyBlockExtractor to DBExtractor.
+type bodyBlockDBExtractor struct {
+	extractor *BodyBlockExtractor
+}
+
+// NewBodyBlockDBExtractor adapts extractor for use with Pipeline.
+func NewBodyBlockDBExtractor(extractor *BodyBlockExtractor) DBExtractor {
+	return &bodyBlockDBExtractor{extractor: extractor}
+}
+
+func (a *bodyBlockDBExtractor) Name() string                     { return "body_block" }
+func (a *bodyBlockDBExtractor) Enabled(cfg *RegistryConfig) bool { return cfg.IsEnabled(a.Name()) }
+func (a *bodyBlockDBExtractor) ProcessRoundFromDatabase(ctx context.Context, matchID string, round RoundWindow) ([]Event, error) {
+	before := len(a.extractor.events)
+	a.extractor.ProcessRoundFromDatabase(matchID, round.RoundIndex, round.StartTick, round.EndTick)
+	return a.extractor.events[before:], nil
+}
+
+// tradeKillDBExtractor adapts TradeKillExtractor to DBExtractor.
+type tradeKillDBExtractor struct {
+	extractor *TradeKillExtractor
+}
+
+// NewTradeKillDBExtractor adapts extractor for use with Pipeline.
+func NewTradeKillDBExtractor(extractor *TradeKillExtractor) DBExtractor {
+	return &tradeKillDBExtractor{extractor: extractor}
+}
+
+func (a *tradeKillDBExtractor) Name() string                     { return "trade_kill" }
+func (a *tradeKillDBExtractor) Enabled(cfg *RegistryConfig) bool { return cfg.IsEnabled(a.Name()) }
+func (a *tradeKillDBExtractor) ProcessRoundFromDatabase(ctx context.Context, matchID string, round RoundWindow) ([]Event, error) {
+	before := len(a.extractor.events)
+	if err := a.extractor.ProcessRoundFromDatabase(matchID, round.RoundIndex); err != nil {
+		return nil, err
+	}
+	return a.extractor.events[before:], nil
+}
+
+// flashAssistDBExtractor adapts FlashAssistExtractor to DBExtractor.
+type flashAssistDBExtractor struct {
+	extractor *FlashAssistExtractor
+}
+
+// NewFlashAssistDBExtractor adapts extractor for use with Pipeline.
+func NewFlashAssistDBExtractor(extractor *FlashAssistExtractor) DBExtractor {
+	return &flashAssistDBExtractor{extractor: extractor}
+}
+
+func (a *flashAssistDBExtractor) Name() string                     { return "flash_assist" }
+func (a *flashAssistDBExtractor) Enabled(cfg *RegistryConfig) bool { return cfg.IsEnabled(a.Name()) }
+func (a *flashAssistDBExtractor) ProcessRoundFromDatabase(ctx context.Context, matchID string, round RoundWindow) ([]Event, error) {
+	before := len(a.extractor.events)
+	if err := a.extractor.ProcessRoundFromDatabase(matchID, round.RoundIndex); err != nil {
+		return nil, err
+	}
+	return a.extractor.events[before:], nil
+}
+
+// utilityUsageDBExtractor adapts UtilityUsageExtractor to DBExtractor.
+type utilityUsageDBExtractor struct {
+	extractor *UtilityUsageExtractor
+}
+
+// NewUtilityUsageDBExtractor adapts extractor for use with Pipeline.
+func NewUtilityUsageDBExtractor(extractor *UtilityUsageExtractor) DBExtractor {
+	return &utilityUsageDBExtractor{extractor: extractor}
+}
+
+func (a *utilityUsageDBExtractor) Name() string                     { return "utility_usage" }
+func (a *utilityUsageDBExtractor) Enabled(cfg *RegistryConfig) bool { return cfg.IsEnabled(a.Name()) }
+func (a *utilityUsageDBExtractor) ProcessRoundFromDatabase(ctx context.Context, matchID string, round RoundWindow) ([]Event, error) {
+	before := len(a.extractor.events)
+	if err := a.extractor.ProcessRoundFromDatabase(matchID, round.RoundIndex); err != nil {
+		return nil, err
+	}
+	return a.extractor.events[before:], nil
+}
@@ -0,0 +1,112 @@
+package extractors
+
+import "testing"
+
+func TestMemPositionStorePositionAt(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	store.AddPosition("m1", 0, "steam1", 100, position{X: 1, Y: 2, Z: 3})
+	store.AddPosition("m1", 0, "steam1", 200, position{X: 4, Y: 5, Z: 6})
+
+	pos, ok := store.PositionAt("m1", 0, "steam1", 100)
+	if !ok || pos != (position{X: 1, Y: 2, Z: 3}) {
+		t.Fatalf("PositionAt(100) = %v, %v", pos, ok)
+	}
+
+	if _, ok := store.PositionAt("m1", 0, "steam1", 150); ok {
+		t.Fatalf("PositionAt(150) should miss, no sample recorded at that exact tick")
+	}
+
+	if _, ok := store.PositionAt("m1", 0, "steam2", 100); ok {
+		t.Fatalf("PositionAt should not find a different steamid's sample")
+	}
+
+	if _, ok := store.PositionAt("m2", 0, "steam1", 100); ok {
+		t.Fatalf("PositionAt should not cross matchID boundaries")
+	}
+}
+
+func TestMemPositionStoreFirstPositionFrom(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	store.AddPosition("m1", 0, "steam1", 100, position{X: 1})
+	store.AddPosition("m1", 0, "steam1", 200, position{X: 2})
+	store.AddPosition("m1", 0, "steam1", 300, position{X: 3})
+
+	sample, ok := store.FirstPositionFrom("m1", 0, "steam1", 150)
+	if !ok || sample.Tick != 200 || sample.Pos.X != 2 {
+		t.Fatalf("FirstPositionFrom(150) = %+v, %v", sample, ok)
+	}
+
+	if _, ok := store.FirstPositionFrom("m1", 0, "steam1", 301); ok {
+		t.Fatalf("FirstPositionFrom past the last sample should miss")
+	}
+}
+
+func TestMemPositionStorePositionsInRound(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	store.AddPosition("m1", 0, "steam2", 150, position{X: 2})
+	store.AddPosition("m1", 0, "steam1", 100, position{X: 1})
+	store.AddPosition("m1", 0, "steam1", 300, position{X: 3})
+
+	samples := store.PositionsInRound("m1", 0, 100, 150)
+	if len(samples) != 2 {
+		t.Fatalf("PositionsInRound(100,150) returned %d samples, want 2", len(samples))
+	}
+	if samples[0].Tick != 100 || samples[1].Tick != 150 {
+		t.Fatalf("PositionsInRound not ordered by tick: %+v", samples)
+	}
+}
+
+func TestMemPositionStorePlayersInRound(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	store.AddPosition("m1", 0, "steam2", 100, position{})
+	store.AddPosition("m1", 0, "steam1", 100, position{})
+	store.AddPosition("m1", 1, "steam3", 100, position{})
+
+	players := store.PlayersInRound("m1", 0)
+	if len(players) != 2 || players[0] != "steam1" || players[1] != "steam2" {
+		t.Fatalf("PlayersInRound(0) = %v, want sorted [steam1 steam2]", players)
+	}
+}
+
+func TestMemPositionStoreDeathTick(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	store.AddDeath("m1", 0, "steam1", 500)
+	store.AddDeath("m1", 0, "steam1", 300) // earlier death should win
+
+	tick, ok := store.DeathTick("m1", 0, "steam1")
+	if !ok || tick != 300 {
+		t.Fatalf("DeathTick = %d, %v, want 300, true", tick, ok)
+	}
+
+	if _, ok := store.DeathTick("m1", 0, "steam2"); ok {
+		t.Fatalf("DeathTick should miss for a steamid with no recorded death")
+	}
+}
+
+func TestMemPositionStoreDisconnects(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	endTick := 400
+	store.AddDisconnect("m1", "steam1", DisconnectInterval{RoundIndex: 1, StartTick: 200, EndTick: &endTick})
+	store.AddDisconnect("m1", "steam1", DisconnectInterval{RoundIndex: 0, StartTick: 50})
+
+	intervals := store.Disconnects("m1", "steam1")
+	if len(intervals) != 2 {
+		t.Fatalf("Disconnects returned %d intervals, want 2", len(intervals))
+	}
+	if intervals[0].StartTick != 50 || intervals[1].StartTick != 200 {
+		t.Fatalf("Disconnects not ordered by StartTick: %+v", intervals)
+	}
+	if intervals[0].EndTick != nil {
+		t.Fatalf("Disconnects[0].EndTick = %v, want nil (never reconnected)", intervals[0].EndTick)
+	}
+	if intervals[1].EndTick == nil || *intervals[1].EndTick != 400 {
+		t.Fatalf("Disconnects[1].EndTick = %v, want 400", intervals[1].EndTick)
+	}
+}
+
+func TestMemPositionStoreLoadMatchNoopWithNilDB(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	if err := store.LoadMatch("m1"); err != nil {
+		t.Fatalf("LoadMatch with a nil db should no-op, got %v", err)
+	}
+}
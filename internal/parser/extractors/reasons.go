@@ -0,0 +1,230 @@
+package extractors
+
+import "fmt"
+
+// ReasonCode is a stable, numeric identifier for a specific griefing
+// sub-category - "why" a given Event was flagged, one level more specific
+// than Event.Type. Codes are grouped into hex families so a UI or report
+// generator can bucket by family (0x0_ direct-fire team kills, 0x1_
+// splash/utility damage, 0x2_ body-blocking, 0x3_ economy griefing, 0x4_
+// AFK, 0x5_ team flashing, 0x6_ disconnects) without parsing free-form
+// metadata strings. This is the same idea as decoding a Solidity panic
+// code into a human-readable category: the number is what gets stored and
+// compared across versions, the text in reasonDescriptions is what gets
+// shown to a person.
+type ReasonCode uint16
+
+const (
+	// 0x0_: direct-fire team kills, by weapon class.
+	ReasonTeamKillRifle  ReasonCode = 0x01
+	ReasonTeamKillSMG    ReasonCode = 0x02
+	ReasonTeamKillPistol ReasonCode = 0x03
+	ReasonTeamKillMelee  ReasonCode = 0x04
+	ReasonTeamKillOther  ReasonCode = 0x0F
+
+	// 0x1_: splash and utility damage, both lethal (TEAM_KILL with an
+	// explosive weapon) and non-lethal (TEAM_DAMAGE classified via
+	// classifyDamageCause).
+	ReasonSplashGrenadeKill ReasonCode = 0x11
+	ReasonSplashHEDamage    ReasonCode = 0x12
+	ReasonSplashMolotovBurn ReasonCode = 0x13
+	ReasonSplashC4Explosion ReasonCode = 0x14
+	ReasonDirectFireDamage  ReasonCode = 0x15
+	ReasonSplashOther       ReasonCode = 0x1F
+
+	// 0x2_: body-blocking, by how long the block lasted.
+	ReasonBodyBlockBrief     ReasonCode = 0x21
+	ReasonBodyBlockSustained ReasonCode = 0x22
+
+	// 0x3_: economy griefing, one code per EconomyExtractor griefType.
+	ReasonEconomyEquipmentMismatch ReasonCode = 0x31
+	ReasonEconomyNoBuyWithTeam     ReasonCode = 0x32
+	ReasonEconomyExcessiveSaving   ReasonCode = 0x33
+	ReasonEconomyFullSaveHighMoney ReasonCode = 0x34
+
+	// 0x4_: AFK, by how it ended and how long it lasted.
+	ReasonAFKBrief     ReasonCode = 0x41
+	ReasonAFKProlonged ReasonCode = 0x42
+	ReasonAFKDied      ReasonCode = 0x43
+
+	// 0x5_: team flashing, by total blind duration.
+	ReasonFlashBrief     ReasonCode = 0x51
+	ReasonFlashProlonged ReasonCode = 0x52
+
+	// 0x6_: disconnects/abandons, one code per DisconnectClass.
+	ReasonDisconnectRageQuit   ReasonCode = 0x61
+	ReasonDisconnectTimeout    ReasonCode = 0x62
+	ReasonDisconnectKick       ReasonCode = 0x63
+	ReasonDisconnectAbandon    ReasonCode = 0x64
+	ReasonDisconnectTechnical  ReasonCode = 0x65
+	ReasonDisconnectVoteKicked ReasonCode = 0x66
+)
+
+// reasonDescriptions is the central code->text map every extractor draws
+// from when it populates an Event's "reason" meta field. Keeping this in
+// one place (rather than inlining strings at each call site) is what lets
+// DescribeReason stay a stable vocabulary across extractor changes.
+var reasonDescriptions = map[ReasonCode]string{
+	ReasonTeamKillRifle:  "team-kill with rifle",
+	ReasonTeamKillSMG:    "team-kill with SMG",
+	ReasonTeamKillPistol: "team-kill with pistol",
+	ReasonTeamKillMelee:  "team-kill with melee weapon",
+	ReasonTeamKillOther:  "team-kill with unclassified weapon",
+
+	ReasonSplashGrenadeKill: "nade splash team-kill",
+	ReasonSplashHEDamage:    "HE grenade splash damage to teammate",
+	ReasonSplashMolotovBurn: "molotov/incendiary burn damage to teammate",
+	ReasonSplashC4Explosion: "C4 explosion damage to teammate",
+	ReasonDirectFireDamage:  "direct gunfire damage to teammate",
+	ReasonSplashOther:       "unclassified damage to teammate",
+
+	ReasonBodyBlockBrief:     "brief body-block of a teammate",
+	ReasonBodyBlockSustained: "sustained body-block of a teammate",
+
+	ReasonEconomyEquipmentMismatch: "bought well below the team's buy tier",
+	ReasonEconomyNoBuyWithTeam:     "didn't buy while the team bought in",
+	ReasonEconomyExcessiveSaving:   "excessive saving while the team spent",
+	ReasonEconomyFullSaveHighMoney: "full save despite a high bankroll",
+
+	ReasonAFKBrief:     "brief stillness after freeze time",
+	ReasonAFKProlonged: "prolonged AFK after freeze time",
+	ReasonAFKDied:      "died while AFK",
+
+	ReasonFlashBrief:     "brief flash of a teammate",
+	ReasonFlashProlonged: "prolonged flash of a teammate",
+
+	ReasonDisconnectRageQuit:   "rage quit after an adverse event",
+	ReasonDisconnectTimeout:    "disconnected due to network timeout",
+	ReasonDisconnectKick:       "kicked by the server",
+	ReasonDisconnectAbandon:    "abandoned the match without reconnecting",
+	ReasonDisconnectTechnical:  "disconnected for an unclassified technical reason",
+	ReasonDisconnectVoteKicked: "removed by a player vote-kick",
+}
+
+// DescribeReason returns code's human-readable explanation, or a
+// placeholder for a code this version of the package doesn't recognize -
+// e.g. a row written by a newer build being read by an older one.
+func DescribeReason(code ReasonCode) string {
+	if desc, ok := reasonDescriptions[code]; ok {
+		return desc
+	}
+	return fmt.Sprintf("unrecognized reason code 0x%02x", uint16(code))
+}
+
+// classifyTeamKillReason buckets a TEAM_KILL's weapon string (as produced
+// by common.EquipmentType.String(), the same strings isRifle/isSMG/isPistol
+// already classify for economy snapshots) into a ReasonCode.
+func classifyTeamKillReason(weapon string) ReasonCode {
+	switch {
+	case weapon == "":
+		return ReasonTeamKillOther
+	case weapon == "HE Grenade" || weapon == "Molotov" || weapon == "Incendiary Grenade" || weapon == "Decoy Grenade":
+		return ReasonSplashGrenadeKill
+	case isRifle(weapon):
+		return ReasonTeamKillRifle
+	case isSMG(weapon):
+		return ReasonTeamKillSMG
+	case isPistol(weapon):
+		return ReasonTeamKillPistol
+	case weapon == "Knife":
+		return ReasonTeamKillMelee
+	default:
+		return ReasonTeamKillOther
+	}
+}
+
+// classifyTeamDamageReason maps a classifyDamageCause result to the
+// ReasonCode family that describes the mechanism of the TEAM_DAMAGE event.
+func classifyTeamDamageReason(cause DamageCause) ReasonCode {
+	switch cause {
+	case DamageCauseGrenadeSplash:
+		return ReasonSplashHEDamage
+	case DamageCauseMolotovBurn:
+		return ReasonSplashMolotovBurn
+	case DamageCauseC4Explosion:
+		return ReasonSplashC4Explosion
+	case DamageCauseDirectFire:
+		return ReasonDirectFireDamage
+	default:
+		return ReasonSplashOther
+	}
+}
+
+// bodyBlockSustainedSeconds is the threshold past which a body-block is
+// reported as "sustained" rather than "brief" - long enough that it's
+// plausibly deliberate rather than the two players just crossing paths.
+const bodyBlockSustainedSeconds = 3.0
+
+// classifyBodyBlockReason buckets a finalized BODY_BLOCK interval by
+// duration.
+func classifyBodyBlockReason(seconds float64) ReasonCode {
+	if seconds >= bodyBlockSustainedSeconds {
+		return ReasonBodyBlockSustained
+	}
+	return ReasonBodyBlockBrief
+}
+
+// classifyEconomyGriefReason maps one of detectEconomyGriefing's griefType
+// strings to its ReasonCode.
+func classifyEconomyGriefReason(griefType string) ReasonCode {
+	switch griefType {
+	case "equipment_mismatch":
+		return ReasonEconomyEquipmentMismatch
+	case "no_buy_with_team":
+		return ReasonEconomyNoBuyWithTeam
+	case "excessive_saving":
+		return ReasonEconomyExcessiveSaving
+	case "full_save_high_money":
+		return ReasonEconomyFullSaveHighMoney
+	default:
+		return ReasonEconomyEquipmentMismatch
+	}
+}
+
+// afkProlongedSeconds is the threshold past which an AFK interval is
+// reported as "prolonged" rather than "brief".
+const afkProlongedSeconds = 15.0
+
+// classifyAFKReason buckets a finalized AFK_STILLNESS interval by whether
+// the player died while AFK and, if not, by duration.
+func classifyAFKReason(seconds float64, diedWhileAFK bool) ReasonCode {
+	if diedWhileAFK {
+		return ReasonAFKDied
+	}
+	if seconds >= afkProlongedSeconds {
+		return ReasonAFKProlonged
+	}
+	return ReasonAFKBrief
+}
+
+// flashProlongedSeconds is the threshold past which a merged TEAM_FLASH
+// entry is reported as "prolonged" rather than "brief".
+const flashProlongedSeconds = 3.0
+
+// classifyTeamFlashReason buckets a finalized TEAM_FLASH entry by total
+// blind duration.
+func classifyTeamFlashReason(totalBlindSeconds float64) ReasonCode {
+	if totalBlindSeconds >= flashProlongedSeconds {
+		return ReasonFlashProlonged
+	}
+	return ReasonFlashBrief
+}
+
+// classifyDisconnectReason maps one of ClassifyDisconnects' DisconnectClass
+// constants to its ReasonCode.
+func classifyDisconnectReason(classification string) ReasonCode {
+	switch classification {
+	case DisconnectClassRageQuit:
+		return ReasonDisconnectRageQuit
+	case DisconnectClassTimeout:
+		return ReasonDisconnectTimeout
+	case DisconnectClassKick:
+		return ReasonDisconnectKick
+	case DisconnectClassAbandon:
+		return ReasonDisconnectAbandon
+	case DisconnectClassVoteKicked:
+		return ReasonDisconnectVoteKicked
+	default:
+		return ReasonDisconnectTechnical
+	}
+}
@@ -0,0 +1,124 @@
+package extractors
+
+import "testing"
+
+func TestClassifyTeamKillReason(t *testing.T) {
+	cases := []struct {
+		name   string
+		weapon string
+		want   ReasonCode
+	}{
+		{"no weapon info", "", ReasonTeamKillOther},
+		{"he grenade", "HE Grenade", ReasonSplashGrenadeKill},
+		{"molotov", "Molotov", ReasonSplashGrenadeKill},
+		{"incendiary", "Incendiary Grenade", ReasonSplashGrenadeKill},
+		{"decoy", "Decoy Grenade", ReasonSplashGrenadeKill},
+		{"rifle", "AK-47", ReasonTeamKillRifle},
+		{"smg", "MP9", ReasonTeamKillSMG},
+		{"pistol", "Glock-18", ReasonTeamKillPistol},
+		{"knife", "Knife", ReasonTeamKillMelee},
+		{"unclassified", "Zeus x27", ReasonTeamKillOther},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := classifyTeamKillReason(tc.weapon)
+			if got != tc.want {
+				t.Errorf("classifyTeamKillReason(%q) = 0x%02x, want 0x%02x", tc.weapon, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClassifyTeamDamageReason(t *testing.T) {
+	cases := []struct {
+		cause DamageCause
+		want  ReasonCode
+	}{
+		{DamageCauseGrenadeSplash, ReasonSplashHEDamage},
+		{DamageCauseMolotovBurn, ReasonSplashMolotovBurn},
+		{DamageCauseC4Explosion, ReasonSplashC4Explosion},
+		{DamageCauseDirectFire, ReasonDirectFireDamage},
+		{DamageCauseSuicide, ReasonSplashOther},
+		{DamageCauseWorld, ReasonSplashOther},
+	}
+
+	for _, tc := range cases {
+		got := classifyTeamDamageReason(tc.cause)
+		if got != tc.want {
+			t.Errorf("classifyTeamDamageReason(%s) = 0x%02x, want 0x%02x", tc.cause, got, tc.want)
+		}
+	}
+}
+
+func TestClassifyBodyBlockReason(t *testing.T) {
+	if got := classifyBodyBlockReason(1.5); got != ReasonBodyBlockBrief {
+		t.Errorf("classifyBodyBlockReason(1.5) = 0x%02x, want brief", got)
+	}
+	if got := classifyBodyBlockReason(bodyBlockSustainedSeconds); got != ReasonBodyBlockSustained {
+		t.Errorf("classifyBodyBlockReason(%v) = 0x%02x, want sustained", bodyBlockSustainedSeconds, got)
+	}
+}
+
+func TestClassifyEconomyGriefReason(t *testing.T) {
+	cases := map[string]ReasonCode{
+		"equipment_mismatch":    ReasonEconomyEquipmentMismatch,
+		"no_buy_with_team":      ReasonEconomyNoBuyWithTeam,
+		"excessive_saving":      ReasonEconomyExcessiveSaving,
+		"full_save_high_money":  ReasonEconomyFullSaveHighMoney,
+		"unrecognized_type_xyz": ReasonEconomyEquipmentMismatch,
+	}
+
+	for griefType, want := range cases {
+		if got := classifyEconomyGriefReason(griefType); got != want {
+			t.Errorf("classifyEconomyGriefReason(%q) = 0x%02x, want 0x%02x", griefType, got, want)
+		}
+	}
+}
+
+func TestClassifyAFKReason(t *testing.T) {
+	if got := classifyAFKReason(5, false); got != ReasonAFKBrief {
+		t.Errorf("classifyAFKReason(5, false) = 0x%02x, want brief", got)
+	}
+	if got := classifyAFKReason(afkProlongedSeconds, false); got != ReasonAFKProlonged {
+		t.Errorf("classifyAFKReason(%v, false) = 0x%02x, want prolonged", afkProlongedSeconds, got)
+	}
+	if got := classifyAFKReason(5, true); got != ReasonAFKDied {
+		t.Errorf("classifyAFKReason(5, true) = 0x%02x, want died", got)
+	}
+}
+
+func TestClassifyTeamFlashReason(t *testing.T) {
+	if got := classifyTeamFlashReason(1); got != ReasonFlashBrief {
+		t.Errorf("classifyTeamFlashReason(1) = 0x%02x, want brief", got)
+	}
+	if got := classifyTeamFlashReason(flashProlongedSeconds); got != ReasonFlashProlonged {
+		t.Errorf("classifyTeamFlashReason(%v) = 0x%02x, want prolonged", flashProlongedSeconds, got)
+	}
+}
+
+func TestClassifyDisconnectReason(t *testing.T) {
+	cases := map[string]ReasonCode{
+		DisconnectClassRageQuit:   ReasonDisconnectRageQuit,
+		DisconnectClassTimeout:    ReasonDisconnectTimeout,
+		DisconnectClassKick:       ReasonDisconnectKick,
+		DisconnectClassAbandon:    ReasonDisconnectAbandon,
+		DisconnectClassVoteKicked: ReasonDisconnectVoteKicked,
+		"unrecognized_class":      ReasonDisconnectTechnical,
+	}
+
+	for classification, want := range cases {
+		if got := classifyDisconnectReason(classification); got != want {
+			t.Errorf("classifyDisconnectReason(%q) = 0x%02x, want 0x%02x", classification, got, want)
+		}
+	}
+}
+
+func TestDescribeReason(t *testing.T) {
+	if got := DescribeReason(ReasonTeamKillRifle); got == "" {
+		t.Error("expected a non-empty description for a known reason code")
+	}
+	if got := DescribeReason(ReasonCode(0xAB)); got != "unrecognized reason code 0xab" {
+		t.Errorf("DescribeReason(0xAB) = %q, want placeholder text", got)
+	}
+}
@@ -9,35 +9,35 @@ import (
 	events "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/events"
 )
 
-// TeamDamageExtractor extracts team damage events from PlayerHurt events.
-// It merges events within a time window (2 seconds).
+// TeamDamageExtractor extracts team damage events from PlayerHurt events,
+// coalescing consecutive hits between the same attacker, victim and weapon
+// via an Accumulator instead of emitting one event per hit.
 type TeamDamageExtractor struct {
-	pending  map[string]*pendingDamage // key: roundIndex_attackerSteamID_victimSteamID
-	tickRate float64
-	events   []Event
+	acc       *Accumulator
+	filter    *DamageCauseFilter
+	hitgroups map[string]map[string]int // accumulator key -> hitgroup -> count
+	isUtility map[string]bool           // accumulator key -> whether the merged weapon is utility
+	cause     map[string]DamageCause    // accumulator key -> classified damage cause
+	events    []Event
 }
 
-type pendingDamage struct {
-	roundIndex      int
-	attackerSteamID string
-	victimSteamID   string
-	startTick       int
-	lastTick        int
-	totalHealth     int
-	totalArmor      int
-	hitCount        int
-	hitgroups       map[string]int  // hitgroup -> count
-	weapons         map[string]bool // weapon -> exists
-	isUtility       bool
-}
-
-// NewTeamDamageExtractor creates a new team damage extractor.
-func NewTeamDamageExtractor(tickRate float64) *TeamDamageExtractor {
-	return &TeamDamageExtractor{
-		pending:  make(map[string]*pendingDamage),
-		tickRate: tickRate,
-		events:   make([]Event, 0),
+// NewTeamDamageExtractor creates a new team damage extractor, merging hits
+// within a 2-second window (tickRate * 2). A nil filter falls back to
+// DefaultDamageCauseFilter, denying SUICIDE and WORLD.
+func NewTeamDamageExtractor(tickRate float64, filter *DamageCauseFilter) *TeamDamageExtractor {
+	if filter == nil {
+		filter = DefaultDamageCauseFilter()
 	}
+	e := &TeamDamageExtractor{
+		filter:    filter,
+		hitgroups: make(map[string]map[string]int),
+		isUtility: make(map[string]bool),
+		cause:     make(map[string]DamageCause),
+		events:    make([]Event, 0),
+	}
+	windowTicks := int(tickRate * 2.0)
+	e.acc = NewAccumulator(windowTicks, e.finalizeEntry)
+	return e
 }
 
 // HandlePlayerHurt processes a PlayerHurt event and accumulates team damage.
@@ -66,149 +66,102 @@ func (e *TeamDamageExtractor) HandlePlayerHurt(event events.PlayerHurt, roundInd
 		return
 	}
 
-	key := fmt.Sprintf("%d_%s_%s", roundIndex, *attackerSteamID, *victimSteamID)
-
-	// Check if we have a pending event within the merge window
-	mergeWindowTicks := int(e.tickRate * 2.0) // 2 seconds
-	pending, exists := e.pending[key]
-
-	if exists && (tick-pending.lastTick) <= mergeWindowTicks {
-		// Merge into existing pending event
-		pending.lastTick = tick
-		pending.totalHealth += event.HealthDamage
-		pending.totalArmor += event.ArmorDamage
-		pending.hitCount++
-
-		// Track hitgroups (HitGroup is an enum, 0 is generic/unknown)
-		if event.HitGroup != 0 {
-			hitgroupStr := fmt.Sprintf("%d", int(event.HitGroup))
-			pending.hitgroups[hitgroupStr]++
-		}
-
-		// Track weapons
-		if event.Weapon != nil {
-			weaponType := event.Weapon.Type
-			if weaponType != common.EqUnknown {
-				weaponStr := weaponType.String()
-				pending.weapons[weaponStr] = true
-			} else {
-				weaponStr := event.Weapon.String()
-				pending.weapons[weaponStr] = true
-			}
-		}
-
-		// Check if utility
-		if event.Weapon != nil {
-			weaponType := event.Weapon.Type
-			weaponTypeStr := weaponType.String()
-			if weaponTypeStr == "HE Grenade" || weaponTypeStr == "Flashbang" || weaponTypeStr == "Smoke Grenade" || weaponTypeStr == "Molotov" || weaponTypeStr == "Incendiary Grenade" {
-				pending.isUtility = true
-			}
-		}
-	} else {
-		// Create new pending event or finalize old one
-		if exists {
-			// Finalize old event
-			e.finalizePending(key, pending)
-		}
+	cause := classifyDamageCause(attacker, victim, event.Weapon)
+	if !e.filter.Allowed(cause) {
+		return
+	}
 
-		// Create new pending event
-		hitgroups := make(map[string]int)
-		if event.HitGroup != 0 {
-			hitgroupStr := fmt.Sprintf("%d", int(event.HitGroup))
-			hitgroups[hitgroupStr] = 1
+	weapon := "unknown"
+	isUtility := false
+	if event.Weapon != nil {
+		weaponType := event.Weapon.Type
+		if weaponType != common.EqUnknown {
+			weapon = weaponType.String()
+		} else {
+			weapon = event.Weapon.String()
 		}
-
-		weapons := make(map[string]bool)
-		if event.Weapon != nil {
-			weaponType := event.Weapon.Type
-			if weaponType != common.EqUnknown {
-				weapons[weaponType.String()] = true
-			} else {
-				weapons[event.Weapon.String()] = true
-			}
+		if weapon == "HE Grenade" || weapon == "Flashbang" || weapon == "Smoke Grenade" || weapon == "Molotov" || weapon == "Incendiary Grenade" {
+			isUtility = true
 		}
+	}
 
-		isUtility := false
-		if event.Weapon != nil {
-			weaponType := event.Weapon.Type
-			weaponTypeStr := weaponType.String()
-			if weaponTypeStr == "HE Grenade" || weaponTypeStr == "Flashbang" || weaponTypeStr == "Smoke Grenade" || weaponTypeStr == "Molotov" || weaponTypeStr == "Incendiary Grenade" {
-				isUtility = true
-			}
-		}
+	key := accumulatorKey(roundIndex, *attackerSteamID, *victimSteamID, weapon)
+	e.isUtility[key] = isUtility
+	e.cause[key] = cause
 
-		e.pending[key] = &pendingDamage{
-			roundIndex:      roundIndex,
-			attackerSteamID: *attackerSteamID,
-			victimSteamID:   *victimSteamID,
-			startTick:       tick,
-			lastTick:        tick,
-			totalHealth:     event.HealthDamage,
-			totalArmor:      event.ArmorDamage,
-			hitCount:        1,
-			hitgroups:       hitgroups,
-			weapons:         weapons,
-			isUtility:       isUtility,
+	if event.HitGroup != 0 {
+		if e.hitgroups[key] == nil {
+			e.hitgroups[key] = make(map[string]int)
 		}
+		hitgroupStr := fmt.Sprintf("%d", int(event.HitGroup))
+		e.hitgroups[key][hitgroupStr]++
 	}
+
+	e.acc.Add(roundIndex, *attackerSteamID, *victimSteamID, weapon, tick, map[string]float64{
+		"dmg_health": float64(event.HealthDamage),
+		"dmg_armor":  float64(event.ArmorDamage),
+	})
 }
 
 // FinalizeRound finalizes all pending damage events for a round.
 func (e *TeamDamageExtractor) FinalizeRound(roundIndex int) {
-	keysToFinalize := make([]string, 0)
-	for key, pending := range e.pending {
-		if pending.roundIndex == roundIndex {
-			keysToFinalize = append(keysToFinalize, key)
-		}
-	}
+	e.acc.FlushRound(roundIndex)
+}
 
-	for _, key := range keysToFinalize {
-		e.finalizePending(key, e.pending[key])
-		delete(e.pending, key)
-	}
+// FlushParticipant finalizes any pending damage events involving steamID as
+// attacker or victim, e.g. when that player dies mid-window.
+func (e *TeamDamageExtractor) FlushParticipant(steamID string) {
+	e.acc.FlushParticipant(steamID)
 }
 
-func (e *TeamDamageExtractor) finalizePending(key string, pending *pendingDamage) {
-	// Build metadata
+func (e *TeamDamageExtractor) finalizeEntry(entry *AccumulatorEntry) {
+	key := accumulatorKey(entry.RoundIndex, entry.ActorSteamID, entry.VictimSteamID, entry.Weapon)
+
+	dmgHealth := int(entry.Metrics["dmg_health"])
+	dmgArmor := int(entry.Metrics["dmg_armor"])
+	totalDamage := float64(dmgHealth + dmgArmor)
+
 	meta := make(map[string]interface{})
-	meta["dmg_health"] = pending.totalHealth
-	meta["dmg_armor"] = pending.totalArmor
-	totalDamage := float64(pending.totalHealth + pending.totalArmor)
+	meta["dmg_health"] = dmgHealth
+	meta["dmg_armor"] = dmgArmor
 	meta["total_damage"] = totalDamage
-	meta["hit_count"] = pending.hitCount
-	meta["is_utility"] = pending.isUtility
-
-	if len(pending.hitgroups) > 0 {
-		meta["hitgroups"] = pending.hitgroups
+	meta["hit_count"] = entry.HitCount
+	meta["is_utility"] = e.isUtility[key]
+	cause, hasCause := e.cause[key]
+	if hasCause {
+		meta["cause"] = string(cause)
 	}
-
-	if len(pending.weapons) > 0 {
-		weaponsList := make([]string, 0, len(pending.weapons))
-		for weapon := range pending.weapons {
-			weaponsList = append(weaponsList, weapon)
-		}
-		meta["weapon"] = weaponsList
+	reasonCode := classifyTeamDamageReason(cause)
+	meta["reason_code"] = reasonCode
+	meta["reason"] = DescribeReason(reasonCode)
+	if entry.Weapon != "unknown" {
+		meta["weapon"] = entry.Weapon
+	}
+	if hitgroups, ok := e.hitgroups[key]; ok && len(hitgroups) > 0 {
+		meta["hitgroups"] = hitgroups
 	}
+	delete(e.hitgroups, key)
+	delete(e.isUtility, key)
+	delete(e.cause, key)
 
 	metaJSON, _ := json.Marshal(meta)
 	metaJSONStr := string(metaJSON)
 
-	actorSteamID := &pending.attackerSteamID
-	victimSteamID := &pending.victimSteamID
-	endTick := pending.lastTick
+	actorSteamID := entry.ActorSteamID
+	victimSteamID := entry.VictimSteamID
+	endTick := entry.LastTick
 
-	// Calculate severity: scale by total damage, cap at 1.0
-	// Assuming 100 damage = 1.0 severity
+	// Calculate severity: scale by total damage, cap at 1.0.
+	// Assuming 100 damage = 1.0 severity.
 	severity := math.Min(totalDamage/100.0, 1.0)
 
 	e.events = append(e.events, Event{
 		Type:          "TEAM_DAMAGE",
-		RoundIndex:    pending.roundIndex,
-		StartTick:     pending.startTick,
+		RoundIndex:    entry.RoundIndex,
+		StartTick:     entry.FirstTick,
 		EndTick:       &endTick,
-		ActorSteamID:  actorSteamID,
-		VictimSteamID: victimSteamID,
+		ActorSteamID:  &actorSteamID,
+		VictimSteamID: &victimSteamID,
 		Severity:      severity,
 		Confidence:    1.0,
 		MetaJSON:      &metaJSONStr,
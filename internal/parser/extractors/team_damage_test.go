@@ -1,50 +1,16 @@
 package extractors
 
-import (
-	"testing"
-)
+import "testing"
 
 func TestTeamDamageMerge(t *testing.T) {
 	tickRate := 64.0 // 64 tick server
-	extractor := NewTeamDamageExtractor(tickRate)
+	extractor := NewTeamDamageExtractor(tickRate, nil)
 
-	// Simulate events within merge window (2 seconds = 128 ticks)
-	// Round 0, attacker "1", victim "2"
-	
-	// First event at tick 1000
-	// We can't easily create real events, so we'll test the merge logic directly
-	// by simulating the pending map structure
-	
-	key := "0_1_2"
-	pending := &pendingDamage{
-		roundIndex:     0,
-		attackerSteamID: "1",
-		victimSteamID:   "2",
-		startTick:      1000,
-		lastTick:       1000,
-		totalHealth:    20,
-		totalArmor:     10,
-		hitCount:       1,
-		hitgroups:      make(map[string]int),
-		weapons:        make(map[string]bool),
-		isUtility:      false,
-	}
-	extractor.pending[key] = pending
-
-	// Second event at tick 1100 (within 128 tick window)
-	pending.lastTick = 1100
-	pending.totalHealth += 15
-	pending.totalArmor += 5
-	pending.hitCount++
-
-	// Third event at tick 1200 (within 128 tick window)
-	pending.lastTick = 1200
-	pending.totalHealth += 25
-	pending.totalArmor += 0
-	pending.hitCount++
-
-	// Finalize
-	extractor.finalizePending(key, pending)
+	// Three hits within the 128-tick (2 second) merge window.
+	extractor.acc.Add(0, "1", "2", "ak47", 1000, map[string]float64{"dmg_health": 20, "dmg_armor": 10})
+	extractor.acc.Add(0, "1", "2", "ak47", 1100, map[string]float64{"dmg_health": 15, "dmg_armor": 5})
+	extractor.acc.Add(0, "1", "2", "ak47", 1200, map[string]float64{"dmg_health": 25, "dmg_armor": 0})
+	extractor.FinalizeRound(0)
 
 	events := extractor.GetEvents()
 	if len(events) != 1 {
@@ -62,8 +28,8 @@ func TestTeamDamageMerge(t *testing.T) {
 		t.Errorf("expected type TEAM_DAMAGE, got %s", event.Type)
 	}
 
-	// Check severity calculation (total damage = 60, should be 0.6)
-	expectedSeverity := 0.6
+	// Total damage = (20+15+25) health + (10+5+0) armor = 75, so severity 0.75.
+	expectedSeverity := 0.75
 	if event.Severity < expectedSeverity-0.01 || event.Severity > expectedSeverity+0.01 {
 		t.Errorf("expected severity ~%.2f, got %.2f", expectedSeverity, event.Severity)
 	}
@@ -71,52 +37,32 @@ func TestTeamDamageMerge(t *testing.T) {
 
 func TestTeamDamageNoMerge(t *testing.T) {
 	tickRate := 64.0
-	extractor := NewTeamDamageExtractor(tickRate)
+	extractor := NewTeamDamageExtractor(tickRate, nil)
 
-	// Simulate events outside merge window
-	key1 := "0_1_2"
-	pending1 := &pendingDamage{
-		roundIndex:     0,
-		attackerSteamID: "1",
-		victimSteamID:   "2",
-		startTick:      1000,
-		lastTick:       1000,
-		totalHealth:    20,
-		totalArmor:     10,
-		hitCount:       1,
-		hitgroups:      make(map[string]int),
-		weapons:        make(map[string]bool),
-		isUtility:      false,
-	}
-	extractor.pending[key1] = pending1
+	// Second hit at tick 1200 is outside the 128-tick window from tick 1000,
+	// so it should flush the first hit as its own event.
+	extractor.acc.Add(0, "1", "2", "ak47", 1000, map[string]float64{"dmg_health": 20, "dmg_armor": 10})
+	extractor.acc.Add(0, "1", "2", "ak47", 1200, map[string]float64{"dmg_health": 15, "dmg_armor": 5})
+	extractor.FinalizeRound(0)
 
-	// Second event at tick 1200 (outside 128 tick window from 1000)
-	key2 := "0_1_2"
-	pending2 := &pendingDamage{
-		roundIndex:     0,
-		attackerSteamID: "1",
-		victimSteamID:   "2",
-		startTick:      1200,
-		lastTick:       1200,
-		totalHealth:    15,
-		totalArmor:     5,
-		hitCount:       1,
-		hitgroups:      make(map[string]int),
-		weapons:        make(map[string]bool),
-		isUtility:      false,
+	events := extractor.GetEvents()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 separate events, got %d", len(events))
 	}
+}
 
-	// Finalize first
-	extractor.finalizePending(key1, pending1)
-	delete(extractor.pending, key1)
+func TestTeamDamageWeaponChangeSplitsEvent(t *testing.T) {
+	tickRate := 64.0
+	extractor := NewTeamDamageExtractor(tickRate, nil)
 
-	// Add second
-	extractor.pending[key2] = pending2
-	extractor.finalizePending(key2, pending2)
+	// Same actor/victim/round, but a different weapon mid-window should not
+	// merge into the same event.
+	extractor.acc.Add(0, "1", "2", "ak47", 1000, map[string]float64{"dmg_health": 20, "dmg_armor": 0})
+	extractor.acc.Add(0, "1", "2", "he", 1010, map[string]float64{"dmg_health": 10, "dmg_armor": 0})
+	extractor.FinalizeRound(0)
 
 	events := extractor.GetEvents()
 	if len(events) != 2 {
-		t.Fatalf("expected 2 separate events, got %d", len(events))
+		t.Fatalf("expected 2 events (one per weapon), got %d", len(events))
 	}
 }
-
@@ -7,17 +7,27 @@ import (
 	events "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/events"
 )
 
-// TeamFlashExtractor extracts team flash events from flash/blind events.
-// Creates individual events per thrower-victim pair (similar to cs2-web-replay).
+// teamFlashWeapon is the constant Weapon key team flashes are accumulated
+// under - there's only one weapon that can cause a PlayerFlashed event, so
+// unlike TeamDamageExtractor there's no weapon dimension worth splitting on.
+const teamFlashWeapon = "Flashbang"
+
+// TeamFlashExtractor extracts team flash events from flash/blind events,
+// coalescing consecutive flashes of the same victim by the same thrower via
+// an Accumulator instead of emitting one event per flash.
 type TeamFlashExtractor struct {
+	acc    *Accumulator
 	events []Event
 }
 
-// NewTeamFlashExtractor creates a new team flash extractor.
+// NewTeamFlashExtractor creates a new team flash extractor, merging flashes
+// within DefaultAccumulatorWindowTicks of each other.
 func NewTeamFlashExtractor() *TeamFlashExtractor {
-	return &TeamFlashExtractor{
+	e := &TeamFlashExtractor{
 		events: make([]Event, 0),
 	}
+	e.acc = NewAccumulator(DefaultAccumulatorWindowTicks, e.finalizeEntry)
+	return e
 }
 
 // HandlePlayerFlashed processes a flash/blind event and accumulates team flashes.
@@ -41,60 +51,78 @@ func (e *TeamFlashExtractor) HandlePlayerFlashed(event events.PlayerFlashed, rou
 		return
 	}
 
-	// Get flash duration from event
-	// In demoinfocs-golang v5, PlayerFlashed has a FlashDuration() method
-	// that returns a time.Duration (nanoseconds), which we convert to seconds
+	// Get flash duration from event. PlayerFlashed.FlashDuration() returns a
+	// time.Duration, which we convert to seconds.
 	duration := 0.0
-
-	// Try to get flash duration from the event
-	// PlayerFlashed event should have FlashDuration() method
 	flashDuration := event.FlashDuration()
 	if flashDuration > 0 {
-		// Convert time.Duration to seconds (float64)
 		duration = flashDuration.Seconds()
 	} else {
-		// Fallback: estimate based on typical flash duration (2-5 seconds)
-		// Most flashes last 2-4 seconds, use 3.0 as default
+		// Fallback: estimate based on typical flash duration (2-5 seconds).
+		// Most flashes last 2-4 seconds, use 3.0 as default.
 		duration = 3.0
 	}
 
-	// Filter out very short flashes (< 1 second) as they're not significant team flashes
-	// This matches cs2-web-replay's approach
+	// Filter out very short flashes (< 1 second) as they're not significant
+	// team flashes.
 	if duration < 1.0 {
 		return
 	}
 
-	// Create individual event per thrower-victim pair (like cs2-web-replay)
-	// This makes it easier to display in the UI and matches the expected data structure
+	e.acc.Add(roundIndex, *flasherSteamID, *victimSteamID, teamFlashWeapon, tick, map[string]float64{
+		"total_blind_seconds": duration,
+	})
+}
+
+// FinalizeRound finalizes all pending flash events for a round.
+func (e *TeamFlashExtractor) FinalizeRound(roundIndex int) {
+	e.acc.FlushRound(roundIndex)
+}
+
+// FlushParticipant finalizes any pending flash events involving steamID as
+// thrower or victim, e.g. when that player dies mid-window.
+func (e *TeamFlashExtractor) FlushParticipant(steamID string) {
+	e.acc.FlushParticipant(steamID)
+}
+
+func (e *TeamFlashExtractor) finalizeEntry(entry *AccumulatorEntry) {
+	totalBlind := entry.Metrics["total_blind_seconds"]
+
 	meta := make(map[string]interface{})
-	meta["blind_duration"] = duration
+	meta["total_blind_seconds"] = totalBlind
+	meta["hit_count"] = entry.HitCount
+	// blind_duration is kept for backwards compatibility with readers that
+	// expect a single-flash duration; for a merged entry it's the average.
+	meta["blind_duration"] = totalBlind / float64(entry.HitCount)
+
+	reasonCode := classifyTeamFlashReason(totalBlind)
+	meta["reason_code"] = reasonCode
+	meta["reason"] = DescribeReason(reasonCode)
 
 	metaJSON, _ := json.Marshal(meta)
 	metaJSONStr := string(metaJSON)
 
-	// Calculate severity: scale by flash duration, cap at 1.0
-	// 5 seconds = 1.0 severity
-	severity := math.Min(duration/5.0, 1.0)
+	actorSteamID := entry.ActorSteamID
+	victimSteamID := entry.VictimSteamID
+	endTick := entry.LastTick
+
+	// Calculate severity: scale by total blind seconds, cap at 1.0.
+	// 5 seconds = 1.0 severity.
+	severity := math.Min(totalBlind/5.0, 1.0)
 
 	e.events = append(e.events, Event{
 		Type:          "TEAM_FLASH",
-		RoundIndex:    roundIndex,
-		StartTick:     tick,
-		EndTick:       nil,
-		ActorSteamID:  flasherSteamID,
-		VictimSteamID: victimSteamID, // Individual victim per event
+		RoundIndex:    entry.RoundIndex,
+		StartTick:     entry.FirstTick,
+		EndTick:       &endTick,
+		ActorSteamID:  &actorSteamID,
+		VictimSteamID: &victimSteamID,
 		Severity:      severity,
 		Confidence:    1.0,
 		MetaJSON:      &metaJSONStr,
 	})
 }
 
-// FinalizeRound finalizes all pending flash events for a round.
-// No-op for this extractor since we create events immediately.
-func (e *TeamFlashExtractor) FinalizeRound(roundIndex int) {
-	// Events are created immediately, no pending state to finalize
-}
-
 // GetEvents returns all extracted events.
 func (e *TeamFlashExtractor) GetEvents() []Event {
 	return e.events
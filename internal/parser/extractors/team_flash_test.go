@@ -1,39 +1,16 @@
 package extractors
 
-import (
-	"testing"
-)
+import "testing"
 
 func TestTeamFlashMerge(t *testing.T) {
 	extractor := NewTeamFlashExtractor()
 
-	// Simulate multiple teammates flashed from same flash
-	// Round 0, flasher "1", flash tick 1000 (grouped)
-	key := "0_1_1000"
-	pending := &pendingFlash{
-		roundIndex:    0,
-		flasherSteamID: "1",
-		flashTick:     1000,
-		victims:       make([]flashVictim, 0),
-	}
-	extractor.pending[key] = pending
-
-	// Add multiple victims
-	pending.victims = append(pending.victims, flashVictim{
-		SteamID:  "2",
-		Duration: 3.0,
-	})
-	pending.victims = append(pending.victims, flashVictim{
-		SteamID:  "3",
-		Duration: 2.5,
-	})
-	pending.victims = append(pending.victims, flashVictim{
-		SteamID:  "4",
-		Duration: 4.0,
-	})
-
-	// Finalize
-	extractor.finalizePending(key, pending)
+	// Three flashes of the same victim within the window, summing to
+	// 9.5 blind seconds across 3 hits.
+	extractor.acc.Add(0, "1", "2", teamFlashWeapon, 1000, map[string]float64{"total_blind_seconds": 3.0})
+	extractor.acc.Add(0, "1", "2", teamFlashWeapon, 1010, map[string]float64{"total_blind_seconds": 2.5})
+	extractor.acc.Add(0, "1", "2", teamFlashWeapon, 1020, map[string]float64{"total_blind_seconds": 4.0})
+	extractor.FinalizeRound(0)
 
 	events := extractor.GetEvents()
 	if len(events) != 1 {
@@ -47,17 +24,42 @@ func TestTeamFlashMerge(t *testing.T) {
 	if event.StartTick != 1000 {
 		t.Errorf("expected start tick 1000, got %d", event.StartTick)
 	}
-	if len(pending.victims) != 3 {
-		t.Errorf("expected 3 victims, got %d", len(pending.victims))
+
+	// Total blind: 3.0 + 2.5 + 4.0 = 9.5 seconds over 3 hits.
+	// Severity = min(9.5 / 5.0, 1.0) = 1.0 (clamped).
+	if event.Severity != 1.0 {
+		t.Errorf("expected severity 1.0 (clamped), got %.3f", event.Severity)
 	}
+}
 
-	// Check severity calculation
-	// Total blind: 3.0 + 2.5 + 4.0 = 9.5 seconds
-	// Victims: 3
-	// Severity = min(9.5 / 3 / 5.0, 1.0) = min(0.633, 1.0) = 0.633
-	expectedSeverity := 9.5 / 3.0 / 5.0
-	if event.Severity < expectedSeverity-0.01 || event.Severity > expectedSeverity+0.01 {
-		t.Errorf("expected severity ~%.3f, got %.3f", expectedSeverity, event.Severity)
+func TestTeamFlashWindowRollover(t *testing.T) {
+	extractor := NewTeamFlashExtractor()
+
+	// Second flash lands outside DefaultAccumulatorWindowTicks of the
+	// first, so it should close the first entry rather than merge into it.
+	extractor.acc.Add(0, "1", "2", teamFlashWeapon, 1000, map[string]float64{"total_blind_seconds": 2.0})
+	extractor.acc.Add(0, "1", "2", teamFlashWeapon, 1000+DefaultAccumulatorWindowTicks+1, map[string]float64{"total_blind_seconds": 2.0})
+	extractor.FinalizeRound(0)
+
+	events := extractor.GetEvents()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 separate events, got %d", len(events))
 	}
 }
 
+func TestTeamFlashRoundBoundaryFlush(t *testing.T) {
+	extractor := NewTeamFlashExtractor()
+
+	extractor.acc.Add(0, "1", "2", teamFlashWeapon, 1000, map[string]float64{"total_blind_seconds": 2.0})
+	extractor.acc.Add(1, "1", "2", teamFlashWeapon, 1000, map[string]float64{"total_blind_seconds": 2.0})
+
+	extractor.FinalizeRound(0)
+	if len(extractor.GetEvents()) != 1 {
+		t.Fatalf("expected FinalizeRound(0) to flush only round 0's entry, got %d events", len(extractor.GetEvents()))
+	}
+
+	extractor.FinalizeRound(1)
+	if len(extractor.GetEvents()) != 2 {
+		t.Fatalf("expected FinalizeRound(1) to flush round 1's entry too, got %d events", len(extractor.GetEvents()))
+	}
+}
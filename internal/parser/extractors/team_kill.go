@@ -58,15 +58,21 @@ func (e *TeamKillExtractor) HandlePlayerDeath(event events.Kill, roundIndex int,
 	meta := make(map[string]interface{})
 
 	// Weapon
+	weaponStr := ""
 	if event.Weapon != nil {
 		weaponType := event.Weapon.Type
 		if weaponType != common.EqUnknown {
-			meta["weapon"] = weaponType.String()
+			weaponStr = weaponType.String()
 		} else {
-			meta["weapon"] = event.Weapon.String()
+			weaponStr = event.Weapon.String()
 		}
+		meta["weapon"] = weaponStr
 	}
 
+	reasonCode := classifyTeamKillReason(weaponStr)
+	meta["reason_code"] = reasonCode
+	meta["reason"] = DescribeReason(reasonCode)
+
 	// Headshot
 	if event.IsHeadshot {
 		meta["headshot"] = true
@@ -0,0 +1,194 @@
+package extractors
+
+import (
+	"math"
+	"sort"
+)
+
+// RoundContext is the shared state an ExtractorLoop advances through a
+// single round, handed to every registered TickHandler so none of them
+// need to re-derive freeze/grace timing or track who's alive/connected
+// themselves.
+type RoundContext struct {
+	MatchID            string
+	RoundIndex         int
+	TickRate           float64
+	FreezeEndTick      int
+	GracePeriodEndTick int
+	RoundEndTick       int
+
+	dead         map[string]int
+	disconnected map[string]bool
+}
+
+// IsAlive reports whether steamID hasn't died by tick.
+func (c *RoundContext) IsAlive(steamID string, tick int) bool {
+	deathTick, died := c.dead[steamID]
+	return !died || tick < deathTick
+}
+
+// IsConnected reports whether steamID is connected as of the last
+// OnDisconnect dispatch.
+func (c *RoundContext) IsConnected(steamID string) bool {
+	return !c.disconnected[steamID]
+}
+
+// TickHandler reacts to the events an ExtractorLoop dispatches while
+// replaying a round: freeze time ending, each tick's recorded positions, a
+// player dying or (dis)connecting, and the round ending. AFKExtractor's
+// round-start-AFK detector is one such handler; movement/utility/economy
+// extractors that need the same tick-ordered view of a round can join the
+// same loop as additional handlers instead of re-querying the database.
+type TickHandler interface {
+	// OnFreezeEnd fires once, after ctx's timing fields are populated but
+	// before any tick is dispatched.
+	OnFreezeEnd(ctx *RoundContext)
+	// OnTick fires once per distinct tick with a recorded position, in
+	// ascending tick order, with every player's sample at that tick.
+	OnTick(ctx *RoundContext, tick int, positions []PositionSample)
+	// OnDeath fires once, the tick steamID died, before OnTick for the same
+	// tick (if any position was also recorded there).
+	OnDeath(ctx *RoundContext, tick int, steamID string)
+	// OnDisconnect fires at each DisconnectInterval boundary: once at
+	// StartTick (reconnected=false), and again at EndTick if the player
+	// reconnected before the round/match ended (reconnected=true).
+	OnDisconnect(ctx *RoundContext, tick int, steamID string, reconnected bool)
+	// OnRoundEnd fires once, after every tick/death/disconnect has been
+	// dispatched.
+	OnRoundEnd(ctx *RoundContext)
+}
+
+// TickSource supplies one round's players, positions, deaths and
+// disconnects to an ExtractorLoop. MemPositionStore (and any PositionStore)
+// already implements it. The separate, narrower interface exists so a
+// future live-parsing source - buffering frames as the demo streams in,
+// instead of replaying rows already written to player_positions/events -
+// can back the same loop without either ExtractorLoop or its TickHandlers
+// knowing which ingestion mode they're running against.
+type TickSource interface {
+	PlayersInRound(matchID string, roundIndex int) []string
+	PositionsInRound(matchID string, roundIndex int, fromTick, toTick int) []PositionSample
+	DeathTick(matchID string, roundIndex int, steamID string) (int, bool)
+	Disconnects(matchID string, steamID string) []DisconnectInterval
+}
+
+// gracePeriodTicks is the fixed 5-second window after freeze end within
+// which a handler waits before treating stillness as AFK.
+func gracePeriodTicks(tickRate float64) int {
+	return int(math.Ceil(5.0 * tickRate))
+}
+
+// ExtractorLoop replays a round's positions, deaths and disconnects from a
+// TickSource in tick order, the way a game server's main loop advances
+// frame by frame, dispatching each event to every registered TickHandler.
+type ExtractorLoop struct {
+	handlers []TickHandler
+}
+
+// NewExtractorLoop creates an ExtractorLoop that dispatches to handlers in
+// registration order.
+func NewExtractorLoop(handlers ...TickHandler) *ExtractorLoop {
+	return &ExtractorLoop{handlers: handlers}
+}
+
+type boundEvent struct {
+	tick         int
+	steamID      string
+	isDeath      bool
+	reconnected  bool
+	isDisconnect bool
+}
+
+// Run replays matchID/roundIndex between freezeEndTick and roundEndTick
+// from source, dispatching OnFreezeEnd, then OnTick/OnDeath/OnDisconnect in
+// tick order, then OnRoundEnd, to every registered handler.
+func (l *ExtractorLoop) Run(source TickSource, matchID string, roundIndex int, tickRate float64, freezeEndTick, roundEndTick int) {
+	ctx := &RoundContext{
+		MatchID:            matchID,
+		RoundIndex:         roundIndex,
+		TickRate:           tickRate,
+		FreezeEndTick:      freezeEndTick,
+		GracePeriodEndTick: freezeEndTick + gracePeriodTicks(tickRate),
+		RoundEndTick:       roundEndTick,
+		dead:               make(map[string]int),
+		disconnected:       make(map[string]bool),
+	}
+
+	for _, h := range l.handlers {
+		h.OnFreezeEnd(ctx)
+	}
+
+	players := source.PlayersInRound(matchID, roundIndex)
+
+	// Resolve each player's death/disconnect events up front so they can be
+	// merged into the tick-ordered position stream below.
+	var events []boundEvent
+	for _, steamID := range players {
+		if deathTick, died := source.DeathTick(matchID, roundIndex, steamID); died {
+			events = append(events, boundEvent{tick: deathTick, steamID: steamID, isDeath: true})
+		}
+		for _, interval := range source.Disconnects(matchID, steamID) {
+			if interval.RoundIndex < roundIndex {
+				// Disconnect from a previous round: if they never
+				// reconnected, they're still disconnected for the whole
+				// of this round.
+				if interval.EndTick == nil {
+					events = append(events, boundEvent{tick: freezeEndTick, steamID: steamID, isDisconnect: true})
+				}
+				continue
+			}
+			if interval.RoundIndex != roundIndex {
+				continue
+			}
+			events = append(events, boundEvent{tick: interval.StartTick, steamID: steamID, isDisconnect: true})
+			if interval.EndTick != nil {
+				events = append(events, boundEvent{tick: *interval.EndTick, steamID: steamID, isDisconnect: true, reconnected: true})
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].tick < events[j].tick })
+
+	positions := source.PositionsInRound(matchID, roundIndex, freezeEndTick, roundEndTick)
+
+	dispatch := func(ev boundEvent) {
+		if ev.isDeath {
+			ctx.dead[ev.steamID] = ev.tick
+			for _, h := range l.handlers {
+				h.OnDeath(ctx, ev.tick, ev.steamID)
+			}
+			return
+		}
+		ctx.disconnected[ev.steamID] = !ev.reconnected
+		for _, h := range l.handlers {
+			h.OnDisconnect(ctx, ev.tick, ev.steamID, ev.reconnected)
+		}
+	}
+
+	eventIdx, posIdx := 0, 0
+	for posIdx < len(positions) {
+		tick := positions[posIdx].Tick
+
+		for eventIdx < len(events) && events[eventIdx].tick <= tick {
+			dispatch(events[eventIdx])
+			eventIdx++
+		}
+
+		var batch []PositionSample
+		for posIdx < len(positions) && positions[posIdx].Tick == tick {
+			batch = append(batch, positions[posIdx])
+			posIdx++
+		}
+		for _, h := range l.handlers {
+			h.OnTick(ctx, tick, batch)
+		}
+	}
+	// Flush any death/disconnect that landed after the last recorded
+	// position, e.g. a player who died without another position sample.
+	for ; eventIdx < len(events); eventIdx++ {
+		dispatch(events[eventIdx])
+	}
+
+	for _, h := range l.handlers {
+		h.OnRoundEnd(ctx)
+	}
+}
@@ -0,0 +1,341 @@
+package extractors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cs-griefer-electron/internal/db"
+)
+
+const testTickRate = 64.0
+
+func afkEventMeta(t *testing.T, e Event) map[string]interface{} {
+	t.Helper()
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(*e.MetaJSON), &meta); err != nil {
+		t.Fatalf("failed to unmarshal event metadata: %v", err)
+	}
+	return meta
+}
+
+// onlyType returns events whose Type matches eventType, for tests that need
+// to isolate the finalized AFK_STILLNESS interval from the AFK_SUSPECTED/
+// AFK_CONFIRMED markers now fired alongside it.
+func onlyType(events []Event, eventType string) []Event {
+	var out []Event
+	for _, e := range events {
+		if e.Type == eventType {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestProcessAFKFromDatabaseStillAtRoundEnd(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd, roundEnd := 1000, 1000+int(30*testTickRate)
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0, Y: 0, Z: 0})
+	store.AddPosition(matchID, 0, "steam1", roundEnd, position{X: 0, Y: 0, Z: 0})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	events := ex.GetEvents()
+	stillness := onlyType(events, "AFK_STILLNESS")
+	if len(stillness) != 1 {
+		t.Fatalf("got %d AFK_STILLNESS events, want 1: %+v", len(stillness), events)
+	}
+	meta := afkEventMeta(t, stillness[0])
+	if meta["endedBy"] != "ROUND_END" {
+		t.Fatalf("endedBy = %v, want ROUND_END", meta["endedBy"])
+	}
+
+	// A 30s round-start AFK crosses both the suspected (2.5s) and
+	// confirmed (5s) thresholds well before round end.
+	if len(onlyType(events, "AFK_SUSPECTED")) != 1 {
+		t.Fatalf("got %d AFK_SUSPECTED events, want 1: %+v", len(onlyType(events, "AFK_SUSPECTED")), events)
+	}
+	if len(onlyType(events, "AFK_CONFIRMED")) != 1 {
+		t.Fatalf("got %d AFK_CONFIRMED events, want 1: %+v", len(onlyType(events, "AFK_CONFIRMED")), events)
+	}
+
+	summary := ex.GetRoundAFKSummary(0)
+	s, ok := summary["steam1"]
+	if !ok {
+		t.Fatalf("GetRoundAFKSummary(0) missing steam1: %+v", summary)
+	}
+	if s.EpisodeCount != 1 || s.TotalAFKSeconds != 30.0 || s.LongestEpisodeSecs != 30.0 {
+		t.Fatalf("summary = %+v, want 1 episode totalling 30s", s)
+	}
+}
+
+func TestProcessAFKFromDatabaseConfirmedAFKReturns(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	stillTick := freezeEnd + int(6*testTickRate) // past the 5s confirmed threshold, still stationary
+	movedTick := freezeEnd + int(8*testTickRate) // then moves
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0})
+	store.AddPosition(matchID, 0, "steam1", stillTick, position{X: 0})
+	store.AddPosition(matchID, 0, "steam1", movedTick, position{X: 500})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	events := ex.GetEvents()
+	if len(onlyType(events, "AFK_SUSPECTED")) != 1 {
+		t.Fatalf("got %d AFK_SUSPECTED events, want 1: %+v", len(onlyType(events, "AFK_SUSPECTED")), events)
+	}
+	if len(onlyType(events, "AFK_CONFIRMED")) != 1 {
+		t.Fatalf("got %d AFK_CONFIRMED events, want 1: %+v", len(onlyType(events, "AFK_CONFIRMED")), events)
+	}
+	returned := onlyType(events, "AFK_RETURNED")
+	if len(returned) != 1 {
+		t.Fatalf("got %d AFK_RETURNED events, want 1: %+v", len(returned), events)
+	}
+	meta := afkEventMeta(t, returned[0])
+	if meta["return_tick"] != float64(movedTick) {
+		t.Fatalf("return_tick = %v, want %d", meta["return_tick"], movedTick)
+	}
+}
+
+func TestProcessAFKFromDatabaseMovedDuringGraceIsNotAFK(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	graceTicks := int(3 * testTickRate) // well inside the 5s grace window
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0})
+	store.AddPosition(matchID, 0, "steam1", freezeEnd+graceTicks, position{X: 500}) // big move
+	store.AddPosition(matchID, 0, "steam1", roundEnd, position{X: 500})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	if events := ex.GetEvents(); len(events) != 0 {
+		t.Fatalf("got %d events, want 0 (player moved during grace): %+v", len(events), events)
+	}
+}
+
+func TestProcessAFKFromDatabaseMovedAfterGraceEndsAFK(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	afterGrace := freezeEnd + int(10*testTickRate) // past the 5s grace window
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0})
+	store.AddPosition(matchID, 0, "steam1", afterGrace, position{X: 500})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	events := ex.GetEvents()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(events), events)
+	}
+	meta := afkEventMeta(t, events[0])
+	if meta["endedBy"] != "MOVED" {
+		t.Fatalf("endedBy = %v, want MOVED", meta["endedBy"])
+	}
+}
+
+func TestProcessAFKFromDatabaseDiedWhileAFK(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	deathTick := freezeEnd + int(2*testTickRate)
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0})
+	store.AddDeath(matchID, 0, "steam1", deathTick)
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	events := ex.GetEvents()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(events), events)
+	}
+	meta := afkEventMeta(t, events[0])
+	if meta["endedBy"] != "DIED" || meta["diedWhileAFK"] != true {
+		t.Fatalf("meta = %+v, want endedBy=DIED diedWhileAFK=true", meta)
+	}
+}
+
+func TestProcessAFKFromDatabaseDisconnectedPlayerNotTracked(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0})
+	store.AddDisconnect(matchID, "steam1", DisconnectInterval{RoundIndex: 0, StartTick: freezeEnd})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	if events := ex.GetEvents(); len(events) != 0 {
+		t.Fatalf("got %d events, want 0 (player disconnected at round start): %+v", len(events), events)
+	}
+}
+
+func TestProcessAFKFromDatabaseLoiteringKeepsAFKAlive(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	loiterTick := freezeEnd + int(10*testTickRate) // loiterWindowSeconds of history now covered
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0})
+	store.AddPosition(matchID, 0, "steam1", loiterTick, position{X: 4}) // exceeds moveEps, stays within loiterRadius
+	store.AddPosition(matchID, 0, "steam1", roundEnd, position{X: 4})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	events := ex.GetEvents()
+	stillness := onlyType(events, "AFK_STILLNESS")
+	if len(stillness) != 1 {
+		t.Fatalf("got %d AFK_STILLNESS events, want 1 (loitering should keep AFK alive to round end): %+v", len(stillness), events)
+	}
+	meta := afkEventMeta(t, stillness[0])
+	if meta["endedBy"] != "ROUND_END" {
+		t.Fatalf("endedBy = %v, want ROUND_END", meta["endedBy"])
+	}
+	if meta["detectionMethod"] != "loitering" {
+		t.Fatalf("detectionMethod = %v, want loitering", meta["detectionMethod"])
+	}
+}
+
+func TestProcessAFKFromDatabaseLoiterDetectionDisabledTreatsWiggleAsMovement(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	loiterTick := freezeEnd + int(10*testTickRate)
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0})
+	store.AddPosition(matchID, 0, "steam1", loiterTick, position{X: 4})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	ex.SetLoiterDetection(false, 50.0, 10.0)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	events := ex.GetEvents()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(events), events)
+	}
+	meta := afkEventMeta(t, events[0])
+	if meta["endedBy"] != "MOVED" {
+		t.Fatalf("endedBy = %v, want MOVED (loiter detection disabled, wiggle counts as movement)", meta["endedBy"])
+	}
+}
+
+func TestProcessAFKFromDatabaseCarriesDisconnectFromPreviousRound(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	// Disconnected in round 0 with no reconnect recorded anywhere.
+	store.AddDisconnect(matchID, "steam1", DisconnectInterval{RoundIndex: 0, StartTick: 500})
+	store.AddPosition(matchID, 1, "steam1", freezeEnd, position{X: 0})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	if err := ex.ProcessAFKFromDatabase(matchID, 1, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	if events := ex.GetEvents(); len(events) != 0 {
+		t.Fatalf("got %d events, want 0 (still disconnected from a prior round): %+v", len(events), events)
+	}
+}
+
+func TestProcessAFKFromDatabaseActivityCancelsAFK(t *testing.T) {
+	store := NewMemPositionStore(nil)
+	const matchID = "m1"
+	freezeEnd := 1000
+	roundEnd := freezeEnd + int(30*testTickRate)
+	stillTick := freezeEnd + int(6*testTickRate)    // past the 5s confirmed threshold
+	activityTick := freezeEnd + int(8*testTickRate) // shot fired here, still stationary
+	store.AddPosition(matchID, 0, "steam1", freezeEnd, position{X: 0, Y: 0, Z: 0})
+	store.AddPosition(matchID, 0, "steam1", stillTick, position{X: 0, Y: 0, Z: 0})
+	store.AddPosition(matchID, 0, "steam1", activityTick, position{X: 0, Y: 0, Z: 0})
+	store.AddPosition(matchID, 0, "steam1", roundEnd, position{X: 0, Y: 0, Z: 0})
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	ex.SetPositionStore(store)
+	// Held an angle and fired partway through what would otherwise be a
+	// round-long AFK interval.
+	ex.RegisterActivity("steam1", activityTick, ActivityShotFired)
+	if err := ex.ProcessAFKFromDatabase(matchID, 0, freezeEnd, roundEnd); err != nil {
+		t.Fatalf("ProcessAFKFromDatabase: %v", err)
+	}
+
+	events := ex.GetEvents()
+	stillness := onlyType(events, "AFK_STILLNESS")
+	if len(stillness) != 1 {
+		t.Fatalf("got %d AFK_STILLNESS events, want 1: %+v", len(stillness), events)
+	}
+	meta := afkEventMeta(t, stillness[0])
+	if meta["endedBy"] != "ACTIVITY" {
+		t.Fatalf("endedBy = %v, want ACTIVITY", meta["endedBy"])
+	}
+	if meta["activity_kind"] != "shot_fired" {
+		t.Fatalf("activity_kind = %v, want shot_fired", meta["activity_kind"])
+	}
+
+	returned := onlyType(events, "AFK_RETURNED")
+	if len(returned) != 1 {
+		t.Fatalf("got %d AFK_RETURNED events, want 1: %+v", len(returned), events)
+	}
+	if meta := afkEventMeta(t, returned[0]); meta["activity_kind"] != "shot_fired" {
+		t.Fatalf("AFK_RETURNED activity_kind = %v, want shot_fired", meta["activity_kind"])
+	}
+}
+
+func TestProcessAFKFromPositionsDetectsStillness(t *testing.T) {
+	const matchID = "m1"
+	freezeEnd, roundEnd := 1000, 1000+int(30*testTickRate)
+	positions := []db.PlayerPosition{
+		{MatchID: matchID, RoundIndex: 0, SteamID: "steam1", Tick: freezeEnd, X: 0, Y: 0, Z: 0},
+		{MatchID: matchID, RoundIndex: 0, SteamID: "steam1", Tick: roundEnd, X: 0, Y: 0, Z: 0},
+		// A different round's samples must be ignored.
+		{MatchID: matchID, RoundIndex: 1, SteamID: "steam1", Tick: freezeEnd, X: 500, Y: 500, Z: 500},
+	}
+
+	ex := NewAFKExtractor(testTickRate, nil, matchID)
+	if err := ex.ProcessAFKFromPositions(matchID, 0, freezeEnd, roundEnd, positions); err != nil {
+		t.Fatalf("ProcessAFKFromPositions: %v", err)
+	}
+
+	stillness := onlyType(ex.GetEvents(), "AFK_STILLNESS")
+	if len(stillness) != 1 {
+		t.Fatalf("got %d AFK_STILLNESS events, want 1: %+v", len(stillness), ex.GetEvents())
+	}
+	if meta := afkEventMeta(t, stillness[0]); meta["endedBy"] != "ROUND_END" {
+		t.Fatalf("endedBy = %v, want ROUND_END", meta["endedBy"])
+	}
+}
@@ -0,0 +1,152 @@
+package extractors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// TradeKillExtractor detects traded kills: a player's killer is himself
+// killed by one of the victim's teammates within a short window afterward.
+// Unlike KillExtractor (which runs off live demoinfocs callbacks as they
+// stream by), this needs to look at a kill's *following* kills, so it
+// re-reads already-persisted KILL events from the database instead.
+type TradeKillExtractor struct {
+	db          *sql.DB
+	tickRate    float64
+	tradeWindow float64 // seconds within which an avenging kill counts as a trade
+	events      []Event
+}
+
+// NewTradeKillExtractor creates a new trade kill extractor.
+func NewTradeKillExtractor(tickRate float64, db *sql.DB) *TradeKillExtractor {
+	return &TradeKillExtractor{
+		db:          db,
+		tickRate:    tickRate,
+		tradeWindow: 5.0,
+		events:      make([]Event, 0),
+	}
+}
+
+// GetEvents returns all extracted events.
+func (e *TradeKillExtractor) GetEvents() []Event {
+	return e.events
+}
+
+// ClearEvents clears all extracted events from memory.
+func (e *TradeKillExtractor) ClearEvents() {
+	e.events = e.events[:0]
+}
+
+type tradeKillRow struct {
+	actorSteamID  string
+	victimSteamID string
+	tick          int
+}
+
+// ProcessRoundFromDatabase re-reads this round's KILL events and marks one
+// as TRADE_KILL whenever the original killer is himself killed by one of
+// the victim's teammates within tradeWindow seconds.
+func (e *TradeKillExtractor) ProcessRoundFromDatabase(matchID string, roundIndex int) error {
+	if e.db == nil || matchID == "" {
+		return nil
+	}
+
+	rows, err := e.db.Query(`
+		SELECT actor_steamid, victim_steamid, start_tick
+		FROM events
+		WHERE match_id = ? AND round_index = ? AND type = 'KILL'
+			AND actor_steamid IS NOT NULL AND victim_steamid IS NOT NULL
+		ORDER BY start_tick
+	`, matchID, roundIndex)
+	if err != nil {
+		return fmt.Errorf("failed to query kills: %w", err)
+	}
+	defer rows.Close()
+
+	var kills []tradeKillRow
+	for rows.Next() {
+		var k tradeKillRow
+		if err := rows.Scan(&k.actorSteamID, &k.victimSteamID, &k.tick); err != nil {
+			continue
+		}
+		kills = append(kills, k)
+	}
+
+	windowTicks := int(math.Ceil(e.tradeWindow * e.tickRate))
+
+	for i, original := range kills {
+		for j := i + 1; j < len(kills); j++ {
+			avenge := kills[j]
+			if avenge.tick-original.tick > windowTicks {
+				break
+			}
+			if avenge.victimSteamID != original.actorSteamID {
+				continue
+			}
+
+			avengerTeam, err := e.teamAtTick(matchID, roundIndex, avenge.actorSteamID, avenge.tick)
+			if err != nil || avengerTeam == "" {
+				continue
+			}
+			victimTeam, err := e.teamAtTick(matchID, roundIndex, original.victimSteamID, original.tick)
+			if err != nil || victimTeam == "" || avengerTeam != victimTeam {
+				continue
+			}
+
+			e.recordTrade(roundIndex, original, avenge)
+			break
+		}
+	}
+
+	return nil
+}
+
+// teamAtTick looks up steamID's team from the closest player_positions row
+// to tick, the same per-tick team source BodyBlockExtractor uses (a match's
+// `players.team` is fixed, but positions track side swaps correctly).
+func (e *TradeKillExtractor) teamAtTick(matchID string, roundIndex int, steamID string, tick int) (string, error) {
+	var team sql.NullString
+	err := e.db.QueryRow(`
+		SELECT team FROM player_positions
+		WHERE match_id = ? AND round_index = ? AND steamid = ?
+		ORDER BY ABS(tick - ?) ASC
+		LIMIT 1
+	`, matchID, roundIndex, steamID, tick).Scan(&team)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return team.String, nil
+}
+
+func (e *TradeKillExtractor) recordTrade(roundIndex int, original, avenge tradeKillRow) {
+	seconds := float64(avenge.tick-original.tick) / e.tickRate
+
+	meta := map[string]interface{}{
+		"seconds":       seconds,
+		"original_tick": original.tick,
+		"traded_player": original.victimSteamID,
+	}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	avengeTick := avenge.tick
+	avenger := avenge.actorSteamID
+	traded := original.actorSteamID
+
+	e.events = append(e.events, Event{
+		Type:          "TRADE_KILL",
+		RoundIndex:    roundIndex,
+		StartTick:     original.tick,
+		EndTick:       &avengeTick,
+		ActorSteamID:  &avenger,
+		VictimSteamID: &traded,
+		Severity:      0.0, // trades are a positive play, not a griefing signal
+		Confidence:    1.0,
+		MetaJSON:      &metaJSONStr,
+	})
+}
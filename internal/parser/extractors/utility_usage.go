@@ -0,0 +1,126 @@
+package extractors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// UtilityUsageExtractor groups a player's grenade throws within a round
+// into "utility windows": runs of throws close together in time, useful for
+// spotting utility-heavy pushes/retakes rather than isolated single throws.
+type UtilityUsageExtractor struct {
+	db            *sql.DB
+	tickRate      float64
+	maxGapSeconds float64 // max gap between throws to still count as the same window
+	events        []Event
+}
+
+// NewUtilityUsageExtractor creates a new utility usage extractor.
+func NewUtilityUsageExtractor(tickRate float64, db *sql.DB) *UtilityUsageExtractor {
+	return &UtilityUsageExtractor{
+		db:            db,
+		tickRate:      tickRate,
+		maxGapSeconds: 8.0,
+		events:        make([]Event, 0),
+	}
+}
+
+// GetEvents returns all extracted events.
+func (e *UtilityUsageExtractor) GetEvents() []Event {
+	return e.events
+}
+
+// ClearEvents clears all extracted events from memory.
+func (e *UtilityUsageExtractor) ClearEvents() {
+	e.events = e.events[:0]
+}
+
+type utilityThrow struct {
+	tick        int
+	grenadeName string
+}
+
+// ProcessRoundFromDatabase groups each thrower's grenade_events rows for
+// this round into utility windows and emits one UTILITY_USAGE event per
+// window of two or more throws.
+func (e *UtilityUsageExtractor) ProcessRoundFromDatabase(matchID string, roundIndex int) error {
+	if e.db == nil || matchID == "" {
+		return nil
+	}
+
+	rows, err := e.db.Query(`
+		SELECT thrower_steamid, tick, grenade_name
+		FROM grenade_events
+		WHERE match_id = ? AND round_index = ? AND thrower_steamid IS NOT NULL
+		ORDER BY thrower_steamid, tick
+	`, matchID, roundIndex)
+	if err != nil {
+		return fmt.Errorf("failed to query grenade events: %w", err)
+	}
+	defer rows.Close()
+
+	byThrower := make(map[string][]utilityThrow)
+	for rows.Next() {
+		var steamID, grenadeName string
+		var tick int
+		if err := rows.Scan(&steamID, &tick, &grenadeName); err != nil {
+			continue
+		}
+		byThrower[steamID] = append(byThrower[steamID], utilityThrow{tick: tick, grenadeName: grenadeName})
+	}
+
+	maxGapTicks := int(e.maxGapSeconds * e.tickRate)
+
+	for steamID, throws := range byThrower {
+		var window []utilityThrow
+		flush := func() {
+			if len(window) >= 2 {
+				e.recordWindow(roundIndex, steamID, window)
+			}
+			window = nil
+		}
+
+		for _, t := range throws {
+			if len(window) > 0 && t.tick-window[len(window)-1].tick > maxGapTicks {
+				flush()
+			}
+			window = append(window, t)
+		}
+		flush()
+	}
+
+	return nil
+}
+
+func (e *UtilityUsageExtractor) recordWindow(roundIndex int, steamID string, window []utilityThrow) {
+	startTick := window[0].tick
+	endTick := window[len(window)-1].tick
+	seconds := float64(endTick-startTick) / e.tickRate
+
+	counts := make(map[string]int)
+	for _, t := range window {
+		counts[t.grenadeName]++
+	}
+
+	meta := map[string]interface{}{
+		"seconds":        seconds,
+		"throw_count":    len(window),
+		"grenade_counts": counts,
+	}
+	metaJSON, _ := json.Marshal(meta)
+	metaJSONStr := string(metaJSON)
+
+	actor := steamID
+
+	e.events = append(e.events, Event{
+		Type:         "UTILITY_USAGE",
+		RoundIndex:   roundIndex,
+		StartTick:    startTick,
+		EndTick:      &endTick,
+		ActorSteamID: &actor,
+		Severity:     0.0, // informational, not a griefing signal
+		Confidence:   1.0,
+		MetaJSON:     &metaJSONStr,
+	})
+}
@@ -0,0 +1,107 @@
+package extractors
+
+import (
+	common "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/common"
+)
+
+// weaponPrices holds the canonical CS2 buy-menu price for every purchasable
+// EquipmentType, matching the values Valve's buy_preset.cpp uses to cost out
+// a WeaponSet. Items that can't be bought (knife, bomb, world props, etc.)
+// are simply absent and price 0.
+var weaponPrices = map[common.EquipmentType]int{
+	// Pistols
+	common.EqP2000:        200,
+	common.EqGlock:        200,
+	common.EqP250:         300,
+	common.EqDeagle:       700,
+	common.EqFiveSeven:    500,
+	common.EqDualBerettas: 300,
+	common.EqTec9:         500,
+	common.EqCZ:           500,
+	common.EqUSP:          200,
+	common.EqRevolver:     600,
+
+	// SMGs
+	common.EqMP7:   1500,
+	common.EqMP9:   1250,
+	common.EqBizon: 1400,
+	common.EqMac10: 1050,
+	common.EqUMP:   1200,
+	common.EqP90:   2350,
+	common.EqMP5:   1500,
+
+	// Heavy
+	common.EqSawedOff: 1100,
+	common.EqNova:     1050,
+	common.EqSwag7:    1800,
+	common.EqXM1014:   2000,
+	common.EqM249:     5200,
+	common.EqNegev:    1700,
+
+	// Rifles
+	common.EqGalil:  1800,
+	common.EqFamas:  1950,
+	common.EqAK47:   2700,
+	common.EqM4A4:   2900,
+	common.EqM4A1:   2900,
+	common.EqSSG08:  1700,
+	common.EqSG553:  3000,
+	common.EqAUG:    3300,
+	common.EqAWP:    4750,
+	common.EqScar20: 5000,
+	common.EqG3SG1:  5000,
+
+	// Equipment
+	common.EqZeus:      200,
+	common.EqKevlar:    650,
+	common.EqHelmet:    1000,
+	common.EqDefuseKit: 400,
+
+	// Grenades
+	common.EqDecoy:      50,
+	common.EqMolotov:    400,
+	common.EqIncendiary: 600,
+	common.EqFlash:      200,
+	common.EqSmoke:      300,
+	common.EqHE:         300,
+}
+
+// weaponPrice returns the buy-menu price for eqType, or 0 if it has no
+// purchase cost (knife, bomb, world props, and anything else not in
+// weaponPrices).
+func weaponPrice(eqType common.EquipmentType) int {
+	return weaponPrices[eqType]
+}
+
+// LoadoutCost sums the buy-menu value of everything player is currently
+// carrying: weapons and grenades from Weapons(), plus armor/helmet (a
+// helmet purchase always includes the vest, so HasHelmet alone prices both)
+// and a defuse kit. This is "what the current loadout would cost to buy
+// from scratch", independent of MoneySpentThisRound (which also reflects
+// rebuys and teammate drops).
+func LoadoutCost(player *common.Player) int {
+	if player == nil {
+		return 0
+	}
+
+	cost := 0
+	for _, weapon := range player.Weapons() {
+		if weapon == nil {
+			continue
+		}
+		cost += weaponPrice(weapon.Type)
+	}
+
+	switch {
+	case player.HasHelmet():
+		cost += weaponPrices[common.EqHelmet]
+	case player.Armor() > 0:
+		cost += weaponPrices[common.EqKevlar]
+	}
+
+	if player.HasDefuseKit() {
+		cost += weaponPrices[common.EqDefuseKit]
+	}
+
+	return cost
+}
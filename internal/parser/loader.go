@@ -0,0 +1,376 @@
+package parser
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoaderConfig configures NewFromSource's rate-limited, cached,
+// single-flight fetch pipeline for remote DemoSources (HTTPSource,
+// S3Source). It has no effect on FileSource or other local reads.
+//
+// The zero value disables every feature (no rate limiting, no caching, no
+// de-duplication) and NewFromSource behaves exactly like
+// NewParserFromSource with download progress reporting added.
+type LoaderConfig struct {
+	// RatePerSecond limits requests to at most this many per second,
+	// bucketed per host (see remoteSource) so a Valve share-code mirror
+	// and an unrelated S3 bucket are throttled independently. <= 0 means
+	// no limit.
+	RatePerSecond int
+	// CacheDir, if set, caches the downloaded (already decompressed) demo
+	// on disk keyed by CacheKey, so re-parsing the same match ID/share
+	// code is free on a later call. Caching (and single-flight
+	// de-duplication, below) is disabled unless both CacheDir and
+	// CacheKey are set.
+	CacheDir string
+	// CacheKey identifies the demo being fetched, typically a match ID or
+	// share code. It names the cache entry in CacheDir and is also the
+	// de-duplication key: two concurrent NewFromSource calls for the same
+	// CacheKey share one download instead of fetching twice.
+	CacheKey string
+	// CacheMaxEntries bounds CacheDir to the N most recently used demos,
+	// evicting the least-recently-used entry first. <= 0 means unbounded.
+	CacheMaxEntries int
+	// SHA1 is the expected SHA1 checksum (hex-encoded) of the
+	// decompressed demo, if known - e.g. from a match-sharing API
+	// response. A mismatch fails the fetch before the cached file is
+	// installed or handed to demoinfocs.
+	//
+	// SHA1 is only checked when CacheDir/CacheKey cache the download to
+	// disk: verifying it for an uncached stream would mean buffering the
+	// whole demo before parsing could begin, defeating the point of
+	// streaming straight into demoinfocs.NewParser.
+	SHA1 string
+}
+
+// remoteSource is implemented by DemoSources that have a URL to rate-limit
+// and de-duplicate by (HTTPSource, and S3Source via embedding).
+// FileSource doesn't implement it, so NewFromSource's rate limiting is a
+// no-op for local files.
+type remoteSource interface {
+	sourceURL() string
+}
+
+// tokenBucket is the same minimal requests-per-second limiter
+// internal/steamapi/ratelimit.go uses: golang.org/x/time/rate isn't
+// vendored in this offline build, so this hand-rolls the one thing a
+// fetch pipeline needs from it - block until the next call is allowed.
+type tokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newTokenBucket(ratePerSecond int) *tokenBucket {
+	if ratePerSecond <= 0 {
+		ratePerSecond = 1
+	}
+	return &tokenBucket{interval: time.Second / time.Duration(ratePerSecond)}
+}
+
+func (t *tokenBucket) wait(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hostLimiters holds one evenly-spaced tokenBucket per host, shared by
+// every NewFromSource call in this process - two concurrent fetches
+// against the same mirror host wait on the same bucket instead of each
+// getting an independent rate.
+var (
+	hostLimitersMu sync.Mutex
+	hostLimiters   = map[string]*tokenBucket{}
+)
+
+func limiterForHost(host string, ratePerSecond int) *tokenBucket {
+	hostLimitersMu.Lock()
+	defer hostLimitersMu.Unlock()
+	tb, ok := hostLimiters[host]
+	if !ok {
+		tb = newTokenBucket(ratePerSecond)
+		hostLimiters[host] = tb
+	}
+	return tb
+}
+
+// fetchCall tracks one in-flight cached fetch so concurrent NewFromSource
+// calls for the same CacheKey share it instead of downloading twice - a
+// hand-rolled stand-in for golang.org/x/sync/singleflight, which also
+// isn't vendored here.
+type fetchCall struct {
+	done chan struct{}
+	path string
+	err  error
+}
+
+var (
+	inflightMu sync.Mutex
+	inflight   = map[string]*fetchCall{}
+)
+
+// joinOrLeadFetch returns (true, call) if the caller is now responsible
+// for actually performing the fetch for key, or (false, call) if another
+// goroutine is already doing so and the caller should wait on call.done.
+func joinOrLeadFetch(key string) (lead bool, call *fetchCall) {
+	inflightMu.Lock()
+	defer inflightMu.Unlock()
+	if c, ok := inflight[key]; ok {
+		return false, c
+	}
+	c := &fetchCall{done: make(chan struct{})}
+	inflight[key] = c
+	return true, c
+}
+
+// progressReader wraps a DemoSource's stream, invoking callback with stage
+// "downloading" as bytes are read so a caller sees download progress
+// before parsing begins.
+type progressReader struct {
+	rc       io.ReadCloser
+	read     int64
+	total    int64
+	callback ParseCallback
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.read += int64(n)
+	if r.callback != nil {
+		pct := 0.0
+		if r.total > 0 {
+			pct = float64(r.read) / float64(r.total)
+			if pct > 1 {
+				pct = 1
+			}
+		}
+		r.callback("downloading", int(r.read), int(r.total), pct)
+	}
+	return n, err
+}
+
+func (r *progressReader) Close() error {
+	return r.rc.Close()
+}
+
+// readerSource adapts an already-opened io.ReadCloser back into a
+// DemoSource, so NewParserFromSource can be reused to apply its usual
+// validation (non-empty, .dem extension) after NewFromSource has wrapped
+// the stream in rate limiting / progress reporting.
+type readerSource struct {
+	rc   io.ReadCloser
+	meta SourceMeta
+}
+
+func (s readerSource) Open(ctx context.Context) (io.ReadCloser, SourceMeta, error) {
+	return s.rc, s.meta, nil
+}
+
+// NewFromSource is NewParserFromSource generalized with a rate-limited,
+// cached, single-flight fetch pipeline in front of it - the remote
+// counterpart to NewParser/NewParserFromSource, which assume the caller
+// already has bytes ready to read. It's meant for callers plugging in an
+// HTTPSource/S3Source pointed at a Valve match-sharing mirror, S3, or any
+// other URL: source.Open is rate-limited per host (cfg.RatePerSecond),
+// de-duplicated against concurrent requests for the same demo
+// (cfg.CacheKey), cached on disk so a repeat parse is free
+// (cfg.CacheDir), and SHA1-verified when cached (cfg.SHA1).
+//
+// callback, if non-nil, is invoked with stage "downloading" (see
+// ParseCallback) while bytes are fetched, before parsing itself begins -
+// the same callback a caller would later pass to Parser.Parse or
+// Parser.ParseWithDB, just invoked one stage earlier.
+func NewFromSource(ctx context.Context, source DemoSource, cfg LoaderConfig, callback ParseCallback) (*Parser, error) {
+	resolved, err := resolveSource(ctx, source, cfg, callback)
+	if err != nil {
+		return nil, err
+	}
+	return NewParserFromSource(resolved)
+}
+
+// resolveSource applies LoaderConfig's rate limiting, caching and
+// single-flight de-duplication, returning a DemoSource ready to hand to
+// NewParserFromSource. Split out from NewFromSource so the fetch pipeline
+// itself can be exercised in tests without constructing a real demoinfocs
+// parser.
+func resolveSource(ctx context.Context, source DemoSource, cfg LoaderConfig, callback ParseCallback) (DemoSource, error) {
+	if rs, ok := source.(remoteSource); ok && cfg.RatePerSecond > 0 {
+		if err := limiterForHost(hostOf(rs.sourceURL()), cfg.RatePerSecond).wait(ctx); err != nil {
+			return nil, fmt.Errorf("parser: rate limit wait: %w", err)
+		}
+	}
+
+	if cfg.CacheDir == "" || cfg.CacheKey == "" {
+		return resolveUncached(ctx, source, callback)
+	}
+	return resolveCached(ctx, source, cfg, callback)
+}
+
+// hostOf returns rawURL's host, or rawURL itself if it doesn't parse as a
+// URL with a host (e.g. an S3 "bucket/key" path) - either way it's only
+// used to bucket the rate limiter, so a degraded fallback is fine.
+func hostOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
+
+func resolveUncached(ctx context.Context, source DemoSource, callback ParseCallback) (DemoSource, error) {
+	rc, meta, err := source.Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open demo source: %w", err)
+	}
+	pr := &progressReader{rc: rc, total: meta.Size, callback: callback}
+	return readerSource{rc: pr, meta: meta}, nil
+}
+
+func resolveCached(ctx context.Context, source DemoSource, cfg LoaderConfig, callback ParseCallback) (DemoSource, error) {
+	if err := os.MkdirAll(cfg.CacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("parser: create cache dir: %w", err)
+	}
+	cachePath := filepath.Join(cfg.CacheDir, cacheFileName(cfg.CacheKey))
+
+	if info, err := os.Stat(cachePath); err == nil {
+		now := time.Now()
+		os.Chtimes(cachePath, now, now) // bump LRU recency on a cache hit
+		if callback != nil {
+			callback("downloading", int(info.Size()), int(info.Size()), 1.0)
+		}
+		return FileSource{Path: cachePath}, nil
+	}
+
+	lead, call := joinOrLeadFetch(cfg.CacheKey)
+	if !lead {
+		<-call.done
+		if call.err != nil {
+			return nil, call.err
+		}
+		return FileSource{Path: call.path}, nil
+	}
+
+	call.path, call.err = cachePath, fetchToCache(ctx, source, cfg, cachePath, callback)
+	close(call.done)
+	inflightMu.Lock()
+	delete(inflight, cfg.CacheKey)
+	inflightMu.Unlock()
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	return FileSource{Path: cachePath}, nil
+}
+
+// fetchToCache downloads source into a temp file alongside cachePath,
+// verifies cfg.SHA1 if set, and renames it into place - so a reader never
+// sees a partially-written cache entry - then evicts old entries past
+// cfg.CacheMaxEntries.
+func fetchToCache(ctx context.Context, source DemoSource, cfg LoaderConfig, cachePath string, callback ParseCallback) error {
+	rc, meta, err := source.Open(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open demo source: %w", err)
+	}
+	defer rc.Close()
+
+	tmp, err := os.CreateTemp(cfg.CacheDir, ".download-*")
+	if err != nil {
+		return fmt.Errorf("parser: create temp cache file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename below succeeds
+
+	hasher := sha1.New()
+	pr := &progressReader{rc: rc, total: meta.Size, callback: callback}
+	if _, err := io.Copy(tmp, io.TeeReader(pr, hasher)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("parser: download demo: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("parser: close temp cache file: %w", err)
+	}
+
+	if cfg.SHA1 != "" {
+		if got := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(got, cfg.SHA1) {
+			return fmt.Errorf("parser: sha1 mismatch for %s: got %s, want %s", meta.Name, got, cfg.SHA1)
+		}
+	}
+
+	if err := os.Rename(tmpPath, cachePath); err != nil {
+		return fmt.Errorf("parser: install cached demo: %w", err)
+	}
+
+	evictLRU(cfg.CacheDir, cfg.CacheMaxEntries)
+	return nil
+}
+
+// evictLRU removes the least-recently-used files in dir (by mtime) until
+// at most maxEntries remain. maxEntries <= 0 means unbounded.
+func evictLRU(dir string, maxEntries int) {
+	if maxEntries <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type cacheFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []cacheFile
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".download-") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{path: filepath.Join(dir, e.Name()), modTime: info.ModTime()})
+	}
+	if len(files) <= maxEntries {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files[:len(files)-maxEntries] {
+		os.Remove(f.path)
+	}
+}
+
+// cacheFileName turns an arbitrary CacheKey (match ID, share code, ...)
+// into a safe file name within CacheDir.
+func cacheFileName(key string) string {
+	safe := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(key)
+	return safe + ".dem"
+}
@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func demoServer(t *testing.T, body string) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestResolveSourceUncachedReportsDownloadProgress(t *testing.T) {
+	srv, _ := demoServer(t, "demo-bytes")
+
+	var stages []string
+	resolved, err := resolveSource(context.Background(), HTTPSource{URL: srv.URL + "/match.dem"}, LoaderConfig{},
+		func(stage string, tick, round int, pct float64) { stages = append(stages, stage) })
+	if err != nil {
+		t.Fatalf("resolveSource failed: %v", err)
+	}
+	rc, _, err := resolved.Open(context.Background())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rc.Close()
+	if _, err := io.ReadAll(rc); err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+
+	if len(stages) == 0 {
+		t.Fatal("expected at least one downloading progress callback")
+	}
+	for _, s := range stages {
+		if s != "downloading" {
+			t.Errorf("expected stage %q, got %q", "downloading", s)
+		}
+	}
+}
+
+func TestResolveSourceCachesToDisk(t *testing.T) {
+	srv, requests := demoServer(t, "demo-bytes")
+	cacheDir := t.TempDir()
+	cfg := LoaderConfig{CacheDir: cacheDir, CacheKey: "match123"}
+
+	if _, err := resolveSource(context.Background(), HTTPSource{URL: srv.URL + "/match.dem"}, cfg, nil); err != nil {
+		t.Fatalf("first resolveSource failed: %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Fatalf("expected 1 request after first fetch, got %d", got)
+	}
+
+	resolved, err := resolveSource(context.Background(), HTTPSource{URL: srv.URL + "/match.dem"}, cfg, nil)
+	if err != nil {
+		t.Fatalf("second resolveSource failed: %v", err)
+	}
+	if _, ok := resolved.(FileSource); !ok {
+		t.Errorf("expected a cache hit to resolve to a FileSource, got %T", resolved)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("expected cache hit to avoid a second request, got %d total requests", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, "match123.dem")); err != nil {
+		t.Errorf("expected cached demo on disk: %v", err)
+	}
+}
+
+func TestResolveSourceDeduplicatesConcurrentFetches(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			close(started)
+			<-release
+		}
+		w.Write([]byte("demo-bytes"))
+	}))
+	defer srv.Close()
+
+	cacheDir := t.TempDir()
+	cfg := LoaderConfig{CacheDir: cacheDir, CacheKey: "shared-match"}
+
+	var wg sync.WaitGroup
+	results := make([]DemoSource, 2)
+	errs := make([]error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = resolveSource(context.Background(), HTTPSource{URL: srv.URL + "/match.dem"}, cfg, nil)
+		}(i)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the leading fetch to start")
+	}
+	time.Sleep(50 * time.Millisecond) // give the follower a chance to join in-flight
+	close(release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("resolveSource[%d] failed: %v", i, err)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 HTTP request shared between both callers, got %d", got)
+	}
+}
+
+func TestResolveSourceVerifiesSHA1(t *testing.T) {
+	srv, _ := demoServer(t, "demo-bytes")
+	cacheDir := t.TempDir()
+
+	_, err := resolveSource(context.Background(), HTTPSource{URL: srv.URL + "/match.dem"},
+		LoaderConfig{CacheDir: cacheDir, CacheKey: "bad-sha", SHA1: "0000000000000000000000000000000000000"}, nil)
+	if err == nil {
+		t.Fatal("expected a sha1 mismatch error")
+	}
+	if _, statErr := os.Stat(filepath.Join(cacheDir, "bad-sha.dem")); statErr == nil {
+		t.Error("expected no cache file to be installed after a sha1 mismatch")
+	}
+
+	sum := sha1.Sum([]byte("demo-bytes"))
+	if _, err := resolveSource(context.Background(), HTTPSource{URL: srv.URL + "/match.dem"},
+		LoaderConfig{CacheDir: cacheDir, CacheKey: "good-sha", SHA1: hex.EncodeToString(sum[:])}, nil); err != nil {
+		t.Fatalf("expected a matching sha1 to succeed: %v", err)
+	}
+}
+
+func TestEvictLRUKeepsOnlyMostRecentEntries(t *testing.T) {
+	dir := t.TempDir()
+	for i, name := range []string{"a.dem", "b.dem", "c.dem"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		mtime := time.Now().Add(time.Duration(i) * time.Second)
+		os.Chtimes(path, mtime, mtime)
+	}
+
+	evictLRU(dir, 2)
+
+	if _, err := os.Stat(filepath.Join(dir, "a.dem")); err == nil {
+		t.Error("expected the oldest entry (a.dem) to be evicted")
+	}
+	for _, name := range []string{"b.dem", "c.dem"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to survive eviction: %v", name, err)
+		}
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"os"
-	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
@@ -23,14 +24,304 @@ import (
 	"github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/msg"
 
 	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/ipc/broker"
+	"cs-griefer-electron/internal/parser/ballistics"
 	"cs-griefer-electron/internal/parser/extractors"
+	"cs-griefer-electron/internal/steamapi"
+	"cs-griefer-electron/internal/steamid"
+	"cs-griefer-electron/internal/userid"
 )
 
 // Parser wraps demoinfocs to parse CS2 demo files.
 type Parser struct {
 	parser dem.Parser
-	path   string
-	file   *os.File // Store file handle for explicit cleanup
+	rc     io.ReadCloser // underlying source stream; closed via Close
+
+	// sourceName is the demo's display name (see SourceMeta.Name), used
+	// for Source-detection heuristics regardless of which DemoSource it
+	// came from.
+	sourceName string
+	// localPath is non-empty only when the Parser was opened from a
+	// FileSource, so the best-effort header peek below can read the file
+	// a second time. Other DemoSource implementations have no seekable
+	// path to peek at, so that heuristic is simply skipped for them.
+	localPath string
+
+	// backpressure, when set via SetBackpressureSampler, is polled
+	// alongside the fixed positionInterval so a memory governor can
+	// thin out position sampling (e.g. every tick -> every 4th tick)
+	// under heap pressure without the parser caring who's driving it.
+	backpressure func() int
+
+	// steamClient, when set via SetSteamEnrichment, resolves extracted
+	// events' actor/victim SteamIDs against the Steam Web API before the
+	// final flush. Left nil (the default) for fully offline parsing.
+	steamClient *steamapi.Client
+
+	// eventBroker, when set via SetEventBroker, receives a copy of every
+	// extracted event as it's flushed, so subscribers can follow a match
+	// live instead of waiting for the sorted-merge phase to finish. Left
+	// as a no-op Publisher (the default) when no --broker flag is set.
+	eventBroker   broker.Publisher
+	eventBrokerID string
+
+	// onRoundCheckpoint, when set via SetCheckpointCallback, is invoked
+	// once per completed round so a caller can persist a Checkpoint
+	// (NDJSON offset, chunk file list, etc.) for --resume.
+	onRoundCheckpoint func(roundIndex, tick int)
+
+	// resumeFromRound, when set via ResumeFrom, suppresses re-emitting
+	// events for rounds already covered by a previous run's checkpoint.
+	// -1 (the default) means "not resuming, emit everything".
+	resumeFromRound int
+
+	// resumeData, when set by ResumeParser, is the MatchData a previous
+	// in-memory Parse/ParseWithDB call had reached before it was
+	// checkpointed - ParseWithDB merges it with the fresh re-parse's data
+	// for rounds up to resumeFromRound, instead of discarding it and
+	// re-deriving the same early rounds from scratch. Nil for a parser
+	// not created by ResumeParser.
+	resumeData *MatchData
+
+	// data points at the MatchData a Parse/ParseWithDB call is currently
+	// accumulating, so Checkpoint can serialize it mid-parse - it's only
+	// safe to read from the same goroutine driving the parse, i.e. from
+	// inside the onRoundCheckpoint callback below.
+	data *MatchData
+	// checkpointRound and checkpointTick are the round/tick Checkpoint
+	// reports alongside data, updated right before each onRoundCheckpoint
+	// call.
+	checkpointRound int
+	checkpointTick  int
+
+	// onRoundLifecycle, when set via SetRoundLifecycleCallback, is invoked
+	// on every RoundStart/RoundEnd so a caller can mirror match progress
+	// to an external system (e.g. a webhook dispatcher) without this
+	// package knowing anything about HTTP or match-management protocols.
+	// winner is nil for "OnRoundStart" and for an RoundEnd with no decided
+	// winner.
+	onRoundLifecycle func(event string, roundIndex, tick int, winner *string)
+
+	// onPlayerDeath, when set via SetPlayerDeathCallback, is invoked for
+	// every Kill event alongside the round/tick it happened in.
+	onPlayerDeath func(e events.Kill, roundIndex, tick int)
+
+	// onBombPlanted, when set via SetBombPlantedCallback, is invoked for
+	// every BombPlanted event alongside the round/tick it happened in.
+	onBombPlanted func(e events.BombPlanted, roundIndex, tick int)
+
+	// recordInputs, when set via SetRecordInputs, makes ParseWithDB
+	// reconstruct and persist every player's per-tick button state to
+	// player_inputs instead of skipping it - off by default since it's
+	// substantially higher-volume than the sampled player_positions table.
+	recordInputs bool
+
+	// positionEncoding and positionKeyframeInterval, set via
+	// SetPositionEncoding, select between ParseWithDB writing sampled
+	// positions to player_positions ("full", the default) or to
+	// player_positions_keyframes/player_positions_deltas ("delta") - see
+	// the position FrameDone handler and db.ReconstructPositions.
+	positionEncoding         string
+	positionKeyframeInterval int
+
+	// enabledExtractors, when set via SetEnabledExtractors, restricts the
+	// behavior-detector registry (see extractors.ExtractorRegistry) to only
+	// the named extractors - "" (the default) runs every registered one.
+	enabledExtractors string
+
+	// steamIDFormat, set via SetSteamIDFormat, picks which of
+	// steamid.ID.Formats' representations ParseWithDB/Stream use as the
+	// primary SteamID string threaded through MatchData/db/streaming event
+	// structs - "" (the default) is decimal SteamID64, this package's
+	// behavior before SteamIDFormat existed.
+	steamIDFormat steamid.PrimaryFormat
+
+	// streamMu guards streaming/closePending below, since Stream's handlers
+	// run on the goroutine driving ParseToEnd while Close or Cancel may be
+	// called from another goroutine (or reentrantly, from inside a handler).
+	streamMu sync.Mutex
+	// streaming is true for the duration of a Stream(ctx) call, so Close
+	// knows a handler-triggered Close must defer to Stream's own cleanup
+	// instead of closing out from under a parse still in progress.
+	streaming bool
+	// closePending is set when Close is called while streaming is true;
+	// Stream checks it after ParseToEnd returns and performs the deferred
+	// close itself.
+	closePending bool
+
+	// onStreamKill, onStreamGrenadeThrown, onStreamPlayerPosition and
+	// onStreamChatMessage back OnKill/OnGrenadeThrown/OnPlayerPosition/
+	// OnChatMessage (see stream.go) - set, at most one callback each, before
+	// calling Stream.
+	onStreamKill           func(KillEvent)
+	onStreamGrenadeThrown  func(GrenadeThrownEvent)
+	onStreamPlayerPosition func(PlayerPositionEvent)
+	onStreamChatMessage    func(ChatMessageEvent)
+}
+
+// Resumable is implemented by Parser so a caller can checkpoint progress
+// and, on a resumed run, avoid re-emitting output that's already safely
+// on disk. See Checkpoint's doc comment for why this can't skip decoding
+// itself - demoinfocs-golang v5 has no mid-demo seek API.
+type Resumable interface {
+	ResumeFrom(ckpt *Checkpoint) error
+}
+
+// SetCheckpointCallback registers fn to be called once per completed
+// round during ParseWithDB, so a caller can persist a Checkpoint without
+// this package knowing anything about file paths or chunk formats.
+func (p *Parser) SetCheckpointCallback(fn func(roundIndex, tick int)) {
+	p.onRoundCheckpoint = fn
+}
+
+// SetRoundLifecycleCallback registers fn to be called with "OnRoundStart"
+// and "OnRoundEnd" as each round begins and ends during ParseWithDB, so a
+// caller can forward match progress to an external system (e.g. a webhook
+// dispatcher) without this package depending on that system.
+func (p *Parser) SetRoundLifecycleCallback(fn func(event string, roundIndex, tick int, winner *string)) {
+	p.onRoundLifecycle = fn
+}
+
+// SetPlayerDeathCallback registers fn to be called for every Kill event
+// during ParseWithDB, alongside the round index and tick it happened in.
+func (p *Parser) SetPlayerDeathCallback(fn func(e events.Kill, roundIndex, tick int)) {
+	p.onPlayerDeath = fn
+}
+
+// SetBombPlantedCallback registers fn to be called for every BombPlanted
+// event during ParseWithDB, alongside the round index and tick it
+// happened in.
+func (p *Parser) SetBombPlantedCallback(fn func(e events.BombPlanted, roundIndex, tick int)) {
+	p.onBombPlanted = fn
+}
+
+// SetRecordInputs enables reconstructing and persisting every player's
+// per-tick button state (see db.PlayerInput) during ParseWithDB, behind
+// --record-inputs. Left false (the default), ParseWithDB never touches
+// player_inputs.
+func (p *Parser) SetRecordInputs(enabled bool) {
+	p.recordInputs = enabled
+}
+
+// SetPositionEncoding selects how ParseWithDB writes sampled positions:
+// "full" (the default, used when this is never called or encoding is
+// anything other than "delta") writes player_positions as before; "delta"
+// writes a full row to player_positions_keyframes every keyframeInterval
+// sampled ticks per player and compact player_positions_deltas rows in
+// between (see db.ReconstructPositions). keyframeInterval <= 0 falls back
+// to 64.
+func (p *Parser) SetPositionEncoding(encoding string, keyframeInterval int) {
+	p.positionEncoding = encoding
+	if keyframeInterval <= 0 {
+		keyframeInterval = 64
+	}
+	p.positionKeyframeInterval = keyframeInterval
+}
+
+// SetEnabledExtractors restricts the behavior-detector registry (disconnect,
+// team_kill, team_flash, and any third party extractor registered alongside
+// them - see extractors.ExtractorRegistry) to the comma-separated names in
+// csv, disabling every other registered one. An empty csv (the default, and
+// what --extractors leaves it at when unset) runs every registered
+// extractor.
+func (p *Parser) SetEnabledExtractors(csv string) {
+	p.enabledExtractors = csv
+}
+
+// SetSteamIDFormat controls which text representation of a player's
+// SteamID - steamid64 (the default), steamid32, steamid2, or steamid3 -
+// ParseWithDB and Stream use as the primary SteamID string in
+// MatchData/db rows and streaming event structs. An unrecognized format
+// falls back to steamid64, the same as "" (see steamid.ID.Primary).
+func (p *Parser) SetSteamIDFormat(format string) {
+	p.steamIDFormat = steamid.PrimaryFormat(format)
+}
+
+// getSteamID converts player's SteamID64 to p.steamIDFormat's string
+// representation, handling nil players.
+func (p *Parser) getSteamID(player *common.Player) *string {
+	if player == nil {
+		return nil
+	}
+	id := steamid.ID(player.SteamID64).Primary(p.steamIDFormat)
+	return &id
+}
+
+// ResumeFrom configures ParseWithDB to suppress re-emitting events for any
+// round up to and including ckpt.LastRoundIndex, on the assumption the
+// caller already has that output durably written from a previous run. A
+// nil ckpt (or never calling ResumeFrom) emits every round, as normal.
+func (p *Parser) ResumeFrom(ckpt *Checkpoint) error {
+	if ckpt == nil {
+		p.resumeFromRound = -1
+		return nil
+	}
+	p.resumeFromRound = ckpt.LastRoundIndex
+	return nil
+}
+
+// SetSteamEnrichment enables Steam Web API enrichment of extracted events:
+// persona name, VAC/game ban status, profile visibility and CS2 playtime
+// get folded into each event's MetaJSON under "actor_profile"/
+// "victim_profile". apiKey is required; cacheDir/cacheTTL are forwarded to
+// steamapi.NewClient unchanged. Never call this to keep parsing fully
+// offline - it is opt-in precisely so --steam-api-key absent means no
+// network access at all.
+func (p *Parser) SetSteamEnrichment(apiKey, cacheDir string, cacheTTL time.Duration) error {
+	client, err := steamapi.NewClient(apiKey, cacheDir, cacheTTL)
+	if err != nil {
+		return err
+	}
+	p.steamClient = client
+	return nil
+}
+
+// SetEventBroker configures pub to receive a copy of every event extracted
+// during ParseWithDB, published under broker.EventTopic(matchID,
+// event.Type), plus a copy of every round lifecycle marker (round_start,
+// freezetime_end, round_end) under broker.LifecycleTopic(matchID, marker)
+// - one subject per event type/marker, so a live consumer can subscribe
+// to just what it needs. Never call this to keep parsing fully
+// broker-free - a nil eventBroker (the default) skips publishing
+// entirely rather than going through a no-op Publisher.
+func (p *Parser) SetEventBroker(pub broker.Publisher, matchID string) {
+	p.eventBroker = pub
+	p.eventBrokerID = matchID
+}
+
+// publishLifecycle publishes one round lifecycle marker (see
+// SetEventBroker) if an eventBroker is configured; it's a no-op
+// otherwise. winner is nil for "round_start"/"freezetime_end" and for a
+// "round_end" with no decided winner. extra carries marker-specific
+// fields (e.g. round_end's t_wins/ct_wins).
+func (p *Parser) publishLifecycle(marker string, roundIndex, tick int, winner *string, extra map[string]interface{}) {
+	if p.eventBroker == nil {
+		return
+	}
+
+	payload := map[string]interface{}{"round": roundIndex, "tick": tick}
+	if winner != nil {
+		payload["winner"] = *winner
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	topic := broker.LifecycleTopic(p.eventBrokerID, marker)
+	dedupID := fmt.Sprintf("%s:%s:%d", p.eventBrokerID, marker, roundIndex)
+	p.eventBroker.PublishDedup(topic, payloadJSON, dedupID)
+}
+
+// SetBackpressureSampler registers a callback that returns an additional
+// divisor to apply to positionInterval: 1 under normal conditions, >1 to
+// sample less often once a caller-side memory governor reports back-
+// pressure. A nil sampler (the default) leaves positionInterval as-is.
+func (p *Parser) SetBackpressureSampler(sampler func() int) {
+	p.backpressure = sampler
 }
 
 // MatchData contains extracted match information.
@@ -124,6 +415,9 @@ type GrenadeEventData struct {
 	ThrowerSteamID *string
 	ThrowerName    *string
 	ThrowerTeam    *string
+	// FiresJSON is only populated for inferno_start/inferno_update/
+	// inferno_expire rows - see db.GrenadeEvent.FiresJSON.
+	FiresJSON *string
 }
 
 // ShotData contains weapon fire information.
@@ -145,37 +439,46 @@ type ParseCallback func(stage string, tick, round int, pct float64)
 
 // NewParser creates a new parser for the given demo file.
 func NewParser(path string) (*Parser, error) {
-	// Validate file exists and is readable
-	info, err := os.Stat(path)
+	return NewParserFromSource(FileSource{Path: path})
+}
+
+// NewParserFromSource is NewParser generalized to any DemoSource - a local
+// file (FileSource, what NewParser uses), an HTTP(S) URL (HTTPSource), an
+// S3 object (S3Source), or a caller-supplied implementation. It applies
+// the same validation NewParser always has (non-empty, .dem extension)
+// against src's reported SourceMeta instead of an os.Stat call, since not
+// every source has one.
+func NewParserFromSource(src DemoSource) (*Parser, error) {
+	rc, meta, err := src.Open(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("failed to access demo file: %w", err)
+		return nil, fmt.Errorf("failed to open demo source: %w", err)
 	}
-	if info.Size() == 0 {
+	if meta.Size == 0 {
+		rc.Close()
 		return nil, fmt.Errorf("demo file is empty")
 	}
-
-	// Basic validation: check file extension
-	if !strings.HasSuffix(strings.ToLower(path), ".dem") {
+	if !strings.HasSuffix(strings.ToLower(meta.Name), ".dem") {
+		rc.Close()
 		return nil, fmt.Errorf("file does not have .dem extension")
 	}
 
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open demo file: %w", err)
-	}
-
-	p := dem.NewParser(f)
+	p := dem.NewParser(rc)
 
 	// Note: We can't validate the header here because the parser needs to
 	// read the demo file first. Validation will happen during Parse().
 	// Note: demoinfocs may read the entire demo file into memory during parsing.
-	// The file handle is stored so we can explicitly close it after parsing to free memory.
+	// The stream is stored so we can explicitly close it after parsing to free memory.
 
-	return &Parser{
-		parser: p,
-		path:   path,
-		file:   f, // Store file handle for explicit cleanup
-	}, nil
+	parser := &Parser{
+		parser:          p,
+		rc:              rc,
+		sourceName:      meta.Name,
+		resumeFromRound: -1,
+	}
+	if fs, ok := src.(FileSource); ok {
+		parser.localPath = fs.Path
+	}
+	return parser, nil
 }
 
 // Parse parses the demo file and extracts match data.
@@ -196,11 +499,19 @@ func (p *Parser) Parse(ctx context.Context, callback ParseCallback) (*MatchData,
 // If steamIDSet is provided, only data for those Steam IDs will be stored (positions, chat, grenades, shots, players).
 func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn *sql.DB, positionInterval int, writer interface {
 	InsertPlayerPositions(context.Context, []db.PlayerPosition) error
+	InsertPlayerPositionKeyframes(context.Context, []db.PlayerPosition) error
+	InsertPlayerPositionDeltas(context.Context, []db.PlayerPositionDelta) error
 	InsertPlayer(context.Context, db.Player) error
 	InsertChatMessages(context.Context, []db.ChatMessage) error
+	InsertSystemChatEvents(context.Context, []db.SystemChatEvent) error
 	InsertGrenadePositions(context.Context, []db.GrenadePosition) error
 	InsertGrenadeEvents(context.Context, []db.GrenadeEvent) error
+	InsertBombEvents(context.Context, []db.BombEvent) error
+	InsertGrenadePredictions(context.Context, []db.GrenadePrediction) error
+	InsertPlayerInputs(context.Context, []db.PlayerInput) error
 	InsertShots(context.Context, []db.Shot) error
+	InsertWeaponSwitches(context.Context, []db.WeaponSwitch) error
+	InsertPlayerRoundWeaponStats(context.Context, []db.PlayerRoundWeaponStats) error
 }, matchID string, eventsFile *os.File, steamIDSet map[string]bool) (*MatchData, error) {
 	// Collection modes:
 	// 1. JSON streaming mode: eventsFile != nil, writer = nil, matchID = ""
@@ -213,12 +524,23 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	//    - All data accumulated in memory for backward compatibility
 	//    - Large slices ARE allocated
 
+	// steamIDFormat is read once into a local so every closure below can
+	// capture it by value instead of the receiver p - some of those
+	// closures shadow p with an unrelated *common.Player loop variable
+	// of the same name (see the "for _, p := range participants.All()"
+	// loops further down), so p.steamIDFormat wouldn't always resolve to
+	// this Parser there.
+	steamIDFormat := p.steamIDFormat
+
 	// In JSON mode (eventsFile != nil), we don't need to store most data in memory
 	// Only store essential data: Players, Rounds, and minimal metadata
 	data := &MatchData{
 		Players: make([]PlayerData, 0),
 		Rounds:  make([]RoundData, 0),
 	}
+	// Expose the in-progress MatchData to Checkpoint - see p.data's doc
+	// comment on the Parser struct.
+	p.data = data
 
 	// Only allocate large telemetry slices in true in-memory mode (legacy fallback)
 	// JSON mode and DB streaming mode should NEVER allocate these slices
@@ -237,7 +559,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	var roundStartTick int
 	var freezeEndTick *int
 	playerMap := make(map[uint64]*PlayerData) // steamid -> player
-	
+
 	// Track total tick count for progress calculation
 	// We'll estimate based on maxTick seen so far, and update as we go
 	// Start with a very high estimate so progress starts low and increases gradually
@@ -251,15 +573,369 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	positionBuffer := make([]db.PlayerPosition, 0, 5000) // Buffer up to 5000 positions
 	const positionBatchSize = 5000                       // Flush every 5000 positions (was 1000)
 
+	// positionKeyframeBuffer/positionDeltaBuffer are player_positions'
+	// counterparts under --position-encoding=delta - see
+	// p.positionEncoding and appendPositionSample below.
+	positionKeyframeBuffer := make([]db.PlayerPosition, 0, 1000)
+	positionDeltaBuffer := make([]db.PlayerPositionDelta, 0, 5000)
+	const positionKeyframeBatchSize = 1000
+	const positionDeltaBatchSize = 5000
+
+	// positionDeltaState tracks, per player, the last absolute
+	// position/yaw written (keyframe or delta) and how many samples have
+	// elapsed since the last keyframe, so appendPositionSample knows
+	// whether the next sample can be a delta or needs a fresh keyframe.
+	// Reset at every RoundStart so a round's delta chain never spans
+	// across a round boundary (db.ReconstructPositions replays one round
+	// at a time).
+	type positionDeltaState struct {
+		x, y, z              float64
+		yaw                  float64
+		samplesSinceKeyframe int
+	}
+	positionDeltaStates := make(map[uint64]*positionDeltaState)
+
+	// appendPositionSample is positionBuffer's --position-encoding=delta
+	// counterpart: it writes a full player_positions_keyframes row every
+	// positionKeyframeInterval samples (or whenever a delta's dx/dy/dz/yaw
+	// delta wouldn't fit int16 - e.g. a teleport), and a
+	// player_positions_deltas row otherwise. dx/dy/dz are rounded to whole
+	// world units and yaw to 0.5 degree steps, so reconstruction matches
+	// pos to that resolution rather than bit-for-bit.
+	appendPositionSample := func(steamID64 uint64, pos db.PlayerPosition) {
+		var yaw float64
+		if pos.Yaw != nil {
+			yaw = *pos.Yaw
+		}
+
+		state, hasState := positionDeltaStates[steamID64]
+		if hasState && state.samplesSinceKeyframe < p.positionKeyframeInterval {
+			dx := math.Round(pos.X - state.x)
+			dy := math.Round(pos.Y - state.y)
+			dz := math.Round(pos.Z - state.z)
+
+			yawDelta := yaw - state.yaw
+			for yawDelta > 180 {
+				yawDelta -= 360
+			}
+			for yawDelta < -180 {
+				yawDelta += 360
+			}
+			yawDeltaQ := math.Round(yawDelta / 0.5)
+
+			if dx >= -32768 && dx <= 32767 && dy >= -32768 && dy <= 32767 &&
+				dz >= -32768 && dz <= 32767 && yawDeltaQ >= -32768 && yawDeltaQ <= 32767 {
+				positionDeltaBuffer = append(positionDeltaBuffer, db.PlayerPositionDelta{
+					MatchID:    pos.MatchID,
+					RoundIndex: pos.RoundIndex,
+					Tick:       pos.Tick,
+					SteamID:    pos.SteamID,
+					DX:         int16(dx),
+					DY:         int16(dy),
+					DZ:         int16(dz),
+					YawDelta:   int16(yawDeltaQ),
+					Team:       pos.Team,
+					Health:     pos.Health,
+					Armor:      pos.Armor,
+					Weapon:     pos.Weapon,
+				})
+				if len(positionDeltaBuffer) >= positionDeltaBatchSize {
+					if err := writer.InsertPlayerPositionDeltas(ctx, positionDeltaBuffer); err != nil {
+						fmt.Fprintf(os.Stderr, "WARN: Failed to insert position deltas batch: %v\n", err)
+					} else {
+						positionDeltaBuffer = positionDeltaBuffer[:0]
+					}
+				}
+
+				state.x, state.y, state.z = pos.X, pos.Y, pos.Z
+				state.yaw = yaw
+				state.samplesSinceKeyframe++
+				return
+			}
+			// Falls through to a keyframe: the movement since the last
+			// sample doesn't fit int16 (e.g. a teleport/respawn).
+		}
+
+		positionKeyframeBuffer = append(positionKeyframeBuffer, pos)
+		if len(positionKeyframeBuffer) >= positionKeyframeBatchSize {
+			if err := writer.InsertPlayerPositionKeyframes(ctx, positionKeyframeBuffer); err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Failed to insert position keyframes batch: %v\n", err)
+			} else {
+				positionKeyframeBuffer = positionKeyframeBuffer[:0]
+			}
+		}
+		positionDeltaStates[steamID64] = &positionDeltaState{x: pos.X, y: pos.Y, z: pos.Z, yaw: yaw, samplesSinceKeyframe: 0}
+	}
+
+	// currentRoundPositions is phase 1 of the AFK/body-block two-phase
+	// pipeline for the no-writer (JSON/Parquet streaming) path: positions
+	// that would otherwise be discarded (see the "never stored in memory"
+	// note below) are kept for the single in-progress round only, then fed
+	// to AFKExtractor.ProcessAFKFromPositions/BodyBlockExtractor.
+	// ProcessRoundFromPositions at round end and discarded - phase 2 runs as
+	// a pure function over that round's positions instead of re-querying
+	// SQLite, which the streaming modes don't have. Database mode doesn't
+	// use this at all: its phase 2 runs post-parse in cmd/parser/main.go
+	// via extractors.Pipeline against the persisted player_positions table.
+	var currentRoundPositions []db.PlayerPosition
+
 	chatBuffer := make([]db.ChatMessage, 0, 500) // Buffer up to 500 chat messages
 	const chatBatchSize = 500                    // Flush every 500 messages (was 100)
 
+	// systemChatBuffer holds server/system log lines (join/leave, kicks,
+	// name changes, map/round transitions) distinct from player chat - see
+	// appendSystemChat below.
+	systemChatBuffer := make([]db.SystemChatEvent, 0, 200)
+	const systemChatBatchSize = 200
+
+	// appendSystemChat buffers one system/server chat entry, honoring the
+	// same steamIDSet filter the player chat path does by leaving the
+	// decision of whether a steamID passes it to the caller (most call
+	// sites aren't attributable to a single player at all, e.g. map_change).
+	appendSystemChat := func(kind string, roundIndex, tick int, steamID, name *string, message string) {
+		if writer == nil || matchID == "" {
+			return
+		}
+		systemChatBuffer = append(systemChatBuffer, db.SystemChatEvent{
+			MatchID:    matchID,
+			RoundIndex: roundIndex,
+			Tick:       tick,
+			Kind:       kind,
+			SteamID:    steamID,
+			Name:       name,
+			Message:    message,
+		})
+		if len(systemChatBuffer) >= systemChatBatchSize {
+			if err := writer.InsertSystemChatEvents(ctx, systemChatBuffer); err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Failed to insert system chat events batch: %v\n", err)
+			} else {
+				systemChatBuffer = systemChatBuffer[:0]
+			}
+		}
+	}
+
 	grenadePositionBuffer := make([]db.GrenadePosition, 0, 2000) // Buffer up to 2000 grenade positions
 	const grenadePositionBatchSize = 2000                        // Flush every 2000 positions (was 500)
 
 	grenadeEventBuffer := make([]db.GrenadeEvent, 0, 2000) // Buffer up to 2000 grenade events
 	const grenadeEventBatchSize = 2000                     // Flush every 2000 events (was 500)
 
+	// bombEventBuffer holds the bomb's lifecycle (pickup/drop/plant/defuse/
+	// explode) plus periodic position samples - see the bomb event handlers
+	// registered below the grenade-tracking one.
+	bombEventBuffer := make([]db.BombEvent, 0, 500)
+	const bombEventBatchSize = 500
+	// bombSite tracks the Bombsite the bomb was last planted at, since only
+	// BombPlanted/BombExplode/BombDefused carry a Site - BombDefuseStart
+	// and position samples need it too, so it's cached here until the next
+	// round resets it.
+	var bombSite *string
+	lastBombPositionTick := 0
+
+	// lastInfernoUpdateTick throttles the periodic inferno_update rows
+	// emitted below (one shared cadence for every active inferno, same as
+	// lastBombPositionTick above) so a long-burning molotov doesn't emit a
+	// row every single tick.
+	lastInfernoUpdateTick := 0
+
+	// bombPosition returns the bomb's current position regardless of
+	// whether it's held, dropped, or planted - common.Bomb.Position()
+	// already resolves that (Carrier's position if held, else
+	// LastOnGroundPosition), so this just guards against a nil GameState/Bomb.
+	bombPosition := func() (x, y, z float64) {
+		gs := p.parser.GameState()
+		if gs == nil {
+			return 0, 0, 0
+		}
+		bomb := gs.Bomb()
+		if bomb == nil {
+			return 0, 0, 0
+		}
+		pos := bomb.Position()
+		return pos.X, pos.Y, pos.Z
+	}
+
+	// siteStr converts a demoinfocs Bombsite into the *string bomb_events
+	// expects, returning nil for BomsiteUnknown rather than storing a
+	// null-byte string.
+	siteStr := func(site events.Bombsite) *string {
+		if site == events.BomsiteUnknown { // sic - misspelled in demoinfocs-golang itself
+			return nil
+		}
+		s := string(rune(site))
+		return &s
+	}
+
+	// infernoFirePoint is the JSON shape stored in GrenadeEvent.FiresJSON for
+	// inferno_start/inferno_update/inferno_expire rows - one entry per flame
+	// demoinfocs is currently tracking for that inferno (including already
+	// extinguished ones, since Inferno.Fires() returns past + present).
+	type infernoFirePoint struct {
+		X         float64 `json:"x"`
+		Y         float64 `json:"y"`
+		Z         float64 `json:"z"`
+		IsBurning bool    `json:"is_burning"`
+	}
+
+	// infernoCentroidAndFires computes the centroid of inf's active flames
+	// (falling back to all flames if none are currently burning, e.g. for the
+	// inferno_expire row) for GrenadeEvent.X/Y/Z, plus a JSON dump of every
+	// flame origin for FiresJSON so downstream 2D renderers can draw the
+	// actual burning area instead of a single point.
+	infernoCentroidAndFires := func(inf *common.Inferno) (x, y, z float64, firesJSON *string) {
+		if inf == nil {
+			return 0, 0, 0, nil
+		}
+		all := inf.Fires().List()
+		active := inf.Fires().Active().List()
+		fires := active
+		if len(fires) == 0 {
+			fires = all
+		}
+		if len(fires) > 0 {
+			var sumX, sumY, sumZ float64
+			for _, f := range fires {
+				sumX += f.X
+				sumY += f.Y
+				sumZ += f.Z
+			}
+			n := float64(len(fires))
+			x, y, z = sumX/n, sumY/n, sumZ/n
+		}
+		if len(all) > 0 {
+			points := make([]infernoFirePoint, len(all))
+			for i, f := range all {
+				points[i] = infernoFirePoint{X: f.X, Y: f.Y, Z: f.Z, IsBurning: f.IsBurning}
+			}
+			if encoded, err := json.Marshal(points); err == nil {
+				s := string(encoded)
+				firesJSON = &s
+			}
+		}
+		return x, y, z, firesJSON
+	}
+
+	// appendBombEvent appends one bomb_events row and flushes once
+	// bombEventBatchSize is reached, mirroring the grenadeEventBuffer append
+	// sites elsewhere in this function.
+	appendBombEvent := func(row db.BombEvent) {
+		if writer == nil || matchID == "" {
+			return
+		}
+		bombEventBuffer = append(bombEventBuffer, row)
+		if len(bombEventBuffer) >= bombEventBatchSize {
+			if err := writer.InsertBombEvents(ctx, bombEventBuffer); err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Failed to insert bomb events batch: %v\n", err)
+			} else {
+				bombEventBuffer = bombEventBuffer[:0]
+			}
+		}
+	}
+
+	// grenadePredictionBuffer holds one predicted detonation/landing point
+	// per projectile, computed the first time FrameDone observes it (see
+	// predictedProjectiles below) - paired against the actual grenade_events
+	// row recorded later for the same ProjectileID.
+	grenadePredictionBuffer := make([]db.GrenadePrediction, 0, 500)
+	const grenadePredictionBatchSize = 500
+	// predictedProjectiles tracks which ProjectileIDs already got a
+	// prediction computed, so a projectile tracked across many FrameDone
+	// calls only gets predicted once, at first observation.
+	predictedProjectiles := make(map[int]bool)
+	// grenadeActivityRegistered mirrors predictedProjectiles but gates
+	// afkExtractor.RegisterActivity(..., ActivityGrenadeThrown) instead, so
+	// a projectile tracked across many FrameDone calls only counts as one
+	// "sign of life" for its thrower, not one per tick it's in flight.
+	grenadeActivityRegistered := make(map[int]bool)
+
+	// playerInputBuffer holds reconstructed per-tick button state, behind
+	// --record-inputs/SetRecordInputs - see the FrameDone handler
+	// registered below the grenade-tracking one.
+	playerInputBuffer := make([]db.PlayerInput, 0, 5000)
+	const playerInputBatchSize = 5000
+	lastInputPos := make(map[uint64]r3.Vector)
+	lastInputTick := make(map[uint64]int)
+
+	// weaponSwitchBuffer holds one row per observed ActiveWeapon() change -
+	// see the dedicated FrameDone/WeaponFire handlers registered below the
+	// shot-tracking one.
+	weaponSwitchBuffer := make([]db.WeaponSwitch, 0, 500)
+	const weaponSwitchBatchSize = 500
+	// playerRoundWeaponStatsBuffer accumulates at most one row per
+	// player per round (low volume), flushed directly at each RoundEnd
+	// rather than batched across the whole match.
+	playerRoundWeaponStatsBuffer := make([]db.PlayerRoundWeaponStats, 0, 32)
+
+	// lastWeaponState tracks each player's most recently observed weapon
+	// name/magazine ammo, so the weapon-switch handler can tell a genuine
+	// ActiveWeapon() change from re-observing the same weapon on the next
+	// tick.
+	type weaponState struct {
+		name string
+		ammo int
+	}
+	lastWeaponState := make(map[uint64]weaponState)
+
+	// pendingWeaponSwitch holds, per player, the most recent switch that
+	// hasn't yet been resolved against a following WeaponFire - see
+	// maxDeployWindowTicks and the WeaponFire handler below.
+	type pendingSwitchState struct {
+		row       db.WeaponSwitch
+		equipTick int
+	}
+	pendingWeaponSwitch := make(map[uint64]*pendingSwitchState)
+
+	// roundWeaponAgg accumulates this round's weapon_switches per player,
+	// flushed into playerRoundWeaponStatsBuffer at RoundEnd then reset.
+	type weaponRoundAgg struct {
+		switches        int
+		deployTickSum   int
+		deployCount     int
+		switchesToEmpty int
+	}
+	roundWeaponAgg := make(map[uint64]*weaponRoundAgg)
+
+	// maxDeployWindowTicks bounds how long after a switch a WeaponFire may
+	// still count as "the first shot with the new weapon" - past this, the
+	// switch is persisted with a nil deploy latency instead of waiting
+	// indefinitely (e.g. the player never fires that weapon before dying
+	// or switching again).
+	const maxDeployWindowTicks = 5 * 64 // ~5s at the common 64-tick rate
+
+	// flushPendingWeaponSwitch moves steamID64's pending switch (if any)
+	// into weaponSwitchBuffer/roundWeaponAgg as unresolved (no deploy
+	// latency observed), called when it's superseded by a new switch, the
+	// round ends, or the window above elapses.
+	flushPendingWeaponSwitch := func(steamID64 uint64) {
+		pending, ok := pendingWeaponSwitch[steamID64]
+		if !ok {
+			return
+		}
+		delete(pendingWeaponSwitch, steamID64)
+		weaponSwitchBuffer = append(weaponSwitchBuffer, pending.row)
+		agg, ok := roundWeaponAgg[steamID64]
+		if !ok {
+			agg = &weaponRoundAgg{}
+			roundWeaponAgg[steamID64] = agg
+		}
+		agg.switches++
+		if pending.row.WasEmpty {
+			agg.switchesToEmpty++
+		}
+		if pending.row.DeployTicksUntilFirstFire != nil {
+			agg.deployTickSum += *pending.row.DeployTicksUntilFirstFire
+			agg.deployCount++
+		}
+
+		if len(weaponSwitchBuffer) >= weaponSwitchBatchSize {
+			if err := writer.InsertWeaponSwitches(ctx, weaponSwitchBuffer); err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Failed to insert weapon switches batch: %v\n", err)
+			} else {
+				weaponSwitchBuffer = weaponSwitchBuffer[:0]
+			}
+		}
+	}
+
 	shotBuffer := make([]db.Shot, 0, 5000) // Buffer up to 5000 shots
 	const shotBatchSize = 5000             // Flush every 5000 shots (was 1000)
 
@@ -276,6 +952,18 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 			positionBuffer = positionBuffer[:0]
 		}
+		if len(positionKeyframeBuffer) > 0 {
+			if err := writer.InsertPlayerPositionKeyframes(ctx, positionKeyframeBuffer); err != nil {
+				return fmt.Errorf("failed to flush position keyframe buffer: %w", err)
+			}
+			positionKeyframeBuffer = positionKeyframeBuffer[:0]
+		}
+		if len(positionDeltaBuffer) > 0 {
+			if err := writer.InsertPlayerPositionDeltas(ctx, positionDeltaBuffer); err != nil {
+				return fmt.Errorf("failed to flush position delta buffer: %w", err)
+			}
+			positionDeltaBuffer = positionDeltaBuffer[:0]
+		}
 
 		// Flush chat messages
 		if len(chatBuffer) > 0 {
@@ -285,6 +973,14 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			chatBuffer = chatBuffer[:0]
 		}
 
+		// Flush system chat events
+		if len(systemChatBuffer) > 0 {
+			if err := writer.InsertSystemChatEvents(ctx, systemChatBuffer); err != nil {
+				return fmt.Errorf("failed to flush system chat buffer: %w", err)
+			}
+			systemChatBuffer = systemChatBuffer[:0]
+		}
+
 		// Flush grenade positions
 		if len(grenadePositionBuffer) > 0 {
 			if err := writer.InsertGrenadePositions(ctx, grenadePositionBuffer); err != nil {
@@ -301,6 +997,30 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			grenadeEventBuffer = grenadeEventBuffer[:0]
 		}
 
+		// Flush bomb events
+		if len(bombEventBuffer) > 0 {
+			if err := writer.InsertBombEvents(ctx, bombEventBuffer); err != nil {
+				return fmt.Errorf("failed to flush bomb event buffer: %w", err)
+			}
+			bombEventBuffer = bombEventBuffer[:0]
+		}
+
+		// Flush grenade predictions
+		if len(grenadePredictionBuffer) > 0 {
+			if err := writer.InsertGrenadePredictions(ctx, grenadePredictionBuffer); err != nil {
+				return fmt.Errorf("failed to flush grenade prediction buffer: %w", err)
+			}
+			grenadePredictionBuffer = grenadePredictionBuffer[:0]
+		}
+
+		// Flush player inputs
+		if len(playerInputBuffer) > 0 {
+			if err := writer.InsertPlayerInputs(ctx, playerInputBuffer); err != nil {
+				return fmt.Errorf("failed to flush player input buffer: %w", err)
+			}
+			playerInputBuffer = playerInputBuffer[:0]
+		}
+
 		// Flush shots
 		if len(shotBuffer) > 0 {
 			if err := writer.InsertShots(ctx, shotBuffer); err != nil {
@@ -309,6 +1029,14 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			shotBuffer = shotBuffer[:0]
 		}
 
+		// Flush weapon switches
+		if len(weaponSwitchBuffer) > 0 {
+			if err := writer.InsertWeaponSwitches(ctx, weaponSwitchBuffer); err != nil {
+				return fmt.Errorf("failed to flush weapon switch buffer: %w", err)
+			}
+			weaponSwitchBuffer = weaponSwitchBuffer[:0]
+		}
+
 		// Force garbage collection after flushing buffers to free memory
 		runtime.GC()
 
@@ -316,7 +1044,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	}
 
 	// Helper function to flush events from extractors immediately (for JSON mode)
-	flushExtractorEvents := func(eventsFile *os.File, teamKill, kill, teamDamage, teamFlash, disconnect, afk, bodyBlock interface{}) {
+	flushExtractorEvents := func(eventsFile *os.File, teamKill, kill, teamDamage, teamFlash, disconnect, afk, bodyBlock, friendlyFire interface{}) {
 		var allEvents []extractors.Event
 
 		if teamKill != nil {
@@ -382,6 +1110,15 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				}
 			}
 		}
+		if friendlyFire != nil {
+			if e, ok := friendlyFire.(*extractors.FriendlyFireIncidentExtractor); ok {
+				events := e.GetEvents()
+				if len(events) > 0 {
+					allEvents = append(allEvents, events...)
+					e.ClearEvents()
+				}
+			}
+		}
 
 		// Write events to file as NDJSON
 		for _, event := range allEvents {
@@ -420,7 +1157,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	// Track map name from ServerInfo event (v5)
 	var mapName string
 	var serverName string
-	
+
 	// Try to read server name from demo file header (best effort)
 	// For CS2 (Source 2), this requires protobuf parsing which is complex
 	// For CS:GO (Source 1), we can read it directly from the header
@@ -429,19 +1166,27 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			// Ignore any panics from header reading
 			_ = recover()
 		}()
-		
-		f, err := os.Open(p.path)
+
+		if p.localPath == "" {
+			// Only a FileSource has a path we can independently re-open
+			// to peek at the header; other DemoSource kinds already have
+			// their one stream handed to demoinfocs, so this heuristic
+			// is best-effort and simply skipped for them.
+			return
+		}
+
+		f, err := os.Open(p.localPath)
 		if err != nil {
 			return
 		}
 		defer f.Close()
-		
+
 		// Read first 8 bytes to check filestamp
 		buf := make([]byte, 8)
 		if _, err := f.ReadAt(buf, 0); err != nil {
 			return
 		}
-		
+
 		filestamp := string(buf)
 		if filestamp == "HL2DEMO" {
 			// Source 1 demo (CS:GO) - server name is at offset 16, 260 bytes
@@ -460,10 +1205,10 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	getDemoSource := func(serverName, fileName string) string {
 		faceitRegex := `\d+_team[\da-z-]+-team[\da-z-]+_de_[\da-z]+\.dem`
 		matched, _ := regexp.MatchString(faceitRegex, fileName)
-		
+
 		serverLower := strings.ToLower(serverName)
 		fileLower := strings.ToLower(fileName)
-		
+
 		if strings.Contains(serverLower, "faceit") || strings.Contains(serverLower, "blast") || matched {
 			return "faceit"
 		}
@@ -518,7 +1263,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		if strings.Contains(serverLower, "esplay") {
 			return "esplay"
 		}
-		
+
 		// If server name is empty and file name doesn't match any pattern,
 		// check if it looks like a Valve matchmaking demo
 		// Valve demos often have specific patterns or are from Valve servers
@@ -529,14 +1274,14 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			if matched, _ := regexp.MatchString(valvePattern, fileName); matched {
 				return "valve"
 			}
-			
+
 			// If server name contains "valve" (case-insensitive check already done above)
 			// But also check for common Valve server indicators in file name
 			if strings.Contains(fileLower, "valve") || strings.Contains(fileLower, "matchmaking") {
 				return "valve"
 			}
 		}
-		
+
 		return "unknown"
 	}
 
@@ -547,13 +1292,13 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	var firstRoundProcessed bool             // Track if we've processed the first round
 	var tTeamAssignment string               // "A" or "B" - assigned to first T team seen
 	var ctTeamAssignment string              // "A" or "B" - assigned to first CT team seen
-	
+
 	// Track player connection/disconnection status
 	playerFirstConnectRound := make(map[uint64]int) // steamid -> round index when first connected
 	playerDisconnected := make(map[uint64]bool)     // steamid -> true if disconnected
 	playerDisconnectTick := make(map[uint64]int)    // steamid -> tick when disconnected
 	playerDisconnectRound := make(map[uint64]int)   // steamid -> round index when disconnected
-	
+
 	// Track round end ticks to filter out team kills near round end
 	roundEndTicks := make(map[int]int) // roundIndex -> round end tick
 
@@ -563,18 +1308,55 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	tickRate := 64.0 // Default fallback
 	teamKillExtractor := extractors.NewTeamKillExtractor()
 	killExtractor := extractors.NewKillExtractor()
-	teamDamageExtractor := extractors.NewTeamDamageExtractor(tickRate)
+	teamDamageExtractor := extractors.NewTeamDamageExtractor(tickRate, nil)
 	teamFlashExtractor := extractors.NewTeamFlashExtractor()
+	friendlyFireExtractor := extractors.NewFriendlyFireIncidentExtractor(tickRate, extractors.DefaultFriendlyFireMergeWindow, nil, nil)
+	flashExtractor := extractors.NewFlashExtractor()
 	disconnectExtractor := extractors.NewDisconnectExtractor()
-	afkExtractor := extractors.NewAFKExtractor(tickRate, dbConn)
+	useridResolver := userid.NewResolver()
+
+	// behaviorRegistry fans PlayerConnect/PlayerHurt/RoundEnd/Kill/
+	// PlayerFlashed out to disconnect/team_kill/team_flash, and is the
+	// extension point third-party analytics (utility-lineup detection,
+	// trade-kill windows, prefire spots, ...) register against via
+	// extractors.RegisterExtractor instead of patching this function.
+	// PlayerDisconnected stays a direct call below - HandlePlayerDisconnected
+	// returns reasonFamily/reasonText that subsequent code here still needs,
+	// and BehaviorExtractor.Handle has no way to surface them.
+	behaviorRegistry := extractors.NewExtractorRegistry()
+	behaviorRegistry.RegisterExtractor(extractors.NewDisconnectBehaviorAdapter(disconnectExtractor))
+	behaviorRegistry.RegisterExtractor(extractors.NewTeamKillBehaviorAdapter(teamKillExtractor))
+	behaviorRegistry.RegisterExtractor(extractors.NewTeamFlashBehaviorAdapter(teamFlashExtractor))
+	behaviorRegistry.ApplyConfig(extractors.ParseEnabledExtractors(p.enabledExtractors))
+	afkExtractor := extractors.NewAFKExtractor(tickRate, dbConn, matchID)
 	bodyBlockExtractor := extractors.NewBodyBlockExtractor(tickRate, dbConn)
 	economyExtractor := extractors.NewEconomyExtractor()
+	economyProfileExtractor := extractors.NewEconomyProfileExtractor()
+	griefingAggregator := extractors.NewGriefingAggregator()
+	chatVoteExtractor := extractors.NewChatVoteExtractor()
 
 	// Register handler for ServerInfo to get map name (v5)
 	// Based on: https://github.com/markus-wa/demoinfocs-golang/blob/master/examples/print-events/print_events.go
+	lastAnnouncedMap := ""
 	p.parser.RegisterNetMessageHandler(func(m *msg.CSVCMsg_ServerInfo) {
-		if m != nil {
-			mapName = m.GetMapName()
+		if m == nil {
+			return
+		}
+		mapName = m.GetMapName()
+		if mapName != "" && mapName != lastAnnouncedMap {
+			lastAnnouncedMap = mapName
+			roundIndex := -1
+			if currentRound != nil {
+				roundIndex = currentRound.RoundIndex
+			}
+			// getCurrentTick isn't declared until below this handler's
+			// registration, so read the tick directly here instead.
+			tick := 0
+			if gs := p.parser.GameState(); gs != nil {
+				tick = gs.IngameTick()
+			}
+			appendSystemChat(db.SystemChatKindMapChange, roundIndex, tick, nil, nil,
+				fmt.Sprintf("Map loaded: %s", mapName))
 		}
 	})
 
@@ -619,15 +1401,22 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 
 			// Finalize pending events for previous round
 			teamDamageExtractor.FinalizeRound(currentRound.RoundIndex)
-			teamFlashExtractor.FinalizeRound(currentRound.RoundIndex)
+			friendlyFireExtractor.FinalizeRound(currentRound.RoundIndex)
 			// AFK detection is now done from database after positions are written
-			disconnectExtractor.FinalizeRound(currentRound.RoundIndex)
+			behaviorRegistry.FinalizeAll(currentRound.RoundIndex)
+
+			if p.onRoundCheckpoint != nil {
+				p.checkpointRound = currentRound.RoundIndex
+				p.checkpointTick = tick
+				p.onRoundCheckpoint(currentRound.RoundIndex, tick)
+			}
 		}
 
 		// Start new round
 		roundNumber++
 		roundStartTick = tick
 		freezeEndTick = nil // Reset for new round
+		bombSite = nil      // Reset for new round - last round's plant site doesn't carry over
 		afkExtractor.HandleRoundStart(roundNumber-1, tick)
 
 		// Start new round
@@ -638,6 +1427,11 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			CTWins:     ctWins,
 		}
 
+		if p.onRoundLifecycle != nil {
+			p.onRoundLifecycle("OnRoundStart", currentRound.RoundIndex, roundStartTick, nil)
+		}
+		p.publishLifecycle("round_start", currentRound.RoundIndex, roundStartTick, nil, nil)
+
 		// Assign teams based on the first round only
 		// First T team seen = Team A, first CT team seen = Team B
 		// Players keep their team assignment for the whole game
@@ -651,12 +1445,12 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 						continue
 					}
 					steamID64 := p.SteamID64
-					
+
 					// Skip spectators
 					if p.Team == common.TeamSpectators || p.Team == common.TeamUnassigned {
 						continue
 					}
-					
+
 					var assignedTeam string
 					if p.Team == common.TeamTerrorists {
 						if tTeamAssignment == "" {
@@ -673,10 +1467,10 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 						}
 						assignedTeam = ctTeamAssignment
 					}
-					
+
 					if assignedTeam != "" {
 						playerTeamMap[steamID64] = assignedTeam
-						
+
 						// Update PlayerData with team assignment
 						var playerData *PlayerData
 						var needsUpdate bool
@@ -693,14 +1487,14 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 								name = fmt.Sprintf("Player_%d", steamID64)
 							}
 							playerData = &PlayerData{
-								SteamID: fmt.Sprintf("%d", steamID64),
+								SteamID: steamid.ID(steamID64).Primary(steamIDFormat),
 								Name:    name,
 								Team:    assignedTeam,
 							}
 							playerMap[steamID64] = playerData
 							needsUpdate = true
 						}
-						
+
 						// Insert or update player in database immediately
 						if writer != nil && matchID != "" && needsUpdate {
 							dbPlayer := db.Player{
@@ -772,16 +1566,26 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		freezeEndTick = &tick
 		if currentRound != nil {
 			currentRound.FreezeEndTick = freezeEndTick
+			p.publishLifecycle("freezetime_end", currentRound.RoundIndex, tick, nil, nil)
 			// Notify AFK extractor that freeze time has ended
 			afkExtractor.HandleFreezeTimeEnd(currentRound.RoundIndex, tick)
-			
+
 			// Analyze economy at freeze time end (after buy phase)
 			gs := p.parser.GameState()
 			if gs != nil {
 				participants := gs.Participants()
 				allPlayers := participants.All()
 				economyExtractor.HandleFreezeTimeEnd(currentRound.RoundIndex, tick, allPlayers)
-				
+
+				// Fold this round's snapshots into each player's running economic
+				// personality profile (aggregated across the whole demo in Finalize).
+				for _, teamSnapshots := range economyExtractor.LastSnapshots() {
+					teamCategory := extractors.TeamBuyCategory(teamSnapshots)
+					for _, snapshot := range teamSnapshots {
+						economyProfileExtractor.RecordRound(snapshot, teamCategory)
+					}
+				}
+
 				// Write economy events immediately to file/DB
 				economyEvents := economyExtractor.GetEvents()
 				if len(economyEvents) > 0 {
@@ -809,6 +1613,22 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		}
 	})
 
+	p.parser.RegisterEventHandler(func(e events.ItemDrop) {
+		roundIndex := 0
+		if currentRound != nil {
+			roundIndex = currentRound.RoundIndex
+		}
+		economyExtractor.HandleItemDrop(e, roundIndex)
+	})
+
+	p.parser.RegisterEventHandler(func(e events.ItemPickup) {
+		roundIndex := 0
+		if currentRound != nil {
+			roundIndex = currentRound.RoundIndex
+		}
+		economyExtractor.HandleItemPickup(e, roundIndex)
+	})
+
 	p.parser.RegisterEventHandler(func(e events.RoundEnd) {
 		if currentRound == nil {
 			return
@@ -817,7 +1637,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		updateTick()
 		tick := getCurrentTick()
 		currentRound.EndTick = tick
-		
+
 		// Flush position buffer at end of round if using incremental insertion
 		if writer != nil && matchID != "" && len(positionBuffer) > 0 {
 			if err := writer.InsertPlayerPositions(ctx, positionBuffer); err != nil {
@@ -826,19 +1646,102 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 			positionBuffer = positionBuffer[:0] // Clear buffer
 		}
+		if writer != nil && matchID != "" {
+			if len(positionKeyframeBuffer) > 0 {
+				if err := writer.InsertPlayerPositionKeyframes(ctx, positionKeyframeBuffer); err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: Failed to flush position keyframe buffer at round end: %v\n", err)
+				}
+				positionKeyframeBuffer = positionKeyframeBuffer[:0]
+			}
+			if len(positionDeltaBuffer) > 0 {
+				if err := writer.InsertPlayerPositionDeltas(ctx, positionDeltaBuffer); err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: Failed to flush position delta buffer at round end: %v\n", err)
+				}
+				positionDeltaBuffer = positionDeltaBuffer[:0]
+			}
+			// Each round's delta chain must start from its own keyframe -
+			// see positionDeltaState's doc comment.
+			positionDeltaStates = make(map[uint64]*positionDeltaState)
+		}
+
+		// Body-block/AFK detection from the database happens post-parse in
+		// main.go once positions are fully stored there. Without a writer
+		// (JSON/Parquet streaming), there's no database to re-query post-parse,
+		// so run the same detectors here instead, over this round's
+		// currentRoundPositions - phase 2 of the two-phase pipeline, just
+		// fed from memory rather than SQL.
+		if writer == nil {
+			if currentRound.FreezeEndTick != nil {
+				if err := afkExtractor.ProcessAFKFromPositions(matchID, currentRound.RoundIndex, *currentRound.FreezeEndTick, tick, currentRoundPositions); err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: AFK detection failed for round %d: %v\n", currentRound.RoundIndex, err)
+				}
+			}
+			bodyBlockExtractor.ProcessRoundFromPositions(currentRound.RoundIndex, currentRound.StartTick, tick, currentRoundPositions)
+			if callback != nil {
+				callback("detecting", tick, currentRound.RoundIndex, lastReportedPct)
+			}
+			currentRoundPositions = currentRoundPositions[:0]
+		}
+
+		// Resolve any weapon switches still awaiting a first-fire match
+		// (the player never fired that weapon before round end), then turn
+		// this round's per-player switch counts into player_round_weapon_stats
+		// rows and reset the accumulator for the next round.
+		if writer != nil && matchID != "" {
+			for steamID64 := range pendingWeaponSwitch {
+				flushPendingWeaponSwitch(steamID64)
+			}
+			for steamID64, agg := range roundWeaponAgg {
+				var avgDeploySeconds *float64
+				if agg.deployCount > 0 {
+					seconds := (float64(agg.deployTickSum) / float64(agg.deployCount)) / tickRate
+					avgDeploySeconds = &seconds
+				}
+				playerRoundWeaponStatsBuffer = append(playerRoundWeaponStatsBuffer, db.PlayerRoundWeaponStats{
+					MatchID:            matchID,
+					RoundIndex:         currentRound.RoundIndex,
+					SteamID:            steamid.ID(steamID64).Primary(steamIDFormat),
+					SwitchesPerRound:   agg.switches,
+					AvgDeploySeconds:   avgDeploySeconds,
+					SwitchesToEmptyGun: agg.switchesToEmpty,
+				})
+			}
+			roundWeaponAgg = make(map[uint64]*weaponRoundAgg)
 
-		// Note: Body blocking detection moved to post-parse step in main.go
-		// It needs all positions to be fully stored in the database first
+			if err := writer.InsertPlayerRoundWeaponStats(ctx, playerRoundWeaponStatsBuffer); err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Failed to insert player round weapon stats for round %d: %v\n", currentRound.RoundIndex, err)
+			}
+			playerRoundWeaponStatsBuffer = playerRoundWeaponStatsBuffer[:0]
+		}
 
 		// Notify disconnect extractor of round end (for filtering disconnects within 10s)
 		disconnectExtractor.SetLastRoundEndTick(tick)
-		
+
 		// Store round end tick for filtering team kills near round end
 		roundEndTicks[currentRound.RoundIndex] = tick
-		
+
+		// Update each team's consecutive-loss streak so next round's
+		// loss-bonus projection (used to judge save legitimacy) is accurate.
+		economyExtractor.HandleRoundEnd(e.Winner)
+
+		// Track the running score so a later disconnect can be judged
+		// against the margin at the moment it happened.
+		behaviorRegistry.Dispatch(extractors.BehaviorContext{RoundIndex: currentRound.RoundIndex, Tick: tick, TickRate: tickRate}, extractors.EventKindRoundEnd, e)
+
 		// Notify AFK extractor of round end (for filtering AFK periods that end at round end)
 		afkExtractor.HandleRoundEnd(currentRound.RoundIndex, tick)
 
+		// Feed this round's team-kill/team-flash/rage-disconnect events into
+		// the sliding-window griefing aggregator, then re-check thresholds
+		// every few rounds so PLAYER_GRIEFING_SCORE alerts stay timely
+		// without recomputing on every single round.
+		griefingAggregator.IngestSource("team_kill", teamKillExtractor.GetEvents())
+		griefingAggregator.IngestSource("team_flash", teamFlashExtractor.GetEvents())
+		griefingAggregator.IngestSource("disconnect", disconnectExtractor.GetEvents())
+		if currentRound.RoundIndex%extractors.GriefingEvalIntervalRounds == 0 {
+			griefingAggregator.Evaluate(currentRound.RoundIndex)
+		}
+
 		// Flush any remaining events at round end (should be minimal since we flush immediately)
 		// This is mainly for AFK events which are processed at round end
 		if eventsFile != nil {
@@ -886,6 +1789,21 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		currentRound.TWins = tWins
 		currentRound.CTWins = ctWins
 
+		if p.onRoundLifecycle != nil {
+			p.onRoundLifecycle("OnRoundEnd", currentRound.RoundIndex, tick, winner)
+		}
+		p.publishLifecycle("round_end", currentRound.RoundIndex, tick, winner, map[string]interface{}{
+			"t_wins":  tWins,
+			"ct_wins": ctWins,
+		})
+
+		winnerText := "no winner"
+		if winner != nil {
+			winnerText = *winner + " won"
+		}
+		appendSystemChat(db.SystemChatKindRoundResult, currentRound.RoundIndex, tick, nil, nil,
+			fmt.Sprintf("Round %d ended: %s (T %d - %d CT)", currentRound.RoundIndex, winnerText, tWins, ctWins))
+
 		if tick > maxTick {
 			maxTick = tick
 			// Gradually refine the estimate as we see more ticks
@@ -943,7 +1861,9 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		updateTick()
 		tick := getCurrentTick()
 
-		steamID := fmt.Sprintf("%d", player.SteamID64)
+		steamID := steamid.ID(player.SteamID64).Primary(steamIDFormat)
+
+		useridResolver.Observe(player.UserID, player.SteamID64)
 
 		// Filter by Steam ID set if provided - skip players not in the set
 		if steamIDSet != nil && !steamIDSet[steamID] {
@@ -951,7 +1871,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		}
 
 		// Check if this is a reconnection (only for filtered players)
-		disconnectExtractor.HandlePlayerConnect(e, roundIndex, tick, tickRate)
+		behaviorRegistry.Dispatch(extractors.BehaviorContext{RoundIndex: roundIndex, Tick: tick, TickRate: tickRate}, extractors.EventKindPlayerConnect, e)
 
 		var playerData *PlayerData
 		var isNewPlayer bool
@@ -972,7 +1892,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			playerData = playerMap[player.SteamID64]
 			isNewPlayer = false
 		}
-		
+
 		// Mark as reconnected if they were disconnected
 		playerDisconnected[player.SteamID64] = false
 
@@ -988,7 +1908,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			// Player connected mid-game (or during first round but after team assignments are set)
 			// Find a connected player on the same in-game team and use their Team assignment
 			var assignedTeam string
-			
+
 			// Look for a connected player on the same team to determine their Team (A or B)
 			gs := p.parser.GameState()
 			if gs != nil {
@@ -1013,7 +1933,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 					}
 				}
 			}
-			
+
 			// Fallback: if no connected player found on same team, use the stored team assignments
 			if assignedTeam == "" {
 				if player.Team == common.TeamTerrorists {
@@ -1029,7 +1949,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 					}
 					assignedTeam = ctTeamAssignment
 				}
-				
+
 				if assignedTeam != "" {
 					playerTeamMap[player.SteamID64] = assignedTeam
 					playerData.Team = assignedTeam
@@ -1056,7 +1976,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				}
 				assignedTeam = ctTeamAssignment
 			}
-			
+
 			if assignedTeam != "" {
 				playerTeamMap[player.SteamID64] = assignedTeam
 				playerData.Team = assignedTeam
@@ -1083,18 +2003,17 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 		}
 
-		// Add server announcement for player joining
-		// roundIndex and tick are already declared above
-		
+		// Add a system chat entry for the player joining. InsertPlayer above
+		// has already run for isNewPlayer, so the FK to players(match_id,
+		// steamid) this join message carries is satisfied by the time it's
+		// flushed - the "can cause foreign key constraint issues" problem
+		// this used to dodge by just dropping the announcement.
 		playerName := player.Name
 		if playerName == "" {
 			playerName = fmt.Sprintf("Player_%d", player.SteamID64)
 		}
-		
-		// Skip server announcements - they can cause foreign key constraint issues
-		// if the player hasn't been properly inserted into the players table yet.
-		// Real chat messages are captured separately via events.ChatMessage
-		_ = playerName // Suppress unused variable warning
+
+		appendSystemChat(db.SystemChatKindConnect, roundIndex, tick, &steamID, &playerName, fmt.Sprintf("%s connected", playerName))
 	})
 
 	// Update player names when they change
@@ -1104,10 +2023,24 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			return
 		}
 
-		steamID := fmt.Sprintf("%d", player.SteamID64)
+		steamID := steamid.ID(player.SteamID64).Primary(steamIDFormat)
+		if steamIDSet != nil && !steamIDSet[steamID] {
+			return
+		}
+
 		if p, exists := playerMap[player.SteamID64]; exists {
-			if player.Name != "" {
+			if player.Name != "" && player.Name != p.Name {
+				oldName := p.Name
 				p.Name = player.Name
+
+				roundIndex := -1
+				if currentRound != nil {
+					roundIndex = currentRound.RoundIndex
+				}
+				updateTick()
+				tick := getCurrentTick()
+				appendSystemChat(db.SystemChatKindNameChange, roundIndex, tick, &steamID, &player.Name,
+					fmt.Sprintf("%s is now known as %s", oldName, player.Name))
 			}
 		} else {
 			// Player not yet in map, add them
@@ -1133,10 +2066,10 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			attackerSteamID := ""
 			victimSteamID := ""
 			if e.Killer != nil {
-				attackerSteamID = fmt.Sprintf("%d", e.Killer.SteamID64)
+				attackerSteamID = steamid.ID(e.Killer.SteamID64).Primary(steamIDFormat)
 			}
 			if e.Victim != nil {
-				victimSteamID = fmt.Sprintf("%d", e.Victim.SteamID64)
+				victimSteamID = steamid.ID(e.Victim.SteamID64).Primary(steamIDFormat)
 			}
 			// Skip if neither attacker nor victim is in the set
 			if (attackerSteamID == "" || !steamIDSet[attackerSteamID]) &&
@@ -1145,9 +2078,9 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 		}
 
-			updateTick()
-			tick := getCurrentTick()
-		
+		updateTick()
+		tick := getCurrentTick()
+
 		// Helper function to check if a player was disconnected at a given tick
 		// This checks if the player disconnected before or at the check tick and hasn't reconnected
 		isPlayerDisconnectedAtTick := func(steamID string, checkTick int) bool {
@@ -1160,11 +2093,11 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			if disconnected, exists := playerDisconnected[steamID64]; !exists || !disconnected {
 				return false // Player is not disconnected (or never was)
 			}
-			
+
 			// Player is marked as disconnected - check if they disconnected before or at the check tick
 			disconnectTick := playerDisconnectTick[steamID64]
 			disconnectRound := playerDisconnectRound[steamID64]
-			
+
 			// If disconnected in the same round and before or at the check tick, they were disconnected
 			if disconnectRound == currentRound.RoundIndex && disconnectTick <= checkTick {
 				return true
@@ -1173,10 +2106,10 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			if disconnectRound < currentRound.RoundIndex {
 				return true
 			}
-			
+
 			return false
 		}
-		
+
 		// Helper function to check if a kill happened near the end of a round
 		// Exclude kills within 10 seconds of round end (similar to disconnect filtering)
 		isNearRoundEnd := func(checkRoundIndex int, checkTick int) bool {
@@ -1186,43 +2119,265 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				// We can't know the exact end tick yet, so we'll only filter after round end is known
 				return false
 			}
-			
+
 			// Check if kill happened within 10 seconds before round end
 			ticksBeforeRoundEnd := roundEndTick - checkTick
 			if ticksBeforeRoundEnd < 0 {
 				// Kill happened after round end (shouldn't happen, but handle gracefully)
 				return false
 			}
-			
+
 			secondsBeforeRoundEnd := float64(ticksBeforeRoundEnd) / tickRate
 			// Exclude kills within 10 seconds of round end
 			return secondsBeforeRoundEnd < 10.0
 		}
-		
-		teamKillExtractor.HandlePlayerDeath(e, currentRound.RoundIndex, tick, isPlayerDisconnectedAtTick, isNearRoundEnd)
-			killExtractor.HandlePlayerDeath(e, currentRound.RoundIndex, tick)
+
+		behaviorRegistry.Dispatch(extractors.BehaviorContext{
+			RoundIndex:           currentRound.RoundIndex,
+			Tick:                 tick,
+			TickRate:             tickRate,
+			IsVictimDisconnected: isPlayerDisconnectedAtTick,
+			IsNearRoundEnd:       isNearRoundEnd,
+		}, extractors.EventKindKill, e)
+		killExtractor.HandlePlayerDeath(e, currentRound.RoundIndex, tick)
+		friendlyFireExtractor.HandleKill(e, currentRound.RoundIndex, tick)
+
+		// A dead player can neither land nor receive any more team-damage/flash
+		// hits, so close out any of their accumulator entries still open
+		// rather than leaving them to merge with a future round's hits.
+		if e.Victim != nil {
+			victimSteamID := steamid.ID(e.Victim.SteamID64).Primary(steamIDFormat)
+			teamDamageExtractor.FlushParticipant(victimSteamID)
+			teamFlashExtractor.FlushParticipant(victimSteamID)
+			friendlyFireExtractor.FlushParticipant(victimSteamID)
+		}
+		if e.Killer != nil {
+			killerSteamID := steamid.ID(e.Killer.SteamID64).Primary(steamIDFormat)
+			teamDamageExtractor.FlushParticipant(killerSteamID)
+			teamFlashExtractor.FlushParticipant(killerSteamID)
+			friendlyFireExtractor.FlushParticipant(killerSteamID)
+		}
 
 		// In JSON mode, flush events immediately to avoid accumulation
 		if eventsFile != nil {
-			flushExtractorEvents(eventsFile, teamKillExtractor, killExtractor, nil, nil, nil, nil, bodyBlockExtractor)
+			flushExtractorEvents(eventsFile, teamKillExtractor, killExtractor, teamDamageExtractor, teamFlashExtractor, nil, nil, bodyBlockExtractor, friendlyFireExtractor)
 		}
 		// AFK tracking is now done from database after positions are written
+
+		if p.onPlayerDeath != nil {
+			p.onPlayerDeath(e, currentRound.RoundIndex, tick)
+		}
 	})
 
-	p.parser.RegisterEventHandler(func(e events.PlayerHurt) {
-		if currentRound == nil {
+	p.parser.RegisterEventHandler(func(e events.BombPickup) {
+		if currentRound == nil || e.Player == nil {
+			return
+		}
+		steamID := steamid.ID(e.Player.SteamID64).Primary(steamIDFormat)
+		if steamIDSet != nil && !steamIDSet[steamID] {
 			return
 		}
+		updateTick()
+		tick := getCurrentTick()
 
-		// Early filter: only process events involving players in Steam ID set
-		if steamIDSet != nil {
-			attackerSteamID := ""
-			victimSteamID := ""
-			if e.Attacker != nil {
-				attackerSteamID = fmt.Sprintf("%d", e.Attacker.SteamID64)
-			}
+		x, y, z := bombPosition()
+		appendBombEvent(db.BombEvent{
+			MatchID:        matchID,
+			RoundIndex:     currentRound.RoundIndex,
+			Tick:           tick,
+			EventType:      "pickup",
+			CarrierSteamID: &steamID,
+			X:              x,
+			Y:              y,
+			Z:              z,
+			Site:           bombSite,
+		})
+	})
+
+	p.parser.RegisterEventHandler(func(e events.BombDropped) {
+		if currentRound == nil || e.Player == nil {
+			return
+		}
+		steamID := steamid.ID(e.Player.SteamID64).Primary(steamIDFormat)
+		if steamIDSet != nil && !steamIDSet[steamID] {
+			return
+		}
+		updateTick()
+		tick := getCurrentTick()
+
+		x, y, z := bombPosition()
+		appendBombEvent(db.BombEvent{
+			MatchID:        matchID,
+			RoundIndex:     currentRound.RoundIndex,
+			Tick:           tick,
+			EventType:      "dropped",
+			CarrierSteamID: &steamID,
+			X:              x,
+			Y:              y,
+			Z:              z,
+			Site:           bombSite,
+		})
+	})
+
+	p.parser.RegisterEventHandler(func(e events.BombPlantBegin) {
+		if currentRound == nil || e.Player == nil {
+			return
+		}
+		steamID := steamid.ID(e.Player.SteamID64).Primary(steamIDFormat)
+		if steamIDSet != nil && !steamIDSet[steamID] {
+			return
+		}
+		updateTick()
+		tick := getCurrentTick()
+
+		x, y, z := bombPosition()
+		appendBombEvent(db.BombEvent{
+			MatchID:        matchID,
+			RoundIndex:     currentRound.RoundIndex,
+			Tick:           tick,
+			EventType:      "plant_begin",
+			CarrierSteamID: &steamID,
+			X:              x,
+			Y:              y,
+			Z:              z,
+			Site:           siteStr(e.Site),
+		})
+	})
+
+	p.parser.RegisterEventHandler(func(e events.BombPlanted) {
+		if currentRound == nil {
+			return
+		}
+		updateTick()
+		tick := getCurrentTick()
+
+		if p.onBombPlanted != nil {
+			p.onBombPlanted(e, currentRound.RoundIndex, tick)
+		}
+
+		site := siteStr(e.Site)
+		bombSite = site
+
+		if e.Player != nil {
+			steamID := steamid.ID(e.Player.SteamID64).Primary(steamIDFormat)
+			if steamIDSet == nil || steamIDSet[steamID] {
+				afkExtractor.RegisterActivity(steamID, tick, extractors.ActivityBombPlant)
+				x, y, z := bombPosition()
+				appendBombEvent(db.BombEvent{
+					MatchID:        matchID,
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "planted",
+					CarrierSteamID: &steamID,
+					X:              x,
+					Y:              y,
+					Z:              z,
+					Site:           site,
+				})
+			}
+		}
+	})
+
+	p.parser.RegisterEventHandler(func(e events.BombDefuseStart) {
+		if currentRound == nil || e.Player == nil {
+			return
+		}
+		steamID := steamid.ID(e.Player.SteamID64).Primary(steamIDFormat)
+		if steamIDSet != nil && !steamIDSet[steamID] {
+			return
+		}
+		updateTick()
+		tick := getCurrentTick()
+
+		x, y, z := bombPosition()
+		appendBombEvent(db.BombEvent{
+			MatchID:        matchID,
+			RoundIndex:     currentRound.RoundIndex,
+			Tick:           tick,
+			EventType:      "defuse_start",
+			DefuserSteamID: &steamID,
+			X:              x,
+			Y:              y,
+			Z:              z,
+			Site:           bombSite,
+			HasKit:         e.HasKit,
+		})
+	})
+
+	p.parser.RegisterEventHandler(func(e events.BombDefused) {
+		if currentRound == nil {
+			return
+		}
+		updateTick()
+		tick := getCurrentTick()
+
+		site := siteStr(e.Site)
+		bombSite = site
+
+		var defuserSteamID *string
+		if e.Player != nil {
+			steamID := steamid.ID(e.Player.SteamID64).Primary(steamIDFormat)
+			if steamIDSet != nil && !steamIDSet[steamID] {
+				return
+			}
+			defuserSteamID = &steamID
+			afkExtractor.RegisterActivity(steamID, tick, extractors.ActivityBombDefuse)
+		}
+
+		x, y, z := bombPosition()
+		appendBombEvent(db.BombEvent{
+			MatchID:        matchID,
+			RoundIndex:     currentRound.RoundIndex,
+			Tick:           tick,
+			EventType:      "defused",
+			DefuserSteamID: defuserSteamID,
+			X:              x,
+			Y:              y,
+			Z:              z,
+			Site:           site,
+		})
+	})
+
+	// BombExplode has no single relevant player to filter by steamIDSet
+	// (Player can be nil even outside POV demos by the time it detonates),
+	// so unlike the other lifecycle events this one always records.
+	p.parser.RegisterEventHandler(func(e events.BombExplode) {
+		if currentRound == nil {
+			return
+		}
+		updateTick()
+		tick := getCurrentTick()
+
+		site := siteStr(e.Site)
+		bombSite = site
+
+		x, y, z := bombPosition()
+		appendBombEvent(db.BombEvent{
+			MatchID:    matchID,
+			RoundIndex: currentRound.RoundIndex,
+			Tick:       tick,
+			EventType:  "explode",
+			X:          x,
+			Y:          y,
+			Z:          z,
+			Site:       site,
+		})
+	})
+
+	p.parser.RegisterEventHandler(func(e events.PlayerHurt) {
+		if currentRound == nil {
+			return
+		}
+
+		// Early filter: only process events involving players in Steam ID set
+		if steamIDSet != nil {
+			attackerSteamID := ""
+			victimSteamID := ""
+			if e.Attacker != nil {
+				attackerSteamID = steamid.ID(e.Attacker.SteamID64).Primary(steamIDFormat)
+			}
 			if e.Player != nil {
-				victimSteamID = fmt.Sprintf("%d", e.Player.SteamID64)
+				victimSteamID = steamid.ID(e.Player.SteamID64).Primary(steamIDFormat)
 			}
 			// Skip if neither attacker nor victim is in the set
 			if (attackerSteamID == "" || !steamIDSet[attackerSteamID]) &&
@@ -1231,13 +2386,21 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 		}
 
-			updateTick()
-			tick := getCurrentTick()
-			teamDamageExtractor.HandlePlayerHurt(e, currentRound.RoundIndex, tick)
+		updateTick()
+		tick := getCurrentTick()
+		if e.Attacker != nil {
+			afkExtractor.RegisterActivity(steamid.ID(e.Attacker.SteamID64).Primary(steamIDFormat), tick, extractors.ActivityDamageDealt)
+		}
+		if e.Player != nil {
+			afkExtractor.RegisterActivity(steamid.ID(e.Player.SteamID64).Primary(steamIDFormat), tick, extractors.ActivityDamageReceived)
+		}
+		teamDamageExtractor.HandlePlayerHurt(e, currentRound.RoundIndex, tick)
+		behaviorRegistry.Dispatch(extractors.BehaviorContext{RoundIndex: currentRound.RoundIndex, Tick: tick, TickRate: tickRate}, extractors.EventKindPlayerHurt, e)
+		friendlyFireExtractor.HandlePlayerHurt(e, currentRound.RoundIndex, tick)
 
 		// In JSON mode, flush events immediately to avoid accumulation
 		if eventsFile != nil {
-			flushExtractorEvents(eventsFile, nil, nil, teamDamageExtractor, nil, nil, nil, bodyBlockExtractor)
+			flushExtractorEvents(eventsFile, nil, nil, teamDamageExtractor, nil, nil, nil, bodyBlockExtractor, friendlyFireExtractor)
 		}
 		// AFK tracking is now done from database after positions are written
 	})
@@ -1252,10 +2415,10 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			attackerSteamID := ""
 			victimSteamID := ""
 			if e.Attacker != nil {
-				attackerSteamID = fmt.Sprintf("%d", e.Attacker.SteamID64)
+				attackerSteamID = steamid.ID(e.Attacker.SteamID64).Primary(steamIDFormat)
 			}
 			if e.Player != nil {
-				victimSteamID = fmt.Sprintf("%d", e.Player.SteamID64)
+				victimSteamID = steamid.ID(e.Player.SteamID64).Primary(steamIDFormat)
 			}
 			// Skip if neither attacker nor victim is in the set
 			if (attackerSteamID == "" || !steamIDSet[attackerSteamID]) &&
@@ -1264,13 +2427,15 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 		}
 
-			updateTick()
-			tick := getCurrentTick()
-			teamFlashExtractor.HandlePlayerFlashed(e, currentRound.RoundIndex, tick)
+		updateTick()
+		tick := getCurrentTick()
+		behaviorRegistry.Dispatch(extractors.BehaviorContext{RoundIndex: currentRound.RoundIndex, Tick: tick, TickRate: tickRate}, extractors.EventKindPlayerFlashed, e)
+		flashExtractor.HandlePlayerFlashed(e, currentRound.RoundIndex, tick)
+		friendlyFireExtractor.HandlePlayerFlashed(e, currentRound.RoundIndex, tick)
 
 		// In JSON mode, flush events immediately to avoid accumulation
 		if eventsFile != nil {
-			flushExtractorEvents(eventsFile, nil, nil, nil, teamFlashExtractor, nil, nil, bodyBlockExtractor)
+			flushExtractorEvents(eventsFile, nil, nil, nil, teamFlashExtractor, nil, nil, bodyBlockExtractor, friendlyFireExtractor)
 		}
 		// AFK tracking is now done from database after positions are written
 	})
@@ -1289,7 +2454,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 
 		// Early filter: only process disconnects for players in Steam ID set
 		if steamIDSet != nil {
-			steamID := fmt.Sprintf("%d", player.SteamID64)
+			steamID := steamid.ID(player.SteamID64).Primary(steamIDFormat)
 			if !steamIDSet[steamID] {
 				return
 			}
@@ -1297,14 +2462,18 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 
 		updateTick()
 		tick := getCurrentTick()
-		
-		disconnectExtractor.HandlePlayerDisconnected(e, roundIndex, tick, tickRate)
+
+		// Called directly rather than through behaviorRegistry.Dispatch:
+		// reasonFamily/reasonText are consumed below for system-chat
+		// bookkeeping, and BehaviorExtractor.Handle's []Event return can't
+		// carry them.
+		reasonFamily, reasonText := disconnectExtractor.HandlePlayerDisconnected(e, roundIndex, tick, tickRate)
 
 		// In JSON mode, flush events immediately to avoid accumulation
 		if eventsFile != nil {
-			flushExtractorEvents(eventsFile, nil, nil, nil, nil, disconnectExtractor, nil, bodyBlockExtractor)
+			flushExtractorEvents(eventsFile, nil, nil, nil, nil, disconnectExtractor, nil, bodyBlockExtractor, nil)
 		}
-		
+
 		// Mark player as disconnected and record the tick and round
 		steamID64 := player.SteamID64
 		playerDisconnected[steamID64] = true
@@ -1312,15 +2481,26 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		playerDisconnectRound[steamID64] = roundIndex
 		// Clear first connect round since they disconnected
 		delete(playerFirstConnectRound, steamID64)
-		
+
 		playerName := player.Name
 		if playerName == "" {
 			playerName = fmt.Sprintf("Player_%d", steamID64)
 		}
-		
-		// Skip server announcements - they can cause foreign key constraint issues
-		// if the player hasn't been properly inserted into the players table yet.
-		_ = playerName // Suppress unused variable warning
+
+		// There's no dedicated Kick/Ban demoinfocs event to hook - CS2 only
+		// ever fires PlayerDisconnected, with the kick/ban distinction buried
+		// in the numeric reason code DisconnectExtractor already decodes for
+		// ClassifyDisconnects. Reuse that same classification here instead of
+		// introducing a second reason-code table.
+		kind := db.SystemChatKindDisconnect
+		switch {
+		case strings.Contains(strings.ToLower(reasonText), "ban"):
+			kind = db.SystemChatKindBan
+		case reasonFamily == "kick":
+			kind = db.SystemChatKindKick
+		}
+		steamIDStr := steamid.ID(steamID64).Primary(steamIDFormat)
+		appendSystemChat(kind, roundIndex, tick, &steamIDStr, &playerName, fmt.Sprintf("%s disconnected (%s)", playerName, reasonText))
 	})
 
 	// Handle chat messages using SayText2 event
@@ -1356,7 +2536,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		// - Server messages may have different MsgName values
 		isTeamChat := false
 		msgNameLower := strings.ToLower(e.MsgName)
-		
+
 		// Only treat as team chat if MsgName explicitly contains "team"
 		// This matches CS Demo Analyzer's approach
 		// Note: We capture ALL chat messages (both team and all chat) - the IsTeamChat flag
@@ -1364,7 +2544,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		if strings.Contains(msgNameLower, "team") {
 			isTeamChat = true
 		}
-		
+
 		// Debug logging (uncomment to debug chat extraction)
 		// fmt.Printf("[Chat] MsgName: %s, Player: %s, Message: %s, IsTeamChat: %v\n", e.MsgName, playerName, messageText, isTeamChat)
 
@@ -1384,7 +2564,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			for _, p := range participants.All() {
 				if p != nil && p.Entity != nil && p.Entity.ID() == e.EntIdx {
 					player = p
-					steamID = fmt.Sprintf("%d", p.SteamID64)
+					steamID = steamid.ID(p.SteamID64).Primary(steamIDFormat)
 					// Update playerName from actual player object (more reliable)
 					if p.Name != "" {
 						playerName = p.Name
@@ -1400,7 +2580,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			for _, p := range participants.All() {
 				if p != nil && strings.EqualFold(p.Name, playerName) {
 					player = p
-					steamID = fmt.Sprintf("%d", p.SteamID64)
+					steamID = steamid.ID(p.SteamID64).Primary(steamIDFormat)
 					break
 				}
 			}
@@ -1440,7 +2620,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				steamID = playerName
 			}
 		} else {
-			steamID = fmt.Sprintf("%d", player.SteamID64)
+			steamID = steamid.ID(player.SteamID64).Primary(steamIDFormat)
 		}
 
 		// Get team assignment (A or B) - use original team from first round
@@ -1511,6 +2691,8 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			roundIndex = currentRound.RoundIndex
 		}
 
+		chatVoteExtractor.HandleChatMessage(steamID, messageText, roundIndex, tick, isTeamChat)
+
 		// Filter by Steam ID set if provided - skip chat messages from players not in the set
 		if steamIDSet != nil && steamID != "" && !steamIDSet[steamID] {
 			return
@@ -1548,15 +2730,15 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		} else if data.ChatMessages != nil {
 			// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
 			// In JSON mode and DB streaming mode, data.ChatMessages is nil, so this never executes
-		data.ChatMessages = append(data.ChatMessages, ChatMessageData{
-			RoundIndex: roundIndex,
-			Tick:       tick,
-			SteamID:    steamID,
-			Name:       playerName,
-			Team:       team,
-			Message:    messageText,
-			IsTeamChat: isTeamChat,
-		})
+			data.ChatMessages = append(data.ChatMessages, ChatMessageData{
+				RoundIndex: roundIndex,
+				Tick:       tick,
+				SteamID:    steamID,
+				Name:       playerName,
+				Team:       team,
+				Message:    messageText,
+				IsTeamChat: isTeamChat,
+			})
 		}
 	})
 
@@ -1589,8 +2771,17 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			return
 		}
 
-		// Track positions at intervals (for database storage)
-		if tick-lastPositionTick < positionInterval {
+		// Track positions at intervals (for database storage). Under
+		// heap back-pressure, the sampler divisor widens this interval
+		// (e.g. 4x -> 16x) so a long demo doesn't keep accumulating
+		// positions as fast as the buffers can be flushed.
+		effectiveInterval := positionInterval
+		if p.backpressure != nil {
+			if divisor := p.backpressure(); divisor > 1 {
+				effectiveInterval *= divisor
+			}
+		}
+		if tick-lastPositionTick < effectiveInterval {
 			return
 		}
 		lastPositionTick = tick
@@ -1607,7 +2798,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				continue
 			}
 
-			steamID := fmt.Sprintf("%d", player.SteamID64)
+			steamID := steamid.ID(player.SteamID64).Primary(steamIDFormat)
 
 			// Skip position tracking if AFK detection is complete for this player in this round
 			// Once a player moves or dies (ending their AFK period), we don't need more positions
@@ -1668,11 +2859,11 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			} else {
 				playerData = existingPlayer
 			}
-			
+
 			// Assign team if not already assigned and first round is processed
 			if playerData.Team == "" && firstRoundProcessed {
 				var assignedTeam string
-				
+
 				// Look for a connected player on the same in-game team and use their Team assignment
 				participants := p.parser.GameState().Participants()
 				for _, otherPlayer := range participants.All() {
@@ -1689,7 +2880,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 						}
 					}
 				}
-				
+
 				// Fallback: if no connected player found on same team, use the stored team assignments
 				if assignedTeam == "" {
 					if player.Team == common.TeamTerrorists {
@@ -1706,7 +2897,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 						assignedTeam = ctTeamAssignment
 					}
 				}
-				
+
 				if assignedTeam != "" {
 					playerTeamMap[player.SteamID64] = assignedTeam
 					playerData.Team = assignedTeam
@@ -1719,7 +2910,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 					needsInsert = true
 				}
 			}
-			
+
 			// Insert player into database if needed
 			if needsInsert && writer != nil && matchID != "" {
 				dbPlayer := db.Player{
@@ -1793,7 +2984,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			if yaw != 0 {
 				yawPtr = &yaw
 			}
-			
+
 			posData := db.PlayerPosition{
 				MatchID:    matchID,
 				RoundIndex: currentRound.RoundIndex,
@@ -1808,22 +2999,30 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				Armor:      armor,
 				Weapon:     weapon,
 			}
-			
+
 			// If writer is provided, buffer for incremental insertion
 			if writer != nil && matchID != "" {
-				positionBuffer = append(positionBuffer, posData)
-				
-				// Flush buffer when it reaches batch size
-				if len(positionBuffer) >= positionBatchSize {
-					if err := writer.InsertPlayerPositions(ctx, positionBuffer); err != nil {
-						// Log error but continue parsing - we'll retry at round end
-						fmt.Fprintf(os.Stderr, "WARN: Failed to insert player positions batch: %v\n", err)
+				if p.positionEncoding == "delta" {
+					appendPositionSample(player.SteamID64, posData)
+				} else {
+					positionBuffer = append(positionBuffer, posData)
+
+					// Flush buffer when it reaches batch size
+					if len(positionBuffer) >= positionBatchSize {
+						if err := writer.InsertPlayerPositions(ctx, positionBuffer); err != nil {
+							// Log error but continue parsing - we'll retry at round end
+							fmt.Fprintf(os.Stderr, "WARN: Failed to insert player positions batch: %v\n", err)
+						}
+						positionBuffer = positionBuffer[:0] // Clear buffer
 					}
-					positionBuffer = positionBuffer[:0] // Clear buffer
 				}
+			} else {
+				// No writer: keep this round's positions in memory just long
+				// enough for the round-end AFK/body-block pass below (see
+				// currentRoundPositions). Still far less RAM than buffering
+				// the whole match, since it's cleared every round.
+				currentRoundPositions = append(currentRoundPositions, posData)
 			}
-			// Note: Positions are only streamed to database, never stored in memory
-			// This significantly reduces RAM usage during parsing
 		}
 
 		// Track all active grenade projectiles
@@ -1868,7 +3067,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			var throwerTeam *string
 			thrower := grenade.Thrower
 			if thrower != nil {
-				steamID := fmt.Sprintf("%d", thrower.SteamID64)
+				steamID := steamid.ID(thrower.SteamID64).Primary(steamIDFormat)
 				throwerSteamID = &steamID
 				name := thrower.Name
 				throwerName = &name
@@ -1881,6 +3080,43 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				continue
 			}
 
+			if throwerSteamID != nil && !grenadeActivityRegistered[projectileID] {
+				grenadeActivityRegistered[projectileID] = true
+				afkExtractor.RegisterActivity(*throwerSteamID, tick, extractors.ActivityGrenadeThrown)
+			}
+
+			// Predict this projectile's detonation/landing point the first
+			// time it's observed, from its position and velocity at that
+			// moment - see internal/parser/ballistics for the physics and
+			// its documented simplifications (no real per-map geometry).
+			if writer != nil && matchID != "" && !predictedProjectiles[projectileID] {
+				predictedProjectiles[projectileID] = true
+				vel := grenade.Velocity()
+				if pred, ok := ballistics.Predict(normalizedName, tickRate,
+					[3]float64{float64(pos.X), float64(pos.Y), float64(pos.Z)},
+					[3]float64{float64(vel.X), float64(vel.Y), float64(vel.Z)}); ok {
+					grenadePredictionBuffer = append(grenadePredictionBuffer, db.GrenadePrediction{
+						MatchID:       matchID,
+						RoundIndex:    currentRound.RoundIndex,
+						Tick:          tick,
+						ProjectileID:  uint64(projectileID),
+						GrenadeName:   normalizedName,
+						PredictedX:    pred.X,
+						PredictedY:    pred.Y,
+						PredictedZ:    pred.Z,
+						PredictedTick: tick + pred.TickOffset,
+						EffectRadius:  pred.EffectRadius,
+					})
+					if len(grenadePredictionBuffer) >= grenadePredictionBatchSize {
+						if err := writer.InsertGrenadePredictions(ctx, grenadePredictionBuffer); err != nil {
+							fmt.Fprintf(os.Stderr, "WARN: Failed to insert grenade predictions batch: %v\n", err)
+						} else {
+							grenadePredictionBuffer = grenadePredictionBuffer[:0]
+						}
+					}
+				}
+			}
+
 			// Stream grenade position to database if writer is available
 			if writer != nil && matchID != "" {
 				grenadePositionBuffer = append(grenadePositionBuffer, db.GrenadePosition{
@@ -1908,67 +3144,11 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			} else if data.GrenadePositions != nil {
 				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
 				// In JSON mode and DB streaming mode, data.GrenadePositions is nil, so this never executes
-			data.GrenadePositions = append(data.GrenadePositions, GrenadePositionData{
-				RoundIndex:     currentRound.RoundIndex,
-				Tick:           tick,
-				ProjectileID:   uint64(projectileID),
-				GrenadeName:    normalizedName,
-				X:              float64(pos.X),
-				Y:              float64(pos.Y),
-				Z:              float64(pos.Z),
-				ThrowerSteamID: throwerSteamID,
-				ThrowerName:    throwerName,
-				ThrowerTeam:    throwerTeam,
-			})
-			}
-		}
-	})
-
-	// Track grenade events (skip in JSON mode - not needed for output)
-	// Grenade positions and events are not needed for JSON output (only TEAM_KILL, TEAM_DAMAGE, etc.)
-	if eventsFile == nil {
-	// Smoke grenade detonation (smoke starts)
-	p.parser.RegisterEventHandler(func(e events.GrenadeProjectileDestroy) {
-		// Check if it's a smoke grenade
-		if e.Projectile == nil {
-			return
-		}
-		weaponInstance := e.Projectile.WeaponInstance
-		if weaponInstance == nil {
-			return
-		}
-		grenadeName := strings.ToLower(weaponInstance.Type.String())
-		if !strings.Contains(grenadeName, "smoke") && !strings.Contains(grenadeName, "smokegrenade") {
-			return
-		}
-		if currentRound == nil {
-			return
-		}
-		updateTick()
-		tick := getCurrentTick()
-
-		pos := e.Projectile.Position()
-		var throwerSteamID *string
-		var throwerName *string
-		var throwerTeam *string
-		if e.Projectile.Thrower != nil {
-			steamID := fmt.Sprintf("%d", e.Projectile.Thrower.SteamID64)
-			throwerSteamID = &steamID
-			name := e.Projectile.Thrower.Name
-			throwerName = &name
-			team := getTeamString(e.Projectile.Thrower.Team)
-			throwerTeam = &team
-		}
-
-			// Stream grenade event to database if writer is available
-			if writer != nil && matchID != "" {
-				grenadeEventBuffer = append(grenadeEventBuffer, db.GrenadeEvent{
-					MatchID:        matchID,
+				data.GrenadePositions = append(data.GrenadePositions, GrenadePositionData{
 					RoundIndex:     currentRound.RoundIndex,
 					Tick:           tick,
-					EventType:      "smoke_start",
-					ProjectileID:   uint64(e.Projectile.UniqueID()),
-					GrenadeName:    "smokegrenade",
+					ProjectileID:   uint64(projectileID),
+					GrenadeName:    normalizedName,
 					X:              float64(pos.X),
 					Y:              float64(pos.Y),
 					Z:              float64(pos.Z),
@@ -1976,136 +3156,442 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 					ThrowerName:    throwerName,
 					ThrowerTeam:    throwerTeam,
 				})
-
-				// Flush if buffer is full
-				if len(grenadeEventBuffer) >= grenadeEventBatchSize {
-					if err := writer.InsertGrenadeEvents(ctx, grenadeEventBuffer); err != nil {
-						fmt.Fprintf(os.Stderr, "WARN: Failed to insert grenade events batch: %v\n", err)
-					} else {
-						grenadeEventBuffer = grenadeEventBuffer[:0]
-					}
-				}
-			} else if data.GrenadeEvents != nil {
-				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
-				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
-		data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
-			RoundIndex:     currentRound.RoundIndex,
-			Tick:           tick,
-			EventType:      "smoke_start",
-			ProjectileID:   uint64(e.Projectile.UniqueID()),
-			GrenadeName:    "smokegrenade",
-			X:              float64(pos.X),
-			Y:              float64(pos.Y),
-			Z:              float64(pos.Z),
-			ThrowerSteamID: throwerSteamID,
-			ThrowerName:    throwerName,
-			ThrowerTeam:    throwerTeam,
-		})
 			}
-	})
-
-	// HE grenade explosion
-	p.parser.RegisterEventHandler(func(e events.GrenadeProjectileDestroy) {
-		// Check if it's an HE grenade
-		if e.Projectile == nil {
-			return
-		}
-		weaponInstance := e.Projectile.WeaponInstance
-		if weaponInstance == nil {
-			return
 		}
-		grenadeName := strings.ToLower(weaponInstance.Type.String())
-		if !strings.Contains(grenadeName, "he") && !strings.Contains(grenadeName, "hegrenade") {
-			return
-		}
-		if currentRound == nil {
-			return
-		}
-		updateTick()
-		tick := getCurrentTick()
+	})
 
-		pos := e.Projectile.Position()
-		var throwerSteamID *string
-		var throwerName *string
-		var throwerTeam *string
-		if e.Projectile.Thrower != nil {
-			steamID := fmt.Sprintf("%d", e.Projectile.Thrower.SteamID64)
-			throwerSteamID = &steamID
-			name := e.Projectile.Thrower.Name
-			throwerName = &name
-			team := getTeamString(e.Projectile.Thrower.Team)
-			throwerTeam = &team
-		}
+	// Detect weapon switches: fires whenever a player's ActiveWeapon()
+	// differs from what was last observed for them. Registered as its own
+	// FrameDone handler (not folded into the position-sampling one above)
+	// because that handler is subsampled by positionInterval/backpressure,
+	// and a quick-swap can happen and reverse within a subsampled gap.
+	if eventsFile == nil {
+		p.parser.RegisterEventHandler(func(e events.FrameDone) {
+			if currentRound == nil || writer == nil || matchID == "" {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
 
-			// Filter by Steam ID set if provided - skip grenade events from players not in the set
-			if steamIDSet != nil && (throwerSteamID == nil || !steamIDSet[*throwerSteamID]) {
+			gs := p.parser.GameState()
+			if gs == nil {
 				return
 			}
 
-			// Stream grenade event to database if writer is available
-			if writer != nil && matchID != "" {
-				grenadeEventBuffer = append(grenadeEventBuffer, db.GrenadeEvent{
-					MatchID:        matchID,
-					RoundIndex:     currentRound.RoundIndex,
-					Tick:           tick,
-					EventType:      "he_explode",
-					ProjectileID:   uint64(e.Projectile.UniqueID()),
-					GrenadeName:    "hegrenade",
-					X:              float64(pos.X),
-					Y:              float64(pos.Y),
-					Z:              float64(pos.Z),
-					ThrowerSteamID: throwerSteamID,
-					ThrowerName:    throwerName,
-					ThrowerTeam:    throwerTeam,
-				})
+			for _, player := range gs.Participants().All() {
+				if player == nil || player.Team == common.TeamSpectators || player.Team == common.TeamUnassigned {
+					continue
+				}
+				steamID := steamid.ID(player.SteamID64).Primary(steamIDFormat)
+				if steamIDSet != nil && !steamIDSet[steamID] {
+					continue
+				}
 
-				// Flush if buffer is full
-				if len(grenadeEventBuffer) >= grenadeEventBatchSize {
-					if err := writer.InsertGrenadeEvents(ctx, grenadeEventBuffer); err != nil {
-						fmt.Fprintf(os.Stderr, "WARN: Failed to insert grenade events batch: %v\n", err)
-					} else {
-						grenadeEventBuffer = grenadeEventBuffer[:0]
-					}
+				weapon := player.ActiveWeapon()
+				if weapon == nil {
+					continue
 				}
-			} else if data.GrenadeEvents != nil {
-				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
-				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
-		data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
-			RoundIndex:     currentRound.RoundIndex,
-			Tick:           tick,
-			EventType:      "he_explode",
-			ProjectileID:   uint64(e.Projectile.UniqueID()),
-			GrenadeName:    "hegrenade",
-			X:              float64(pos.X),
-			Y:              float64(pos.Y),
-			Z:              float64(pos.Z),
-			ThrowerSteamID: throwerSteamID,
-			ThrowerName:    throwerName,
-			ThrowerTeam:    throwerTeam,
-		})
-			}
-	})
+				newName := weapon.Type.String()
+				newAmmo := weapon.AmmoInMagazine()
 
-	// Flashbang explosion
-	p.parser.RegisterEventHandler(func(e events.FlashExplode) {
-		if currentRound == nil {
-			return
-		}
-		updateTick()
-		tick := getCurrentTick()
+				prev, hadPrev := lastWeaponState[player.SteamID64]
+				lastWeaponState[player.SteamID64] = weaponState{name: newName, ammo: newAmmo}
+				if hadPrev && prev.name == newName {
+					continue
+				}
 
-		pos := e.Position
-		var throwerSteamID *string
-		var throwerName *string
-		var throwerTeam *string
-		if e.Thrower != nil {
-			steamID := fmt.Sprintf("%d", e.Thrower.SteamID64)
-			throwerSteamID = &steamID
-			name := e.Thrower.Name
-			throwerName = &name
-			team := getTeamString(e.Thrower.Team)
-			throwerTeam = &team
-		}
+				// A new switch supersedes any still-pending one for this
+				// player (e.g. they swapped again before firing).
+				flushPendingWeaponSwitch(player.SteamID64)
+
+				var prevWeapon *string
+				var prevAmmo *int
+				if hadPrev {
+					name := prev.name
+					prevWeapon = &name
+					ammo := prev.ammo
+					prevAmmo = &ammo
+				}
+				ammo := newAmmo
+				row := db.WeaponSwitch{
+					MatchID:    matchID,
+					RoundIndex: currentRound.RoundIndex,
+					Tick:       tick,
+					SteamID:    steamID,
+					PrevWeapon: prevWeapon,
+					NewWeapon:  newName,
+					PrevAmmo:   prevAmmo,
+					NewAmmo:    &ammo,
+					WasEmpty:   newAmmo == 0,
+				}
+				pendingWeaponSwitch[player.SteamID64] = &pendingSwitchState{row: row, equipTick: tick}
+			}
+		})
+
+		// Pair each weapon switch with the next WeaponFire from the same
+		// player within maxDeployWindowTicks, to measure deploy latency
+		// (panic-switches show up as a long gap here).
+		p.parser.RegisterEventHandler(func(e events.WeaponFire) {
+			if currentRound == nil || writer == nil || matchID == "" || e.Shooter == nil {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
+
+			pending, ok := pendingWeaponSwitch[e.Shooter.SteamID64]
+			if !ok {
+				return
+			}
+			if tick-pending.equipTick > maxDeployWindowTicks {
+				flushPendingWeaponSwitch(e.Shooter.SteamID64)
+				return
+			}
+			deployTicks := tick - pending.equipTick
+			pending.row.DeployTicksUntilFirstFire = &deployTicks
+			flushPendingWeaponSwitch(e.Shooter.SteamID64)
+		})
+	}
+
+	// Sample the bomb's position at the same cadence as player positions,
+	// so it can still be located on the map while dropped or planted and
+	// not otherwise generating a pickup/drop/plant/defuse event of its own.
+	if eventsFile == nil {
+		p.parser.RegisterEventHandler(func(e events.FrameDone) {
+			if currentRound == nil || writer == nil || matchID == "" {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
+
+			if tick-lastBombPositionTick < positionInterval {
+				return
+			}
+			lastBombPositionTick = tick
+
+			gs := p.parser.GameState()
+			if gs == nil {
+				return
+			}
+			bomb := gs.Bomb()
+			if bomb == nil {
+				return
+			}
+			pos := bomb.Position()
+
+			var carrierSteamID *string
+			if bomb.Carrier != nil {
+				steamID := steamid.ID(bomb.Carrier.SteamID64).Primary(steamIDFormat)
+				if steamIDSet == nil || steamIDSet[steamID] {
+					carrierSteamID = &steamID
+				}
+			}
+
+			appendBombEvent(db.BombEvent{
+				MatchID:        matchID,
+				RoundIndex:     currentRound.RoundIndex,
+				Tick:           tick,
+				EventType:      "position_sample",
+				CarrierSteamID: carrierSteamID,
+				X:              pos.X,
+				Y:              pos.Y,
+				Z:              pos.Z,
+				Site:           bombSite,
+			})
+		})
+	}
+
+	// Reconstruct per-tick player button state, behind --record-inputs.
+	// Unlike the position-sampling FrameDone handler above, this one isn't
+	// gated by positionInterval/freezeEndTick - the request this exists for
+	// wants every tick, and it's off by default so demos that don't need it
+	// don't pay the extra row volume.
+	if p.recordInputs {
+		p.parser.RegisterEventHandler(func(e events.FrameDone) {
+			if currentRound == nil || eventsFile != nil || writer == nil || matchID == "" {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
+
+			gs := p.parser.GameState()
+			if gs == nil {
+				return
+			}
+
+			for _, player := range gs.Participants().All() {
+				if player == nil || player.Team == common.TeamSpectators || player.Team == common.TeamUnassigned {
+					continue
+				}
+				steamID := steamid.ID(player.SteamID64).Primary(steamIDFormat)
+				if steamIDSet != nil && !steamIDSet[steamID] {
+					continue
+				}
+
+				pos := player.Position()
+
+				// forwardmove/sidemove: the XY velocity estimated from the
+				// delta against this player's last recorded position,
+				// projected onto their current view basis - demoinfocs
+				// doesn't expose CS2's own analog move values, only the
+				// position those values produced.
+				var forwardMove, sideMove float64
+				if lastTick, ok := lastInputTick[player.SteamID64]; ok && tick > lastTick {
+					dt := float64(tick-lastTick) / tickRate
+					if dt > 0 {
+						last := lastInputPos[player.SteamID64]
+						velX := (pos.X - last.X) / dt
+						velY := (pos.Y - last.Y) / dt
+
+						forwardX, forwardY := float64(player.ViewDirectionX()), float64(player.ViewDirectionY())
+						if norm := math.Hypot(forwardX, forwardY); norm > 0 {
+							forwardX /= norm
+							forwardY /= norm
+						}
+						// Right vector is the forward vector rotated -90 degrees.
+						rightX, rightY := forwardY, -forwardX
+
+						forwardMove = float64(velX)*forwardX + float64(velY)*forwardY
+						sideMove = float64(velX)*rightX + float64(velY)*rightY
+					}
+				}
+				lastInputPos[player.SteamID64] = pos
+				lastInputTick[player.SteamID64] = tick
+
+				var buttons int
+				if player.IsPressingButton(common.ButtonForward) {
+					buttons |= db.InputButtonForward
+				}
+				if player.IsPressingButton(common.ButtonBack) {
+					buttons |= db.InputButtonBack
+				}
+				if player.IsPressingButton(common.ButtonMoveLeft) {
+					buttons |= db.InputButtonLeft
+				}
+				if player.IsPressingButton(common.ButtonMoveRight) {
+					buttons |= db.InputButtonRight
+				}
+				if player.IsPressingButton(common.ButtonJump) {
+					buttons |= db.InputButtonJump
+				}
+				if player.IsPressingButton(common.ButtonDuck) {
+					buttons |= db.InputButtonDuck
+				}
+				if player.IsPressingButton(common.ButtonAttack) {
+					buttons |= db.InputButtonAttack
+				}
+				if player.IsPressingButton(common.ButtonReload) {
+					buttons |= db.InputButtonReload
+				}
+				if player.IsPressingButton(common.ButtonUse) {
+					buttons |= db.InputButtonUse
+				}
+				if player.IsPressingButton(common.ButtonSpeed) {
+					buttons |= db.InputButtonWalk
+				}
+
+				playerInputBuffer = append(playerInputBuffer, db.PlayerInput{
+					MatchID:     matchID,
+					RoundIndex:  currentRound.RoundIndex,
+					Tick:        tick,
+					SteamID:     steamID,
+					Buttons:     buttons,
+					ForwardMove: forwardMove,
+					SideMove:    sideMove,
+				})
+
+				if len(playerInputBuffer) >= playerInputBatchSize {
+					if err := writer.InsertPlayerInputs(ctx, playerInputBuffer); err != nil {
+						fmt.Fprintf(os.Stderr, "WARN: Failed to insert player inputs batch: %v\n", err)
+					} else {
+						playerInputBuffer = playerInputBuffer[:0]
+					}
+				}
+			}
+		})
+	}
+
+	// Track grenade events (skip in JSON mode - not needed for output)
+	// Grenade positions and events are not needed for JSON output (only TEAM_KILL, TEAM_DAMAGE, etc.)
+	if eventsFile == nil {
+		// Smoke grenade detonation (smoke starts)
+		p.parser.RegisterEventHandler(func(e events.GrenadeProjectileDestroy) {
+			// Check if it's a smoke grenade
+			if e.Projectile == nil {
+				return
+			}
+			weaponInstance := e.Projectile.WeaponInstance
+			if weaponInstance == nil {
+				return
+			}
+			grenadeName := strings.ToLower(weaponInstance.Type.String())
+			if !strings.Contains(grenadeName, "smoke") && !strings.Contains(grenadeName, "smokegrenade") {
+				return
+			}
+			if currentRound == nil {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
+
+			pos := e.Projectile.Position()
+			var throwerSteamID *string
+			var throwerName *string
+			var throwerTeam *string
+			if e.Projectile.Thrower != nil {
+				steamID := steamid.ID(e.Projectile.Thrower.SteamID64).Primary(steamIDFormat)
+				throwerSteamID = &steamID
+				name := e.Projectile.Thrower.Name
+				throwerName = &name
+				team := getTeamString(e.Projectile.Thrower.Team)
+				throwerTeam = &team
+			}
+
+			// Stream grenade event to database if writer is available
+			if writer != nil && matchID != "" {
+				grenadeEventBuffer = append(grenadeEventBuffer, db.GrenadeEvent{
+					MatchID:        matchID,
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "smoke_start",
+					ProjectileID:   uint64(e.Projectile.UniqueID()),
+					GrenadeName:    "smokegrenade",
+					X:              float64(pos.X),
+					Y:              float64(pos.Y),
+					Z:              float64(pos.Z),
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+				})
+
+				// Flush if buffer is full
+				if len(grenadeEventBuffer) >= grenadeEventBatchSize {
+					if err := writer.InsertGrenadeEvents(ctx, grenadeEventBuffer); err != nil {
+						fmt.Fprintf(os.Stderr, "WARN: Failed to insert grenade events batch: %v\n", err)
+					} else {
+						grenadeEventBuffer = grenadeEventBuffer[:0]
+					}
+				}
+			} else if data.GrenadeEvents != nil {
+				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
+				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
+				data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "smoke_start",
+					ProjectileID:   uint64(e.Projectile.UniqueID()),
+					GrenadeName:    "smokegrenade",
+					X:              float64(pos.X),
+					Y:              float64(pos.Y),
+					Z:              float64(pos.Z),
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+				})
+			}
+		})
+
+		// HE grenade explosion
+		p.parser.RegisterEventHandler(func(e events.GrenadeProjectileDestroy) {
+			// Check if it's an HE grenade
+			if e.Projectile == nil {
+				return
+			}
+			weaponInstance := e.Projectile.WeaponInstance
+			if weaponInstance == nil {
+				return
+			}
+			grenadeName := strings.ToLower(weaponInstance.Type.String())
+			if !strings.Contains(grenadeName, "he") && !strings.Contains(grenadeName, "hegrenade") {
+				return
+			}
+			if currentRound == nil {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
+
+			pos := e.Projectile.Position()
+			var throwerSteamID *string
+			var throwerName *string
+			var throwerTeam *string
+			if e.Projectile.Thrower != nil {
+				steamID := steamid.ID(e.Projectile.Thrower.SteamID64).Primary(steamIDFormat)
+				throwerSteamID = &steamID
+				name := e.Projectile.Thrower.Name
+				throwerName = &name
+				team := getTeamString(e.Projectile.Thrower.Team)
+				throwerTeam = &team
+			}
+
+			// Filter by Steam ID set if provided - skip grenade events from players not in the set
+			if steamIDSet != nil && (throwerSteamID == nil || !steamIDSet[*throwerSteamID]) {
+				return
+			}
+
+			// Stream grenade event to database if writer is available
+			if writer != nil && matchID != "" {
+				grenadeEventBuffer = append(grenadeEventBuffer, db.GrenadeEvent{
+					MatchID:        matchID,
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "he_explode",
+					ProjectileID:   uint64(e.Projectile.UniqueID()),
+					GrenadeName:    "hegrenade",
+					X:              float64(pos.X),
+					Y:              float64(pos.Y),
+					Z:              float64(pos.Z),
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+				})
+
+				// Flush if buffer is full
+				if len(grenadeEventBuffer) >= grenadeEventBatchSize {
+					if err := writer.InsertGrenadeEvents(ctx, grenadeEventBuffer); err != nil {
+						fmt.Fprintf(os.Stderr, "WARN: Failed to insert grenade events batch: %v\n", err)
+					} else {
+						grenadeEventBuffer = grenadeEventBuffer[:0]
+					}
+				}
+			} else if data.GrenadeEvents != nil {
+				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
+				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
+				data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "he_explode",
+					ProjectileID:   uint64(e.Projectile.UniqueID()),
+					GrenadeName:    "hegrenade",
+					X:              float64(pos.X),
+					Y:              float64(pos.Y),
+					Z:              float64(pos.Z),
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+				})
+			}
+		})
+
+		// Flashbang explosion
+		p.parser.RegisterEventHandler(func(e events.FlashExplode) {
+			if currentRound == nil {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
+
+			pos := e.Position
+			var throwerSteamID *string
+			var throwerName *string
+			var throwerTeam *string
+			if e.Thrower != nil {
+				steamID := steamid.ID(e.Thrower.SteamID64).Primary(steamIDFormat)
+				throwerSteamID = &steamID
+				name := e.Thrower.Name
+				throwerName = &name
+				team := getTeamString(e.Thrower.Team)
+				throwerTeam = &team
+			}
 
 			// Filter by Steam ID set if provided - skip grenade events from players not in the set
 			if steamIDSet != nil && (throwerSteamID == nil || !steamIDSet[*throwerSteamID]) {
@@ -2140,42 +3626,42 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			} else if data.GrenadeEvents != nil {
 				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
 				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
-		data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
-			RoundIndex:     currentRound.RoundIndex,
-			Tick:           tick,
-			EventType:      "flash_explode",
-			ProjectileID:   0, // FlashExplode doesn't have ProjectileID, use 0
-			GrenadeName:    "flashbang",
-			X:              float64(pos.X),
-			Y:              float64(pos.Y),
-			Z:              float64(pos.Z),
-			ThrowerSteamID: throwerSteamID,
-			ThrowerName:    throwerName,
-			ThrowerTeam:    throwerTeam,
-		})
+				data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "flash_explode",
+					ProjectileID:   0, // FlashExplode doesn't have ProjectileID, use 0
+					GrenadeName:    "flashbang",
+					X:              float64(pos.X),
+					Y:              float64(pos.Y),
+					Z:              float64(pos.Z),
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+				})
 			}
-	})
+		})
 
-	// Decoy start
-	p.parser.RegisterEventHandler(func(e events.DecoyStart) {
-		if currentRound == nil {
-			return
-		}
-		updateTick()
-		tick := getCurrentTick()
+		// Decoy start
+		p.parser.RegisterEventHandler(func(e events.DecoyStart) {
+			if currentRound == nil {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
 
-		pos := e.Position
-		var throwerSteamID *string
-		var throwerName *string
-		var throwerTeam *string
-		if e.Thrower != nil {
-			steamID := fmt.Sprintf("%d", e.Thrower.SteamID64)
-			throwerSteamID = &steamID
-			name := e.Thrower.Name
-			throwerName = &name
-			team := getTeamString(e.Thrower.Team)
-			throwerTeam = &team
-		}
+			pos := e.Position
+			var throwerSteamID *string
+			var throwerName *string
+			var throwerTeam *string
+			if e.Thrower != nil {
+				steamID := steamid.ID(e.Thrower.SteamID64).Primary(steamIDFormat)
+				throwerSteamID = &steamID
+				name := e.Thrower.Name
+				throwerName = &name
+				team := getTeamString(e.Thrower.Team)
+				throwerTeam = &team
+			}
 
 			// Stream grenade event to database if writer is available
 			if writer != nil && matchID != "" {
@@ -2205,76 +3691,162 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			} else if data.GrenadeEvents != nil {
 				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
 				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
-		data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
-			RoundIndex:     currentRound.RoundIndex,
-			Tick:           tick,
-			EventType:      "decoy_start",
-			ProjectileID:   0, // DecoyStart doesn't have ProjectileID, use 0
-			GrenadeName:    "decoy",
-			X:              float64(pos.X),
-			Y:              float64(pos.Y),
-			Z:              float64(pos.Z),
-			ThrowerSteamID: throwerSteamID,
-			ThrowerName:    throwerName,
-			ThrowerTeam:    throwerTeam,
+				data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "decoy_start",
+					ProjectileID:   0, // DecoyStart doesn't have ProjectileID, use 0
+					GrenadeName:    "decoy",
+					X:              float64(pos.X),
+					Y:              float64(pos.Y),
+					Z:              float64(pos.Z),
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+				})
+			}
 		})
+
+		// Inferno (molotov/incendiary) start
+		p.parser.RegisterEventHandler(func(e events.InfernoStart) {
+			if currentRound == nil {
+				return
 			}
-	})
+			updateTick()
+			tick := getCurrentTick()
 
-	// Inferno (molotov/incendiary) start
-	p.parser.RegisterEventHandler(func(e events.InfernoStart) {
-		if currentRound == nil {
-			return
-		}
-		updateTick()
-		tick := getCurrentTick()
+			// Inferno.Fires() has nothing to report yet at InfernoStart (the
+			// entity was just created), so this row's centroid is the origin
+			// at the time of throw - infernoCentroidAndFires falls back to
+			// (0,0,0)/nil for an empty fire list, same as before this request.
+			var x, y, z float64
+			var firesJSON *string
+			var throwerSteamID *string
+			var throwerName *string
+			var throwerTeam *string
+			if e.Inferno != nil {
+				x, y, z, firesJSON = infernoCentroidAndFires(e.Inferno)
+				// Thrower is a method that returns the player who threw it
+				if e.Inferno.Thrower() != nil {
+					thrower := e.Inferno.Thrower()
+					steamID := steamid.ID(thrower.SteamID64).Primary(steamIDFormat)
+					throwerSteamID = &steamID
+					name := thrower.Name
+					throwerName = &name
+					team := getTeamString(thrower.Team)
+					throwerTeam = &team
+				}
+			}
 
-		// InfernoStart has Entity field
-		// Inferno doesn't have Position() method - we'll track position from grenade projectile instead
-		// For now, use zero position and rely on grenade positions for actual location
-		var pos r3.Vector
-		var throwerSteamID *string
-		var throwerName *string
-		var throwerTeam *string
-		if e.Inferno != nil {
-			// Inferno doesn't expose Position() directly
-			// We'll use (0,0,0) as placeholder - actual position tracked via grenade positions
-			pos = r3.Vector{X: 0, Y: 0, Z: 0}
-			// Thrower is a method that returns the player who threw it
-			if e.Inferno.Thrower() != nil {
-				thrower := e.Inferno.Thrower()
-				steamID := fmt.Sprintf("%d", thrower.SteamID64)
-				throwerSteamID = &steamID
-				name := thrower.Name
-				throwerName = &name
-				team := getTeamString(thrower.Team)
-				throwerTeam = &team
+			// Filter by Steam ID set if provided - skip grenade events from players not in the set
+			if steamIDSet != nil && (throwerSteamID == nil || !steamIDSet[*throwerSteamID]) {
+				return
+			}
+
+			// Stream grenade event to database if writer is available
+			if writer != nil && matchID != "" {
+				grenadeEventBuffer = append(grenadeEventBuffer, db.GrenadeEvent{
+					MatchID:        matchID,
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "inferno_start",
+					ProjectileID:   0, // InfernoStart doesn't have ProjectileID
+					GrenadeName:    "incendiary",
+					X:              x,
+					Y:              y,
+					Z:              z,
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+					FiresJSON:      firesJSON,
+				})
+
+				// Flush if buffer is full
+				if len(grenadeEventBuffer) >= grenadeEventBatchSize {
+					if err := writer.InsertGrenadeEvents(ctx, grenadeEventBuffer); err != nil {
+						fmt.Fprintf(os.Stderr, "WARN: Failed to insert grenade events batch: %v\n", err)
+					} else {
+						grenadeEventBuffer = grenadeEventBuffer[:0]
+					}
+				}
+			} else if data.GrenadeEvents != nil {
+				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
+				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
+				data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "inferno_start",
+					ProjectileID:   0, // InfernoStart doesn't have ProjectileID
+					GrenadeName:    "incendiary",
+					X:              x,
+					Y:              y,
+					Z:              z,
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+					FiresJSON:      firesJSON,
+				})
+			}
+		})
+
+		// Periodically sample each active inferno's flames, throttled to the
+		// same positionInterval cadence as player/bomb position sampling, so
+		// viewers can animate flame spread between inferno_start and
+		// inferno_expire instead of only seeing the two endpoints.
+		p.parser.RegisterEventHandler(func(e events.FrameDone) {
+			if currentRound == nil || writer == nil || matchID == "" {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
+
+			if tick-lastInfernoUpdateTick < positionInterval {
+				return
 			}
-		}
+			lastInfernoUpdateTick = tick
 
-			// Filter by Steam ID set if provided - skip grenade events from players not in the set
-			if steamIDSet != nil && (throwerSteamID == nil || !steamIDSet[*throwerSteamID]) {
+			gs := p.parser.GameState()
+			if gs == nil {
 				return
 			}
+			for _, inf := range gs.Infernos() {
+				if inf == nil {
+					continue
+				}
+				var throwerSteamID *string
+				var throwerName *string
+				var throwerTeam *string
+				if thrower := inf.Thrower(); thrower != nil {
+					steamID := steamid.ID(thrower.SteamID64).Primary(steamIDFormat)
+					if steamIDSet != nil && !steamIDSet[steamID] {
+						continue
+					}
+					throwerSteamID = &steamID
+					name := thrower.Name
+					throwerName = &name
+					team := getTeamString(thrower.Team)
+					throwerTeam = &team
+				} else if steamIDSet != nil {
+					continue
+				}
 
-			// Stream grenade event to database if writer is available
-			if writer != nil && matchID != "" {
+				x, y, z, firesJSON := infernoCentroidAndFires(inf)
 				grenadeEventBuffer = append(grenadeEventBuffer, db.GrenadeEvent{
 					MatchID:        matchID,
 					RoundIndex:     currentRound.RoundIndex,
 					Tick:           tick,
-					EventType:      "inferno_start",
-					ProjectileID:   0, // InfernoStart doesn't have ProjectileID
+					EventType:      "inferno_update",
+					ProjectileID:   uint64(inf.UniqueID()),
 					GrenadeName:    "incendiary",
-					X:              float64(pos.X),
-					Y:              float64(pos.Y),
-					Z:              float64(pos.Z),
+					X:              x,
+					Y:              y,
+					Z:              z,
 					ThrowerSteamID: throwerSteamID,
 					ThrowerName:    throwerName,
 					ThrowerTeam:    throwerTeam,
+					FiresJSON:      firesJSON,
 				})
 
-				// Flush if buffer is full
 				if len(grenadeEventBuffer) >= grenadeEventBatchSize {
 					if err := writer.InsertGrenadeEvents(ctx, grenadeEventBuffer); err != nil {
 						fmt.Fprintf(os.Stderr, "WARN: Failed to insert grenade events batch: %v\n", err)
@@ -2282,54 +3854,35 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 						grenadeEventBuffer = grenadeEventBuffer[:0]
 					}
 				}
-			} else if data.GrenadeEvents != nil {
-				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
-				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
-		data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
-			RoundIndex:     currentRound.RoundIndex,
-			Tick:           tick,
-			EventType:      "inferno_start",
-			ProjectileID:   0, // InfernoStart doesn't have ProjectileID
-			GrenadeName:    "incendiary",
-			X:              float64(pos.X),
-			Y:              float64(pos.Y),
-			Z:              float64(pos.Z),
-			ThrowerSteamID: throwerSteamID,
-			ThrowerName:    throwerName,
-			ThrowerTeam:    throwerTeam,
-		})
 			}
-	})
+		})
 
-	// Inferno expire
-	p.parser.RegisterEventHandler(func(e events.InfernoExpired) {
-		if currentRound == nil {
-			return
-		}
-		updateTick()
-		tick := getCurrentTick()
+		// Inferno expire
+		p.parser.RegisterEventHandler(func(e events.InfernoExpired) {
+			if currentRound == nil {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
 
-		// InfernoExpired has Entity field
-		// Get position from the inferno entity - Inferno doesn't have Position() method
-		var pos r3.Vector
-		var throwerSteamID *string
-		var throwerName *string
-		var throwerTeam *string
-		if e.Inferno != nil {
-			// Inferno doesn't expose Position() directly
-			// For now, we'll set to zero and track via grenade positions instead
-			pos = r3.Vector{X: 0, Y: 0, Z: 0}
-			// Thrower is a method that returns the player who threw it
-			if e.Inferno.Thrower() != nil {
-				thrower := e.Inferno.Thrower()
-				steamID := fmt.Sprintf("%d", thrower.SteamID64)
-				throwerSteamID = &steamID
-				name := thrower.Name
-				throwerName = &name
-				team := getTeamString(thrower.Team)
-				throwerTeam = &team
+			var x, y, z float64
+			var firesJSON *string
+			var throwerSteamID *string
+			var throwerName *string
+			var throwerTeam *string
+			if e.Inferno != nil {
+				x, y, z, firesJSON = infernoCentroidAndFires(e.Inferno)
+				// Thrower is a method that returns the player who threw it
+				if e.Inferno.Thrower() != nil {
+					thrower := e.Inferno.Thrower()
+					steamID := steamid.ID(thrower.SteamID64).Primary(steamIDFormat)
+					throwerSteamID = &steamID
+					name := thrower.Name
+					throwerName = &name
+					team := getTeamString(thrower.Team)
+					throwerTeam = &team
+				}
 			}
-		}
 
 			// Filter by Steam ID set if provided - skip grenade events from players not in the set
 			if steamIDSet != nil && (throwerSteamID == nil || !steamIDSet[*throwerSteamID]) {
@@ -2345,12 +3898,13 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 					EventType:      "inferno_expire",
 					ProjectileID:   0, // InfernoExpire doesn't have ProjectileID
 					GrenadeName:    "incendiary",
-					X:              float64(pos.X),
-					Y:              float64(pos.Y),
-					Z:              float64(pos.Z),
+					X:              x,
+					Y:              y,
+					Z:              z,
 					ThrowerSteamID: throwerSteamID,
 					ThrowerName:    throwerName,
 					ThrowerTeam:    throwerTeam,
+					FiresJSON:      firesJSON,
 				})
 
 				// Flush if buffer is full
@@ -2364,26 +3918,33 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			} else if data.GrenadeEvents != nil {
 				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
 				// In JSON mode and DB streaming mode, data.GrenadeEvents is nil, so this never executes
-		data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
-			RoundIndex:     currentRound.RoundIndex,
-			Tick:           tick,
-			EventType:      "inferno_expire",
-			ProjectileID:   0, // InfernoExpire doesn't have ProjectileID
-			GrenadeName:    "incendiary",
-			X:              float64(pos.X),
-			Y:              float64(pos.Y),
-			Z:              float64(pos.Z),
-			ThrowerSteamID: throwerSteamID,
-			ThrowerName:    throwerName,
-			ThrowerTeam:    throwerTeam,
-		})
+				data.GrenadeEvents = append(data.GrenadeEvents, GrenadeEventData{
+					RoundIndex:     currentRound.RoundIndex,
+					Tick:           tick,
+					EventType:      "inferno_expire",
+					ProjectileID:   0, // InfernoExpire doesn't have ProjectileID
+					GrenadeName:    "incendiary",
+					X:              x,
+					Y:              y,
+					Z:              z,
+					ThrowerSteamID: throwerSteamID,
+					ThrowerName:    throwerName,
+					ThrowerTeam:    throwerTeam,
+					FiresJSON:      firesJSON,
+				})
 			}
-	})
+		})
 	}
 
-	// Handle player_disconnect GenericGameEvent to extract reason code
-	// This is the same approach used by cs2-web-replay
-	// The GenericGameEvent contains the raw event data including the reason field
+	// Handle player_disconnect GenericGameEvent to extract reason code.
+	// userid is resolved to a SteamID via useridResolver and
+	// StoreDisconnectReason is keyed by that SteamID (with tick as a
+	// secondary field), so two players disconnecting on the same tick can
+	// never collide - see internal/userid's package doc for why UserID,
+	// not Entity.ID(), is the right thing to resolve against. Only when
+	// the userid can't be resolved (e.g. its PlayerConnect was never
+	// observed) does this fall back to the old "tick-N" heuristic key
+	// HandlePlayerDisconnected matches within a ±10 tick window.
 	p.parser.RegisterEventHandler(func(e events.GenericGameEvent) {
 		if e.Name != "player_disconnect" {
 			return
@@ -2393,46 +3954,26 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		tick := getCurrentTick()
 
 		// Extract userid from event data
-		var userid int
+		var eventUserID int
 		if useridKey, ok := e.Data["userid"]; ok && useridKey != nil {
 			if useridKey.ValLong != nil {
-				userid = int(*useridKey.ValLong)
+				eventUserID = int(*useridKey.ValLong)
 			} else if useridKey.ValShort != nil {
-				userid = int(*useridKey.ValShort)
+				eventUserID = int(*useridKey.ValShort)
 			}
 		}
-		if userid == 0 {
+		if eventUserID == 0 {
 			return
 		}
 
-		// Debug: log all available data in event
-		fmt.Fprintf(os.Stderr, "DEBUG: player_disconnect event at tick %d with userid %d\n", tick, userid)
-		for k, v := range e.Data {
-			if v != nil {
-				if v.ValLong != nil {
-					fmt.Fprintf(os.Stderr, "  %s (Long): %d\n", k, *v.ValLong)
-				} else if v.ValShort != nil {
-					fmt.Fprintf(os.Stderr, "  %s (Short): %d\n", k, *v.ValShort)
-				} else if v.ValBool != nil {
-					fmt.Fprintf(os.Stderr, "  %s (Bool): %v\n", k, *v.ValBool)
-				} else if v.ValString != nil {
-					fmt.Fprintf(os.Stderr, "  %s (String): %s\n", k, *v.ValString)
-				} else if v.ValFloat != nil {
-					fmt.Fprintf(os.Stderr, "  %s (Float): %f\n", k, *v.ValFloat)
-				}
-			}
-		}
-
 		// Extract reason from event data - this is the numerical code
 		var reason interface{}
 		if reasonKey, ok := e.Data["reason"]; ok && reasonKey != nil {
 			// Try to get as integer first
 			if reasonKey.ValLong != nil {
 				reason = int(*reasonKey.ValLong)
-				fmt.Fprintf(os.Stderr, "DEBUG: Got reason from ValLong: %v at tick %d\n", reason, tick)
 			} else if reasonKey.ValShort != nil {
 				reason = int(*reasonKey.ValShort)
-				fmt.Fprintf(os.Stderr, "DEBUG: Got reason from ValShort: %v at tick %d\n", reason, tick)
 			} else if reasonKey.ValString != nil {
 				// Try to parse string as int, otherwise keep as string
 				if parsed, err := strconv.Atoi(*reasonKey.ValString); err == nil {
@@ -2440,160 +3981,240 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				} else {
 					reason = *reasonKey.ValString
 				}
-				fmt.Fprintf(os.Stderr, "DEBUG: Got reason from ValString: %v at tick %d\n", reason, tick)
 			}
-		} else {
-			fmt.Fprintf(os.Stderr, "DEBUG: No reason key found in player_disconnect event at tick %d\n", tick)
+		}
+		if reason == nil {
+			return
 		}
 
-		// Find player by userid to get SteamID
-		gs := p.parser.GameState()
-		if gs != nil {
-			participants := gs.Participants()
-			for _, player := range participants.All() {
-				if player != nil {
-					// userid is typically the entity ID - try to match by entity index
-					// For now, we'll match on a close tick basis - when PlayerDisconnected event fires,
-					// we'll use the stored reason
-					// Actually, we need to match by userid properly - but userid in GenericGameEvent
-					// might be different from entity ID. Let's store it and match by tick/round instead
-				}
-			}
+		// Reconcile against the live participant list in case this
+		// userid's PlayerConnect predates useridResolver (e.g. a bot
+		// promoted to a human slot mid-match).
+		if gs := p.parser.GameState(); gs != nil {
+			useridResolver.ObserveParticipants(toUseridPlayers(gs.Participants().All()))
 		}
 
-		// Store reason temporarily - we'll match it with PlayerDisconnected event by tick
-		// Store by tick so we can match it when PlayerDisconnected event fires
-		// We'll look for reasons within a small tick range (±10 ticks) when matching
-		if reason != nil {
-			// Store by tick - we'll match by tick in HandlePlayerDisconnected
-			disconnectExtractor.StoreDisconnectReason(fmt.Sprintf("tick-%d", tick), tick, reason)
-			fmt.Fprintf(os.Stderr, "DEBUG: Stored disconnect reason %v for tick %d\n", reason, tick)
+		if steamID, ok := useridResolver.Resolve(eventUserID); ok {
+			disconnectExtractor.StoreDisconnectReason(steamID, tick, reason)
 		} else {
-			fmt.Fprintf(os.Stderr, "DEBUG: Reason is nil for player_disconnect at tick %d, not storing\n", tick)
+			disconnectExtractor.StoreDisconnectReason(fmt.Sprintf("tick-%d", tick), tick, reason)
 		}
 	})
 
-	// Weapon fired (shots) - using GenericGameEvent
-	// According to https://github.com/markus-wa/demoinfocs-golang/blob/master/docs/game-events.md
-	// weapon_fire is available in both GOTV and POV demos
-	// Skip shots in JSON mode - not needed for output
-	if eventsFile == nil {
+	// Log server_cvar changes (e.g. mp_* settings adjusted mid-match) to the
+	// system chat log, the same way a Source engine log_... file would
+	// record a "server_cvar" line.
 	p.parser.RegisterEventHandler(func(e events.GenericGameEvent) {
-		if e.Name != "weapon_fire" {
+		if e.Name != "server_cvar" {
 			return
 		}
 
-		if currentRound == nil {
+		cvarName := ""
+		if v, ok := e.Data["cvarname"]; ok && v != nil && v.ValString != nil {
+			cvarName = *v.ValString
+		}
+		cvarValue := ""
+		if v, ok := e.Data["cvarvalue"]; ok && v != nil && v.ValString != nil {
+			cvarValue = *v.ValString
+		}
+		if cvarName == "" {
 			return
 		}
+
 		updateTick()
 		tick := getCurrentTick()
+		roundIndex := -1
+		if currentRound != nil {
+			roundIndex = currentRound.RoundIndex
+		}
+		appendSystemChat(db.SystemChatKindServerCvar, roundIndex, tick, nil, nil,
+			fmt.Sprintf("server_cvar: %s = %s", cvarName, cvarValue))
+	})
 
-		// Only track shots after freeze time ends
-		if freezeEndTick == nil || tick < *freezeEndTick {
-			return
+	// Handle vote-kick lifecycle via the vote_started/vote_passed/vote_failed
+	// GenericGameEvents. Like player_disconnect above, UserID isn't available
+	// with CS2 demos, so the initiator/target SteamIDs below are frequently
+	// empty - ChatVoteExtractor still emits the lifecycle events, just without
+	// a resolvable target to cross-reference against disconnects.
+	p.parser.RegisterEventHandler(func(e events.GenericGameEvent) {
+		updateTick()
+		tick := getCurrentTick()
+		roundIndex := -1
+		if currentRound != nil {
+			roundIndex = currentRound.RoundIndex
 		}
 
-		// Get userid from event data
-		// GenericGameEvent.Data is a map[string]*msg.CMsgSource1LegacyGameEventKeyT
-		var userid int
-		if useridKey, ok := e.Data["userid"]; ok && useridKey != nil {
-			// The value is stored in the KeyT structure
-			// Try to get the integer value from ValLong or ValShort
-			if useridKey.ValLong != nil {
-				userid = int(*useridKey.ValLong)
-			} else if useridKey.ValShort != nil {
-				userid = int(*useridKey.ValShort)
+		genericEventString := func(key string) string {
+			if v, ok := e.Data[key]; ok && v != nil && v.ValString != nil {
+				return *v.ValString
 			}
+			return ""
 		}
-		if userid == 0 {
-			return
-		}
-
-		// Get weapon name from event data
-		weaponName := "unknown"
-		if weaponKey, ok := e.Data["weapon"]; ok && weaponKey != nil {
-			if weaponKey.ValString != nil {
-				weaponName = *weaponKey.ValString
+		genericEventInt := func(key string) int {
+			if v, ok := e.Data[key]; ok && v != nil {
+				if v.ValLong != nil {
+					return int(*v.ValLong)
+				}
+				if v.ValShort != nil {
+					return int(*v.ValShort)
+				}
 			}
+			return 0
 		}
 
-		// Skip knife and grenades (we only want gun shots)
-		if strings.Contains(weaponName, "knife") || 
-		   strings.Contains(weaponName, "grenade") || 
-		   strings.Contains(weaponName, "flashbang") ||
-		   strings.Contains(weaponName, "smoke") ||
-		   strings.Contains(weaponName, "molotov") ||
-		   strings.Contains(weaponName, "incendiary") ||
-		   strings.Contains(weaponName, "decoy") ||
-		   strings.Contains(weaponName, "c4") {
-			return
-		}
+		// Source engine only allows one vote to be in progress at a time, so
+		// a single fixed key is enough to correlate vote_started with the
+		// vote_passed/vote_failed that follows it - there's no shared vote
+		// id carried across these GenericGameEvents to match on instead.
+		const currentVoteID = "current"
 
-		// Find player by userid
-		gs := p.parser.GameState()
-		if gs == nil {
-			return
+		switch e.Name {
+		case "vote_started":
+			initiator := genericEventString("initiator")
+			target := genericEventString("target")
+			chatVoteExtractor.HandleVoteStarted(currentVoteID, initiator, target, roundIndex, tick)
+
+		case "vote_passed", "vote_failed":
+			yesVotes := genericEventInt("yes_votes")
+			noVotes := genericEventInt("no_votes")
+			chatVoteExtractor.HandleVoteResult(currentVoteID, e.Name == "vote_passed", yesVotes, noVotes, roundIndex, tick)
 		}
+	})
 
-		participants := gs.Participants()
-		var player *common.Player
-		for _, p := range participants.All() {
-			if p != nil && int(p.Entity.ID()) == userid {
-				player = p
-				break
+	// Weapon fired (shots) - using GenericGameEvent
+	// According to https://github.com/markus-wa/demoinfocs-golang/blob/master/docs/game-events.md
+	// weapon_fire is available in both GOTV and POV demos
+	// Skip shots in JSON mode - not needed for output
+	if eventsFile == nil {
+		p.parser.RegisterEventHandler(func(e events.GenericGameEvent) {
+			if e.Name != "weapon_fire" {
+				return
 			}
-		}
 
-		if player == nil {
-			return
-		}
+			if currentRound == nil {
+				return
+			}
+			updateTick()
+			tick := getCurrentTick()
 
-		// Skip spectators
-		if player.Team == common.TeamSpectators || player.Team == common.TeamUnassigned {
-			return
-		}
+			// Only track shots after freeze time ends
+			if freezeEndTick == nil || tick < *freezeEndTick {
+				return
+			}
 
-		// Get player position
-		pos := player.Position()
+			// Get userid from event data
+			// GenericGameEvent.Data is a map[string]*msg.CMsgSource1LegacyGameEventKeyT
+			var eventUserID int
+			if useridKey, ok := e.Data["userid"]; ok && useridKey != nil {
+				// The value is stored in the KeyT structure
+				// Try to get the integer value from ValLong or ValShort
+				if useridKey.ValLong != nil {
+					eventUserID = int(*useridKey.ValLong)
+				} else if useridKey.ValShort != nil {
+					eventUserID = int(*useridKey.ValShort)
+				}
+			}
+			if eventUserID == 0 {
+				return
+			}
 
-		// Get team
-		var team *string
-		switch player.Team {
-		case common.TeamTerrorists:
-			t := "T"
-			team = &t
-		case common.TeamCounterTerrorists:
-			ct := "CT"
-			team = &ct
-		default:
-			return
-		}
+			// Get weapon name from event data
+			weaponName := "unknown"
+			if weaponKey, ok := e.Data["weapon"]; ok && weaponKey != nil {
+				if weaponKey.ValString != nil {
+					weaponName = *weaponKey.ValString
+				}
+			}
+
+			// Skip knife and grenades (we only want gun shots)
+			if strings.Contains(weaponName, "knife") ||
+				strings.Contains(weaponName, "grenade") ||
+				strings.Contains(weaponName, "flashbang") ||
+				strings.Contains(weaponName, "smoke") ||
+				strings.Contains(weaponName, "molotov") ||
+				strings.Contains(weaponName, "incendiary") ||
+				strings.Contains(weaponName, "decoy") ||
+				strings.Contains(weaponName, "c4") {
+				return
+			}
+
+			// Find player by userid, resolved to a SteamID via
+			// useridResolver rather than matching Entity.ID() - the
+			// entity backing a given userid is recreated on every
+			// respawn, so that match silently missed shots fired after
+			// a death/respawn (see internal/userid's package doc).
+			gs := p.parser.GameState()
+			if gs == nil {
+				return
+			}
+
+			participants := gs.Participants().All()
+			useridResolver.ObserveParticipants(toUseridPlayers(participants))
+
+			resolvedSteamID, ok := useridResolver.Resolve(eventUserID)
+			if !ok {
+				return
+			}
+
+			var player *common.Player
+			for _, candidate := range participants {
+				if candidate != nil && steamid.ID(candidate.SteamID64).Primary(steamIDFormat) == resolvedSteamID {
+					player = candidate
+					break
+				}
+			}
+
+			if player == nil {
+				return
+			}
+
+			// Skip spectators
+			if player.Team == common.TeamSpectators || player.Team == common.TeamUnassigned {
+				return
+			}
+
+			// Get player position
+			pos := player.Position()
+
+			// Get team
+			var team *string
+			switch player.Team {
+			case common.TeamTerrorists:
+				t := "T"
+				team = &t
+			case common.TeamCounterTerrorists:
+				ct := "CT"
+				team = &ct
+			default:
+				return
+			}
 
-		steamID := fmt.Sprintf("%d", player.SteamID64)
+			steamID := steamid.ID(player.SteamID64).Primary(steamIDFormat)
 
 			// Filter by Steam ID set if provided - skip shots from players not in the set
 			if steamIDSet != nil && !steamIDSet[steamID] {
 				return
 			}
 
-		// Get view direction (yaw angle) - same calculation as player positions
-		viewDirX := player.ViewDirectionX()
-		viewDirY := player.ViewDirectionY()
-		var yaw float64
-		if viewDirX != 0 || viewDirY != 0 {
-			yaw = math.Atan2(float64(viewDirY), float64(viewDirX)) * 180.0 / math.Pi
-			if yaw < 0 {
-				yaw += 360
+			afkExtractor.RegisterActivity(steamID, tick, extractors.ActivityShotFired)
+
+			// Get view direction (yaw angle) - same calculation as player positions
+			viewDirX := player.ViewDirectionX()
+			viewDirY := player.ViewDirectionY()
+			var yaw float64
+			if viewDirX != 0 || viewDirY != 0 {
+				yaw = math.Atan2(float64(viewDirY), float64(viewDirX)) * 180.0 / math.Pi
+				if yaw < 0 {
+					yaw += 360
+				}
 			}
-		}
 
-		// Get pitch (view angle up/down)
-		// Note: ViewDirectionZ() doesn't exist in demoinfocs v5
-		// We can calculate pitch from ViewDirectionX/Y if needed, but for now we'll skip it
-		// Pitch is not critical for 2D viewer rendering
-		var pitch *float64
-		// TODO: Calculate pitch if needed using ViewDirectionX/Y conversion
+			// Get pitch (view angle up/down)
+			// Note: ViewDirectionZ() doesn't exist in demoinfocs v5
+			// We can calculate pitch from ViewDirectionX/Y if needed, but for now we'll skip it
+			// Pitch is not critical for 2D viewer rendering
+			var pitch *float64
+			// TODO: Calculate pitch if needed using ViewDirectionX/Y conversion
 
 			// Stream shot to database if writer is available
 			if writer != nil && matchID != "" {
@@ -2622,20 +4243,20 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			} else if data.Shots != nil {
 				// Fallback: store in memory ONLY if slice is allocated (in-memory mode)
 				// In JSON mode and DB streaming mode, data.Shots is nil, so this never executes
-		data.Shots = append(data.Shots, ShotData{
-			RoundIndex: currentRound.RoundIndex,
-			Tick:       tick,
-			SteamID:    steamID,
-			WeaponName: weaponName,
-			X:          float64(pos.X),
-			Y:          float64(pos.Y),
-			Z:          float64(pos.Z),
-			Yaw:        yaw,
-			Pitch:      pitch,
-			Team:       team,
-		})
+				data.Shots = append(data.Shots, ShotData{
+					RoundIndex: currentRound.RoundIndex,
+					Tick:       tick,
+					SteamID:    steamID,
+					WeaponName: weaponName,
+					X:          float64(pos.X),
+					Y:          float64(pos.Y),
+					Z:          float64(pos.Z),
+					Yaw:        yaw,
+					Pitch:      pitch,
+					Team:       team,
+				})
 			}
-	})
+		})
 	}
 
 	// Parse the demo
@@ -2695,9 +4316,20 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 
 		// Finalize pending events for last round
 		teamDamageExtractor.FinalizeRound(currentRound.RoundIndex)
-		teamFlashExtractor.FinalizeRound(currentRound.RoundIndex)
+		friendlyFireExtractor.FinalizeRound(currentRound.RoundIndex)
 		// AFK detection is now done from database after positions are written
-		disconnectExtractor.FinalizeRound(currentRound.RoundIndex)
+		// (writer != nil) or, for the no-writer streaming path, from
+		// currentRoundPositions at round end above - but a demo that ends
+		// mid-round never fires RoundEnd, so run that same pass here too.
+		if writer == nil {
+			if currentRound.FreezeEndTick != nil {
+				if err := afkExtractor.ProcessAFKFromPositions(matchID, currentRound.RoundIndex, *currentRound.FreezeEndTick, finalTick, currentRoundPositions); err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: AFK detection failed for final round %d: %v\n", currentRound.RoundIndex, err)
+				}
+			}
+			bodyBlockExtractor.ProcessRoundFromPositions(currentRound.RoundIndex, currentRound.StartTick, finalTick, currentRoundPositions)
+		}
+		behaviorRegistry.FinalizeAll(currentRound.RoundIndex)
 	}
 
 	// Flush any remaining buffers before collecting events
@@ -2725,10 +4357,45 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		allEvents = append(allEvents, killExtractor.GetEvents()...)
 		allEvents = append(allEvents, teamDamageExtractor.GetEvents()...)
 		allEvents = append(allEvents, teamFlashExtractor.GetEvents()...)
+		allEvents = append(allEvents, flashExtractor.GetEvents()...)
 		allEvents = append(allEvents, disconnectExtractor.GetEvents()...)
 		allEvents = append(allEvents, afkExtractor.GetEvents()...)
 		allEvents = append(allEvents, economyExtractor.GetEvents()...)
+		allEvents = append(allEvents, economyProfileExtractor.GetEvents()...)
 		allEvents = append(allEvents, bodyBlockExtractor.GetEvents()...)
+		allEvents = append(allEvents, griefingAggregator.GetEvents()...)
+		allEvents = append(allEvents, chatVoteExtractor.GetEvents()...)
+		allEvents = append(allEvents, friendlyFireExtractor.GetEvents()...)
+
+		if p.resumeFromRound >= 0 {
+			// Rounds up to resumeFromRound were already flushed to disk by
+			// the run this one is resuming; re-emitting them would
+			// duplicate output in the NDJSON file/chunks.
+			kept := allEvents[:0]
+			for _, event := range allEvents {
+				if event.RoundIndex > p.resumeFromRound {
+					kept = append(kept, event)
+				}
+			}
+			allEvents = kept
+		}
+
+		if p.steamClient != nil {
+			if err := steamapi.EnrichEvents(ctx, p.steamClient, allEvents); err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Steam API enrichment failed, continuing without it: %v\n", err)
+			}
+		}
+
+		if p.eventBroker != nil {
+			for _, event := range allEvents {
+				eventJSON, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				topic := broker.EventTopic(p.eventBrokerID, event.Type)
+				p.eventBroker.PublishDedup(topic, eventJSON, eventDedupID(p.eventBrokerID, event))
+			}
+		}
 
 		if eventsFile != nil {
 			// Write events to file as NDJSON
@@ -2748,14 +4415,17 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 			// Clear events from extractors to free memory
 			if clearAfterFlush {
-				teamKillExtractor.ClearEvents()
+				behaviorRegistry.ResetAll()
 				killExtractor.ClearEvents()
 				teamDamageExtractor.ClearEvents()
-				teamFlashExtractor.ClearEvents()
-				disconnectExtractor.ClearEvents()
+				flashExtractor.ClearEvents()
 				afkExtractor.ClearEvents()
 				economyExtractor.ClearEvents()
+				economyProfileExtractor.ClearEvents()
 				bodyBlockExtractor.ClearEvents()
+				griefingAggregator.ClearEvents()
+				chatVoteExtractor.ClearEvents()
+				friendlyFireExtractor.ClearEvents()
 			}
 		} else {
 			// Store in memory for database mode
@@ -2763,6 +4433,41 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		}
 	}
 
+	// Economy personality profiles are only meaningful once every round has
+	// been recorded, so compute them right before the final flush.
+	economyProfileExtractor.Finalize()
+
+	// Disconnect classification needs final match K/D, only available now
+	// that every round has been processed.
+	if finalParticipants := p.parser.GameState().Participants(); finalParticipants != nil {
+		matchKills := make(map[string]int)
+		matchDeaths := make(map[string]int)
+		for _, participant := range finalParticipants.All() {
+			if participant == nil {
+				continue
+			}
+			steamID := steamid.ID(participant.SteamID64).Primary(steamIDFormat)
+			matchKills[steamID] = participant.Kills()
+			matchDeaths[steamID] = participant.Deaths()
+		}
+		disconnectExtractor.SetMatchContext(matchKills, matchDeaths)
+	}
+	disconnectExtractor.ClassifyDisconnects(tickRate)
+
+	// Reclassify any ABANDON that followed a passed vote-kick within
+	// voteKickTimeoutSeconds, so formatDisconnectReason's generic "Kicked"
+	// gets replaced with vote context (initiator SteamID, yes/no tallies).
+	chatVoteExtractor.ApplyVoteKickContext(disconnectExtractor, tickRate)
+
+	// Final catch-up: ClassifyDisconnects only produces ABANDON events once
+	// the whole match is known, so the griefing aggregator needs one last
+	// ingest pass (plus a final evaluation) to score rage-quits alongside
+	// every team kill/flash already folded in round by round.
+	griefingAggregator.IngestSource("team_kill", teamKillExtractor.GetEvents())
+	griefingAggregator.IngestSource("team_flash", teamFlashExtractor.GetEvents())
+	griefingAggregator.IngestSource("disconnect", disconnectExtractor.GetEvents())
+	griefingAggregator.Evaluate(roundNumber)
+
 	// Flush all events at the end (don't clear, we need them for database mode)
 	flushEventsFromExtractors(false)
 
@@ -2795,7 +4500,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				player.Team = team
 			}
 		}
-		
+
 		// Mark as permanent disconnect if they disconnected and never reconnected
 		// But exclude disconnects within 20 seconds of game end
 		if playerDisconnected[steamID64] {
@@ -2812,14 +4517,14 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 				// Fallback: if we can't calculate, mark as permanent
 				player.PermanentDisconnect = true
 			}
-			
+
 			// Store disconnect round
 			if disconnectRound, exists := playerDisconnectRound[steamID64]; exists {
 				roundNum := &disconnectRound
 				player.DisconnectRound = roundNum
 			}
 		}
-		
+
 		// Update connected_midgame flag and first connect round based on when they first connected
 		if firstConnectRound, exists := playerFirstConnectRound[steamID64]; exists {
 			if firstConnectRound > 0 {
@@ -2827,10 +4532,10 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 			player.FirstConnectRound = &firstConnectRound
 		}
-		
+
 		// Filter by Steam ID set if provided - only include players in the set
 		if steamIDSet == nil || steamIDSet[player.SteamID] {
-		data.Players = append(data.Players, *player)
+			data.Players = append(data.Players, *player)
 		}
 	}
 
@@ -2846,7 +4551,7 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 			}
 		}
 	}
-	
+
 	// Flush any remaining positions in buffer
 	if writer != nil && matchID != "" && len(positionBuffer) > 0 {
 		if err := writer.InsertPlayerPositions(ctx, positionBuffer); err != nil {
@@ -2854,6 +4559,20 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 		}
 		positionBuffer = positionBuffer[:0]
 	}
+	if writer != nil && matchID != "" {
+		if len(positionKeyframeBuffer) > 0 {
+			if err := writer.InsertPlayerPositionKeyframes(ctx, positionKeyframeBuffer); err != nil {
+				return nil, fmt.Errorf("failed to flush final position keyframe buffer: %w", err)
+			}
+			positionKeyframeBuffer = positionKeyframeBuffer[:0]
+		}
+		if len(positionDeltaBuffer) > 0 {
+			if err := writer.InsertPlayerPositionDeltas(ctx, positionDeltaBuffer); err != nil {
+				return nil, fmt.Errorf("failed to flush final position delta buffer: %w", err)
+			}
+			positionDeltaBuffer = positionDeltaBuffer[:0]
+		}
+	}
 
 	// Defensive diagnostics: check lengths of large slices to detect accidental accumulation
 	// In JSON mode and DB streaming mode, these should all be 0
@@ -2872,8 +4591,11 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 	// }
 
 	// Set source (map is already set above)
-	demoFileName := filepath.Base(p.path)
-	data.Source = getDemoSource(serverName, demoFileName)
+	data.Source = getDemoSource(serverName, p.sourceName)
+
+	if p.resumeFromRound >= 0 && p.resumeData != nil {
+		data = mergeResumedMatchData(p.resumeData, data, p.resumeFromRound)
+	}
 
 	return data, nil
 }
@@ -2882,7 +4604,24 @@ func (p *Parser) ParseWithDB(ctx context.Context, callback ParseCallback, dbConn
 // This frees the memory used by demoinfocs to hold the demo file in memory.
 // IMPORTANT: All parsing operations must complete before calling Close().
 // Safe to call multiple times - will only close once and ignore "already closed" errors.
+//
+// The one exception is a Stream(ctx) in progress: Close called from inside a
+// streaming handler (or from another goroutine while Stream is running)
+// can't safely close the file out from under ParseToEnd, so it instead
+// cancels the stream (same as Cancel) and defers the actual close until
+// Stream returns, which then calls Close again to finish the job.
 func (p *Parser) Close() error {
+	p.streamMu.Lock()
+	if p.streaming {
+		p.closePending = true
+		if p.parser != nil {
+			p.parser.Cancel()
+		}
+		p.streamMu.Unlock()
+		return nil
+	}
+	p.streamMu.Unlock()
+
 	var err error
 	if p.parser != nil {
 		if closeErr := p.parser.Close(); closeErr != nil {
@@ -2893,19 +4632,40 @@ func (p *Parser) Close() error {
 		}
 		p.parser = nil // Mark as closed
 	}
-	// Note: demoinfocs parser.Close() already closes the underlying file,
-	// so we don't need to close p.file separately. Just mark it as nil.
-	if p.file != nil {
-		p.file = nil // Mark as closed (file is closed by parser.Close())
+	// Note: demoinfocs parser.Close() already closes the underlying
+	// stream, so we don't need to close p.rc separately. Just mark it as
+	// nil.
+	if p.rc != nil {
+		p.rc = nil // Mark as closed (stream is closed by parser.Close())
 	}
 	return err
 }
 
-// getSteamID converts a player's SteamID64 to a string, handling nil players.
-func getSteamID(player *common.Player) *string {
-	if player == nil {
-		return nil
+// eventDedupID builds a stable idempotency key for publishing event to
+// matchID's broker subject (see broker.Publisher.PublishDedup), so a
+// --resume run republishing a round already delivered doesn't produce a
+// duplicate downstream of a JetStream-backed NATS subject.
+func eventDedupID(matchID string, event extractors.Event) string {
+	actor := ""
+	if event.ActorSteamID != nil {
+		actor = *event.ActorSteamID
+	}
+	victim := ""
+	if event.VictimSteamID != nil {
+		victim = *event.VictimSteamID
+	}
+	return fmt.Sprintf("%s:%s:%d:%d:%s:%s", matchID, event.Type, event.RoundIndex, event.StartTick, actor, victim)
+}
+
+// toUseridPlayers adapts a live participant list to the narrow shape
+// userid.Resolver.ObserveParticipants needs, skipping nil players.
+func toUseridPlayers(participants []*common.Player) []userid.Player {
+	out := make([]userid.Player, 0, len(participants))
+	for _, participant := range participants {
+		if participant == nil {
+			continue
+		}
+		out = append(out, userid.Player{UserID: participant.UserID, SteamID64: participant.SteamID64})
 	}
-	steamID := fmt.Sprintf("%d", player.SteamID64)
-	return &steamID
+	return out
 }
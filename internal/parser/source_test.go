@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSourceOpen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "match.dem")
+	if err := os.WriteFile(path, []byte("demo-bytes"), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	rc, meta, err := (FileSource{Path: path}).Open(context.Background())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rc.Close()
+
+	if meta.Name != "match.dem" || meta.Size != int64(len("demo-bytes")) {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(data) != "demo-bytes" {
+		t.Errorf("got %q, want %q", data, "demo-bytes")
+	}
+}
+
+func TestFileSourceOpenMissing(t *testing.T) {
+	if _, _, err := (FileSource{Path: "/nonexistent/match.dem"}).Open(context.Background()); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestHTTPSourceOpen(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("demo-bytes"))
+	}))
+	defer srv.Close()
+
+	rc, meta, err := (HTTPSource{URL: srv.URL + "/match.dem"}).Open(context.Background())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rc.Close()
+
+	if meta.Name != "match.dem" {
+		t.Errorf("got name %q, want match.dem", meta.Name)
+	}
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(data) != "demo-bytes" {
+		t.Errorf("got %q, want %q", data, "demo-bytes")
+	}
+}
+
+func TestHTTPSourceRejectsNonResumableRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK) // ignores the Range header, unlike a real resumable server
+	}))
+	defer srv.Close()
+
+	if _, _, err := (HTTPSource{URL: srv.URL + "/match.dem", ResumeFrom: 100}).Open(context.Background()); err == nil {
+		t.Error("expected an error when the server ignores Range and returns 200")
+	}
+}
+
+func TestHTTPSourceReportsUnexpectedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if _, _, err := (HTTPSource{URL: srv.URL + "/match.dem"}).Open(context.Background()); err == nil {
+		t.Error("expected an error for a 404 response")
+	}
+}
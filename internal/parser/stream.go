@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dem "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs"
+	common "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/common"
+	events "github.com/markus-wa/demoinfocs-golang/v5/pkg/demoinfocs/events"
+
+	"cs-griefer-electron/internal/steamid"
+)
+
+// KillEvent is the streaming-friendly shape of a Kill event - SteamIDs
+// instead of *common.Player, like every other event type this package
+// exposes across MatchData/db (see db.PlayerPosition's SteamID).
+type KillEvent struct {
+	RoundIndex int
+	Tick       int
+	Killer     *string
+	Victim     *string
+	Weapon     string
+	Headshot   bool
+}
+
+// GrenadeThrownEvent is the streaming-friendly shape of a
+// GrenadeProjectileThrow event.
+type GrenadeThrownEvent struct {
+	RoundIndex  int
+	Tick        int
+	Thrower     *string
+	GrenadeName string
+}
+
+// PlayerPositionEvent is one player's position as of a FrameDone tick.
+// OnPlayerPosition fires this once per tracked player per FrameDone, with
+// no sampling interval of its own - unlike ParseWithDB's position pipeline,
+// which thins samples out under --position-interval/--heap-soft-limit,
+// Stream leaves any such throttling to the caller's own handler.
+type PlayerPositionEvent struct {
+	RoundIndex int
+	Tick       int
+	SteamID    string
+	X, Y, Z    float64
+}
+
+// ChatMessageEvent is the streaming-friendly shape of a ChatMessage event.
+type ChatMessageEvent struct {
+	RoundIndex int
+	Tick       int
+	SteamID    string
+	Message    string
+	IsTeamChat bool
+}
+
+// OnKill registers fn to be called for every Kill event during Stream.
+// Only the most recently registered fn is kept, same single-callback
+// contract as SetPlayerDeathCallback.
+func (p *Parser) OnKill(fn func(KillEvent)) {
+	p.onStreamKill = fn
+}
+
+// OnGrenadeThrown registers fn to be called for every
+// GrenadeProjectileThrow event during Stream.
+func (p *Parser) OnGrenadeThrown(fn func(GrenadeThrownEvent)) {
+	p.onStreamGrenadeThrown = fn
+}
+
+// OnPlayerPosition registers fn to be called once per tracked player on
+// every FrameDone event during Stream.
+func (p *Parser) OnPlayerPosition(fn func(PlayerPositionEvent)) {
+	p.onStreamPlayerPosition = fn
+}
+
+// OnChatMessage registers fn to be called for every ChatMessage event
+// during Stream.
+func (p *Parser) OnChatMessage(fn func(ChatMessageEvent)) {
+	p.onStreamChatMessage = fn
+}
+
+// RegisterHandler forwards handler to the underlying demoinfocs event
+// dispatcher unchanged, so a caller can subscribe to any demoinfocs event
+// type (events.BombDefused, events.WeaponFired, ...) Stream doesn't already
+// have an On* convenience method for, without this package wrapping every
+// one of them. handler must be func(EventType), exactly
+// dem.Parser.RegisterEventHandler's own contract - Stream doesn't validate
+// this itself, the underlying dispatcher does.
+func (p *Parser) RegisterHandler(handler any) {
+	p.parser.RegisterEventHandler(handler)
+}
+
+// Stream drives the underlying demoinfocs parser tick-by-tick, invoking
+// whichever OnKill/OnGrenadeThrown/OnPlayerPosition/OnChatMessage/
+// RegisterHandler callbacks were registered beforehand, and never
+// accumulates a MatchData - the caller's handlers are the only place
+// results end up. This is the alternative to Parse/ParseWithDB for demos
+// too large to hold in memory as a single result struct.
+//
+// ctx cancellation maps onto demoinfocs's own Parser.Cancel()/ErrCancelled:
+// Stream returns nil (not ctx.Err()) when ctx is what triggered the
+// cancellation, matching Cancel's own contract below.
+func (p *Parser) Stream(ctx context.Context) error {
+	p.streamMu.Lock()
+	p.streaming = true
+	p.streamMu.Unlock()
+	defer func() {
+		p.streamMu.Lock()
+		p.streaming = false
+		closePending := p.closePending
+		p.closePending = false
+		p.streamMu.Unlock()
+		if closePending {
+			p.Close()
+		}
+	}()
+
+	var currentRoundIndex int
+	var currentTick int
+
+	p.parser.RegisterEventHandler(func(e events.RoundStart) {
+		currentRoundIndex++
+	})
+
+	p.parser.RegisterEventHandler(func(e events.FrameDone) {
+		gs := p.parser.GameState()
+		if gs == nil {
+			return
+		}
+		currentTick = gs.IngameTick()
+
+		if p.onStreamPlayerPosition == nil {
+			return
+		}
+		for _, player := range gs.Participants().All() {
+			if player == nil || player.Team == common.TeamSpectators || player.Team == common.TeamUnassigned {
+				continue
+			}
+			pos := player.Position()
+			p.onStreamPlayerPosition(PlayerPositionEvent{
+				RoundIndex: currentRoundIndex,
+				Tick:       currentTick,
+				SteamID:    steamid.ID(player.SteamID64).Primary(p.steamIDFormat),
+				X:          pos.X,
+				Y:          pos.Y,
+				Z:          pos.Z,
+			})
+		}
+	})
+
+	p.parser.RegisterEventHandler(func(e events.Kill) {
+		if p.onStreamKill == nil {
+			return
+		}
+		weapon := ""
+		if e.Weapon != nil {
+			weapon = e.Weapon.String()
+		}
+		p.onStreamKill(KillEvent{
+			RoundIndex: currentRoundIndex,
+			Tick:       currentTick,
+			Killer:     p.getSteamID(e.Killer),
+			Victim:     p.getSteamID(e.Victim),
+			Weapon:     weapon,
+			Headshot:   e.IsHeadshot,
+		})
+	})
+
+	p.parser.RegisterEventHandler(func(e events.GrenadeProjectileThrow) {
+		if p.onStreamGrenadeThrown == nil || e.Projectile == nil {
+			return
+		}
+		grenadeName := ""
+		if e.Projectile.WeaponInstance != nil {
+			grenadeName = strings.ToLower(e.Projectile.WeaponInstance.Type.String())
+		}
+		p.onStreamGrenadeThrown(GrenadeThrownEvent{
+			RoundIndex:  currentRoundIndex,
+			Tick:        currentTick,
+			Thrower:     p.getSteamID(e.Projectile.Thrower),
+			GrenadeName: grenadeName,
+		})
+	})
+
+	p.parser.RegisterEventHandler(func(e events.ChatMessage) {
+		if p.onStreamChatMessage == nil || e.Sender == nil {
+			return
+		}
+		p.onStreamChatMessage(ChatMessageEvent{
+			RoundIndex: currentRoundIndex,
+			Tick:       currentTick,
+			SteamID:    steamid.ID(e.Sender.SteamID64).Primary(p.steamIDFormat),
+			Message:    e.Text,
+			IsTeamChat: !e.IsChatAll,
+		})
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.Cancel()
+		case <-stop:
+		}
+	}()
+
+	if err := p.parser.ParseToEnd(); err != nil {
+		if err == dem.ErrCancelled {
+			return nil
+		}
+		return fmt.Errorf("stream demo: %w", err)
+	}
+	return nil
+}
+
+// Cancel aborts a Stream(ctx) in progress, same as cancelling ctx - safe to
+// call from inside a handler (same goroutine as Stream) or from another
+// goroutine. A no-op if Stream isn't running or the parser is already
+// closed.
+func (p *Parser) Cancel() {
+	p.streamMu.Lock()
+	defer p.streamMu.Unlock()
+	if p.parser != nil {
+		p.parser.Cancel()
+	}
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatchSource polls a directory for new .dem files, emitting each as a
+// FileSource once it looks complete. It does not use fsnotify: that
+// package isn't vendored in this offline build, so instead of inotify
+// events this falls back to comparing a file's size across two
+// consecutive polls - a cruder, dependency-free stand-in for "the writer
+// closed the file" (see internal/steamapi's tokenBucket doc comment for
+// the same kind of substitution elsewhere in this repo).
+//
+// WatchSource doesn't implement DemoSource itself: "watch a directory" is
+// an ongoing stream of files, not a single Open(ctx) byte stream. Feed its
+// output channel to a Dispatcher to actually parse what it finds.
+type WatchSource struct {
+	Dir string
+	// PollInterval is how often Dir is re-scanned. <= 0 defaults to 2s.
+	PollInterval time.Duration
+}
+
+func (w WatchSource) withDefaults() WatchSource {
+	if w.PollInterval <= 0 {
+		w.PollInterval = 2 * time.Second
+	}
+	return w
+}
+
+// Watch scans w.Dir every PollInterval and sends a FileSource for each new
+// .dem file once its size has stopped changing between two consecutive
+// scans. It runs until ctx is cancelled, closing the returned channel
+// before it returns.
+func (w WatchSource) Watch(ctx context.Context) <-chan FileSource {
+	w = w.withDefaults()
+	out := make(chan FileSource)
+
+	go func() {
+		defer close(out)
+
+		emitted := make(map[string]bool)
+		lastSize := make(map[string]int64)
+		ticker := time.NewTicker(w.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.scan(ctx, out, emitted, lastSize)
+			}
+		}
+	}()
+
+	return out
+}
+
+func (w WatchSource) scan(ctx context.Context, out chan<- FileSource, emitted map[string]bool, lastSize map[string]int64) {
+	entries, err := os.ReadDir(w.Dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || emitted[entry.Name()] || !strings.HasSuffix(strings.ToLower(entry.Name()), ".dem") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		prevSize, seenBefore := lastSize[entry.Name()]
+		lastSize[entry.Name()] = info.Size()
+		if !seenBefore || info.Size() == 0 || info.Size() != prevSize {
+			continue // still growing (or just noticed) - wait for the next poll
+		}
+
+		emitted[entry.Name()] = true
+		select {
+		case out <- FileSource{Path: filepath.Join(w.Dir, entry.Name())}:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// Dispatcher runs a channel of FileSources (typically WatchSource.Watch's
+// output) through a fixed number of parser workers, each calling ParseOne
+// once per file - the server-side batch-ingestion counterpart to
+// Electron's one-file-at-a-time interactive use, all workers writing to
+// the same SQLite database via whatever ParseOne closes over (see
+// cmd/parser's runWithDB for the usual NewParserFromSource+ParseWithDB
+// shape).
+type Dispatcher struct {
+	// Workers bounds how many FileSources are parsed concurrently. <= 0
+	// defaults to 1.
+	Workers int
+	// ParseOne is invoked once per FileSource Run receives. It owns its
+	// own error handling/logging - Run has no error channel, since one
+	// bad demo in a long-running watch shouldn't stop the rest.
+	ParseOne func(ctx context.Context, src FileSource)
+}
+
+// Run fans sources out across d.Workers goroutines and returns once
+// sources is closed and every in-flight ParseOne call has returned.
+func (d Dispatcher) Run(ctx context.Context, sources <-chan FileSource) {
+	workers := d.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for src := range sources {
+				d.ParseOne(ctx, src)
+			}
+		}()
+	}
+	wg.Wait()
+}
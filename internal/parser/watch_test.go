@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWatchSourceScanWaitsWhileFileIsGrowing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "match.dem")
+	if err := os.WriteFile(path, []byte("half"), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	w := WatchSource{Dir: dir}
+	out := make(chan FileSource, 1)
+	emitted := make(map[string]bool)
+	lastSize := make(map[string]int64)
+	ctx := context.Background()
+
+	w.scan(ctx, out, emitted, lastSize) // first sighting: no baseline to compare yet
+	select {
+	case src := <-out:
+		t.Fatalf("expected no emission on first scan, got %+v", src)
+	default:
+	}
+
+	if err := os.WriteFile(path, []byte("half-plus-more"), 0o644); err != nil {
+		t.Fatalf("failed to grow fixture: %v", err)
+	}
+	w.scan(ctx, out, emitted, lastSize) // size changed since last scan: still growing
+	select {
+	case src := <-out:
+		t.Fatalf("expected no emission while the file is still growing, got %+v", src)
+	default:
+	}
+}
+
+func TestWatchSourceScanEmitsOnceSizeStable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "match.dem")
+	if err := os.WriteFile(path, []byte("complete"), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	w := WatchSource{Dir: dir}
+	out := make(chan FileSource, 1)
+	emitted := make(map[string]bool)
+	lastSize := make(map[string]int64)
+	ctx := context.Background()
+
+	w.scan(ctx, out, emitted, lastSize) // first sighting: records size, no emission
+	w.scan(ctx, out, emitted, lastSize) // size unchanged: emits
+
+	select {
+	case src := <-out:
+		if src.Path != path {
+			t.Errorf("got %q, want %q", src.Path, path)
+		}
+	default:
+		t.Fatal("expected an emission once size stabilized")
+	}
+
+	w.scan(ctx, out, emitted, lastSize) // already emitted: must not re-emit
+	select {
+	case src := <-out:
+		t.Fatalf("expected no re-emission, got %+v", src)
+	default:
+	}
+}
+
+func TestWatchSourceScanIgnoresNonDemFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hi"), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	w := WatchSource{Dir: dir}
+	out := make(chan FileSource, 1)
+	emitted := make(map[string]bool)
+	lastSize := make(map[string]int64)
+	ctx := context.Background()
+
+	w.scan(ctx, out, emitted, lastSize)
+	w.scan(ctx, out, emitted, lastSize)
+
+	select {
+	case src := <-out:
+		t.Fatalf("expected non-.dem files to be ignored, got %+v", src)
+	default:
+	}
+}
@@ -0,0 +1,193 @@
+// Package pipeline runs a batch of demos through a parse function
+// concurrently, bounded by a worker pool, instead of the one-demo-at-a-time
+// flow cmd/parser's run/runJSON otherwise offer. Every worker shares a
+// single SQLite output connection capped to one open connection, so many
+// demos writing "concurrently" are actually serialized by database/sql
+// itself rather than tripping SQLite's SQLITE_BUSY. A demo whose ParseFunc
+// errors is recorded in failed_demos instead of aborting the rest of the
+// batch.
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"sync"
+
+	"cs-griefer-electron/internal/db"
+	"cs-griefer-electron/internal/ipc"
+)
+
+// Job is one demo to parse as part of a batch.
+type Job struct {
+	DemoPath string
+	MatchID  string // derived from DemoPath's filename if empty
+}
+
+// ParseFunc parses one demo against the batch's shared dbConn. A non-nil
+// error fails only that job - it's recorded in failed_demos rather than
+// aborting the rest of the batch.
+type ParseFunc func(ctx context.Context, dbConn *sql.DB, job Job) error
+
+// Config configures a BatchRunner.
+type Config struct {
+	// Workers bounds how many ParseFunc calls run concurrently. <= 0
+	// defaults to runtime.NumCPU().
+	Workers int
+
+	// DBPath is the shared SQLite output every job's ParseFunc writes to.
+	DBPath string
+
+	// Output receives per-job progress/log lines, interleaved safely since
+	// ipc.Output is mutex-guarded - the "single merged progress stream"
+	// callers get instead of N parsers each logging independently. Nil
+	// means silent.
+	Output *ipc.Output
+}
+
+// FailedJob is one demo that failed to parse.
+type FailedJob struct {
+	Job Job
+	Err error
+}
+
+// Result summarizes a completed batch.
+type Result struct {
+	Succeeded int
+	Failed    []FailedJob
+}
+
+// BatchRunner runs many demos through a ParseFunc concurrently, bounded by
+// Config.Workers, against one shared SQLite output database.
+type BatchRunner struct {
+	cfg Config
+}
+
+// NewBatchRunner creates a BatchRunner from cfg.
+func NewBatchRunner(cfg Config) *BatchRunner {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	return &BatchRunner{cfg: cfg}
+}
+
+// ExpandDemoPaths resolves pattern to a sorted list of .dem files: pattern
+// may be a glob (e.g. "demos/*.dem") or a plain directory, in which case
+// every top-level *.dem file in it is included.
+func ExpandDemoPaths(pattern string) ([]string, error) {
+	info, err := os.Stat(pattern)
+	if err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*.dem")
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline: invalid demo glob %q: %w", pattern, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// Run parses every job in jobs with up to Config.Workers parseOne calls
+// running concurrently, sharing one *sql.DB opened against Config.DBPath.
+// Capping that connection to a single open connection forces database/sql
+// to serialize every query against it, so concurrent workers never
+// collide on SQLite's single file-level write lock and see SQLITE_BUSY.
+func (r *BatchRunner) Run(ctx context.Context, jobs []Job, parseOne ParseFunc) (*Result, error) {
+	dbConn, err := db.Open(ctx, r.cfg.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline: failed to open batch output database: %w", err)
+	}
+	defer dbConn.Close()
+	dbConn.SetMaxOpenConns(1)
+
+	writer := db.NewWriter(dbConn)
+
+	jobCh := make(chan Job)
+	go func() {
+		defer close(jobCh)
+		for _, job := range jobs {
+			if job.MatchID == "" {
+				base := filepath.Base(job.DemoPath)
+				job.MatchID = base[:len(base)-len(filepath.Ext(base))]
+			}
+			select {
+			case jobCh <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	type outcome struct {
+		job Job
+		err error
+	}
+	outcomeCh := make(chan outcome, len(jobs))
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				err := r.runOne(ctx, dbConn, writer, job, parseOne)
+				outcomeCh <- outcome{job: job, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(outcomeCh)
+	}()
+
+	result := &Result{}
+	total := len(jobs)
+	done := 0
+	for o := range outcomeCh {
+		done++
+		if o.err != nil {
+			result.Failed = append(result.Failed, FailedJob{Job: o.job, Err: o.err})
+		} else {
+			result.Succeeded++
+		}
+		r.logProgress(done, total, o.job, o.err)
+	}
+
+	return result, nil
+}
+
+// runOne runs parseOne for job, recovering a panic into an error so one bad
+// demo can't take the whole worker pool down, and persisting any failure
+// to failed_demos instead of propagating it to the caller.
+func (r *BatchRunner) runOne(ctx context.Context, dbConn *sql.DB, writer *db.Writer, job Job, parseOne ParseFunc) (runErr error) {
+	defer func() {
+		if p := recover(); p != nil {
+			runErr = fmt.Errorf("panic: %v", p)
+		}
+		if runErr != nil {
+			if recErr := writer.RecordFailedDemo(ctx, job.DemoPath, runErr.Error(), string(debug.Stack())); recErr != nil && r.cfg.Output != nil {
+				r.cfg.Output.Log("warn", fmt.Sprintf("Failed to record failure for %s: %v", job.DemoPath, recErr))
+			}
+		}
+	}()
+
+	return parseOne(ctx, dbConn, job)
+}
+
+func (r *BatchRunner) logProgress(done, total int, job Job, err error) {
+	if r.cfg.Output == nil {
+		return
+	}
+	if err != nil {
+		r.cfg.Output.Log("warn", fmt.Sprintf("[%d/%d] failed: %s: %v", done, total, job.DemoPath, err))
+	} else {
+		r.cfg.Output.Log("info", fmt.Sprintf("[%d/%d] done: %s", done, total, job.DemoPath))
+	}
+}
@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"cs-griefer-electron/internal/db"
+)
+
+func TestBatchRunnerRunRecordsSuccessesAndFailures(t *testing.T) {
+	dbConn, err := db.Open(context.Background(), t.TempDir()+"/batch.db")
+	if err != nil {
+		t.Fatalf("db.Open: %v", err)
+	}
+	defer dbConn.Close()
+
+	jobs := []Job{
+		{DemoPath: "match1.dem"},
+		{DemoPath: "match2.dem"},
+		{DemoPath: "match3.dem"},
+	}
+
+	parseOne := func(ctx context.Context, conn *sql.DB, job Job) error {
+		if job.DemoPath == "match2.dem" {
+			return fmt.Errorf("boom")
+		}
+		return nil
+	}
+
+	runner := NewBatchRunner(Config{Workers: 3, DBPath: t.TempDir() + "/unused.db"})
+	result, err := runner.Run(context.Background(), jobs, parseOne)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Succeeded != 2 {
+		t.Errorf("Succeeded = %d, want 2", result.Succeeded)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Job.DemoPath != "match2.dem" {
+		t.Errorf("Failed = %+v, want one failure for match2.dem", result.Failed)
+	}
+}
+
+func TestBatchRunnerRunRecoversWorkerPanic(t *testing.T) {
+	dbPath := t.TempDir() + "/batch.db"
+	jobs := []Job{
+		{DemoPath: "panics.dem"},
+		{DemoPath: "fine.dem"},
+	}
+
+	parseOne := func(ctx context.Context, conn *sql.DB, job Job) error {
+		if job.DemoPath == "panics.dem" {
+			panic("simulated parser panic")
+		}
+		return nil
+	}
+
+	runner := NewBatchRunner(Config{Workers: 2, DBPath: dbPath})
+	result, err := runner.Run(context.Background(), jobs, parseOne)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Succeeded != 1 {
+		t.Errorf("Succeeded = %d, want 1 (the panic must not take the other job down)", result.Succeeded)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Job.DemoPath != "panics.dem" {
+		t.Fatalf("Failed = %+v, want one failure for panics.dem", result.Failed)
+	}
+
+	dbConn, err := db.Open(context.Background(), dbPath)
+	if err != nil {
+		t.Fatalf("db.Open: %v", err)
+	}
+	defer dbConn.Close()
+	var count int
+	if err := dbConn.QueryRow("SELECT COUNT(*) FROM failed_demos WHERE demo_path = ?", "panics.dem").Scan(&count); err != nil {
+		t.Fatalf("querying failed_demos: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("failed_demos rows for panics.dem = %d, want 1", count)
+	}
+}
+
+func TestBatchRunnerRunParallelizesAcrossWorkers(t *testing.T) {
+	const workers = 4
+	const jobCount = 20
+
+	jobs := make([]Job, jobCount)
+	for i := range jobs {
+		jobs[i] = Job{DemoPath: fmt.Sprintf("match%d.dem", i)}
+	}
+
+	var mu sync.Mutex
+	inFlight := 0
+	maxInFlight := 0
+	release := make(chan struct{})
+	var once sync.Once
+
+	parseOne := func(ctx context.Context, conn *sql.DB, job Job) error {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		atMax := inFlight == workers
+		mu.Unlock()
+
+		if atMax {
+			once.Do(func() { close(release) })
+		}
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+			t.Errorf("never observed %d concurrent parseOne calls; Workers is not bounding/allowing concurrency as expected", workers)
+			once.Do(func() { close(release) })
+		}
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return nil
+	}
+
+	runner := NewBatchRunner(Config{Workers: workers, DBPath: t.TempDir() + "/batch.db"})
+	result, err := runner.Run(context.Background(), jobs, parseOne)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Succeeded != jobCount {
+		t.Errorf("Succeeded = %d, want %d", result.Succeeded, jobCount)
+	}
+	if maxInFlight != workers {
+		t.Errorf("max concurrent parseOne calls = %d, want %d (Workers should bound concurrency, not serialize it)", maxInFlight, workers)
+	}
+}
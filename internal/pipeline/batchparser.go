@@ -0,0 +1,265 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"cs-griefer-electron/internal/parser"
+)
+
+// DemoResult is one demo's outcome from BatchParser.Run.
+type DemoResult struct {
+	Path string
+	Data *parser.MatchData
+	Err  error
+}
+
+// ProgressUpdate is one BatchParser Progress channel event, sent whenever
+// a demo starts, makes parsing progress, or finishes.
+type ProgressUpdate struct {
+	Path       string
+	BytesRead  int64
+	TotalBytes int64
+	Err        error
+}
+
+// Sink receives each demo's result as it completes, for a caller that
+// wants to stream results (e.g. straight into columnar.WriteParquet)
+// instead of holding every demo's MatchData in memory at once on top of
+// BatchParser's own in-flight byte budget.
+type Sink interface {
+	Accept(path string, data *parser.MatchData, err error) error
+}
+
+// BatchParserConfig configures a BatchParser.
+type BatchParserConfig struct {
+	// Workers bounds how many demos parse concurrently. <= 0 defaults to
+	// runtime.NumCPU(), same as BatchRunner.Config.Workers.
+	Workers int
+
+	// MaxConcurrentBytes caps the combined file size of demos being
+	// parsed at once, since each demoinfocs parser holds its whole demo
+	// in memory (see parser.Parser.Close's comment) - Workers
+	// alone would still let that many huge demos run at once and exhaust
+	// memory. <= 0 means unbounded, relying on Workers alone.
+	MaxConcurrentBytes int64
+
+	// Progress, if non-nil, receives a ProgressUpdate as each demo starts
+	// and as its ParseCallback fires. Sends are non-blocking - a slow or
+	// unbuffered Progress channel only drops updates, it never stalls
+	// parsing. Never closed by BatchParser.
+	Progress chan<- ProgressUpdate
+
+	// Sink, if non-nil, receives each demo's result as it completes and
+	// Run returns a nil []DemoResult - the caller chose to stream instead
+	// of accumulate. If nil, Run accumulates and returns every
+	// DemoResult.
+	Sink Sink
+}
+
+// BatchParser parses many demos concurrently via parser.Parse, bounded by
+// Config.Workers and, optionally, a combined in-flight byte budget
+// (Config.MaxConcurrentBytes). It's the in-memory-result analogue to
+// BatchRunner, which instead writes straight to one shared SQLite
+// connection; BatchParser suits ad-hoc analysis over full MatchData
+// (optionally streamed to a Sink) rather than batch-to-SQLite ingestion.
+type BatchParser struct {
+	cfg BatchParserConfig
+}
+
+// NewBatchParser creates a BatchParser from cfg.
+func NewBatchParser(cfg BatchParserConfig) *BatchParser {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	return &BatchParser{cfg: cfg}
+}
+
+// Run parses every path in paths, returning one DemoResult per path in
+// completion order (or nil, if Config.Sink is set - see its doc
+// comment). ctx cancellation stops scheduling new demos and unblocks any
+// demo waiting on the byte budget, but lets already-started demos finish.
+// If Config.Sink is set, Run's returned []DemoResult is always nil, but
+// any error Sink.Accept returns is joined (errors.Join) into Run's error
+// instead of being dropped.
+func (b *BatchParser) Run(ctx context.Context, paths []string) ([]DemoResult, error) {
+	type job struct {
+		path string
+		size int64
+	}
+	jobs := make([]job, len(paths))
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline: failed to stat %q: %w", path, err)
+		}
+		jobs[i] = job{path: path, size: info.Size()}
+	}
+
+	budget := newByteBudget(b.cfg.MaxConcurrentBytes)
+
+	jobCh := make(chan job)
+	go func() {
+		defer close(jobCh)
+		for _, j := range jobs {
+			select {
+			case jobCh <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		results  []DemoResult
+		sinkErrs []error
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < b.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobCh {
+				if !budget.acquire(ctx, j.size) {
+					return
+				}
+				result := b.parseOne(ctx, j.path, j.size)
+				budget.release(j.size)
+
+				if b.cfg.Sink != nil {
+					if err := b.cfg.Sink.Accept(j.path, result.Data, result.Err); err != nil {
+						mu.Lock()
+						sinkErrs = append(sinkErrs, fmt.Errorf("pipeline: sink rejected %s: %w", j.path, err))
+						mu.Unlock()
+					}
+					continue
+				}
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if b.cfg.Sink != nil {
+		return nil, errors.Join(sinkErrs...)
+	}
+	return results, nil
+}
+
+// parseOne parses path, recovering a panic into an error the same way
+// BatchRunner.runOne does so one bad demo can't take the whole worker
+// pool down. The inner Parser's Close() always runs via defer, including
+// on panic, relying on Close's own "already closed" tolerance if a panic
+// happens mid-parse.
+func (b *BatchParser) parseOne(ctx context.Context, path string, size int64) (result DemoResult) {
+	result.Path = path
+	b.reportProgress(ProgressUpdate{Path: path, TotalBytes: size})
+
+	defer func() {
+		if p := recover(); p != nil {
+			result.Err = fmt.Errorf("panic parsing %s: %v\n%s", path, p, debug.Stack())
+		}
+		b.reportProgress(ProgressUpdate{Path: path, BytesRead: size, TotalBytes: size, Err: result.Err})
+	}()
+
+	p, err := parser.NewParser(path)
+	if err != nil {
+		result.Err = fmt.Errorf("pipeline: failed to open %s: %w", path, err)
+		return result
+	}
+	defer p.Close()
+
+	data, err := p.Parse(ctx, func(stage string, tick, round int, pct float64) {
+		b.reportProgress(ProgressUpdate{Path: path, BytesRead: int64(pct * float64(size)), TotalBytes: size})
+	})
+	if err != nil {
+		result.Err = fmt.Errorf("pipeline: failed to parse %s: %w", path, err)
+		return result
+	}
+	result.Data = data
+	return result
+}
+
+func (b *BatchParser) reportProgress(update ProgressUpdate) {
+	if b.cfg.Progress == nil {
+		return
+	}
+	select {
+	case b.cfg.Progress <- update:
+	default:
+	}
+}
+
+// byteBudget gates concurrent demo parsing by combined in-flight file
+// size, since Workers alone would still let that many huge demos run at
+// once and exhaust memory (each demoinfocs parser holds its whole demo in
+// memory). limit <= 0 means unbounded - acquire always succeeds
+// immediately.
+type byteBudget struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     int64
+	available int64
+}
+
+func newByteBudget(limit int64) *byteBudget {
+	b := &byteBudget{limit: limit, available: limit}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// acquire blocks until size bytes of budget are free, returning false if
+// ctx is cancelled first (true immediately if limit <= 0). A single demo
+// larger than the whole limit is still let through once every other demo
+// has released its budget, rather than deadlocking forever.
+func (b *byteBudget) acquire(ctx context.Context, size int64) bool {
+	if b.limit <= 0 {
+		return true
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.mu.Lock()
+			b.cond.Broadcast()
+			b.mu.Unlock()
+		case <-done:
+		}
+	}()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.available < size && b.available != b.limit {
+		if ctx.Err() != nil {
+			return false
+		}
+		b.cond.Wait()
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	b.available -= size
+	return true
+}
+
+// release returns size bytes to the budget and wakes any acquire waiting
+// on it.
+func (b *byteBudget) release(size int64) {
+	if b.limit <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.available += size
+	b.cond.Broadcast()
+	b.mu.Unlock()
+}
@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"cs-griefer-electron/internal/parser"
+)
+
+// writeFakeDemo writes a non-empty .dem file that satisfies
+// parser.NewParser's validation (non-empty, .dem extension) but isn't a
+// real demoinfocs-parseable recording, so parser.Parse always fails it
+// with a non-nil error - exactly the failure path these tests exercise.
+func writeFakeDemo(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("not a real demo"), 0o644); err != nil {
+		t.Fatalf("failed to write fake demo %q: %v", name, err)
+	}
+	return path
+}
+
+// errSink is a Sink whose Accept always fails, for exercising
+// BatchParser.Run's error-surfacing path.
+type errSink struct {
+	mu       sync.Mutex
+	accepted []string
+}
+
+func (s *errSink) Accept(path string, data *parser.MatchData, err error) error {
+	s.mu.Lock()
+	s.accepted = append(s.accepted, path)
+	s.mu.Unlock()
+	return fmt.Errorf("sink rejected %s", path)
+}
+
+func TestBatchParserRunSurfacesSinkErrors(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{
+		writeFakeDemo(t, dir, "match1.dem"),
+		writeFakeDemo(t, dir, "match2.dem"),
+	}
+
+	sink := &errSink{}
+	bp := NewBatchParser(BatchParserConfig{Workers: 2, Sink: sink})
+
+	results, err := bp.Run(context.Background(), paths)
+	if results != nil {
+		t.Errorf("results = %v, want nil when Config.Sink is set", results)
+	}
+	if err == nil {
+		t.Fatal("expected Run to surface the Sink's Accept errors, got nil")
+	}
+
+	sink.mu.Lock()
+	accepted := len(sink.accepted)
+	sink.mu.Unlock()
+	if accepted != len(paths) {
+		t.Errorf("Sink.Accept called %d times, want %d (one per job)", accepted, len(paths))
+	}
+}
+
+func TestBatchParserRunWithoutSinkAccumulatesResults(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{
+		writeFakeDemo(t, dir, "match1.dem"),
+		writeFakeDemo(t, dir, "match2.dem"),
+		writeFakeDemo(t, dir, "match3.dem"),
+	}
+
+	bp := NewBatchParser(BatchParserConfig{Workers: 3})
+
+	results, err := bp.Run(context.Background(), paths)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != len(paths) {
+		t.Fatalf("got %d results, want %d", len(results), len(paths))
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			t.Errorf("result for %s: want a parse error for a fake demo file, got nil", result.Path)
+		}
+	}
+}
+
+func TestBatchParserRunMissingPathFailsFast(t *testing.T) {
+	bp := NewBatchParser(BatchParserConfig{Workers: 1})
+	if _, err := bp.Run(context.Background(), []string{"/nonexistent/match.dem"}); err == nil {
+		t.Error("expected Run to fail stat-ing a nonexistent demo path")
+	}
+}
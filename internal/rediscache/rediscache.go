@@ -0,0 +1,220 @@
+// Package rediscache is a minimal Redis client used to cache hot per-match
+// reads (player scores, round summaries) and to deduplicate demo ingestion
+// across parser workers via SETNX. There's no real client library vendored
+// in this module, but RESP (REdis Serialization Protocol) is simple enough
+// to hand-roll the handful of commands this package needs - see also
+// internal/ipc/broker/redis.go, which does the same for PUBLISH/AUTH.
+package rediscache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dialTimeout = 5 * time.Second
+
+// Client is a synchronous RESP client guarded by a mutex: the cache reads
+// this package serves are infrequent enough (one per hot API request, one
+// per parse) that a single shared connection is simpler than a pool, and
+// correctness (no interleaved replies) matters more than throughput here.
+type Client struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Dial opens a connection to a Redis server at rawURL, e.g.
+// "redis://[user:pass@]host:port" (db selection via "/N" is not supported -
+// this package only ever targets db 0). Dial returns a nil *Client only
+// together with a non-nil error; callers that want a no-op cache should simply not call Dial and
+// instead leave their *Client nil - every method here is a safe no-op on a
+// nil receiver.
+func Dial(rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("rediscache: invalid URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("rediscache: unsupported scheme %q (want redis://)", u.Scheme)
+	}
+
+	addr := u.Host
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("rediscache: dial %s: %w", addr, err)
+	}
+
+	c := &Client{conn: conn, reader: bufio.NewReader(conn)}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			var authErr error
+			if username := u.User.Username(); username != "" {
+				_, authErr = c.command("AUTH", username, pass)
+			} else {
+				_, authErr = c.command("AUTH", pass)
+			}
+			if authErr != nil {
+				conn.Close()
+				return nil, fmt.Errorf("rediscache: auth: %w", authErr)
+			}
+		}
+	}
+	return c, nil
+}
+
+// Close closes the underlying connection. A no-op on a nil *Client.
+func (c *Client) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Get returns the value stored at key, or ok=false if it doesn't exist. A
+// no-op (ok=false, err=nil) on a nil *Client, so callers can treat "no cache
+// configured" the same as "cache miss".
+func (c *Client) Get(key string) (value []byte, ok bool, err error) {
+	if c == nil {
+		return nil, false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reply, err := c.command("GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	b, isBulk := reply.([]byte)
+	if !isBulk {
+		return nil, false, fmt.Errorf("rediscache: GET returned unexpected reply type %T", reply)
+	}
+	return b, true, nil
+}
+
+// Set stores value at key, expiring after ttl (0 means no expiry). A no-op
+// on a nil *Client.
+func (c *Client) Set(key string, value []byte, ttl time.Duration) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "EX", strconv.Itoa(int(ttl.Seconds())))
+	}
+	_, err := c.command(args...)
+	return err
+}
+
+// SetNX atomically sets key to value (expiring after ttl, if positive) only
+// if key doesn't already exist, reporting whether it did the set. Used to
+// turn "has this demo already been ingested" into a single round trip that
+// stays correct when multiple parser workers race on the same demo. A no-op
+// (set=false, err=nil) on a nil *Client.
+func (c *Client) SetNX(key string, value []byte, ttl time.Duration) (set bool, err error) {
+	if c == nil {
+		return false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	args := []string{"SET", key, string(value), "NX"}
+	if ttl > 0 {
+		args = append(args, "EX", strconv.Itoa(int(ttl.Seconds())))
+	}
+	reply, err := c.command(args...)
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+// command writes args as a RESP array of bulk strings and returns the
+// parsed reply: nil for a nil bulk/array reply, []byte for a bulk string,
+// string for a simple string, int64 for an integer.
+func (c *Client) command(args ...string) (interface{}, error) {
+	var buf []byte
+	buf = append(buf, []byte("*"+strconv.Itoa(len(args))+"\r\n")...)
+	for _, a := range args {
+		buf = append(buf, []byte("$"+strconv.Itoa(len(a))+"\r\n")...)
+		buf = append(buf, a...)
+		buf = append(buf, '\r', '\n')
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, fmt.Errorf("rediscache: write: %w", err)
+	}
+	return c.readReply()
+}
+
+// readReply parses a single RESP reply: a simple string (+), error (-),
+// integer (:), bulk string ($, or $-1 for nil), or array (*, used only for
+// MULTI/EXEC-style replies this package doesn't otherwise issue).
+func (c *Client) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("rediscache: read reply: %w", err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("rediscache: empty reply line")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("rediscache: %s", line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("rediscache: invalid integer reply %q: %w", line, err)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("rediscache: invalid bulk length %q: %w", line, err)
+		}
+		if n < 0 {
+			return nil, nil // nil bulk reply, e.g. GET on a missing key
+		}
+		data := make([]byte, n+2) // +2 for the trailing \r\n
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, fmt.Errorf("rediscache: read bulk payload: %w", err)
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("rediscache: invalid array length %q: %w", line, err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		arr := make([]interface{}, n)
+		for i := 0; i < n; i++ {
+			arr[i], err = c.readReply()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("rediscache: unrecognized reply prefix %q", line[0])
+	}
+}
@@ -0,0 +1,230 @@
+package rediscache
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRedisServer is a tiny in-memory RESP server implementing just enough
+// of GET/SET/SET..NX/AUTH to exercise Client against a real TCP connection
+// instead of mocking the Client's own wire format.
+type fakeRedisServer struct {
+	ln   net.Listener
+	data map[string]string
+}
+
+func startFakeRedisServer(t *testing.T) *fakeRedisServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake redis server: %v", err)
+	}
+	srv := &fakeRedisServer{ln: ln, data: make(map[string]string)}
+	go srv.serve(t)
+	t.Cleanup(func() { ln.Close() })
+	return srv
+}
+
+func (s *fakeRedisServer) addr() string { return s.ln.Addr().String() }
+
+func (s *fakeRedisServer) serve(t *testing.T) {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(t, conn)
+	}
+}
+
+func (s *fakeRedisServer) handleConn(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		reply := s.dispatch(args)
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("expected array header, got %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := 0; i < n; i++ {
+		lenLine, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		lenLine = strings.TrimRight(lenLine, "\r\n")
+		blen, err := strconv.Atoi(strings.TrimPrefix(lenLine, "$"))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, blen+2)
+		total := 0
+		for total < len(buf) {
+			n, err := r.Read(buf[total:])
+			if err != nil {
+				return nil, err
+			}
+			total += n
+		}
+		args[i] = string(buf[:blen])
+	}
+	return args, nil
+}
+
+func (s *fakeRedisServer) dispatch(args []string) string {
+	if len(args) == 0 {
+		return "-ERR empty command\r\n"
+	}
+	switch strings.ToUpper(args[0]) {
+	case "AUTH":
+		return "+OK\r\n"
+	case "GET":
+		v, ok := s.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "SET":
+		key, value := args[1], args[2]
+		nx := false
+		for _, opt := range args[3:] {
+			if strings.ToUpper(opt) == "NX" {
+				nx = true
+			}
+		}
+		if nx {
+			if _, exists := s.data[key]; exists {
+				return "$-1\r\n"
+			}
+		}
+		s.data[key] = value
+		return "+OK\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command %q\r\n", args[0])
+	}
+}
+
+func TestClientSetAndGet(t *testing.T) {
+	srv := startFakeRedisServer(t)
+	c, err := Dial("redis://" + srv.addr())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Set("match:m1:scores", []byte(`{"players":[]}`), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	value, ok, err := c.Get("match:m1:scores")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected Get to find the key that was just Set")
+	}
+	if string(value) != `{"players":[]}` {
+		t.Errorf("Get returned %q, want %q", value, `{"players":[]}`)
+	}
+}
+
+func TestClientGetMissingKey(t *testing.T) {
+	srv := startFakeRedisServer(t)
+	c, err := Dial("redis://" + srv.addr())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer c.Close()
+
+	_, ok, err := c.Get("does-not-exist")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if ok {
+		t.Error("expected ok=false for a missing key")
+	}
+}
+
+func TestClientSetNX(t *testing.T) {
+	srv := startFakeRedisServer(t)
+	c, err := Dial("redis://" + srv.addr())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer c.Close()
+
+	set, err := c.SetNX("demo:abc123:matchid", []byte("match-1"), time.Hour)
+	if err != nil {
+		t.Fatalf("SetNX failed: %v", err)
+	}
+	if !set {
+		t.Fatal("expected the first SetNX on a fresh key to succeed")
+	}
+
+	set, err = c.SetNX("demo:abc123:matchid", []byte("match-2"), time.Hour)
+	if err != nil {
+		t.Fatalf("SetNX failed: %v", err)
+	}
+	if set {
+		t.Fatal("expected SetNX to report false once the key already exists")
+	}
+
+	value, ok, err := c.Get("demo:abc123:matchid")
+	if err != nil || !ok {
+		t.Fatalf("Get after SetNX race = (%q, %v, %v)", value, ok, err)
+	}
+	if string(value) != "match-1" {
+		t.Errorf("expected the original value to survive the losing SetNX, got %q", value)
+	}
+}
+
+func TestDialUnsupportedScheme(t *testing.T) {
+	if _, err := Dial("memcache://localhost:11211"); err == nil {
+		t.Fatal("expected an error for an unsupported scheme")
+	}
+}
+
+func TestDialInvalidURL(t *testing.T) {
+	if _, err := Dial("redis://%zz"); err == nil {
+		t.Fatal("expected an error for an unparseable url")
+	}
+}
+
+func TestNilClientIsNoOp(t *testing.T) {
+	var c *Client
+	if _, ok, err := c.Get("k"); ok || err != nil {
+		t.Errorf("nil Client.Get = (_, %v, %v), want (_, false, nil)", ok, err)
+	}
+	if err := c.Set("k", []byte("v"), time.Minute); err != nil {
+		t.Errorf("nil Client.Set = %v, want nil", err)
+	}
+	if set, err := c.SetNX("k", []byte("v"), time.Minute); set || err != nil {
+		t.Errorf("nil Client.SetNX = (%v, %v), want (false, nil)", set, err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("nil Client.Close = %v, want nil", err)
+	}
+}
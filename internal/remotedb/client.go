@@ -0,0 +1,164 @@
+package remotedb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// Client is the read-side counterpart to Store: it mirrors db.Reader's
+// query methods (and adds StreamProgress) against a remote Server, for a
+// tool that wants to inspect a match an analyzer process is holding
+// without opening its SQLite/Postgres backend directly.
+type Client struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewClient creates a Client that talks to cfg.BaseURL.
+func NewClient(cfg Config) *Client {
+	return &Client{cfg: cfg, client: cfg.httpClient()}
+}
+
+// GetPlayerScores mirrors db.Reader.GetPlayerScores.
+func (c *Client) GetPlayerScores(ctx context.Context, matchID string) ([]db.PlayerScore, error) {
+	var scores []db.PlayerScore
+	err := c.get(ctx, "/v1/matches/"+url.PathEscape(matchID)+"/scores", nil, &scores)
+	return scores, err
+}
+
+// GetEvents mirrors db.Reader.GetEvents; q.MatchID selects the path, every
+// other field becomes a query parameter.
+func (c *Client) GetEvents(ctx context.Context, q db.EventQuery) ([]db.Event, error) {
+	params := url.Values{}
+	if q.Type != nil {
+		params.Set("type", *q.Type)
+	}
+	if q.SteamID != nil {
+		params.Set("steamid", *q.SteamID)
+	}
+	if q.Round != nil {
+		params.Set("round", strconv.Itoa(*q.Round))
+	}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		params.Set("offset", strconv.Itoa(q.Offset))
+	}
+
+	var events []db.Event
+	err := c.get(ctx, "/v1/matches/"+url.PathEscape(q.MatchID)+"/events", params, &events)
+	return events, err
+}
+
+// GetRounds mirrors db.Reader.GetRounds.
+func (c *Client) GetRounds(ctx context.Context, matchID string) ([]db.Round, error) {
+	var rounds []db.Round
+	err := c.get(ctx, "/v1/matches/"+url.PathEscape(matchID)+"/rounds", nil, &rounds)
+	return rounds, err
+}
+
+// GetChatMessages mirrors db.Reader.GetChatMessages; a nil steamID returns
+// every player's chat, same as passing nil there.
+func (c *Client) GetChatMessages(ctx context.Context, matchID string, steamID *string) ([]db.ChatMessage, error) {
+	var params url.Values
+	if steamID != nil {
+		params = url.Values{"steamid": []string{*steamID}}
+	}
+
+	var messages []db.ChatMessage
+	err := c.get(ctx, "/v1/matches/"+url.PathEscape(matchID)+"/chat", params, &messages)
+	return messages, err
+}
+
+// UpsertMatch sends matchID's Match row to the remote Server, the same RPC
+// Store.UpsertMatch uses - Client exposes it too since a tool reading a
+// match's data over remotedb may also be the one that registered it.
+func (c *Client) UpsertMatch(ctx context.Context, m db.Match) error {
+	return doJSON(ctx, c.client, c.cfg, http.MethodPost, "/v1/matches", m, nil)
+}
+
+// get issues a GET to path with params appended as a query string,
+// decoding the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, params url.Values, out any) error {
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return doJSON(ctx, c.client, c.cfg, http.MethodGet, path, nil, out)
+}
+
+// ProgressStream is a long-lived connection that streams progress updates
+// to a Server, mirroring ipc.Output.Progress's shape but over the network
+// instead of stdout - see Client.StreamProgress.
+type ProgressStream struct {
+	enc    *json.Encoder
+	pw     *io.PipeWriter
+	result chan error
+}
+
+// progressMessage is one line of the NDJSON body ProgressStream writes and
+// Server.handleProgress reads, field-for-field identical to
+// ipc.Output.Progress's payload.
+type progressMessage struct {
+	Stage string  `json:"stage"`
+	Tick  int     `json:"tick"`
+	Round int     `json:"round"`
+	Pct   float64 `json:"pct"`
+}
+
+// StreamProgress opens a streaming POST to the remote Server and returns a
+// ProgressStream that Send can be called on repeatedly; the request body
+// is newline-delimited JSON, read incrementally by the server instead of
+// buffered, the same way internal/ipc.Output writes one progress line at a
+// time rather than accumulating them. Call Close when done.
+func (c *Client) StreamProgress(ctx context.Context, matchID string) (*ProgressStream, error) {
+	pr, pw := io.Pipe()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/v1/matches/"+url.PathEscape(matchID)+"/progress", pr)
+	if err != nil {
+		pw.Close()
+		return nil, fmt.Errorf("remotedb: building progress stream request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if bearer := authBearer(c.cfg.AuthToken); bearer != "" {
+		req.Header.Set(AuthHeader, bearer)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		resp, err := c.client.Do(req)
+		if err != nil {
+			result <- fmt.Errorf("remotedb: streaming progress: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			var apiErr errorResponse
+			_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+			result <- fmt.Errorf("remotedb: streaming progress: status %d: %s", resp.StatusCode, apiErr.Error)
+			return
+		}
+		result <- nil
+	}()
+
+	return &ProgressStream{enc: json.NewEncoder(pw), pw: pw, result: result}, nil
+}
+
+// Send writes one progress update to the stream, matching
+// ipc.Output.Progress's parameters.
+func (s *ProgressStream) Send(stage string, tick, round int, pct float64) error {
+	return s.enc.Encode(progressMessage{Stage: stage, Tick: tick, Round: round, Pct: pct})
+}
+
+// Close finishes the stream and waits for the server's final response.
+func (s *ProgressStream) Close() error {
+	s.pw.Close()
+	return <-s.result
+}
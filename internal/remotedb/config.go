@@ -0,0 +1,48 @@
+package remotedb
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Config configures a Store, Client or Server. The zero value is a usable
+// (unauthenticated, plain HTTP, default timeout) configuration pointed at
+// BaseURL - every other field is an optional hardening knob, the same
+// "empty means off" convention internal/webhooks.Config and
+// internal/exporters/tsdb.Config already use.
+type Config struct {
+	// BaseURL is the remote analyzer's address, e.g. "https://analyzer:8443".
+	// Required for Store/Client; ignored by Server (which is the listener).
+	BaseURL string
+
+	// AuthToken is sent as "Authorization: Bearer <token>" on every request
+	// a Store/Client makes, and is what Server.withAuth compares
+	// incoming requests against. Empty disables auth on both ends - fine
+	// for a trusted network, not for anything exposed publicly.
+	AuthToken string
+
+	// TLSClientConfig configures a Store/Client's transport, e.g. to trust
+	// a self-signed cert via RootCAs. Nil uses http.DefaultTransport's
+	// usual system trust store.
+	TLSClientConfig *tls.Config
+
+	// TLSCertFile/TLSKeyFile are not read by Server (NewServer takes no
+	// Config); cmd/parser/remotedb.go's runRemoteDBServer takes its own
+	// cert/key paths and, if both are set, serves HTTPS via
+	// http.Server.ListenAndServeTLS instead of plain HTTP.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Timeout bounds a single RPC. Defaults to defaultTimeout.
+	Timeout time.Duration
+}
+
+// httpClient builds the *http.Client a Store/Client issues RPCs with.
+func (c Config) httpClient() *http.Client {
+	var transport http.RoundTripper
+	if c.TLSClientConfig != nil {
+		transport = &http.Transport{TLSClientConfig: c.TLSClientConfig}
+	}
+	return newHTTPClient(c.Timeout, transport)
+}
@@ -0,0 +1,67 @@
+// Package remotedb lets several parser workers stream events and
+// positions into one central analyzer over the network instead of each
+// producing its own SQLite file that has to be merged after the fact.
+//
+// The request this package implements asked for a gRPC service (proto
+// methods InsertEvents, InsertPositions, UpsertMatch, GetPlayerScores,
+// GetEvents, GetRounds, GetChatMessages, plus a streaming StreamProgress).
+// This repo vendors google.golang.org/protobuf but not
+// google.golang.org/grpc (see go.mod), and there's no protoc available to
+// generate stubs from a .proto file offline. So instead of faking that
+// dependency, remotedb exposes the same RPCs as plain JSON-over-HTTP,
+// following the same pattern cmd/parser/apiserver.go and internal/httpapi
+// already use for this repo's other network-facing component: one
+// http.Server, one *http.Client, newline-delimited JSON for the streaming
+// call (mirroring how internal/ipc.Output already streams progress over
+// stdout).
+//
+// Store implements db.Store (see internal/db/store.go) by POSTing to a
+// Server, so it's a drop-in alternative to db.SQLiteStore/db.PostgresStore
+// wherever pipeline.Config accepts a Store. Client adds the read-side
+// calls (GetPlayerScores, GetEvents, GetRounds, GetChatMessages) that
+// db.Store has no equivalent for, plus StreamProgress.
+//
+// Out of scope: a generic database/sql driver.Conn proxy that would let
+// extractors keep calling e.db.Query(...) unmodified against a remote
+// connection. That needs to tunnel arbitrary SQL (and driver-specific
+// result decoding) over the wire, which is a much bigger surface than
+// this repo's extractors actually need - they only ever go through
+// db.Writer/db.Reader's typed methods. An extractor that wants remote
+// storage should be wired against the db.Store interface instead, the way
+// internal/db/postgres_store.go already is for Postgres.
+package remotedb
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultTimeout bounds a single RPC's round trip, matching
+	// internal/webhooks' default HTTP client timeout.
+	defaultTimeout = 10 * time.Second
+
+	// AuthHeader carries the shared token both Store/Client and Server
+	// agree on in place of gRPC's per-call metadata headers.
+	AuthHeader = "Authorization"
+)
+
+// authBearer formats token the way Server expects to find it in
+// AuthHeader: "Bearer <token>". An empty token means auth is disabled.
+func authBearer(token string) string {
+	if token == "" {
+		return ""
+	}
+	return "Bearer " + token
+}
+
+// newHTTPClient builds the *http.Client a Store/Client uses for its RPCs.
+// transport is nil for a plain http:// remote (http.DefaultTransport); for
+// https:// pass an *http.Transport carrying a *tls.Config (e.g. with a
+// RootCAs pool for a self-signed analyzer cert), as Config.httpClient does.
+func newHTTPClient(timeout time.Duration, transport http.RoundTripper) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
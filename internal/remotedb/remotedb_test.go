@@ -0,0 +1,151 @@
+package remotedb
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"testing"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// startTestServer opens a fresh SQLite database and wraps it in a Server
+// behind an httptest.Server, so Store/Client are exercised against the
+// real wire format instead of a mock.
+func startTestServer(t *testing.T, authToken string) (*httptest.Server, *sql.DB) {
+	t.Helper()
+	dbConn, err := db.Open(context.Background(), t.TempDir()+"/test.db")
+	if err != nil {
+		t.Fatalf("db.Open: %v", err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+
+	srv := NewServer(dbConn, authToken)
+	httpSrv := httptest.NewServer(srv.Handler())
+	t.Cleanup(httpSrv.Close)
+	return httpSrv, dbConn
+}
+
+func TestStoreInsertEventsAndClientReadsThemBack(t *testing.T) {
+	httpSrv, _ := startTestServer(t, "")
+	ctx := context.Background()
+
+	store := NewStore(Config{BaseURL: httpSrv.URL})
+	client := NewClient(Config{BaseURL: httpSrv.URL})
+
+	if err := client.UpsertMatch(ctx, db.Match{ID: "match-1", Map: "de_dust2", TickRate: 64}); err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+
+	events := []db.Event{
+		{MatchID: "match-1", RoundIndex: 0, Type: "body_block", StartTick: 100},
+		{MatchID: "match-1", RoundIndex: 1, Type: "trade_kill", StartTick: 200},
+	}
+	if err := store.InsertEvents(ctx, "match-1", events); err != nil {
+		t.Fatalf("InsertEvents: %v", err)
+	}
+
+	got, err := client.GetEvents(ctx, db.EventQuery{MatchID: "match-1"})
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2", len(got))
+	}
+}
+
+func TestStoreInsertPositions(t *testing.T) {
+	httpSrv, _ := startTestServer(t, "")
+	ctx := context.Background()
+
+	client := NewClient(Config{BaseURL: httpSrv.URL})
+	if err := client.UpsertMatch(ctx, db.Match{ID: "match-1", Map: "de_dust2", TickRate: 64}); err != nil {
+		t.Fatalf("UpsertMatch: %v", err)
+	}
+
+	store := NewStore(Config{BaseURL: httpSrv.URL})
+	positions := []db.PlayerPosition{
+		{MatchID: "match-1", RoundIndex: 0, Tick: 100, SteamID: "76561198000000000", X: 1, Y: 2, Z: 3},
+	}
+	if err := store.InsertPositions(ctx, "match-1", positions); err != nil {
+		t.Fatalf("InsertPositions: %v", err)
+	}
+}
+
+func TestServerRejectsMissingOrWrongToken(t *testing.T) {
+	httpSrv, _ := startTestServer(t, "s3cret")
+	ctx := context.Background()
+
+	// No token configured client-side.
+	client := NewClient(Config{BaseURL: httpSrv.URL})
+	if _, err := client.GetRounds(ctx, "match-1"); err == nil {
+		t.Fatal("expected an error with no auth token configured")
+	}
+
+	// Wrong token.
+	wrong := NewClient(Config{BaseURL: httpSrv.URL, AuthToken: "nope"})
+	if _, err := wrong.GetRounds(ctx, "match-1"); err == nil {
+		t.Fatal("expected an error with the wrong auth token")
+	}
+
+	// Correct token succeeds.
+	right := NewClient(Config{BaseURL: httpSrv.URL, AuthToken: "s3cret"})
+	if _, err := right.GetRounds(ctx, "match-1"); err != nil {
+		t.Fatalf("GetRounds with correct token: %v", err)
+	}
+}
+
+func TestClientStreamProgress(t *testing.T) {
+	httpSrv, _ := startTestServer(t, "")
+	ctx := context.Background()
+
+	client := NewClient(Config{BaseURL: httpSrv.URL})
+	stream, err := client.StreamProgress(ctx, "match-1")
+	if err != nil {
+		t.Fatalf("StreamProgress: %v", err)
+	}
+	if err := stream.Send("parsing", 100, 0, 0.1); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := stream.Send("parsing", 200, 1, 0.2); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestClientStreamProgressInvokesServerCallback(t *testing.T) {
+	dbConn, err := db.Open(context.Background(), t.TempDir()+"/test.db")
+	if err != nil {
+		t.Fatalf("db.Open: %v", err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+
+	srv := NewServer(dbConn, "")
+	var received []progressMessage
+	srv.OnProgress(func(matchID, stage string, tick, round int, pct float64) {
+		received = append(received, progressMessage{Stage: stage, Tick: tick, Round: round, Pct: pct})
+	})
+	httpSrv := httptest.NewServer(srv.Handler())
+	t.Cleanup(httpSrv.Close)
+
+	client := NewClient(Config{BaseURL: httpSrv.URL})
+	stream, err := client.StreamProgress(context.Background(), "match-1")
+	if err != nil {
+		t.Fatalf("StreamProgress: %v", err)
+	}
+	if err := stream.Send("parsing", 50, 0, 0.5); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("got %d progress messages, want 1", len(received))
+	}
+	if received[0].Stage != "parsing" || received[0].Tick != 50 {
+		t.Errorf("unexpected progress message: %+v", received[0])
+	}
+}
@@ -0,0 +1,265 @@
+package remotedb
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// Server is the network-facing end of remotedb: it wraps a writer/reader
+// pair over one backing database (SQLite or Postgres, same as
+// cmd/parser/apiserver.go's runAPIServer) and exposes the RPCs Store and
+// Client call. Unlike httpapi.Server (read-only), Server also accepts
+// writes, so it backs a central analyzer that several parser workers
+// stream into concurrently.
+type Server struct {
+	writer *db.Writer
+	reader *db.Reader
+
+	// authToken, if non-empty, is the bearer token every request (besides
+	// CORS preflight) must present in AuthHeader.
+	authToken string
+
+	// onProgress, if set via OnProgress, is called for each line a
+	// StreamProgress request delivers. Nil just drains the stream.
+	onProgress ProgressFunc
+}
+
+// NewServer creates a Server writing/reading through dbConn. authToken
+// disables auth when empty, the same convention Config.AuthToken uses.
+func NewServer(dbConn *sql.DB, authToken string) *Server {
+	return &Server{writer: db.NewWriter(dbConn), reader: db.NewReader(dbConn), authToken: authToken}
+}
+
+// OnProgress registers fn to be called for every progress update a
+// StreamProgress client sends, e.g. to re-emit it through the analyzer's
+// own ipc.Output. Must be called before Handler starts serving requests.
+func (s *Server) OnProgress(fn ProgressFunc) {
+	s.onProgress = fn
+}
+
+// Handler returns remotedb's RPCs as routes, wrapped in the bearer-token
+// auth check.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/init", s.handleInit)
+	mux.HandleFunc("POST /v1/matches", s.handleUpsertMatch)
+	mux.HandleFunc("POST /v1/matches/{id}/events", s.handleInsertEvents)
+	mux.HandleFunc("POST /v1/matches/{id}/positions", s.handleInsertPositions)
+	mux.HandleFunc("POST /v1/matches/{id}/progress", s.handleProgress)
+	mux.HandleFunc("GET /v1/matches/{id}/scores", s.handleGetScores)
+	mux.HandleFunc("GET /v1/matches/{id}/events", s.handleGetEvents)
+	mux.HandleFunc("GET /v1/matches/{id}/rounds", s.handleGetRounds)
+	mux.HandleFunc("GET /v1/matches/{id}/chat", s.handleGetChat)
+	return s.withAuth(mux)
+}
+
+// withAuth rejects any request that doesn't present s.authToken as
+// "Authorization: Bearer <token>". A blank s.authToken disables the check
+// entirely, matching Config.AuthToken's "empty means off" convention.
+func (s *Server) withAuth(handler http.Handler) http.Handler {
+	if s.authToken == "" {
+		return handler
+	}
+	want := authBearer(s.authToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(AuthHeader) != want {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// handleInit prepares the backend for writing. dbConn passed to NewServer
+// already had InitSchema run against it by db.Open/db.OpenPostgres, so
+// there's nothing left to do - this RPC exists so a worker that connects
+// before the analyzer has otherwise touched the database still has a
+// well-defined "am I ready" call, mirroring db.Store.Init's contract.
+func (s *Server) handleInit(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// handleUpsertMatch decodes a db.Match and writes it via db.Writer.InsertMatch.
+func (s *Server) handleUpsertMatch(w http.ResponseWriter, r *http.Request) {
+	var m db.Match
+	if !decodeJSON(w, r, &m) {
+		return
+	}
+	if err := s.writer.InsertMatch(r.Context(), m); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// handleInsertEvents decodes a []db.Event and writes it via
+// db.Writer.BatchInsertEvents. The {id} path value is accepted for parity
+// with Store.InsertEvents' signature but isn't otherwise needed: every
+// db.Event already carries its own MatchID field.
+func (s *Server) handleInsertEvents(w http.ResponseWriter, r *http.Request) {
+	var events []db.Event
+	if !decodeJSON(w, r, &events) {
+		return
+	}
+	if err := s.writer.BatchInsertEvents(r.Context(), events); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// handleInsertPositions decodes a []db.PlayerPosition and writes it via
+// db.Writer.InsertPlayerPositions.
+func (s *Server) handleInsertPositions(w http.ResponseWriter, r *http.Request) {
+	var positions []db.PlayerPosition
+	if !decodeJSON(w, r, &positions) {
+		return
+	}
+	if err := s.writer.InsertPlayerPositions(r.Context(), positions); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// ProgressFunc is called once per line Server reads off a StreamProgress
+// request body, in arrival order.
+type ProgressFunc func(matchID, stage string, tick, round int, pct float64)
+
+// handleProgress reads r.Body as newline-delimited JSON, one progressMessage
+// per line, calling s.onProgress (if set) for each and replying once the
+// client closes its side of the stream (io.EOF). A nil s.onProgress just
+// drains the stream, which is enough for callers that only want
+// StreamProgress's connection-liveness guarantee and not the updates
+// themselves.
+func (s *Server) handleProgress(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var msg progressMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("decoding progress line: %w", err))
+			return
+		}
+		if s.onProgress != nil {
+			s.onProgress(matchID, msg.Stage, msg.Tick, msg.Round, msg.Pct)
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// handleGetScores mirrors httpapi.Server.handleMatchScores.
+func (s *Server) handleGetScores(w http.ResponseWriter, r *http.Request) {
+	scores, err := s.reader.GetPlayerScores(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, scores)
+}
+
+// handleGetEvents mirrors httpapi.Server.handleMatchEvents, minus paging:
+// remotedb's Client exposes db.EventQuery's Limit/Offset directly instead
+// of httpapi's page-number convention, since Client's callers are other
+// Go code rather than a browser-facing dashboard.
+func (s *Server) handleGetEvents(w http.ResponseWriter, r *http.Request) {
+	q := db.EventQuery{MatchID: r.PathValue("id")}
+	query := r.URL.Query()
+	if t := query.Get("type"); t != "" {
+		q.Type = &t
+	}
+	if sid := query.Get("steamid"); sid != "" {
+		q.SteamID = &sid
+	}
+	if roundStr := query.Get("round"); roundStr != "" {
+		round, err := strconv.Atoi(roundStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid round: %w", err))
+			return
+		}
+		q.Round = &round
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %w", err))
+			return
+		}
+		q.Limit = limit
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid offset: %w", err))
+			return
+		}
+		q.Offset = offset
+	}
+
+	events, err := s.reader.GetEvents(r.Context(), q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// handleGetRounds mirrors httpapi.Server.handleMatchRounds.
+func (s *Server) handleGetRounds(w http.ResponseWriter, r *http.Request) {
+	rounds, err := s.reader.GetRounds(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rounds)
+}
+
+// handleGetChat mirrors httpapi.Server.handleMatchChat.
+func (s *Server) handleGetChat(w http.ResponseWriter, r *http.Request) {
+	var steamID *string
+	if sid := r.URL.Query().Get("steamid"); sid != "" {
+		steamID = &sid
+	}
+	messages, err := s.reader.GetChatMessages(r.Context(), r.PathValue("id"), steamID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, messages)
+}
+
+// decodeJSON decodes r.Body's JSON into dest, writing a 400 response and
+// returning false on failure.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dest any) bool {
+	if err := json.NewDecoder(r.Body).Decode(dest); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decoding request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
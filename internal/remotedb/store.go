@@ -0,0 +1,131 @@
+package remotedb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// Store implements db.Store by sending InsertEvents/InsertPositions over
+// HTTP to a Server, so several parser workers can point at one central
+// analyzer instead of each writing their own SQLite file. It's a drop-in
+// alternative anywhere a db.Store is accepted, alongside db.SQLiteStore
+// and db.PostgresStore.
+type Store struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewStore creates a Store that talks to cfg.BaseURL.
+func NewStore(cfg Config) *Store {
+	return &Store{cfg: cfg, client: cfg.httpClient()}
+}
+
+// Init tells the remote Server to prepare its backend for writing. Unlike
+// db.SQLiteStore.Init (a no-op, since db.Open already ran InitSchema
+// locally), this is a real RPC: the Server process may not have opened its
+// backing store yet when the first worker connects.
+func (s *Store) Init(ctx context.Context) error {
+	return s.post(ctx, "/v1/init", nil, nil)
+}
+
+// InsertEvents sends a batch of events for matchID to the remote Server.
+func (s *Store) InsertEvents(ctx context.Context, matchID string, events []db.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return s.post(ctx, "/v1/matches/"+matchID+"/events", events, nil)
+}
+
+// InsertPositions sends a batch of player positions for matchID to the
+// remote Server.
+func (s *Store) InsertPositions(ctx context.Context, matchID string, positions []db.PlayerPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	return s.post(ctx, "/v1/matches/"+matchID+"/positions", positions, nil)
+}
+
+// UpsertMatch sends the match row itself, the way db.Writer.InsertMatch
+// does locally. db.Store has no equivalent method (SQLiteStore/
+// PostgresStore both assume InsertMatch is called directly against the
+// underlying db.Writer), but a remote backend needs it over the wire, so
+// it's exposed here rather than shoehorned into the Store interface.
+func (s *Store) UpsertMatch(ctx context.Context, m db.Match) error {
+	return s.post(ctx, "/v1/matches", m, nil)
+}
+
+// Flush is a no-op: like db.SQLiteStore, the remote Server commits each
+// Insert* request as it's received, so there's nothing buffered client-side
+// to force out.
+func (s *Store) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close releases the Store's idle HTTP connections. The remote Server
+// itself is unaffected - Close only tears down this client's end.
+func (s *Store) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// post JSON-encodes body (if non-nil) and POSTs it to path on cfg.BaseURL,
+// decoding the response into out (if non-nil). Used by Store's calls;
+// Client calls doJSON directly.
+func (s *Store) post(ctx context.Context, path string, body, out any) error {
+	return doJSON(ctx, s.client, s.cfg, http.MethodPost, path, body, out)
+}
+
+// doJSON issues an HTTP request carrying body as a JSON payload (skipped
+// if body is nil) and decodes a JSON response into out (skipped if out is
+// nil; an empty response body is a decode error). Shared by Store and Client.
+func doJSON(ctx context.Context, client *http.Client, cfg Config, method, path string, body, out any) error {
+	var reader bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("remotedb: encoding request: %w", err)
+		}
+		reader = *bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, cfg.BaseURL+path, &reader)
+	if err != nil {
+		return fmt.Errorf("remotedb: building request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if bearer := authBearer(cfg.AuthToken); bearer != "" {
+		req.Header.Set(AuthHeader, bearer)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remotedb: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr errorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error != "" {
+			return fmt.Errorf("remotedb: %s %s: %s (status %d)", method, path, apiErr.Error, resp.StatusCode)
+		}
+		return fmt.Errorf("remotedb: %s %s: status %d", method, path, resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errorResponse is the JSON body Server writes alongside a non-200 status.
+type errorResponse struct {
+	Error string `json:"error"`
+}
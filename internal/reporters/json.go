@@ -0,0 +1,19 @@
+package reporters
+
+import "encoding/json"
+
+// JSONReporter renders a MatchReport as indented JSON, for piping into
+// another tool or embedding in an API response.
+type JSONReporter struct{}
+
+// Format implements Reporter.
+func (JSONReporter) Format() string { return "json" }
+
+// Render implements Reporter.
+func (JSONReporter) Render(report *MatchReport) (string, error) {
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
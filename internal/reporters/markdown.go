@@ -0,0 +1,48 @@
+package reporters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarkdownReporter renders a MatchReport as GitHub-flavored Markdown: an
+// event timeline table followed by a player score table, for pasting into a
+// PR description or chat message.
+type MarkdownReporter struct{}
+
+// Format implements Reporter.
+func (MarkdownReporter) Format() string { return "markdown" }
+
+// Render implements Reporter.
+func (MarkdownReporter) Render(report *MatchReport) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Match %s (%s)\n\n", report.MatchID, report.Map)
+
+	b.WriteString("## Timeline\n\n")
+	b.WriteString("| Round | Time | Type | Actor | Victim | Severity |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, e := range report.Events {
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s | %.2f |\n",
+			e.RoundIndex, e.ClockTime, e.Type, e.ActorName, e.VictimName, e.Severity)
+	}
+
+	b.WriteString("\n## Player scores")
+	if report.ProfileName != "" {
+		fmt.Fprintf(&b, " (profile %s)", report.ProfileName)
+	}
+	b.WriteString("\n\n")
+	b.WriteString("| SteamID | Grief score | Team kills | Team damage | Flash seconds | AFK seconds | Body-block seconds | Economy griefs | Top reasons |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+	for _, p := range report.Players {
+		reasons := make([]string, 0, len(p.TopReasons))
+		for _, r := range p.TopReasons {
+			reasons = append(reasons, fmt.Sprintf("%dx %s", r.Count, r.Reason))
+		}
+		fmt.Fprintf(&b, "| %s | %.1f | %d | %.1f | %.1f | %.1f | %.1f | %d | %s |\n",
+			p.SteamID, p.GriefScore, p.TeamKills, p.TeamDamage, p.TeamFlashSeconds,
+			p.AFKSeconds, p.BodyBlockSeconds, p.EconomyGriefCount, strings.Join(reasons, "; "))
+	}
+
+	return b.String(), nil
+}
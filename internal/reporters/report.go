@@ -0,0 +1,141 @@
+// Package reporters renders a match's stored events and player scores into
+// a human- or machine-readable summary, similar in spirit to tools that
+// post-process demo files into a narrative of kills, knocks and notable
+// incidents. BuildReport does the one-time work of joining events, rounds
+// and player scores into a MatchReport; each Reporter implementation then
+// turns that into one output format without touching db, scoring or
+// extractors.
+package reporters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cs-griefer-electron/internal/db"
+)
+
+// ReportEvent is one db.Event flattened for rendering: tick has been turned
+// into a match-clock time within its round, and actor/victim SteamIDs have
+// been resolved to names where known.
+type ReportEvent struct {
+	RoundIndex    int
+	ClockTime     string // "m:ss" since the round's start tick
+	Type          string
+	ActorSteamID  string
+	ActorName     string
+	VictimSteamID string
+	VictimName    string
+	Severity      float64
+}
+
+// MatchReport is everything a Reporter needs to render one match: its
+// timeline of detected events and the final per-player score table.
+type MatchReport struct {
+	MatchID     string
+	Map         string
+	ProfileName string
+	Events      []ReportEvent
+	Players     []db.PlayerScore
+}
+
+// BuildReport reads matchID's rounds, events and scores and assembles a
+// MatchReport. profileName restricts Players to one scoring.ScoringProfile's
+// rows (see db.GetPlayerScoresByProfile); empty means every profile the
+// match has been scored under (see db.GetPlayerScores).
+func BuildReport(ctx context.Context, reader *db.Reader, matchID, profileName string) (*MatchReport, error) {
+	match, err := reader.GetMatch(ctx, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get match: %w", err)
+	}
+
+	rounds, err := reader.GetRounds(ctx, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rounds: %w", err)
+	}
+	roundStartTick := make(map[int]int, len(rounds))
+	for _, rnd := range rounds {
+		roundStartTick[rnd.RoundIndex] = rnd.StartTick
+	}
+
+	events, err := reader.GetEvents(ctx, db.EventQuery{MatchID: matchID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	names := make(map[string]string)
+	resolveName := func(steamID string) string {
+		if steamID == "" {
+			return ""
+		}
+		if name, ok := names[steamID]; ok {
+			return name
+		}
+		name, err := reader.GetPlayerName(ctx, matchID, steamID)
+		if err != nil || name == "" {
+			name = steamID
+		}
+		names[steamID] = name
+		return name
+	}
+
+	reportEvents := make([]ReportEvent, 0, len(events))
+	for _, e := range events {
+		startTick, ok := roundStartTick[e.RoundIndex]
+		if !ok {
+			startTick = e.StartTick
+		}
+		re := ReportEvent{
+			RoundIndex: e.RoundIndex,
+			ClockTime:  formatClock(e.StartTick-startTick, match.TickRate),
+			Type:       e.Type,
+		}
+		if e.ActorSteamID != nil {
+			re.ActorSteamID = *e.ActorSteamID
+			re.ActorName = resolveName(*e.ActorSteamID)
+		}
+		if e.VictimSteamID != nil {
+			re.VictimSteamID = *e.VictimSteamID
+			re.VictimName = resolveName(*e.VictimSteamID)
+		}
+		if e.Severity != nil {
+			re.Severity = *e.Severity
+		}
+		reportEvents = append(reportEvents, re)
+	}
+
+	var players []db.PlayerScore
+	if profileName != "" {
+		players, err = reader.GetPlayerScoresByProfile(ctx, matchID, profileName)
+	} else {
+		players, err = reader.GetPlayerScores(ctx, matchID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player scores: %w", err)
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].GriefScore > players[j].GriefScore
+	})
+
+	return &MatchReport{
+		MatchID:     matchID,
+		Map:         match.Map,
+		ProfileName: profileName,
+		Events:      reportEvents,
+		Players:     players,
+	}, nil
+}
+
+// formatClock renders ticksIntoRound as "m:ss" at tickRate ticks/second. A
+// non-positive tickRate (shouldn't happen outside a malformed matches row)
+// falls back to "0:00" rather than dividing by zero.
+func formatClock(ticksIntoRound int, tickRate float64) string {
+	if tickRate <= 0 {
+		return "0:00"
+	}
+	totalSeconds := int(float64(ticksIntoRound) / tickRate)
+	if totalSeconds < 0 {
+		totalSeconds = 0
+	}
+	return fmt.Sprintf("%d:%02d", totalSeconds/60, totalSeconds%60)
+}
@@ -0,0 +1,27 @@
+package reporters
+
+import "fmt"
+
+// Reporter renders a MatchReport into one output format. New formats (HTML,
+// CSV) implement this interface without touching db, scoring or extractors.
+type Reporter interface {
+	// Format is the short name passed to New, e.g. "text".
+	Format() string
+	// Render returns report rendered in this Reporter's format.
+	Render(report *MatchReport) (string, error)
+}
+
+// New returns the Reporter registered for format, or an error naming the
+// formats that are available.
+func New(format string) (Reporter, error) {
+	switch format {
+	case "text":
+		return TextReporter{}, nil
+	case "markdown":
+		return MarkdownReporter{}, nil
+	case "json":
+		return JSONReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q (want text, markdown or json)", format)
+	}
+}
@@ -0,0 +1,51 @@
+package reporters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TextReporter renders a MatchReport as plain text: one line per event in
+// match-clock order, followed by a per-player block of raw counters and the
+// top reason codes behind them.
+type TextReporter struct{}
+
+// Format implements Reporter.
+func (TextReporter) Format() string { return "text" }
+
+// Render implements Reporter.
+func (TextReporter) Render(report *MatchReport) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Match %s (%s)\n", report.MatchID, report.Map)
+	b.WriteString(strings.Repeat("=", 60))
+	b.WriteString("\n\n")
+
+	for _, e := range report.Events {
+		victim := ""
+		if e.VictimName != "" {
+			victim = " -> " + e.VictimName
+		}
+		fmt.Fprintf(&b, "[round %d %s] %s: %s%s (severity %.2f)\n",
+			e.RoundIndex, e.ClockTime, e.Type, e.ActorName, victim, e.Severity)
+	}
+
+	b.WriteString("\nPlayer scores")
+	if report.ProfileName != "" {
+		fmt.Fprintf(&b, " (profile %s)", report.ProfileName)
+	}
+	b.WriteString("\n")
+	b.WriteString(strings.Repeat("-", 60))
+	b.WriteString("\n")
+
+	for _, p := range report.Players {
+		fmt.Fprintf(&b, "%s  grief_score=%.1f\n", p.SteamID, p.GriefScore)
+		fmt.Fprintf(&b, "  team_kills=%d team_damage=%.1f team_flash_seconds=%.1f afk_seconds=%.1f body_block_seconds=%.1f economy_grief_count=%d\n",
+			p.TeamKills, p.TeamDamage, p.TeamFlashSeconds, p.AFKSeconds, p.BodyBlockSeconds, p.EconomyGriefCount)
+		for _, reason := range p.TopReasons {
+			fmt.Fprintf(&b, "  - %dx %s\n", reason.Count, reason.Reason)
+		}
+	}
+
+	return b.String(), nil
+}
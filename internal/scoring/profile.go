@@ -0,0 +1,122 @@
+package scoring
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MetricWeight is one term of a ScoringProfile's weighted sum: metric's
+// tanh(value/SoftCap) is normalized to 0-100 and scaled by Weight before
+// being summed into the final grief score.
+type MetricWeight struct {
+	// Metric names a playerAggregate field (see aggregateValue): team_kills,
+	// team_damage, team_flash_seconds, afk_seconds, body_block_seconds or
+	// economy_grief_count. Profiles may also list entry_frags, trades or
+	// utility_damage, but those contribute 0: ComputeScores aggregates no
+	// events into them (there is no EntryFragExtractor, and TRADE_KILL /
+	// UTILITY_USAGE events are not counted).
+	Metric  string
+	Weight  float64
+	SoftCap float64
+}
+
+// DamageCauseWeight scales how much a TEAM_DAMAGE event's total_damage
+// contributes to the team_damage metric, based on the event's MetaJSON
+// "cause" field (see extractors.DamageCause). A cause not listed here
+// contributes at its full value (weight 1.0).
+type DamageCauseWeight struct {
+	Cause  string
+	Weight float64
+}
+
+// ScoringProfile is a named, versioned set of weights ComputeScores uses to
+// turn per-player aggregates into a 0-100 grief score. Loading scores from a
+// YAML file instead of the hardcoded constants this replaced lets analysts
+// iterate on the formula across a whole database of already-parsed demos
+// without re-parsing them (see the rescore subcommand).
+type ScoringProfile struct {
+	Name    string
+	Version string
+	Metrics []MetricWeight
+
+	// DamageCauseWeights down-weights (or excludes, at weight 0) specific
+	// TEAM_DAMAGE causes from the team_damage metric - e.g. a teammate
+	// sprinting into your own molotov is usually not intentional griefing.
+	DamageCauseWeights []DamageCauseWeight
+}
+
+// damageCauseWeight looks up cause's weight, defaulting to 1.0 (full value)
+// if the profile doesn't list it.
+func (p *ScoringProfile) damageCauseWeight(cause string) float64 {
+	for _, w := range p.DamageCauseWeights {
+		if w.Cause == cause {
+			return w.Weight
+		}
+	}
+	return 1.0
+}
+
+// DefaultProfile reproduces the metric weights and soft caps this package
+// used before ScoringProfile existed, and on top of them weights
+// MOLOTOV_BURN team damage at 0.5, which that earlier scorer did not.
+func DefaultProfile() *ScoringProfile {
+	return &ScoringProfile{
+		Name:    "default",
+		Version: "1",
+		Metrics: []MetricWeight{
+			{Metric: "team_kills", Weight: 0.25, SoftCap: 5},
+			{Metric: "team_damage", Weight: 0.20, SoftCap: 200},
+			{Metric: "team_flash_seconds", Weight: 0.15, SoftCap: 30},
+			{Metric: "afk_seconds", Weight: 0.15, SoftCap: 60},
+			{Metric: "body_block_seconds", Weight: 0.10, SoftCap: 30},
+			{Metric: "economy_grief_count", Weight: 0.15, SoftCap: 5},
+		},
+		DamageCauseWeights: []DamageCauseWeight{
+			{Cause: "MOLOTOV_BURN", Weight: 0.5},
+		},
+	}
+}
+
+// Hash is a stable fingerprint of the profile's name, version and weights,
+// stored alongside computed scores (see ComputeScores) so a grief score can
+// always be traced back to the exact profile that produced it, even if a
+// later edit to the YAML file reuses the same Version string.
+func (p *ScoringProfile) Hash() string {
+	metrics := make([]MetricWeight, len(p.Metrics))
+	copy(metrics, p.Metrics)
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Metric < metrics[j].Metric })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s|%s", p.Name, p.Version)
+	for _, m := range metrics {
+		fmt.Fprintf(&sb, "|%s:%g:%g", m.Metric, m.Weight, m.SoftCap)
+	}
+
+	causeWeights := make([]DamageCauseWeight, len(p.DamageCauseWeights))
+	copy(causeWeights, p.DamageCauseWeights)
+	sort.Slice(causeWeights, func(i, j int) bool { return causeWeights[i].Cause < causeWeights[j].Cause })
+	for _, w := range causeWeights {
+		fmt.Fprintf(&sb, "|%s=%g", w.Cause, w.Weight)
+	}
+
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadProfile reads a ScoringProfile from a YAML file at path. See
+// parseProfileYAML for the (intentionally small) subset of YAML supported.
+func LoadProfile(path string) (*ScoringProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scoring profile %s: %w", path, err)
+	}
+	profile, err := parseProfileYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scoring profile %s: %w", path, err)
+	}
+	return profile, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
 
 	"cs-griefer-electron/internal/db"
 )
@@ -19,8 +20,20 @@ func NewScorer(writer *db.Writer) *Scorer {
 	return &Scorer{writer: writer}
 }
 
-// ComputeScores computes and stores player scores for a match.
-func (s *Scorer) ComputeScores(ctx context.Context, matchID string, reader *db.Reader) error {
+// ComputeScores computes and stores player scores for a match using
+// profile's weights and soft caps. A nil profile falls back to
+// DefaultProfile, so existing callers that predate ScoringProfile keep
+// their original behavior. The profile's name, version and Hash are
+// recorded in the meta table (namespaced by matchID, since --batch can
+// write several matches' worth of scores into one shared database) so a
+// score can always be traced back to the exact profile that produced it -
+// see the rescore subcommand, which recomputes scores from already-parsed
+// events without re-parsing the demo.
+func (s *Scorer) ComputeScores(ctx context.Context, matchID string, reader *db.Reader, profile *ScoringProfile) error {
+	if profile == nil {
+		profile = DefaultProfile()
+	}
+
 	// Get all events for the match
 	events, err := reader.GetEvents(ctx, db.EventQuery{MatchID: matchID})
 	if err != nil {
@@ -46,6 +59,16 @@ func (s *Scorer) ComputeScores(ctx context.Context, matchID string, reader *db.R
 
 		agg := scores[steamID]
 
+		if event.MetaJSON != nil {
+			var meta map[string]interface{}
+			if err := json.Unmarshal([]byte(*event.MetaJSON), &meta); err == nil {
+				if code, ok := meta["reason_code"].(float64); ok {
+					reason, _ := meta["reason"].(string)
+					agg.recordReason(int(code), reason)
+				}
+			}
+		}
+
 		switch event.Type {
 		case "TEAM_KILL":
 			agg.teamKills++
@@ -56,22 +79,31 @@ func (s *Scorer) ComputeScores(ctx context.Context, matchID string, reader *db.R
 			if event.MetaJSON != nil {
 				var meta map[string]interface{}
 				if err := json.Unmarshal([]byte(*event.MetaJSON), &meta); err == nil {
+					var dmg float64
 					// Try total_damage first (as per requirements)
 					if totalDmg, ok := meta["total_damage"].(float64); ok {
-						agg.teamDamage += totalDmg
+						dmg = totalDmg
 					} else {
 						// Fallback: sum health and armor damage
 						if dmgHealth, ok := meta["dmg_health"].(float64); ok {
-							agg.teamDamage += dmgHealth
+							dmg += dmgHealth
 						} else if dmgHealth, ok := meta["dmg_health"].(int); ok {
-							agg.teamDamage += float64(dmgHealth)
+							dmg += float64(dmgHealth)
 						}
 						if dmgArmor, ok := meta["dmg_armor"].(float64); ok {
-							agg.teamDamage += dmgArmor
+							dmg += dmgArmor
 						} else if dmgArmor, ok := meta["dmg_armor"].(int); ok {
-							agg.teamDamage += float64(dmgArmor)
+							dmg += float64(dmgArmor)
 						}
 					}
+					// A teammate sprinting into your own molotov isn't really
+					// griefing, so the profile's per-cause weight table (see
+					// ScoringProfile.damageCauseWeight) can down-weight it before
+					// it contributes to the metric.
+					if cause, ok := meta["cause"].(string); ok {
+						dmg *= profile.damageCauseWeight(cause)
+					}
+					agg.teamDamage += dmg
 				}
 			}
 
@@ -108,7 +140,7 @@ func (s *Scorer) ComputeScores(ctx context.Context, matchID string, reader *db.R
 					}
 				}
 			}
-			
+
 		case "ECONOMY_GRIEF":
 			agg.economyGriefCount++
 		}
@@ -116,10 +148,11 @@ func (s *Scorer) ComputeScores(ctx context.Context, matchID string, reader *db.R
 
 	// Compute grief scores and store
 	for steamID, agg := range scores {
-		score := s.computeGriefScore(agg)
+		score := s.computeGriefScore(agg, profile)
 		playerScore := db.PlayerScore{
 			MatchID:           matchID,
 			SteamID:           steamID,
+			ProfileName:       profile.Name,
 			TeamKills:         agg.teamKills,
 			TeamDamage:        agg.teamDamage,
 			TeamFlashSeconds:  agg.teamFlashSeconds,
@@ -127,6 +160,7 @@ func (s *Scorer) ComputeScores(ctx context.Context, matchID string, reader *db.R
 			BodyBlockSeconds:  agg.bodyBlockSeconds,
 			EconomyGriefCount: agg.economyGriefCount,
 			GriefScore:        score,
+			TopReasons:        agg.topReasons(),
 		}
 
 		if err := s.writer.InsertPlayerScore(ctx, playerScore); err != nil {
@@ -134,9 +168,41 @@ func (s *Scorer) ComputeScores(ctx context.Context, matchID string, reader *db.R
 		}
 	}
 
+	if err := s.writer.SetMeta(ctx, fmt.Sprintf("scoring_profile_name:%s", matchID), profile.Name); err != nil {
+		return fmt.Errorf("failed to record scoring profile name: %w", err)
+	}
+	if err := s.writer.SetMeta(ctx, fmt.Sprintf("scoring_profile_version:%s", matchID), profile.Version); err != nil {
+		return fmt.Errorf("failed to record scoring profile version: %w", err)
+	}
+	if err := s.writer.SetMeta(ctx, fmt.Sprintf("scoring_profile_hash:%s", matchID), profile.Hash()); err != nil {
+		return fmt.Errorf("failed to record scoring profile hash: %w", err)
+	}
+
 	return nil
 }
 
+// aggregateValue returns agg's value for one of MetricWeight's recognized
+// metric names, or (0, false) for a metric this tree has no extractor data
+// for yet (see MetricWeight.Metric).
+func aggregateValue(agg *playerAggregate, metric string) (float64, bool) {
+	switch metric {
+	case "team_kills":
+		return float64(agg.teamKills), true
+	case "team_damage":
+		return agg.teamDamage, true
+	case "team_flash_seconds":
+		return agg.teamFlashSeconds, true
+	case "afk_seconds":
+		return agg.afkSeconds, true
+	case "body_block_seconds":
+		return agg.bodyBlockSeconds, true
+	case "economy_grief_count":
+		return float64(agg.economyGriefCount), true
+	default:
+		return 0, false
+	}
+}
+
 type playerAggregate struct {
 	steamID           string
 	teamKills         int
@@ -145,51 +211,69 @@ type playerAggregate struct {
 	afkSeconds        float64
 	bodyBlockSeconds  float64
 	economyGriefCount int
+	reasonCounts      map[int]*reasonTally
 }
 
-// computeGriefScore calculates the grief score (0-100) from aggregates.
-// Uses soft caps and weights for normalization.
-func (s *Scorer) computeGriefScore(agg *playerAggregate) float64 {
-	// Weights
-	const (
-		weightTK        = 0.25 // High
-		weightDamage    = 0.20 // Medium-high
-		weightFlash     = 0.15 // Medium
-		weightAFK       = 0.15 // Medium
-		weightBodyBlock = 0.10 // Medium-low
-		weightEconomy   = 0.15 // Medium - impacts team strategy
-	)
-
-	// Soft cap functions: tanh-based normalization
-	// This gives diminishing returns after certain thresholds
-
-	// Team kills: soft cap at 5 (tanh(5) ≈ 1.0)
-	tkScore := math.Tanh(float64(agg.teamKills) / 5.0) * 100.0
-
-	// Team damage: soft cap at 200 (tanh(200) ≈ 1.0)
-	damageScore := math.Tanh(agg.teamDamage / 200.0) * 100.0
-
-	// Team flash: soft cap at 30 seconds (tanh(30) ≈ 1.0)
-	flashScore := math.Tanh(agg.teamFlashSeconds / 30.0) * 100.0
-
-	// AFK: soft cap at 60 seconds (tanh(60) ≈ 1.0)
-	afkScore := math.Tanh(agg.afkSeconds / 60.0) * 100.0
-
-	// Body block: soft cap at 30 seconds (tanh(30) ≈ 1.0)
-	bodyBlockScore := math.Tanh(agg.bodyBlockSeconds / 30.0) * 100.0
-	
-	// Economy grief: soft cap at 5 incidents (tanh(5) ≈ 1.0)
-	economyScore := math.Tanh(float64(agg.economyGriefCount) / 5.0) * 100.0
-
-	// Weighted sum
-	totalScore := tkScore*weightTK +
-		damageScore*weightDamage +
-		flashScore*weightFlash +
-		afkScore*weightAFK +
-		bodyBlockScore*weightBodyBlock +
-		economyScore*weightEconomy
-
-	// Clamp to 0-100
-	return math.Max(0, math.Min(100, totalScore))
+// reasonTally tracks how many of a player's events carried a given
+// extractors.ReasonCode, plus the code's human-readable text (captured
+// from the event rather than re-derived, so scoring doesn't need to
+// import the extractors package).
+type reasonTally struct {
+	reason string
+	count  int
 }
 
+// recordReason tallies one event's reason_code/reason pair, if it has one.
+// Events without a reason_code (anything predating this extractor change,
+// or an event type that doesn't carry one) are silently skipped.
+func (a *playerAggregate) recordReason(code int, reason string) {
+	if a.reasonCounts == nil {
+		a.reasonCounts = make(map[int]*reasonTally)
+	}
+	tally, ok := a.reasonCounts[code]
+	if !ok {
+		tally = &reasonTally{reason: reason}
+		a.reasonCounts[code] = tally
+	}
+	tally.count++
+}
+
+// topReasons returns the 3 most frequent reason codes recorded via
+// recordReason, most frequent first, breaking ties by code so results are
+// deterministic across runs.
+func (a *playerAggregate) topReasons() []db.ReasonCount {
+	if len(a.reasonCounts) == 0 {
+		return nil
+	}
+	counts := make([]db.ReasonCount, 0, len(a.reasonCounts))
+	for code, tally := range a.reasonCounts {
+		counts = append(counts, db.ReasonCount{Code: code, Reason: tally.reason, Count: tally.count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Code < counts[j].Code
+	})
+	if len(counts) > 3 {
+		counts = counts[:3]
+	}
+	return counts
+}
+
+// computeGriefScore calculates the grief score (0-100) from agg using
+// profile's weights and soft caps: each metric is normalized to 0-100 via
+// tanh(value/SoftCap) for diminishing returns past the soft cap, then
+// summed scaled by Weight. Metrics profile lists that this tree has no
+// extractor data for yet (see aggregateValue) are skipped.
+func (s *Scorer) computeGriefScore(agg *playerAggregate, profile *ScoringProfile) float64 {
+	var total float64
+	for _, m := range profile.Metrics {
+		value, ok := aggregateValue(agg, m.Metric)
+		if !ok || m.SoftCap == 0 {
+			continue
+		}
+		total += math.Tanh(value/m.SoftCap) * 100.0 * m.Weight
+	}
+	return math.Max(0, math.Min(100, total))
+}
@@ -0,0 +1,193 @@
+package scoring
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseProfileYAML parses the small, fixed-shape subset of YAML a
+// ScoringProfile needs:
+//
+//	name: aggressive
+//	version: "2"
+//	metrics:
+//	  - metric: team_kills
+//	    weight: 0.3
+//	    soft_cap: 4
+//	  - metric: team_damage
+//	    weight: 0.2
+//	    soft_cap: 150
+//	damage_cause_weights:
+//	  - cause: MOLOTOV_BURN
+//	    weight: 0.5
+//
+// This module has no YAML library vendored and builds offline
+// (GOPROXY=off), so rather than hand-write a full YAML 1.1 parser this
+// only understands top-level scalar keys and two indented "- key: value"
+// lists (exactly the shapes above) - anything else (flow style, multi-line
+// scalars, anchors) is rejected with an error instead of silently
+// misparsed.
+func parseProfileYAML(data []byte) (*ScoringProfile, error) {
+	profile := &ScoringProfile{}
+
+	const (
+		sectionNone = iota
+		sectionMetrics
+		sectionDamageCauseWeights
+	)
+	section := sectionNone
+	var currentMetric *MetricWeight
+	var currentCauseWeight *DamageCauseWeight
+
+	flush := func() {
+		if currentMetric != nil {
+			profile.Metrics = append(profile.Metrics, *currentMetric)
+			currentMetric = nil
+		}
+		if currentCauseWeight != nil {
+			profile.DamageCauseWeights = append(profile.DamageCauseWeights, *currentCauseWeight)
+			currentCauseWeight = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := scanner.Text()
+		line := strings.SplitN(raw, "#", 2)[0]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		switch {
+		case trimmed == "metrics:":
+			flush()
+			section = sectionMetrics
+			continue
+
+		case trimmed == "damage_cause_weights:":
+			flush()
+			section = sectionDamageCauseWeights
+			continue
+
+		case strings.HasPrefix(trimmed, "- "):
+			flush()
+			trimmed = strings.TrimPrefix(trimmed, "- ")
+			switch section {
+			case sectionMetrics:
+				currentMetric = &MetricWeight{}
+				if err := setMetricField(currentMetric, trimmed, lineNo); err != nil {
+					return nil, err
+				}
+			case sectionDamageCauseWeights:
+				currentCauseWeight = &DamageCauseWeight{}
+				if err := setDamageCauseWeightField(currentCauseWeight, trimmed, lineNo); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("line %d: list entry outside of metrics:/damage_cause_weights:", lineNo)
+			}
+
+		case currentMetric != nil && strings.Contains(trimmed, ":"):
+			if err := setMetricField(currentMetric, trimmed, lineNo); err != nil {
+				return nil, err
+			}
+
+		case currentCauseWeight != nil && strings.Contains(trimmed, ":"):
+			if err := setDamageCauseWeightField(currentCauseWeight, trimmed, lineNo); err != nil {
+				return nil, err
+			}
+
+		default:
+			key, value, ok := strings.Cut(trimmed, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected \"key: value\", got %q", lineNo, trimmed)
+			}
+			value = unquote(strings.TrimSpace(value))
+			switch strings.TrimSpace(key) {
+			case "name":
+				profile.Name = value
+			case "version":
+				profile.Version = value
+			default:
+				return nil, fmt.Errorf("line %d: unknown top-level key %q", lineNo, key)
+			}
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if profile.Name == "" || profile.Version == "" {
+		return nil, fmt.Errorf("profile must set both name and version")
+	}
+	if len(profile.Metrics) == 0 {
+		return nil, fmt.Errorf("profile must list at least one metric")
+	}
+	return profile, nil
+}
+
+func setDamageCauseWeightField(w *DamageCauseWeight, kv string, lineNo int) error {
+	key, value, ok := strings.Cut(kv, ":")
+	if !ok {
+		return fmt.Errorf("line %d: expected \"key: value\" in damage_cause_weights entry, got %q", lineNo, kv)
+	}
+	key = strings.TrimSpace(key)
+	value = unquote(strings.TrimSpace(value))
+
+	switch key {
+	case "cause":
+		w.Cause = value
+	case "weight":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid weight %q: %w", lineNo, value, err)
+		}
+		w.Weight = f
+	default:
+		return fmt.Errorf("line %d: unknown damage_cause_weights field %q", lineNo, key)
+	}
+	return nil
+}
+
+func setMetricField(m *MetricWeight, kv string, lineNo int) error {
+	key, value, ok := strings.Cut(kv, ":")
+	if !ok {
+		return fmt.Errorf("line %d: expected \"key: value\" in metric entry, got %q", lineNo, kv)
+	}
+	key = strings.TrimSpace(key)
+	value = unquote(strings.TrimSpace(value))
+
+	switch key {
+	case "metric":
+		m.Metric = value
+	case "weight":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid weight %q: %w", lineNo, value, err)
+		}
+		m.Weight = f
+	case "soft_cap":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid soft_cap %q: %w", lineNo, value, err)
+		}
+		m.SoftCap = f
+	default:
+		return fmt.Errorf("line %d: unknown metric field %q", lineNo, key)
+	}
+	return nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,109 @@
+package sharecode
+
+import (
+	"compress/bzip2"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FetchConfig configures Fetch's download of a decoded share code's demo.
+type FetchConfig struct {
+	// URLTemplate is the mirror URL to download the demo's .dem.bz2 from,
+	// with "{match_id}", "{outcome_id}" and "{token}" placeholders
+	// substituted from the Decoded triple, e.g.
+	// "https://my-mirror.example/{match_id}/{outcome_id}/{token}.dem.bz2".
+	//
+	// A real client would instead resolve this URL from Valve's Game
+	// Coordinator (the CMsgGCCStrike15_v2_MatchList GC message CS2 itself
+	// sends), but that requires an authenticated Steam client connection
+	// over the GC protocol, which isn't vendored here and can't be reached
+	// from this offline build environment. URLTemplate is the explicit
+	// "starting point" fallback the request calls for: point it at any
+	// HTTP mirror that serves demos by this same triple and Fetch works
+	// unchanged; swapping in real GC resolution later only means replacing
+	// how the URL is produced, not anything downstream of it.
+	URLTemplate string
+	// DestDir is the directory downloaded-and-decompressed .dem files are
+	// written to. Created if missing.
+	DestDir string
+	// HTTPClient is the client Fetch issues the GET with. A nil value
+	// gets a 60-second-timeout default - demos run tens of megabytes, so
+	// the short timeouts steamapi.Client uses for small JSON calls don't
+	// apply here.
+	HTTPClient *http.Client
+}
+
+func (c FetchConfig) withDefaults() FetchConfig {
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: 60 * time.Second}
+	}
+	return c
+}
+
+// ResolveURL substitutes d's fields into template (the same
+// "{match_id}"/"{outcome_id}"/"{token}" placeholders FetchConfig.URLTemplate
+// documents). Exported so a caller that wants to stream d's demo straight
+// into internal/parser.NewFromSource (via an HTTPSource) instead of landing
+// it on disk via Fetch can still resolve the same URL Fetch would have used.
+func ResolveURL(template string, d Decoded) string {
+	r := strings.NewReplacer(
+		"{match_id}", strconv.FormatUint(d.MatchID, 10),
+		"{outcome_id}", strconv.FormatUint(d.OutcomeID, 10),
+		"{token}", strconv.FormatUint(uint64(d.Token), 10),
+	)
+	return r.Replace(template)
+}
+
+// Fetch downloads d's demo per cfg.URLTemplate, decompresses the .dem.bz2
+// body on the fly via compress/bzip2, and writes the resulting .dem to
+// cfg.DestDir, returning its path.
+func Fetch(ctx context.Context, cfg FetchConfig, d Decoded) (string, error) {
+	cfg = cfg.withDefaults()
+	if cfg.URLTemplate == "" {
+		return "", fmt.Errorf("sharecode: FetchConfig.URLTemplate is required")
+	}
+	if cfg.DestDir == "" {
+		return "", fmt.Errorf("sharecode: FetchConfig.DestDir is required")
+	}
+	if err := os.MkdirAll(cfg.DestDir, 0o755); err != nil {
+		return "", fmt.Errorf("sharecode: create dest dir: %w", err)
+	}
+
+	reqURL := ResolveURL(cfg.URLTemplate, d)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("sharecode: build request: %w", err)
+	}
+
+	resp, err := cfg.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sharecode: fetch %s: %w", reqURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("sharecode: fetch %s: unexpected status %d: %s", reqURL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	destPath := filepath.Join(cfg.DestDir, strconv.FormatUint(d.MatchID, 10)+".dem")
+	f, err := os.Create(destPath)
+	if err != nil {
+		return "", fmt.Errorf("sharecode: create %s: %w", destPath, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, bzip2.NewReader(resp.Body)); err != nil {
+		os.Remove(destPath)
+		return "", fmt.Errorf("sharecode: decompress %s into %s: %w", reqURL, destPath, err)
+	}
+
+	return destPath, nil
+}
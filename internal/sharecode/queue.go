@@ -0,0 +1,127 @@
+package sharecode
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"cs-griefer-electron/internal/db"
+)
+
+const (
+	defaultQueueMaxAttempts  = 5
+	defaultQueueInitialDelay = 1 * time.Second
+	defaultQueueMaxDelay     = 30 * time.Second
+)
+
+// RetryConfig configures Queue.Submit's retry/backoff around Fetch, the
+// same zero-value-falls-back-to-defaults convention db.RetryConfig uses.
+type RetryConfig struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaultQueueMaxAttempts
+	}
+	if c.InitialDelay <= 0 {
+		c.InitialDelay = defaultQueueInitialDelay
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = defaultQueueMaxDelay
+	}
+	return c
+}
+
+// Handler is called once a share code's demo has been downloaded and
+// decompressed to demoPath, to parse it and store the result - typically
+// NewParser+ParseWithDB followed by a db.Writer.InsertMatch with
+// Source="valve" (see cmd/ingest-sharecode).
+type Handler func(ctx context.Context, matchID string, d Decoded, demoPath string) error
+
+// Queue submits share codes for ingestion, skipping any whose matchID
+// already exists in the database (so resubmitting the same share code is a
+// no-op) and retrying Fetch with exponential backoff on transient
+// failures.
+type Queue struct {
+	reader   *db.Reader
+	fetchCfg FetchConfig
+	retryCfg RetryConfig
+	handler  Handler
+}
+
+// NewQueue creates a Queue. reader is used only for the matches.id
+// dedup check; fetchCfg/retryCfg configure how each share code's demo is
+// downloaded and retried; handler does the actual parse-and-store.
+func NewQueue(reader *db.Reader, fetchCfg FetchConfig, retryCfg RetryConfig, handler Handler) *Queue {
+	return &Queue{
+		reader:   reader,
+		fetchCfg: fetchCfg,
+		retryCfg: retryCfg.withDefaults(),
+		handler:  handler,
+	}
+}
+
+// Submit decodes code, skips it if its matchID is already stored, then
+// fetches and hands its demo to Queue's Handler, retrying Fetch with
+// exponential backoff up to RetryConfig.MaxAttempts times. Submit blocks
+// until the share code is fully processed (or exhausts its retries) -
+// callers wanting several in flight at once should run Submit from their
+// own worker pool, the way pipeline.BatchRunner does for demo files.
+func (q *Queue) Submit(ctx context.Context, code string) error {
+	decoded, err := Decode(code)
+	if err != nil {
+		return err
+	}
+	matchID := strconv.FormatUint(decoded.MatchID, 10)
+
+	exists, err := q.reader.GetMatchExists(ctx, matchID)
+	if err != nil {
+		return fmt.Errorf("sharecode: check existing match %s: %w", matchID, err)
+	}
+	if exists {
+		return nil
+	}
+
+	demoPath, err := q.fetchWithRetry(ctx, decoded)
+	if err != nil {
+		return fmt.Errorf("sharecode: fetch %s: %w", code, err)
+	}
+
+	return q.handler(ctx, matchID, decoded, demoPath)
+}
+
+// fetchWithRetry calls Fetch, retrying with exponential backoff (capped at
+// RetryConfig.MaxDelay) on failure.
+func (q *Queue) fetchWithRetry(ctx context.Context, d Decoded) (string, error) {
+	delay := q.retryCfg.InitialDelay
+	var lastErr error
+
+	for attempt := 1; attempt <= q.retryCfg.MaxAttempts; attempt++ {
+		demoPath, err := Fetch(ctx, q.fetchCfg, d)
+		if err == nil {
+			return demoPath, nil
+		}
+		lastErr = err
+
+		if attempt == q.retryCfg.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+
+		delay *= 2
+		if delay > q.retryCfg.MaxDelay {
+			delay = q.retryCfg.MaxDelay
+		}
+	}
+
+	return "", fmt.Errorf("exhausted %d attempts: %w", q.retryCfg.MaxAttempts, lastErr)
+}
@@ -0,0 +1,123 @@
+// Package sharecode decodes and encodes CS2 matchmaking share codes (the
+// "CSGO-xxxxx-xxxxx-xxxxx-xxxxx-xxxxx" strings players exchange to look up
+// a specific match) into the (matchID, outcomeID, token) triple Valve's
+// demo CDN keys a download URL on, per the publicly documented share code
+// format used by community match trackers.
+package sharecode
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// dictionary is the 57-character alphabet share codes are encoded in -
+// every uppercase/lowercase letter except the visually ambiguous I, g and
+// l, plus the digits 2-9 (skipping 0 and 1 for the same reason).
+const dictionary = "ABCDEFGHJKLMNOPQRSTUVWXYZabcdefhijkmnopqrstuvwxyz23456789"
+
+// codePattern matches a share code with its "CSGO-" prefix and dashes
+// intact: CSGO- followed by five 5-character groups.
+var codePattern = regexp.MustCompile(`^CSGO(-[A-Za-z0-9]{5}){5}$`)
+
+// Decoded is the triple a share code encodes.
+type Decoded struct {
+	MatchID   uint64
+	OutcomeID uint64
+	Token     uint16
+}
+
+// Decode parses a "CSGO-xxxxx-xxxxx-xxxxx-xxxxx-xxxxx" share code into its
+// (matchID, outcomeID, token) triple. The 25 dictionary characters (with
+// the "CSGO-" prefix and dashes stripped) are read least-significant-first
+// as base-57 digits into an 18-byte little-endian integer, which splits
+// into matchID (bytes 0-7), outcomeID (bytes 8-15) and token (bytes 16-17).
+func Decode(code string) (Decoded, error) {
+	if !codePattern.MatchString(code) {
+		return Decoded{}, fmt.Errorf("sharecode: %q is not a well-formed share code", code)
+	}
+
+	digits := strings.ReplaceAll(strings.TrimPrefix(code, "CSGO"), "-", "")
+
+	value := new(big.Int)
+	base := big.NewInt(57)
+	for i := len(digits) - 1; i >= 0; i-- {
+		idx := strings.IndexByte(dictionary, digits[i])
+		if idx < 0 {
+			return Decoded{}, fmt.Errorf("sharecode: %q contains a character outside the share code alphabet", code)
+		}
+		value.Mul(value, base)
+		value.Add(value, big.NewInt(int64(idx)))
+	}
+
+	raw := value.Bytes() // big-endian, shorter than 18 bytes unless the high bytes are non-zero
+	buf := make([]byte, 18)
+	copy(buf[18-len(raw):], raw)
+	reverseBytes(buf) // big.Int.Bytes is big-endian; the share code format is little-endian
+
+	return Decoded{
+		MatchID:   byteOrderUint64(buf[0:8]),
+		OutcomeID: byteOrderUint64(buf[8:16]),
+		Token:     uint16(buf[16]) | uint16(buf[17])<<8,
+	}, nil
+}
+
+// Encode is Decode's inverse: it renders d back into a "CSGO-"-prefixed
+// share code, useful for round-tripping in tests and for re-deriving a
+// canonical share code string from a (matchID, outcomeID, token) triple
+// read back out of storage.
+func Encode(d Decoded) string {
+	buf := make([]byte, 18)
+	putUint64(buf[0:8], d.MatchID)
+	putUint64(buf[8:16], d.OutcomeID)
+	buf[16] = byte(d.Token)
+	buf[17] = byte(d.Token >> 8)
+
+	be := make([]byte, 18)
+	copy(be, buf)
+	reverseBytes(be) // back to big-endian for big.Int
+
+	value := new(big.Int).SetBytes(be)
+	base := big.NewInt(57)
+	mod := new(big.Int)
+
+	// Always emit exactly 25 digits (five 5-character groups): once value
+	// hits zero, DivMod keeps yielding a 0 remainder, which pads the
+	// high-order digits with dictionary[0] - matching how Decode only ever
+	// reads/produces a fixed-width 18-byte triple.
+	digits := make([]byte, 25)
+	for i := range digits {
+		value.DivMod(value, base, mod)
+		digits[i] = dictionary[mod.Int64()]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CSGO")
+	for i := 0; i < 5; i++ {
+		sb.WriteByte('-')
+		sb.Write(digits[i*5 : i*5+5])
+	}
+	return sb.String()
+}
+
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+func byteOrderUint64(b []byte) uint64 {
+	var v uint64
+	for i := 7; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := 0; i < 8; i++ {
+		b[i] = byte(v)
+		v >>= 8
+	}
+}
@@ -0,0 +1,45 @@
+package sharecode
+
+import "testing"
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	cases := []Decoded{
+		{MatchID: 1, OutcomeID: 1, Token: 1},
+		{MatchID: 0, OutcomeID: 0, Token: 0},
+		{MatchID: 3251324521861644288, OutcomeID: 7076837250307195160, Token: 12345},
+		{MatchID: ^uint64(0), OutcomeID: ^uint64(0), Token: ^uint16(0)},
+	}
+
+	for _, want := range cases {
+		code := Encode(want)
+		got, err := Decode(code)
+		if err != nil {
+			t.Fatalf("Decode(%q) after Encode(%+v): %v", code, want, err)
+		}
+		if got != want {
+			t.Errorf("round trip mismatch: encoded %+v as %q, decoded back to %+v", want, code, got)
+		}
+	}
+}
+
+func TestEncodeProducesWellFormedCode(t *testing.T) {
+	code := Encode(Decoded{MatchID: 42, OutcomeID: 7, Token: 99})
+	if !codePattern.MatchString(code) {
+		t.Errorf("Encode produced %q, which doesn't match the share code pattern", code)
+	}
+}
+
+func TestDecodeRejectsMalformedInput(t *testing.T) {
+	cases := []string{
+		"",
+		"CSGO-abcde-abcde-abcde-abcde",        // only four groups
+		"CSGO-abcde-abcde-abcde-abcde-abcdef", // group too long
+		"abcde-abcde-abcde-abcde-abcde",       // missing CSGO prefix
+		"CSGO-abcd0-abcde-abcde-abcde-abcde",  // '0' isn't in the dictionary
+	}
+	for _, code := range cases {
+		if _, err := Decode(code); err == nil {
+			t.Errorf("Decode(%q) = nil error, want an error", code)
+		}
+	}
+}
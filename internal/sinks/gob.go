@@ -0,0 +1,26 @@
+package sinks
+
+import (
+	"encoding/gob"
+	"io"
+)
+
+// gobSink writes Records as a stream of gob-encoded values, a compact
+// binary alternative to ndjsonSink for Go-only consumers replaying the
+// stream without a JSON parser.
+type gobSink struct {
+	enc     *gob.Encoder
+	closeFn func() error
+}
+
+func newGobSink(w io.Writer, closeFn func() error) *gobSink {
+	return &gobSink{enc: gob.NewEncoder(w), closeFn: closeFn}
+}
+
+func (s *gobSink) Write(record Record) error {
+	return s.enc.Encode(record)
+}
+
+func (s *gobSink) Close() error {
+	return s.closeFn()
+}
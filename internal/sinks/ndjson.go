@@ -0,0 +1,25 @@
+package sinks
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ndjsonSink writes one JSON object per line, the newline-delimited JSON
+// format downstream log aggregators and ML pipelines expect.
+type ndjsonSink struct {
+	enc     *json.Encoder
+	closeFn func() error
+}
+
+func newNDJSONSink(w io.Writer, closeFn func() error) *ndjsonSink {
+	return &ndjsonSink{enc: json.NewEncoder(w), closeFn: closeFn}
+}
+
+func (s *ndjsonSink) Write(record Record) error {
+	return s.enc.Encode(record)
+}
+
+func (s *ndjsonSink) Close() error {
+	return s.closeFn()
+}
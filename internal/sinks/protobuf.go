@@ -0,0 +1,78 @@
+package sinks
+
+import (
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protobufSink writes Records as length-delimited protobuf messages: a
+// uvarint byte length followed by that many bytes of a protowire-encoded
+// message, the same framing protoc's "writeDelimitedTo" streams use. There
+// is no .proto file or protoc-gen-go code here - this module builds
+// offline with no protoc available - so the wire format below is encoded
+// by hand with protowire against these field numbers, which is exactly
+// what a real .proto matching this message would compile to:
+//
+//	message EventRecord {
+//	  string match_id        = 1;
+//	  int32  round_index     = 2;
+//	  int32  tick            = 3;
+//	  string type            = 4;
+//	  string actor_steam_id  = 5; // absent if the event has no actor
+//	  string victim_steam_id = 6; // absent if the event has no victim
+//	  bytes  meta            = 7; // raw JSON, absent if empty
+//	}
+type protobufSink struct {
+	w       io.Writer
+	closeFn func() error
+}
+
+func newProtobufSink(w io.Writer, closeFn func() error) *protobufSink {
+	return &protobufSink{w: w, closeFn: closeFn}
+}
+
+const (
+	fieldMatchID       protowire.Number = 1
+	fieldRoundIndex    protowire.Number = 2
+	fieldTick          protowire.Number = 3
+	fieldType          protowire.Number = 4
+	fieldActorSteamID  protowire.Number = 5
+	fieldVictimSteamID protowire.Number = 6
+	fieldMeta          protowire.Number = 7
+)
+
+func (s *protobufSink) Write(record Record) error {
+	var msg []byte
+	msg = protowire.AppendTag(msg, fieldMatchID, protowire.BytesType)
+	msg = protowire.AppendString(msg, record.MatchID)
+	msg = protowire.AppendTag(msg, fieldRoundIndex, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(int64(record.RoundIndex)))
+	msg = protowire.AppendTag(msg, fieldTick, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(int64(record.Tick)))
+	msg = protowire.AppendTag(msg, fieldType, protowire.BytesType)
+	msg = protowire.AppendString(msg, record.Type)
+	if record.ActorSteamID != nil {
+		msg = protowire.AppendTag(msg, fieldActorSteamID, protowire.BytesType)
+		msg = protowire.AppendString(msg, *record.ActorSteamID)
+	}
+	if record.VictimSteamID != nil {
+		msg = protowire.AppendTag(msg, fieldVictimSteamID, protowire.BytesType)
+		msg = protowire.AppendString(msg, *record.VictimSteamID)
+	}
+	if len(record.Meta) > 0 {
+		msg = protowire.AppendTag(msg, fieldMeta, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, record.Meta)
+	}
+
+	length := protowire.AppendVarint(nil, uint64(len(msg)))
+	if _, err := s.w.Write(length); err != nil {
+		return err
+	}
+	_, err := s.w.Write(msg)
+	return err
+}
+
+func (s *protobufSink) Close() error {
+	return s.closeFn()
+}
@@ -0,0 +1,87 @@
+// Package sinks mirrors the event stream already going to
+// writer.BatchInsertEvents to some other destination - a newline-delimited
+// JSON file, a gzip'd gob stream, a hand-encoded protobuf stream, or stdout
+// for shell piping - so downstream consumers that don't speak SQLite (ML
+// pipelines, log aggregators) can replay a match without touching the
+// database.
+package sinks
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Record is one exported event, the same shape regardless of destination
+// format.
+type Record struct {
+	MatchID       string          `json:"match_id"`
+	RoundIndex    int             `json:"round_index"`
+	Tick          int             `json:"tick"`
+	Type          string          `json:"type"`
+	ActorSteamID  *string         `json:"actor_steam_id,omitempty"`
+	VictimSteamID *string         `json:"victim_steam_id,omitempty"`
+	Meta          json.RawMessage `json:"meta,omitempty"`
+}
+
+// Sink writes Records to whatever destination Open was configured with.
+type Sink interface {
+	Write(record Record) error
+	Close() error
+}
+
+// Open builds a Sink writing format ("ndjson", "gob" or "protobuf") to
+// path. path of "" or "-" writes to stdout instead of a file, for shell
+// piping; otherwise a path ending in ".gz" is transparently gzip
+// compressed, mirroring the compressed-transport convention csgowtfd uses
+// for its own event exports.
+func Open(format, path string) (Sink, error) {
+	w, closeFn, err := openWriter(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "", "ndjson":
+		return newNDJSONSink(w, closeFn), nil
+	case "gob":
+		return newGobSink(w, closeFn), nil
+	case "protobuf":
+		return newProtobufSink(w, closeFn), nil
+	default:
+		closeFn()
+		return nil, fmt.Errorf("sinks: unsupported export format %q (want ndjson, gob or protobuf)", format)
+	}
+}
+
+// openWriter resolves path to an io.Writer and the func that releases
+// whatever resources it holds (flushing a buffered stdout writer, or
+// closing a gzip writer and the file underneath it).
+func openWriter(path string) (io.Writer, func() error, error) {
+	if path == "" || path == "-" {
+		bw := bufio.NewWriter(os.Stdout)
+		return bw, bw.Flush, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sinks: failed to create export file %q: %w", path, err)
+	}
+
+	if strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(f)
+		return gz, func() error {
+			if err := gz.Close(); err != nil {
+				f.Close()
+				return fmt.Errorf("sinks: failed to close gzip stream: %w", err)
+			}
+			return f.Close()
+		}, nil
+	}
+
+	return f, f.Close, nil
+}
@@ -0,0 +1,309 @@
+// Package steamapi resolves SteamID64s to Steam Web API profile data
+// (persona name, ban status, CS2 playtime) so griefing events can be
+// annotated with "is this actor a repeat offender" context. It is entirely
+// optional: callers that never construct a Client (e.g. --steam-api-key
+// wasn't passed) get no network calls at all, so offline usage is
+// unaffected.
+package steamapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	baseURL = "https://api.steampowered.com"
+
+	// maxIDsPerRequest is the documented batch limit for GetPlayerSummaries
+	// and GetPlayerBans.
+	maxIDsPerRequest = 100
+
+	// requestsPerSecond is a conservative rate under Steam's documented
+	// ~100,000 calls/day guidance for a single API key.
+	requestsPerSecond = 1
+)
+
+// Profile is the enrichment folded into an Event's MetaJSON under
+// "actor_profile"/"victim_profile".
+type Profile struct {
+	SteamID            string    `json:"steam_id"`
+	PersonaName        string    `json:"persona_name,omitempty"`
+	ProfileVisibility  int       `json:"profile_visibility"`
+	VACBanned          bool      `json:"vac_banned"`
+	NumberOfVACBans    int       `json:"number_of_vac_bans"`
+	GameBanned         bool      `json:"game_banned"`
+	DaysSinceLastBan   int       `json:"days_since_last_ban,omitempty"`
+	CS2PlaytimeMinutes int       `json:"cs2_playtime_minutes"`
+	FetchedAt          time.Time `json:"fetched_at"`
+}
+
+// stale reports whether this cached profile is older than ttl and should be
+// re-fetched.
+func (p *Profile) stale(ttl time.Duration) bool {
+	return time.Since(p.FetchedAt) > ttl
+}
+
+// Client resolves SteamID64s against the Steam Web API, rate-limiting
+// outgoing requests and caching responses on disk so re-running the parser
+// over the same match doesn't re-hit the API.
+type Client struct {
+	apiKey     string
+	httpClient *http.Client
+	cacheDir   string
+	cacheTTL   time.Duration
+	limiter    *tokenBucket
+
+	mu    sync.Mutex
+	cache map[string]*Profile // in-process cache, seeded from disk on first miss
+}
+
+// NewClient creates a Client for apiKey, caching resolved profiles under
+// cacheDir (created if missing) and treating cached entries older than
+// cacheTTL as stale. A zero cacheTTL falls back to 24 hours.
+func NewClient(apiKey, cacheDir string, cacheTTL time.Duration) (*Client, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("steamapi: apiKey is required")
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = 24 * time.Hour
+	}
+	if cacheDir != "" {
+		if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+			return nil, fmt.Errorf("steamapi: create cache dir: %w", err)
+		}
+	}
+	return &Client{
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cacheDir:   cacheDir,
+		cacheTTL:   cacheTTL,
+		limiter:    newTokenBucket(requestsPerSecond),
+		cache:      make(map[string]*Profile),
+	}, nil
+}
+
+// GetProfiles resolves steamIDs to Profiles, batching up to
+// maxIDsPerRequest per underlying API call and skipping any ID already
+// satisfied by a fresh cache entry (in-process or on disk). A failed batch
+// is logged by the caller via the returned error; profiles resolved before
+// the failure are still returned.
+func (c *Client) GetProfiles(ctx context.Context, steamIDs []string) (map[string]*Profile, error) {
+	result := make(map[string]*Profile, len(steamIDs))
+	var toFetch []string
+
+	for _, id := range dedupe(steamIDs) {
+		if profile := c.cached(id); profile != nil {
+			result[id] = profile
+			continue
+		}
+		toFetch = append(toFetch, id)
+	}
+
+	for start := 0; start < len(toFetch); start += maxIDsPerRequest {
+		end := start + maxIDsPerRequest
+		if end > len(toFetch) {
+			end = len(toFetch)
+		}
+		batch := toFetch[start:end]
+
+		profiles, err := c.fetchBatch(ctx, batch)
+		if err != nil {
+			return result, err
+		}
+		for id, profile := range profiles {
+			result[id] = profile
+			c.store(id, profile)
+		}
+	}
+
+	return result, nil
+}
+
+// fetchBatch resolves one batch (<=maxIDsPerRequest) via GetPlayerSummaries,
+// GetPlayerBans and GetOwnedGames, merging the three into one Profile per ID.
+func (c *Client) fetchBatch(ctx context.Context, steamIDs []string) (map[string]*Profile, error) {
+	profiles := make(map[string]*Profile, len(steamIDs))
+	for _, id := range steamIDs {
+		profiles[id] = &Profile{SteamID: id, FetchedAt: time.Now()}
+	}
+
+	idList := strings.Join(steamIDs, ",")
+
+	var summaries struct {
+		Response struct {
+			Players []struct {
+				SteamID                  string `json:"steamid"`
+				PersonaName              string `json:"personaname"`
+				CommunityVisibilityState int    `json:"communityvisibilitystate"`
+			} `json:"players"`
+		} `json:"response"`
+	}
+	if err := c.call(ctx, "ISteamUser/GetPlayerSummaries/v2", url.Values{"steamids": {idList}}, &summaries); err != nil {
+		return nil, fmt.Errorf("steamapi: GetPlayerSummaries: %w", err)
+	}
+	for _, player := range summaries.Response.Players {
+		if profile, ok := profiles[player.SteamID]; ok {
+			profile.PersonaName = player.PersonaName
+			profile.ProfileVisibility = player.CommunityVisibilityState
+		}
+	}
+
+	var bans struct {
+		Players []struct {
+			SteamID          string `json:"SteamId"`
+			VACBanned        bool   `json:"VACBanned"`
+			NumberOfVACBans  int    `json:"NumberOfVACBans"`
+			DaysSinceLastBan int    `json:"DaysSinceLastBan"`
+			GameBanned       bool   `json:"NumberOfGameBans"`
+		} `json:"players"`
+	}
+	if err := c.call(ctx, "ISteamUser/GetPlayerBans/v1", url.Values{"steamids": {idList}}, &bans); err != nil {
+		return nil, fmt.Errorf("steamapi: GetPlayerBans: %w", err)
+	}
+	for _, player := range bans.Players {
+		if profile, ok := profiles[player.SteamID]; ok {
+			profile.VACBanned = player.VACBanned
+			profile.NumberOfVACBans = player.NumberOfVACBans
+			profile.DaysSinceLastBan = player.DaysSinceLastBan
+			profile.GameBanned = player.GameBanned
+		}
+	}
+
+	// GetOwnedGames only accepts one SteamID per call, so fetch CS2 playtime
+	// individually. This is the slowest part of enrichment; the rate
+	// limiter keeps it from tripping Steam's per-key throttling.
+	const cs2AppID = "730"
+	for _, id := range steamIDs {
+		var owned struct {
+			Response struct {
+				Games []struct {
+					AppID           int `json:"appid"`
+					PlaytimeForever int `json:"playtime_forever"`
+				} `json:"games"`
+			} `json:"response"`
+		}
+		err := c.call(ctx, "IPlayerService/GetOwnedGames/v1", url.Values{
+			"steamid":                   {id},
+			"include_appinfo":           {"0"},
+			"include_played_free_games": {"1"},
+			"appids_filter[0]":          {cs2AppID},
+		}, &owned)
+		if err != nil {
+			// A private games list is a normal, expected case, not a hard
+			// failure for the whole batch - leave playtime at its zero value.
+			continue
+		}
+		for _, game := range owned.Response.Games {
+			if game.AppID == 730 {
+				profiles[id].CS2PlaytimeMinutes = game.PlaytimeForever
+			}
+		}
+	}
+
+	return profiles, nil
+}
+
+// call rate-limits, issues one GET against the Steam Web API, and decodes
+// the JSON response body into out.
+func (c *Client) call(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
+	if err := c.limiter.wait(ctx); err != nil {
+		return err
+	}
+
+	params.Set("key", c.apiKey)
+	reqURL := fmt.Sprintf("%s/%s?%s", baseURL, endpoint, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// cached returns a fresh Profile for steamID from the in-process cache or
+// disk, or nil if none exists or it's past cacheTTL.
+func (c *Client) cached(steamID string) *Profile {
+	c.mu.Lock()
+	if profile, ok := c.cache[steamID]; ok {
+		c.mu.Unlock()
+		if profile.stale(c.cacheTTL) {
+			return nil
+		}
+		return profile
+	}
+	c.mu.Unlock()
+
+	if c.cacheDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.cachePath(steamID))
+	if err != nil {
+		return nil
+	}
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil
+	}
+	if profile.stale(c.cacheTTL) {
+		return nil
+	}
+
+	c.mu.Lock()
+	c.cache[steamID] = &profile
+	c.mu.Unlock()
+	return &profile
+}
+
+// store saves profile to the in-process cache and, if configured, to disk.
+func (c *Client) store(steamID string, profile *Profile) {
+	c.mu.Lock()
+	c.cache[steamID] = profile
+	c.mu.Unlock()
+
+	if c.cacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cachePath(steamID), data, 0o644)
+}
+
+func (c *Client) cachePath(steamID string) string {
+	return filepath.Join(c.cacheDir, steamID+".json")
+}
+
+func dedupe(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
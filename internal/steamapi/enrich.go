@@ -0,0 +1,90 @@
+package steamapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cs-griefer-electron/internal/parser/extractors"
+)
+
+// EnrichEvents resolves every distinct ActorSteamID/VictimSteamID found
+// across events against the Steam Web API and folds the result into each
+// event's MetaJSON under "actor_profile"/"victim_profile", turning a raw
+// TEAM_KILL or DISCONNECT into something reviewable at a glance (e.g. an
+// actor who already has a VAC ban or under 10 hours of CS2 playtime).
+// Events are mutated in place. A player whose profile couldn't be resolved
+// (private profile, API error) is simply left without a profile key rather
+// than failing the whole batch.
+func EnrichEvents(ctx context.Context, client *Client, events []extractors.Event) error {
+	if client == nil {
+		return nil
+	}
+
+	var steamIDs []string
+	for _, event := range events {
+		if event.ActorSteamID != nil {
+			steamIDs = append(steamIDs, *event.ActorSteamID)
+		}
+		if event.VictimSteamID != nil {
+			steamIDs = append(steamIDs, *event.VictimSteamID)
+		}
+	}
+	if len(steamIDs) == 0 {
+		return nil
+	}
+
+	// On a failed batch GetProfiles still returns the profiles it resolved
+	// before the failure; attach those first, then report the error.
+	profiles, fetchErr := client.GetProfiles(ctx, steamIDs)
+
+	for i := range events {
+		if err := attachProfile(&events[i], profiles); err != nil {
+			return err
+		}
+	}
+	if fetchErr != nil {
+		return fmt.Errorf("steamapi: enrich events: %w", fetchErr)
+	}
+	return nil
+}
+
+// attachProfile merges actor_profile/victim_profile into a single event's
+// MetaJSON, preserving whatever metadata the extractor already put there.
+func attachProfile(event *extractors.Event, profiles map[string]*Profile) error {
+	actorProfile, haveActor := lookup(profiles, event.ActorSteamID)
+	victimProfile, haveVictim := lookup(profiles, event.VictimSteamID)
+	if !haveActor && !haveVictim {
+		return nil
+	}
+
+	meta := make(map[string]interface{})
+	if event.MetaJSON != nil {
+		if err := json.Unmarshal([]byte(*event.MetaJSON), &meta); err != nil {
+			return fmt.Errorf("steamapi: unmarshal existing meta for %s event: %w", event.Type, err)
+		}
+	}
+
+	if haveActor {
+		meta["actor_profile"] = actorProfile
+	}
+	if haveVictim {
+		meta["victim_profile"] = victimProfile
+	}
+
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("steamapi: marshal enriched meta for %s event: %w", event.Type, err)
+	}
+	metaJSONStr := string(metaJSON)
+	event.MetaJSON = &metaJSONStr
+	return nil
+}
+
+func lookup(profiles map[string]*Profile, steamID *string) (*Profile, bool) {
+	if steamID == nil {
+		return nil, false
+	}
+	profile, ok := profiles[*steamID]
+	return profile, ok
+}
@@ -0,0 +1,93 @@
+package steamapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cs-griefer-electron/internal/parser/extractors"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestAttachProfileAddsBothKeysWhenResolved(t *testing.T) {
+	actor := "1"
+	victim := "2"
+	event := extractors.Event{
+		Type:          "TEAM_KILL",
+		ActorSteamID:  &actor,
+		VictimSteamID: &victim,
+	}
+	profiles := map[string]*Profile{
+		"1": {SteamID: "1", PersonaName: "actorname", VACBanned: true},
+		"2": {SteamID: "2", PersonaName: "victimname"},
+	}
+
+	if err := attachProfile(&event, profiles); err != nil {
+		t.Fatalf("attachProfile returned error: %v", err)
+	}
+	if event.MetaJSON == nil {
+		t.Fatalf("expected MetaJSON to be set")
+	}
+
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(*event.MetaJSON), &meta); err != nil {
+		t.Fatalf("failed to unmarshal meta: %v", err)
+	}
+	if _, ok := meta["actor_profile"]; !ok {
+		t.Errorf("expected actor_profile key")
+	}
+	if _, ok := meta["victim_profile"]; !ok {
+		t.Errorf("expected victim_profile key")
+	}
+}
+
+func TestAttachProfilePreservesExistingMeta(t *testing.T) {
+	actor := "1"
+	existing := `{"reason_family":"kick"}`
+	event := extractors.Event{
+		Type:         "DISCONNECT",
+		ActorSteamID: &actor,
+		MetaJSON:     &existing,
+	}
+	profiles := map[string]*Profile{"1": {SteamID: "1", PersonaName: "actorname"}}
+
+	if err := attachProfile(&event, profiles); err != nil {
+		t.Fatalf("attachProfile returned error: %v", err)
+	}
+
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(*event.MetaJSON), &meta); err != nil {
+		t.Fatalf("failed to unmarshal meta: %v", err)
+	}
+	if meta["reason_family"] != "kick" {
+		t.Errorf("expected existing reason_family to survive, got %v", meta["reason_family"])
+	}
+	if _, ok := meta["actor_profile"]; !ok {
+		t.Errorf("expected actor_profile key to be added")
+	}
+}
+
+func TestAttachProfileNoopWhenUnresolved(t *testing.T) {
+	actor := "unresolved"
+	event := extractors.Event{Type: "TEAM_KILL", ActorSteamID: &actor}
+
+	if err := attachProfile(&event, map[string]*Profile{}); err != nil {
+		t.Fatalf("attachProfile returned error: %v", err)
+	}
+	if event.MetaJSON != nil {
+		t.Errorf("expected MetaJSON to stay nil when no profile resolved, got %q", *event.MetaJSON)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	got := dedupe([]string{"1", "2", "1", "", "3", "2"})
+	want := []string{"1", "2", "3"}
+	if len(got) != len(want) {
+		t.Fatalf("dedupe(...) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("dedupe(...)[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
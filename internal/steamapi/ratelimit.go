@@ -0,0 +1,51 @@
+package steamapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a minimal requests-per-second limiter. The repo would
+// normally reach for golang.org/x/time/rate here, but it isn't vendored and
+// this module is built offline (GOPROXY=off), so this hand-rolls the one
+// method enrichment actually needs: block until a token is available.
+type tokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newTokenBucket creates a limiter allowing at most ratePerSecond calls per
+// second, evenly spaced (not bursty).
+func newTokenBucket(ratePerSecond int) *tokenBucket {
+	if ratePerSecond <= 0 {
+		ratePerSecond = 1
+	}
+	return &tokenBucket{interval: time.Second / time.Duration(ratePerSecond)}
+}
+
+// wait blocks until the next call is allowed, or ctx is cancelled.
+func (t *tokenBucket) wait(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
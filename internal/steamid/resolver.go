@@ -0,0 +1,233 @@
+package steamid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver resolves a Steam vanity name (the part after
+// steamcommunity.com/id/ in a vanity URL) to an ID - the one thing Parse
+// can't do without a network call.
+type Resolver interface {
+	ResolveVanityURL(ctx context.Context, vanityName string) (ID, error)
+}
+
+const (
+	vanityBaseURL = "https://api.steampowered.com"
+
+	// vanityRequestsPerSecond mirrors internal/steamapi's requestsPerSecond:
+	// a conservative rate under Steam's documented per-key throttling.
+	vanityRequestsPerSecond = 1
+)
+
+// tokenBucket is the same minimal requests-per-second limiter
+// internal/steamapi/ratelimit.go and internal/parser/loader.go hand-roll:
+// golang.org/x/time/rate isn't vendored in this offline build.
+type tokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newTokenBucket(ratePerSecond int) *tokenBucket {
+	if ratePerSecond <= 0 {
+		ratePerSecond = 1
+	}
+	return &tokenBucket{interval: time.Second / time.Duration(ratePerSecond)}
+}
+
+func (t *tokenBucket) wait(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cachedVanity is what's persisted to disk/kept in-process per vanity
+// name, so a repeat lookup (e.g. re-parsing the same match's
+// --filter-steamids) doesn't re-hit the API.
+type cachedVanity struct {
+	ID        ID        `json:"id"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+func (c cachedVanity) stale(ttl time.Duration) bool {
+	return time.Since(c.FetchedAt) > ttl
+}
+
+// SteamWebAPIResolver resolves vanity names via Steam's
+// ISteamUser/ResolveVanityURL Web API endpoint. It rate-limits outgoing
+// requests and caches resolved IDs in-process and (optionally) on disk,
+// the same shape internal/steamapi.Client uses for profile enrichment -
+// this is the same API key and the same throttling concerns.
+type SteamWebAPIResolver struct {
+	apiKey     string
+	httpClient *http.Client
+	cacheDir   string
+	cacheTTL   time.Duration
+	limiter    *tokenBucket
+	// baseURL defaults to vanityBaseURL; overridable by tests so they
+	// don't need a live Steam Web API key.
+	baseURL string
+
+	mu    sync.Mutex
+	cache map[string]cachedVanity
+}
+
+// NewSteamWebAPIResolver creates a SteamWebAPIResolver for apiKey, caching
+// resolved vanity names under cacheDir (created if missing; "" disables
+// disk caching) and treating cached entries older than cacheTTL as stale.
+// A zero cacheTTL falls back to 24 hours.
+func NewSteamWebAPIResolver(apiKey, cacheDir string, cacheTTL time.Duration) (*SteamWebAPIResolver, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("steamid: apiKey is required")
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = 24 * time.Hour
+	}
+	if cacheDir != "" {
+		if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+			return nil, fmt.Errorf("steamid: create cache dir: %w", err)
+		}
+	}
+	return &SteamWebAPIResolver{
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cacheDir:   cacheDir,
+		cacheTTL:   cacheTTL,
+		limiter:    newTokenBucket(vanityRequestsPerSecond),
+		baseURL:    vanityBaseURL,
+		cache:      make(map[string]cachedVanity),
+	}, nil
+}
+
+// ResolveVanityURL implements Resolver.
+func (r *SteamWebAPIResolver) ResolveVanityURL(ctx context.Context, vanityName string) (ID, error) {
+	if cached, ok := r.cached(vanityName); ok {
+		return cached, nil
+	}
+
+	if err := r.limiter.wait(ctx); err != nil {
+		return 0, err
+	}
+
+	params := url.Values{"key": {r.apiKey}, "vanityurl": {vanityName}}
+	reqURL := fmt.Sprintf("%s/ISteamUser/ResolveVanityURL/v1/?%s", r.baseURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("steamid: resolve vanity %q: %w", vanityName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("steamid: resolve vanity %q: unexpected status %d: %s", vanityName, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var decoded struct {
+		Response struct {
+			Success int    `json:"success"`
+			SteamID string `json:"steamid"`
+			Message string `json:"message"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return 0, fmt.Errorf("steamid: resolve vanity %q: decode response: %w", vanityName, err)
+	}
+	if decoded.Response.Success != 1 {
+		return 0, fmt.Errorf("steamid: vanity %q did not resolve: %s", vanityName, decoded.Response.Message)
+	}
+
+	id, err := parseSteamID64(decoded.Response.SteamID)
+	if err != nil {
+		return 0, fmt.Errorf("steamid: resolve vanity %q: %w", vanityName, err)
+	}
+
+	r.store(vanityName, id)
+	return id, nil
+}
+
+func (r *SteamWebAPIResolver) cached(vanityName string) (ID, bool) {
+	r.mu.Lock()
+	if cached, ok := r.cache[vanityName]; ok {
+		r.mu.Unlock()
+		if cached.stale(r.cacheTTL) {
+			return 0, false
+		}
+		return cached.ID, true
+	}
+	r.mu.Unlock()
+
+	if r.cacheDir == "" {
+		return 0, false
+	}
+	data, err := os.ReadFile(r.cachePath(vanityName))
+	if err != nil {
+		return 0, false
+	}
+	var cached cachedVanity
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return 0, false
+	}
+	if cached.stale(r.cacheTTL) {
+		return 0, false
+	}
+
+	r.mu.Lock()
+	r.cache[vanityName] = cached
+	r.mu.Unlock()
+	return cached.ID, true
+}
+
+func (r *SteamWebAPIResolver) store(vanityName string, id ID) {
+	cached := cachedVanity{ID: id, FetchedAt: time.Now()}
+
+	r.mu.Lock()
+	r.cache[vanityName] = cached
+	r.mu.Unlock()
+
+	if r.cacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(r.cachePath(vanityName), data, 0o644)
+}
+
+func (r *SteamWebAPIResolver) cachePath(vanityName string) string {
+	return filepath.Join(r.cacheDir, url.QueryEscape(vanityName)+".json")
+}
@@ -0,0 +1,68 @@
+package steamid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSteamWebAPIResolverResolvesAndCaches(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, `{"response":{"success":1,"steamid":"76561198000000000"}}`)
+	}))
+	defer srv.Close()
+
+	resolver, err := NewSteamWebAPIResolver("fake-key", t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewSteamWebAPIResolver failed: %v", err)
+	}
+	resolver.httpClient = srv.Client()
+	resolver.baseURL = srv.URL
+
+	id, err := resolver.ResolveVanityURL(context.Background(), "somecoolname")
+	if err != nil {
+		t.Fatalf("ResolveVanityURL failed: %v", err)
+	}
+	if got, want := id.String(), "76561198000000000"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Second call for the same name should hit the in-process cache, not
+	// the server again.
+	if _, err := resolver.ResolveVanityURL(context.Background(), "somecoolname"); err != nil {
+		t.Fatalf("second ResolveVanityURL failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("expected 1 HTTP request (second lookup should be cached), got %d", got)
+	}
+}
+
+func TestSteamWebAPIResolverReportsUnresolvedVanity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"success":42,"message":"No match"}}`)
+	}))
+	defer srv.Close()
+
+	resolver, err := NewSteamWebAPIResolver("fake-key", "", time.Hour)
+	if err != nil {
+		t.Fatalf("NewSteamWebAPIResolver failed: %v", err)
+	}
+	resolver.httpClient = srv.Client()
+	resolver.baseURL = srv.URL
+
+	if _, err := resolver.ResolveVanityURL(context.Background(), "nobody"); err == nil {
+		t.Fatal("expected an error for an unresolved vanity name")
+	}
+}
+
+func TestNewSteamWebAPIResolverRequiresAPIKey(t *testing.T) {
+	if _, err := NewSteamWebAPIResolver("", "", 0); err == nil {
+		t.Error("expected an error for an empty apiKey")
+	}
+}
@@ -0,0 +1,186 @@
+// Package steamid parses the handful of SteamID text formats Valve's own
+// tools scatter across the ecosystem (SteamID64, SteamID3, SteamID2,
+// steamcommunity.com profile URLs) into one canonical form, so callers
+// like internal/parser's steamIDSet filter don't force users to
+// pre-convert to a raw decimal SteamID64 first.
+package steamid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// individualAccountBase is the SteamID64 of account ID 0 for the
+// "individual" universe/type/instance (universe=1 public, type=1
+// individual, instance=1 desktop): universe, type and instance are packed
+// into the upper 32 bits, account ID into the lower 32, so
+// SteamID64 = individualAccountBase + accountID.
+const individualAccountBase uint64 = 0x0110000100000000
+
+// ID is a SteamID64, normalized from whatever format Parse was given.
+type ID uint64
+
+// String returns id as a decimal SteamID64 - the form already used
+// throughout this repo (demoinfocs' Player.SteamID64, the steamIDSet
+// filter, db.PlayerPosition.SteamID, ...).
+func (id ID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// AccountID returns id's 32-bit account ID (the part SteamID2 and
+// SteamID3 actually encode).
+func (id ID) AccountID() uint32 {
+	return uint32(uint64(id) - individualAccountBase)
+}
+
+// Formats is every text representation of a SteamID this package and the
+// wider CS2 ecosystem use, computed once instead of leaving each consumer
+// to redo the bit math getSteamID (internal/parser) used to skip by just
+// stringifying SteamID64.
+type Formats struct {
+	SteamID64  string // decimal SteamID64, e.g. "76561198000000001"
+	SteamID32  string // decimal account ID, e.g. "39734273"
+	SteamID2   string // "STEAM_1:Y:Z"
+	SteamID3   string // "[U:1:accountID]"
+	ProfileURL string // steamcommunity.com/profiles/<SteamID64>
+}
+
+// Formats computes every text representation of id. Y = accountID & 1,
+// Z = accountID >> 1 - Valve's own SteamID2 bit layout, the inverse of
+// steamID2Re's parsing in Parse below.
+func (id ID) Formats() Formats {
+	accountID := id.AccountID()
+	y := accountID & 1
+	z := accountID >> 1
+	return Formats{
+		SteamID64:  id.String(),
+		SteamID32:  strconv.FormatUint(uint64(accountID), 10),
+		SteamID2:   fmt.Sprintf("STEAM_1:%d:%d", y, z),
+		SteamID3:   fmt.Sprintf("[U:1:%d]", accountID),
+		ProfileURL: fmt.Sprintf("https://steamcommunity.com/profiles/%d", uint64(id)),
+	}
+}
+
+// PrimaryFormat names which of Formats' fields ought to be used as the
+// primary SteamID string in contexts - like ParserConfig.SteamIDFormat in
+// internal/parser - that otherwise always used decimal SteamID64.
+type PrimaryFormat string
+
+const (
+	FormatSteamID64 PrimaryFormat = "steamid64"
+	FormatSteamID32 PrimaryFormat = "steamid32"
+	FormatSteamID2  PrimaryFormat = "steamid2"
+	FormatSteamID3  PrimaryFormat = "steamid3"
+)
+
+// Primary returns id's string representation under format, defaulting to
+// decimal SteamID64 (every existing caller's behavior before
+// PrimaryFormat existed) for "" or an unrecognized format.
+func (id ID) Primary(format PrimaryFormat) string {
+	switch format {
+	case FormatSteamID32:
+		return id.Formats().SteamID32
+	case FormatSteamID2:
+		return id.Formats().SteamID2
+	case FormatSteamID3:
+		return id.Formats().SteamID3
+	default:
+		return id.String()
+	}
+}
+
+// ErrVanityURL is returned by Parse for a steamcommunity.com/id/<name>
+// vanity URL: resolving a vanity name to a SteamID64 requires a Steam Web
+// API call (ISteamUser/ResolveVanityURL), which Parse itself can't make.
+// Use ParseWithResolver, or call a Resolver directly, instead.
+var ErrVanityURL = errors.New("steamid: vanity URL requires a Resolver (see ParseWithResolver)")
+
+var (
+	// steamID3Re matches both "[U:1:12345]" and the bracket-less
+	// "U:1:12345" the request calls out as an accepted variant.
+	steamID3Re = regexp.MustCompile(`^\[?U:1:(\d+)\]?$`)
+	// steamID2Re matches "STEAM_0:Y:Z" and "STEAM_1:Y:Z" (Valve has used
+	// both universe prefixes for the public universe in the wild).
+	steamID2Re   = regexp.MustCompile(`(?i)^STEAM_[01]:([01]):(\d+)$`)
+	profileURLRe = regexp.MustCompile(`(?i)^https?://steamcommunity\.com/profiles/(\d+)/?$`)
+	vanityURLRe  = regexp.MustCompile(`(?i)^https?://steamcommunity\.com/id/([^/]+)/?$`)
+)
+
+// Parse normalizes s - a SteamID64, SteamID3 ("[U:1:A]" or "U:1:A"),
+// SteamID2 ("STEAM_0:Y:Z" / "STEAM_1:Y:Z"), or a steamcommunity.com
+// profile URL - into an ID. A vanity URL (steamcommunity.com/id/<name>)
+// parses syntactically but returns ErrVanityURL, since turning the name
+// into an ID needs a network call; see ParseWithResolver.
+func Parse(s string) (ID, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("steamid: empty input")
+	}
+
+	if m := profileURLRe.FindStringSubmatch(s); m != nil {
+		return parseSteamID64(m[1])
+	}
+	if name, ok := vanityName(s); ok {
+		return 0, fmt.Errorf("%w: %q", ErrVanityURL, name)
+	}
+	if m := steamID3Re.FindStringSubmatch(s); m != nil {
+		accountID, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("steamid: invalid SteamID3 %q: %w", s, err)
+		}
+		return ID(individualAccountBase + accountID), nil
+	}
+	if m := steamID2Re.FindStringSubmatch(s); m != nil {
+		y, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("steamid: invalid SteamID2 %q: %w", s, err)
+		}
+		z, err := strconv.ParseUint(m[2], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("steamid: invalid SteamID2 %q: %w", s, err)
+		}
+		return ID(individualAccountBase + z*2 + y), nil
+	}
+
+	return parseSteamID64(s)
+}
+
+// vanityName reports whether s is a steamcommunity.com/id/<name> URL,
+// returning the extracted name.
+func vanityName(s string) (string, bool) {
+	m := vanityURLRe.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+func parseSteamID64(s string) (ID, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n < individualAccountBase {
+		return 0, fmt.Errorf("steamid: %q is not a recognized SteamID64, SteamID3, SteamID2, or profile URL", s)
+	}
+	return ID(n), nil
+}
+
+// ParseWithResolver is Parse, plus vanity URL support via resolver. It
+// only calls resolver.ResolveVanityURL when s is actually a vanity URL -
+// every other format is handled by Parse alone, with no network call.
+func ParseWithResolver(ctx context.Context, s string, resolver Resolver) (ID, error) {
+	id, err := Parse(s)
+	if err == nil {
+		return id, nil
+	}
+	name, ok := vanityName(s)
+	if !ok || !errors.Is(err, ErrVanityURL) {
+		return 0, err
+	}
+	if resolver == nil {
+		return 0, err
+	}
+	return resolver.ResolveVanityURL(ctx, name)
+}
@@ -0,0 +1,167 @@
+package steamid
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseSteamID64(t *testing.T) {
+	id, err := Parse("76561198000000000")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, want := id.String(), "76561198000000000"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseSteamID3(t *testing.T) {
+	const accountID = 39734272
+	want := ID(individualAccountBase + accountID).String()
+
+	for _, s := range []string{"[U:1:39734272]", "U:1:39734272"} {
+		id, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", s, err)
+		}
+		if got := id.String(); got != want {
+			t.Errorf("Parse(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestParseSteamID2(t *testing.T) {
+	// STEAM_0:1:19867136 -> accountID = 19867136*2 + 1 = 39734273
+	id, err := Parse("STEAM_0:1:19867136")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, want := id.AccountID(), uint32(39734273); got != want {
+		t.Errorf("got accountID %d, want %d", got, want)
+	}
+
+	// STEAM_1 is accepted the same way as STEAM_0.
+	id2, err := Parse("STEAM_1:1:19867136")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if id != id2 {
+		t.Errorf("expected STEAM_0 and STEAM_1 forms to resolve identically, got %v and %v", id, id2)
+	}
+}
+
+func TestParseProfileURL(t *testing.T) {
+	id, err := Parse("https://steamcommunity.com/profiles/76561198000000000")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, want := id.String(), "76561198000000000"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Trailing slash is tolerated.
+	if _, err := Parse("https://steamcommunity.com/profiles/76561198000000000/"); err != nil {
+		t.Errorf("Parse with trailing slash failed: %v", err)
+	}
+}
+
+func TestParseVanityURLReturnsErrVanityURL(t *testing.T) {
+	_, err := Parse("https://steamcommunity.com/id/somecoolname")
+	if !errors.Is(err, ErrVanityURL) {
+		t.Fatalf("expected ErrVanityURL, got %v", err)
+	}
+}
+
+func TestFormatsRoundTripsThroughParse(t *testing.T) {
+	// STEAM_0:1:19867136 -> accountID = 19867136*2 + 1 = 39734273 (see
+	// TestParseSteamID2).
+	id := ID(individualAccountBase + 39734273)
+	formats := id.Formats()
+
+	if formats.SteamID64 != id.String() {
+		t.Errorf("SteamID64 = %q, want %q", formats.SteamID64, id.String())
+	}
+	if formats.SteamID32 != "39734273" {
+		t.Errorf("SteamID32 = %q, want %q", formats.SteamID32, "39734273")
+	}
+	if formats.SteamID2 != "STEAM_1:1:19867136" {
+		t.Errorf("SteamID2 = %q, want %q", formats.SteamID2, "STEAM_1:1:19867136")
+	}
+	if formats.SteamID3 != "[U:1:39734273]" {
+		t.Errorf("SteamID3 = %q, want %q", formats.SteamID3, "[U:1:39734273]")
+	}
+
+	// Every format Formats produces (other than ProfileURL) should parse
+	// back to the same ID.
+	for _, s := range []string{formats.SteamID64, formats.SteamID2, formats.SteamID3} {
+		got, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", s, err)
+		}
+		if got != id {
+			t.Errorf("Parse(%q) = %v, want %v", s, got, id)
+		}
+	}
+}
+
+func TestPrimaryPicksFormat(t *testing.T) {
+	id := ID(individualAccountBase + 39734273)
+	formats := id.Formats()
+
+	cases := []struct {
+		format PrimaryFormat
+		want   string
+	}{
+		{"", formats.SteamID64},
+		{FormatSteamID64, formats.SteamID64},
+		{FormatSteamID32, formats.SteamID32},
+		{FormatSteamID2, formats.SteamID2},
+		{FormatSteamID3, formats.SteamID3},
+		{"unrecognized", formats.SteamID64},
+	}
+	for _, c := range cases {
+		if got := id.Primary(c.format); got != c.want {
+			t.Errorf("Primary(%q) = %q, want %q", c.format, got, c.want)
+		}
+	}
+}
+
+func TestParseRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"", "not-a-steamid", "12345", "STEAM_9:1:5"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q): expected an error", s)
+		}
+	}
+}
+
+type stubResolver struct {
+	id  ID
+	err error
+}
+
+func (s stubResolver) ResolveVanityURL(ctx context.Context, vanityName string) (ID, error) {
+	return s.id, s.err
+}
+
+func TestParseWithResolverUsesResolverOnlyForVanityURLs(t *testing.T) {
+	want := ID(individualAccountBase + 5)
+	resolver := stubResolver{id: want}
+
+	id, err := ParseWithResolver(context.Background(), "https://steamcommunity.com/id/somecoolname", resolver)
+	if err != nil {
+		t.Fatalf("ParseWithResolver failed: %v", err)
+	}
+	if id != want {
+		t.Errorf("got %v, want %v", id, want)
+	}
+
+	// Non-vanity input never touches the resolver.
+	id2, err := ParseWithResolver(context.Background(), "76561198000000000", stubResolver{err: errors.New("should not be called")})
+	if err != nil {
+		t.Fatalf("ParseWithResolver failed: %v", err)
+	}
+	if got, want := id2.String(), "76561198000000000"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+// Package userid resolves the per-connection UserID a CS2 demo's
+// GenericGameEvents carry (player_disconnect's "userid", weapon_fire's
+// "userid", etc.) to the player's persistent SteamID64.
+//
+// Matching those events against a *common.Player by entity ID, as this
+// parser previously did for weapon_fire, is unreliable: a player's Entity
+// (and EntityID) gets recreated on every death/respawn, while UserID stays
+// fixed for the player's whole connection. common.Player.UserID is
+// populated for CS2 demos (demoinfocs sets it in datatables.go and
+// stringtables.go from the same string-table data GenericGameEvents' userid
+// values come from), so it's the right thing to key a resolver off of.
+//
+// events.PlayerInfo's common.PlayerInfo.UserID field is explicitly
+// documented as "not available with CS2 demos", and demoinfocs-golang v5
+// has no ServerInfo event at all, so neither is a usable resolution source
+// here - Resolver instead is fed from events.PlayerConnect and from
+// periodic reconciliation against the live participant list.
+package userid
+
+import "strconv"
+
+// Resolver maintains a live UserID -> SteamID64 map for one demo.
+// Not safe for concurrent use - callers drive it from a single parser's
+// sequential event handlers, same as every other parser-local extractor.
+type Resolver struct {
+	bySteamID map[int]uint64
+}
+
+// NewResolver creates an empty Resolver.
+func NewResolver() *Resolver {
+	return &Resolver{bySteamID: make(map[int]uint64)}
+}
+
+// Observe records userID -> steamID64, if both are non-zero. Typically
+// called from a PlayerConnect handler with e.Player.UserID/e.Player.SteamID64.
+func (r *Resolver) Observe(userID int, steamID64 uint64) {
+	if userID == 0 || steamID64 == 0 {
+		return
+	}
+	r.bySteamID[userID] = steamID64
+}
+
+// ObserveParticipants records every player's UserID -> SteamID64 from a
+// live participant list (typically gs.Participants().All()) - a
+// reconciliation pass that catches any player whose connect predates this
+// Resolver, or who otherwise wasn't reported to Observe directly.
+func (r *Resolver) ObserveParticipants(participants []Player) {
+	for _, p := range participants {
+		r.Observe(p.UserID, p.SteamID64)
+	}
+}
+
+// Player is the subset of *common.Player ObserveParticipants needs, kept
+// narrow so this package doesn't have to import demoinfocs' common package.
+type Player struct {
+	UserID    int
+	SteamID64 uint64
+}
+
+// Resolve returns userID's SteamID64 as the decimal string format every
+// SteamID in this codebase uses (see parser.getSteamID), and whether userID
+// was known.
+func (r *Resolver) Resolve(userID int) (string, bool) {
+	steamID64, ok := r.bySteamID[userID]
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatUint(steamID64, 10), true
+}
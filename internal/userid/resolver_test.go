@@ -0,0 +1,52 @@
+package userid
+
+import "testing"
+
+func TestResolverObserveAndResolve(t *testing.T) {
+	r := NewResolver()
+
+	if _, ok := r.Resolve(5); ok {
+		t.Fatal("expected unknown userID to miss")
+	}
+
+	r.Observe(5, 76561198000000001)
+	got, ok := r.Resolve(5)
+	if !ok {
+		t.Fatal("expected userID 5 to resolve")
+	}
+	if want := "76561198000000001"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestResolverObserveIgnoresZeroValues(t *testing.T) {
+	r := NewResolver()
+	r.Observe(0, 76561198000000001)
+	r.Observe(5, 0)
+
+	if _, ok := r.Resolve(0); ok {
+		t.Fatal("expected userID 0 to never be stored")
+	}
+	if _, ok := r.Resolve(5); ok {
+		t.Fatal("expected a zero SteamID64 to never be stored")
+	}
+}
+
+func TestResolverObserveParticipants(t *testing.T) {
+	r := NewResolver()
+	r.ObserveParticipants([]Player{
+		{UserID: 1, SteamID64: 76561198000000001},
+		{UserID: 2, SteamID64: 76561198000000002},
+		{UserID: 0, SteamID64: 76561198000000003},
+	})
+
+	if got, ok := r.Resolve(1); !ok || got != "76561198000000001" {
+		t.Fatalf("Resolve(1) = %q, %v", got, ok)
+	}
+	if got, ok := r.Resolve(2); !ok || got != "76561198000000002" {
+		t.Fatalf("Resolve(2) = %q, %v", got, ok)
+	}
+	if _, ok := r.Resolve(0); ok {
+		t.Fatal("expected userID 0 to never resolve")
+	}
+}
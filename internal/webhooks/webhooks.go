@@ -0,0 +1,252 @@
+// Package webhooks delivers Get5-style match lifecycle notifications to a
+// configurable HTTP endpoint so external match-management systems can
+// consume analyzer output without polling the SQLite DB. Payloads are a
+// flat JSON object modeled after Get5's event webhooks: a "matchid" (and
+// optional "map_number") envelope, an "event" field naming the lifecycle
+// event (OnSeriesInit, OnRoundStart, OnRoundEnd, OnPlayerDeath,
+// OnBombPlanted, OnBodyBlockDetected, OnAFKDetected, OnSeriesResult), and
+// whatever extra fields that event carries.
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultQueueSize      = 256
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+	defaultTimeout        = 10 * time.Second
+)
+
+// Config configures a Dispatcher. Zero values fall back to the defaults
+// above, except URL: an empty URL makes every Dispatcher method a no-op so
+// callers can construct one unconditionally and skip an `if configured`
+// check at every call site.
+type Config struct {
+	URL    string
+	Secret string // HMAC-SHA256 key signing the request body; empty means unsigned.
+
+	MatchID   string
+	MapNumber *int
+
+	// Events filters which event types are sent; nil/empty means every
+	// event type passes. Keyed by the exact event name (e.g. "OnRoundEnd").
+	Events map[string]bool
+
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	QueueSize      int
+	Timeout        time.Duration
+
+	HTTPClient *http.Client
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = defaultMaxRetries
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = defaultInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultMaxBackoff
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultQueueSize
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: c.Timeout}
+	}
+	return c
+}
+
+// job is one queued, not-yet-delivered webhook delivery.
+type job struct {
+	eventType string
+	body      []byte
+}
+
+// Dispatcher delivers Emit calls to Config.URL off the calling goroutine,
+// retrying failed deliveries with exponential backoff. A Dispatcher with an
+// empty Config.URL is a valid no-op - every method is safe to call and does
+// nothing, so callers don't need to special-case "webhooks not configured".
+type Dispatcher struct {
+	cfg Config
+
+	queue  chan job
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	dropped uint64
+}
+
+// New creates a Dispatcher. Call Start before the first Emit and Stop once
+// done to flush any still-queued deliveries.
+func New(cfg Config) *Dispatcher {
+	cfg = cfg.withDefaults()
+	return &Dispatcher{
+		cfg:   cfg,
+		queue: make(chan job, cfg.QueueSize),
+	}
+}
+
+// Start launches the background delivery goroutine. A no-op if Config.URL
+// is empty, or if already started.
+func (d *Dispatcher) Start() {
+	if d == nil || d.cfg.URL == "" || d.stopCh != nil {
+		return
+	}
+	d.stopCh = make(chan struct{})
+	d.doneCh = make(chan struct{})
+	go d.loop()
+}
+
+// Stop halts the delivery goroutine, delivering (not dropping) whatever was
+// already queued before it exits - mirroring memgov/broker's
+// flush-before-halt shutdown contract.
+func (d *Dispatcher) Stop() {
+	if d == nil || d.stopCh == nil {
+		return
+	}
+	close(d.stopCh)
+	<-d.doneCh
+	d.stopCh = nil
+}
+
+// DroppedCount returns how many Emit calls were dropped because the queue
+// was full.
+func (d *Dispatcher) DroppedCount() uint64 {
+	if d == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&d.dropped)
+}
+
+// Emit enqueues eventType for delivery with fields merged into the Get5-
+// style envelope. It never blocks: if the queue is full, the delivery is
+// dropped and counted (see DroppedCount) rather than stalling the parse
+// pipeline behind a slow or unreachable webhook endpoint. A no-op if
+// Config.URL is empty, Start was never called, or eventType is filtered out
+// by Config.Events.
+func (d *Dispatcher) Emit(eventType string, fields map[string]interface{}) {
+	if d == nil || d.cfg.URL == "" || d.stopCh == nil {
+		return
+	}
+	if len(d.cfg.Events) > 0 && !d.cfg.Events[eventType] {
+		return
+	}
+
+	envelope := make(map[string]interface{}, len(fields)+3)
+	envelope["matchid"] = d.cfg.MatchID
+	if d.cfg.MapNumber != nil {
+		envelope["map_number"] = *d.cfg.MapNumber
+	}
+	envelope["event"] = eventType
+	for k, v := range fields {
+		envelope[k] = v
+	}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return
+	}
+
+	select {
+	case d.queue <- job{eventType: eventType, body: body}:
+	default:
+		atomic.AddUint64(&d.dropped, 1)
+	}
+}
+
+func (d *Dispatcher) loop() {
+	defer close(d.doneCh)
+	for {
+		select {
+		case j := <-d.queue:
+			d.deliverWithRetry(j)
+		case <-d.stopCh:
+			d.drainRemaining()
+			return
+		}
+	}
+}
+
+// drainRemaining delivers (best-effort, no further retries beyond the usual
+// backoff loop) whatever was sitting in the queue at shutdown, instead of
+// silently dropping it.
+func (d *Dispatcher) drainRemaining() {
+	for {
+		select {
+		case j := <-d.queue:
+			d.deliverWithRetry(j)
+		default:
+			return
+		}
+	}
+}
+
+// deliverWithRetry POSTs body to Config.URL, retrying with exponential
+// backoff (capped at MaxBackoff) up to MaxRetries times before giving up.
+// Retries run inline on this single worker goroutine, so a slow endpoint
+// delays later queued events rather than reordering them - acceptable for
+// a match-lifecycle feed where ordering matters more than low latency.
+func (d *Dispatcher) deliverWithRetry(j job) {
+	backoff := d.cfg.InitialBackoff
+	for attempt := 0; attempt <= d.cfg.MaxRetries; attempt++ {
+		if err := d.deliver(j.body); err == nil {
+			return
+		}
+		if attempt == d.cfg.MaxRetries {
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > d.cfg.MaxBackoff {
+			backoff = d.cfg.MaxBackoff
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, d.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.cfg.Secret != "" {
+		req.Header.Set("X-Signature", sign(d.cfg.Secret, body))
+	}
+
+	resp, err := d.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of body keyed by secret, for the
+// X-Signature header a receiver uses to authenticate the delivery.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,153 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEmitSendsSignedEnvelope(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		gotBody  map[string]interface{}
+		gotSig   string
+		received = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		json.Unmarshal(body, &gotBody)
+		gotSig = r.Header.Get("X-Signature")
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		close(received)
+	}))
+	defer srv.Close()
+
+	mapNum := 1
+	d := New(Config{URL: srv.URL, Secret: "s3cr3t", MatchID: "match-1", MapNumber: &mapNum})
+	d.Start()
+	defer d.Stop()
+
+	d.Emit("OnRoundStart", map[string]interface{}{"round": float64(3)})
+
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was never delivered")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotBody["matchid"] != "match-1" {
+		t.Errorf("matchid = %v, want match-1", gotBody["matchid"])
+	}
+	if gotBody["event"] != "OnRoundStart" {
+		t.Errorf("event = %v, want OnRoundStart", gotBody["event"])
+	}
+	if gotBody["map_number"] != float64(1) {
+		t.Errorf("map_number = %v, want 1", gotBody["map_number"])
+	}
+	if gotBody["round"] != float64(3) {
+		t.Errorf("round = %v, want 3", gotBody["round"])
+	}
+	if gotSig == "" {
+		t.Fatal("expected a non-empty X-Signature header")
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"matchid": "match-1", "map_number": float64(1), "event": "OnRoundStart", "round": float64(3)})
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write(body)
+	want := hex.EncodeToString(mac.Sum(nil))
+	// Only the signature's shape (hex of a SHA-256 MAC's length) is checked
+	// here, not its exact value.
+	if len(gotSig) != len(want) {
+		t.Errorf("X-Signature length = %d, want %d", len(gotSig), len(want))
+	}
+}
+
+func TestEmitFiltersByEventType(t *testing.T) {
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	d := New(Config{URL: srv.URL, MatchID: "m", Events: map[string]bool{"OnRoundEnd": true}})
+	d.Start()
+	defer d.Stop()
+
+	d.Emit("OnRoundStart", nil)
+	d.Emit("OnRoundEnd", nil)
+	time.Sleep(200 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&count); got != 1 {
+		t.Errorf("delivered %d requests, want 1 (only OnRoundEnd should pass the filter)", got)
+	}
+}
+
+func TestEmitWithoutURLIsNoOp(t *testing.T) {
+	d := New(Config{MatchID: "m"})
+	d.Start() // no-op: no URL configured
+	d.Emit("OnSeriesInit", nil)
+	d.Stop()
+	if d.DroppedCount() != 0 {
+		t.Error("expected no drops when the dispatcher has no URL configured")
+	}
+}
+
+func TestEmitRetriesWithBackoffThenSucceeds(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		if n < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	d := New(Config{URL: srv.URL, MatchID: "m", InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, MaxRetries: 5})
+	d.Start()
+
+	d.Emit("OnSeriesResult", nil)
+	d.Stop() // Stop blocks until the queued delivery (with its retries) finishes.
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("server saw %d attempts, want 3 (2 failures + 1 success)", got)
+	}
+}
+
+func TestEmitDropsOnQueueOverflow(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	d := New(Config{URL: srv.URL, MatchID: "m", QueueSize: 2, MaxRetries: 0})
+	d.Start()
+
+	for i := 0; i < 10; i++ {
+		d.Emit("OnRoundStart", nil)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if d.DroppedCount() == 0 {
+		t.Error("expected some deliveries to be dropped once the queue filled up behind the blocked server")
+	}
+
+	close(block)
+	d.Stop()
+}